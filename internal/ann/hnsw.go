@@ -0,0 +1,394 @@
+// Package ann provides an approximate nearest-neighbour index over embedding
+// vectors, used to answer semantic search queries without scanning every
+// stored embedding.
+//
+// The index is a Hierarchical Navigable Small World graph (Malkov & Yashunin,
+// 2016). Vectors are normalized on insert, so similarity is the dot product
+// and equals cosine similarity. Entries can be added, replaced and removed
+// incrementally; removals are tombstoned and the graph is rebuilt once too
+// many accumulate.
+package ann
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Default construction and search parameters.
+const (
+	DefaultM              = 16
+	DefaultEfConstruction = 200
+	DefaultEfSearch       = 64
+)
+
+// Result is a search hit.
+type Result struct {
+	Key        string
+	Similarity float64
+}
+
+// Index is an HNSW index keyed by string. It is not safe for concurrent use.
+type Index struct {
+	m              int     // max neighbours per node on upper layers (2*m on layer 0)
+	efConstruction int     // candidate list size while inserting
+	efSearch       int     // candidate list size while searching
+	levelMult      float64 // 1/ln(m), controls the layer distribution
+
+	dim      int
+	nodes    []*node
+	byKey    map[string]int32
+	entry    int32 // entry point, -1 when empty
+	maxLevel int
+	deleted  int
+
+	rng *rand.Rand
+}
+
+type node struct {
+	key     string
+	tag     string // caller-defined version stamp, e.g. model and content hash
+	vec     []float32
+	links   [][]int32 // links[level] = neighbour ids
+	deleted bool
+}
+
+// New creates an empty index with the default parameters.
+func New() *Index {
+	return NewWithParams(DefaultM, DefaultEfConstruction, DefaultEfSearch)
+}
+
+// NewWithParams creates an empty index with explicit HNSW parameters.
+func NewWithParams(m, efConstruction, efSearch int) *Index {
+	if m < 2 {
+		m = 2
+	}
+	if efConstruction < m {
+		efConstruction = m
+	}
+	if efSearch < 1 {
+		efSearch = 1
+	}
+	return &Index{
+		m:              m,
+		efConstruction: efConstruction,
+		efSearch:       efSearch,
+		levelMult:      1 / math.Log(float64(m)),
+		byKey:          make(map[string]int32),
+		entry:          -1,
+		rng:            rand.New(rand.NewSource(42)),
+	}
+}
+
+// Len returns the number of live entries.
+func (x *Index) Len() int {
+	return len(x.byKey)
+}
+
+// Dim returns the vector dimension, or 0 if nothing has been added.
+func (x *Index) Dim() int {
+	return x.dim
+}
+
+// Tag returns the tag stored with key, and whether key is present.
+func (x *Index) Tag(key string) (string, bool) {
+	id, ok := x.byKey[key]
+	if !ok {
+		return "", false
+	}
+	return x.nodes[id].tag, true
+}
+
+// Tags returns the tag of every live entry, keyed by entry key.
+func (x *Index) Tags() map[string]string {
+	tags := make(map[string]string, len(x.byKey))
+	for key, id := range x.byKey {
+		tags[key] = x.nodes[id].tag
+	}
+	return tags
+}
+
+// Add inserts vec under key, replacing any existing entry for key.
+// tag is stored alongside and returned by Tag, so callers can tell whether
+// an entry is out of date without comparing vectors.
+func (x *Index) Add(key, tag string, vec []float32) error {
+	if len(vec) == 0 {
+		return fmt.Errorf("ann: empty vector for %s", key)
+	}
+	if x.dim == 0 {
+		x.dim = len(vec)
+	}
+	if len(vec) != x.dim {
+		return fmt.Errorf("ann: vector for %s has dimension %d, index has %d", key, len(vec), x.dim)
+	}
+	x.Remove(key)
+	x.insert(key, tag, normalize(vec))
+	return nil
+}
+
+// Remove deletes key from the index. It reports whether key was present.
+func (x *Index) Remove(key string) bool {
+	id, ok := x.byKey[key]
+	if !ok {
+		return false
+	}
+	delete(x.byKey, key)
+	x.nodes[id].deleted = true
+	x.deleted++
+	if x.deleted > 64 && x.deleted > len(x.byKey) {
+		x.compact()
+	}
+	return true
+}
+
+// Search returns up to k live entries most similar to query, best first.
+func (x *Index) Search(query []float32, k int) []Result {
+	if k <= 0 || x.entry < 0 || len(query) != x.dim || len(x.byKey) == 0 {
+		return nil
+	}
+	q := normalize(query)
+
+	ep := x.entry
+	for level := x.maxLevel; level > 0; level-- {
+		ep = x.greedy(q, ep, level)
+	}
+	ef := x.efSearch
+	if ef < k {
+		ef = k
+	}
+	found := x.searchLayer(q, ep, ef, 0)
+
+	results := make([]Result, 0, k)
+	for _, c := range found {
+		n := x.nodes[c.id]
+		if n.deleted {
+			continue
+		}
+		results = append(results, Result{Key: n.key, Similarity: float64(c.sim)})
+		if len(results) == k {
+			break
+		}
+	}
+	return results
+}
+
+// insert adds a normalized vector as a new node.
+func (x *Index) insert(key, tag string, vec []float32) {
+	level := int(math.Floor(-math.Log(1-x.rng.Float64()) * x.levelMult))
+	id := int32(len(x.nodes))
+	n := &node{key: key, tag: tag, vec: vec, links: make([][]int32, level+1)}
+	x.nodes = append(x.nodes, n)
+	x.byKey[key] = id
+
+	if x.entry < 0 {
+		x.entry = id
+		x.maxLevel = level
+		return
+	}
+
+	ep := x.entry
+	for l := x.maxLevel; l > level; l-- {
+		ep = x.greedy(vec, ep, l)
+	}
+	for l := min(level, x.maxLevel); l >= 0; l-- {
+		candidates := x.searchLayer(vec, ep, x.efConstruction, l)
+		neighbours := x.selectNeighbours(candidates, x.maxLinks(l))
+		n.links[l] = neighbours
+		for _, nb := range neighbours {
+			x.link(nb, id, l)
+		}
+		ep = candidates[0].id
+	}
+	if level > x.maxLevel {
+		x.maxLevel = level
+		x.entry = id
+	}
+}
+
+// link adds a connection from -> to on level, pruning from's neighbour
+// list back down to the layer's limit if it overflows.
+func (x *Index) link(from, to int32, level int) {
+	n := x.nodes[from]
+	n.links[level] = append(n.links[level], to)
+	limit := x.maxLinks(level)
+	if len(n.links[level]) <= limit {
+		return
+	}
+	candidates := make([]candidate, len(n.links[level]))
+	for i, nb := range n.links[level] {
+		candidates[i] = candidate{id: nb, sim: dot(n.vec, x.nodes[nb].vec)}
+	}
+	sortCandidates(candidates)
+	n.links[level] = x.selectNeighbours(candidates, limit)
+}
+
+// selectNeighbours picks up to limit neighbours from candidates (sorted best
+// first) using the HNSW heuristic: a candidate is kept only if it is closer to
+// the new node than to any neighbour already kept, which spreads links across
+// clusters. Remaining slots are filled with the closest discarded candidates.
+func (x *Index) selectNeighbours(candidates []candidate, limit int) []int32 {
+	selected := make([]int32, 0, limit)
+	var skipped []int32
+	for _, c := range candidates {
+		if len(selected) == limit {
+			break
+		}
+		keep := true
+		for _, s := range selected {
+			if dot(x.nodes[c.id].vec, x.nodes[s].vec) > c.sim {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c.id)
+		} else {
+			skipped = append(skipped, c.id)
+		}
+	}
+	for _, id := range skipped {
+		if len(selected) == limit {
+			break
+		}
+		selected = append(selected, id)
+	}
+	return selected
+}
+
+func (x *Index) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * x.m
+	}
+	return x.m
+}
+
+// greedy walks level from ep towards q and returns the closest node found.
+func (x *Index) greedy(q []float32, ep int32, level int) int32 {
+	best := dot(q, x.nodes[ep].vec)
+	for changed := true; changed; {
+		changed = false
+		for _, nb := range x.nodes[ep].links[level] {
+			if sim := dot(q, x.nodes[nb].vec); sim > best {
+				best, ep, changed = sim, nb, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer runs a beam search of width ef on level starting at ep and
+// returns the nodes found, best first. Tombstoned nodes are traversed but
+// callers filter them from results.
+func (x *Index) searchLayer(q []float32, ep int32, ef, level int) []candidate {
+	visited := map[int32]bool{ep: true}
+	start := candidate{id: ep, sim: dot(q, x.nodes[ep].vec)}
+	frontier := &maxHeap{start}
+	found := &minHeap{start}
+
+	for frontier.Len() > 0 {
+		c := heap.Pop(frontier).(candidate)
+		if found.Len() >= ef && c.sim < (*found)[0].sim {
+			break
+		}
+		for _, nb := range x.nodes[c.id].links[level] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+			sim := dot(q, x.nodes[nb].vec)
+			if found.Len() < ef || sim > (*found)[0].sim {
+				heap.Push(frontier, candidate{id: nb, sim: sim})
+				heap.Push(found, candidate{id: nb, sim: sim})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	result := []candidate(*found)
+	sortCandidates(result)
+	return result
+}
+
+// compact rebuilds the graph from live entries, dropping tombstones.
+func (x *Index) compact() {
+	live := make([]*node, 0, len(x.byKey))
+	for _, n := range x.nodes {
+		if !n.deleted {
+			live = append(live, n)
+		}
+	}
+	x.nodes = nil
+	x.byKey = make(map[string]int32, len(live))
+	x.entry = -1
+	x.maxLevel = 0
+	x.deleted = 0
+	for _, n := range live {
+		x.insert(n.key, n.tag, n.vec)
+	}
+}
+
+type candidate struct {
+	id  int32
+	sim float32
+}
+
+func sortCandidates(c []candidate) {
+	sort.Slice(c, func(i, j int) bool { return c[i].sim > c[j].sim })
+}
+
+// maxHeap pops the most similar candidate first.
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].sim > h[j].sim }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(v any)        { *h = append(*h, v.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+// minHeap pops the least similar candidate first.
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].sim < h[j].sim }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(v any)        { *h = append(*h, v.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// normalize returns a unit-length copy of v.
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, f := range v {
+		norm += float64(f) * float64(f)
+	}
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i, f := range v {
+		out[i] = f * scale
+	}
+	return out
+}
@@ -0,0 +1,180 @@
+package ann
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func randomVectors(n, dim int, seed int64) [][]float32 {
+	rng := rand.New(rand.NewSource(seed))
+	vecs := make([][]float32, n)
+	for i := range vecs {
+		vecs[i] = make([]float32, dim)
+		for j := range vecs[i] {
+			vecs[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	return vecs
+}
+
+// bruteForce returns the keys of the k most similar vectors.
+func bruteForce(vecs [][]float32, q []float32, k int) []string {
+	qn := normalize(q)
+	type hit struct {
+		key string
+		sim float32
+	}
+	hits := make([]hit, len(vecs))
+	for i, v := range vecs {
+		hits[i] = hit{fmt.Sprintf("e%d", i), dot(qn, normalize(v))}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].sim > hits[j].sim })
+	keys := make([]string, k)
+	for i := range keys {
+		keys[i] = hits[i].key
+	}
+	return keys
+}
+
+func buildIndex(t *testing.T, vecs [][]float32) *Index {
+	t.Helper()
+	x := New()
+	for i, v := range vecs {
+		if err := x.Add(fmt.Sprintf("e%d", i), "v1", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return x
+}
+
+func TestSearchRecall(t *testing.T) {
+	vecs := randomVectors(2000, 32, 1)
+	x := buildIndex(t, vecs)
+
+	const k = 10
+	queries := randomVectors(50, 32, 2)
+	hits := 0
+	for _, q := range queries {
+		want := make(map[string]bool)
+		for _, key := range bruteForce(vecs, q, k) {
+			want[key] = true
+		}
+		got := x.Search(q, k)
+		if len(got) != k {
+			t.Fatalf("Search returned %d results, want %d", len(got), k)
+		}
+		for i, r := range got {
+			if want[r.Key] {
+				hits++
+			}
+			if i > 0 && r.Similarity > got[i-1].Similarity {
+				t.Fatalf("results not sorted: %+v", got)
+			}
+		}
+	}
+	if recall := float64(hits) / float64(k*len(queries)); recall < 0.9 {
+		t.Errorf("recall@%d = %.2f, want >= 0.9", k, recall)
+	}
+}
+
+func TestAddReplaceRemove(t *testing.T) {
+	x := New()
+	x.Add("a", "v1", []float32{1, 0, 0})
+	x.Add("b", "v1", []float32{0, 1, 0})
+	x.Add("a", "v2", []float32{0, 0, 1})
+
+	if x.Len() != 2 {
+		t.Errorf("Len = %d, want 2", x.Len())
+	}
+	if tag, _ := x.Tag("a"); tag != "v2" {
+		t.Errorf("Tag(a) = %q, want v2", tag)
+	}
+	got := x.Search([]float32{0, 0.1, 1}, 1)
+	if len(got) != 1 || got[0].Key != "a" || got[0].Similarity < 0.99 {
+		t.Errorf("Search after replace = %+v, want a", got)
+	}
+
+	if !x.Remove("a") || x.Remove("a") {
+		t.Error("Remove should report presence exactly once")
+	}
+	got = x.Search([]float32{0, 0, 1}, 5)
+	if len(got) != 1 || got[0].Key != "b" {
+		t.Errorf("Search after remove = %+v, want only b", got)
+	}
+	if err := x.Add("c", "v1", []float32{1, 2}); err == nil {
+		t.Error("Add with the wrong dimension should fail")
+	}
+}
+
+func TestRemoveCompacts(t *testing.T) {
+	vecs := randomVectors(300, 8, 3)
+	x := buildIndex(t, vecs)
+	for i := 0; i < 250; i++ {
+		x.Remove(fmt.Sprintf("e%d", i))
+	}
+	if x.deleted >= 250 {
+		t.Errorf("tombstones were never compacted (%d)", x.deleted)
+	}
+	got := x.Search(vecs[299], 1)
+	if len(got) != 1 || got[0].Key != "e299" {
+		t.Errorf("Search after compaction = %+v, want e299", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	vecs := randomVectors(500, 16, 4)
+	x := buildIndex(t, vecs)
+	x.Remove("e0")
+
+	path := filepath.Join(t.TempDir(), "index.hnsw")
+	if err := x.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	y, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y.Len() != x.Len() || y.Dim() != 16 {
+		t.Fatalf("loaded Len=%d Dim=%d, want %d 16", y.Len(), y.Dim(), x.Len())
+	}
+	if _, ok := y.Tag("e0"); ok {
+		t.Error("removed entry was saved")
+	}
+	for _, q := range randomVectors(5, 16, 5) {
+		a, b := x.Search(q, 5), y.Search(q, 5)
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Errorf("loaded index disagrees:\n%v\n%v", a, b)
+		}
+	}
+
+	// Loaded indexes keep accepting incremental updates
+	if err := y.Add("new", "v1", vecs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := y.Search(vecs[1], 2); len(got) != 2 {
+		t.Errorf("Search after add = %+v", got)
+	}
+}
+
+func TestLoadRejectsCorruptFiles(t *testing.T) {
+	x := buildIndex(t, randomVectors(50, 4, 6))
+	path := filepath.Join(t.TempDir(), "index.hnsw")
+	if err := x.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+
+	for name, corrupt := range map[string][]byte{
+		"truncated": data[:len(data)/2],
+		"bad magic": append([]byte("NOTANIDX"), data[8:]...),
+	} {
+		os.WriteFile(path, corrupt, 0644)
+		if _, err := Load(path); err != ErrBadFormat {
+			t.Errorf("%s: Load error = %v, want ErrBadFormat", name, err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package ann
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// fileMagic identifies an index file and its format version.
+const fileMagic = "CXANN001"
+
+// ErrBadFormat is returned by Load when the file is not an index written by
+// this version of cx. Callers should rebuild the index.
+var ErrBadFormat = errors.New("ann: unrecognized index file format")
+
+// Save writes the index to path atomically (via a temporary file and rename).
+// Tombstoned entries are not written.
+func (x *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create index directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("create index file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := x.write(w); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write index: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads an index written by Save.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	x, err := read(bufio.NewReader(f))
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrBadFormat
+		}
+		return nil, err
+	}
+	return x, nil
+}
+
+// write serializes the index. Layout (little-endian):
+//
+//	magic [8]byte
+//	m, efConstruction, efSearch, dim, maxLevel uint32
+//	entry int32, nodeCount uint32
+//	per node: key, tag (uint32 length + bytes), dim float32s,
+//	          levelCount uint32, per level: linkCount uint32, links []int32
+//
+// Node ids are renumbered to skip tombstones.
+func (x *Index) write(w io.Writer) error {
+	remap := make(map[int32]int32, len(x.byKey))
+	var live []int32
+	for id, n := range x.nodes {
+		if !n.deleted {
+			remap[int32(id)] = int32(len(live))
+			live = append(live, int32(id))
+		}
+	}
+	entry := int32(-1)
+	if e, ok := remap[x.entry]; ok {
+		entry = e
+	} else if len(live) > 0 {
+		// The entry point was removed; rebuild so a live node takes its place.
+		x.compact()
+		return x.write(w)
+	}
+
+	if _, err := io.WriteString(w, fileMagic); err != nil {
+		return err
+	}
+	header := []any{
+		uint32(x.m), uint32(x.efConstruction), uint32(x.efSearch), uint32(x.dim), uint32(x.maxLevel),
+		entry, uint32(len(live)),
+	}
+	for _, v := range header {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	for _, id := range live {
+		n := x.nodes[id]
+		if err := writeString(w, n.key); err != nil {
+			return err
+		}
+		if err := writeString(w, n.tag); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, n.vec); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(n.links))); err != nil {
+			return err
+		}
+		for _, links := range n.links {
+			kept := make([]int32, 0, len(links))
+			for _, nb := range links {
+				if r, ok := remap[nb]; ok {
+					kept = append(kept, r)
+				}
+			}
+			if err := binary.Write(w, binary.LittleEndian, uint32(len(kept))); err != nil {
+				return err
+			}
+			if err := binary.Write(w, binary.LittleEndian, kept); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func read(r io.Reader) (*Index, error) {
+	magic := make([]byte, len(fileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != fileMagic {
+		return nil, ErrBadFormat
+	}
+
+	var m, efc, efs, dim, maxLevel, count uint32
+	var entry int32
+	for _, v := range []any{&m, &efc, &efs, &dim, &maxLevel, &entry, &count} {
+		if err := binary.Read(r, binary.LittleEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	if count > 0 && (entry < 0 || uint32(entry) >= count || dim == 0) {
+		return nil, ErrBadFormat
+	}
+
+	x := NewWithParams(int(m), int(efc), int(efs))
+	x.dim = int(dim)
+	x.maxLevel = int(maxLevel)
+	x.entry = entry
+	x.nodes = make([]*node, count)
+	for i := range x.nodes {
+		n := &node{}
+		var err error
+		if n.key, err = readString(r); err != nil {
+			return nil, err
+		}
+		if n.tag, err = readString(r); err != nil {
+			return nil, err
+		}
+		n.vec = make([]float32, dim)
+		if err := binary.Read(r, binary.LittleEndian, n.vec); err != nil {
+			return nil, err
+		}
+		var levels uint32
+		if err := binary.Read(r, binary.LittleEndian, &levels); err != nil {
+			return nil, err
+		}
+		if levels == 0 || levels > maxLevel+1 {
+			return nil, ErrBadFormat
+		}
+		n.links = make([][]int32, levels)
+		for l := range n.links {
+			var k uint32
+			if err := binary.Read(r, binary.LittleEndian, &k); err != nil {
+				return nil, err
+			}
+			if k > count {
+				return nil, ErrBadFormat
+			}
+			n.links[l] = make([]int32, k)
+			if err := binary.Read(r, binary.LittleEndian, n.links[l]); err != nil {
+				return nil, err
+			}
+			for _, nb := range n.links[l] {
+				if nb < 0 || uint32(nb) >= count {
+					return nil, ErrBadFormat
+				}
+			}
+		}
+		x.nodes[i] = n
+		x.byKey[n.key] = int32(i)
+	}
+
+	// Every link must point at a node present on that layer, and the entry
+	// point must span every layer, or searches would index out of range.
+	if count > 0 && len(x.nodes[entry].links) != int(maxLevel)+1 {
+		return nil, ErrBadFormat
+	}
+	for _, n := range x.nodes {
+		for l, links := range n.links {
+			for _, nb := range links {
+				if len(x.nodes[nb].links) <= l {
+					return nil, ErrBadFormat
+				}
+			}
+		}
+	}
+	return x, nil
+}
+
+func writeString(w io.Writer, s string) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readString(r io.Reader) (string, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return "", err
+	}
+	if n > math.MaxUint16 {
+		return "", ErrBadFormat
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -16,7 +16,7 @@ These commands are used less frequently during normal agent workflows.
 For everyday use, prefer: cx, cx find, cx check, cx scan.
 
 Subcommands:
-  db          Database management (info, compact, doctor, export)
+  db          Database management (info, compact, doctor, migrate, export)
   tag         Entity tag management (add, remove, list, find)
   sql         Execute SQL directly
   doctor      Health check
@@ -34,6 +34,7 @@ Subcommands:
   serve       Start MCP server
   impact      Analyze blast radius
   coverage    Import/analyze coverage data
+  trend       Metric history of an entity, package or the repo
 
 Examples:
   cx admin db info              # Database statistics
@@ -41,7 +42,8 @@ Examples:
   cx admin tag add Foo important  # Tag an entity
   cx admin doctor               # Health check
   cx admin sql "SELECT ..."     # Direct SQL query
-  cx admin blame Execute        # Entity commit history`,
+  cx admin blame Execute        # Entity commit history
+  cx admin trend repo --metric coverage  # Coverage over scans`,
 }
 
 // ── db subgroup ──────────────────────────────────────────────
@@ -49,7 +51,7 @@ Examples:
 var adminDbCmd = &cobra.Command{
 	Use:   "db",
 	Short: "Database management commands",
-	Long:  `Database management: info, compact, doctor, export.`,
+	Long:  `Database management: info, compact, doctor, migrate, export.`,
 }
 
 var adminDbInfoCmd = &cobra.Command{
@@ -67,6 +69,12 @@ var adminDbDoctorCmd = &cobra.Command{
 	RunE: runDoctor,
 }
 
+var adminDbMigrateCmd = &cobra.Command{
+	Use: "migrate", Short: "Apply pending schema migrations",
+	Long: dbMigrateCmd.Long,
+	RunE: runDbMigrate,
+}
+
 // ── tag subgroup ─────────────────────────────────────────────
 
 var adminTagCmd = &cobra.Command{
@@ -163,6 +171,8 @@ func init() {
 	adminDbCmd.AddCommand(adminDbInfoCmd)
 	adminDbCmd.AddCommand(adminDbCompactCmd)
 	adminDbCmd.AddCommand(adminDbDoctorCmd)
+	adminDbCmd.AddCommand(adminDbMigrateCmd)
+	addMigrateFlags(adminDbMigrateCmd)
 
 	// tag subgroup
 	adminCmd.AddCommand(adminTagCmd)
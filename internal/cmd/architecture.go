@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/rules"
+	"github.com/anthropics/cx/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// ArchitectureOutput is the result of checking the whole graph against the
+// architecture rules
+type ArchitectureOutput struct {
+	Summary    *ArchitectureSummary `yaml:"summary" json:"summary"`
+	Violations []rules.Violation    `yaml:"violations,omitempty" json:"violations,omitempty"`
+}
+
+// ArchitectureSummary counts rule violations
+type ArchitectureSummary struct {
+	Layers       int            `yaml:"layers" json:"layers"`
+	Rules        int            `yaml:"rules" json:"rules"`
+	ErrorCount   int            `yaml:"error_count" json:"error_count"`
+	WarningCount int            `yaml:"warning_count" json:"warning_count"`
+	ByRule       map[string]int `yaml:"by_rule,omitempty" json:"by_rule,omitempty"`
+	PassStatus   string         `yaml:"pass_status" json:"pass_status"` // pass, warnings, fail
+}
+
+// runArchitectureCheck evaluates the rules section of the config against
+// every code dependency in the graph. Exit codes follow cx guard.
+func runArchitectureCheck(cmd *cobra.Command) error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cmd.Flags().Changed("fail-on-warnings") {
+		guardFailOnWarnings = cfg.Guard.FailOnWarnings
+	}
+	rc := cfg.Rules
+	ruleCount := len(rc.Allow) + len(rc.Forbid) + len(rc.Only)
+	if ruleCount == 0 {
+		return fmt.Errorf("no architecture rules: add allow, forbid or only rules under 'rules:' in .cx/config.yaml")
+	}
+	set, err := rules.Compile(rc)
+	if err != nil {
+		return fmt.Errorf("invalid rules: %w", err)
+	}
+
+	cxDir, err := config.FindConfigDir(".")
+	if err != nil {
+		return fmt.Errorf("cx not initialized: run 'cx scan' first")
+	}
+	storeDB, err := store.Open(cxDir)
+	if err != nil {
+		return fmt.Errorf("failed to open store: %w", err)
+	}
+	defer storeDB.Close()
+
+	violations, err := rules.Check(storeDB, set, rules.CheckOptions{ProjectRoot: filepath.Dir(cxDir)})
+	if err != nil {
+		return err
+	}
+
+	result := &ArchitectureOutput{
+		Summary: &ArchitectureSummary{
+			Layers: len(rc.Layers),
+			Rules:  ruleCount,
+			ByRule: make(map[string]int),
+		},
+		Violations: violations,
+	}
+	for _, v := range violations {
+		if v.Severity == "error" {
+			result.Summary.ErrorCount++
+		} else {
+			result.Summary.WarningCount++
+		}
+		result.Summary.ByRule[v.Rule]++
+	}
+
+	exitCode := 0
+	switch {
+	case result.Summary.ErrorCount > 0:
+		exitCode = 2
+		result.Summary.PassStatus = "fail"
+	case result.Summary.WarningCount > 0 && guardFailOnWarnings:
+		exitCode = 1
+		result.Summary.PassStatus = "fail"
+	case result.Summary.WarningCount > 0:
+		result.Summary.PassStatus = "warnings"
+	default:
+		result.Summary.PassStatus = "pass"
+	}
+
+	if !(quiet && exitCode == 0) {
+		format, err := output.ParseFormat(outputFormat)
+		if err != nil {
+			return fmt.Errorf("invalid format: %w", err)
+		}
+		density, err := output.ParseDensity(outputDensity)
+		if err != nil {
+			return fmt.Errorf("invalid density: %w", err)
+		}
+		formatter, err := output.GetFormatter(format)
+		if err != nil {
+			return fmt.Errorf("failed to get formatter: %w", err)
+		}
+		if err := formatter.FormatToWriter(cmd.OutOrStdout(), result, density); err != nil {
+			return fmt.Errorf("failed to format output: %w", err)
+		}
+	}
+
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+	return nil
+}
@@ -78,6 +78,10 @@ func runBranch(cmd *cobra.Command, args []string) error {
 	}
 	defer st.Close()
 
+	if !st.SupportsVersionControl() {
+		return fmt.Errorf("branches require the dolt storage backend (storage.backend is %q)", st.Backend())
+	}
+
 	// Parse format
 	format, err := output.ParseFormat(outputFormat)
 	if err != nil {
@@ -104,7 +108,7 @@ func runBranch(cmd *cobra.Command, args []string) error {
 	return createBranch(cmd, st, branchName, branchFrom)
 }
 
-func listBranches(cmd *cobra.Command, st *store.Store, format output.Format) error {
+func listBranches(cmd *cobra.Command, st *store.SQLStore, format output.Format) error {
 	db := st.DB()
 
 	// Get current branch first
@@ -165,7 +169,7 @@ func listBranches(cmd *cobra.Command, st *store.Store, format output.Format) err
 	return formatter.FormatToWriter(cmd.OutOrStdout(), branchOutput, output.DensityMedium)
 }
 
-func createBranch(cmd *cobra.Command, st *store.Store, name string, fromRef string) error {
+func createBranch(cmd *cobra.Command, st *store.SQLStore, name string, fromRef string) error {
 	db := st.DB()
 
 	// Validate branch name
@@ -195,7 +199,7 @@ func createBranch(cmd *cobra.Command, st *store.Store, name string, fromRef stri
 	return nil
 }
 
-func deleteBranch(cmd *cobra.Command, st *store.Store, name string) error {
+func deleteBranch(cmd *cobra.Command, st *store.SQLStore, name string) error {
 	db := st.DB()
 
 	// Check if trying to delete current branch
@@ -222,7 +226,7 @@ func deleteBranch(cmd *cobra.Command, st *store.Store, name string) error {
 	return nil
 }
 
-func checkoutBranch(cmd *cobra.Command, st *store.Store, name string) error {
+func checkoutBranch(cmd *cobra.Command, st *store.SQLStore, name string) error {
 	db := st.DB()
 
 	// Validate branch name
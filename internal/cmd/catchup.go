@@ -214,7 +214,7 @@ func runIncrementalScan(cxDir string) (*scanResult, error) {
 	return result, nil
 }
 
-func getCatchupChanges(storeDB *store.Store, projectRoot string, compareRef string) (*CatchupChanges, error) {
+func getCatchupChanges(storeDB *store.SQLStore, projectRoot string, compareRef string) (*CatchupChanges, error) {
 	changes := &CatchupChanges{}
 
 	// Use Dolt diff if we have a compare ref
@@ -245,7 +245,7 @@ func getCatchupChanges(storeDB *store.Store, projectRoot string, compareRef stri
 	return changes, nil
 }
 
-func countKeystonesChanged(storeDB *store.Store, compareRef string) (int, error) {
+func countKeystonesChanged(storeDB *store.SQLStore, compareRef string) (int, error) {
 	if compareRef == "" {
 		return 0, nil
 	}
@@ -278,7 +278,7 @@ func countKeystonesChanged(storeDB *store.Store, compareRef string) (int, error)
 	return keystoneCount, nil
 }
 
-func getChangesByFile(storeDB *store.Store, compareRef string) ([]CatchupFileChange, error) {
+func getChangesByFile(storeDB *store.SQLStore, compareRef string) ([]CatchupFileChange, error) {
 	if compareRef == "" {
 		return nil, nil
 	}
@@ -19,16 +19,46 @@ Modes:
   cx check --test            Smart test selection (same as: cx test --diff)
   cx check --test --gaps     Coverage gap analysis
   cx check --coverage        Coverage summary
+  cx check --architecture    Check the whole graph against architecture rules
 
 The default (no args, no flags) runs the pre-commit guard on staged files.
 
+Architecture rules (.cx/config.yaml) are checked for changed entities by the
+guard, and for every dependency with --architecture:
+
+  rules:
+    layers:                    # a file belongs to the first layer it matches;
+      - name: cmd              # a path without wildcards matches its subtree
+        paths: [internal/cmd]
+      - name: service
+        paths: [internal/service/**]
+      - name: store
+        paths: [internal/store]
+    allow:                     # a layer with allow rules may only depend on
+      - from: cmd              # the layers listed (or itself, or unlayered code)
+        to: [service]
+    forbid:
+      - from: store
+        to: [cmd, service]
+        reason: store is the bottom layer
+    only:                      # only these callers may depend on the target
+      - target: "store#Open"
+        callers: [service]
+        severity: warning
+
+Selectors are a layer name or a path glob (** crosses directories), with an
+optional #Name glob for entity names. dep_types limits a rule to dependency
+types (calls, uses_type, implements, ...). Severity is error (default) or
+warning. Each violation reports the dependency's call-site line.
+
 Examples:
   cx check src/auth/login.go        # Safety check before modifying file
   cx check                          # Pre-commit guard (staged files)
   cx check --guard --all            # Guard all modified files
   cx check --test                   # Which tests to run for changes?
   cx check --test --gaps            # Coverage gaps analysis
-  cx check --test --run             # Run affected tests`,
+  cx check --test --run             # Run affected tests
+  cx check --architecture           # Full-repo architecture rule check`,
 	RunE: runCheck,
 }
 
@@ -37,6 +67,7 @@ var (
 	checkGuard    bool
 	checkTest     bool
 	checkCoverage bool
+	checkArch     bool
 	checkDepth    int // shared depth flag (sets testDepth or safeDepth based on mode)
 )
 
@@ -47,10 +78,11 @@ func init() {
 	checkCmd.Flags().BoolVar(&checkGuard, "guard", false, "Run pre-commit guard checks")
 	checkCmd.Flags().BoolVar(&checkTest, "test", false, "Run smart test selection")
 	checkCmd.Flags().BoolVar(&checkCoverage, "coverage", false, "Show coverage summary")
+	checkCmd.Flags().BoolVar(&checkArch, "architecture", false, "Check all dependencies against architecture rules")
 
 	// Pass-through guard flags
 	checkCmd.Flags().BoolVar(&guardAll, "all", false, "Check all modified files (with --guard)")
-	checkCmd.Flags().BoolVar(&guardFailOnWarnings, "fail-on-warnings", false, "Exit with error on warnings (with --guard)")
+	checkCmd.Flags().BoolVar(&guardFailOnWarnings, "fail-on-warnings", false, "Exit with error on warnings (with --guard or --architecture)")
 
 	// Pass-through test flags
 	checkCmd.Flags().BoolVar(&testShowGaps, "gaps", false, "Show coverage gaps (with --test)")
@@ -88,6 +120,9 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		testShowCoverage = true
 		return runTest(testCmd, args)
 	}
+	if checkArch {
+		return runArchitectureCheck(cmd)
+	}
 	if checkGuard {
 		return runGuard(guardCmd, args)
 	}
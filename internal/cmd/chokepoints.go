@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// An entity is a chokepoint when it dominates at least minChokepointDominated
+// entities and minChokepointShare of everything the roots reach
+const (
+	minChokepointDominated = 5
+	minChokepointShare     = 0.01
+)
+
+// maxDominatedExamples caps the dominated entities listed per chokepoint
+const maxDominatedExamples = 5
+
+// ChokepointsOutput is the result of cx find --chokepoints
+type ChokepointsOutput struct {
+	Roots       map[string]int    `yaml:"roots" json:"roots"` // entry points by kind
+	Reachable   int               `yaml:"reachable" json:"reachable"`
+	Chokepoints []ChokepointEntry `yaml:"chokepoints" json:"chokepoints"`
+	Bridges     []BridgeEntry     `yaml:"bridges,omitempty" json:"bridges,omitempty"`
+}
+
+// ChokepointEntry is an entity that every path from the roots to other
+// entities passes through
+type ChokepointEntry struct {
+	Name         string   `yaml:"name" json:"name"`
+	Type         string   `yaml:"type" json:"type"`
+	Location     string   `yaml:"location" json:"location"`
+	Dominates    int      `yaml:"dominates" json:"dominates"` // entities reachable from the roots only through it
+	Share        float64  `yaml:"share" json:"share"`         // of the reachable entities
+	Articulation bool     `yaml:"articulation,omitempty" json:"articulation,omitempty"`
+	CutsOff      int      `yaml:"cuts_off,omitempty" json:"cuts_off,omitempty"` // entities disconnected when it is removed
+	Guards       []string `yaml:"guards,omitempty" json:"guards,omitempty"`     // entities it immediately dominates
+}
+
+// BridgeEntry is a dependency whose removal splits the graph in two
+type BridgeEntry struct {
+	From    string `yaml:"from" json:"from"`
+	To      string `yaml:"to" json:"to"`
+	Smaller int    `yaml:"smaller_side" json:"smaller_side"`
+}
+
+// DominatorsOutput is the result of cx trace --dominators
+type DominatorsOutput struct {
+	Entity       string           `yaml:"entity" json:"entity"`
+	Location     string           `yaml:"location" json:"location"`
+	Reachable    bool             `yaml:"reachable" json:"reachable"`
+	Root         bool             `yaml:"root,omitempty" json:"root,omitempty"`
+	ReachedFrom  []string         `yaml:"reached_from,omitempty" json:"reached_from,omitempty"` // roots with a path to it
+	Dominators   []DominatorEntry `yaml:"dominators" json:"dominators"`                         // outermost first
+	Dominates    int              `yaml:"dominates" json:"dominates"`
+	Guards       []string         `yaml:"guards,omitempty" json:"guards,omitempty"`
+	Articulation bool             `yaml:"articulation,omitempty" json:"articulation,omitempty"`
+	CutsOff      int              `yaml:"cuts_off,omitempty" json:"cuts_off,omitempty"`
+}
+
+// DominatorEntry is one entity on every path from the roots to the target
+type DominatorEntry struct {
+	Name      string `yaml:"name" json:"name"`
+	Location  string `yaml:"location" json:"location"`
+	Dominates int    `yaml:"dominates" json:"dominates"`
+}
+
+// chokepointAnalysis is the dominator tree of the active graph from the
+// configured roots, with the articulation points of its undirected view
+type chokepointAnalysis struct {
+	roots    []entryRoot
+	kinds    map[string]string // root kind by entity ID
+	entities map[string]*store.Entity
+	graph    *graph.Graph
+	tree     *graph.DominatorTree
+	cuts     *graph.CutAnalysis
+}
+
+// analyzeChokepoints builds the analysis over the active entities of g
+func analyzeChokepoints(storeDB *store.SQLStore, g *graph.Graph, cfg *config.Config) (*chokepointAnalysis, error) {
+	roots, entities, err := loadRoots(storeDB, cfg.Roots)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]string, 0, len(entities))
+	for _, id := range g.Nodes() {
+		if entities[id] != nil {
+			active = append(active, id)
+		}
+	}
+	sub := g.Subgraph(active)
+	ids := make([]string, 0, len(roots))
+	kinds := make(map[string]string, len(roots))
+	for _, r := range roots {
+		ids = append(ids, r.entity.ID)
+		kinds[r.entity.ID] = r.kind
+	}
+	return &chokepointAnalysis{
+		roots:    roots,
+		kinds:    kinds,
+		entities: entities,
+		graph:    sub,
+		tree:     sub.Dominators(ids),
+		cuts:     sub.CutVertices(),
+	}, nil
+}
+
+// isChokepoint reports whether a non-root entity dominates enough of the
+// reachable graph to count as a chokepoint
+func (a *chokepointAnalysis) isChokepoint(id string) bool {
+	d := a.tree.Dominated(id)
+	return d >= minChokepointDominated && float64(d) >= minChokepointShare*float64(a.tree.Len()) && a.kinds[id] == ""
+}
+
+// share returns dominated entities as a fraction of the reachable ones
+func (a *chokepointAnalysis) share(id string) float64 {
+	if a.tree.Len() == 0 {
+		return 0
+	}
+	return math.Round(float64(a.tree.Dominated(id))/float64(a.tree.Len())*1000) / 1000
+}
+
+// label names an entity with its location
+func (a *chokepointAnalysis) label(id string) string {
+	if e := a.entities[id]; e != nil {
+		return fmt.Sprintf("%s (%s)", e.Name, formatStoreLocation(e))
+	}
+	return id
+}
+
+// guards lists the entities id immediately dominates, largest subtree first
+func (a *chokepointAnalysis) guards(id string) []string {
+	children := a.tree.Children(id)
+	sort.SliceStable(children, func(i, j int) bool {
+		return a.tree.Dominated(children[i]) > a.tree.Dominated(children[j])
+	})
+	var names []string
+	for i, c := range children {
+		if i == maxDominatedExamples {
+			break
+		}
+		if e := a.entities[c]; e != nil {
+			names = append(names, e.Name)
+		}
+	}
+	return names
+}
+
+// runFindChokepoints lists the chokepoints by the number of entities they dominate
+func runFindChokepoints(cmd *cobra.Command) error {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	density, err := output.ParseDensity(outputDensity)
+	if err != nil {
+		return fmt.Errorf("invalid density: %w", err)
+	}
+	cfg, err := config.Load(".")
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	g, err := graph.BuildFromStore(storeDB)
+	if err != nil {
+		return fmt.Errorf("failed to build graph: %w", err)
+	}
+	a, err := analyzeChokepoints(storeDB, g, cfg)
+	if err != nil {
+		return err
+	}
+	if len(a.tree.Roots) == 0 {
+		return fmt.Errorf("no entry points found for roots.kinds %v: configure roots.include in .cx/config.yaml", cfg.Roots.Kinds)
+	}
+
+	var ids []string
+	for id := range a.tree.Idom {
+		if a.isChokepoint(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		di, dj := a.tree.Dominated(ids[i]), a.tree.Dominated(ids[j])
+		if di != dj {
+			return di > dj
+		}
+		return ids[i] < ids[j]
+	})
+	if findTop > 0 && len(ids) > findTop {
+		ids = ids[:findTop]
+	}
+
+	result := &ChokepointsOutput{
+		Roots:       rootCounts(a.roots),
+		Reachable:   a.tree.Len(),
+		Chokepoints: make([]ChokepointEntry, 0, len(ids)),
+	}
+	for _, id := range ids {
+		e := a.entities[id]
+		cutsOff, articulation := a.cuts.Articulation[id]
+		result.Chokepoints = append(result.Chokepoints, ChokepointEntry{
+			Name:         e.Name,
+			Type:         e.EntityType,
+			Location:     formatStoreLocation(e),
+			Dominates:    a.tree.Dominated(id),
+			Share:        a.share(id),
+			Articulation: articulation,
+			CutsOff:      cutsOff,
+			Guards:       a.guards(id),
+		})
+	}
+	for _, b := range a.cuts.Bridges {
+		if b.Smaller < minChokepointDominated || (findTop > 0 && len(result.Bridges) >= findTop) {
+			break
+		}
+		result.Bridges = append(result.Bridges, BridgeEntry{From: a.label(b.From), To: a.label(b.To), Smaller: b.Smaller})
+	}
+
+	formatter, err := output.GetFormatter(format)
+	if err != nil {
+		return fmt.Errorf("failed to get formatter: %w", err)
+	}
+	return formatter.FormatToWriter(cmd.OutOrStdout(), result, density)
+}
+
+// traceDominators reports the entities on every path from the roots to an entity
+func traceDominators(query string, storeDB *store.SQLStore, g *graph.Graph, cfg *config.Config) (*DominatorsOutput, error) {
+	entity, err := resolveEntityByName(query, storeDB, "")
+	if err != nil {
+		return nil, err
+	}
+	a, err := analyzeChokepoints(storeDB, g, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	id := entity.ID
+	cutsOff, articulation := a.cuts.Articulation[id]
+	out := &DominatorsOutput{
+		Entity:       entity.Name,
+		Location:     formatStoreLocation(entity),
+		Reachable:    a.tree.Reachable(id),
+		Root:         a.kinds[id] != "",
+		Dominators:   []DominatorEntry{},
+		Dominates:    a.tree.Dominated(id),
+		Guards:       a.guards(id),
+		Articulation: articulation,
+		CutsOff:      cutsOff,
+	}
+	if !out.Reachable {
+		return out, nil
+	}
+	for _, d := range a.tree.DominatorsOf(id) {
+		de := DominatorEntry{Name: d, Dominates: a.tree.Dominated(d)}
+		if e := a.entities[d]; e != nil {
+			de.Name, de.Location = e.Name, formatStoreLocation(e)
+		}
+		out.Dominators = append(out.Dominators, de)
+	}
+
+	// Roots with a path to the entity
+	upstream := make(map[string]bool)
+	for _, u := range a.graph.ReverseTransitiveClosure(id) {
+		upstream[u] = true
+	}
+	upstream[id] = true
+	for _, r := range a.roots {
+		if upstream[r.entity.ID] && len(out.ReachedFrom) < maxDominatedExamples {
+			out.ReachedFrom = append(out.ReachedFrom, fmt.Sprintf("%s (%s)", r.entity.Name, r.kind))
+		}
+	}
+	return out, nil
+}
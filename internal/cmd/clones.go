@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/anthropics/cx/internal/extract"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// Defaults for clone detection by cx find --clones and cx guard
+const (
+	defaultCloneMinLines   = 6
+	defaultCloneSimilarity = 0.8
+)
+
+// maxClonePostings skips fingerprint hashes shared by more bodies than this
+// when looking for candidate pairs: they are boilerplate such as error checks
+const maxClonePostings = 50
+
+// ClonesOutput is the result of cx find --clones
+type ClonesOutput struct {
+	MinLines      int          `yaml:"min_lines" json:"min_lines"`
+	Similarity    float64      `yaml:"similarity" json:"similarity"`
+	Fingerprinted int          `yaml:"fingerprinted" json:"fingerprinted"` // functions compared
+	Groups        []CloneGroup `yaml:"groups" json:"groups"`
+}
+
+// CloneGroup is a set of functions whose bodies are copies of each other
+type CloneGroup struct {
+	Kind       string        `yaml:"kind" json:"kind"`             // identical, renamed or near
+	Similarity float64       `yaml:"similarity" json:"similarity"` // lowest of the pairs that joined the group
+	Lines      int           `yaml:"lines" json:"lines"`           // of all members
+	Members    []CloneMember `yaml:"members" json:"members"`
+}
+
+// CloneMember is one copy in a clone group
+type CloneMember struct {
+	Name     string `yaml:"name" json:"name"`
+	Type     string `yaml:"type" json:"type"`
+	Location string `yaml:"location" json:"location"`
+	Lines    int    `yaml:"lines" json:"lines"`
+}
+
+// cloneCandidate is a fingerprinted function body
+type cloneCandidate struct {
+	entity *store.Entity
+	hashes []uint32
+}
+
+// clonePair is two candidates, by index, with their similarity
+type clonePair struct {
+	a, b       int
+	similarity float64
+}
+
+// runFindClones lists groups of near-copied functions
+func runFindClones(cmd *cobra.Command) error {
+	if findCloneSimilarity <= 0 || findCloneSimilarity > 1 {
+		return fmt.Errorf("invalid --similarity %v: must be in (0, 1]", findCloneSimilarity)
+	}
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	density, err := output.ParseDensity(outputDensity)
+	if err != nil {
+		return fmt.Errorf("invalid density: %w", err)
+	}
+
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	candidates, err := loadCloneCandidates(storeDB, findCloneMinLines)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no fingerprinted functions of at least %d lines: run 'cx scan --force' to fingerprint all files", findCloneMinLines)
+	}
+
+	groups := groupClones(candidates, findClonePairs(candidates, findCloneSimilarity))
+	if findTop > 0 && len(groups) > findTop {
+		groups = groups[:findTop]
+	}
+	result := &ClonesOutput{
+		MinLines:      findCloneMinLines,
+		Similarity:    findCloneSimilarity,
+		Fingerprinted: len(candidates),
+		Groups:        groups,
+	}
+
+	formatter, err := output.GetFormatter(format)
+	if err != nil {
+		return fmt.Errorf("failed to get formatter: %w", err)
+	}
+	return formatter.FormatToWriter(cmd.OutOrStdout(), result, density)
+}
+
+// loadCloneCandidates returns the fingerprinted functions and methods of at
+// least minLines lines outside test files
+func loadCloneCandidates(storeDB *store.SQLStore, minLines int) ([]cloneCandidate, error) {
+	fps, err := storeDB.GetFingerprints()
+	if err != nil {
+		return nil, err
+	}
+	entities, err := storeDB.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+	byID := make(map[string]*store.Entity, len(entities))
+	for _, e := range entities {
+		byID[e.ID] = e
+	}
+	var candidates []cloneCandidate
+	for _, fp := range fps {
+		e := byID[fp.EntityID]
+		if e == nil {
+			continue
+		}
+		if entityLines(e) < minLines || extract.IsTestFile(e.FilePath, e.Language) {
+			continue
+		}
+		candidates = append(candidates, cloneCandidate{entity: e, hashes: fp.Hashes})
+	}
+	return candidates, nil
+}
+
+// entityLines is the number of lines an entity spans
+func entityLines(e *store.Entity) int {
+	if e.LineEnd != nil && *e.LineEnd >= e.LineStart {
+		return *e.LineEnd - e.LineStart + 1
+	}
+	return 1
+}
+
+// findClonePairs returns the pairs of candidates at least threshold similar.
+// Candidate pairs share a fingerprint hash that is not boilerplate, and are
+// compared only when their sizes allow the threshold.
+func findClonePairs(candidates []cloneCandidate, threshold float64) []clonePair {
+	postings := make(map[uint32][]int)
+	for i, c := range candidates {
+		for _, h := range c.hashes {
+			postings[h] = append(postings[h], i)
+		}
+	}
+
+	var pairs []clonePair
+	for i, c := range candidates {
+		seen := make(map[int]bool)
+		for _, h := range c.hashes {
+			list := postings[h]
+			if len(list) > maxClonePostings {
+				continue
+			}
+			for _, j := range list {
+				if j <= i || seen[j] {
+					continue
+				}
+				seen[j] = true
+				small, large := len(c.hashes), len(candidates[j].hashes)
+				if small > large {
+					small, large = large, small
+				}
+				if float64(small) < threshold*float64(large) {
+					continue // Jaccard cannot exceed small/large
+				}
+				if sim := extract.FingerprintSimilarity(c.hashes, candidates[j].hashes); sim >= threshold {
+					pairs = append(pairs, clonePair{a: i, b: j, similarity: sim})
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// groupClones joins clone pairs transitively into groups, largest
+// duplication first
+func groupClones(candidates []cloneCandidate, pairs []clonePair) []CloneGroup {
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	lowest := make(map[int]float64)
+	for _, p := range pairs {
+		ra, rb := find(p.a), find(p.b)
+		low := p.similarity
+		if s, ok := lowest[ra]; ok {
+			low = math.Min(low, s)
+		}
+		if s, ok := lowest[rb]; ok {
+			low = math.Min(low, s)
+		}
+		if ra != rb {
+			parent[rb] = ra
+			delete(lowest, rb)
+		}
+		lowest[ra] = low
+	}
+
+	members := make(map[int][]int)
+	for i := range candidates {
+		if _, ok := lowest[find(i)]; ok {
+			members[find(i)] = append(members[find(i)], i)
+		}
+	}
+	groups := make([]CloneGroup, 0, len(members))
+	for root, ids := range members {
+		g := CloneGroup{Similarity: math.Round(lowest[root]*100) / 100}
+		for _, i := range ids {
+			e := candidates[i].entity
+			lines := entityLines(e)
+			g.Lines += lines
+			g.Members = append(g.Members, CloneMember{
+				Name:     e.Name,
+				Type:     e.EntityType,
+				Location: formatStoreLocation(e),
+				Lines:    lines,
+			})
+		}
+		sort.Slice(g.Members, func(i, j int) bool { return g.Members[i].Location < g.Members[j].Location })
+		g.Kind = cloneKind(candidates, ids, lowest[root])
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Lines != groups[j].Lines {
+			return groups[i].Lines > groups[j].Lines
+		}
+		return groups[i].Members[0].Location < groups[j].Members[0].Location
+	})
+	return groups
+}
+
+// cloneKind classifies a group: identical bodies (Type-1), the same
+// structure with renamed identifiers or changed literals (Type-2), or near
+// copies with edits (Type-3)
+func cloneKind(candidates []cloneCandidate, ids []int, similarity float64) string {
+	if similarity < 1 {
+		return "near"
+	}
+	body := candidates[ids[0]].entity.BodyHash
+	for _, i := range ids[1:] {
+		if h := candidates[i].entity.BodyHash; h == "" || h != body || extract.IsEmptyHash(h) {
+			return "renamed"
+		}
+	}
+	return "identical"
+}
+
+// nearestClone returns the candidate most similar to a fingerprint, at
+// least threshold similar, skipping candidates for which skip is true
+func nearestClone(hashes []uint32, candidates []cloneCandidate, threshold float64, skip func(*store.Entity) bool) (*store.Entity, float64) {
+	var best *store.Entity
+	bestSim := 0.0
+	for _, c := range candidates {
+		if skip(c.entity) {
+			continue
+		}
+		if sim := extract.FingerprintSimilarity(hashes, c.hashes); sim >= threshold && sim > bestSim {
+			best, bestSim = c.entity, sim
+		}
+	}
+	return best, bestSim
+}
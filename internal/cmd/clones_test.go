@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+)
+
+func TestGroupClones(t *testing.T) {
+	candidate := func(name string, line int, body string, hashes ...uint32) cloneCandidate {
+		end := line + 9
+		return cloneCandidate{
+			entity: &store.Entity{Name: name, EntityType: "function", FilePath: "pkg/a.go", LineStart: line, LineEnd: &end, BodyHash: body},
+			hashes: hashes,
+		}
+	}
+	candidates := []cloneCandidate{
+		candidate("parseA", 1, "aaaa1111", 1, 2, 3, 4, 5),
+		candidate("parseB", 20, "aaaa1111", 1, 2, 3, 4, 5),
+		candidate("parseC", 40, "bbbb2222", 1, 2, 3, 4, 5, 6), // edited copy
+		candidate("encode", 60, "cccc3333", 7, 8, 9, 10),
+		candidate("decode", 80, "dddd4444", 7, 8, 9, 10), // renamed copy
+		candidate("other", 100, "eeee5555", 2, 11, 12, 13),
+	}
+
+	groups := groupClones(candidates, findClonePairs(candidates, 0.8))
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(groups), groups)
+	}
+
+	first := groups[0]
+	if len(first.Members) != 3 || first.Kind != "near" || first.Similarity != 0.83 || first.Lines != 30 {
+		t.Errorf("first group = %+v", first)
+	}
+	if first.Members[0].Location != "pkg/a.go:1-10" {
+		t.Errorf("first member = %+v", first.Members[0])
+	}
+	second := groups[1]
+	if len(second.Members) != 2 || second.Kind != "renamed" || second.Similarity != 1 {
+		t.Errorf("second group = %+v", second)
+	}
+
+	// The identical pair alone is a Type-1 clone
+	identical := groupClones(candidates[:2], findClonePairs(candidates[:2], 0.8))
+	if len(identical) != 1 || identical[0].Kind != "identical" {
+		t.Errorf("identical groups = %+v", identical)
+	}
+}
+
+func TestNearestClone(t *testing.T) {
+	end := 10
+	existing := []cloneCandidate{
+		{entity: &store.Entity{Name: "Load", FilePath: "a.go", LineStart: 1, LineEnd: &end}, hashes: []uint32{1, 2, 3, 4}},
+		{entity: &store.Entity{Name: "Save", FilePath: "b.go", LineStart: 1, LineEnd: &end}, hashes: []uint32{1, 2, 3, 4, 5}},
+	}
+	none := func(*store.Entity) bool { return false }
+
+	got, sim := nearestClone([]uint32{1, 2, 3, 4}, existing, 0.8, none)
+	if got == nil || got.Name != "Load" || sim != 1 {
+		t.Errorf("nearestClone = %v, %v; want Load, 1", got, sim)
+	}
+	skipLoad := func(e *store.Entity) bool { return e.Name == "Load" }
+	if got, sim := nearestClone([]uint32{1, 2, 3, 4}, existing, 0.8, skipLoad); got == nil || got.Name != "Save" || sim != 0.8 {
+		t.Errorf("nearestClone skipping Load = %v, %v; want Save, 0.8", got, sim)
+	}
+	if got, _ := nearestClone([]uint32{9, 10}, existing, 0.8, none); got != nil {
+		t.Errorf("nearestClone of unrelated = %v, want nil", got)
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/anthropics/cx/internal/diff"
+	"github.com/anthropics/cx/internal/metrics"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/store"
+)
+
+const (
+	// coChangeCommits is how many commits of git history are mined for
+	// file co-changes
+	coChangeCommits = 1000
+
+	// coChangeScans is how many scans of history are mined for entity
+	// co-changes
+	coChangeScans = 50
+
+	// coChangeMaxEntities skips scans that changed more entities: one scan
+	// can cover many commits, so its change set is larger than a commit's
+	coChangeMaxEntities = 100
+
+	// strongCoChange is the confidence from which a counterpart is reported
+	// by cx safe, cx impact and cx context --for
+	strongCoChange = 0.5
+
+	// maxCoChanges caps the counterparts reported for one target
+	maxCoChanges = 10
+)
+
+// recordCoChanges mines co-change edges from git history (files) and scan
+// history (entities) and stores them, after recording the entities scanID
+// changed. Returns the number of edges stored at each level. Without git
+// history the file level is left untouched.
+func recordCoChanges(storeDB *store.SQLStore, projectRoot string, scanID int) (files, entities int, err error) {
+	cfg := metrics.DefaultCoChangeConfig()
+
+	if sets, gitErr := diff.NewGitDiff(projectRoot).CommitFileSets(coChangeCommits); gitErr == nil {
+		edges := coChangeEdges(metrics.ComputeCoChanges(sets, cfg))
+		if err := storeDB.SaveCoChanges(store.CoChangeFile, edges); err != nil {
+			return 0, 0, err
+		}
+		files = len(edges)
+	}
+
+	if err := storeDB.RecordEntityChangeSet(scanID, coChangeScans); err != nil {
+		return files, 0, fmt.Errorf("entity history: %w", err)
+	}
+	sets, err := storeDB.EntityChangeSets(coChangeScans)
+	if err != nil {
+		return files, 0, fmt.Errorf("entity history: %w", err)
+	}
+	cfg.MaxSetSize = coChangeMaxEntities
+	edges := coChangeEdges(metrics.ComputeCoChanges(sets, cfg))
+	if err := storeDB.SaveCoChanges(store.CoChangeEntity, edges); err != nil {
+		return files, 0, err
+	}
+	return files, len(edges), nil
+}
+
+// coChangeEdges converts mined rules to store edges
+func coChangeEdges(rules []metrics.CoChange) []store.CoChange {
+	edges := make([]store.CoChange, 0, len(rules))
+	for _, r := range rules {
+		edges = append(edges, store.CoChange{From: r.From, To: r.To, Support: r.Support, Confidence: r.Confidence})
+	}
+	return edges
+}
+
+// strongCoChanges returns the files and entities that change together with
+// the target entities in at least strongCoChange of their changes, strongest
+// first. Counterparts in skip (file paths or entity IDs) and the targets
+// themselves are left out, so callers can drop what a dependency edge
+// already explains.
+func strongCoChanges(storeDB *store.SQLStore, targets []*store.Entity, skip map[string]bool) []*output.CoChangeEntry {
+	targetFiles := make(map[string]bool)
+	var files, ids []string
+	names := make(map[string]string, len(targets))
+	for _, e := range targets {
+		names[e.ID] = e.Name
+		ids = append(ids, e.ID)
+		if path := filepath.ToSlash(e.FilePath); !targetFiles[path] {
+			targetFiles[path] = true
+			files = append(files, path)
+		}
+	}
+
+	var result []*output.CoChangeEntry
+	seen := make(map[string]bool)
+
+	fileEdges, _ := storeDB.GetCoChanges(store.CoChangeFile, files, strongCoChange)
+	for _, c := range fileEdges {
+		if targetFiles[c.To] || skip[c.To] || seen[c.To] {
+			continue
+		}
+		seen[c.To] = true
+		result = append(result, &output.CoChangeEntry{
+			Name:       c.To,
+			Level:      store.CoChangeFile,
+			With:       c.From,
+			Support:    c.Support,
+			Confidence: c.Confidence,
+		})
+	}
+
+	entityEdges, _ := storeDB.GetCoChanges(store.CoChangeEntity, ids, strongCoChange)
+	for _, c := range entityEdges {
+		if names[c.To] != "" || skip[c.To] || seen[c.To] {
+			continue
+		}
+		e, err := storeDB.GetEntity(c.To)
+		if err != nil || e == nil || e.Status != "active" {
+			continue
+		}
+		seen[c.To] = true
+		result = append(result, &output.CoChangeEntry{
+			Name:       e.Name,
+			Level:      store.CoChangeEntity,
+			ID:         e.ID,
+			Location:   formatStoreLocation(e),
+			With:       names[c.From],
+			Support:    c.Support,
+			Confidence: c.Confidence,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if a.Support != b.Support {
+			return a.Support > b.Support
+		}
+		return a.Name < b.Name
+	})
+	if len(result) > maxCoChanges {
+		result = result[:maxCoChanges]
+	}
+	return result
+}
+
+// coChangeReason describes a co-change counterpart for reason fields
+func coChangeReason(c *output.CoChangeEntry) string {
+	unit := "commits"
+	if c.Level == store.CoChangeEntity {
+		unit = "scans"
+	}
+	return fmt.Sprintf("Changes with %s in %.0f%% of its %s (%d together)", c.With, c.Confidence*100, unit, c.Support)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -15,6 +16,7 @@ import (
 	"github.com/anthropics/cx/internal/integration"
 	"github.com/anthropics/cx/internal/output"
 	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
 	"github.com/spf13/cobra"
 )
 
@@ -49,9 +51,27 @@ Smart Context (--smart):
   Uses intent extraction, keyword search, and flow tracing to assemble
   focused context within the token budget.
 
+  --rank ppr (default) ranks code by personalized PageRank: a random walk
+  that restarts at the entry points and follows dependencies weighted by
+  type (calls over shared types over imports). Each entity reports its
+  ppr score. --rank hops expands breadth-first up to --depth hops with
+  global PageRank as the tiebreaker.
+
+  --pack fills the budget with source instead of bare entries: the full
+  body of the most relevant code, the signature and doc comment of the
+  next, one-line skeletons of the rest, chosen by relevance per token.
+  Each entity reports its tier; ask for a body with --expand <name|id>.
+
+  --session <id> makes calls stateful: entities delivered earlier in the
+  session with the same body are listed by ID under already_provided
+  instead of being sent again, and the rest report a delta of new or
+  changed. With --pack the budget goes to what was not provided yet.
+  Sessions live in .cx/sessions; --session-reset starts one over.
+
   cx context --smart "add rate limiting to API endpoints" --budget 8000
   cx context --smart "fix auth bug in login" --budget 6000
   cx context --smart "optimize database queries" --budget 10000
+  cx context --smart "fix auth bug in login" --pack --expand LoginUser
 
 Diff-Based Context (--diff, --staged, --commit-range):
   Get context focused on code changes. Analyzes git diff to identify:
@@ -142,6 +162,11 @@ var (
 	contextFor          string // --for <file|entity|dir>: file-targeted context (pure graph)
 	contextSmart        string
 	contextDepth        int
+	contextRank         string
+	contextPack         bool     // --pack: progressive-detail source in --smart
+	contextExpand       []string // --expand: entities to pack in full
+	contextSession      string   // --session: send only what the session has not seen
+	contextSessionReset bool     // --session-reset: forget the session's deliveries
 	contextWithCoverage bool
 	contextFull         bool   // For session recovery mode (--full)
 	contextDiff         bool   // For diff-based context (uncommitted changes)
@@ -165,7 +190,12 @@ func init() {
 
 	// Smart context flags
 	contextCmd.Flags().StringVar(&contextSmart, "smart", "", "Natural language task description for intent-aware context assembly")
-	contextCmd.Flags().IntVar(&contextDepth, "depth", 2, "Max hops from entry points for --smart mode")
+	contextCmd.Flags().IntVar(&contextDepth, "depth", 2, "Max hops from entry points for --smart --rank hops")
+	contextCmd.Flags().StringVar(&contextRank, "rank", context.RankPPR, "Expansion for --smart: ppr (personalized PageRank) or hops")
+	contextCmd.Flags().BoolVar(&contextPack, "pack", false, "Pack source for --smart: full bodies, signatures or skeletons by relevance per token")
+	contextCmd.Flags().StringSliceVar(&contextExpand, "expand", nil, "Entities (IDs or names) to pack with full bodies (implies --pack)")
+	contextCmd.Flags().StringVar(&contextSession, "session", "", "Session ID for --smart: send only entities new or changed since earlier calls")
+	contextCmd.Flags().BoolVar(&contextSessionReset, "session-reset", false, "Forget what --session delivered, e.g. after the agent's context was cleared")
 
 	// Coverage flag
 	contextCmd.Flags().BoolVar(&contextWithCoverage, "with-coverage", false, "Include test coverage data for each entity")
@@ -195,6 +225,13 @@ type contextEntry struct {
 }
 
 func runContext(cmd *cobra.Command, args []string) error {
+	if (contextSession != "" || contextSessionReset) && contextSmart == "" {
+		return fmt.Errorf("--session requires --smart")
+	}
+	if contextSessionReset && contextSession == "" {
+		return fmt.Errorf("--session-reset requires --session")
+	}
+
 	// Handle --for mode (file-targeted context, pure graph traversal)
 	if contextFor != "" {
 		return runForContext(cmd, contextFor)
@@ -229,6 +266,10 @@ func runContext(cmd *cobra.Command, args []string) error {
 	if contextBudget != "importance" && contextBudget != "distance" {
 		return fmt.Errorf("invalid budget-mode %q: must be importance or distance", contextBudget)
 	}
+	counter, err := loadTokenizer()
+	if err != nil {
+		return err
+	}
 
 	// Parse format and density
 	format, err := output.ParseFormat(outputFormat)
@@ -401,7 +442,7 @@ func runContext(cmd *cobra.Command, args []string) error {
 				}
 			}
 		}
-		entry.tokens = estimateTokensStore(entry, density)
+		entry.tokens = estimateTokensStore(counter, entry, density)
 	}
 
 	// Apply token budget
@@ -420,6 +461,7 @@ func runContext(cmd *cobra.Command, args []string) error {
 			Target:     target,
 			Budget:     contextMaxTokens,
 			TokensUsed: tokensUsed,
+			Tokenizer:  counter.Name(),
 		},
 		EntryPoints: make(map[string]*output.EntryPoint),
 		Relevant:    make(map[string]*output.RelevantEntity),
@@ -597,46 +639,23 @@ func computeImportanceScore(pageRank float64, inDegree int) float64 {
 	return pageRank*0.7 + degreeScore*0.3
 }
 
-// estimateTokensStore estimates the token count for an entry at given density
-func estimateTokensStore(entry *contextEntry, density output.Density) int {
+// estimateTokensStore counts the tokens an entry costs at the given density
+func estimateTokensStore(counter tokens.Counter, entry *contextEntry, density output.Density) int {
 	if entry == nil {
 		return 0
 	}
 
-	// Base token estimates by density
-	base := 10 // Minimal overhead
-
 	// For task entries
 	if entry.isTask && entry.beadInfo != nil {
-		titleTokens := len(strings.Fields(entry.beadInfo.Title)) + 2
-		descTokens := len(strings.Fields(entry.beadInfo.Description))
-		return base + titleTokens + descTokens + 20
+		return counter.Count(fmt.Sprintf("task:\n  title: %s\n  description: %s\n",
+			entry.beadInfo.Title, entry.beadInfo.Description))
 	}
 
 	// For code entities
 	if entry.entity == nil {
 		return 0
 	}
-
-	// Add tokens based on content
-	titleTokens := len(strings.Fields(entry.entity.Name)) + 2
-
-	switch {
-	case density == output.DensitySparse:
-		// Just marker, location, name
-		return base + titleTokens + 5
-	case density == output.DensityMedium || density == output.DensitySmart:
-		// Add signature info
-		sigTokens := len(strings.Fields(entry.entity.Signature)) + 5
-		return base + titleTokens + sigTokens + 10
-	case density == output.DensityDense:
-		// Full signature, metrics, edges
-		sigTokens := len(strings.Fields(entry.entity.Signature))
-		metricsTokens := 15 // importance, pr, deps, betw
-		return base + titleTokens + sigTokens + metricsTokens + 20
-	}
-
-	return base + titleTokens
+	return estimateTokensForEntity(counter, entry.entity, density)
 }
 
 // applyTokenBudget trims entries to fit within budget
@@ -730,7 +749,8 @@ func filterEntries(entries []*contextEntry, pred func(*contextEntry) bool) []*co
 
 // runForContext handles the --for flag for file-targeted context gathering.
 // Pure graph traversal — no semantic/embedding search. Returns full neighborhood:
-// entities in target, direct callers, direct callees, related tests, sibling files, coverage.
+// entities in target, direct callers, direct callees, co-change counterparts,
+// related tests, sibling files, coverage.
 func runForContext(cmd *cobra.Command, target string) error {
 	format, err := output.ParseFormat(outputFormat)
 	if err != nil {
@@ -758,6 +778,11 @@ func runForContext(cmd *cobra.Command, target string) error {
 		return fmt.Errorf("failed to build graph: %w", err)
 	}
 
+	counter, err := loadTokenizer()
+	if err != nil {
+		return err
+	}
+
 	// Resolve target entities based on type: file path, directory, entity ID, or glob
 	rootEntities, err := resolveForTarget(storeDB, target)
 	if err != nil {
@@ -842,6 +867,24 @@ func runForContext(cmd *cobra.Command, target string) error {
 		}
 	}
 
+	// Priority 4: co-change counterparts — entities that history shows
+	// changing with the target although no edge links them. Files without
+	// entities (migrations, schemas) are reported under co_changes only.
+	skip := make(map[string]bool, len(seen))
+	for id := range seen {
+		skip[id] = true
+	}
+	coChanges := strongCoChanges(storeDB, rootEntities, skip)
+	for _, c := range coChanges {
+		if c.Level != store.CoChangeEntity || seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		if e, err := storeDB.GetEntity(c.ID); err == nil && e != nil {
+			entries = append(entries, bucketEntry{entity: e, reason: coChangeReason(c), priority: 4})
+		}
+	}
+
 	// Priority 4: related tests — find test entities in the same or _test file
 	// Skip for broad targets (tests are likely already in the root set)
 	if !isBroadTarget {
@@ -898,7 +941,7 @@ func runForContext(cmd *cobra.Command, target string) error {
 
 	dropped := 0
 	for _, be := range entries {
-		tokens := estimateTokensForEntity(be.entity, density)
+		tokens := estimateTokensForEntity(counter, be.entity, density)
 		if tokensUsed+tokens > contextMaxTokens && be.priority > 1 {
 			dropped++
 			continue
@@ -916,10 +959,12 @@ func runForContext(cmd *cobra.Command, target string) error {
 			Target:     target,
 			Budget:     contextMaxTokens,
 			TokensUsed: tokensUsed,
+			Tokenizer:  counter.Name(),
 		},
 		EntryPoints: make(map[string]*output.EntryPoint),
 		Relevant:    make(map[string]*output.RelevantEntity),
 		Excluded:    make(map[string]string),
+		CoChanges:   coChanges,
 	}
 
 	for _, be := range kept {
@@ -973,7 +1018,7 @@ func runForContext(cmd *cobra.Command, target string) error {
 
 // resolveForTarget resolves a --for target to a list of entities.
 // Accepts file paths, directory paths (with trailing /), entity IDs, and globs.
-func resolveForTarget(storeDB *store.Store, target string) ([]*store.Entity, error) {
+func resolveForTarget(storeDB *store.SQLStore, target string) ([]*store.Entity, error) {
 	// Check if it's an entity ID
 	if strings.HasPrefix(target, "sa-") {
 		e, err := storeDB.GetEntity(target)
@@ -1022,7 +1067,7 @@ func resolveForTarget(storeDB *store.Store, target string) ([]*store.Entity, err
 }
 
 // resolveGlobTarget resolves glob patterns to entities.
-func resolveGlobTarget(storeDB *store.Store, pattern string) ([]*store.Entity, error) {
+func resolveGlobTarget(storeDB *store.SQLStore, pattern string) ([]*store.Entity, error) {
 	// Get all file entries and match against glob
 	files, err := storeDB.GetAllFileEntries()
 	if err != nil {
@@ -1048,7 +1093,7 @@ func resolveGlobTarget(storeDB *store.Store, pattern string) ([]*store.Entity, e
 }
 
 // findRelatedTests finds test entities related to a given entity.
-func findRelatedTests(storeDB *store.Store, e *store.Entity) []*store.Entity {
+func findRelatedTests(storeDB *store.SQLStore, e *store.Entity) []*store.Entity {
 	// Look for _test file in same directory
 	dir := filepath.Dir(e.FilePath)
 	base := filepath.Base(e.FilePath)
@@ -1083,24 +1128,37 @@ func findRelatedTests(storeDB *store.Store, e *store.Entity) []*store.Entity {
 	return testEntities
 }
 
-// estimateTokensForEntity estimates tokens for a single entity at given density.
-func estimateTokensForEntity(e *store.Entity, density output.Density) int {
-	base := 10
-	nameTokens := len(strings.Fields(e.Name)) + 2
-	switch density {
-	case output.DensitySparse:
-		return base + nameTokens + 5
-	case output.DensityDense:
-		sigTokens := len(strings.Fields(e.Signature))
-		return base + nameTokens + sigTokens + 35
-	default: // medium, smart
-		sigTokens := len(strings.Fields(e.Signature)) + 5
-		return base + nameTokens + sigTokens + 10
+// estimateTokensForEntity counts the tokens of an entity's entry at the given
+// density: name, type and location, the signature unless sparse, and the
+// metrics when dense.
+func estimateTokensForEntity(counter tokens.Counter, e *store.Entity, density output.Density) int {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  %s:\n    type: %s\n    location: %s\n    relevance: medium\n    reason: Called by %s\n",
+		e.Name, e.EntityType, formatStoreLocation(e), e.Name)
+	if density != output.DensitySparse && e.Signature != "" {
+		fmt.Fprintf(&sb, "    signature: %s\n", e.Signature)
+	}
+	if density == output.DensityDense {
+		sb.WriteString("    metrics:\n      pagerank: 0.0125\n      in_degree: 12\n      out_degree: 4\n      betweenness: 0.0031\n")
+	}
+	return counter.Count(sb.String())
+}
+
+// loadTokenizer returns the token counter configured in .cx/config.yaml
+func loadTokenizer() (tokens.Counter, error) {
+	counter, err := tokens.NewFromConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tokenizer: %w", err)
 	}
+	return counter, nil
 }
 
 // runSmartContext handles the --smart flag for intent-aware context assembly.
 func runSmartContext(cmd *cobra.Command, taskDescription string) error {
+	if contextRank != context.RankPPR && contextRank != context.RankHops {
+		return fmt.Errorf("invalid --rank %q: must be ppr or hops", contextRank)
+	}
+
 	// Parse format and density
 	format, err := output.ParseFormat(outputFormat)
 	if err != nil {
@@ -1135,6 +1193,21 @@ func runSmartContext(cmd *cobra.Command, taskDescription string) error {
 	opts.TaskDescription = taskDescription
 	opts.Budget = contextMaxTokens
 	opts.Depth = contextDepth
+	opts.Rank = contextRank
+	opts.Pack = contextPack || len(contextExpand) > 0
+	opts.Expand = contextExpand
+	if opts.Tokenizer, err = loadTokenizer(); err != nil {
+		return err
+	}
+
+	if contextSession != "" {
+		if opts.Session, err = context.OpenSession(cxDir, contextSession); err != nil {
+			return err
+		}
+		if contextSessionReset {
+			opts.Session.Reset()
+		}
+	}
 
 	sc := context.NewSmartContext(storeDB, g, opts)
 
@@ -1143,6 +1216,11 @@ func runSmartContext(cmd *cobra.Command, taskDescription string) error {
 	if err != nil {
 		return fmt.Errorf("smart context assembly failed: %w", err)
 	}
+	if opts.Session != nil {
+		if err := opts.Session.Save(); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+	}
 
 	// Convert to SmartContextOutput for YAML/JSON output
 	smartOut := buildSmartContextOutput(result, density, storeDB)
@@ -1159,11 +1237,15 @@ func runSmartContext(cmd *cobra.Command, taskDescription string) error {
 // SmartContextOutput represents the output structure for smart context.
 type SmartContextOutput struct {
 	Intent       *SmartContextIntent               `yaml:"intent" json:"intent"`
+	Rank         string                            `yaml:"rank" json:"rank"`
 	EntryPoints  map[string]*output.EntryPoint     `yaml:"entry_points" json:"entry_points"`
 	Relevant     map[string]*output.RelevantEntity `yaml:"relevant_entities" json:"relevant_entities"`
 	Excluded     map[string]string                 `yaml:"excluded,omitempty" json:"excluded,omitempty"`
 	TokensUsed   int                               `yaml:"tokens_used" json:"tokens_used"`
 	TokensBudget int                               `yaml:"tokens_budget" json:"tokens_budget"`
+	Tokenizer    string                            `yaml:"tokenizer" json:"tokenizer"`
+	Session      string                            `yaml:"session,omitempty" json:"session,omitempty"`
+	Provided     []string                          `yaml:"already_provided,omitempty" json:"already_provided,omitempty"`
 	Warnings     []string                          `yaml:"warnings,omitempty" json:"warnings,omitempty"`
 }
 
@@ -1175,13 +1257,17 @@ type SmartContextIntent struct {
 }
 
 // buildSmartContextOutput converts SmartContextResult to output format.
-func buildSmartContextOutput(result *context.SmartContextResult, density output.Density, storeDB *store.Store) *SmartContextOutput {
+func buildSmartContextOutput(result *context.SmartContextResult, density output.Density, storeDB *store.SQLStore) *SmartContextOutput {
 	out := &SmartContextOutput{
+		Rank:         result.Rank,
 		EntryPoints:  make(map[string]*output.EntryPoint),
 		Relevant:     make(map[string]*output.RelevantEntity),
 		Excluded:     make(map[string]string),
 		TokensUsed:   result.TokensUsed,
 		TokensBudget: result.TokensBudget,
+		Tokenizer:    result.Tokenizer,
+		Session:      result.Session,
+		Provided:     result.AlreadyProvided,
 		Warnings:     result.Warnings,
 	}
 
@@ -1226,6 +1312,10 @@ func buildSmartContextOutput(result *context.SmartContextResult, density output.
 			Location:  re.Location,
 			Relevance: relevance,
 			Reason:    reason,
+			PPR:       math.Round(re.PPR*1e6) / 1e6,
+			Delta:     re.Delta,
+			Tier:      re.Tier,
+			Code:      re.Code,
 		}
 
 		// Add coverage data if --with-coverage flag is set
@@ -1247,7 +1337,7 @@ func buildSmartContextOutput(result *context.SmartContextResult, density output.
 // addCoverageToRelevantEntity adds coverage data to a RelevantEntity.
 // It retrieves coverage information from the database and sets the Coverage
 // and CoverageWarning fields on the entity.
-func addCoverageToRelevantEntity(relEntity *output.RelevantEntity, entityID string, pageRank float64, storeDB *store.Store) {
+func addCoverageToRelevantEntity(relEntity *output.RelevantEntity, entityID string, pageRank float64, storeDB *store.SQLStore) {
 	cov, err := coverage.GetEntityCoverage(storeDB, entityID)
 	if err != nil {
 		// No coverage data available for this entity
@@ -1521,6 +1611,9 @@ func runDiffContext(cmd *cobra.Command) error {
 	opts.Depth = contextDepth
 	opts.Staged = contextStaged
 	opts.CommitRange = contextCommitRange
+	if opts.Tokenizer, err = tokens.New(cfg.Tokenizer); err != nil {
+		return fmt.Errorf("failed to load tokenizer: %w", err)
+	}
 
 	// Create diff context assembler
 	dc := diff.NewDiffContext(storeDB, g, projectRoot, cfg, opts)
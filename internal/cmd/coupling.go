@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/metrics"
+	"github.com/anthropics/cx/internal/report"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// recordScanCoupling stores the package coupling metrics of a scan
+func recordScanCoupling(storeDB *store.SQLStore, scanID int) error {
+	packages, err := report.PackageCoupling(storeDB)
+	if err != nil {
+		return err
+	}
+	rows := make([]store.PackageMetrics, 0, len(packages))
+	for _, p := range packages {
+		rows = append(rows, store.PackageMetrics{
+			Package:       p.Package,
+			Entities:      p.Entities,
+			Types:         p.Types,
+			AbstractTypes: p.AbstractTypes,
+			Ca:            p.Ca,
+			Ce:            p.Ce,
+			Instability:   p.Instability,
+			Abstractness:  p.Abstractness,
+			Distance:      p.Distance,
+			Zone:          p.Zone,
+		})
+	}
+	return storeDB.SavePackageMetrics(scanID, rows)
+}
+
+// writeCouplingSection appends the package coupling table to a module guide,
+// farthest from the main sequence first, and with --format d2 the scatter
+// of packages over abstractness and instability.
+func writeCouplingSection(sb *strings.Builder, storeDB *store.SQLStore) error {
+	packages, err := report.PackageCoupling(storeDB)
+	if err != nil {
+		return err
+	}
+	var coupled []metrics.PackageCoupling
+	for _, p := range packages {
+		if p.Ca+p.Ce > 0 {
+			coupled = append(coupled, p)
+		}
+	}
+	if len(coupled) == 0 {
+		return nil
+	}
+	sort.SliceStable(coupled, func(i, j int) bool { return coupled[i].Distance > coupled[j].Distance })
+
+	sb.WriteString("## Package Coupling\n\n")
+	sb.WriteString("Ca counts outside entities that depend on the package, Ce package entities that depend on outside ones. ")
+	sb.WriteString("Instability I = Ce/(Ca+Ce), abstractness A is the share of interfaces and abstract types, ")
+	sb.WriteString("and D = |A+I-1| is the distance from the main sequence.\n\n")
+	sb.WriteString("| Package | Ca | Ce | I | A | D | Zone |\n")
+	sb.WriteString("|---------|----|----|---|---|---|------|\n")
+	for _, p := range coupled {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %.2f | %.2f | %.2f | %s |\n",
+			p.Package, p.Ca, p.Ce, p.Instability, p.Abstractness, p.Distance, p.Zone))
+	}
+	sb.WriteString("\n")
+
+	if guideFormat == "d2" {
+		points := make([]graph.CouplingPoint, 0, len(coupled))
+		for _, p := range coupled {
+			points = append(points, graph.CouplingPoint{Package: p.Package, Instability: p.Instability, Abstractness: p.Abstractness})
+		}
+		sb.WriteString("```d2\n")
+		sb.WriteString(graph.BuildCouplingDiagram(points, ""))
+		sb.WriteString("```\n\n")
+	}
+	return nil
+}
@@ -144,7 +144,7 @@ func runImport(cmd *cobra.Command, args []string) error {
 }
 
 // runImportCoverageOut handles traditional coverage.out file import
-func runImportCoverageOut(storeDB *store.Store, coverageFile, basePath string) error {
+func runImportCoverageOut(storeDB *store.SQLStore, coverageFile, basePath string) error {
 	// Parse coverage file
 	fmt.Fprintf(os.Stderr, "Parsing coverage file: %s\n", coverageFile)
 	coverageData, err := coverage.ParseCoverageFile(coverageFile)
@@ -180,7 +180,7 @@ func runImportCoverageOut(storeDB *store.Store, coverageFile, basePath string) e
 }
 
 // runImportPerTestDir handles a directory of per-test coverage.out files
-func runImportPerTestDir(storeDB *store.Store, dirPath, basePath string) error {
+func runImportPerTestDir(storeDB *store.SQLStore, dirPath, basePath string) error {
 	fmt.Fprintf(os.Stderr, "Parsing per-test coverage directory: %s\n", dirPath)
 
 	perTestData, err := coverage.ParsePerTestCoverageDir(dirPath)
@@ -227,7 +227,7 @@ func runImportPerTestDir(storeDB *store.Store, dirPath, basePath string) error {
 }
 
 // runImportGOCOVERDIR handles Go 1.20+ GOCOVERDIR import with per-test attribution
-func runImportGOCOVERDIR(storeDB *store.Store, dirPath, basePath string) error {
+func runImportGOCOVERDIR(storeDB *store.SQLStore, dirPath, basePath string) error {
 	fmt.Fprintf(os.Stderr, "Parsing GOCOVERDIR: %s\n", dirPath)
 
 	gocoverData, err := coverage.ParseGOCOVERDIR(dirPath)
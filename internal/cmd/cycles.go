@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// cycleLevels are the granularities cycles are reported and recorded at
+var cycleLevels = []string{"entity", "file", "package"}
+
+// maxCycleVia caps the example entity dependencies listed per cut edge
+const maxCycleVia = 3
+
+// CyclesOutput is the result of cx find --cycles
+type CyclesOutput struct {
+	Level   string         `yaml:"level" json:"level"`
+	Summary *CyclesSummary `yaml:"summary" json:"summary"`
+	Cycles  []CycleOutput  `yaml:"cycles,omitempty" json:"cycles,omitempty"`
+}
+
+// CyclesSummary counts cycles at one level
+type CyclesSummary struct {
+	Cycles        int    `yaml:"cycles" json:"cycles"`
+	NodesInCycles int    `yaml:"nodes_in_cycles" json:"nodes_in_cycles"`
+	Largest       int    `yaml:"largest" json:"largest"`
+	CutEdges      int    `yaml:"cut_edges" json:"cut_edges"`
+	Baseline      string `yaml:"baseline,omitempty" json:"baseline,omitempty"` // scan compared against
+	New           int    `yaml:"new,omitempty" json:"new,omitempty"`
+	Grew          int    `yaml:"grew,omitempty" json:"grew,omitempty"`
+	Shrank        int    `yaml:"shrank,omitempty" json:"shrank,omitempty"`
+	Resolved      int    `yaml:"resolved,omitempty" json:"resolved,omitempty"` // baseline cycles that are gone
+}
+
+// CycleOutput is one strongly connected component
+type CycleOutput struct {
+	Size         int        `yaml:"size" json:"size"`
+	Trend        string     `yaml:"trend,omitempty" json:"trend,omitempty"` // new, grew, shrank, unchanged
+	PreviousSize int        `yaml:"previous_size,omitempty" json:"previous_size,omitempty"`
+	Members      []string   `yaml:"members" json:"members"`
+	Edges        int        `yaml:"edges" json:"edges"`
+	Cut          []CycleCut `yaml:"cut" json:"cut"`
+}
+
+// CycleCut is a dependency to remove to break the cycle
+type CycleCut struct {
+	From   string   `yaml:"from" json:"from"`
+	To     string   `yaml:"to" json:"to"`
+	Weight int      `yaml:"weight,omitempty" json:"weight,omitempty"` // entity dependencies behind a file or package edge
+	Via    []string `yaml:"via,omitempty" json:"via,omitempty"`
+}
+
+// cycleGroups maps entity IDs to the node they belong to at a level: the
+// entity itself, its file, or its package directory. Inactive entities map
+// to "".
+func cycleGroups(entities map[string]*store.Entity, level string) func(string) string {
+	return func(id string) string {
+		e := entities[id]
+		if e == nil {
+			return ""
+		}
+		switch level {
+		case "file":
+			return e.FilePath
+		case "package":
+			return filepath.ToSlash(filepath.Dir(e.FilePath))
+		}
+		return id
+	}
+}
+
+// loadCycleGraph builds the dependency graph and the active entities it is
+// grouped by.
+func loadCycleGraph(s store.Store) (*graph.Graph, map[string]*store.Entity, error) {
+	g, err := graph.BuildFromStore(s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build graph: %w", err)
+	}
+	all, err := s.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query entities: %w", err)
+	}
+	entities := make(map[string]*store.Entity, len(all))
+	for _, e := range all {
+		entities[e.ID] = e
+	}
+	return g, entities, nil
+}
+
+// recordScanCycles stores the cycles at every level for a scan, so the next
+// cx find --cycles can tell which cycles grew.
+func recordScanCycles(storeDB *store.SQLStore, scanID int) error {
+	g, entities, err := loadCycleGraph(storeDB)
+	if err != nil {
+		return err
+	}
+	for _, level := range cycleLevels {
+		var members [][]string
+		for _, c := range g.Cycles(cycleGroups(entities, level)) {
+			members = append(members, c.Members)
+		}
+		if err := storeDB.SaveCycles(scanID, level, members); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runFindCycles reports every dependency cycle at the --level granularity
+func runFindCycles(cmd *cobra.Command) error {
+	if !slices.Contains(cycleLevels, findLevel) {
+		return fmt.Errorf("invalid --level %q: must be entity, file or package", findLevel)
+	}
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	density, err := output.ParseDensity(outputDensity)
+	if err != nil {
+		return fmt.Errorf("invalid density: %w", err)
+	}
+
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	g, entities, err := loadCycleGraph(storeDB)
+	if err != nil {
+		return err
+	}
+	groupOf := cycleGroups(entities, findLevel)
+	cycles := g.Cycles(groupOf)
+
+	// Compare with the scan before the latest one; the latest scan recorded
+	// the graph as it is now.
+	var baseline [][]string
+	hasBaseline := false
+	if latest, err := storeDB.GetLatestScanMetadata(); err == nil && latest != nil {
+		if prev, err := storeDB.PreviousScanID(latest.ID); err == nil && prev > 0 {
+			if baseline, err = storeDB.GetCycles(prev, findLevel); err == nil {
+				hasBaseline = true
+			}
+		}
+	}
+
+	result := &CyclesOutput{Level: findLevel, Summary: &CyclesSummary{Cycles: len(cycles)}}
+	if hasBaseline {
+		result.Summary.Baseline = "previous scan"
+	}
+	label := func(node string) string {
+		if findLevel == "entity" {
+			if e := entities[node]; e != nil {
+				return fmt.Sprintf("%s (%s)", e.Name, formatStoreLocation(e))
+			}
+		}
+		return node
+	}
+
+	current := make(map[string]bool)
+	for i, c := range cycles {
+		for _, m := range c.Members {
+			current[m] = true
+		}
+		cut := graph.FeedbackArcSet(c)
+		result.Summary.NodesInCycles += len(c.Members)
+		result.Summary.CutEdges += len(cut)
+		if len(c.Members) > result.Summary.Largest {
+			result.Summary.Largest = len(c.Members)
+		}
+
+		var trend string
+		var prevSize int
+		if hasBaseline {
+			trend, prevSize = graph.CycleTrend(c.Members, baseline)
+			switch trend {
+			case "new":
+				result.Summary.New++
+			case "grew":
+				result.Summary.Grew++
+			case "shrank":
+				result.Summary.Shrank++
+			}
+		}
+		if findTop > 0 && i >= findTop {
+			continue
+		}
+
+		co := CycleOutput{
+			Size:         len(c.Members),
+			Trend:        trend,
+			PreviousSize: prevSize,
+			Edges:        len(c.Edges),
+			Cut:          make([]CycleCut, 0, len(cut)),
+		}
+		for _, m := range c.Members {
+			co.Members = append(co.Members, label(m))
+		}
+		for _, e := range cut {
+			cc := CycleCut{From: label(e.From), To: label(e.To)}
+			if findLevel != "entity" {
+				cc.Weight = e.Weight
+				cc.Via = cycleCutVia(g, entities, groupOf, e)
+			}
+			co.Cut = append(co.Cut, cc)
+		}
+		result.Cycles = append(result.Cycles, co)
+	}
+
+	for _, prev := range baseline {
+		gone := true
+		for _, m := range prev {
+			if current[m] {
+				gone = false
+				break
+			}
+		}
+		if gone {
+			result.Summary.Resolved++
+		}
+	}
+
+	formatter, err := output.GetFormatter(format)
+	if err != nil {
+		return fmt.Errorf("failed to get formatter: %w", err)
+	}
+	return formatter.FormatToWriter(cmd.OutOrStdout(), result, density)
+}
+
+// cycleCutVia lists entity dependencies behind a file or package edge, so
+// the cut can be acted on.
+func cycleCutVia(g *graph.Graph, entities map[string]*store.Entity, groupOf func(string) string, e graph.WeightedEdge) []string {
+	var via []string
+	seen := make(map[string]bool)
+	for _, from := range g.Nodes() {
+		if groupOf(from) != e.From {
+			continue
+		}
+		for _, to := range g.Successors(from) {
+			if groupOf(to) != e.To {
+				continue
+			}
+			s := fmt.Sprintf("%s -> %s (%s)", entities[from].Name, entities[to].Name, formatStoreLocation(entities[from]))
+			if !seen[s] {
+				seen[s] = true
+				via = append(via, s)
+			}
+		}
+	}
+	sort.Strings(via)
+	if len(via) > maxCycleVia {
+		via = via[:maxCycleVia]
+	}
+	return via
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/anthropics/cx/internal/config"
 	"github.com/anthropics/cx/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -20,7 +21,8 @@ Examples:
   cx db doctor --fix              # Auto-fix health issues
   cx db compact                   # Reclaim unused space
   cx db compact --remove-archived # Remove archived entities first
-  cx db export -o backup.jsonl    # Export to JSONL file`,
+  cx db export -o backup.jsonl    # Export to JSONL file
+  cx db migrate --status          # Show schema migration status`,
 }
 
 var dbInfoCmd = &cobra.Command{
@@ -44,6 +46,22 @@ var dbExportCmd = &cobra.Command{
 	RunE:  runDbExport,
 }
 
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Long: `Apply pending schema migrations to the .cx database.
+
+Migrations are also applied automatically whenever cx opens the database;
+this command lets you inspect or apply them explicitly. On the Dolt backend
+each migration is recorded as its own commit.
+
+Examples:
+  cx admin db migrate              # Apply pending migrations
+  cx admin db migrate --status     # Show applied and pending migrations
+  cx admin db migrate --dry-run    # Show what would be applied`,
+	RunE: runDbMigrate,
+}
+
 // dbStatusCmd is a top-level alias for "db info" - more intuitive for AI agents
 var dbStatusCmd = &cobra.Command{
 	Use:     "status",
@@ -58,6 +76,8 @@ var (
 	compactRemoveArchived bool
 	compactDryRun         bool
 	exportOutput          string
+	migrateStatus         bool
+	migrateDryRun         bool
 )
 
 // dbDoctorCmd is an alias for the top-level doctor command
@@ -79,10 +99,12 @@ func init() {
 	dbCmd.AddCommand(dbCompactCmd)
 	dbCmd.AddCommand(dbExportCmd)
 	dbCmd.AddCommand(dbDoctorCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
 
 	dbCompactCmd.Flags().BoolVar(&compactRemoveArchived, "remove-archived", false, "Remove archived entities before compacting")
 	dbCompactCmd.Flags().BoolVar(&compactDryRun, "dry-run", false, "Show what would be done without making changes")
 	dbExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
+	addMigrateFlags(dbMigrateCmd)
 	// db doctor shares flags with top-level doctor command
 	dbDoctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Auto-fix issues found")
 	dbDoctorCmd.Flags().BoolVar(&doctorDeep, "deep", false, "Run deep checks including archived entity ratio")
@@ -304,6 +326,74 @@ func runDbExport(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// addMigrateFlags registers the migrate flags on cmd (shared by cx db and cx admin db).
+func addMigrateFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&migrateStatus, "status", false, "Show applied and pending migrations without applying them")
+	cmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show pending migrations without applying them")
+}
+
+func runDbMigrate(cmd *cobra.Command, args []string) error {
+	cxDir, err := config.FindConfigDir(".")
+	if err != nil {
+		return fmt.Errorf("cx not initialized: run 'cx scan' first")
+	}
+
+	s, err := store.OpenWithOptions(cxDir, store.OpenOptions{SkipMigrations: true})
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer s.Close()
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if migrateStatus {
+		status, err := s.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schema version: %d (latest: %d)\n\n", current, store.LatestSchemaVersion())
+		for _, m := range status {
+			state := "pending"
+			if m.Applied {
+				state = "applied " + m.AppliedAt
+			}
+			fmt.Printf("  %3d  %-40s %s\n", m.Version, m.Description, state)
+		}
+		return nil
+	}
+
+	pending, err := s.PendingMigrations()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Printf("Schema is up to date (version %d)\n", current)
+		return nil
+	}
+
+	if migrateDryRun {
+		fmt.Printf("[dry-run] Would apply %d migration(s) to schema version %d:\n", len(pending), current)
+		for _, m := range pending {
+			fmt.Printf("[dry-run]   %3d  %s\n", m.Version, m.Description)
+		}
+		fmt.Println("[dry-run] No changes made")
+		return nil
+	}
+
+	applied, err := s.Migrate()
+	for _, m := range applied {
+		fmt.Printf("Applied migration %d: %s\n", m.Version, m.Description)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema is now at version %d\n", store.LatestSchemaVersion())
+	return nil
+}
+
 // formatBytes formats a byte count into a human-readable string
 func formatBytes(bytes int64) string {
 	const unit = 1024
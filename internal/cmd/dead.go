@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/extract"
+	"github.com/anthropics/cx/internal/graph"
 	"github.com/anthropics/cx/internal/output"
 	"github.com/anthropics/cx/internal/store"
 	"github.com/spf13/cobra"
@@ -25,8 +29,18 @@ Confidence Tiers:
   Tier 1 (definite):   Private/unexported + zero callers. Safe to delete.
   Tier 2 (probable):   Exported + zero internal callers. May be used externally.
   Tier 3 (suspicious): All callers are themselves dead/suspicious. Dead in practice.
+  Tier 4 (unreachable): No path from any entry point. Catches code kept alive
+                        only by other dead code or by tests.
 
-Default: --tier 1 (only definite dead code). Use --tier 2 or --tier 3 for more.
+Default: --tier 1 (only definite dead code). Use --tier 2, 3 or 4 for more.
+
+Entry points come from the roots section of .cx/config.yaml: built-in
+kinds (main, commands, handlers, exported), selectors, tags (default:
+entrypoint, so 'cx tag add X entrypoint' keeps X alive) and annotation
+markers for reflection or dependency injection. They are never reported,
+and tier 4 follows dependencies from them. Methods are reachable with
+their receiver type. Each unreachable entity comes with a
+reason chain: its callers, followed back to where they start.
 
 Output Structure:
   dead_code:
@@ -44,6 +58,7 @@ Examples:
   cx dead                        # Find dead private code (tier 1)
   cx dead --tier 2               # Include unused exports (probable)
   cx dead --tier 3               # Include suspicious (all callers dead)
+  cx dead --tier 4               # Include unreachable from entry points
   cx dead --chains               # Group dead chains together
   cx dead --include-exports      # Include unused exports (legacy, same as --tier 2)
   cx dead --by-file              # Group by file
@@ -63,7 +78,7 @@ var (
 	deadByFile         bool
 	deadCreateTask     bool
 	deadTypeFilter     string
-	deadTier           int  // 1=definite, 2=probable, 3=suspicious
+	deadTier           int  // 1=definite, 2=probable, 3=suspicious, 4=unreachable
 	deadChains         bool // group dead chains together
 )
 
@@ -74,7 +89,7 @@ func init() {
 	deadCmd.Flags().BoolVar(&deadByFile, "by-file", false, "Group results by file path")
 	deadCmd.Flags().BoolVar(&deadCreateTask, "create-task", false, "Print bd create commands for cleanup")
 	deadCmd.Flags().StringVar(&deadTypeFilter, "type", "", "Filter by entity type (F=function, T=type, M=method, C=constant)")
-	deadCmd.Flags().IntVar(&deadTier, "tier", 1, "Confidence tier: 1=definite, 2=+probable, 3=+suspicious")
+	deadCmd.Flags().IntVar(&deadTier, "tier", 1, "Confidence tier: 1=definite, 2=+probable, 3=+suspicious, 4=+unreachable")
 	deadCmd.Flags().BoolVar(&deadChains, "chains", false, "Group dead chains together")
 }
 
@@ -83,11 +98,18 @@ type deadCodeItem struct {
 	entity     *store.Entity
 	metrics    *store.Metrics
 	reason     string
-	confidence string // "definite", "probable", "suspicious"
-	tier       int    // 1, 2, or 3
+	confidence string // "definite", "probable", "suspicious", "unreachable"
+	tier       int    // 1 to 4
 	chainID    int    // chain group ID (0 = no chain)
+
+	// reasonChain lists the callers of an unreachable entity, followed back
+	// to where they start
+	reasonChain []string
 }
 
+// maxReasonChain caps the callers followed back from an unreachable entity
+const maxReasonChain = 5
+
 func runDead(cmd *cobra.Command, args []string) error {
 	// Open store
 	storeDB, err := openStore()
@@ -123,8 +145,8 @@ func runDead(cmd *cobra.Command, args []string) error {
 	typeFilter := normalizeDeadTypeFilter(deadTypeFilter)
 
 	// Validate tier
-	if deadTier < 1 || deadTier > 3 {
-		return fmt.Errorf("invalid --tier %d: must be 1, 2, or 3", deadTier)
+	if deadTier < 1 || deadTier > 4 {
+		return fmt.Errorf("invalid --tier %d: must be 1, 2, 3 or 4", deadTier)
 	}
 
 	// Build metrics map and entity map for graph analysis
@@ -137,6 +159,20 @@ func runDead(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Entry points from the roots configuration are never dead
+	cfg, err := config.Load(".")
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	roots, _, err := loadRoots(storeDB, cfg.Roots)
+	if err != nil {
+		return err
+	}
+	rootIDs := make(map[string]bool, len(roots))
+	for _, r := range roots {
+		rootIDs[r.entity.ID] = true
+	}
+
 	// Build list of dead code across all tiers
 	var deadItems []deadCodeItem
 
@@ -145,7 +181,7 @@ func runDead(cmd *cobra.Command, args []string) error {
 
 	// --- Tier 1: Definite — private, zero callers ---
 	for _, e := range entities {
-		if e.EntityType == "import" || isKnownEntryPoint(e) {
+		if e.EntityType == "import" || isKnownEntryPoint(e) || rootIDs[e.ID] {
 			continue
 		}
 		if typeFilter != "" && !matchesDeadTypeFilter(e.EntityType, typeFilter) {
@@ -175,7 +211,7 @@ func runDead(cmd *cobra.Command, args []string) error {
 	// --- Tier 2: Probable — exported, zero internal callers ---
 	if deadTier >= 2 || deadIncludeExports {
 		for _, e := range entities {
-			if e.EntityType == "import" || isKnownEntryPoint(e) {
+			if e.EntityType == "import" || isKnownEntryPoint(e) || rootIDs[e.ID] {
 				continue
 			}
 			if typeFilter != "" && !matchesDeadTypeFilter(e.EntityType, typeFilter) {
@@ -213,7 +249,7 @@ func runDead(cmd *cobra.Command, args []string) error {
 		for changed {
 			changed = false
 			for _, e := range entities {
-				if e.EntityType == "import" || isKnownEntryPoint(e) {
+				if e.EntityType == "import" || isKnownEntryPoint(e) || rootIDs[e.ID] {
 					continue
 				}
 				if deadIDs[e.ID] {
@@ -257,6 +293,18 @@ func runDead(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// --- Tier 4: Unreachable — no path from any entry point ---
+	if deadTier >= 4 {
+		if len(roots) == 0 {
+			return fmt.Errorf("no entry points found for roots.kinds %v: configure roots in .cx/config.yaml", cfg.Roots.Kinds)
+		}
+		unreachable, err := unreachableItems(storeDB, roots, entityMap, metricsMap, deadIDs, typeFilter)
+		if err != nil {
+			return err
+		}
+		deadItems = append(deadItems, unreachable...)
+	}
+
 	// --- Dead Chain Detection ---
 	if deadChains {
 		assignDeadChains(deadItems, deadIDs, storeDB)
@@ -315,6 +363,160 @@ func runDead(cmd *cobra.Command, args []string) error {
 	return formatter.FormatToWriter(cmd.OutOrStdout(), outputData, output.DensityMedium)
 }
 
+// unreachableItems returns the entities with no path from the configured
+// roots that earlier tiers did not report. Test code is never reported but
+// does not keep anything alive either.
+func unreachableItems(storeDB *store.SQLStore, roots []entryRoot, entityMap map[string]*store.Entity, metricsMap map[string]*store.Metrics, deadIDs map[string]bool, typeFilter string) ([]deadCodeItem, error) {
+	g, err := graph.BuildFromStore(storeDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graph: %w", err)
+	}
+
+	reachable := reachableFromRoots(g, roots, entityMap)
+	var items []deadCodeItem
+	for id, e := range entityMap {
+		if reachable[id] || deadIDs[id] || e.EntityType == "import" || isKnownEntryPoint(e) || extract.IsTestFile(e.FilePath, e.Language) {
+			continue
+		}
+		if typeFilter != "" && !matchesDeadTypeFilter(e.EntityType, typeFilter) {
+			continue
+		}
+		m := metricsMap[id]
+		if m == nil {
+			continue
+		}
+		reason, chain := unreachableReason(id, g, entityMap)
+		items = append(items, deadCodeItem{
+			entity:      e,
+			metrics:     m,
+			reason:      reason,
+			confidence:  "unreachable",
+			tier:        4,
+			reasonChain: chain,
+		})
+		deadIDs[id] = true
+	}
+	return items, nil
+}
+
+// reachableFromRoots returns the entities reachable from the roots along
+// dependencies. A method is reachable with its receiver type, since calls
+// through an interface leave no edge to it, and a type with code in its
+// directory that constructs it (Name{), since composite literals leave none.
+func reachableFromRoots(g *graph.Graph, roots []entryRoot, entityMap map[string]*store.Entity) map[string]bool {
+	methods := make(map[string][]string)
+	types := make(map[string][]*store.Entity) // by directory
+	for id, e := range entityMap {
+		switch e.EntityType {
+		case "method":
+			if e.Receiver != "" {
+				key := receiverKey(filepath.Dir(e.FilePath), e.Receiver)
+				methods[key] = append(methods[key], id)
+			}
+		case "type":
+			dir := filepath.Dir(e.FilePath)
+			types[dir] = append(types[dir], e)
+		}
+	}
+
+	reachable := make(map[string]bool)
+	var stack []string
+	visit := func(id string) {
+		if !reachable[id] && entityMap[id] != nil {
+			reachable[id] = true
+			stack = append(stack, id)
+		}
+	}
+	for _, r := range roots {
+		visit(r.entity.ID)
+	}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range g.Successors(id) {
+			visit(next)
+		}
+		e := entityMap[id]
+		dir := filepath.Dir(e.FilePath)
+		if e.EntityType == "type" {
+			for _, m := range methods[receiverKey(dir, e.Name)] {
+				visit(m)
+			}
+		}
+		for _, t := range types[dir] {
+			if !reachable[t.ID] && strings.Contains(e.BodyText, t.Name+"{") {
+				visit(t.ID)
+			}
+		}
+	}
+	return reachable
+}
+
+// receiverKey identifies a receiver type within its directory, ignoring
+// pointers, receiver names and type parameters
+func receiverKey(dir, receiver string) string {
+	fields := strings.Fields(strings.Trim(receiver, "()"))
+	if len(fields) > 0 {
+		receiver = fields[len(fields)-1]
+	}
+	receiver = strings.TrimLeft(receiver, "*&")
+	if i := strings.IndexByte(receiver, '['); i >= 0 {
+		receiver = receiver[:i]
+	}
+	return dir + "#" + receiver
+}
+
+// unreachableReason follows the callers of an unreachable entity back to
+// where they start: code with no callers, test code or a cycle. It returns
+// the reason and the callers followed, nearest first.
+func unreachableReason(id string, g *graph.Graph, entityMap map[string]*store.Entity) (string, []string) {
+	seen := map[string]bool{id: true}
+	var chain []string
+	cur := id
+	for len(chain) < maxReasonChain {
+		var callers []*store.Entity
+		for _, p := range g.Predecessors(cur) {
+			if e := entityMap[p]; e != nil {
+				callers = append(callers, e)
+			}
+		}
+		if len(callers) == 0 {
+			break
+		}
+		// Prefer callers not yet on the chain, then test code, then by name
+		sort.Slice(callers, func(i, j int) bool {
+			a, b := callers[i], callers[j]
+			if seen[a.ID] != seen[b.ID] {
+				return !seen[a.ID]
+			}
+			if ta, tb := extract.IsTestFile(a.FilePath, a.Language), extract.IsTestFile(b.FilePath, b.Language); ta != tb {
+				return ta
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+		next := callers[0]
+		if seen[next.ID] {
+			return "Unreachable from entry points: only called from a cycle of dead code", chain
+		}
+		seen[next.ID] = true
+		chain = append(chain, fmt.Sprintf("%s (%s)", next.Name, formatStoreLocation(next)))
+		if extract.IsTestFile(next.FilePath, next.Language) {
+			return "Unreachable from entry points: only used by tests", chain
+		}
+		cur = next.ID
+	}
+	switch {
+	case len(chain) == 0:
+		return "Unreachable from entry points, no callers", nil
+	case len(chain) == maxReasonChain:
+		return "Unreachable from entry points", chain
+	}
+	return fmt.Sprintf("Unreachable from entry points: callers start at %s, which has no callers", entityMap[cur].Name), chain
+}
+
 // normalizeDeadTypeFilter converts short type codes to entity types
 func normalizeDeadTypeFilter(filter string) string {
 	switch strings.ToUpper(strings.TrimSpace(filter)) {
@@ -418,7 +620,7 @@ func isCommonLocalVarName(name string) bool {
 
 // assignDeadChains groups dead items into chains based on call relationships.
 // A chain is a connected subgraph of dead entities linked by dependencies.
-func assignDeadChains(items []deadCodeItem, deadIDs map[string]bool, storeDB *store.Store) {
+func assignDeadChains(items []deadCodeItem, deadIDs map[string]bool, storeDB *store.SQLStore) {
 	// Build adjacency among dead entities
 	idToIdx := make(map[string]int)
 	for i, item := range items {
@@ -556,6 +758,9 @@ func buildDeadItemData(item deadCodeItem) map[string]interface{} {
 	if item.chainID > 0 {
 		data["chain"] = item.chainID
 	}
+	if len(item.reasonChain) > 0 {
+		data["reason_chain"] = item.reasonChain
+	}
 	return data
 }
 
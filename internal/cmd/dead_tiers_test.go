@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -200,3 +201,65 @@ func TestDeadChains(t *testing.T) {
 		t.Errorf("expected chain grouping in output, got:\n%s", out)
 	}
 }
+
+func TestDeadTier4_Unreachable(t *testing.T) {
+	tmpDir, cleanup := setupDeadTierTestStore(t)
+	defer cleanup()
+
+	// main reaches caller -> alive; ping <-> pong is a cycle nothing reaches;
+	// hook has no callers but is tagged as an entry point
+	st, err := store.Open(filepath.Join(tmpDir, ".cx"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	now := time.Now()
+	for _, e := range []struct{ id, name string }{
+		{"sa-fn-main", "main"}, {"sa-fn-ping", "ping"}, {"sa-fn-pong", "pong"}, {"sa-fn-hook", "hook"},
+	} {
+		st.CreateEntity(&store.Entity{
+			ID: e.id, Name: e.name, EntityType: "function",
+			FilePath: "pkg/f.go", LineStart: 1, Visibility: "private", Status: "active", Language: "go",
+			CreatedAt: now, UpdatedAt: now,
+		})
+	}
+	st.CreateDependency(&store.Dependency{FromID: "sa-fn-main", ToID: "sa-fn-caller", DepType: "calls"})
+	st.CreateDependency(&store.Dependency{FromID: "sa-fn-ping", ToID: "sa-fn-pong", DepType: "calls"})
+	st.CreateDependency(&store.Dependency{FromID: "sa-fn-pong", ToID: "sa-fn-ping", DepType: "calls"})
+	st.SaveBulkMetrics([]*store.Metrics{
+		{EntityID: "sa-fn-caller", InDegree: 1, OutDegree: 1, ComputedAt: now},
+		{EntityID: "sa-fn-main", InDegree: 0, OutDegree: 1, ComputedAt: now},
+		{EntityID: "sa-fn-ping", InDegree: 1, OutDegree: 1, ComputedAt: now},
+		{EntityID: "sa-fn-pong", InDegree: 1, OutDegree: 1, ComputedAt: now},
+		{EntityID: "sa-fn-hook", InDegree: 0, OutDegree: 0, ComputedAt: now},
+	})
+	if err := st.AddTag("sa-fn-hook", "entrypoint", "test"); err != nil {
+		t.Fatalf("add tag: %v", err)
+	}
+	st.Close()
+
+	deadTier = 4
+	deadIncludeExports = false
+	deadChains = false
+	deadByFile = false
+	deadCreateTask = false
+	deadTypeFilter = ""
+	defer func() { deadTier = 1 }()
+
+	var buf bytes.Buffer
+	deadCmd.SetOut(&buf)
+	if err := runDead(deadCmd, []string{}); err != nil {
+		t.Fatalf("runDead failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, name := range []string{"name: ping", "name: pong", "only called from a cycle", "reason_chain", "deadPrivate"} {
+		if !strings.Contains(out, name) {
+			t.Errorf("tier 4 output should contain %q, got:\n%s", name, out)
+		}
+	}
+	for _, name := range []string{"name: alive", "name: caller", "name: main", "name: hook"} {
+		if strings.Contains(out, name) {
+			t.Errorf("%s is reachable from an entry point and should not be reported", name)
+		}
+	}
+}
@@ -151,7 +151,7 @@ func checkIntegrity(db *sql.DB) doctorResult {
 }
 
 // checkOrphanDependencies finds dependencies referencing non-existent entities
-func checkOrphanDependencies(st *store.Store, db *sql.DB, fix bool) doctorResult {
+func checkOrphanDependencies(st *store.SQLStore, db *sql.DB, fix bool) doctorResult {
 	query := `
 		SELECT d.from_id, d.to_id, d.dep_type
 		FROM dependencies d
@@ -220,7 +220,7 @@ func checkOrphanDependencies(st *store.Store, db *sql.DB, fix bool) doctorResult
 }
 
 // checkStaleEntities finds entities in files that no longer exist
-func checkStaleEntities(st *store.Store, fix bool) doctorResult {
+func checkStaleEntities(st *store.SQLStore, fix bool) doctorResult {
 	// Get all active entities
 	entities, err := st.QueryEntities(store.EntityFilter{Status: "active"})
 	if err != nil {
@@ -291,7 +291,7 @@ func checkStaleEntities(st *store.Store, fix bool) doctorResult {
 
 // checkArchivedRatio detects when archived entities vastly outnumber active ones
 // This indicates a potentially stale database that should be reset
-func checkArchivedRatio(st *store.Store, fix bool, autoConfirm bool) doctorResult {
+func checkArchivedRatio(st *store.SQLStore, fix bool, autoConfirm bool) doctorResult {
 	activeCount, err := st.CountEntities(store.EntityFilter{Status: "active"})
 	if err != nil {
 		return doctorResult{
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/scip"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd groups commands that write the code graph in other tools' formats
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the code graph for other tools",
+	Long: `Export the code graph in formats understood by other tools.
+
+Subcommands:
+  graph  Entities and dependencies as GraphML, GEXF, DOT or Neo4j CSV
+  scip   A SCIP index for code search and navigation tools`,
+}
+
+// exportGraphCmd writes the dependency graph with entity attributes
+var exportGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export entities and dependencies as GraphML, GEXF, DOT or Neo4j CSV",
+	Long: `Export the code graph for analysis in Gephi, yEd, Cytoscape, NetworkX,
+Graphviz or Neo4j.
+
+Nodes are active entities with their attributes (name, type, file, lines,
+language, signature, visibility), metrics (PageRank, betweenness, degree),
+coverage and tags. Edges carry the dependency type, whether the dependency
+is optional, and its source (treesitter, or scip when imported with
+'cx scan --scip'). Metrics and coverage are left out for entities that have
+none rather than written as zero.
+
+Formats:
+  graphml    GraphML (Gephi, yEd, Cytoscape, NetworkX)
+  gexf       GEXF 1.3 (Gephi)
+  dot        Graphviz DOT; optional edges are dashed
+  neo4j-csv  nodes.csv and relationships.csv for 'neo4j-admin database import'
+
+Filters narrow the export; edges are kept only when both ends are exported.
+
+Examples:
+  cx export graph --format graphml -o cx.graphml
+  cx export graph --format gexf --path internal/store -o store.gexf
+  cx export graph --format dot --dep-type calls --min-pagerank 0.001 | dot -Tsvg > calls.svg
+  cx export graph --format neo4j-csv -o neo4j-import/`,
+	Args: cobra.NoArgs,
+	RunE: runExportGraph,
+}
+
+// exportSCIPCmd writes the graph as a SCIP index
+var exportSCIPCmd = &cobra.Command{
+	Use:   "scip",
+	Short: "Export the code graph as a SCIP index",
+	Long: `Write the code graph as a SCIP index (https://github.com/sourcegraph/scip)
+for code search and navigation tools, including for languages that have no
+SCIP indexer of their own (PHP, Ruby, Kotlin, ...).
+
+Every entity becomes a symbol definition spanning its lines, with its doc
+comment, signature and kind. Every dependency becomes a reference to its
+target wherever the target's name appears in the source entity; if the name
+cannot be found, the reference is placed at the start of the entity.
+PageRank, betweenness, degree, coverage and tags are attached to each symbol
+as a documentation line of the form:
+
+  cx: pagerank=0.0123 betweenness=0.004 in_degree=7 out_degree=3
+
+Symbols have the form 'cx <language> . . <path>/<Type>#<name>().'.
+Exporting and then importing with 'cx scan --scip' maps back onto the same
+entities.
+
+Examples:
+  cx export scip                       # Write index.scip
+  cx export scip -o cx.scip --lang php # Only PHP entities
+  cx export scip --path src/billing`,
+	Args: cobra.NoArgs,
+	RunE: runExportSCIP,
+}
+
+var (
+	exportSCIPOutput string
+	exportSCIPPath   string
+	exportSCIPLang   string
+)
+
+var (
+	exportGraphFormat      string
+	exportGraphOutput      string
+	exportGraphPath        string
+	exportGraphLang        string
+	exportGraphDepTypes    []string
+	exportGraphMinPageRank float64
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportGraphCmd)
+	exportCmd.AddCommand(exportSCIPCmd)
+
+	exportGraphCmd.Flags().StringVar(&exportGraphFormat, "format", graph.ExportGraphML, "Export format: "+strings.Join(graph.ExportFormats, "|"))
+	exportGraphCmd.Flags().StringVarP(&exportGraphOutput, "output", "o", "", "Output file (default: stdout); a directory for neo4j-csv")
+	exportGraphCmd.Flags().StringVar(&exportGraphPath, "path", "", "Only entities under this path prefix")
+	exportGraphCmd.Flags().StringVar(&exportGraphLang, "lang", "", "Only entities in this language (go, typescript, python, rust, java)")
+	exportGraphCmd.Flags().StringSliceVar(&exportGraphDepTypes, "dep-type", nil, "Only edges of these dependency types (calls, uses_type, ...)")
+	exportGraphCmd.Flags().Float64Var(&exportGraphMinPageRank, "min-pagerank", 0, "Only entities with at least this PageRank")
+
+	exportSCIPCmd.Flags().StringVarP(&exportSCIPOutput, "output", "o", "index.scip", "Output file")
+	exportSCIPCmd.Flags().StringVar(&exportSCIPPath, "path", "", "Only entities under this path prefix")
+	exportSCIPCmd.Flags().StringVar(&exportSCIPLang, "lang", "", "Only entities in this language")
+}
+
+func runExportGraph(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(graph.ExportFormats, exportGraphFormat) {
+		return fmt.Errorf("unknown format %q (valid: %s)", exportGraphFormat, strings.Join(graph.ExportFormats, ", "))
+	}
+	if exportGraphFormat == graph.ExportNeo4jCSV && exportGraphOutput == "" {
+		return fmt.Errorf("neo4j-csv writes two files: use -o <directory>")
+	}
+
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	g, err := graph.BuildExport(storeDB, graph.ExportFilter{
+		PathPrefix:  exportGraphPath,
+		Language:    exportGraphLang,
+		DepTypes:    exportGraphDepTypes,
+		MinPageRank: exportGraphMinPageRank,
+	})
+	if err != nil {
+		return err
+	}
+
+	if exportGraphFormat == graph.ExportNeo4jCSV {
+		if err := writeNeo4jExport(exportGraphOutput, g); err != nil {
+			return err
+		}
+	} else {
+		var w io.Writer = cmd.OutOrStdout()
+		if exportGraphOutput != "" {
+			f, err := os.Create(exportGraphOutput)
+			if err != nil {
+				return fmt.Errorf("create output: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := graph.WriteExport(w, g, exportGraphFormat); err != nil {
+			return fmt.Errorf("write %s: %w", exportGraphFormat, err)
+		}
+	}
+
+	if exportGraphOutput != "" && !quiet {
+		fmt.Fprintf(os.Stderr, "Exported %d nodes, %d edges to %s\n", len(g.Nodes), len(g.Edges), exportGraphOutput)
+	}
+	return nil
+}
+
+// writeNeo4jExport writes nodes.csv and relationships.csv into dir.
+func writeNeo4jExport(dir string, g *graph.ExportGraph) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	nodes, err := os.Create(filepath.Join(dir, "nodes.csv"))
+	if err != nil {
+		return err
+	}
+	defer nodes.Close()
+	rels, err := os.Create(filepath.Join(dir, "relationships.csv"))
+	if err != nil {
+		return err
+	}
+	defer rels.Close()
+
+	if err := graph.WriteNeo4jCSV(nodes, rels, g); err != nil {
+		return fmt.Errorf("write neo4j-csv: %w", err)
+	}
+	return nil
+}
+
+func runExportSCIP(cmd *cobra.Command, args []string) error {
+	cxDir, err := config.FindConfigDir(".")
+	if err != nil {
+		return fmt.Errorf("cx not initialized: run 'cx scan' first")
+	}
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	g, err := graph.BuildExport(storeDB, graph.ExportFilter{
+		PathPrefix: exportSCIPPath,
+		Language:   exportSCIPLang,
+	})
+	if err != nil {
+		return err
+	}
+
+	idx := scip.FromGraph(g, scip.ExportOptions{
+		ProjectRoot: filepath.Dir(cxDir),
+		ToolVersion: Version,
+	})
+	if err := scip.WriteFile(exportSCIPOutput, idx); err != nil {
+		return fmt.Errorf("write SCIP index: %w", err)
+	}
+
+	if !quiet {
+		symbols := 0
+		for _, doc := range idx.Documents {
+			symbols += len(doc.Symbols)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d documents, %d symbols, %d dependencies to %s\n",
+			len(idx.Documents), symbols, len(g.Edges), exportSCIPOutput)
+	}
+	return nil
+}
@@ -6,12 +6,9 @@ import (
 	"os"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/anthropics/cx/internal/config"
 	"github.com/anthropics/cx/internal/embeddings"
-	"github.com/anthropics/cx/internal/graph"
-	"github.com/anthropics/cx/internal/metrics"
 	"github.com/anthropics/cx/internal/output"
 	"github.com/anthropics/cx/internal/store"
 	"github.com/spf13/cobra"
@@ -66,6 +63,23 @@ Output Formats:
 Semantic Search:
   --semantic       Use embedding-based semantic search (find code by concept)
 
+Structure:
+  --cycles         Every dependency cycle (strongly connected component) with
+                   its members, the edges to cut to break it, and whether it
+                   is new, grew or shrank since the previous scan
+  --level <l>      Cycle granularity: package (directory, default), file, entity
+  --chokepoints    Entities every path from the entry points (roots.kinds in
+                   config: main, commands, handlers, exported) to many others
+                   goes through, from the dominator tree; plus articulation
+                   points and bridges of the undirected graph
+  --hotspots       Entities that are both complex and changed often: churn
+                   from git history (--since window, default 90d) times size
+                   and estimated cyclomatic complexity, weighted by PageRank
+  --clones         Groups of near-copied functions: bodies with the same
+                   token structure after renaming identifiers and changing
+                   literals, or with small edits (--similarity, default 0.8,
+                   over functions of --min-lines, default 6)
+
 Examples:
   cx find LoginUser                        # Name search: prefix match
   cx find "auth validation"                # Concept search: FTS
@@ -94,32 +108,44 @@ Examples:
   cx find Auth --since HEAD~10             # Auth* entities changed in last 10 commits
   cx find --semantic "user authentication" # Semantic: find by concept
   cx find --semantic "error handling"      # Semantic: find error handlers
-  cx find --semantic "database queries" --type=F  # Semantic with type filter`,
+  cx find --semantic "database queries" --type=F  # Semantic with type filter
+  cx find --cycles                         # Package dependency cycles
+  cx find --cycles --level file --top 5    # Five largest file-level cycles
+  cx find --hotspots --since 90d           # Churn × complexity hotspots
+  cx find --chokepoints                    # What everything funnels through
+  cx find --clones --similarity 0.9        # Copy-pasted functions`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFind,
 }
 
 var (
-	findType        string
-	findFile        string
-	findLang        string
-	findExact       bool
-	findQualified   bool
-	findLimit       int
-	findImportant   bool
-	findKeystones   bool
-	findBottlenecks bool
-	findTop         int
-	findRecompute   bool
-	findTags        []string
-	findTagAny      bool
-	findAt          string // Time travel: query at specific commit/ref
-	findSince       string // Change tracking: show entities changed since ref
-	findNew         bool   // Change tracking: show only new/added entities
-	findChanged     bool   // Change tracking: show only modified entities
-	findRemoved     bool   // Change tracking: show only removed entities
-	findSemantic    bool   // Semantic search using embeddings
-	findDead        bool   // Dead code detection (dispatches to runDead)
+	findType            string
+	findFile            string
+	findLang            string
+	findExact           bool
+	findQualified       bool
+	findLimit           int
+	findImportant       bool
+	findKeystones       bool
+	findBottlenecks     bool
+	findTop             int
+	findRecompute       bool
+	findTags            []string
+	findTagAny          bool
+	findAt              string // Time travel: query at specific commit/ref
+	findSince           string // Change tracking: show entities changed since ref
+	findNew             bool   // Change tracking: show only new/added entities
+	findChanged         bool   // Change tracking: show only modified entities
+	findRemoved         bool   // Change tracking: show only removed entities
+	findSemantic        bool   // Semantic search using embeddings
+	findDead            bool   // Dead code detection (dispatches to runDead)
+	findCycles          bool   // Dependency cycle report
+	findHotspots        bool   // Churn × complexity hotspots
+	findChokepoints     bool   // Dominator-tree chokepoints
+	findClones          bool   // Near-copied functions
+	findCloneMinLines   int
+	findCloneSimilarity float64
+	findLevel           string // Cycle granularity: entity, file, package
 )
 
 func init() {
@@ -137,8 +163,8 @@ func init() {
 	findCmd.Flags().BoolVar(&findImportant, "important", false, "Sort results by PageRank importance")
 	findCmd.Flags().BoolVar(&findKeystones, "keystones", false, "Show only keystone entities (highly depended-on)")
 	findCmd.Flags().BoolVar(&findBottlenecks, "bottlenecks", false, "Show only bottleneck entities (central to paths)")
-	findCmd.Flags().IntVar(&findTop, "top", 20, "Number of results for --important/--keystones/--bottlenecks")
-	findCmd.Flags().BoolVar(&findRecompute, "recompute", false, "Force recompute metrics (for --important/--keystones)")
+	findCmd.Flags().IntVar(&findTop, "top", 20, "Number of results for --important/--keystones/--bottlenecks/--cycles/--hotspots/--chokepoints/--clones")
+	findCmd.Flags().BoolVar(&findRecompute, "recompute", false, "Recompute metrics over the whole graph (for --important/--keystones)")
 
 	// Tag filtering flags
 	findCmd.Flags().StringArrayVar(&findTags, "tag", nil, "Filter by tag (can be repeated, default: match ALL tags)")
@@ -148,7 +174,7 @@ func init() {
 	findCmd.Flags().StringVar(&findAt, "at", "", "Query at specific commit/ref (e.g., HEAD~5, commit hash, branch)")
 
 	// Change tracking flags
-	findCmd.Flags().StringVar(&findSince, "since", "", "Show entities changed since ref (e.g., HEAD~5, commit hash)")
+	findCmd.Flags().StringVar(&findSince, "since", "", "Show entities changed since ref (e.g., HEAD~5, commit hash); history window for --hotspots (e.g., 90d)")
 	findCmd.Flags().BoolVar(&findNew, "new", false, "Show only newly added entities (since HEAD~1 or --since ref)")
 	findCmd.Flags().BoolVar(&findChanged, "changed", false, "Show only modified entities (since HEAD~1 or --since ref)")
 	findCmd.Flags().BoolVar(&findRemoved, "removed", false, "Show only removed entities (since HEAD~1 or --since ref)")
@@ -160,6 +186,21 @@ func init() {
 	findCmd.Flags().BoolVar(&findDead, "dead", false, "Find dead code (same as: cx dead)")
 	findCmd.Flags().IntVar(&deadTier, "tier", 1, "Dead code confidence tier: 1=definite, 2=+probable, 3=+suspicious (with --dead)")
 	findCmd.Flags().BoolVar(&deadChains, "chains", false, "Group dead chains together (with --dead)")
+
+	// Cycle report
+	findCmd.Flags().BoolVar(&findCycles, "cycles", false, "Report dependency cycles with the edges to cut")
+	findCmd.Flags().StringVar(&findLevel, "level", "package", "Cycle granularity: entity, file, package (with --cycles)")
+
+	// Hotspot report
+	findCmd.Flags().BoolVar(&findHotspots, "hotspots", false, "Rank entities by churn × complexity over the --since window")
+
+	// Chokepoint report
+	findCmd.Flags().BoolVar(&findChokepoints, "chokepoints", false, "Rank entities by how much of the graph is reachable from the roots only through them")
+
+	// Clone report
+	findCmd.Flags().BoolVar(&findClones, "clones", false, "Group functions whose bodies are copies of each other, up to renames and small edits")
+	findCmd.Flags().IntVar(&findCloneMinLines, "min-lines", defaultCloneMinLines, "Smallest function compared (with --clones)")
+	findCmd.Flags().Float64Var(&findCloneSimilarity, "similarity", defaultCloneSimilarity, "Lowest fingerprint similarity reported, 0-1 (with --clones)")
 }
 
 func runFind(cmd *cobra.Command, args []string) error {
@@ -171,6 +212,18 @@ func runFind(cmd *cobra.Command, args []string) error {
 		}
 		return runDead(deadCmd, args)
 	}
+	if findCycles {
+		return runFindCycles(cmd)
+	}
+	if findHotspots {
+		return runFindHotspots(cmd)
+	}
+	if findChokepoints {
+		return runFindChokepoints(cmd)
+	}
+	if findClones {
+		return runFindClones(cmd)
+	}
 
 	// Get query if provided
 	query := ""
@@ -278,7 +331,7 @@ func isMultiWordQuery(query string) bool {
 }
 
 // runFindWithChangeTracking handles --since, --new, --changed, --removed flags
-func runFindWithChangeTracking(cmd *cobra.Command, storeDB *store.Store, query string, format output.Format, density output.Density) error {
+func runFindWithChangeTracking(cmd *cobra.Command, storeDB *store.SQLStore, query string, format output.Format, density output.Density) error {
 	// Determine the "from" ref for the diff
 	fromRef := findSince
 	if fromRef == "" {
@@ -433,7 +486,7 @@ func runFindWithChangeTracking(cmd *cobra.Command, storeDB *store.Store, query s
 }
 
 // runFindByName performs traditional name-based search
-func runFindByName(cmd *cobra.Command, storeDB *store.Store, query string, format output.Format, density output.Density) error {
+func runFindByName(cmd *cobra.Command, storeDB *store.SQLStore, query string, format output.Format, density output.Density) error {
 	// Build filter from flags
 	filter := store.EntityFilter{
 		Status: "active",
@@ -513,7 +566,7 @@ func runFindByName(cmd *cobra.Command, storeDB *store.Store, query string, forma
 }
 
 // runFindByTagsOnly performs tag-only search (no query, just --tag filters)
-func runFindByTagsOnly(cmd *cobra.Command, storeDB *store.Store, format output.Format, density output.Density) error {
+func runFindByTagsOnly(cmd *cobra.Command, storeDB *store.SQLStore, format output.Format, density output.Density) error {
 	// Get entities matching the tag criteria
 	matchAll := !findTagAny // default is match ALL tags
 	entities, err := storeDB.FindByTags(findTags, matchAll)
@@ -559,7 +612,7 @@ func runFindByTagsOnly(cmd *cobra.Command, storeDB *store.Store, format output.F
 }
 
 // runSemanticFind performs embedding-based semantic search
-func runSemanticFind(cmd *cobra.Command, storeDB *store.Store, query string, format output.Format, density output.Density) error {
+func runSemanticFind(cmd *cobra.Command, storeDB *store.SQLStore, query string, format output.Format, density output.Density) error {
 	// Check if embeddings exist
 	count, err := storeDB.EmbeddingCount()
 	if err != nil || count == 0 {
@@ -567,7 +620,7 @@ func runSemanticFind(cmd *cobra.Command, storeDB *store.Store, query string, for
 	}
 
 	// Create embedder for query embedding
-	embedder, err := embeddings.NewLocalEmbedder()
+	embedder, err := embeddings.NewFromConfig()
 	if err != nil {
 		return fmt.Errorf("semantic search unavailable: %w", err)
 	}
@@ -667,7 +720,7 @@ func runSemanticFind(cmd *cobra.Command, storeDB *store.Store, query string, for
 }
 
 // runFindWithFTS performs full-text/concept search (migrated from search command)
-func runFindWithFTS(cmd *cobra.Command, storeDB *store.Store, query string, format output.Format, density output.Density) error {
+func runFindWithFTS(cmd *cobra.Command, storeDB *store.SQLStore, query string, format output.Format, density output.Density) error {
 	// Build search options
 	opts := store.DefaultSearchOptions()
 	opts.Query = query
@@ -727,7 +780,7 @@ func runFindWithFTS(cmd *cobra.Command, storeDB *store.Store, query string, form
 }
 
 // runFindWithRanking performs ranked search (migrated from rank command)
-func runFindWithRanking(cmd *cobra.Command, storeDB *store.Store, cfg *config.Config, query string, isConceptSearch bool, format output.Format, density output.Density) error {
+func runFindWithRanking(cmd *cobra.Command, storeDB *store.SQLStore, cfg *config.Config, query string, isConceptSearch bool, format output.Format, density output.Density) error {
 	// Get all active entities (use AS OF if --at specified)
 	filter := store.EntityFilter{Status: "active"}
 	var entities []*store.Entity
@@ -758,48 +811,13 @@ func runFindWithRanking(cmd *cobra.Command, storeDB *store.Store, cfg *config.Co
 
 	if needRecompute {
 		fmt.Fprintf(os.Stderr, "Computing metrics for %d entities...\n", len(entities))
-
-		// Build graph from store
-		g, err := graph.BuildFromStore(storeDB)
+		run, err := refreshMetrics(storeDB, cfg, nil, findRecompute)
 		if err != nil {
-			return fmt.Errorf("failed to build graph: %w", err)
+			return fmt.Errorf("failed to compute metrics: %w", err)
 		}
-
-		// Create adjacency map
-		adjacency := g.Edges
-
-		// Compute PageRank
-		prConfig := metrics.PageRankConfig{
-			Damping:       cfg.Metrics.PageRankDamping,
-			MaxIterations: cfg.Metrics.PageRankIterations,
-			Tolerance:     0.0001,
+		if err := saveMetricsRun(storeDB, run); err != nil {
+			return fmt.Errorf("failed to record metrics run: %w", err)
 		}
-		pagerank := metrics.ComputePageRank(adjacency, prConfig)
-
-		// Compute betweenness
-		betweenness := metrics.ComputeBetweenness(adjacency)
-
-		// Compute degrees
-		inDegree, outDegree := metrics.ComputeInOutDegree(adjacency)
-
-		// Save to store
-		bulkMetrics := make([]*store.Metrics, 0, len(entities))
-		for _, e := range entities {
-			m := &store.Metrics{
-				EntityID:    e.ID,
-				PageRank:    pagerank[e.ID],
-				Betweenness: betweenness[e.ID],
-				InDegree:    inDegree[e.ID],
-				OutDegree:   outDegree[e.ID],
-				ComputedAt:  time.Now(),
-			}
-			bulkMetrics = append(bulkMetrics, m)
-		}
-
-		if err := storeDB.SaveBulkMetrics(bulkMetrics); err != nil {
-			return fmt.Errorf("failed to save metrics: %w", err)
-		}
-
 		fmt.Fprintf(os.Stderr, "Metrics computed and saved.\n")
 	}
 
@@ -916,8 +934,9 @@ func runFindWithRanking(cmd *cobra.Command, storeDB *store.Store, cfg *config.Co
 
 	// Build ListOutput with ranked entities
 	listOutput := &output.ListOutput{
-		Results: make(map[string]*output.EntityOutput),
-		Count:   0,
+		Results:   make(map[string]*output.EntityOutput),
+		Count:     0,
+		Freshness: metricsFreshness(storeDB),
 	}
 
 	for _, r := range ranked {
@@ -993,7 +1012,7 @@ func filterByName(entities []*store.Entity, query string, exact bool) []*store.E
 
 // filterByTags filters entities to only include those with specified tags
 // If matchAll is true, entity must have ALL tags. If false, entity must have ANY tag.
-func filterByTags(entities []*store.Entity, storeDB *store.Store, tags []string, matchAll bool) []*store.Entity {
+func filterByTags(entities []*store.Entity, storeDB *store.SQLStore, tags []string, matchAll bool) []*store.Entity {
 	if len(tags) == 0 {
 		return entities
 	}
@@ -1080,7 +1099,7 @@ func mapEntityTypeToCGF(t string) output.CGFEntityType {
 // formatEntityLocation moved to utils.go
 
 // storeEntityToOutput converts a store.Entity to output.EntityOutput
-func storeEntityToOutput(e *store.Entity, density output.Density, storeDB *store.Store) *output.EntityOutput {
+func storeEntityToOutput(e *store.Entity, density output.Density, storeDB *store.SQLStore) *output.EntityOutput {
 	entityOut := &output.EntityOutput{
 		Type:     mapStoreEntityTypeToString(e.EntityType),
 		Location: formatEntityLocation(e),
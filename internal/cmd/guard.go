@@ -14,6 +14,7 @@ import (
 	"github.com/anthropics/cx/internal/graph"
 	"github.com/anthropics/cx/internal/output"
 	"github.com/anthropics/cx/internal/parser"
+	"github.com/anthropics/cx/internal/rules"
 	"github.com/anthropics/cx/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -33,6 +34,10 @@ Checks performed:
   3. Breaking changes - Are there signature changes with unchecked callers?
   4. Dead on arrival - Are there new private entities with zero callers?
   5. Graph drift - Is the cx database out of sync with code?
+  6. Architecture rules - Do changed entities break the rules section of
+     the config? Violations are errors or warnings by rule severity.
+  7. Near clones - Is a new function a near-copy of an existing one, up to
+     renamed identifiers and small edits? (see 'cx find --clones')
 
 Exit codes:
   0 = pass (no errors, warnings allowed if --fail-on-warnings is false)
@@ -44,6 +49,12 @@ Configuration (.cx/config.yaml):
     fail_on_coverage_regression: true
     min_coverage_for_keystones: 50
     fail_on_warnings: false
+  rules:                      # see 'cx check --help'
+    layers:
+      - {name: cmd, paths: [internal/cmd]}
+      - {name: store, paths: [internal/store]}
+    forbid:
+      - {from: store, to: [cmd], reason: "store must not import commands"}
 
 Examples:
   cx guard                    # Check staged changes (default)
@@ -94,14 +105,17 @@ type GuardSummary struct {
 	CoverageIssues   int    `yaml:"coverage_issues" json:"coverage_issues"`
 	SignatureChanges int    `yaml:"signature_changes" json:"signature_changes"`
 	DeadCodeCount    int    `yaml:"dead_code_count" json:"dead_code_count"`
+	ArchViolations   int    `yaml:"architecture_violations" json:"architecture_violations"`
+	NearClones       int    `yaml:"near_clones" json:"near_clones"`
 	PassStatus       string `yaml:"pass_status" json:"pass_status"` // pass, warnings, fail
 }
 
 // GuardIssue represents a single error or warning
 type GuardIssue struct {
-	Type       string `yaml:"type" json:"type"` // coverage_regression, untested_code, signature_change, drift
+	Type       string `yaml:"type" json:"type"` // coverage_regression, untested_code, signature_change, drift, architecture, near_clone
 	Entity     string `yaml:"entity" json:"entity"`
 	File       string `yaml:"file" json:"file"`
+	Location   string `yaml:"location,omitempty" json:"location,omitempty"`
 	Message    string `yaml:"message" json:"message"`
 	Suggestion string `yaml:"suggestion,omitempty" json:"suggestion,omitempty"`
 }
@@ -116,6 +130,7 @@ type guardEntity struct {
 	isNew       bool
 	sigChanged  bool
 	callerCount int
+	fingerprint []uint32 // of new functions and methods
 }
 
 func runGuard(cmd *cobra.Command, args []string) error {
@@ -180,7 +195,10 @@ func runGuard(cmd *cobra.Command, args []string) error {
 
 	// Analyze files
 	baseDir, _ := os.Getwd()
-	guardOutput := analyzeFiles(sourceFiles, storeDB, g, cfg, baseDir)
+	guardOutput, err := analyzeFiles(sourceFiles, storeDB, g, cfg, baseDir)
+	if err != nil {
+		return err
+	}
 
 	// Determine exit status
 	exitCode := 0
@@ -293,7 +311,7 @@ func filterSourceFiles(files []string) []string {
 }
 
 // analyzeFiles performs guard analysis on the given files
-func analyzeFiles(files []string, storeDB *store.Store, g *graph.Graph, cfg *config.Config, baseDir string) *GuardOutput {
+func analyzeFiles(files []string, storeDB *store.SQLStore, g *graph.Graph, cfg *config.Config, baseDir string) (*GuardOutput, error) {
 	output := &GuardOutput{
 		Summary: &GuardSummary{
 			FilesChecked: len(files),
@@ -307,6 +325,12 @@ func analyzeFiles(files []string, storeDB *store.Store, g *graph.Graph, cfg *con
 	signatureChanges := 0
 	coverageIssues := 0
 	deadCodeCount := 0
+	var staged []stagedFile
+	defer func() {
+		for _, f := range staged {
+			f.result.Close()
+		}
+	}()
 
 	for _, filePath := range files {
 		absPath := filePath
@@ -345,13 +369,20 @@ func analyzeFiles(files []string, storeDB *store.Store, g *graph.Graph, cfg *con
 		if err != nil {
 			continue
 		}
+		parseResult.FilePath = filepath.ToSlash(filePath)
 
-		// Extract current entities
-		ext := extract.NewExtractor(parseResult)
-		currentEntities, err := ext.ExtractAll()
+		// Extract current entities, fingerprinting functions for clone checks
+		withNodes, err := extractEntitiesWithNodes(parseResult, lang, baseDir)
 		if err != nil {
+			parseResult.Close()
 			continue
 		}
+		staged = append(staged, stagedFile{path: parseResult.FilePath, result: parseResult, entities: withNodes})
+		extract.FingerprintEntities(withNodes)
+		currentEntities := make([]extract.Entity, len(withNodes))
+		for i, ewn := range withNodes {
+			currentEntities[i] = *ewn.Entity
+		}
 
 		// Build lookup by name for current entities
 		currentLookup := make(map[string]*extract.Entity)
@@ -424,6 +455,7 @@ func analyzeFiles(files []string, storeDB *store.Store, g *graph.Graph, cfg *con
 			_, err := storeDB.GetEntity(entityID)
 			if err != nil {
 				// New entity
+				endLine := int(currentEnt.EndLine)
 				ge := &guardEntity{
 					entity: &store.Entity{
 						ID:         entityID,
@@ -431,9 +463,11 @@ func analyzeFiles(files []string, storeDB *store.Store, g *graph.Graph, cfg *con
 						EntityType: string(currentEnt.Kind),
 						FilePath:   filePath,
 						LineStart:  int(currentEnt.StartLine),
+						LineEnd:    &endLine,
 						Visibility: string(currentEnt.Visibility),
 					},
-					isNew: true,
+					isNew:       true,
+					fingerprint: currentEnt.Fingerprint,
 				}
 				entities[entityID] = ge
 			}
@@ -512,7 +546,77 @@ func analyzeFiles(files []string, storeDB *store.Store, g *graph.Graph, cfg *con
 		}
 	}
 
+	// Architecture rules, for dependencies of changed entities as they are now
+	changed := make(map[string]bool, len(entities))
+	for id := range entities {
+		changed[id] = true
+	}
+	architecture := 0
+	set, err := rules.Compile(cfg.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	if !set.Empty() && len(changed) > 0 {
+		overlay, err := stagedGraph(staged, storeDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract staged dependencies: %w", err)
+		}
+		violations, err := rules.Check(storeDB, set, rules.CheckOptions{ProjectRoot: baseDir, From: changed, Staged: overlay})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check rules: %w", err)
+		}
+		for _, v := range violations {
+			issue := GuardIssue{
+				Type:       "architecture",
+				Entity:     v.From,
+				File:       entities[v.FromID].entity.FilePath,
+				Location:   v.Location,
+				Message:    v.Message(),
+				Suggestion: "Route the dependency through an allowed layer or update the rules",
+			}
+			if v.Severity == "error" {
+				output.Errors = append(output.Errors, issue)
+			} else {
+				output.Warnings = append(output.Warnings, issue)
+			}
+		}
+		architecture = len(violations)
+	}
+
+	// Near clones, of new functions against the scanned ones
+	nearClones := 0
+	var candidates []cloneCandidate
+	loaded := false
+	for _, ge := range entities {
+		if !ge.isNew || len(ge.fingerprint) == 0 || entityLines(ge.entity) < defaultCloneMinLines {
+			continue
+		}
+		if !loaded {
+			candidates, _ = loadCloneCandidates(storeDB, defaultCloneMinLines)
+			loaded = true
+		}
+		// A function moved within its file gets a new ID but is no copy
+		moved := func(e *store.Entity) bool {
+			return e.Name == ge.entity.Name && e.FilePath == ge.entity.FilePath
+		}
+		original, sim := nearestClone(ge.fingerprint, candidates, defaultCloneSimilarity, moved)
+		if original == nil {
+			continue
+		}
+		output.Warnings = append(output.Warnings, GuardIssue{
+			Type:       "near_clone",
+			Entity:     ge.entity.Name,
+			File:       ge.entity.FilePath,
+			Location:   formatStoreLocation(ge.entity),
+			Message:    fmt.Sprintf("New %s %s is a near-copy of %s (%s, %.0f%% similar)", ge.entity.EntityType, ge.entity.Name, original.Name, formatStoreLocation(original), sim*100),
+			Suggestion: fmt.Sprintf("Reuse %s or extract the shared logic", original.Name),
+		})
+		nearClones++
+	}
+
 	output.Summary.DriftDetected = driftCount > 0
+	output.Summary.ArchViolations = architecture
+	output.Summary.NearClones = nearClones
 	output.Summary.CoverageIssues = coverageIssues
 	output.Summary.DeadCodeCount = deadCodeCount
 	output.Summary.ErrorCount = len(output.Errors)
@@ -548,8 +652,112 @@ func analyzeFiles(files []string, storeDB *store.Store, g *graph.Graph, cfg *con
 	if deadCodeCount > 0 {
 		output.Recommendations = append(output.Recommendations, "Wire up or remove new entities with no callers")
 	}
+	if architecture > 0 {
+		output.Recommendations = append(output.Recommendations, "Run 'cx check --architecture' to see all rule violations")
+	}
+	if nearClones > 0 {
+		output.Recommendations = append(output.Recommendations, "Run 'cx find --clones' to see all copied functions")
+	}
+
+	return output, nil
+}
+
+// stagedFile is a file guard parsed as it is now.
+type stagedFile struct {
+	path     string
+	result   *parser.ParseResult
+	entities []extract.EntityWithNode
+}
+
+// stagedGraph extracts the dependencies of the staged files as they are now,
+// resolving calls against their current entities and the scanned entities
+// of every other file, the way scan would.
+func stagedGraph(files []stagedFile, storeDB *store.SQLStore) (*rules.Staged, error) {
+	out := &rules.Staged{Files: make(map[string]bool, len(files))}
+	for _, f := range files {
+		out.Files[f.path] = true
+	}
+
+	stored, err := storeDB.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, err
+	}
+	var all []extract.CallGraphEntity
+	for _, e := range stored {
+		if out.Files[e.FilePath] {
+			continue
+		}
+		qualified := e.Name
+		if e.Receiver != "" {
+			qualified = strings.TrimPrefix(e.Receiver, "*") + "." + e.Name
+		}
+		all = append(all, extract.CallGraphEntity{
+			ID:            e.ID,
+			Name:          e.Name,
+			QualifiedName: qualified,
+			Type:          e.EntityType,
+			Location:      fmt.Sprintf("%s:%d", e.FilePath, e.LineStart),
+		})
+	}
+	// Staged entities come last so their names win over scanned ones
+	for _, f := range files {
+		for _, ewn := range f.entities {
+			cge := ewn.Entity.ToCallGraphEntity()
+			cge.Node = ewn.Node
+			all = append(all, cge)
+
+			e := ewn.Entity
+			endLine := int(e.EndLine)
+			out.Entities = append(out.Entities, &store.Entity{
+				ID:         cge.ID,
+				Name:       e.Name,
+				EntityType: string(e.Kind),
+				FilePath:   f.path,
+				LineStart:  int(e.StartLine),
+				LineEnd:    &endLine,
+				Receiver:   e.Receiver,
+				Visibility: string(e.Visibility),
+				Status:     "active",
+			})
+		}
+	}
+
+	entityByName := make(map[string]*extract.CallGraphEntity, len(all))
+	entityByID := make(map[string]*extract.CallGraphEntity, len(all))
+	for i := range all {
+		e := &all[i]
+		entityByName[e.Name] = e
+		if e.QualifiedName != "" {
+			entityByName[e.QualifiedName] = e
+		}
+		entityByID[e.ID] = e
+	}
 
-	return output
+	for _, f := range files {
+		var fileEntities []extract.CallGraphEntity
+		for _, ewn := range f.entities {
+			cge := ewn.Entity.ToCallGraphEntity()
+			cge.Node = ewn.Node
+			fileEntities = append(fileEntities, cge)
+		}
+		deps, err := extractFileDependencies(f.result, fileEntities, entityByName, entityByID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+		for _, d := range deps {
+			if d.ToID == "" {
+				continue
+			}
+			out.Dependencies = append(out.Dependencies, &store.Dependency{
+				FromID:   d.FromID,
+				ToID:     d.ToID,
+				DepType:  string(d.DepType),
+				Optional: d.Optional,
+				Source:   store.DepSourceTreeSitter,
+			})
+		}
+	}
+	return out, nil
 }
 
 // detectLanguageForGuard detects the parser language from a file path
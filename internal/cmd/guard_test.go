@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/store"
+)
+
+func TestAnalyzeFilesChecksStagedDependencies(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, src string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("internal/store/db.go", "package store\n\nfunc Open() {}\n")
+	// Not yet scanned: a new function in cmd calling into store
+	write("internal/cmd/run.go", "package cmd\n\nfunc Run() {\n\tstore.Open()\n}\n")
+
+	st, err := store.Open(filepath.Join(root, ".cx"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer st.Close()
+	end := 3
+	if err := st.CreateEntitiesBulk([]*store.Entity{{
+		ID: "sa-fn-store-3-Open", Name: "Open", EntityType: "function", FilePath: "internal/store/db.go",
+		LineStart: 3, LineEnd: &end, Language: "go", Visibility: "pub", Status: "active",
+	}}); err != nil {
+		t.Fatalf("create entities: %v", err)
+	}
+	g, err := graph.BuildFromStore(st)
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Rules = config.RulesConfig{
+		Layers: []config.LayerConfig{
+			{Name: "cmd", Paths: []string{"internal/cmd"}},
+			{Name: "store", Paths: []string{"internal/store"}},
+		},
+		Forbid: []config.DependencyRule{{From: "cmd", To: []string{"store"}, Severity: "error"}},
+	}
+
+	out, err := analyzeFiles([]string{"internal/cmd/run.go"}, st, g, cfg, root)
+	if err != nil {
+		t.Fatalf("analyzeFiles: %v", err)
+	}
+	if out.Summary.ArchViolations == 0 {
+		t.Fatalf("no architecture violations, errors %+v", out.Errors)
+	}
+	var found bool
+	for _, issue := range out.Errors {
+		if issue.Type == "architecture" && issue.Message == "Run calls Open: cmd must not depend on store" {
+			found = true
+			if issue.File != "internal/cmd/run.go" || issue.Location != "internal/cmd/run.go:4" {
+				t.Errorf("issue = %+v", issue)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no error for Run calls Open in %+v", out.Errors)
+	}
+}
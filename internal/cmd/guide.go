@@ -8,6 +8,7 @@ import (
 
 	"github.com/anthropics/cx/internal/config"
 	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/metrics"
 	"github.com/anthropics/cx/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -91,9 +92,20 @@ This shows:
   - Inter-module dependency diagram
   - Module-level statistics
 
+With --communities it also detects clusters of entities that call and use
+each other (Louvain with Leiden's connectivity refinement over calls and
+uses_type edges) and shows:
+  - Each cluster's size and home directory, and how much of the cluster
+    lives there (alignment)
+  - Dependency counts between clusters
+  - Entities whose directory belongs to a different cluster than they do,
+    candidates to move when drawing module boundaries
+
 Examples:
   cx guide modules                # Module breakdown
   cx guide modules --format d2    # Use D2 format
+  cx guide modules --communities  # Add detected clusters
+  cx guide modules --communities --resolution 2   # Smaller clusters
   cx guide modules -o modules.md`,
 	RunE: runGuideModules,
 }
@@ -119,6 +131,9 @@ var (
 	guideFormat   string // "mermaid" or "d2"
 	guideOutput   string // output file path
 	guideMaxNodes int    // max nodes before collapsing
+
+	guideCommunities bool    // modules: add community detection
+	guideResolution  float64 // modules: community resolution
 )
 
 func init() {
@@ -134,6 +149,9 @@ func init() {
 	guideCmd.PersistentFlags().StringVar(&guideFormat, "format", "mermaid", "Diagram format: mermaid|d2")
 	guideCmd.PersistentFlags().StringVarP(&guideOutput, "output", "o", "", "Write output to file")
 	guideCmd.PersistentFlags().IntVar(&guideMaxNodes, "max-nodes", 30, "Maximum nodes before auto-collapsing")
+
+	guideModulesCmd.Flags().BoolVar(&guideCommunities, "communities", false, "Detect clusters and compare them with directories")
+	guideModulesCmd.Flags().Float64Var(&guideResolution, "resolution", 1.0, "Community resolution: higher finds more, smaller clusters (with --communities)")
 }
 
 // runGuideOverview shows stats + architecture diagram
@@ -409,6 +427,16 @@ func runGuideModules(cmd *cobra.Command, args []string) error {
 		sb.WriteString("```\n\n")
 	}
 
+	if err := writeCouplingSection(&sb, storeDB); err != nil {
+		return err
+	}
+
+	if guideCommunities {
+		if err := writeCommunitySections(&sb, storeDB, entities); err != nil {
+			return err
+		}
+	}
+
 	return writeGuideOutput(cmd, sb.String())
 }
 
@@ -538,7 +566,7 @@ func runGuideDeps(cmd *cobra.Command, args []string) error {
 // Helper functions
 
 // getEntityTypeCounts returns counts of entities by type
-func getEntityTypeCounts(storeDB *store.Store) (map[string]int, error) {
+func getEntityTypeCounts(storeDB *store.SQLStore) (map[string]int, error) {
 	stats := make(map[string]int)
 
 	entityTypes := []string{"function", "type", "method", "constant", "variable", "import"}
@@ -574,7 +602,7 @@ func extractModuleFromPath(filePath string) string {
 }
 
 // computeModuleEdges computes inter-module dependencies
-func computeModuleEdges(entities []*store.Entity, g *graph.Graph, storeDB *store.Store) [][]string {
+func computeModuleEdges(entities []*store.Entity, g *graph.Graph, storeDB *store.SQLStore) [][]string {
 	// Build entity ID to module map
 	entityToModule := make(map[string]string)
 	for _, e := range entities {
@@ -669,7 +697,7 @@ func generateModuleD2Diagram(modules []string, edges [][]string) string {
 }
 
 // generateCycleMermaidDiagram generates a Mermaid diagram for a cycle
-func generateCycleMermaidDiagram(cycle []string, storeDB *store.Store) string {
+func generateCycleMermaidDiagram(cycle []string, storeDB *store.SQLStore) string {
 	var sb strings.Builder
 	sb.WriteString("flowchart LR\n")
 
@@ -695,7 +723,7 @@ func generateCycleMermaidDiagram(cycle []string, storeDB *store.Store) string {
 }
 
 // generateCycleD2Diagram generates a D2 diagram for a cycle
-func generateCycleD2Diagram(cycle []string, storeDB *store.Store) string {
+func generateCycleD2Diagram(cycle []string, storeDB *store.SQLStore) string {
 	var sb strings.Builder
 	sb.WriteString("direction: right\n\n")
 
@@ -719,7 +747,7 @@ func generateCycleD2Diagram(cycle []string, storeDB *store.Store) string {
 }
 
 // getEntityName gets the name of an entity by ID
-func getEntityName(entityID string, storeDB *store.Store) string {
+func getEntityName(entityID string, storeDB *store.SQLStore) string {
 	entity, err := storeDB.GetEntity(entityID)
 	if err != nil {
 		return entityID
@@ -763,3 +791,253 @@ func writeGuideOutput(cmd *cobra.Command, content string) error {
 	fmt.Fprint(cmd.OutOrStdout(), content)
 	return nil
 }
+
+// minCommunitySize is the smallest cluster listed by guide modules --communities
+const minCommunitySize = 3
+
+// guideCluster summarizes one detected community
+type guideCluster struct {
+	id        int
+	size      int
+	dirs      map[string]int // directory -> member count
+	home      string         // directory holding the most members
+	homeCount int
+	cross     int // dependencies to or from other clusters
+}
+
+// label names a cluster by number and home directory
+func (c *guideCluster) label() string {
+	return fmt.Sprintf("C%d %s", c.id+1, c.home)
+}
+
+// writeCommunitySections detects communities over calls and uses_type edges
+// and compares them with the directory structure.
+func writeCommunitySections(sb *strings.Builder, storeDB *store.SQLStore, entities []*store.Entity) error {
+	byID := make(map[string]*store.Entity, len(entities))
+	for _, e := range entities {
+		byID[e.ID] = e
+	}
+	deps, err := storeDB.GetAllDependencies()
+	if err != nil {
+		return fmt.Errorf("failed to get dependencies: %w", err)
+	}
+	adjacency := make(map[string][]string)
+	var edges [][2]string
+	for _, d := range deps {
+		if d.DepType != "calls" && d.DepType != "uses_type" {
+			continue
+		}
+		if byID[d.FromID] == nil || byID[d.ToID] == nil || d.FromID == d.ToID {
+			continue
+		}
+		adjacency[d.FromID] = append(adjacency[d.FromID], d.ToID)
+		edges = append(edges, [2]string{d.FromID, d.ToID})
+	}
+
+	sb.WriteString("## Communities\n\n")
+	if len(edges) == 0 {
+		sb.WriteString("No calls or uses_type dependencies to cluster.\n\n")
+		return nil
+	}
+	result := metrics.DetectCommunities(adjacency, metrics.CommunityConfig{Resolution: guideResolution})
+
+	clusters := make([]*guideCluster, result.Count)
+	for i := range clusters {
+		clusters[i] = &guideCluster{id: i, dirs: make(map[string]int)}
+	}
+	dirCounts := make(map[string]map[int]int)
+	for id, c := range result.Communities {
+		dir := extractModuleFromPath(byID[id].FilePath)
+		clusters[c].size++
+		clusters[c].dirs[dir]++
+		if dirCounts[dir] == nil {
+			dirCounts[dir] = make(map[int]int)
+		}
+		dirCounts[dir][c]++
+	}
+	for _, c := range clusters {
+		for dir, n := range c.dirs {
+			if n > c.homeCount || (n == c.homeCount && dir < c.home) {
+				c.home, c.homeCount = dir, n
+			}
+		}
+	}
+	// The cluster most of a directory's entities belong to
+	dirHome := make(map[string]int, len(dirCounts))
+	for dir, counts := range dirCounts {
+		best, bestN := -1, 0
+		for c, n := range counts {
+			if n > bestN || (n == bestN && c < best) {
+				best, bestN = c, n
+			}
+		}
+		dirHome[dir] = best
+	}
+
+	// Dependencies between clusters, and each entity's links per cluster
+	pairs := make(map[[2]int]int)
+	links := make(map[string]map[int]int)
+	link := func(id string, c int) {
+		if links[id] == nil {
+			links[id] = make(map[int]int)
+		}
+		links[id][c]++
+	}
+	for _, e := range edges {
+		cf, ct := result.Communities[e[0]], result.Communities[e[1]]
+		link(e[0], ct)
+		link(e[1], cf)
+		if cf != ct {
+			pairs[[2]int{cf, ct}]++
+			clusters[cf].cross++
+			clusters[ct].cross++
+		}
+	}
+
+	var listed []*guideCluster
+	listedEntities, aligned, small := 0, 0, 0
+	for _, c := range clusters {
+		if c.size < minCommunitySize {
+			small++
+			continue
+		}
+		listed = append(listed, c)
+		listedEntities += c.size
+		aligned += c.homeCount
+	}
+
+	sb.WriteString(fmt.Sprintf("%d clusters over %d entities from calls and uses_type dependencies, modularity %.2f.",
+		result.Count, len(result.Communities), result.Modularity))
+	if small > 0 {
+		sb.WriteString(fmt.Sprintf(" %d clusters with fewer than %d entities are not listed.", small, minCommunitySize))
+	}
+	if listedEntities > 0 {
+		sb.WriteString(fmt.Sprintf(" %.0f%% of listed entities sit in their cluster's home directory.", 100*float64(aligned)/float64(listedEntities)))
+	}
+	sb.WriteString("\n\n")
+
+	shown := listed
+	if guideMaxNodes > 0 && len(shown) > guideMaxNodes {
+		shown = shown[:guideMaxNodes]
+	}
+	sb.WriteString("| Cluster | Entities | Home directory | Alignment | Other directories | Cross-cluster deps |\n")
+	sb.WriteString("|---------|----------|----------------|-----------|-------------------|--------------------|\n")
+	for _, c := range shown {
+		others := make([]string, 0, len(c.dirs))
+		for dir := range c.dirs {
+			if dir != c.home {
+				others = append(others, dir)
+			}
+		}
+		sort.Slice(others, func(i, j int) bool {
+			if c.dirs[others[i]] != c.dirs[others[j]] {
+				return c.dirs[others[i]] > c.dirs[others[j]]
+			}
+			return others[i] < others[j]
+		})
+		var parts []string
+		for i, dir := range others {
+			if i == 3 {
+				parts = append(parts, fmt.Sprintf("+%d more", len(others)-3))
+				break
+			}
+			parts = append(parts, fmt.Sprintf("%s (%d)", dir, c.dirs[dir]))
+		}
+		sb.WriteString(fmt.Sprintf("| C%d | %d | %s | %.0f%% | %s | %d |\n",
+			c.id+1, c.size, c.home, 100*float64(c.homeCount)/float64(c.size), strings.Join(parts, ", "), c.cross))
+	}
+	sb.WriteString("\n")
+
+	// Cluster dependencies among the clusters shown
+	inShown := make(map[int]bool, len(shown))
+	for _, c := range shown {
+		inShown[c.id] = true
+	}
+	type clusterPair struct {
+		from, to int
+		count    int
+	}
+	var shownPairs []clusterPair
+	for p, n := range pairs {
+		if inShown[p[0]] && inShown[p[1]] {
+			shownPairs = append(shownPairs, clusterPair{p[0], p[1], n})
+		}
+	}
+	sort.Slice(shownPairs, func(i, j int) bool {
+		if shownPairs[i].count != shownPairs[j].count {
+			return shownPairs[i].count > shownPairs[j].count
+		}
+		if shownPairs[i].from != shownPairs[j].from {
+			return shownPairs[i].from < shownPairs[j].from
+		}
+		return shownPairs[i].to < shownPairs[j].to
+	})
+	if len(shownPairs) > 15 {
+		shownPairs = shownPairs[:15] // the heaviest; the rest clutter the diagram
+	}
+	if len(shownPairs) > 0 {
+		sb.WriteString("## Cluster Dependencies\n\n")
+		labels := make([]string, len(shown))
+		for i, c := range shown {
+			labels[i] = c.label()
+		}
+		var diagramEdges [][]string
+		for _, p := range shownPairs {
+			diagramEdges = append(diagramEdges, []string{clusters[p.from].label(), clusters[p.to].label()})
+		}
+		sb.WriteString("```")
+		sb.WriteString(guideFormat)
+		sb.WriteString("\n")
+		if guideFormat == "d2" {
+			sb.WriteString(generateModuleD2Diagram(labels, diagramEdges))
+		} else {
+			sb.WriteString(generateModuleMermaidDiagram(labels, diagramEdges))
+		}
+		sb.WriteString("```\n\n")
+
+		sb.WriteString("| From | To | Dependencies |\n")
+		sb.WriteString("|------|----|--------------|\n")
+		for _, p := range shownPairs {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d |\n", clusters[p.from].label(), clusters[p.to].label(), p.count))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Entities whose directory mostly belongs to another listed cluster
+	type misplaced struct {
+		e            *store.Entity
+		own, dirHome int
+		ownLinks     int
+		dirLinks     int
+	}
+	var moves []misplaced
+	for id, c := range result.Communities {
+		e := byID[id]
+		home := dirHome[extractModuleFromPath(e.FilePath)]
+		if home == c || clusters[c].size < minCommunitySize || clusters[home].size < minCommunitySize {
+			continue
+		}
+		moves = append(moves, misplaced{e: e, own: c, dirHome: home, ownLinks: links[id][c], dirLinks: links[id][home]})
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].ownLinks != moves[j].ownLinks {
+			return moves[i].ownLinks > moves[j].ownLinks
+		}
+		return moves[i].e.ID < moves[j].e.ID
+	})
+	if len(moves) > 0 {
+		sb.WriteString("## Entities Outside Their Cluster's Directory\n\n")
+		sb.WriteString(fmt.Sprintf("%d entities belong to a different cluster than most of their directory.\n\n", len(moves)))
+		sb.WriteString("| Entity | Location | Cluster | Directory's cluster | Links (cluster / directory's) |\n")
+		sb.WriteString("|--------|----------|---------|---------------------|-------------------------------|\n")
+		for i, m := range moves {
+			if guideMaxNodes > 0 && i == guideMaxNodes {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %d / %d |\n",
+				m.e.Name, formatStoreLocation(m.e), clusters[m.own].label(), clusters[m.dirHome].label(), m.ownLinks, m.dirLinks))
+		}
+		sb.WriteString("\n")
+	}
+	return nil
+}
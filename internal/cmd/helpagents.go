@@ -124,6 +124,8 @@ cx find --keystones               # Most important entities
 cx find --important --top 20      # Top by PageRank
 cx find --type F Login            # Functions only (F|T|M|C)
 cx find --tag critical            # Filter by tag
+cx find --chokepoints             # Entities that dominate much of the code
+cx find --clones                  # Copy-pasted functions, up to renames
 ` + "```" + `
 
 ---
@@ -136,6 +138,7 @@ cx trace <from> <to>              # Shortest path between entities
 cx trace <from> <to> --all        # All paths
 cx trace <entity> --callers       # What calls this?
 cx trace <entity> --callees       # What does this call?
+cx trace <entity> --dominators    # What every path to this goes through
 ` + "```" + `
 
 ---
@@ -215,6 +218,7 @@ cx find Login --at HEAD~10        # Find entities at older state
 cx history --stats                # Commit history with entity counts
 cx diff --from HEAD~1             # What changed since last commit
 cx blame Entity                   # Who changed this and when?
+cx owners Entity                  # Who wrote it (git blame) and its bus factor
 cx stale --scans 5                # Unchanged for 5+ scans
 cx catchup --since v1.0           # Changes since tag/ref
 cx safe <file> --trend            # Entity count trends over time
@@ -367,6 +371,7 @@ cx render report.html --embed           # Inline SVGs in HTML (replaces D2 code
 | Run tests | ` + "`cx test --diff --run`" + ` |
 | Bookmark code | ` + "`cx tag <entity> <tags>`" + ` |
 | Entity history | ` + "`cx blame <entity>`" + ` or ` + "`cx show <entity> --history`" + ` |
+| Who to ask | ` + "`cx owners <entity>`" + ` |
 | Compare states | ` + "`cx diff --from HEAD~1`" + ` |
 | Old code state | ` + "`cx show <entity> --at v1.0`" + ` |
 | What changed? | ` + "`cx catchup --since v1.0`" + ` |
@@ -467,6 +472,11 @@ func generateAgentReferenceJSON() string {
       "usage": "cx blame <entity>",
       "flags": ["--limit"]
     },
+    "owners": {
+      "purpose": "Show who wrote an entity, file or package, its bus factor and CODEOWNERS mismatches",
+      "usage": "cx owners <entity|path>",
+      "flags": ["--codeowners", "--top"]
+    },
     "stale": {
       "purpose": "Find entities unchanged for N scans",
       "usage": "cx stale --scans 5",
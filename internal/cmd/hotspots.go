@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/diff"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// defaultHotspotWindow is the git history window hotspots are computed over
+const defaultHotspotWindow = "90d"
+
+// HotspotsOutput is the result of cx find --hotspots
+type HotspotsOutput struct {
+	Since    string               `yaml:"since" json:"since"`
+	Commits  int                  `yaml:"commits" json:"commits"`
+	Hotspots []report.HotspotData `yaml:"hotspots" json:"hotspots"`
+}
+
+// parseSinceWindow parses a history window as a number of days, weeks or
+// years (90d, 12w, 1y), a Go duration (720h) or a date (2026-01-02), and
+// returns its start relative to now. An empty window is the default.
+func parseSinceWindow(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		s = defaultHotspotWindow
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n > 0 {
+		switch strings.ToLower(s[len(s)-1:]) {
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		case "w":
+			return now.AddDate(0, 0, -7*n), nil
+		case "y":
+			return now.AddDate(-n, 0, 0), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid window %q: use e.g. 90d, 12w, 1y, 720h or 2026-01-02", s)
+}
+
+// hotspotHistory returns the commits since the start of the window from the
+// git repository of the project
+func hotspotHistory(window string) ([]diff.CommitHunks, time.Time, error) {
+	since, err := parseSinceWindow(window, time.Now())
+	if err != nil {
+		return nil, since, err
+	}
+	cxDir, err := config.FindConfigDir(".")
+	if err != nil {
+		return nil, since, fmt.Errorf("cx not initialized: run 'cx scan' first")
+	}
+	commits, err := diff.NewGitDiff(filepath.Dir(cxDir)).CommitHunksSince(since)
+	if err != nil {
+		return nil, since, fmt.Errorf("read git history: %w", err)
+	}
+	return commits, since, nil
+}
+
+// runFindHotspots ranks entities by churn × complexity over the --since window
+func runFindHotspots(cmd *cobra.Command) error {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	density, err := output.ParseDensity(outputDensity)
+	if err != nil {
+		return fmt.Errorf("invalid density: %w", err)
+	}
+
+	commits, since, err := hotspotHistory(findSince)
+	if err != nil {
+		return err
+	}
+
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	hotspots, err := report.Hotspots(storeDB, diff.ProjectToHead(commits), findTop)
+	if err != nil {
+		return err
+	}
+	result := &HotspotsOutput{
+		Since:    since.UTC().Format(time.RFC3339),
+		Commits:  len(commits),
+		Hotspots: hotspots,
+	}
+
+	formatter, err := output.GetFormatter(format)
+	if err != nil {
+		return fmt.Errorf("failed to get formatter: %w", err)
+	}
+	return formatter.FormatToWriter(cmd.OutOrStdout(), result, density)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSinceWindow(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", now.AddDate(0, 0, -90)},
+		{"30d", now.AddDate(0, 0, -30)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"1y", now.AddDate(-1, 0, 0)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2026-01-02", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSinceWindow(tt.in, now)
+		if err != nil {
+			t.Errorf("parseSinceWindow(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSinceWindow(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"d", "0d", "-5d", "HEAD~5", "soon"} {
+		if _, err := parseSinceWindow(bad, now); err == nil {
+			t.Errorf("parseSinceWindow(%q) should fail", bad)
+		}
+	}
+}
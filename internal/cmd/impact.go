@@ -27,6 +27,9 @@ Uses forward BFS through the dependency graph to find:
   3. Affected tests — test functions that exercise the changed code
   4. Risk assessment — keystone status, dependent count, test coverage
 
+Files and entities that git and scan history show changing together with
+the target, without a dependency edge, are listed under co_changes.
+
 Examples:
   cx impact src/parser/walk.go              # Impact of changing this file
   cx impact sa-fn-abc123                    # Impact of changing this entity
@@ -181,6 +184,17 @@ func runImpact(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Files and entities that historically change with the target but that
+	// the traversal did not reach
+	skip := make(map[string]bool, len(seen))
+	for id := range seen {
+		skip[id] = true
+	}
+	for _, a := range affected {
+		skip[filepath.ToSlash(a.entity.FilePath)] = true
+	}
+	coChanges := strongCoChanges(storeDB, rootEntities, skip)
+
 	// Sort: tests first (for suggested command), then by hop, then by importance
 	sort.Slice(affected, func(i, j int) bool {
 		if affected[i].hop != affected[j].hop {
@@ -226,6 +240,11 @@ func runImpact(cmd *cobra.Command, args []string) error {
 		},
 		Affected:        make(map[string]*output.AffectedEntity),
 		Recommendations: buildRecommendations(riskLevel, testPackages, testNames, rootEntities, storeDB),
+		CoChanges:       coChanges,
+	}
+	if len(coChanges) > 0 {
+		impactOut.Recommendations = append(impactOut.Recommendations,
+			fmt.Sprintf("Check %d co-changing files/entities with no dependency on the target (see co_changes)", len(coChanges)))
 	}
 
 	for _, a := range affected {
@@ -266,7 +285,7 @@ func runImpact(cmd *cobra.Command, args []string) error {
 }
 
 // computeRiskLevel determines the risk of changing the target entities.
-func computeImpactRiskLevel(roots []*store.Entity, affected []impactCmdEntry, storeDB *store.Store) string {
+func computeImpactRiskLevel(roots []*store.Entity, affected []impactCmdEntry, storeDB *store.SQLStore) string {
 	// Check if any root is a keystone
 	hasKeystone := false
 	for _, e := range roots {
@@ -311,7 +330,7 @@ func computeImpactRiskLevel(roots []*store.Entity, affected []impactCmdEntry, st
 }
 
 // buildRecommendations generates actionable suggestions.
-func buildRecommendations(riskLevel string, testPkgs map[string]bool, testNames []string, roots []*store.Entity, storeDB *store.Store) []string {
+func buildRecommendations(riskLevel string, testPkgs map[string]bool, testNames []string, roots []*store.Entity, storeDB *store.SQLStore) []string {
 	var recs []string
 
 	// Suggested test command
@@ -199,3 +199,57 @@ func TestRunImpact_DepthLimit(t *testing.T) {
 		t.Errorf("depth 1 should NOT find FuncD, got:\n%s", out)
 	}
 }
+
+func TestRunImpact_CoChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	cxDir := tmpDir + "/.cx"
+
+	st, err := store.Open(cxDir)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+
+	st.CreateEntity(&store.Entity{
+		ID: "sa-fn-h-CreateUser", Name: "CreateUser", EntityType: "function",
+		FilePath: "api/users.go", LineStart: 1, LineEnd: intPtr(10),
+		Signature: "func CreateUser()", Visibility: "public", Status: "active", Language: "go",
+	})
+	st.CreateEntity(&store.Entity{
+		ID: "sa-ty-t-User", Name: "User", EntityType: "type",
+		FilePath: "web/user.ts", LineStart: 1, LineEnd: intPtr(5),
+		Signature: "interface User", Visibility: "public", Status: "active", Language: "typescript",
+	})
+	st.SaveCoChanges(store.CoChangeFile, []store.CoChange{
+		{From: "api/users.go", To: "migrations/002_users.sql", Support: 5, Confidence: 0.8},
+		{From: "api/users.go", To: "README.md", Support: 3, Confidence: 0.2},
+	})
+	st.SaveCoChanges(store.CoChangeEntity, []store.CoChange{
+		{From: "sa-fn-h-CreateUser", To: "sa-ty-t-User", Support: 4, Confidence: 0.75},
+	})
+	st.Close()
+
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	var buf bytes.Buffer
+	impactCmd.SetOut(&buf)
+
+	impactDepth = 2
+	outputFormat = "yaml"
+	outputDensity = "medium"
+
+	if err := runImpact(impactCmd, []string{"api/users.go"}); err != nil {
+		t.Fatalf("runImpact failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"co_changes:", "migrations/002_users.sql", "name: User", "with: CreateUser"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "README.md") {
+		t.Errorf("weak co-change README.md should not be reported:\n%s", out)
+	}
+}
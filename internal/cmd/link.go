@@ -98,7 +98,7 @@ func runLink(cmd *cobra.Command, args []string) error {
 	return handleLinkCreate(storeDB, entityID, externalID)
 }
 
-func handleLinkCreate(storeDB *store.Store, entityID, externalID string) error {
+func handleLinkCreate(storeDB *store.SQLStore, entityID, externalID string) error {
 	link := &store.EntityLink{
 		EntityID:       entityID,
 		ExternalSystem: linkSystem,
@@ -115,7 +115,7 @@ func handleLinkCreate(storeDB *store.Store, entityID, externalID string) error {
 	return nil
 }
 
-func handleLinkList(storeDB *store.Store, entityID string) error {
+func handleLinkList(storeDB *store.SQLStore, entityID string) error {
 	links, err := storeDB.GetLinks(entityID)
 	if err != nil {
 		return fmt.Errorf("failed to get links: %w", err)
@@ -134,7 +134,7 @@ func handleLinkList(storeDB *store.Store, entityID string) error {
 	return nil
 }
 
-func handleLinkRemove(storeDB *store.Store, entityID, externalID string) error {
+func handleLinkRemove(storeDB *store.SQLStore, entityID, externalID string) error {
 	// First check if link exists with default system
 	existingLinks, err := storeDB.GetLinks(entityID)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/anthropics/cx/internal/config"
 	"github.com/anthropics/cx/internal/output"
 	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
 	"github.com/spf13/cobra"
 )
 
@@ -36,6 +37,11 @@ Filters:
   --filter C     Constants only
   --lang go      Filter by language
 
+Token budget:
+  --max-tokens N keeps whole files, in path order, while their skeletons
+                 fit in N tokens, counted with the configured tokenizer
+                 (tokenizer in .cx/config.yaml), and reports what it left out
+
 Output Formats:
   --format text  Human-readable Go-like code (default)
   --format yaml  YAML format
@@ -47,6 +53,7 @@ Examples:
   cx map --filter F               # Show only functions
   cx map --filter T               # Show only types
   cx map --lang go                # Filter by language
+  cx map --max-tokens 8000        # Fit the map into an 8k token budget
   cx map --format yaml            # YAML output`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runMap,
@@ -56,6 +63,7 @@ var (
 	mapFilter string
 	mapLang   string
 	mapDepth  int
+	mapTokens int
 )
 
 func init() {
@@ -64,6 +72,7 @@ func init() {
 	mapCmd.Flags().StringVar(&mapFilter, "filter", "", "Filter by entity type (F=function, T=type, M=method, C=constant)")
 	mapCmd.Flags().StringVar(&mapLang, "lang", "", "Filter by language (go, typescript, python, rust, java)")
 	mapCmd.Flags().IntVar(&mapDepth, "depth", 0, "How deep to expand nested types (0 = no limit)")
+	mapCmd.Flags().IntVar(&mapTokens, "max-tokens", 0, "Token budget for the skeletons (0 = no limit)")
 }
 
 func runMap(cmd *cobra.Command, args []string) error {
@@ -106,10 +115,16 @@ func runMap(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to query entities: %w", err)
 	}
 
+	counter, err := loadTokenizer()
+	if err != nil {
+		return err
+	}
+	budget := fitMapBudget(entities, counter, mapTokens)
+
 	// Handle "text" format specially for map command
 	formatLower := strings.ToLower(outputFormat)
 	if formatLower == "text" {
-		return outputMapText(cmd, entities)
+		return outputMapText(cmd, budget)
 	}
 
 	// Parse format for yaml/json
@@ -118,7 +133,58 @@ func runMap(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid format: %w", err)
 	}
 
-	return outputMapStructured(cmd, entities, format)
+	return outputMapStructured(cmd, budget, format)
+}
+
+// mapBudget is the part of the map that fits the token budget
+type mapBudget struct {
+	entities  []*store.Entity // kept, by file and line
+	tokens    int             // of the kept skeletons
+	omitted   int             // entities left out
+	files     int             // files left out
+	max       int             // the budget, 0 for none
+	tokenizer string
+}
+
+// fitMapBudget orders entities by file and line and keeps whole files
+// while their skeletons, as printed in text format, fit in maxTokens
+func fitMapBudget(entities []*store.Entity, counter tokens.Counter, maxTokens int) *mapBudget {
+	sorted := append([]*store.Entity(nil), entities...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].FilePath != sorted[j].FilePath {
+			return sorted[i].FilePath < sorted[j].FilePath
+		}
+		return sorted[i].LineStart < sorted[j].LineStart
+	})
+
+	b := &mapBudget{max: maxTokens, tokenizer: counter.Name()}
+	for start := 0; start < len(sorted); {
+		end := start
+		text := "// " + sorted[start].FilePath + "\n"
+		for end < len(sorted) && sorted[end].FilePath == sorted[start].FilePath {
+			text += entitySkeleton(sorted[end]) + "\n\n"
+			end++
+		}
+		cost := counter.Count(text)
+		if maxTokens > 0 && b.tokens+cost > maxTokens {
+			b.omitted += end - start
+			b.files++
+		} else {
+			b.entities = append(b.entities, sorted[start:end]...)
+			b.tokens += cost
+		}
+		start = end
+	}
+	return b
+}
+
+// entitySkeleton returns the stored skeleton, or one generated from the
+// entity's fields
+func entitySkeleton(e *store.Entity) string {
+	if e.Skeleton != "" {
+		return e.Skeleton
+	}
+	return generateSkeletonFromEntity(e)
 }
 
 // mapTypeFilter converts short type filter to entity type
@@ -142,7 +208,8 @@ func mapTypeFilter(f string) string {
 }
 
 // outputMapText outputs entities in Go-like text format grouped by file
-func outputMapText(cmd *cobra.Command, entities []*store.Entity) error {
+func outputMapText(cmd *cobra.Command, budget *mapBudget) error {
+	entities := budget.entities
 	// Group entities by file
 	byFile := make(map[string][]*store.Entity)
 	for _, e := range entities {
@@ -175,13 +242,7 @@ func outputMapText(cmd *cobra.Command, entities []*store.Entity) error {
 
 		// Print each entity's skeleton
 		for _, e := range fileEntities {
-			skeleton := e.Skeleton
-			if skeleton == "" {
-				// Generate skeleton on the fly if not stored
-				skeleton = generateSkeletonFromEntity(e)
-			}
-
-			if skeleton != "" {
+			if skeleton := entitySkeleton(e); skeleton != "" {
 				// Print with proper indentation
 				lines := strings.Split(skeleton, "\n")
 				for _, line := range lines {
@@ -192,33 +253,35 @@ func outputMapText(cmd *cobra.Command, entities []*store.Entity) error {
 		}
 	}
 
+	if budget.omitted > 0 {
+		fmt.Fprintf(w, "\n// %d entities in %d files omitted to fit %d tokens (%d used, %s)\n",
+			budget.omitted, budget.files, budget.max, budget.tokens, budget.tokenizer)
+	}
 	return nil
 }
 
 // outputMapStructured outputs entities in YAML or JSON format
-func outputMapStructured(cmd *cobra.Command, entities []*store.Entity, format output.Format) error {
+func outputMapStructured(cmd *cobra.Command, budget *mapBudget, format output.Format) error {
 	// Build output structure
 	mapOutput := &MapOutput{
-		Files: make(map[string]*FileMap),
-		Count: len(entities),
+		Files:     make(map[string]*FileMap),
+		Count:     len(budget.entities),
+		Tokens:    budget.tokens,
+		Tokenizer: budget.tokenizer,
+		Omitted:   budget.omitted,
 	}
 
-	for _, e := range entities {
+	for _, e := range budget.entities {
 		if _, ok := mapOutput.Files[e.FilePath]; !ok {
 			mapOutput.Files[e.FilePath] = &FileMap{
 				Entities: make(map[string]*MapEntity),
 			}
 		}
 
-		skeleton := e.Skeleton
-		if skeleton == "" {
-			skeleton = generateSkeletonFromEntity(e)
-		}
-
 		mapOutput.Files[e.FilePath].Entities[e.Name] = &MapEntity{
 			Type:       e.EntityType,
 			Location:   formatEntityLocation(e),
-			Skeleton:   skeleton,
+			Skeleton:   entitySkeleton(e),
 			DocComment: e.DocComment,
 			Visibility: inferVisibility(e.Name),
 		}
@@ -235,8 +298,11 @@ func outputMapStructured(cmd *cobra.Command, entities []*store.Entity, format ou
 
 // MapOutput represents the output structure for cx map
 type MapOutput struct {
-	Files map[string]*FileMap `yaml:"files" json:"files"`
-	Count int                 `yaml:"count" json:"count"`
+	Files     map[string]*FileMap `yaml:"files" json:"files"`
+	Count     int                 `yaml:"count" json:"count"`
+	Tokens    int                 `yaml:"tokens" json:"tokens"` // of the skeletons
+	Tokenizer string              `yaml:"tokenizer" json:"tokenizer"`
+	Omitted   int                 `yaml:"omitted,omitempty" json:"omitted,omitempty"` // entities over --max-tokens
 }
 
 // FileMap represents entities in a single file
@@ -9,6 +9,7 @@ import (
 
 	"github.com/anthropics/cx/internal/output"
 	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
 )
 
 // TestMapCommand tests the cx map command functionality
@@ -111,7 +112,7 @@ func TestMapCommand(t *testing.T) {
 
 		var buf bytes.Buffer
 		mapCmd.SetOut(&buf)
-		err = outputMapText(mapCmd, entities)
+		err = outputMapText(mapCmd, fitMapBudget(entities, tokens.Heuristic{}, 0))
 		if err != nil {
 			t.Fatalf("output map text: %v", err)
 		}
@@ -149,7 +150,7 @@ func TestMapCommand(t *testing.T) {
 
 		var buf bytes.Buffer
 		mapCmd.SetOut(&buf)
-		err = outputMapStructured(mapCmd, entities, output.FormatYAML)
+		err = outputMapStructured(mapCmd, fitMapBudget(entities, tokens.Heuristic{}, 0), output.FormatYAML)
 		if err != nil {
 			t.Fatalf("output map structured: %v", err)
 		}
@@ -180,7 +181,7 @@ func TestMapCommand(t *testing.T) {
 
 		var buf bytes.Buffer
 		mapCmd.SetOut(&buf)
-		err = outputMapStructured(mapCmd, entities, output.FormatJSON)
+		err = outputMapStructured(mapCmd, fitMapBudget(entities, tokens.Heuristic{}, 0), output.FormatJSON)
 		if err != nil {
 			t.Fatalf("output map structured: %v", err)
 		}
@@ -198,6 +199,38 @@ func TestMapCommand(t *testing.T) {
 			t.Error("expected '\"count\"' in JSON output")
 		}
 	})
+
+	t.Run("token budget", func(t *testing.T) {
+		entities, err := st.QueryEntities(store.EntityFilter{Status: "active"})
+		if err != nil {
+			t.Fatalf("query entities: %v", err)
+		}
+		counter, err := tokens.Embedded()
+		if err != nil {
+			t.Fatal(err)
+		}
+		all := fitMapBudget(entities, counter, 0)
+		if all.omitted != 0 || len(all.entities) != 6 || all.tokens == 0 {
+			t.Fatalf("unlimited map kept %d entities in %d tokens, omitted %d", len(all.entities), all.tokens, all.omitted)
+		}
+
+		// main.go comes first and its three skeletons fit on their own
+		mainGo := counter.Count("// main.go\nfunc main() { ... }\n\nfunc init() { ... }\n\nconst Version = \"1.0.0\"\n\n")
+		budget := fitMapBudget(entities, counter, mainGo)
+		if len(budget.entities) != 3 || budget.omitted != 3 || budget.files != 2 || budget.tokens != mainGo {
+			t.Errorf("budget %d kept %d entities in %d tokens, omitted %d in %d files",
+				mainGo, len(budget.entities), budget.tokens, budget.omitted, budget.files)
+		}
+
+		var buf bytes.Buffer
+		mapCmd.SetOut(&buf)
+		if err := outputMapText(mapCmd, budget); err != nil {
+			t.Fatal(err)
+		}
+		if result := buf.String(); strings.Contains(result, "Handler") || !strings.Contains(result, "3 entities in 2 files omitted") {
+			t.Errorf("unexpected budgeted map:\n%s", result)
+		}
+	})
 }
 
 // TestMapTypeFilter tests the type filter conversion
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/metrics"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// refreshMetrics recomputes and stores the graph metrics of every active
+// entity. PageRank is warm-started from the stored scores and only
+// recomputed around entities in changed or whose degree moved, unless full
+// is set or nothing is stored yet. Betweenness is sampled from
+// metrics.betweenness_pivots sources. The returned run is not saved, so the
+// caller can attach the scan it belongs to.
+func refreshMetrics(storeDB *store.SQLStore, cfg *config.Config, changed []string, full bool) (*store.MetricsRun, error) {
+	start := time.Now()
+
+	entities, err := storeDB.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+	g, err := graph.BuildFromStore(storeDB)
+	if err != nil {
+		return nil, fmt.Errorf("build graph: %w", err)
+	}
+	active := make(map[string]bool, len(entities))
+	for _, e := range entities {
+		active[e.ID] = true
+	}
+
+	// Rank active entities only: metrics are stored per entity, so edges to
+	// archived ones would hold rank that is never reported and make every
+	// run look like the graph was resized
+	adjacency := make(map[string][]string, len(g.Edges))
+	for from, targets := range g.Edges {
+		if !active[from] {
+			continue
+		}
+		kept := make([]string, 0, len(targets))
+		for _, to := range targets {
+			if active[to] {
+				kept = append(kept, to)
+			}
+		}
+		adjacency[from] = kept
+	}
+	inDegree, outDegree := metrics.ComputeInOutDegree(adjacency)
+
+	// Scores of the previous graph, and the entities whose edges changed
+	previous := make(map[string]float64)
+	if !full {
+		stored, err := storeDB.GetAllMetrics()
+		if err != nil {
+			return nil, fmt.Errorf("load metrics: %w", err)
+		}
+		for _, m := range stored {
+			if !active[m.EntityID] {
+				continue
+			}
+			if m.PageRank > 0 {
+				previous[m.EntityID] = m.PageRank
+			}
+			if m.InDegree != inDegree[m.EntityID] || m.OutDegree != outDegree[m.EntityID] {
+				changed = append(changed, m.EntityID)
+			}
+		}
+	}
+
+	prConfig := metrics.PageRankConfig{
+		Damping:       cfg.Metrics.PageRankDamping,
+		MaxIterations: cfg.Metrics.PageRankIterations,
+		Tolerance:     0.0001,
+	}
+	pagerank := metrics.ComputePageRankIncremental(adjacency, previous, changed, prConfig)
+
+	pivots := cfg.Metrics.BetweennessPivots
+	betweenness := metrics.ComputeBetweennessSampled(adjacency, pivots)
+
+	now := time.Now()
+	bulkMetrics := make([]*store.Metrics, 0, len(entities))
+	for _, e := range entities {
+		bulkMetrics = append(bulkMetrics, &store.Metrics{
+			EntityID:    e.ID,
+			PageRank:    pagerank.Scores[e.ID],
+			Betweenness: betweenness[e.ID],
+			InDegree:    inDegree[e.ID],
+			OutDegree:   outDegree[e.ID],
+			ComputedAt:  now,
+		})
+	}
+	if err := storeDB.SaveBulkMetrics(bulkMetrics); err != nil {
+		return nil, fmt.Errorf("save metrics: %w", err)
+	}
+
+	run := &store.MetricsRun{
+		ComputedAt:  now,
+		Mode:        "full",
+		Entities:    len(entities),
+		Updated:     pagerank.Updated,
+		Iterations:  pagerank.Iterations,
+		Betweenness: "exact",
+		DurationMs:  int(time.Since(start).Milliseconds()),
+	}
+	if len(previous) > 0 {
+		run.Mode = "incremental"
+	}
+	if n := len(adjacency); pivots > 0 && pivots < n && n >= 3 {
+		run.Betweenness = "sampled"
+		run.Pivots = pivots
+	}
+	return run, nil
+}
+
+// saveMetricsRun records a metrics run against the latest scan.
+func saveMetricsRun(storeDB *store.SQLStore, run *store.MetricsRun) error {
+	if run.ScanID == 0 {
+		if latest, err := storeDB.GetLatestScanMetadata(); err == nil && latest != nil {
+			run.ScanID = latest.ID
+		}
+	}
+	return storeDB.SaveMetricsRun(run)
+}
+
+// metricsFreshness describes the latest metrics run for outputs, or nil if
+// none was recorded.
+func metricsFreshness(storeDB *store.SQLStore) *output.MetricsFreshness {
+	run, err := storeDB.GetLatestMetricsRun()
+	if err != nil || run == nil {
+		return nil
+	}
+	f := &output.MetricsFreshness{
+		ComputedAt:  run.ComputedAt.Format(time.RFC3339),
+		Ago:         formatDurationAgo(time.Since(run.ComputedAt)),
+		PageRank:    run.Mode,
+		Betweenness: run.Betweenness,
+	}
+	if run.Mode == "incremental" {
+		f.PageRank = fmt.Sprintf("incremental (%d of %d updated)", run.Updated, run.Entities)
+	}
+	if run.Betweenness == "sampled" {
+		f.Betweenness = fmt.Sprintf("sampled (%d pivots)", run.Pivots)
+	}
+	if latest, err := storeDB.GetLatestScanMetadata(); err == nil && latest != nil && latest.ID > run.ScanID {
+		f.Stale = true
+	}
+	return f
+}
@@ -0,0 +1,437 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/codeowners"
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/diff"
+	"github.com/anthropics/cx/internal/metrics"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// ownersCmd reports who wrote an entity, file or package
+var ownersCmd = &cobra.Command{
+	Use:   "owners [entity|path]",
+	Short: "Show who wrote an entity, file or package and its bus factor",
+	Long: `Show who knows a piece of code, from git blame over entity line ranges.
+
+Every line of the target's entities is attributed to the author who last
+changed it. Files aggregate their entities and packages (directories)
+aggregate their files. Lines that are not committed yet are not counted.
+
+Reports:
+  owners             Authors by share of the current lines
+  bus_factor         Fewest authors who together wrote more than half of it
+  main_author_share  Share of the top author
+  codeowners         CODEOWNERS owners and whether any of them wrote a line
+  breakdown          Entities of a file, or files of a package
+
+With --codeowners, checks the top entities by PageRank against the
+CODEOWNERS file and lists the keystones none of whose owners has written a
+current line. @org/team owners cannot be matched to authors and are skipped.
+
+Examples:
+  cx owners LoginUser                   # Who wrote LoginUser
+  cx owners internal/store/sqlite.go    # Owners of a file, per entity
+  cx owners internal/store              # Owners of a package, per file
+  cx owners --codeowners --top 50       # Keystones their CODEOWNERS never touched`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runOwners,
+}
+
+var (
+	ownersCodeowners bool
+	ownersTop        int
+)
+
+// maxSafeOwners caps the owners listed by cx safe
+const maxSafeOwners = 3
+
+func init() {
+	rootCmd.AddCommand(ownersCmd)
+
+	ownersCmd.Flags().BoolVar(&ownersCodeowners, "codeowners", false, "List keystones whose CODEOWNERS owners have not written any of their lines")
+	ownersCmd.Flags().IntVar(&ownersTop, "top", 20, "Number of top entities by PageRank to check (with --codeowners)")
+}
+
+// OwnersOutput is the result of cx owners
+type OwnersOutput struct {
+	Target          string               `yaml:"target" json:"target"`
+	Kind            string               `yaml:"kind" json:"kind"` // entity, file, package
+	Lines           int                  `yaml:"lines" json:"lines"`
+	BusFactor       int                  `yaml:"bus_factor" json:"bus_factor"`
+	MainAuthorShare float64              `yaml:"main_author_share" json:"main_author_share"`
+	Owners          []OwnerEntry         `yaml:"owners" json:"owners"`
+	Codeowners      *CodeownersCheck     `yaml:"codeowners,omitempty" json:"codeowners,omitempty"`
+	Breakdown       []OwnershipBreakdown `yaml:"breakdown,omitempty" json:"breakdown,omitempty"`
+}
+
+// OwnerEntry is one author's share of the target
+type OwnerEntry struct {
+	Name  string  `yaml:"name" json:"name"`
+	Email string  `yaml:"email,omitempty" json:"email,omitempty"`
+	Lines int     `yaml:"lines" json:"lines"`
+	Share float64 `yaml:"share" json:"share"`
+}
+
+// CodeownersCheck compares the CODEOWNERS owners of the target with its authors
+type CodeownersCheck struct {
+	File    string   `yaml:"file" json:"file"`
+	Owners  []string `yaml:"owners" json:"owners"`
+	Touched *bool    `yaml:"touched,omitempty" json:"touched,omitempty"` // unset when only teams own it
+}
+
+// OwnershipBreakdown is the ownership of one entity of a file or file of a package
+type OwnershipBreakdown struct {
+	Name            string  `yaml:"name" json:"name"`
+	Location        string  `yaml:"location,omitempty" json:"location,omitempty"`
+	Lines           int     `yaml:"lines" json:"lines"`
+	BusFactor       int     `yaml:"bus_factor" json:"bus_factor"`
+	MainAuthor      string  `yaml:"main_author" json:"main_author"`
+	MainAuthorShare float64 `yaml:"main_author_share" json:"main_author_share"`
+}
+
+// CodeownersReport is the result of cx owners --codeowners
+type CodeownersReport struct {
+	File      string              `yaml:"file" json:"file"`
+	Checked   int                 `yaml:"checked" json:"checked"`
+	Untouched []UntouchedKeystone `yaml:"untouched" json:"untouched"`
+}
+
+// UntouchedKeystone is a keystone none of whose CODEOWNERS owners wrote a current line
+type UntouchedKeystone struct {
+	Entity     string   `yaml:"entity" json:"entity"`
+	Location   string   `yaml:"location" json:"location"`
+	PageRank   float64  `yaml:"pagerank" json:"pagerank"`
+	Codeowners []string `yaml:"codeowners" json:"codeowners"`
+	Authors    []string `yaml:"authors" json:"authors"` // actual top authors
+}
+
+// ownershipIndex attributes entity lines to authors, blaming each file once
+type ownershipIndex struct {
+	gd    *diff.GitDiff
+	blame map[string][]diff.BlameLine
+}
+
+func newOwnershipIndex(projectRoot string) *ownershipIndex {
+	return &ownershipIndex{gd: diff.NewGitDiff(projectRoot), blame: make(map[string][]diff.BlameLine)}
+}
+
+// fileBlame returns the blame of a file, or nil when git cannot blame it
+func (o *ownershipIndex) fileBlame(path string) []diff.BlameLine {
+	if lines, ok := o.blame[path]; ok {
+		return lines
+	}
+	lines, err := o.gd.BlameFile(path)
+	if err != nil {
+		lines = nil
+	}
+	o.blame[path] = lines
+	return lines
+}
+
+// ownership computes the ownership of the lines of entities; lines shared
+// by nested entities (methods inside a class) are counted once
+func (o *ownershipIndex) ownership(entities []*store.Entity) metrics.Ownership {
+	covered := make(map[string]map[int]bool)
+	var lines []metrics.AuthorLines
+	for _, e := range entities {
+		blame := o.fileBlame(e.FilePath)
+		if blame == nil {
+			continue
+		}
+		seen := covered[e.FilePath]
+		if seen == nil {
+			seen = make(map[int]bool)
+			covered[e.FilePath] = seen
+		}
+		end := e.LineStart
+		if e.LineEnd != nil {
+			end = *e.LineEnd
+		}
+		for n := max(e.LineStart, 1); n <= end && n <= len(blame); n++ {
+			b := blame[n-1]
+			if seen[n] || b.Commit == "" {
+				continue
+			}
+			seen[n] = true
+			lines = append(lines, metrics.AuthorLines{Name: b.Author, Email: b.Email, Lines: 1})
+		}
+	}
+	return metrics.ComputeOwnership(lines)
+}
+
+// ownerEntries converts the top limit authors (all when limit <= 0) to output
+func ownerEntries(o metrics.Ownership, limit int) []OwnerEntry {
+	entries := make([]OwnerEntry, 0, len(o.Authors))
+	for i, a := range o.Authors {
+		if limit > 0 && i >= limit {
+			break
+		}
+		entries = append(entries, OwnerEntry{Name: a.Name, Email: a.Email, Lines: a.Lines, Share: roundShare(a.Share)})
+	}
+	return entries
+}
+
+// roundShare rounds a share to two decimals for output
+func roundShare(share float64) float64 {
+	return math.Round(share*100) / 100
+}
+
+// ownershipBreakdown summarizes the ownership of one part of the target
+func ownershipBreakdown(name, location string, o metrics.Ownership) OwnershipBreakdown {
+	b := OwnershipBreakdown{Name: name, Location: location, Lines: o.Lines, BusFactor: o.BusFactor, MainAuthorShare: roundShare(o.MainShare)}
+	if len(o.Authors) > 0 {
+		b.MainAuthor = o.Authors[0].Name
+	}
+	return b
+}
+
+// checkCodeowners reports the CODEOWNERS owners of paths and whether any
+// individual owner authored a line of o
+func checkCodeowners(file *codeowners.File, paths []string, o metrics.Ownership) *CodeownersCheck {
+	if file == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var owners []string
+	for _, p := range paths {
+		for _, owner := range file.Owners(p) {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	check := &CodeownersCheck{File: file.Path, Owners: owners}
+	if len(owners) == 0 {
+		return check
+	}
+	individuals, touched := 0, false
+	for _, owner := range owners {
+		if codeowners.IsTeam(owner) {
+			continue
+		}
+		individuals++
+		for _, a := range o.Authors {
+			if codeowners.MatchesAuthor(owner, a.Name, a.Email) {
+				touched = true
+			}
+		}
+	}
+	if individuals > 0 {
+		check.Touched = &touched
+	}
+	return check
+}
+
+// ownedEntities drops imports, which say nothing about who knows the code
+func ownedEntities(entities []*store.Entity) []*store.Entity {
+	result := make([]*store.Entity, 0, len(entities))
+	for _, e := range entities {
+		if e.EntityType != "import" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func runOwners(cmd *cobra.Command, args []string) error {
+	if !ownersCodeowners && len(args) == 0 {
+		return fmt.Errorf("target entity or path required (or use --codeowners)")
+	}
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	density, err := output.ParseDensity(outputDensity)
+	if err != nil {
+		return fmt.Errorf("invalid density: %w", err)
+	}
+
+	cxDir, err := config.FindConfigDir(".")
+	if err != nil {
+		return fmt.Errorf("cx not initialized: run 'cx scan' first")
+	}
+	projectRoot := filepath.Dir(cxDir)
+	storeDB, err := store.Open(cxDir)
+	if err != nil {
+		return fmt.Errorf("failed to open store: %w", err)
+	}
+	defer storeDB.Close()
+
+	file, err := codeowners.Load(projectRoot)
+	if err != nil {
+		return err
+	}
+	index := newOwnershipIndex(projectRoot)
+
+	var result any
+	if ownersCodeowners {
+		if file == nil {
+			return fmt.Errorf("no CODEOWNERS file found (looked in %s)", strings.Join(codeowners.Locations, ", "))
+		}
+		result, err = codeownersReport(storeDB, index, file, ownersTop)
+	} else {
+		result, err = ownersOfTarget(storeDB, index, file, args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	formatter, err := output.GetFormatter(format)
+	if err != nil {
+		return fmt.Errorf("failed to get formatter: %w", err)
+	}
+	return formatter.FormatToWriter(cmd.OutOrStdout(), result, density)
+}
+
+// ownersOfTarget resolves a package directory, a file or an entity and
+// reports its ownership
+func ownersOfTarget(storeDB *store.SQLStore, index *ownershipIndex, file *codeowners.File, target string) (*OwnersOutput, error) {
+	dir := strings.TrimSuffix(normalizeFilePath(target), "/")
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return ownersOfPackage(storeDB, index, file, dir)
+	}
+
+	if isFilePath(target) {
+		entities := ownedEntities(resolveFilePathToEntities(target, storeDB))
+		if len(entities) > 0 {
+			return ownersOfFile(index, file, target, entities), nil
+		}
+		if out, err := ownersOfPackage(storeDB, index, file, dir); err == nil {
+			return out, nil
+		}
+		return nil, fmt.Errorf("no entities found in %s: run 'cx scan' or check the path", target)
+	}
+
+	entity, err := resolveEntityByName(target, storeDB, "")
+	if err != nil {
+		return nil, err
+	}
+	o := index.ownership([]*store.Entity{entity})
+	return &OwnersOutput{
+		Target:          fmt.Sprintf("%s (%s)", entity.Name, formatStoreLocation(entity)),
+		Kind:            "entity",
+		Lines:           o.Lines,
+		BusFactor:       o.BusFactor,
+		MainAuthorShare: roundShare(o.MainShare),
+		Owners:          ownerEntries(o, 0),
+		Codeowners:      checkCodeowners(file, []string{entity.FilePath}, o),
+	}, nil
+}
+
+// ownersOfFile reports the ownership of a file with a breakdown per entity
+func ownersOfFile(index *ownershipIndex, file *codeowners.File, target string, entities []*store.Entity) *OwnersOutput {
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].FilePath != entities[j].FilePath {
+			return entities[i].FilePath < entities[j].FilePath
+		}
+		return entities[i].LineStart < entities[j].LineStart
+	})
+	paths := make(map[string]bool)
+	var pathList []string
+	out := &OwnersOutput{Target: target, Kind: "file"}
+	for _, e := range entities {
+		if !paths[e.FilePath] {
+			paths[e.FilePath] = true
+			pathList = append(pathList, e.FilePath)
+		}
+		eo := index.ownership([]*store.Entity{e})
+		if eo.Lines > 0 {
+			out.Breakdown = append(out.Breakdown, ownershipBreakdown(e.Name, formatStoreLocation(e), eo))
+		}
+	}
+	if len(pathList) == 1 {
+		out.Target = pathList[0]
+	}
+	o := index.ownership(entities)
+	out.Lines, out.BusFactor, out.MainAuthorShare = o.Lines, o.BusFactor, roundShare(o.MainShare)
+	out.Owners = ownerEntries(o, 0)
+	out.Codeowners = checkCodeowners(file, pathList, o)
+	return out
+}
+
+// ownersOfPackage reports the ownership of the files directly in a
+// directory with a breakdown per file
+func ownersOfPackage(storeDB *store.SQLStore, index *ownershipIndex, file *codeowners.File, dir string) (*OwnersOutput, error) {
+	all, err := storeDB.QueryEntities(store.EntityFilter{FilePath: dir + "/", Status: "active"})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+	byFile := make(map[string][]*store.Entity)
+	var files []string
+	var entities []*store.Entity
+	for _, e := range ownedEntities(all) {
+		if filepath.ToSlash(filepath.Dir(e.FilePath)) != dir {
+			continue
+		}
+		if byFile[e.FilePath] == nil {
+			files = append(files, e.FilePath)
+		}
+		byFile[e.FilePath] = append(byFile[e.FilePath], e)
+		entities = append(entities, e)
+	}
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("no entities found in package %s", dir)
+	}
+	sort.Strings(files)
+
+	o := index.ownership(entities)
+	out := &OwnersOutput{
+		Target:          dir,
+		Kind:            "package",
+		Lines:           o.Lines,
+		BusFactor:       o.BusFactor,
+		MainAuthorShare: roundShare(o.MainShare),
+		Owners:          ownerEntries(o, 0),
+		Codeowners:      checkCodeowners(file, files, o),
+	}
+	for _, f := range files {
+		if fo := index.ownership(byFile[f]); fo.Lines > 0 {
+			out.Breakdown = append(out.Breakdown, ownershipBreakdown(f, "", fo))
+		}
+	}
+	return out, nil
+}
+
+// codeownersReport checks the top entities by PageRank against CODEOWNERS
+func codeownersReport(storeDB *store.SQLStore, index *ownershipIndex, file *codeowners.File, top int) (*CodeownersReport, error) {
+	ranked, err := storeDB.GetTopByPageRank(top)
+	if err != nil {
+		return nil, fmt.Errorf("get top entities: %w", err)
+	}
+	report := &CodeownersReport{File: file.Path, Untouched: []UntouchedKeystone{}}
+	for _, m := range ranked {
+		e, err := storeDB.GetEntity(m.EntityID)
+		if err != nil || e == nil || e.Status != "active" {
+			continue
+		}
+		report.Checked++
+		o := index.ownership([]*store.Entity{e})
+		check := checkCodeowners(file, []string{e.FilePath}, o)
+		if o.Lines == 0 || check.Touched == nil || *check.Touched {
+			continue
+		}
+		var authors []string
+		for _, a := range ownerEntries(o, maxSafeOwners) {
+			authors = append(authors, fmt.Sprintf("%s (%.0f%%)", a.Name, a.Share*100))
+		}
+		report.Untouched = append(report.Untouched, UntouchedKeystone{
+			Entity:     e.Name,
+			Location:   formatStoreLocation(e),
+			PageRank:   m.PageRank,
+			Codeowners: check.Owners,
+			Authors:    authors,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anthropics/cx/internal/codeowners"
+	"github.com/anthropics/cx/internal/metrics"
+)
+
+func TestCheckCodeowners(t *testing.T) {
+	file, err := codeowners.Parse(strings.NewReader("* @acme/core\napi/ @ada\ndb/ @bob\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := metrics.ComputeOwnership([]metrics.AuthorLines{{Name: "Ada", Email: "ada@example.com", Lines: 10}})
+
+	if c := checkCodeowners(file, []string{"api/users.go"}, o); c.Touched == nil || !*c.Touched {
+		t.Errorf("api owners wrote the code: %+v", c)
+	}
+	if c := checkCodeowners(file, []string{"db/sql.go"}, o); c.Touched == nil || *c.Touched {
+		t.Errorf("db owner never touched the code: %+v", c)
+	}
+	if c := checkCodeowners(file, []string{"main.go"}, o); c.Touched != nil {
+		t.Errorf("team owners cannot be checked: %+v", c)
+	}
+	if c := checkCodeowners(nil, []string{"main.go"}, o); c != nil {
+		t.Errorf("no CODEOWNERS file should give no check: %+v", c)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/query"
+	"github.com/spf13/cobra"
+)
+
+// queryCmd runs Cypher-like pattern queries over the code graph
+var queryCmd = &cobra.Command{
+	Use:   "query <query>",
+	Short: "Query the code graph with Cypher-like patterns",
+	Long: `Query the code graph with a small Cypher-like language, for multi-hop
+questions that are awkward in SQL.
+
+  MATCH <pattern>, ... [WHERE <condition>]
+  RETURN [DISTINCT] <expr> [AS <name>], ... | RETURN *
+  [ORDER BY <expr> [DESC], ...] [SKIP <n>] [LIMIT <n>]
+
+Patterns:
+  (a)                          any entity
+  (a:function|method)          by entity type or kind (struct, interface, ...)
+  (a {file: "internal/mcp/**", tags: "critical"})
+                               by property; strings with * or ? are globs
+                               (** crosses directories), tags match if present
+  (a)-[:calls]->(b)            a dependency of a type (calls, uses_type,
+                               implements, extends, imports, references)
+  (a)<-[r:calls|uses_type]-(b) incoming; (a)-[]-(b) either direction
+  (a)-[:calls*]->(b)           1 or more hops; also *2, *1..3, *..4, *0..
+  p = (a)-[*]->(b)             bind the path to p
+
+A variable-length relationship matches each pair of endpoints once, by the
+shortest path between them. NONE(n IN nodes(p) WHERE ...) and ALL(...)
+conditions are applied while searching, so the path found is the shortest
+one that satisfies them.
+
+Node properties: ` + strings.Join(query.NodeProperties, ", ") + `.
+Metrics and coverage are null when not computed. Relationship properties:
+type, source (treesitter or scip), optional.
+
+Operators: = <> < <= > >= =~ (regexp) GLOB, STARTS WITH, ENDS WITH,
+CONTAINS, IN, IS [NOT] NULL, AND, OR, NOT, + - * / %.
+Functions: length, size, nodes, relationships, type, id, labels, toLower,
+toUpper, toString, coalesce, round; any/all/none/single(x IN list WHERE ...).
+Aggregates: count, collect, sum, avg, min, max, grouped by the other columns.
+
+Output uses --format: yaml and json print the result table, jsonl one row
+per line.
+
+Examples:
+  # Functions in internal/mcp that reach store Open without going through the daemon
+  cx query 'MATCH p = (a:function {file: "internal/mcp/**"})-[:calls*]->(b {name: "Open"})
+            WHERE NONE(n IN nodes(p) WHERE n.file GLOB "internal/daemon/**")
+            RETURN a.name, a.file, length(p) AS hops ORDER BY hops'
+
+  # Important functions with poor coverage
+  cx query 'MATCH (f:function) WHERE f.pagerank > 0.01 AND f.coverage < 50
+            RETURN f.name, f.pagerank, f.coverage ORDER BY f.pagerank DESC LIMIT 10'
+
+  # Most called entities per package
+  cx query 'MATCH (a)-[:calls]->(b) RETURN b.package, b.name, count(*) AS callers
+            ORDER BY callers DESC LIMIT 20'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runQuery,
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	density, err := output.ParseDensity(outputDensity)
+	if err != nil {
+		return fmt.Errorf("invalid density: %w", err)
+	}
+
+	q, err := query.Parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	engine, err := query.NewEngine(storeDB)
+	if err != nil {
+		return err
+	}
+	res, err := engine.Execute(q)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	out := &output.QueryOutput{
+		Query:   strings.TrimSpace(args[0]),
+		Columns: res.Columns,
+		Rows:    make([]map[string]any, 0, len(res.Rows)),
+		Count:   len(res.Rows),
+	}
+	for _, row := range res.Rows {
+		m := make(map[string]any, len(row))
+		for i, col := range res.Columns {
+			m[col] = row[i]
+		}
+		out.Rows = append(out.Rows, m)
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "%d rows\n", out.Count)
+	}
+
+	formatter, err := output.GetFormatter(format)
+	if err != nil {
+		return fmt.Errorf("failed to get formatter: %w", err)
+	}
+	return formatter.FormatToWriter(cmd.OutOrStdout(), out, density)
+}
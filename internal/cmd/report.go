@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/anthropics/cx/internal/graph"
 	"github.com/anthropics/cx/internal/output"
@@ -150,6 +151,12 @@ var (
 	changesUntil string // --until ref (defaults to HEAD)
 )
 
+// Health report flags
+var healthSince string // --since history window for hotspots
+
+// healthHotspots is the number of churn hotspots listed in a health report
+const healthHotspots = 10
+
 // reportHealthCmd generates health report data
 var reportHealthCmd = &cobra.Command{
 	Use:   "health",
@@ -163,10 +170,12 @@ Includes:
   - Issues by severity (critical, warning, info)
   - Coverage gaps for important code
   - Complexity hotspots
+  - Churn × complexity hotspots over the --since window (needs git)
   - Risk heat map diagram (D2 code)
 
 Examples:
   cx report health --data
+  cx report health --data --since 30d
   cx report health --data -o health.yaml
   cx report health --data --format json`,
 	RunE: runReportHealth,
@@ -195,6 +204,9 @@ func init() {
 	reportChangesCmd.Flags().StringVar(&changesSince, "since", "", "Starting reference (commit, tag, date)")
 	reportChangesCmd.Flags().StringVar(&changesUntil, "until", "HEAD", "Ending reference (default: HEAD)")
 	reportChangesCmd.MarkFlagRequired("since")
+
+	// Health-specific flags
+	reportHealthCmd.Flags().StringVar(&healthSince, "since", defaultHotspotWindow, "History window for churn hotspots (e.g., 90d, 12w, 2026-01-02)")
 }
 
 // runReportRoot handles the root report command
@@ -333,6 +345,19 @@ func runReportHealth(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("gather health data: %w", err)
 	}
 
+	// Hotspots need git history; a project without it still gets a report
+	if _, err := parseSinceWindow(healthSince, time.Now()); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if commits, since, err := hotspotHistory(healthSince); err == nil {
+		if err := gatherer.GatherHotspots(data, commits, since, healthHotspots); err != nil {
+			return fmt.Errorf("gather hotspots: %w", err)
+		}
+	}
+	if err := addHealthTrends(data, store, reportTheme); err != nil {
+		return fmt.Errorf("gather trends: %w", err)
+	}
+
 	return outputReportData(data)
 }
 
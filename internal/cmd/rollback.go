@@ -27,6 +27,9 @@ Reset modes:
 
 WARNING: --hard will permanently discard uncommitted changes.
 
+With the sqlite storage backend there is no working set: rollback restores
+the scan snapshot at ref (and drops later snapshots), so --hard is required.
+
 Arguments:
   ref     The commit, branch, or ref to reset to (default: HEAD~1)
           Supports: commit hashes, branch names, HEAD~N, tags
@@ -81,6 +84,11 @@ func runRollback(cmd *cobra.Command, args []string) error {
 
 	db := st.DB()
 
+	// The SQLite backend has no working set: a rollback restores a scan snapshot
+	if !st.SupportsVersionControl() && !rollbackHard {
+		return fmt.Errorf("the %s storage backend only supports hard rollbacks (use --hard)", st.Backend())
+	}
+
 	// For hard reset, require confirmation
 	if rollbackHard && !rollbackYes {
 		// Show what will be lost
@@ -108,6 +116,10 @@ func runRollback(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if !st.SupportsVersionControl() {
+		return restoreSnapshot(cmd, st, ref)
+	}
+
 	// Get the commit info before reset for display
 	var beforeHash string
 	err = db.QueryRow("SELECT COMMIT_HASH FROM dolt_log LIMIT 1").Scan(&beforeHash)
@@ -157,8 +169,37 @@ func runRollback(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// restoreSnapshot performs a hard rollback for backends without Dolt history
+// by restoring the scan snapshot at ref.
+func restoreSnapshot(cmd *cobra.Command, st *store.SQLStore, ref string) error {
+	before, _ := st.ResolveRef("HEAD")
+
+	snap, err := st.RestoreSnapshot(ref)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such snapshot") {
+			return fmt.Errorf("ref '%s' not found in history", ref)
+		}
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+
+	after := snap.CommitHash
+	if len(before) > 7 {
+		before = before[:7]
+	}
+	if len(after) > 7 {
+		after = after[:7]
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Reset (hard) to %s\n", ref)
+	fmt.Fprintf(cmd.OutOrStdout(), "HEAD: %s -> %s\n", before, after)
+	fmt.Fprintln(cmd.OutOrStdout(), "Run 'cx scan' to refresh entity bodies and metrics")
+	return nil
+}
+
 // hasUncommittedChanges checks if there are uncommitted changes in the working set
-func hasUncommittedChanges(st *store.Store) (bool, error) {
+func hasUncommittedChanges(st *store.SQLStore) (bool, error) {
+	if !st.SupportsVersionControl() {
+		return false, nil
+	}
 	db := st.DB()
 
 	// Query dolt_status for any changes
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/rules"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// entryRoot is an entity that graph analyses treat as an entry point
+type entryRoot struct {
+	entity *store.Entity
+	kind   string // a config.RootKinds kind, or configured, tagged or annotated
+}
+
+// httpHandlerMarkers are parameter types of HTTP handlers in common frameworks
+var httpHandlerMarkers = []string{
+	"http.ResponseWriter", "http.Request", "gin.Context", "echo.Context", "fiber.Ctx",
+	"HttpRequest", "HttpServletRequest", "req: Request",
+}
+
+// cliCommandMarkers are parameter types of CLI command handlers
+var cliCommandMarkers = []string{"cobra.Command", "cli.Context", "click.Context"}
+
+// rootKind returns the kind of entry point e is among kinds, or ""
+func rootKind(e *store.Entity, kinds []string) string {
+	callable := e.EntityType == "function" || e.EntityType == "method"
+	for _, kind := range kinds {
+		switch kind {
+		case "main":
+			if callable && (e.Name == "main" || (e.Name == "init" && e.Language == "go")) {
+				return kind
+			}
+		case "commands":
+			if callable && containsAny(e.Signature, cliCommandMarkers) {
+				return kind
+			}
+		case "handlers":
+			if callable && (e.Name == "ServeHTTP" || containsAny(e.Signature, httpHandlerMarkers)) {
+				return kind
+			}
+		case "exported":
+			if isExportedAPI(e) {
+				return kind
+			}
+		}
+	}
+	return ""
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExportedAPI reports whether e is public API: a public function, method
+// or type outside internal, cmd and test code
+func isExportedAPI(e *store.Entity) bool {
+	if e.Visibility != "pub" || e.EntityType == "import" || e.EntityType == "constant" || e.EntityType == "var" || e.EntityType == "variable" {
+		return false
+	}
+	path := "/" + e.FilePath
+	return !strings.Contains(path, "/internal/") && !strings.Contains(path, "/cmd/") && !strings.HasSuffix(e.FilePath, "_test.go")
+}
+
+// hasAnnotation reports whether e's doc comment or first source line
+// contains any of the markers
+func hasAnnotation(e *store.Entity, markers []string) bool {
+	if len(markers) == 0 {
+		return false
+	}
+	first, _, _ := strings.Cut(e.BodyText, "\n")
+	return containsAny(e.DocComment, markers) || containsAny(first, markers)
+}
+
+// loadRoots finds the entry points among the active entities according to
+// the roots configuration. It returns the roots sorted by kind and location,
+// and the active entities by ID.
+func loadRoots(storeDB *store.SQLStore, cfg config.RootsConfig) ([]entryRoot, map[string]*store.Entity, error) {
+	include, err := rules.CompileMatcher(cfg.Include)
+	if err != nil {
+		return nil, nil, fmt.Errorf("roots.include: %w", err)
+	}
+	entities, err := storeDB.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("query entities: %w", err)
+	}
+	tagged := make(map[string]bool)
+	if len(cfg.Tags) > 0 {
+		withTags, err := storeDB.FindByTags(cfg.Tags, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("query root tags: %w", err)
+		}
+		for _, e := range withTags {
+			tagged[e.ID] = true
+		}
+	}
+
+	byID := make(map[string]*store.Entity, len(entities))
+	var roots []entryRoot
+	for _, e := range entities {
+		byID[e.ID] = e
+		kind := rootKind(e, cfg.Kinds)
+		switch {
+		case kind != "":
+		case len(include) > 0 && include.Match(e):
+			kind = "configured"
+		case tagged[e.ID]:
+			kind = "tagged"
+		case hasAnnotation(e, cfg.Annotations):
+			kind = "annotated"
+		}
+		if kind != "" {
+			roots = append(roots, entryRoot{entity: e, kind: kind})
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if roots[i].kind != roots[j].kind {
+			return kindOrder(roots[i].kind) < kindOrder(roots[j].kind)
+		}
+		if roots[i].entity.FilePath != roots[j].entity.FilePath {
+			return roots[i].entity.FilePath < roots[j].entity.FilePath
+		}
+		return roots[i].entity.LineStart < roots[j].entity.LineStart
+	})
+	return roots, byID, nil
+}
+
+// extraRootKinds are the kinds of roots selected by the roots
+// configuration rather than by config.RootKinds, in order
+var extraRootKinds = []string{"configured", "tagged", "annotated"}
+
+// kindOrder orders root kinds as config.RootKinds, then extraRootKinds
+func kindOrder(kind string) int {
+	if i := slices.Index(config.RootKinds, kind); i >= 0 {
+		return i
+	}
+	return len(config.RootKinds) + slices.Index(extraRootKinds, kind)
+}
+
+// rootCounts counts roots per kind
+func rootCounts(roots []entryRoot) map[string]int {
+	counts := make(map[string]int)
+	for _, r := range roots {
+		counts[r.kind]++
+	}
+	return counts
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/store"
+)
+
+func TestRootKind(t *testing.T) {
+	kinds := config.RootKinds
+	tests := []struct {
+		entity *store.Entity
+		want   string
+	}{
+		{&store.Entity{Name: "main", EntityType: "function", FilePath: "cmd/cx/main.go"}, "main"},
+		{&store.Entity{Name: "init", EntityType: "function", Language: "go", FilePath: "internal/cmd/scan.go"}, "main"},
+		{&store.Entity{Name: "runScan", EntityType: "function", Signature: "(cmd: *cobra.Command, args: []string) -> error", FilePath: "internal/cmd/scan.go"}, "commands"},
+		{&store.Entity{Name: "handleLogin", EntityType: "function", Signature: "(w: http.ResponseWriter, r: *http.Request)", FilePath: "internal/api/auth.go"}, "handlers"},
+		{&store.Entity{Name: "ServeHTTP", EntityType: "method", FilePath: "internal/api/router.go"}, "handlers"},
+		{&store.Entity{Name: "Parse", EntityType: "function", Visibility: "pub", FilePath: "pkg/parser/parse.go"}, "exported"},
+		{&store.Entity{Name: "Parse", EntityType: "function", Visibility: "pub", FilePath: "internal/parser/parse.go"}, ""},
+		{&store.Entity{Name: "Version", EntityType: "constant", Visibility: "pub", FilePath: "version.go"}, ""},
+	}
+	for _, tt := range tests {
+		if got := rootKind(tt.entity, kinds); got != tt.want {
+			t.Errorf("rootKind(%s in %s) = %q, want %q", tt.entity.Name, tt.entity.FilePath, got, tt.want)
+		}
+	}
+	if got := rootKind(tests[5].entity, []string{"main", "commands"}); got != "" {
+		t.Errorf("exported API should not be a root unless configured, got %q", got)
+	}
+}
+
+func TestHasAnnotation(t *testing.T) {
+	markers := []string{"@Component", "wire:inject"}
+	tests := []struct {
+		entity *store.Entity
+		want   bool
+	}{
+		{&store.Entity{BodyText: "@Component public class Billing {\n}"}, true},
+		{&store.Entity{DocComment: "// NewStore is provided to the injector. wire:inject"}, true},
+		{&store.Entity{BodyText: "func build() {\n\t// @Component is not on the first line\n}"}, false},
+		{&store.Entity{Name: "plain"}, false},
+	}
+	for i, tt := range tests {
+		if got := hasAnnotation(tt.entity, markers); got != tt.want {
+			t.Errorf("case %d: hasAnnotation = %v, want %v", i, got, tt.want)
+		}
+	}
+	if hasAnnotation(tests[0].entity, nil) {
+		t.Error("no markers should match nothing")
+	}
+}
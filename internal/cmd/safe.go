@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/anthropics/cx/internal/codeowners"
 	"github.com/anthropics/cx/internal/config"
 	"github.com/anthropics/cx/internal/coverage"
 	"github.com/anthropics/cx/internal/diff"
@@ -51,16 +52,20 @@ Output Structure (full check):
     keystone_count:      Number of keystone entities affected
     coverage_gaps:       Undertested keystones in the blast radius
     drift_detected:      Whether code has drifted since scan
+    chokepoints:         Targets every path from the entry points goes through
 
   warnings:              List of actionable warnings
   recommendations:       Suggested actions before proceeding
   affected_keystones:    Details of keystone entities at risk
+  co_changes:            Files that usually change with the target (from history)
+  owners:                Top authors of the target's current lines (git blame)
 
 Risk Levels:
   critical:  Multiple undertested keystones affected, or drift detected
   high:      Keystones affected with coverage gaps
   medium:    Multiple entities affected, adequate coverage
   low:       Isolated changes with good test coverage
+  A chokepoint target raises the level by one step.
 
 Examples:
   cx safe src/auth/jwt.go              # Full safety assessment
@@ -147,10 +152,12 @@ func init() {
 
 // SafeOutput represents the safety check results
 type SafeOutput struct {
-	SafetyAssessment  *SafetyAssessment `yaml:"safety_assessment" json:"safety_assessment"`
-	Warnings          []string          `yaml:"warnings,omitempty" json:"warnings,omitempty"`
-	Recommendations   []string          `yaml:"recommendations" json:"recommendations"`
-	AffectedKeystones []KeystoneInfo    `yaml:"affected_keystones,omitempty" json:"affected_keystones,omitempty"`
+	SafetyAssessment  *SafetyAssessment       `yaml:"safety_assessment" json:"safety_assessment"`
+	Warnings          []string                `yaml:"warnings,omitempty" json:"warnings,omitempty"`
+	Recommendations   []string                `yaml:"recommendations" json:"recommendations"`
+	AffectedKeystones []KeystoneInfo          `yaml:"affected_keystones,omitempty" json:"affected_keystones,omitempty"`
+	CoChanges         []*output.CoChangeEntry `yaml:"co_changes,omitempty" json:"co_changes,omitempty"`
+	Owners            []OwnerEntry            `yaml:"owners,omitempty" json:"owners,omitempty"`
 }
 
 // SafetyAssessment contains the aggregate safety metrics
@@ -163,6 +170,7 @@ type SafetyAssessment struct {
 	CoverageGaps  int    `yaml:"coverage_gaps" json:"coverage_gaps"`
 	DriftDetected bool   `yaml:"drift_detected" json:"drift_detected"`
 	DriftedCount  int    `yaml:"drifted_count,omitempty" json:"drifted_count,omitempty"`
+	Chokepoints   int    `yaml:"chokepoints,omitempty" json:"chokepoints,omitempty"` // targets every path from the roots to much of the graph goes through
 }
 
 // KeystoneInfo contains details about an affected keystone
@@ -304,6 +312,19 @@ func runSafeFull(cmd *cobra.Command, target string) error {
 	// === Build Output ===
 	safeOutput := buildSafeOutput(target, affected, cfg, driftCount)
 
+	// === PHASE 4: Co-change Coupling ===
+	// Files and entities that change with the target but lie outside the
+	// blast radius are coupled in ways the graph cannot see
+	addSafeCoChanges(safeOutput, directEntities, affected, storeDB)
+
+	// === PHASE 5: Chokepoints ===
+	// A target that dominates much of the graph from the entry points has
+	// no alternative path around it, so a regression there is unavoidable
+	addSafeChokepoints(safeOutput, directEntities, g, storeDB, cfg)
+
+	// === PHASE 6: Ownership ===
+	addSafeOwners(safeOutput, directEntities, filepath.Dir(cxDir))
+
 	// Parse format
 	format, err := output.ParseFormat(outputFormat)
 	if err != nil {
@@ -1240,7 +1261,7 @@ func runSafeChanges(cmd *cobra.Command, args []string) error {
 }
 
 // findDirectEntitiesSafe finds entities that match the target (file or entity name)
-func findDirectEntitiesSafe(target string, storeDB *store.Store) ([]*safeEntity, error) {
+func findDirectEntitiesSafe(target string, storeDB *store.SQLStore) ([]*safeEntity, error) {
 	var results []*safeEntity
 
 	if isFilePath(target) {
@@ -1290,7 +1311,7 @@ func findDirectEntitiesSafe(target string, storeDB *store.Store) ([]*safeEntity,
 }
 
 // resolveFilePathToEntities tries multiple strategies to match a file path to entities
-func resolveFilePathToEntities(target string, storeDB *store.Store) []*store.Entity {
+func resolveFilePathToEntities(target string, storeDB *store.SQLStore) []*store.Entity {
 	normalizedTarget := normalizeFilePath(target)
 
 	// Strategy 1: Exact/prefix match (current behavior)
@@ -1368,7 +1389,7 @@ func resolveFilePathToEntities(target string, storeDB *store.Store) []*store.Ent
 }
 
 // findAffectedEntitiesSafe performs BFS to find all transitively affected entities
-func findAffectedEntitiesSafe(direct []*safeEntity, g *graph.Graph, storeDB *store.Store, cfg *config.Config, maxDepth int) map[string]*safeEntity {
+func findAffectedEntitiesSafe(direct []*safeEntity, g *graph.Graph, storeDB *store.SQLStore, cfg *config.Config, maxDepth int) map[string]*safeEntity {
 	affected := make(map[string]*safeEntity)
 
 	// Add direct entities
@@ -1429,7 +1450,7 @@ func findAffectedEntitiesSafe(direct []*safeEntity, g *graph.Graph, storeDB *sto
 }
 
 // enrichWithCoverageSafe adds coverage data to affected entities
-func enrichWithCoverageSafe(affected map[string]*safeEntity, storeDB *store.Store) {
+func enrichWithCoverageSafe(affected map[string]*safeEntity, storeDB *store.SQLStore) {
 	for _, e := range affected {
 		cov, err := coverage.GetEntityCoverage(storeDB, e.entity.ID)
 		if err == nil && cov != nil {
@@ -1439,7 +1460,7 @@ func enrichWithCoverageSafe(affected map[string]*safeEntity, storeDB *store.Stor
 }
 
 // enrichWithTagsSafe adds tags data to affected entities
-func enrichWithTagsSafe(affected map[string]*safeEntity, storeDB *store.Store) {
+func enrichWithTagsSafe(affected map[string]*safeEntity, storeDB *store.SQLStore) {
 	for _, e := range affected {
 		tags, err := storeDB.GetTags(e.entity.ID)
 		if err == nil && len(tags) > 0 {
@@ -1463,7 +1484,7 @@ var warningTagsSafe = map[string]string{
 }
 
 // detectDriftSafe checks for code drift in affected entities
-func detectDriftSafe(affected map[string]*safeEntity, storeDB *store.Store, baseDir string) int {
+func detectDriftSafe(affected map[string]*safeEntity, storeDB *store.SQLStore, baseDir string) int {
 	driftCount := 0
 
 	// Group by file for efficient parsing
@@ -1716,6 +1737,113 @@ func buildSafeOutput(target string, affected map[string]*safeEntity, cfg *config
 	}
 }
 
+// addSafeCoChanges reports the strong co-change counterparts of the target
+// that no dependency path reaches, with a warning and a recommendation
+func addSafeCoChanges(safeOut *SafeOutput, direct []*safeEntity, affected map[string]*safeEntity, storeDB *store.SQLStore) {
+	targets := make([]*store.Entity, 0, len(direct))
+	for _, d := range direct {
+		targets = append(targets, d.entity)
+	}
+	skip := make(map[string]bool, len(affected))
+	for id, e := range affected {
+		skip[id] = true
+		skip[filepath.ToSlash(e.entity.FilePath)] = true
+	}
+	safeOut.CoChanges = strongCoChanges(storeDB, targets, skip)
+	if len(safeOut.CoChanges) == 0 {
+		return
+	}
+
+	names := make([]string, 0, 3)
+	for _, c := range safeOut.CoChanges {
+		if len(names) == 3 {
+			break
+		}
+		names = append(names, c.Name)
+	}
+	more := ""
+	if extra := len(safeOut.CoChanges) - len(names); extra > 0 {
+		more = fmt.Sprintf(" and %d more", extra)
+	}
+	safeOut.Warnings = append(safeOut.Warnings, fmt.Sprintf("%d files/entities usually change together with this target but have no dependency on it: %s%s",
+		len(safeOut.CoChanges), strings.Join(names, ", "), more))
+	safeOut.Recommendations = append(safeOut.Recommendations, "Review the co-changing files listed under co_changes - history says they need matching edits")
+}
+
+// addSafeChokepoints counts the targets that are chokepoints, raises the
+// risk level by one step when there are any and explains why
+func addSafeChokepoints(safeOut *SafeOutput, direct []*safeEntity, g *graph.Graph, storeDB *store.SQLStore, cfg *config.Config) {
+	a, err := analyzeChokepoints(storeDB, g, cfg)
+	if err != nil || len(a.tree.Roots) == 0 {
+		return
+	}
+	var largest *store.Entity
+	for _, d := range direct {
+		if !a.isChokepoint(d.entity.ID) {
+			continue
+		}
+		safeOut.SafetyAssessment.Chokepoints++
+		if largest == nil || a.tree.Dominated(d.entity.ID) > a.tree.Dominated(largest.ID) {
+			largest = d.entity
+		}
+	}
+	if largest == nil {
+		return
+	}
+	safeOut.SafetyAssessment.RiskLevel = escalateRisk(safeOut.SafetyAssessment.RiskLevel)
+	safeOut.Warnings = append(safeOut.Warnings, fmt.Sprintf("'%s' is a chokepoint: every path from the entry points to %d entities (%.1f%% of the reachable code) goes through it",
+		largest.Name, a.tree.Dominated(largest.ID), a.share(largest.ID)*100))
+	safeOut.Recommendations = append(safeOut.Recommendations, fmt.Sprintf("Keep '%s' backward compatible - run 'cx trace %s --dominators' to see what it guards", largest.Name, largest.Name))
+}
+
+// escalateRisk returns the next higher risk level
+func escalateRisk(level string) string {
+	switch level {
+	case "low":
+		return "medium"
+	case "medium":
+		return "high"
+	case "high":
+		return "critical"
+	}
+	return level
+}
+
+// addSafeOwners lists the top authors of the target from git blame, asks
+// for their review when keystones are affected, and warns when the
+// target's CODEOWNERS owners have not written any of it
+func addSafeOwners(safeOut *SafeOutput, direct []*safeEntity, projectRoot string) {
+	entities := make([]*store.Entity, 0, len(direct))
+	paths := make(map[string]bool)
+	var pathList []string
+	for _, d := range direct {
+		entities = append(entities, d.entity)
+		if !paths[d.entity.FilePath] {
+			paths[d.entity.FilePath] = true
+			pathList = append(pathList, d.entity.FilePath)
+		}
+	}
+	o := newOwnershipIndex(projectRoot).ownership(ownedEntities(entities))
+	if o.Lines == 0 {
+		return
+	}
+	safeOut.Owners = ownerEntries(o, maxSafeOwners)
+	top := safeOut.Owners[0]
+
+	if safeOut.SafetyAssessment.KeystoneCount > 0 {
+		safeOut.Recommendations = append(safeOut.Recommendations,
+			fmt.Sprintf("Ask %s (%.0f%% of the current lines) to review - they know this code best", top.Name, top.Share*100))
+	}
+	file, err := codeowners.Load(projectRoot)
+	if err != nil || file == nil {
+		return
+	}
+	if check := checkCodeowners(file, pathList, o); check.Touched != nil && !*check.Touched {
+		safeOut.Warnings = append(safeOut.Warnings, fmt.Sprintf("CODEOWNERS owners %s have not written any of the target's current lines; %s wrote %.0f%%",
+			strings.Join(check.Owners, " "), top.Name, top.Share*100))
+	}
+}
+
 // computeDynamicKeystoneThresholdSafe calculates a threshold based on the actual PageRank distribution
 // Uses top 5% of entities or minimum of top 10, whichever identifies more keystones
 func computeDynamicKeystoneThresholdSafe(affected map[string]*safeEntity) float64 {
@@ -2074,7 +2202,7 @@ func countKeystonesSafe(affected map[string]*safeEntity, cfg *config.Config) int
 
 // estimateHistoricalImpact estimates the impact radius at a historical commit
 // This is an approximation based on entity count changes
-func estimateHistoricalImpact(storeDB *store.Store, target string, commitHash string, currentSize int) int {
+func estimateHistoricalImpact(storeDB *store.SQLStore, target string, commitHash string, currentSize int) int {
 	// Get diff between this commit and HEAD to estimate changes
 	diffResult, err := storeDB.DoltDiff(store.DiffOptions{
 		FromRef: commitHash,
@@ -11,13 +11,13 @@ import (
 	"time"
 
 	"github.com/anthropics/cx/internal/config"
+	cxcontext "github.com/anthropics/cx/internal/context"
 	"github.com/anthropics/cx/internal/embeddings"
 	"github.com/anthropics/cx/internal/exclude"
 	"github.com/anthropics/cx/internal/extract"
-	"github.com/anthropics/cx/internal/graph"
-	"github.com/anthropics/cx/internal/metrics"
 	"github.com/anthropics/cx/internal/output"
 	"github.com/anthropics/cx/internal/parser"
+	"github.com/anthropics/cx/internal/scip"
 	"github.com/anthropics/cx/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -38,6 +38,14 @@ The scan process:
   4. Compares with existing entities (create/update/archive)
   5. Updates the .cx/cortex.db file index
 
+Tree-sitter resolves calls and type uses by name, which can pick the wrong
+target when names collide. If your CI produces a SCIP index (scip-go,
+scip-typescript, scip-java, ...), pass it with --scip: its symbols are mapped
+onto the scanned entities and every reference becomes a precise calls or
+uses_type edge recorded with source=scip. Tree-sitter edges keep
+source=treesitter; with --scip-replace, those SCIP does not confirm are
+removed for the files the index covers.
+
 Supported languages: Go, TypeScript, JavaScript, Java, Rust, Python, C, C++, C#, PHP, Kotlin, Ruby
 
 Auto-excludes dependency directories (disable with --no-auto-exclude):
@@ -58,6 +66,7 @@ Examples:
   cx scan --no-auto-exclude  # Don't auto-exclude dependency directories
   cx scan --tag v1.0         # Create tag usable as: cx show Entity --at v1.0
   cx scan --embed            # Generate embeddings for semantic search
+  cx scan --scip index.scip  # Add compiler-precise references from a SCIP index
   cx scan -v                 # Verbose: shows auto-excluded directories`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runScan,
@@ -75,6 +84,8 @@ var (
 	scanDiff          bool
 	scanTag           string
 	scanEmbed         bool
+	scanSCIP          string
+	scanSCIPReplace   bool
 )
 
 func init() {
@@ -91,6 +102,8 @@ func init() {
 	scanCmd.Flags().BoolVar(&scanDiff, "diff", false, "Show what changed since previous scan")
 	scanCmd.Flags().StringVar(&scanTag, "tag", "", "Create a Dolt tag after scan (usable as ref for --at, --since)")
 	scanCmd.Flags().BoolVar(&scanEmbed, "embed", false, "Generate embeddings for semantic search after scan")
+	scanCmd.Flags().StringVar(&scanSCIP, "scip", "", "Import precise references from a SCIP index file")
+	scanCmd.Flags().BoolVar(&scanSCIPReplace, "scip-replace", false, "With --scip, drop tree-sitter calls/uses_type edges the index does not confirm")
 }
 
 // scanStats tracks scan statistics for summary output
@@ -106,6 +119,7 @@ type scanStats struct {
 	depsExtracted int
 	depsResolved  int
 	depsPersisted int
+	scip          *scip.ImportStats // nil unless --scip was given
 }
 
 // fileScanResult holds the results from scanning a single file.
@@ -354,6 +368,8 @@ func runScan(cmd *cobra.Command, args []string) error {
 	// Collect entities for bulk insert
 	var entitiesToCreate []*store.Entity
 	var entitiesToUpdate []*store.Entity
+	var fingerprintFiles []string
+	var fingerprints []store.EntityFingerprint
 
 	for _, fr := range fileResults {
 		// Handle unchanged files: preserve their existing entities
@@ -384,6 +400,15 @@ func runScan(cmd *cobra.Command, args []string) error {
 			status, storeEntity := processEntityWithStore(entity, entityID, storeDB, stats, existingEntityIDs)
 			writeEntityWithStatus(w, entity, status)
 
+			if len(entity.Fingerprint) > 0 {
+				fingerprints = append(fingerprints, store.EntityFingerprint{
+					EntityID: entityID,
+					FilePath: fr.relPath,
+					Tokens:   entity.FingerprintTokens,
+					Hashes:   entity.Fingerprint,
+				})
+			}
+
 			if storeEntity != nil {
 				if status == "new" {
 					entitiesToCreate = append(entitiesToCreate, storeEntity)
@@ -393,6 +418,8 @@ func runScan(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		fingerprintFiles = append(fingerprintFiles, fr.relPath)
+
 		// Update file index
 		if !scanDryRun {
 			if err := storeDB.SetFileScanned(fr.relPath, fr.fileHash); err != nil && verbose {
@@ -417,6 +444,13 @@ func runScan(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Replace the clone fingerprints of the rescanned files
+	if len(fingerprintFiles) > 0 && !scanDryRun {
+		if err := storeDB.SaveFingerprints(fingerprintFiles, fingerprints); err != nil {
+			w.WriteComment(fmt.Sprintf("Error: fingerprint update failed: %v", err))
+		}
+	}
+
 	// ============================================================
 	// PASS 2: Extract dependencies using global entity map
 	// ============================================================
@@ -466,51 +500,8 @@ func runScan(cmd *cobra.Command, args []string) error {
 			fileEntities = append(fileEntities, cge)
 		}
 
-		// Create call graph extractor with shared lookup maps
-		// Dispatch to language-specific extractor
-		var deps []extract.Dependency
-		var extractErr error
-
-		switch fr.parseResult.Language {
-		case parser.Go:
-			extractor := extract.NewCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.TypeScript, parser.JavaScript:
-			extractor := extract.NewTypeScriptCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.Python:
-			extractor := extract.NewPythonCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.Java:
-			extractor := extract.NewJavaCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.Rust:
-			extractor := extract.NewRustCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.C:
-			extractor := extract.NewCCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.Cpp:
-			extractor := extract.NewCppCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.CSharp:
-			extractor := extract.NewCSharpCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.PHP:
-			extractor := extract.NewPHPCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.Ruby:
-			extractor := extract.NewRubyCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		case parser.Kotlin:
-			extractor := extract.NewKotlinCallGraphExtractorWithMaps(fr.parseResult, fileEntities, entityByName, entityByID)
-			deps, extractErr = extractor.ExtractDependencies()
-		default:
-			// Unsupported language for call graph extraction
-			continue
-		}
-
-		err := extractErr
+		// Extract with the shared lookup maps, by language
+		deps, err := extractFileDependencies(fr.parseResult, fileEntities, entityByName, entityByID)
 		if err != nil {
 			if verbose {
 				w.WriteComment(fmt.Sprintf("Warning: call graph extraction failed for %s: %v", fr.relPath, err))
@@ -526,9 +517,10 @@ func runScan(cmd *cobra.Command, args []string) error {
 			if dep.ToID != "" {
 				stats.depsResolved++
 				depsToCreate = append(depsToCreate, &store.Dependency{
-					FromID:  dep.FromID,
-					ToID:    dep.ToID,
-					DepType: string(dep.DepType),
+					FromID:   dep.FromID,
+					ToID:     dep.ToID,
+					DepType:  string(dep.DepType),
+					Optional: dep.Optional,
 				})
 			}
 		}
@@ -567,6 +559,39 @@ func runScan(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Apply the SCIP index once all entities and heuristic edges are in place
+	if scanSCIP != "" && !scanDryRun {
+		scipStats, err := importSCIP(storeDB, scanSCIP, projectRoot)
+		if err != nil {
+			return err
+		}
+		stats.scip = scipStats
+	}
+
+	// Bring graph metrics up to date around the entities this scan touched
+	var metricsRun *store.MetricsRun
+	if !scanDryRun {
+		changed := make([]string, 0, len(entitiesToCreate)+len(entitiesToUpdate))
+		for _, e := range entitiesToCreate {
+			changed = append(changed, e.ID)
+		}
+		for _, e := range entitiesToUpdate {
+			changed = append(changed, e.ID)
+		}
+		run, err := refreshMetrics(storeDB, cfg, changed, false)
+		if err != nil {
+			if verbose {
+				w.WriteComment(fmt.Sprintf("Warning: failed to update metrics: %v", err))
+			}
+		} else {
+			metricsRun = run
+			if verbose {
+				w.WriteComment(fmt.Sprintf("Metrics: %s PageRank (%d updated, %d iterations), %s betweenness in %dms",
+					run.Mode, run.Updated, run.Iterations, run.Betweenness, run.DurationMs))
+			}
+		}
+	}
+
 	// Print summary (unless quiet mode)
 	if !quiet {
 		w.WriteBlankLine()
@@ -577,6 +602,10 @@ func runScan(cmd *cobra.Command, args []string) error {
 			w.WriteComment(fmt.Sprintf("Dependencies: %d extracted, %d resolved, %d persisted",
 				stats.depsExtracted, stats.depsResolved, stats.depsPersisted))
 		}
+		if s := stats.scip; s != nil {
+			w.WriteComment(fmt.Sprintf("SCIP: %d documents, %d symbols mapped (%d unmapped), %d precise edges (%d confirmed tree-sitter), %d heuristic edges removed",
+				s.Documents, s.Symbols, s.Unmapped, s.Edges, s.Confirmed, s.Removed))
+		}
 		if stats.skipped > 0 || stats.errors > 0 {
 			w.WriteComment(fmt.Sprintf("Skipped: %d, Errors: %d", stats.skipped, stats.errors))
 		}
@@ -614,6 +643,42 @@ func runScan(cmd *cobra.Command, args []string) error {
 			if verbose {
 				w.WriteComment(fmt.Sprintf("Warning: failed to save scan metadata: %v", err))
 			}
+		} else if meta.ID > 0 {
+			// Record dependency cycles so cx find --cycles can report growth, and
+			// package coupling and entity metrics for trends
+			if err := recordScanCycles(storeDB, meta.ID); err != nil && verbose {
+				w.WriteComment(fmt.Sprintf("Warning: failed to record cycles: %v", err))
+			}
+			if err := recordScanCoupling(storeDB, meta.ID); err != nil && verbose {
+				w.WriteComment(fmt.Sprintf("Warning: failed to record package metrics: %v", err))
+			}
+			if err := recordMetricHistory(storeDB, meta.ID); err != nil && verbose {
+				w.WriteComment(fmt.Sprintf("Warning: failed to record metric history: %v", err))
+			}
+			// Mine co-changes from git history and scan history, including
+			// this scan's uncommitted changes
+			if files, entities, err := recordCoChanges(storeDB, projectRoot, meta.ID); err != nil {
+				if verbose {
+					w.WriteComment(fmt.Sprintf("Warning: failed to record co-changes: %v", err))
+				}
+			} else if verbose {
+				w.WriteComment(fmt.Sprintf("Co-changes: %d file edges, %d entity edges", files, entities))
+			}
+			if metricsRun != nil {
+				metricsRun.ScanID = meta.ID
+				if err := saveMetricsRun(storeDB, metricsRun); err != nil && verbose {
+					w.WriteComment(fmt.Sprintf("Warning: failed to record metrics run: %v", err))
+				}
+			}
+		}
+
+		// Context sessions resend entities whose bodies this scan changed
+		if n, err := cxcontext.InvalidateSessions(cxDir, storeDB); err != nil {
+			if verbose {
+				w.WriteComment(fmt.Sprintf("Warning: failed to update context sessions: %v", err))
+			}
+		} else if n > 0 && verbose {
+			w.WriteComment(fmt.Sprintf("Context sessions: %d deliveries invalidated", n))
 		}
 
 		// Format commit message: cx scan: {entities} entities, {deps} deps [{branch}@{commit}]
@@ -660,7 +725,12 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 	// Generate embeddings if --embed flag is set
 	if scanEmbed && !scanDryRun && stats.errors == 0 {
-		generateEmbeddings(storeDB, w)
+		generateEmbeddings(storeDB, cfg.Embeddings, w)
+
+		// Bring the semantic search index up to date so queries don't pay for it
+		if err := storeDB.SyncEmbeddingIndex(); err != nil && verbose {
+			w.WriteComment(fmt.Sprintf("Warning: failed to update embedding index: %v", err))
+		}
 	}
 
 	return nil
@@ -668,7 +738,7 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 // scanFilePass1 handles the first pass of scanning: parse file and extract entities with AST nodes.
 // Returns nil if file should be skipped (unchanged or error).
-func scanFilePass1(path, basePath string, p *parser.Parser, storeDB *store.Store, stats *scanStats) *fileScanResult {
+func scanFilePass1(path, basePath string, p *parser.Parser, storeDB *store.SQLStore, stats *scanStats) *fileScanResult {
 	stats.filesScanned++
 
 	// Read file
@@ -707,51 +777,13 @@ func scanFilePass1(path, basePath string, p *parser.Parser, storeDB *store.Store
 	result.FilePath = relPath
 
 	// Extract entities with AST nodes based on language
-	var entitiesWithNodes []extract.EntityWithNode
-	switch p.Language() {
-	case parser.Go:
-		extractor := extract.NewExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.Python:
-		extractor := extract.NewPythonExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.TypeScript, parser.JavaScript:
-		extractor := extract.NewTypeScriptExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.Rust:
-		extractor := extract.NewRustExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.Java:
-		extractor := extract.NewJavaExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.C:
-		extractor := extract.NewCExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.CSharp:
-		extractor := extract.NewCSharpExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.PHP:
-		extractor := extract.NewPHPExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.Cpp:
-		extractor := extract.NewCppExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.Kotlin:
-		extractor := extract.NewKotlinExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	case parser.Ruby:
-		extractor := extract.NewRubyExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	default:
-		// Fall back to Go extractor for unsupported languages
-		extractor := extract.NewExtractorWithBase(result, basePath)
-		entitiesWithNodes, err = extractor.ExtractAllWithNodes()
-	}
+	entitiesWithNodes, err := extractEntitiesWithNodes(result, p.Language(), basePath)
 	if err != nil {
 		stats.errors++
 		result.Close()
 		return nil
 	}
+	extract.FingerprintEntities(entitiesWithNodes)
 
 	return &fileScanResult{
 		path:        path,
@@ -765,7 +797,7 @@ func scanFilePass1(path, basePath string, p *parser.Parser, storeDB *store.Store
 
 // processEntityWithStore compares entity with existing and prepares create/update operations.
 // Returns the status string and a store.Entity object if an operation is needed.
-func processEntityWithStore(entity *extract.Entity, entityID string, storeDB *store.Store,
+func processEntityWithStore(entity *extract.Entity, entityID string, storeDB *store.SQLStore,
 	stats *scanStats, existingEntityIDs map[string]bool) (string, *store.Entity) {
 
 	// Detect language from file extension
@@ -783,6 +815,7 @@ func processEntityWithStore(entity *extract.Entity, entityID string, storeDB *st
 				ID:         entityID,
 				Name:       entity.Name,
 				EntityType: string(entity.Kind),
+				Kind:       storeEntityKind(entity),
 				FilePath:   entity.File,
 				LineStart:  int(entity.StartLine),
 				LineEnd:    &endLine,
@@ -802,8 +835,9 @@ func processEntityWithStore(entity *extract.Entity, entityID string, storeDB *st
 		return "unchanged", nil
 	}
 
-	// Entity exists - check for changes
-	if existing.SigHash != entity.SigHash || existing.BodyHash != entity.BodyHash {
+	// Entity exists - check for changes. A kind mismatch means the entity was
+	// stored before kinds were recorded.
+	if existing.SigHash != entity.SigHash || existing.BodyHash != entity.BodyHash || existing.Kind != storeEntityKind(entity) {
 		// Changed - prepare for update
 		stats.updated++
 		endLine := int(entity.EndLine)
@@ -811,6 +845,7 @@ func processEntityWithStore(entity *extract.Entity, entityID string, storeDB *st
 			ID:         entityID,
 			Name:       entity.Name,
 			EntityType: string(entity.Kind),
+			Kind:       storeEntityKind(entity),
 			FilePath:   entity.File,
 			LineStart:  int(entity.StartLine),
 			LineEnd:    &endLine,
@@ -836,6 +871,18 @@ func processEntityWithStore(entity *extract.Entity, entityID string, storeDB *st
 	return "unchanged", nil
 }
 
+// storeEntityKind returns the kind stored for an entity: the type kind
+// (struct, interface, alias, union), or "abstract" for abstract classes.
+func storeEntityKind(entity *extract.Entity) string {
+	if entity.Kind != extract.TypeEntity {
+		return ""
+	}
+	if strings.HasSuffix(entity.ValueType, "(abstract)") {
+		return "abstract"
+	}
+	return string(entity.TypeKind)
+}
+
 // detectLanguageFromPath detects the programming language from a file path.
 func detectLanguageFromPath(path string) string {
 	ext := filepath.Ext(path)
@@ -1045,7 +1092,7 @@ func getRelativePath(path, basePath string) string {
 }
 
 // isFileChanged checks if a file has changed since last scan
-func isFileChanged(storeDB *store.Store, path, hash string) bool {
+func isFileChanged(storeDB *store.SQLStore, path, hash string) bool {
 	if storeDB == nil {
 		return true // No store, assume changed
 	}
@@ -1060,69 +1107,23 @@ func isFileChanged(storeDB *store.Store, path, hash string) bool {
 
 // showScanOverview displays a project overview after scanning (--overview flag).
 // This consolidates the quickstart functionality into scan.
-func showScanOverview(s *store.Store, projectRoot string, cfg *config.Config) error {
-	fmt.Println()
-	fmt.Println("Computing importance metrics...")
-
-	// Get all active entities for metrics computation
-	entities, err := s.QueryEntities(store.EntityFilter{Status: "active"})
-	if err != nil {
-		return fmt.Errorf("query entities: %w", err)
-	}
-
-	if len(entities) == 0 {
+func showScanOverview(s *store.SQLStore, projectRoot string, cfg *config.Config) error {
+	if n, _ := s.CountEntities(store.EntityFilter{Status: "active"}); n == 0 {
+		fmt.Println()
 		fmt.Println("No entities found.")
 		return nil
 	}
 
-	// Check if metrics need computing
-	needRecompute := false
-	for _, e := range entities {
-		if m, _ := s.GetMetrics(e.ID); m == nil {
-			needRecompute = true
-			break
-		}
-	}
-
-	if needRecompute {
-		// Build graph from store
-		g, err := graph.BuildFromStore(s)
+	// Metrics were refreshed by the scan; compute them only if that failed
+	if n, _ := s.CountMetrics(); n == 0 {
+		fmt.Println()
+		fmt.Println("Computing importance metrics...")
+		run, err := refreshMetrics(s, cfg, nil, true)
 		if err != nil {
-			return fmt.Errorf("build graph: %w", err)
-		}
-
-		adjacency := g.Edges
-
-		// Compute PageRank
-		prConfig := metrics.PageRankConfig{
-			Damping:       cfg.Metrics.PageRankDamping,
-			MaxIterations: cfg.Metrics.PageRankIterations,
-			Tolerance:     0.0001,
-		}
-		pagerank := metrics.ComputePageRank(adjacency, prConfig)
-
-		// Compute betweenness
-		betweenness := metrics.ComputeBetweenness(adjacency)
-
-		// Compute degrees
-		inDegree, outDegree := metrics.ComputeInOutDegree(adjacency)
-
-		// Save to store
-		bulkMetrics := make([]*store.Metrics, 0, len(entities))
-		for _, e := range entities {
-			m := &store.Metrics{
-				EntityID:    e.ID,
-				PageRank:    pagerank[e.ID],
-				Betweenness: betweenness[e.ID],
-				InDegree:    inDegree[e.ID],
-				OutDegree:   outDegree[e.ID],
-				ComputedAt:  time.Now(),
-			}
-			bulkMetrics = append(bulkMetrics, m)
+			return err
 		}
-
-		if err := s.SaveBulkMetrics(bulkMetrics); err != nil {
-			return fmt.Errorf("save metrics: %w", err)
+		if err := saveMetricsRun(s, run); err != nil {
+			return err
 		}
 	}
 
@@ -1135,7 +1136,7 @@ func showScanOverview(s *store.Store, projectRoot string, cfg *config.Config) er
 }
 
 // printScanOverviewSummary prints the project summary for --overview.
-func printScanOverviewSummary(s *store.Store, projectRoot string) {
+func printScanOverviewSummary(s *store.SQLStore, projectRoot string) {
 	// Get stats
 	activeCount, _ := s.CountEntities(store.EntityFilter{Status: "active"})
 	depCount, _ := s.CountDependencies()
@@ -1242,9 +1243,9 @@ func getGitBranch(projectRoot string) string {
 // generateEmbeddings generates vector embeddings for entities that need them.
 // Runs after scan completion to enable semantic search.
 // Processes entities in batches for efficiency and logs progress.
-func generateEmbeddings(storeDB *store.Store, w *output.CGFWriter) {
-	// Get entities needing embedding
-	ids, err := storeDB.NeedsEmbedding(embeddings.ModelVersion)
+func generateEmbeddings(storeDB *store.SQLStore, embedCfg config.EmbeddingsConfig, w *output.CGFWriter) {
+	// Get entities needing embedding with the configured provider and model
+	ids, err := storeDB.NeedsEmbedding(embeddings.ModelVersionFor(embedCfg))
 	if err != nil {
 		if verbose {
 			w.WriteComment(fmt.Sprintf("Warning: failed to check embedding needs: %v", err))
@@ -1259,13 +1260,13 @@ func generateEmbeddings(storeDB *store.Store, w *output.CGFWriter) {
 		return
 	}
 
-	// Create embedder (downloads model on first use)
+	// Create embedder (the local provider downloads its model on first use)
 	if !quiet {
 		w.WriteBlankLine()
-		w.WriteComment(fmt.Sprintf("Generating embeddings for %d entities...", len(ids)))
+		w.WriteComment(fmt.Sprintf("Generating embeddings for %d entities (%s)...", len(ids), embeddings.ModelVersionFor(embedCfg)))
 	}
 
-	embedder, err := embeddings.NewLocalEmbedder()
+	embedder, err := embeddings.New(embedCfg)
 	if err != nil {
 		w.WriteComment(fmt.Sprintf("Warning: failed to create embedder: %v", err))
 		return
@@ -1319,7 +1320,7 @@ func generateEmbeddings(storeDB *store.Store, w *output.CGFWriter) {
 				break
 			}
 			contentHash := embeddings.ContentHash(e)
-			if err := storeDB.SaveEmbedding(e.ID, vecs[j], embeddings.ModelVersion, contentHash); err != nil {
+			if err := storeDB.SaveEmbedding(e.ID, vecs[j], embedder.ModelVersion(), contentHash); err != nil {
 				errors++
 				if verbose {
 					w.WriteComment(fmt.Sprintf("Warning: failed to save embedding for %s: %v", e.ID, err))
@@ -1429,3 +1430,83 @@ func detectLanguages(scanPath string, excludes []string) []parser.Language {
 
 	return languages
 }
+
+// importSCIP reads the SCIP index at path and adds its references to the
+// graph as source=scip dependencies.
+func importSCIP(storeDB *store.SQLStore, path, projectRoot string) (*scip.ImportStats, error) {
+	idx, err := scip.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read SCIP index: %w", err)
+	}
+	stats, err := scip.Import(storeDB, idx, scip.ImportOptions{
+		ProjectRoot: projectRoot,
+		Replace:     scanSCIPReplace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import SCIP index: %w", err)
+	}
+	return stats, nil
+}
+
+// extractFileDependencies extracts the dependencies of one parsed file with
+// its language's call graph extractor, resolving targets through the
+// shared lookup maps. Languages without an extractor have none.
+func extractFileDependencies(result *parser.ParseResult, fileEntities []extract.CallGraphEntity,
+	entityByName, entityByID map[string]*extract.CallGraphEntity) ([]extract.Dependency, error) {
+	switch result.Language {
+	case parser.Go:
+		return extract.NewCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.TypeScript, parser.JavaScript:
+		return extract.NewTypeScriptCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.Python:
+		return extract.NewPythonCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.Java:
+		return extract.NewJavaCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.Rust:
+		return extract.NewRustCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.C:
+		return extract.NewCCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.Cpp:
+		return extract.NewCppCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.CSharp:
+		return extract.NewCSharpCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.PHP:
+		return extract.NewPHPCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.Ruby:
+		return extract.NewRubyCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	case parser.Kotlin:
+		return extract.NewKotlinCallGraphExtractorWithMaps(result, fileEntities, entityByName, entityByID).ExtractDependencies()
+	}
+	return nil, nil
+}
+
+// extractEntitiesWithNodes extracts the entities of a parsed file, with
+// their AST nodes, by language. Entity paths are made relative to basePath.
+func extractEntitiesWithNodes(result *parser.ParseResult, lang parser.Language, basePath string) ([]extract.EntityWithNode, error) {
+	switch lang {
+	case parser.Go:
+		return extract.NewExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.Python:
+		return extract.NewPythonExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.TypeScript, parser.JavaScript:
+		return extract.NewTypeScriptExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.Rust:
+		return extract.NewRustExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.Java:
+		return extract.NewJavaExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.C:
+		return extract.NewCExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.CSharp:
+		return extract.NewCSharpExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.PHP:
+		return extract.NewPHPExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.Cpp:
+		return extract.NewCppExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.Kotlin:
+		return extract.NewKotlinExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	case parser.Ruby:
+		return extract.NewRubyExtractorWithBase(result, basePath).ExtractAllWithNodes()
+	}
+	// Fall back to Go extractor for unsupported languages
+	return extract.NewExtractorWithBase(result, basePath).ExtractAllWithNodes()
+}
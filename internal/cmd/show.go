@@ -256,7 +256,7 @@ func runShow(cmd *cobra.Command, args []string) error {
 // resolveShowQuery resolves the query to an entity.
 // Supports: name, ID, or file:line.
 // If ref is non-empty, queries at that historical point using AS OF.
-func resolveShowQuery(query string, storeDB *store.Store, ref string) (*store.Entity, error) {
+func resolveShowQuery(query string, storeDB *store.SQLStore, ref string) (*store.Entity, error) {
 	// Check if it's a file:line pattern (e.g., internal/auth/login.go:45)
 	if strings.Contains(query, ":") && !isDirectIDQuery(query) {
 		parts := strings.Split(query, ":")
@@ -277,7 +277,7 @@ func resolveShowQuery(query string, storeDB *store.Store, ref string) (*store.En
 
 // resolveShowEntityAtLine finds the entity at a specific line in a file
 // If ref is non-empty, queries at that historical point using AS OF.
-func resolveShowEntityAtLine(filePath string, lineNum int, storeDB *store.Store, ref string) (*store.Entity, error) {
+func resolveShowEntityAtLine(filePath string, lineNum int, storeDB *store.SQLStore, ref string) (*store.Entity, error) {
 	// Query entities in the file
 	filter := store.EntityFilter{
 		FilePath: filePath,
@@ -339,7 +339,7 @@ func resolveShowEntityAtLine(filePath string, lineNum int, storeDB *store.Store,
 }
 
 // runShowDefault handles the standard show command behavior
-func runShowDefault(cmd *cobra.Command, entity *store.Entity, storeDB *store.Store, format output.Format, density output.Density) error {
+func runShowDefault(cmd *cobra.Command, entity *store.Entity, storeDB *store.SQLStore, format output.Format, density output.Density) error {
 	entityID := entity.ID
 
 	// Build EntityOutput with name as YAML key
@@ -510,7 +510,7 @@ func runShowDefault(cmd *cobra.Command, entity *store.Entity, storeDB *store.Sto
 }
 
 // runShowRelated handles the --related flag (neighborhood mode, replaces cx near)
-func runShowRelated(cmd *cobra.Command, entity *store.Entity, storeDB *store.Store, format output.Format, density output.Density) error {
+func runShowRelated(cmd *cobra.Command, entity *store.Entity, storeDB *store.SQLStore, format output.Format, density output.Density) error {
 	// Build neighborhood output (logic from near.go)
 	nearOutput, err := buildShowNeighborhood(entity, storeDB, showDepth, showDirection, density)
 	if err != nil {
@@ -527,7 +527,7 @@ func runShowRelated(cmd *cobra.Command, entity *store.Entity, storeDB *store.Sto
 }
 
 // buildShowNeighborhood constructs the neighborhood output for an entity
-func buildShowNeighborhood(entity *store.Entity, storeDB *store.Store, depth int, direction string, density output.Density) (*output.NearOutput, error) {
+func buildShowNeighborhood(entity *store.Entity, storeDB *store.SQLStore, depth int, direction string, density output.Density) (*output.NearOutput, error) {
 	// Build center entity
 	center := &output.NearCenterEntity{
 		Name:     entity.Name,
@@ -670,7 +670,7 @@ type showGraphNode struct {
 }
 
 // runShowGraph handles the --graph flag (graph visualization mode, replaces cx graph)
-func runShowGraph(cmd *cobra.Command, entity *store.Entity, storeDB *store.Store, format output.Format, density output.Density) error {
+func runShowGraph(cmd *cobra.Command, entity *store.Entity, storeDB *store.SQLStore, format output.Format, density output.Density) error {
 	entityID := entity.ID
 
 	// Build graph from store
@@ -955,7 +955,7 @@ func computeImportanceFromMetrics(inDegree int, pageRank float64) string {
 }
 
 // buildCoverageOutput creates coverage information for display
-func buildCoverageOutput(coverageData *coverage.EntityCoverage, tests []coverage.TestInfo, entity *store.Entity, storeDB *store.Store) *output.Coverage {
+func buildCoverageOutput(coverageData *coverage.EntityCoverage, tests []coverage.TestInfo, entity *store.Entity, storeDB *store.SQLStore) *output.Coverage {
 	cov := &output.Coverage{
 		Tested:         len(tests) > 0,
 		Percent:        coverageData.CoveragePercent,
@@ -995,7 +995,7 @@ func buildCoverageOutput(coverageData *coverage.EntityCoverage, tests []coverage
 }
 
 // findTestEntity tries to find a test function entity in the store
-func findTestEntity(storeDB *store.Store, testFile string, testName string) (*store.Entity, error) {
+func findTestEntity(storeDB *store.SQLStore, testFile string, testName string) (*store.Entity, error) {
 	if storeDB == nil {
 		return nil, fmt.Errorf("store is nil")
 	}
@@ -1057,7 +1057,7 @@ func groupLinesIntoRanges(lines []int) [][]int {
 
 // getEntityChangeStatus returns the change status of an entity since a ref.
 // Returns: "added", "modified", "unchanged", or "" on error.
-func getEntityChangeStatus(storeDB *store.Store, entity *store.Entity, sinceRef string) (string, error) {
+func getEntityChangeStatus(storeDB *store.SQLStore, entity *store.Entity, sinceRef string) (string, error) {
 	// Query dolt_diff to find if this entity was changed since the ref
 	diffOpts := store.DiffOptions{
 		FromRef:  sinceRef,
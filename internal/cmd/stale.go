@@ -23,12 +23,14 @@ Compares the last scan time against:
   1. Current time (with configurable threshold)
   2. Git HEAD commit (has HEAD changed since scan?)
   3. File modifications (how many files changed since scan?)
+  4. Graph metrics (were PageRank and betweenness computed after the scan?)
 
 Output:
   status: stale|fresh
   last_scan: timestamp of last scan
   git_status: whether HEAD matches scan commit
   files_changed: number of files modified since scan
+  metrics: when and how PageRank and betweenness were computed
 
 Examples:
   cx stale                    # Check staleness with default 1h threshold
@@ -52,11 +54,12 @@ func init() {
 
 // StaleOutput represents the staleness check results
 type StaleOutput struct {
-	Status       string        `yaml:"status" json:"status"`               // "stale" or "fresh"
-	LastScan     *ScanInfo     `yaml:"last_scan" json:"last_scan"`         // Info about last scan
-	GitStatus    *GitStatus    `yaml:"git_status" json:"git_status"`       // Git HEAD comparison
-	FilesChanged *FilesChanged `yaml:"files_changed" json:"files_changed"` // Files modified since scan
-	Reasons      []string      `yaml:"reasons,omitempty" json:"reasons,omitempty"`
+	Status       string                   `yaml:"status" json:"status"`                       // "stale" or "fresh"
+	LastScan     *ScanInfo                `yaml:"last_scan" json:"last_scan"`                 // Info about last scan
+	GitStatus    *GitStatus               `yaml:"git_status" json:"git_status"`               // Git HEAD comparison
+	FilesChanged *FilesChanged            `yaml:"files_changed" json:"files_changed"`         // Files modified since scan
+	Metrics      *output.MetricsFreshness `yaml:"metrics,omitempty" json:"metrics,omitempty"` // Last PageRank/betweenness computation
+	Reasons      []string                 `yaml:"reasons,omitempty" json:"reasons,omitempty"`
 }
 
 // ScanInfo contains information about the last scan
@@ -181,6 +184,14 @@ func runStale(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Check 4: Metrics computed before the last scan
+	staleOut.Metrics = metricsFreshness(storeDB)
+	if staleOut.Metrics != nil && staleOut.Metrics.Stale {
+		staleOut.Status = "stale"
+		staleOut.Reasons = append(staleOut.Reasons,
+			"Graph metrics predate the last scan (run 'cx find --important --recompute')")
+	}
+
 	return outputStale(cmd, staleOut)
 }
 
@@ -223,7 +234,7 @@ func countCommitsBetween(from, to string) int {
 }
 
 // getFilesModifiedSince returns files that have been modified since the given time
-func getFilesModifiedSince(storeDB *store.Store, since time.Time) []string {
+func getFilesModifiedSince(storeDB *store.SQLStore, since time.Time) []string {
 	files, err := storeDB.GetAllFileEntries()
 	if err != nil {
 		return nil
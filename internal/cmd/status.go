@@ -277,7 +277,7 @@ func formatStatusDuration(d time.Duration) string {
 	return fmt.Sprintf("%dh", hours)
 }
 
-func getStatusLastScanTime(storeDB *store.Store) (time.Time, error) {
+func getStatusLastScanTime(storeDB *store.SQLStore) (time.Time, error) {
 	// Query for the most recent file scan time
 	files, err := storeDB.GetAllFileEntries()
 	if err != nil {
@@ -294,7 +294,7 @@ func getStatusLastScanTime(storeDB *store.Store) (time.Time, error) {
 	return lastScan, nil
 }
 
-func countStatusStaleFiles(storeDB *store.Store, lastScan time.Time) int {
+func countStatusStaleFiles(storeDB *store.SQLStore, lastScan time.Time) int {
 	// Get indexed files and check if any have been modified since last scan
 	files, err := storeDB.GetAllFileEntries()
 	if err != nil {
@@ -303,7 +303,7 @@ func runTagFind(cmd *cobra.Command, args []string) error {
 	return runTagsFindInternal(cmd, storeDB, args)
 }
 
-func runTagsForEntity(cmd *cobra.Command, storeDB *store.Store, entityQuery string) error {
+func runTagsForEntity(cmd *cobra.Command, storeDB *store.SQLStore, entityQuery string) error {
 	// Resolve the entity
 	entity, err := resolveEntityByName(entityQuery, storeDB, "")
 	if err != nil {
@@ -366,7 +366,7 @@ func runTagsForEntity(cmd *cobra.Command, storeDB *store.Store, entityQuery stri
 	return formatter.FormatToWriter(cmd.OutOrStdout(), out, output.DensityMedium)
 }
 
-func runTagsListAll(cmd *cobra.Command, storeDB *store.Store) error {
+func runTagsListAll(cmd *cobra.Command, storeDB *store.SQLStore) error {
 	tagCounts, err := storeDB.ListAllTags()
 	if err != nil {
 		return fmt.Errorf("failed to list tags: %w", err)
@@ -417,7 +417,7 @@ func runTagsListAll(cmd *cobra.Command, storeDB *store.Store) error {
 	return formatter.FormatToWriter(cmd.OutOrStdout(), out, output.DensityMedium)
 }
 
-func runTagsFindInternal(cmd *cobra.Command, storeDB *store.Store, tags []string) error {
+func runTagsFindInternal(cmd *cobra.Command, storeDB *store.SQLStore, tags []string) error {
 	// Determine match mode (default is ANY)
 	matchAll := tagMatchAll
 
@@ -763,7 +763,7 @@ func runTestCoverageImport(cmd *cobra.Command, args []string) error {
 }
 
 // runTestImportCoverageOut handles traditional coverage.out file import
-func runTestImportCoverageOut(storeDB *store.Store, coverageFile, basePath string) error {
+func runTestImportCoverageOut(storeDB *store.SQLStore, coverageFile, basePath string) error {
 	fmt.Fprintf(os.Stderr, "Parsing coverage file: %s\n", coverageFile)
 	coverageData, err := coverage.ParseCoverageFile(coverageFile)
 	if err != nil {
@@ -794,7 +794,7 @@ func runTestImportCoverageOut(storeDB *store.Store, coverageFile, basePath strin
 }
 
 // runTestImportPerTestDir handles a directory of per-test coverage.out files
-func runTestImportPerTestDir(storeDB *store.Store, dirPath, basePath string) error {
+func runTestImportPerTestDir(storeDB *store.SQLStore, dirPath, basePath string) error {
 	fmt.Fprintf(os.Stderr, "Parsing per-test coverage directory: %s\n", dirPath)
 
 	perTestData, err := coverage.ParsePerTestCoverageDir(dirPath)
@@ -836,7 +836,7 @@ func runTestImportPerTestDir(storeDB *store.Store, dirPath, basePath string) err
 }
 
 // runTestImportGOCOVERDIR handles Go 1.20+ GOCOVERDIR import
-func runTestImportGOCOVERDIR(storeDB *store.Store, dirPath, basePath string) error {
+func runTestImportGOCOVERDIR(storeDB *store.SQLStore, dirPath, basePath string) error {
 	fmt.Fprintf(os.Stderr, "Parsing GOCOVERDIR: %s\n", dirPath)
 
 	gocoverData, err := coverage.ParseGOCOVERDIR(dirPath)
@@ -1055,7 +1055,7 @@ func testEntityOverlapsLines(e *store.Entity, changedLines []int) bool {
 }
 
 // getTestsForEntityID gets tests that cover a specific entity
-func getTestsForEntityID(s *store.Store, entityID string) ([]*testSelectionInfo, error) {
+func getTestsForEntityID(s *store.SQLStore, entityID string) ([]*testSelectionInfo, error) {
 	rows, err := s.DB().Query(`
 		SELECT test_file, test_name
 		FROM test_entity_map
@@ -1079,7 +1079,7 @@ func getTestsForEntityID(s *store.Store, entityID string) ([]*testSelectionInfo,
 }
 
 // getTestsInFileByPath gets all tests in a specific test file
-func getTestsInFileByPath(s *store.Store, testFile string) ([]*testSelectionInfo, error) {
+func getTestsInFileByPath(s *store.SQLStore, testFile string) ([]*testSelectionInfo, error) {
 	rows, err := s.DB().Query(`
 		SELECT DISTINCT test_file, test_name
 		FROM test_entity_map
@@ -1103,7 +1103,7 @@ func getTestsInFileByPath(s *store.Store, testFile string) ([]*testSelectionInfo
 }
 
 // findTestIntegrationFiles finds test files that might be integration tests
-func findTestIntegrationFiles(s *store.Store) ([]string, error) {
+func findTestIntegrationFiles(s *store.SQLStore) ([]string, error) {
 	rows, err := s.DB().Query(`
 		SELECT DISTINCT test_file
 		FROM test_entity_map
@@ -1151,7 +1151,7 @@ func isTestIntegrationRelevant(testFile string, changedFiles []string) bool {
 }
 
 // getTestTotalCount gets the total number of tests in the project
-func getTestTotalCount(s *store.Store) (int, error) {
+func getTestTotalCount(s *store.SQLStore) (int, error) {
 	var count int
 	err := s.DB().QueryRow(`
 		SELECT COUNT(DISTINCT test_file || '::' || test_name)
@@ -1391,7 +1391,7 @@ func runTestList(cmd *cobra.Command, args []string) error {
 }
 
 // runTestListForEntity shows tests that cover a specific entity
-func runTestListForEntity(cmd *cobra.Command, storeDB *store.Store, entityID string) error {
+func runTestListForEntity(cmd *cobra.Command, storeDB *store.SQLStore, entityID string) error {
 	tests, err := coverage.GetTestsForEntity(storeDB, entityID)
 	if err != nil {
 		return fmt.Errorf("get tests for entity: %w", err)
@@ -1632,7 +1632,7 @@ func runTestImpact(cmd *cobra.Command, args []string) error {
 }
 
 // runTestImpactUncovered lists all uncovered entities
-func runTestImpactUncovered(cmd *cobra.Command, storeDB *store.Store, cfg *config.Config) error {
+func runTestImpactUncovered(cmd *cobra.Command, storeDB *store.SQLStore, cfg *config.Config) error {
 	uncovered, err := coverage.GetUncoveredEntities(storeDB, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to get uncovered entities: %w", err)
@@ -1653,7 +1653,7 @@ func runTestImpactUncovered(cmd *cobra.Command, storeDB *store.Store, cfg *confi
 }
 
 // resolveEntity resolves an entity by name or ID
-func resolveEntity(storeDB *store.Store, query string) (*store.Entity, error) {
+func resolveEntity(storeDB *store.SQLStore, query string) (*store.Entity, error) {
 	// First try exact ID match
 	entity, err := storeDB.GetEntity(query)
 	if err == nil && entity != nil {
@@ -36,6 +36,11 @@ Modes:
   Callee mode:
     cx trace <entity> --callees   Show what this entity calls
 
+  Dominator mode:
+    cx trace <entity> --dominators   Show the entities on every path from
+                                     the entry points (roots) to this one,
+                                     outermost first, and what it guards
+
 Output:
   By default, shows the shortest path as a chain of entities.
   With --all, shows all discovered paths.
@@ -47,6 +52,7 @@ Examples:
   cx trace SaveUser --callers               # Show what calls SaveUser
   cx trace SaveUser --callers --depth 3     # Show callers up to 3 hops
   cx trace HandleRequest --callees          # Show what HandleRequest calls
+  cx trace SaveUser --dominators            # What every path to SaveUser goes through
   cx trace "Auth*" "database" --all         # Pattern matching (all paths)`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runTrace,
@@ -57,6 +63,7 @@ var (
 	traceCallees bool
 	traceAll     bool
 	traceDepth   int
+	traceDoms    bool
 )
 
 func init() {
@@ -66,6 +73,7 @@ func init() {
 	traceCmd.Flags().BoolVar(&traceCallees, "callees", false, "Trace downstream callees")
 	traceCmd.Flags().BoolVar(&traceAll, "all", false, "Show all paths (not just shortest)")
 	traceCmd.Flags().IntVar(&traceDepth, "depth", 5, "Maximum trace depth")
+	traceCmd.Flags().BoolVar(&traceDoms, "dominators", false, "Show the dominators of an entity from the configured roots")
 }
 
 func runTrace(cmd *cobra.Command, args []string) error {
@@ -78,7 +86,11 @@ func runTrace(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--callers and --callees modes require exactly one entity")
 	}
 
-	if !traceCallers && !traceCallees && len(args) < 2 {
+	if traceDoms && (traceCallers || traceCallees || len(args) > 1) {
+		return fmt.Errorf("--dominators takes exactly one entity and no other mode")
+	}
+
+	if !traceCallers && !traceCallees && !traceDoms && len(args) < 2 {
 		return fmt.Errorf("path mode requires two entities: <from> <to>")
 	}
 
@@ -111,6 +123,22 @@ func runTrace(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to build graph: %w", err)
 	}
 
+	if traceDoms {
+		cfg, err := config.Load(".")
+		if err != nil {
+			cfg = config.DefaultConfig()
+		}
+		domOutput, err := traceDominators(args[0], storeDB, g, cfg)
+		if err != nil {
+			return err
+		}
+		formatter, err := output.GetFormatter(format)
+		if err != nil {
+			return fmt.Errorf("failed to get formatter: %w", err)
+		}
+		return formatter.FormatToWriter(cmd.OutOrStdout(), domOutput, density)
+	}
+
 	var traceOutput *output.TraceOutput
 
 	if traceCallers {
@@ -135,7 +163,7 @@ func runTrace(cmd *cobra.Command, args []string) error {
 }
 
 // runTracePath traces the path between two entities
-func runTracePath(fromQuery, toQuery string, storeDB *store.Store, g *graph.Graph) (*output.TraceOutput, error) {
+func runTracePath(fromQuery, toQuery string, storeDB *store.SQLStore, g *graph.Graph) (*output.TraceOutput, error) {
 	// Resolve from entity
 	fromEntity, err := resolveEntityByName(fromQuery, storeDB, "")
 	if err != nil {
@@ -194,7 +222,7 @@ func runTracePath(fromQuery, toQuery string, storeDB *store.Store, g *graph.Grap
 }
 
 // runTraceCallers traces upstream callers of an entity
-func runTraceCallers(query string, storeDB *store.Store, g *graph.Graph) (*output.TraceOutput, error) {
+func runTraceCallers(query string, storeDB *store.SQLStore, g *graph.Graph) (*output.TraceOutput, error) {
 	// Resolve entity
 	entity, err := resolveEntityByName(query, storeDB, "")
 	if err != nil {
@@ -237,7 +265,7 @@ func runTraceCallers(query string, storeDB *store.Store, g *graph.Graph) (*outpu
 }
 
 // runTraceCallees traces downstream callees of an entity
-func runTraceCallees(query string, storeDB *store.Store, g *graph.Graph) (*output.TraceOutput, error) {
+func runTraceCallees(query string, storeDB *store.SQLStore, g *graph.Graph) (*output.TraceOutput, error) {
 	// Resolve entity
 	entity, err := resolveEntityByName(query, storeDB, "")
 	if err != nil {
@@ -280,7 +308,7 @@ func runTraceCallees(query string, storeDB *store.Store, g *graph.Graph) (*outpu
 }
 
 // buildPathNodes converts a path of entity IDs to TracePathNodes
-func buildPathNodes(path []string, storeDB *store.Store) []*output.TracePathNode {
+func buildPathNodes(path []string, storeDB *store.SQLStore) []*output.TracePathNode {
 	nodes := make([]*output.TracePathNode, 0, len(path))
 
 	for i, nodeID := range path {
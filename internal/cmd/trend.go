@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/output"
+	"github.com/anthropics/cx/internal/report"
+	"github.com/anthropics/cx/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var adminTrendCmd = &cobra.Command{
+	Use:   "trend <entity|package|repo>",
+	Short: "Show how a metric changed over scans",
+	Long: `Show how a metric of an entity, a package or the whole repository changed
+over the recorded scans.
+
+Every cx scan snapshots PageRank, fan-in, fan-out, lines of code and
+coverage of each entity with the scan's git commit. Packages and the
+repository sum their entities; coverage is weighted by lines of code over
+the entities that have coverage. An entity is followed by file and name,
+so its trend survives edits that move it within the file.
+
+Metrics: pagerank, coverage, fan_in, fan_out, loc
+
+Output:
+  sparkline:  the series at a glance (▁ lowest, █ highest)
+  series:     one point per scan with its commit and time
+
+--chart mermaid or --chart d2 prints a line chart for reports instead.
+
+Examples:
+  cx admin trend Execute                       # PageRank of an entity
+  cx admin trend internal/store --metric loc   # Lines of code of a package
+  cx admin trend repo --metric coverage        # Repository coverage
+  cx admin trend repo --metric fan_in --chart mermaid`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrend,
+}
+
+var (
+	trendMetric string
+	trendLast   int
+	trendChart  string
+)
+
+func init() {
+	adminCmd.AddCommand(adminTrendCmd)
+	adminTrendCmd.Flags().StringVar(&trendMetric, "metric", "pagerank", "Metric: pagerank|coverage|fan_in|fan_out|loc")
+	adminTrendCmd.Flags().IntVar(&trendLast, "last", 30, "Show only the last N scans (0 for all)")
+	adminTrendCmd.Flags().StringVar(&trendChart, "chart", "", "Print a line chart instead: mermaid|d2")
+}
+
+// sparkBlocks are the sparkline levels, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// MetricTrendOutput is the result of cx admin trend
+type MetricTrendOutput struct {
+	Target    string             `yaml:"target" json:"target"`
+	Scope     string             `yaml:"scope" json:"scope"` // entity, package or repo
+	Metric    string             `yaml:"metric" json:"metric"`
+	Scans     int                `yaml:"scans" json:"scans"`
+	First     float64            `yaml:"first" json:"first"`
+	Last      float64            `yaml:"last" json:"last"`
+	Change    float64            `yaml:"change" json:"change"`
+	Min       float64            `yaml:"min" json:"min"`
+	Max       float64            `yaml:"max" json:"max"`
+	Sparkline string             `yaml:"sparkline" json:"sparkline"`
+	Series    []MetricTrendPoint `yaml:"series" json:"series"`
+}
+
+// MetricTrendPoint is the value of the metric at one scan
+type MetricTrendPoint struct {
+	Scan   int     `yaml:"scan" json:"scan"`
+	Commit string  `yaml:"commit,omitempty" json:"commit,omitempty"`
+	Time   string  `yaml:"time,omitempty" json:"time,omitempty"`
+	Value  float64 `yaml:"value" json:"value"`
+}
+
+func runTrend(cmd *cobra.Command, args []string) error {
+	if !store.IsHistoryMetric(trendMetric) {
+		return fmt.Errorf("invalid --metric %q: must be one of %v", trendMetric, store.HistoryMetrics)
+	}
+	if trendChart != "" && trendChart != "mermaid" && trendChart != "d2" {
+		return fmt.Errorf("invalid --chart %q: must be mermaid or d2", trendChart)
+	}
+
+	storeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	target, scopeKind, scope, err := resolveTrendTarget(args[0], storeDB)
+	if err != nil {
+		return err
+	}
+	if scopeKind == "repo" && trendMetric == "pagerank" {
+		return fmt.Errorf("PageRank sums to 1 over the repository: trend an entity or a package instead")
+	}
+	series, err := storeDB.GetMetricSeries(trendMetric, scope)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		if trendMetric == "coverage" {
+			return fmt.Errorf("no coverage history for %s: import coverage with 'cx coverage import', then run 'cx scan'", target)
+		}
+		return fmt.Errorf("no %s history for %s: run 'cx scan' to record a snapshot", trendMetric, target)
+	}
+	if trendLast > 0 && len(series) > trendLast {
+		series = series[len(series)-trendLast:]
+	}
+
+	result := buildTrendOutput(target, scopeKind, trendMetric, series)
+	if trendChart != "" {
+		points := make([]graph.TrendPoint, len(series))
+		for i, p := range series {
+			points[i] = graph.TrendPoint{Label: trendLabel(p), Value: p.Value}
+		}
+		title := fmt.Sprintf("%s of %s", trendMetric, target)
+		if trendChart == "d2" {
+			fmt.Fprint(cmd.OutOrStdout(), graph.BuildTrendDiagram(points, title))
+		} else {
+			fmt.Fprint(cmd.OutOrStdout(), graph.GenerateLineChart(points, title, trendMetric))
+		}
+		return nil
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	density, err := output.ParseDensity(outputDensity)
+	if err != nil {
+		return fmt.Errorf("invalid density: %w", err)
+	}
+	formatter, err := output.GetFormatter(format)
+	if err != nil {
+		return fmt.Errorf("failed to get formatter: %w", err)
+	}
+	return formatter.FormatToWriter(cmd.OutOrStdout(), result, density)
+}
+
+// resolveTrendTarget reads the target as the repository ("repo" or "."), a
+// package directory with history, or an entity
+func resolveTrendTarget(arg string, storeDB *store.SQLStore) (string, string, store.HistoryScope, error) {
+	if arg == "repo" || arg == "." {
+		return "repo", "repo", store.HistoryScope{}, nil
+	}
+	pkg := filepath.ToSlash(filepath.Clean(strings.TrimPrefix(arg, "./")))
+	if ok, err := storeDB.HasHistoryPackage(pkg); err != nil {
+		return "", "", store.HistoryScope{}, err
+	} else if ok {
+		return pkg, "package", store.HistoryScope{Package: pkg}, nil
+	}
+	entity, err := resolveEntityByName(arg, storeDB, "")
+	if err != nil {
+		return "", "", store.HistoryScope{}, err
+	}
+	return entity.Name, "entity", store.HistoryScope{FilePath: entity.FilePath, Name: entity.Name}, nil
+}
+
+// buildTrendOutput summarizes a series, oldest point first
+func buildTrendOutput(target, scope, metric string, series []store.MetricPoint) *MetricTrendOutput {
+	out := &MetricTrendOutput{
+		Target: target,
+		Scope:  scope,
+		Metric: metric,
+		Scans:  len(series),
+		Min:    math.Inf(1),
+		Max:    math.Inf(-1),
+	}
+	values := make([]float64, len(series))
+	for i, p := range series {
+		v := roundTrend(p.Value)
+		values[i] = v
+		out.Min, out.Max = math.Min(out.Min, v), math.Max(out.Max, v)
+		point := MetricTrendPoint{Scan: p.ScanID, Commit: p.GitCommit, Value: v}
+		if !p.ScanTime.IsZero() {
+			point.Time = p.ScanTime.UTC().Format("2006-01-02T15:04:05Z")
+		}
+		out.Series = append(out.Series, point)
+	}
+	out.First, out.Last = values[0], values[len(values)-1]
+	out.Change = roundTrend(out.Last - out.First)
+	out.Sparkline = sparkline(values)
+	return out
+}
+
+// sparkline draws values as block characters scaled between their minimum
+// and maximum
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		level := len(sparkBlocks) / 2
+		if hi > lo {
+			level = min(int((v-lo)/(hi-lo)*float64(len(sparkBlocks))), len(sparkBlocks)-1)
+		}
+		sb.WriteRune(sparkBlocks[level])
+	}
+	return sb.String()
+}
+
+// trendLabel names a point by its short commit, or its scan without one
+func trendLabel(p store.MetricPoint) string {
+	if len(p.GitCommit) >= 7 {
+		return p.GitCommit[:7]
+	}
+	if p.GitCommit != "" {
+		return p.GitCommit
+	}
+	return fmt.Sprintf("scan %d", p.ScanID)
+}
+
+// roundTrend keeps six decimals, enough for PageRank
+func roundTrend(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// healthTrendMetrics are the repository trends a health report charts
+var healthTrendMetrics = []string{"coverage", "loc"}
+
+// healthTrendScans caps the scans a health report trend covers
+const healthTrendScans = 30
+
+// addHealthTrends adds a D2 line chart of each repository trend with at
+// least two scans to a health report
+func addHealthTrends(data *report.HealthReportData, storeDB *store.SQLStore, theme string) error {
+	for _, metric := range healthTrendMetrics {
+		series, err := storeDB.GetMetricSeries(metric, store.HistoryScope{})
+		if err != nil {
+			return err
+		}
+		if len(series) < 2 {
+			continue
+		}
+		if len(series) > healthTrendScans {
+			series = series[len(series)-healthTrendScans:]
+		}
+		points := make([]graph.TrendPoint, len(series))
+		for i, p := range series {
+			points[i] = graph.TrendPoint{Label: trendLabel(p), Value: p.Value}
+		}
+		if data.Diagrams == nil {
+			data.Diagrams = make(map[string]report.DiagramData)
+		}
+		title := fmt.Sprintf("Repository %s over the last %d scans", metric, len(series))
+		data.Diagrams[metric+"_trend"] = report.DiagramData{
+			Title: title,
+			D2:    graph.BuildTrendDiagram(points, title, theme),
+		}
+	}
+	return nil
+}
+
+// recordMetricHistory snapshots the metrics and coverage of every active
+// entity for a scan, so cx admin trend can follow them
+func recordMetricHistory(storeDB *store.SQLStore, scanID int) error {
+	entities, err := storeDB.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return fmt.Errorf("query entities: %w", err)
+	}
+	all, err := storeDB.GetAllMetrics()
+	if err != nil {
+		return fmt.Errorf("query metrics: %w", err)
+	}
+	metricsByID := make(map[string]*store.Metrics, len(all))
+	for _, m := range all {
+		metricsByID[m.EntityID] = m
+	}
+	coverage := make(map[string]float64)
+	if covs, err := storeDB.GetAllCoverage(); err == nil {
+		for _, c := range covs {
+			coverage[c.EntityID] = c.CoveragePercent
+		}
+	}
+
+	snapshots := make([]store.MetricSnapshot, 0, len(entities))
+	for _, e := range entities {
+		if e.EntityType == "import" {
+			continue
+		}
+		snap := store.MetricSnapshot{
+			EntityID: e.ID,
+			Name:     e.Name,
+			FilePath: e.FilePath,
+			Package:  filepath.ToSlash(filepath.Dir(e.FilePath)),
+			LOC:      1,
+		}
+		if e.LineEnd != nil && *e.LineEnd >= e.LineStart {
+			snap.LOC = *e.LineEnd - e.LineStart + 1
+		}
+		if m := metricsByID[e.ID]; m != nil {
+			snap.PageRank, snap.FanIn, snap.FanOut = m.PageRank, m.InDegree, m.OutDegree
+		}
+		if c, ok := coverage[e.ID]; ok {
+			snap.Coverage = &c
+		}
+		snapshots = append(snapshots, snap)
+	}
+	return storeDB.SaveMetricHistory(scanID, snapshots)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{[]float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{[]float64{10, 0, 10}, "█▁█"},
+		{[]float64{3, 3}, "▅▅"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTrendOutput(t *testing.T) {
+	series := []store.MetricPoint{
+		{ScanID: 1, GitCommit: "a1b2c3d4e5", Value: 0.0012344},
+		{ScanID: 2, GitCommit: "f6a7b8c9d0", Value: 0.0009},
+		{ScanID: 3, Value: 0.002},
+	}
+	out := buildTrendOutput("Execute", "entity", "pagerank", series)
+	if out.Scans != 3 || out.First != 0.001234 || out.Last != 0.002 || out.Min != 0.0009 || out.Max != 0.002 {
+		t.Errorf("summary = %+v", out)
+	}
+	if out.Change != 0.000766 {
+		t.Errorf("Change = %v, want 0.000766", out.Change)
+	}
+	if out.Sparkline != "▃▁█" {
+		t.Errorf("Sparkline = %q", out.Sparkline)
+	}
+	if got := trendLabel(series[0]); got != "a1b2c3d" {
+		t.Errorf("trendLabel = %q, want the short commit", got)
+	}
+	if got := trendLabel(series[2]); got != "scan 3" {
+		t.Errorf("trendLabel = %q, want the scan without a commit", got)
+	}
+}
@@ -64,12 +64,12 @@ func entityTypePriority(entityType string) int {
 //
 // If multiple entities match, it returns an error listing the options.
 // If ref is non-empty, queries at that historical point using AS OF.
-func resolveEntityByName(query string, storeDB *store.Store, ref string) (*store.Entity, error) {
+func resolveEntityByName(query string, storeDB *store.SQLStore, ref string) (*store.Entity, error) {
 	return resolveEntityByNameWithFilter(query, storeDB, "", ref)
 }
 
 // resolveEntityByNameWithFilter is like resolveEntityByName but allows type filtering.
-func resolveEntityByNameWithFilter(query string, storeDB *store.Store, typeFilter string, ref string) (*store.Entity, error) {
+func resolveEntityByNameWithFilter(query string, storeDB *store.SQLStore, typeFilter string, ref string) (*store.Entity, error) {
 	// Check for file-path hint syntax: name@path
 	var fileHint string
 	if atIdx := strings.LastIndex(query, "@"); atIdx > 0 && atIdx < len(query)-1 {
@@ -374,7 +374,7 @@ func extractPackage(e *store.Entity) string {
 }
 
 // openStore is a helper to open the store from the current directory
-func openStore() (*store.Store, error) {
+func openStore() (*store.SQLStore, error) {
 	cxDir, err := config.FindConfigDir(".")
 	if err != nil {
 		return nil, fmt.Errorf("cx not initialized: run 'cx scan' first")
@@ -531,7 +531,7 @@ func (s *SearchOutput) MarshalYAML() (interface{}, error) {
 }
 
 // buildSearchOutput converts search results to output format
-func buildSearchOutput(results []*store.SearchResult, density output.Density, storeDB *store.Store, query string) *SearchOutput {
+func buildSearchOutput(results []*store.SearchResult, density output.Density, storeDB *store.SQLStore, query string) *SearchOutput {
 	searchOutput := &SearchOutput{
 		Query:   query,
 		Results: make(map[string]*output.EntityOutput),
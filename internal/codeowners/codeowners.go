@@ -0,0 +1,156 @@
+// Package codeowners parses CODEOWNERS files and matches their owners
+// against git authors.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations are the paths GitHub looks for a CODEOWNERS file at, in order.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	// Path is the file's path relative to the project root.
+	Path  string
+	rules []rule
+}
+
+// rule is one pattern line; a rule without owners unassigns the paths
+type rule struct {
+	pattern string
+	re      *regexp.Regexp
+	owners  []string
+}
+
+// Load reads the first CODEOWNERS file found in the project root. It returns
+// nil without an error when the project has none.
+func Load(root string) (*File, error) {
+	for _, loc := range Locations {
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(loc)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		parsed, err := Parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", loc, err)
+		}
+		parsed.Path = loc
+		return parsed, nil
+	}
+	return nil, nil
+}
+
+// Parse reads CODEOWNERS rules: a gitignore-style pattern followed by
+// owners (@user, @org/team or an email) per line, # for comments.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		re, err := compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", fields[0], err)
+		}
+		f.rules = append(f.rules, rule{pattern: fields[0], re: re, owners: fields[1:]})
+	}
+	return f, scanner.Err()
+}
+
+// Owners returns the owners of a slash-separated path relative to the
+// project root. The last matching rule wins, as on GitHub.
+func (f *File) Owners(path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "./")
+	for i := len(f.rules) - 1; i >= 0; i-- {
+		if f.rules[i].re.MatchString(path) {
+			return f.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// compile turns a CODEOWNERS pattern into a regular expression over paths.
+// A pattern without a slash matches at any depth, a pattern that names a
+// directory matches everything below it, and "dir/*" matches only the
+// direct children.
+func compile(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.Trim(pattern, "/"), "/")
+	p := strings.Trim(pattern, "/")
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case p[i] == '*':
+			sb.WriteString("[^/]*")
+		case p[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	if strings.HasSuffix(p, "/*") {
+		sb.WriteString("$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
+
+// IsTeam reports whether an owner is a team (@org/team), which cannot be
+// matched against individual authors.
+func IsTeam(owner string) bool {
+	return strings.HasPrefix(owner, "@") && strings.Contains(owner, "/")
+}
+
+// MatchesAuthor reports whether an owner names a git author. Emails must
+// match exactly. A @user handle matches the local part of the author's
+// email, a GitHub noreply address for the handle, or the author's name
+// without spaces, all case-insensitively. Teams never match.
+func MatchesAuthor(owner, name, email string) bool {
+	email = strings.ToLower(email)
+	if !strings.HasPrefix(owner, "@") {
+		return strings.EqualFold(owner, email)
+	}
+	if IsTeam(owner) {
+		return false
+	}
+	handle := strings.ToLower(owner[1:])
+	local, domain, _ := strings.Cut(email, "@")
+	if local == handle {
+		return true
+	}
+	if domain == "users.noreply.github.com" {
+		if _, user, ok := strings.Cut(local, "+"); ok && user == handle {
+			return true
+		}
+	}
+	return strings.ToLower(strings.ReplaceAll(name, " ", "")) == handle
+}
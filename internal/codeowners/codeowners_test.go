@@ -0,0 +1,81 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `# Default owners
+*                 @acme/core
+*.sql             @dba
+/docs/            docs@example.com
+internal/store/   @ada @bob   # storage
+cmd/*             @cy
+**/testdata/**    @qa
+/vendor/
+`
+
+func TestOwners(t *testing.T) {
+	f, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"main.go", []string{"@acme/core"}},
+		{"db/migrations/001.sql", []string{"@dba"}},
+		{"docs/guide/intro.md", []string{"docs@example.com"}},
+		{"internal/docs/x.md", []string{"@acme/core"}}, // /docs/ is anchored
+		{"internal/store/sqlite.go", []string{"@ada", "@bob"}},
+		{"cmd/cx/main.go", []string{"@acme/core"}}, // cmd/* matches direct children only
+		{"cmd/root.go", []string{"@cy"}},
+		{"internal/diff/testdata/a.txt", []string{"@qa"}},
+		{"vendor/lib/lib.go", []string{}},
+	}
+	for _, tt := range tests {
+		got := f.Owners(tt.path)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	if f, err := Load(root); f != nil || err != nil {
+		t.Fatalf("Load without CODEOWNERS = %v, %v; want nil, nil", f, err)
+	}
+	os.MkdirAll(filepath.Join(root, ".github"), 0o755)
+	os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte("* @ada\n"), 0o644)
+	f, err := Load(root)
+	if err != nil || f == nil || f.Path != ".github/CODEOWNERS" {
+		t.Fatalf("Load = %+v, %v", f, err)
+	}
+}
+
+func TestMatchesAuthor(t *testing.T) {
+	tests := []struct {
+		owner, name, email string
+		want               bool
+	}{
+		{"ada@example.com", "Ada", "Ada@Example.com", true},
+		{"@ada", "Ada Lovelace", "ada@example.com", true},
+		{"@ada", "Ada", "12345+ada@users.noreply.github.com", true},
+		{"@adalovelace", "Ada Lovelace", "al@example.com", true},
+		{"@bob", "Ada", "ada@example.com", false},
+		{"@acme/ada", "Ada", "ada@example.com", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesAuthor(tt.owner, tt.name, tt.email); got != tt.want {
+			t.Errorf("MatchesAuthor(%q, %q, %q) = %v, want %v", tt.owner, tt.name, tt.email, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,16 +18,96 @@ const ConfigDirName = ".cx"
 
 // Config holds all cx configuration
 type Config struct {
-	Storage StorageConfig `yaml:"storage"`
-	Scan    ScanConfig    `yaml:"scan"`
-	Metrics MetricsConfig `yaml:"metrics"`
-	Output  OutputConfig  `yaml:"output"`
-	Guard   GuardConfig   `yaml:"guard"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Scan       ScanConfig       `yaml:"scan"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Output     OutputConfig     `yaml:"output"`
+	Guard      GuardConfig      `yaml:"guard"`
+	Embeddings EmbeddingsConfig `yaml:"embeddings"`
+	Tokenizer  TokenizerConfig  `yaml:"tokenizer"`
+	Rules      RulesConfig      `yaml:"rules,omitempty"`
+	Roots      RootsConfig      `yaml:"roots,omitempty"`
+}
+
+// RootsConfig selects the entry points that dominator (chokepoint) analysis
+// and the unreachable tier of cx dead follow dependencies from.
+type RootsConfig struct {
+	// Kinds are the built-in kinds of entry point: main (main and init),
+	// commands (CLI command handlers), handlers (HTTP handlers) and exported
+	// (the public API outside internal and cmd directories).
+	Kinds []string `yaml:"kinds,omitempty"`
+	// Include adds entities as roots by selector: a path glob, optionally
+	// followed by #Name (cmd/server/**#main, #HandleWebhook).
+	Include []string `yaml:"include,omitempty"`
+	// Tags makes entities carrying any of these tags roots (cx tag add X
+	// entrypoint).
+	Tags []string `yaml:"tags,omitempty"`
+	// Annotations makes entities roots when their doc comment or first
+	// source line contains any of these markers, for code reached through
+	// reflection or dependency injection (@Component, @app.route, wire:inject).
+	Annotations []string `yaml:"annotations,omitempty"`
+}
+
+// RootKinds are the valid kinds of entry point in RootsConfig.
+var RootKinds = []string{"main", "commands", "handlers", "exported"}
+
+// RulesConfig declares architecture layers and the dependencies allowed
+// between them, checked by cx check --architecture and cx guard.
+type RulesConfig struct {
+	Layers []LayerConfig     `yaml:"layers,omitempty"`
+	Allow  []DependencyRule  `yaml:"allow,omitempty"`  // a layer with allow rules may only depend on the listed layers
+	Forbid []DependencyRule  `yaml:"forbid,omitempty"` // dependencies that must not exist
+	Only   []OnlyCallersRule `yaml:"only,omitempty"`   // only the listed callers may depend on a target
+}
+
+// LayerConfig names a group of files. An entity belongs to the first layer
+// with a matching path glob; a path without wildcards matches its directory tree.
+type LayerConfig struct {
+	Name  string   `yaml:"name"`
+	Paths []string `yaml:"paths"`
+}
+
+// DependencyRule is an allowed or forbidden direction between selectors.
+// A selector is a layer name or a path glob, optionally followed by
+// #Name to pick entities by name (store#Open, internal/store/**#Open).
+type DependencyRule struct {
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	DepTypes []string `yaml:"dep_types,omitempty"` // default: all code dependencies
+	Severity string   `yaml:"severity,omitempty"`  // error (default) or warning
+	Reason   string   `yaml:"reason,omitempty"`
+}
+
+// OnlyCallersRule restricts which selectors may depend on a target.
+// Dependencies from within the target itself are always allowed.
+type OnlyCallersRule struct {
+	Target   string   `yaml:"target"`
+	Callers  []string `yaml:"callers"`
+	DepTypes []string `yaml:"dep_types,omitempty"`
+	Severity string   `yaml:"severity,omitempty"`
+	Reason   string   `yaml:"reason,omitempty"`
 }
 
 // StorageConfig holds configuration for the storage backend
 type StorageConfig struct {
-	Backend string `yaml:"backend"` // "dolt" (default) - the storage backend to use
+	Backend string `yaml:"backend"` // "dolt" (default) or "sqlite" - the storage backend to use
+}
+
+// EmbeddingsConfig selects the embedding provider used for semantic search
+type EmbeddingsConfig struct {
+	Provider   string `yaml:"provider"`              // "local" (default), "onnx", "openai" or "ollama"
+	Model      string `yaml:"model,omitempty"`       // Model name; defaults depend on the provider
+	ModelPath  string `yaml:"model_path,omitempty"`  // onnx: directory containing the .onnx model and tokenizer.json
+	Endpoint   string `yaml:"endpoint,omitempty"`    // openai/ollama: base URL of the API
+	APIKeyEnv  string `yaml:"api_key_env,omitempty"` // openai: environment variable holding the API key
+	Dimensions int    `yaml:"dimensions,omitempty"`  // openai: requested embedding size (0 = model default)
+}
+
+// TokenizerConfig selects how token budgets count the tokens of context output
+type TokenizerConfig struct {
+	Kind      string `yaml:"kind"`                 // "bpe" (default) or "heuristic"
+	VocabPath string `yaml:"vocab_path,omitempty"` // bpe: tiktoken vocabulary file (o200k_base.tiktoken) instead of the embedded cl100k_base
+	Encoding  string `yaml:"encoding,omitempty"`   // bpe: pre-tokenization of vocab_path, "cl100k" (default) or "o200k"
 }
 
 // GuardConfig holds configuration for the pre-commit guard
@@ -48,8 +129,13 @@ type MetricsConfig struct {
 	PageRankIterations  int     `yaml:"pagerank_iterations"`
 	KeystoneThreshold   float64 `yaml:"keystone_threshold"`
 	BottleneckThreshold float64 `yaml:"bottleneck_threshold"`
+	BetweennessPivots   int     `yaml:"betweenness_pivots"` // sampled sources; exact for smaller graphs, or always with BetweennessExact
 }
 
+// BetweennessExact as metrics.betweenness_pivots computes exact betweenness
+// from every node. 0 means unset and takes the default.
+const BetweennessExact = -1
+
 // OutputConfig holds configuration for output formatting
 type OutputConfig struct {
 	DefaultDensity string `yaml:"default_density"`
@@ -160,7 +246,7 @@ func EnsureConfigDir(workDir string) (string, error) {
 }
 
 // ValidStorageBackends lists the valid storage backend options
-var ValidStorageBackends = []string{"dolt"}
+var ValidStorageBackends = []string{"dolt", "sqlite"}
 
 // IsValidStorageBackend checks if the given backend value is valid
 func IsValidStorageBackend(backend string) bool {
@@ -172,6 +258,25 @@ func IsValidStorageBackend(backend string) bool {
 	return false
 }
 
+// ValidEmbeddingProviders lists the valid embeddings.provider options
+var ValidEmbeddingProviders = []string{"local", "onnx", "openai", "ollama"}
+
+// IsValidEmbeddingProvider checks if the given provider value is valid
+func IsValidEmbeddingProvider(provider string) bool {
+	for _, valid := range ValidEmbeddingProviders {
+		if provider == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidTokenizers lists the valid tokenizer.kind options
+var ValidTokenizers = []string{"bpe", "heuristic"}
+
+// ValidTokenizerEncodings lists the valid tokenizer.encoding options
+var ValidTokenizerEncodings = []string{"cl100k", "o200k"}
+
 // Validate checks that config values are valid.
 // Returns an error if validation fails.
 func Validate(cfg *Config) error {
@@ -181,6 +286,38 @@ func Validate(cfg *Config) error {
 			ErrInvalidConfig, ValidStorageBackends, cfg.Storage.Backend)
 	}
 
+	// Validate embedding provider
+	if !IsValidEmbeddingProvider(cfg.Embeddings.Provider) {
+		return fmt.Errorf("%w: embeddings.provider must be one of %v, got %q",
+			ErrInvalidConfig, ValidEmbeddingProviders, cfg.Embeddings.Provider)
+	}
+	if cfg.Embeddings.Provider == "onnx" && cfg.Embeddings.ModelPath == "" {
+		return fmt.Errorf("%w: embeddings.model_path is required for the onnx provider", ErrInvalidConfig)
+	}
+	if cfg.Embeddings.Dimensions < 0 {
+		return fmt.Errorf("%w: embeddings.dimensions must be non-negative, got %d",
+			ErrInvalidConfig, cfg.Embeddings.Dimensions)
+	}
+
+	if !slices.Contains(ValidTokenizers, cfg.Tokenizer.Kind) {
+		return fmt.Errorf("%w: tokenizer.kind must be one of %v, got %q",
+			ErrInvalidConfig, ValidTokenizers, cfg.Tokenizer.Kind)
+	}
+	if cfg.Tokenizer.Encoding != "" && !slices.Contains(ValidTokenizerEncodings, cfg.Tokenizer.Encoding) {
+		return fmt.Errorf("%w: tokenizer.encoding must be one of %v, got %q",
+			ErrInvalidConfig, ValidTokenizerEncodings, cfg.Tokenizer.Encoding)
+	}
+
+	if err := validateRules(&cfg.Rules); err != nil {
+		return err
+	}
+	for _, kind := range cfg.Roots.Kinds {
+		if !slices.Contains(RootKinds, kind) {
+			return fmt.Errorf("%w: roots.kinds must be among %v, got %q",
+				ErrInvalidConfig, RootKinds, kind)
+		}
+	}
+
 	// Validate density
 	if !IsValidDensity(cfg.Output.DefaultDensity) {
 		return fmt.Errorf("%w: default_density must be one of %v, got %q",
@@ -210,6 +347,11 @@ func Validate(cfg *Config) error {
 			ErrInvalidConfig, cfg.Metrics.BottleneckThreshold)
 	}
 
+	if cfg.Metrics.BetweennessPivots < BetweennessExact {
+		return fmt.Errorf("%w: betweenness_pivots must be %d (exact) or non-negative, got %d",
+			ErrInvalidConfig, BetweennessExact, cfg.Metrics.BetweennessPivots)
+	}
+
 	// Validate hops (should be non-negative)
 	if cfg.Output.DefaultHops < 0 {
 		return fmt.Errorf("%w: default_hops must be non-negative, got %d",
@@ -225,6 +367,64 @@ func Validate(cfg *Config) error {
 	return nil
 }
 
+// ValidRuleSeverities lists the valid severity values for architecture rules
+var ValidRuleSeverities = []string{"error", "warning"}
+
+// validateRules checks that layers are named once and every rule has a
+// source and targets. Selectors that are neither layers nor globs cannot be
+// told apart from paths, so they are not checked here.
+func validateRules(rules *RulesConfig) error {
+	layers := make(map[string]bool)
+	for i, l := range rules.Layers {
+		if l.Name == "" {
+			return fmt.Errorf("%w: rules.layers[%d] has no name", ErrInvalidConfig, i)
+		}
+		if layers[l.Name] {
+			return fmt.Errorf("%w: rules.layers: duplicate layer %q", ErrInvalidConfig, l.Name)
+		}
+		if len(l.Paths) == 0 {
+			return fmt.Errorf("%w: rules.layers: layer %q has no paths", ErrInvalidConfig, l.Name)
+		}
+		layers[l.Name] = true
+	}
+
+	checkSeverity := func(where, severity string) error {
+		if severity != "" && severity != "error" && severity != "warning" {
+			return fmt.Errorf("%w: %s: severity must be one of %v, got %q",
+				ErrInvalidConfig, where, ValidRuleSeverities, severity)
+		}
+		return nil
+	}
+	for _, group := range []struct {
+		name  string
+		rules []DependencyRule
+	}{{"allow", rules.Allow}, {"forbid", rules.Forbid}} {
+		name := group.name
+		for i, r := range group.rules {
+			where := fmt.Sprintf("rules.%s[%d]", name, i)
+			if r.From == "" || len(r.To) == 0 {
+				return fmt.Errorf("%w: %s needs from and to", ErrInvalidConfig, where)
+			}
+			if name == "allow" && !layers[r.From] {
+				return fmt.Errorf("%w: %s: from must be a layer, got %q", ErrInvalidConfig, where, r.From)
+			}
+			if err := checkSeverity(where, r.Severity); err != nil {
+				return err
+			}
+		}
+	}
+	for i, r := range rules.Only {
+		where := fmt.Sprintf("rules.only[%d]", i)
+		if r.Target == "" || len(r.Callers) == 0 {
+			return fmt.Errorf("%w: %s needs target and callers", ErrInvalidConfig, where)
+		}
+		if err := checkSeverity(where, r.Severity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SaveDefault writes the default configuration to .cx/config.yaml in workDir.
 // Creates the .cx directory if it doesn't exist.
 func SaveDefault(workDir string) (string, error) {
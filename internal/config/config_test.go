@@ -96,7 +96,8 @@ func TestIsValidStorageBackend(t *testing.T) {
 		valid   bool
 	}{
 		{"dolt", true},
-		{"sqlite", false},
+		{"sqlite", true},
+		{"postgres", false},
 		{"", false},
 		{"DOLT", false}, // case sensitive
 	}
@@ -124,9 +125,72 @@ func TestValidate(t *testing.T) {
 		},
 		{
 			name: "invalid storage backend",
+			modify: func(c *Config) {
+				c.Storage.Backend = "postgres"
+			},
+			wantErr: true,
+		},
+		{
+			name: "exact betweenness",
+			modify: func(c *Config) {
+				c.Metrics.BetweennessPivots = BetweennessExact
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid betweenness pivots",
+			modify: func(c *Config) {
+				c.Metrics.BetweennessPivots = -2
+			},
+			wantErr: true,
+		},
+		{
+			name: "sqlite storage backend",
 			modify: func(c *Config) {
 				c.Storage.Backend = "sqlite"
 			},
+			wantErr: false,
+		},
+		{
+			name: "ollama embedding provider",
+			modify: func(c *Config) {
+				c.Embeddings.Provider = "ollama"
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid embedding provider",
+			modify: func(c *Config) {
+				c.Embeddings.Provider = "cohere"
+			},
+			wantErr: true,
+		},
+		{
+			name: "onnx provider without model_path",
+			modify: func(c *Config) {
+				c.Embeddings.Provider = "onnx"
+			},
+			wantErr: true,
+		},
+		{
+			name: "heuristic tokenizer",
+			modify: func(c *Config) {
+				c.Tokenizer.Kind = "heuristic"
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid tokenizer",
+			modify: func(c *Config) {
+				c.Tokenizer.Kind = "sentencepiece"
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tokenizer encoding",
+			modify: func(c *Config) {
+				c.Tokenizer.Encoding = "p50k"
+			},
 			wantErr: true,
 		},
 		{
@@ -185,6 +249,43 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "architecture rules",
+			modify: func(c *Config) {
+				c.Rules.Layers = []LayerConfig{{Name: "cmd", Paths: []string{"internal/cmd"}}, {Name: "store", Paths: []string{"internal/store/**"}}}
+				c.Rules.Allow = []DependencyRule{{From: "cmd", To: []string{"store"}}}
+				c.Rules.Only = []OnlyCallersRule{{Target: "store#Open", Callers: []string{"cmd"}, Severity: "warning"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate layer",
+			modify: func(c *Config) {
+				c.Rules.Layers = []LayerConfig{{Name: "cmd", Paths: []string{"a"}}, {Name: "cmd", Paths: []string{"b"}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "allow rule from unknown layer",
+			modify: func(c *Config) {
+				c.Rules.Allow = []DependencyRule{{From: "cmd", To: []string{"store"}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown root kind",
+			modify: func(c *Config) {
+				c.Roots.Kinds = []string{"main", "lambdas"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid rule severity",
+			modify: func(c *Config) {
+				c.Rules.Forbid = []DependencyRule{{From: "internal/mcp/**", To: []string{"internal/cmd/**"}, Severity: "fatal"}}
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -213,6 +314,13 @@ func TestMerge(t *testing.T) {
 		if merged.Metrics.PageRankDamping != defaults.Metrics.PageRankDamping {
 			t.Errorf("expected damping %f, got %f", defaults.Metrics.PageRankDamping, merged.Metrics.PageRankDamping)
 		}
+
+		if len(merged.Roots.Kinds) != len(defaults.Roots.Kinds) {
+			t.Errorf("expected root kinds %v, got %v", defaults.Roots.Kinds, merged.Roots.Kinds)
+		}
+		if len(merged.Roots.Tags) != 1 || merged.Roots.Tags[0] != "entrypoint" {
+			t.Errorf("expected root tags [entrypoint], got %v", merged.Roots.Tags)
+		}
 	})
 
 	t.Run("loaded values take precedence", func(t *testing.T) {
@@ -222,7 +330,8 @@ func TestMerge(t *testing.T) {
 				MaxTokens:      8000,
 			},
 			Metrics: MetricsConfig{
-				PageRankDamping: 0.90,
+				PageRankDamping:   0.90,
+				BetweennessPivots: BetweennessExact,
 			},
 		}
 		merged := Merge(loaded, defaults)
@@ -239,6 +348,10 @@ func TestMerge(t *testing.T) {
 			t.Errorf("expected damping 0.90, got %f", merged.Metrics.PageRankDamping)
 		}
 
+		if merged.Metrics.BetweennessPivots != BetweennessExact {
+			t.Errorf("expected exact betweenness pivots, got %d", merged.Metrics.BetweennessPivots)
+		}
+
 		// Unset values should use defaults
 		if merged.Output.DefaultHops != defaults.Output.DefaultHops {
 			t.Errorf("expected default hops %d, got %d", defaults.Output.DefaultHops, merged.Output.DefaultHops)
@@ -384,6 +497,43 @@ output:
 		}
 	})
 
+	t.Run("loads architecture rules", func(t *testing.T) {
+		configPath := filepath.Join(tmpDir, "rules.yaml")
+		content := `
+rules:
+  layers:
+    - name: cmd
+      paths: [internal/cmd]
+    - name: store
+      paths: ["internal/store/**"]
+  forbid:
+    - from: store
+      to: [cmd]
+      reason: the store must not depend on the CLI
+  only:
+    - target: store
+      callers: [cmd]
+      severity: warning
+`
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadFromPath(configPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cfg.Rules.Layers) != 2 || cfg.Rules.Layers[1].Paths[0] != "internal/store/**" {
+			t.Errorf("layers = %+v", cfg.Rules.Layers)
+		}
+		if len(cfg.Rules.Forbid) != 1 || cfg.Rules.Forbid[0].Reason == "" {
+			t.Errorf("forbid = %+v", cfg.Rules.Forbid)
+		}
+		if len(cfg.Rules.Only) != 1 || cfg.Rules.Only[0].Severity != "warning" {
+			t.Errorf("only = %+v", cfg.Rules.Only)
+		}
+	})
+
 	t.Run("returns defaults for non-existent file", func(t *testing.T) {
 		cfg, err := LoadFromPath(filepath.Join(tmpDir, "nonexistent.yaml"))
 		if err != nil {
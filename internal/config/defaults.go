@@ -25,6 +25,7 @@ func DefaultConfig() *Config {
 			PageRankIterations:  100,
 			KeystoneThreshold:   0.30,
 			BottleneckThreshold: 0.20,
+			BetweennessPivots:   256,
 		},
 		Output: OutputConfig{
 			DefaultDensity: "medium",
@@ -36,6 +37,16 @@ func DefaultConfig() *Config {
 			MinCoverageForKeystones:  50.0,
 			FailOnWarnings:           false,
 		},
+		Embeddings: EmbeddingsConfig{
+			Provider: "local",
+		},
+		Tokenizer: TokenizerConfig{
+			Kind: "bpe",
+		},
+		Roots: RootsConfig{
+			Kinds: []string{"main", "commands", "handlers"},
+			Tags:  []string{"entrypoint"},
+		},
 	}
 }
 
@@ -60,6 +71,27 @@ func Merge(loaded, defaults *Config) *Config {
 	// Merge Guard config
 	result.Guard = mergeGuardConfig(loaded.Guard, defaults.Guard)
 
+	// Merge Embeddings config
+	result.Embeddings = mergeEmbeddingsConfig(loaded.Embeddings, defaults.Embeddings)
+
+	// Tokenizer: default kind unless configured
+	result.Tokenizer = loaded.Tokenizer
+	if result.Tokenizer.Kind == "" {
+		result.Tokenizer.Kind = defaults.Tokenizer.Kind
+	}
+
+	// Rules have no defaults
+	result.Rules = loaded.Rules
+
+	// Roots: default kinds and tags unless configured
+	result.Roots = loaded.Roots
+	if len(result.Roots.Kinds) == 0 {
+		result.Roots.Kinds = defaults.Roots.Kinds
+	}
+	if len(result.Roots.Tags) == 0 {
+		result.Roots.Tags = defaults.Roots.Tags
+	}
+
 	return result
 }
 
@@ -127,6 +159,13 @@ func mergeMetricsConfig(loaded, defaults MetricsConfig) MetricsConfig {
 		result.BottleneckThreshold = defaults.BottleneckThreshold
 	}
 
+	// BetweennessPivots: use loaded if non-zero; BetweennessExact is kept
+	if loaded.BetweennessPivots != 0 {
+		result.BetweennessPivots = loaded.BetweennessPivots
+	} else {
+		result.BetweennessPivots = defaults.BetweennessPivots
+	}
+
 	return result
 }
 
@@ -183,6 +222,18 @@ func mergeGuardConfig(loaded, defaults GuardConfig) GuardConfig {
 	return result
 }
 
+func mergeEmbeddingsConfig(loaded, defaults EmbeddingsConfig) EmbeddingsConfig {
+	result := loaded
+
+	// Provider: use loaded if non-empty; the other fields are provider specific
+	// and have no defaults here
+	if loaded.Provider == "" {
+		result.Provider = defaults.Provider
+	}
+
+	return result
+}
+
 // ValidDensities lists the valid values for output density
 var ValidDensities = []string{"sparse", "medium", "dense"}
 
@@ -0,0 +1,304 @@
+package context
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/store"
+)
+
+// Detail tiers of packed context, from most to least detailed.
+const (
+	// TierFull is the doc comment, signature and body from body_text.
+	TierFull = "full"
+	// TierSignature is the doc comment and signature.
+	TierSignature = "signature"
+	// TierSkeleton is the one-line skeleton.
+	TierSkeleton = "skeleton"
+)
+
+// Tier indexes in packing; tierNone leaves the entity out.
+const (
+	tierNone = iota
+	tierSkeleton
+	tierSignature
+	tierFull
+	tierCount
+)
+
+// tierNames names the tiers by index
+var tierNames = [tierCount]string{"", TierSkeleton, TierSignature, TierFull}
+
+// tierValues is the share of an entity's relevance each tier delivers: a
+// signature tells an agent how to call code, the body what it does.
+var tierValues = [tierCount]float64{0, 0.2, 0.5, 1.0}
+
+// packItem is an entity's cost in tokens at each tier, 0 when the tier is
+// not available, and its relevance
+type packItem struct {
+	relevance float64
+	cost      [tierCount]int
+	pinned    bool // asked for in full with --expand
+	provided  int  // tier the session already has, or tierNone
+}
+
+// spend is the tokens sending the item at tier t adds to the output: none up
+// to the tier the session already has, the whole tier above it
+func (it packItem) spend(t int) int {
+	if t <= it.provided {
+		return 0
+	}
+	return it.cost[t]
+}
+
+// pack fills the budget with tiers of the relevant entities, most relevant
+// first in full, and records each entity's tier and code. Entities that
+// fit in no tier move to the excluded list. In a session, tiers already
+// delivered cost nothing, and the IDs of the entities left at them are
+// returned as provided instead of being sent again.
+func (sc *SmartContext) pack(relevant []*RelevantEntity) (packed []*RelevantEntity, excluded []*ExcludedEntity, provided []string) {
+	expand := make(map[string]bool, len(sc.options.Expand))
+	for _, name := range sc.options.Expand {
+		expand[name] = true
+	}
+	relevant = append(relevant, sc.expansions(relevant)...)
+
+	items := make([]packItem, len(relevant))
+	texts := make([][tierCount]string, len(relevant))
+	for i, r := range relevant {
+		texts[i] = tierTexts(r.Entity)
+		items[i] = packItem{relevance: r.Relevance, pinned: expand[r.Name] || expand[r.ID]}
+		if !items[i].pinned {
+			items[i].provided = sc.providedTier(r)
+		}
+		for t := tierSkeleton; t < tierCount; t++ {
+			if texts[i][t] != "" {
+				items[i].cost[t] = sc.options.Tokenizer.Count(packedEntry(r, tierNames[t], texts[i][t]))
+			}
+		}
+	}
+
+	tiers := packTiers(items, sc.options.Budget)
+
+	for i, r := range relevant {
+		switch tiers[i] {
+		case tierNone:
+			excluded = append(excluded, &ExcludedEntity{Name: r.Name, Reason: "Over budget"})
+			continue
+		case items[i].provided:
+			provided = append(provided, r.ID)
+			continue
+		}
+		r.Tier = tierNames[tiers[i]]
+		r.Code = texts[i][tiers[i]]
+		r.Tokens = items[i].cost[tiers[i]]
+		if sc.options.Session != nil {
+			r.Delta = sc.options.Session.delta(r, r.Tier)
+		}
+		packed = append(packed, r)
+	}
+	return packed, excluded, provided
+}
+
+// expansions returns the entities asked for with --expand that ranking did
+// not reach, looked up by ID or else by exact name
+func (sc *SmartContext) expansions(relevant []*RelevantEntity) []*RelevantEntity {
+	seen := make(map[string]bool, 2*len(relevant))
+	for _, r := range relevant {
+		seen[r.ID] = true
+		seen[r.Name] = true
+	}
+	var added []*RelevantEntity
+	for _, name := range sc.options.Expand {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		e, err := sc.store.GetEntity(name)
+		if err != nil || e == nil {
+			e = nil
+			matches, _ := sc.store.QueryEntities(store.EntityFilter{Name: name, Status: "active"})
+			for _, m := range matches {
+				if m.Name == name {
+					e = m
+					break
+				}
+			}
+		}
+		if e == nil || seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		added = append(added, &RelevantEntity{
+			Entity:    e,
+			ID:        e.ID,
+			Name:      e.Name,
+			Type:      e.EntityType,
+			Location:  formatLocation(e),
+			Relevance: 1.0,
+			Reason:    "Expanded on request",
+		})
+	}
+	return added
+}
+
+// packTiers picks a tier per item within budget, maximizing the sum of
+// relevance times tier value. This is a multiple-choice knapsack, solved
+// greedily: each item's tiers form a ladder of upgrades whose value per
+// token falls, and upgrades are taken across all items in order of value
+// per token while they fit. Pinned items get their fullest tier first.
+// Items start at the tier the session already has, which costs nothing.
+func packTiers(items []packItem, budget int) []int {
+	tiers := make([]int, len(items))
+	for i, it := range items {
+		tiers[i] = it.provided
+	}
+	used := 0
+
+	for i, it := range items {
+		if !it.pinned {
+			continue
+		}
+		for t := tierFull; t > tierNone; t-- {
+			if it.cost[t] > 0 && used+it.cost[t] <= budget {
+				tiers[i] = t
+				used += it.cost[t]
+				break
+			}
+		}
+	}
+
+	type upgrade struct {
+		item, from, to int
+		efficiency     float64
+	}
+	var upgrades []upgrade
+	for i, it := range items {
+		if it.pinned {
+			continue
+		}
+		for _, step := range upgradeLadder(it) {
+			upgrades = append(upgrades, upgrade{i, step[0], step[1], 0})
+			u := &upgrades[len(upgrades)-1]
+			u.efficiency = it.relevance * (tierValues[u.to] - tierValues[u.from]) / float64(it.spend(u.to)-it.spend(u.from))
+		}
+	}
+	sort.SliceStable(upgrades, func(a, b int) bool { return upgrades[a].efficiency > upgrades[b].efficiency })
+
+	for _, u := range upgrades {
+		// An item skipped at one step is not upgraded past it
+		if tiers[u.item] != u.from {
+			continue
+		}
+		delta := items[u.item].spend(u.to) - items[u.item].spend(u.from)
+		if used+delta <= budget {
+			tiers[u.item] = u.to
+			used += delta
+		}
+	}
+
+	// Spend what is left on the most relevant items that can still grow
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return items[order[a]].relevance > items[order[b]].relevance })
+	for _, i := range order {
+		for t := tierFull; t > tiers[i]; t-- {
+			delta := items[i].spend(t) - items[i].spend(tiers[i])
+			if items[i].cost[t] > 0 && used+delta <= budget {
+				tiers[i] = t
+				used += delta
+				break
+			}
+		}
+	}
+	return tiers
+}
+
+// upgradeLadder returns the steps from the tier the session has to the
+// item's available tiers above it along their upper convex hull, so that
+// value per token falls from step to step. Tiers that cost as much as a
+// cheaper one for less value are dropped.
+func upgradeLadder(it packItem) [][2]int {
+	hull := []int{it.provided}
+	for t := it.provided + 1; t < tierCount; t++ {
+		if it.cost[t] <= 0 {
+			continue
+		}
+		for len(hull) > 1 && it.spend(t) <= it.spend(hull[len(hull)-1]) {
+			hull = hull[:len(hull)-1] // the richer tier is no dearer
+		}
+		for len(hull) >= 2 && !concave(it, hull[len(hull)-2], hull[len(hull)-1], t) {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, t)
+	}
+	steps := make([][2]int, 0, len(hull)-1)
+	for k := 1; k < len(hull); k++ {
+		steps = append(steps, [2]int{hull[k-1], hull[k]})
+	}
+	return steps
+}
+
+// concave reports whether the step a→b yields at least the value per token
+// of b→c
+func concave(it packItem, a, b, c int) bool {
+	return (tierValues[b]-tierValues[a])*float64(it.spend(c)-it.spend(b)) >=
+		(tierValues[c]-tierValues[b])*float64(it.spend(b)-it.spend(a))
+}
+
+// tierTexts returns an entity's code at each tier, empty when a tier adds
+// nothing over the one below it
+func tierTexts(e *store.Entity) [tierCount]string {
+	var texts [tierCount]string
+	if e == nil {
+		return texts
+	}
+	line := oneLineSkeleton(e)
+	texts[tierSkeleton] = line
+
+	signature := strings.TrimSuffix(e.Skeleton, "\n")
+	if signature == "" {
+		signature = line
+		if e.DocComment != "" {
+			signature = e.DocComment + "\n" + line
+		}
+	}
+	if signature != line {
+		texts[tierSignature] = signature
+	}
+
+	if body := strings.TrimSpace(e.BodyText); body != "" {
+		full := strings.TrimSuffix(line, " { ... }") + " " + body
+		if e.DocComment != "" {
+			full = e.DocComment + "\n" + full
+		}
+		texts[tierFull] = full
+	}
+	return texts
+}
+
+// oneLineSkeleton returns the first line of the entity's skeleton after its
+// doc comment, closing an opened block: the skeleton BuildSkeleton stores
+// at scan time, or one made from the signature
+func oneLineSkeleton(e *store.Entity) string {
+	skeleton := strings.TrimPrefix(e.Skeleton, e.DocComment)
+	skeleton = strings.TrimLeft(skeleton, "\n")
+	if skeleton == "" {
+		return strings.TrimSpace(e.EntityType + " " + e.Name + e.Signature)
+	}
+	line, rest, multiline := strings.Cut(skeleton, "\n")
+	if multiline && strings.TrimSpace(rest) != "" && strings.HasSuffix(line, "{") {
+		line += " ... }"
+	}
+	return line
+}
+
+// packedEntry is the output an entity adds at a tier, which its tokens
+// are counted on
+func packedEntry(r *RelevantEntity, tier, code string) string {
+	return fmt.Sprintf("  %s:\n    type: %s\n    location: %s\n    relevance: medium\n    reason: %s\n    tier: %s\n    code: |\n      %s\n",
+		r.Name, r.Type, r.Location, r.Reason, tier, strings.ReplaceAll(code, "\n", "\n      "))
+}
@@ -0,0 +1,154 @@
+package context
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
+)
+
+func TestPackTiers(t *testing.T) {
+	item := func(relevance float64, skeleton, signature, full int) packItem {
+		return packItem{relevance: relevance, cost: [tierCount]int{0, skeleton, signature, full}}
+	}
+	tests := []struct {
+		name   string
+		items  []packItem
+		budget int
+		want   []int
+	}{
+		{
+			name:   "everything fits in full",
+			items:  []packItem{item(1, 10, 20, 50), item(0.5, 10, 20, 50)},
+			budget: 100,
+			want:   []int{tierFull, tierFull},
+		},
+		{
+			name:   "skeletons for all before bodies",
+			items:  []packItem{item(1, 10, 20, 200), item(0.8, 10, 20, 200), item(0.6, 10, 20, 200)},
+			budget: 100,
+			want:   []int{tierSignature, tierSignature, tierSignature},
+		},
+		{
+			name:   "most relevant body first",
+			items:  []packItem{item(1, 10, 20, 60), item(0.3, 10, 20, 60)},
+			budget: 90,
+			want:   []int{tierFull, tierSignature},
+		},
+		{
+			name:   "missing tiers are skipped",
+			items:  []packItem{item(1, 10, 0, 30), item(1, 10, 25, 0)},
+			budget: 60,
+			want:   []int{tierFull, tierSignature},
+		},
+		{
+			name:   "too small for anything",
+			items:  []packItem{item(1, 10, 20, 50)},
+			budget: 5,
+			want:   []int{tierNone},
+		},
+		{
+			name:   "tier already provided costs nothing",
+			items:  []packItem{{relevance: 1, cost: [tierCount]int{0, 10, 20, 50}, provided: tierSignature}, item(0.5, 10, 20, 50)},
+			budget: 30,
+			want:   []int{tierSignature, tierSignature},
+		},
+		{
+			name:   "upgrade from a provided tier sends the whole tier",
+			items:  []packItem{{relevance: 1, cost: [tierCount]int{0, 10, 20, 50}, provided: tierSignature}},
+			budget: 50,
+			want:   []int{tierFull},
+		},
+		{
+			name:   "pinned item in full first",
+			items:  []packItem{item(1, 10, 20, 50), {relevance: 0.1, cost: [tierCount]int{0, 10, 20, 50}, pinned: true}},
+			budget: 70,
+			want:   []int{tierSignature, tierFull},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := packTiers(tt.items, tt.budget)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("packTiers = %v, want %v", got, tt.want)
+			}
+			used := 0
+			for i, tier := range got {
+				used += tt.items[i].spend(tier)
+			}
+			if used > tt.budget {
+				t.Errorf("used %d tokens of %d", used, tt.budget)
+			}
+		})
+	}
+}
+
+func TestUpgradeLadder(t *testing.T) {
+	// A signature costing barely more than the body is not worth a step
+	it := packItem{relevance: 1, cost: [tierCount]int{0, 10, 45, 50}}
+	want := [][2]int{{tierNone, tierSkeleton}, {tierSkeleton, tierFull}}
+	if got := upgradeLadder(it); !reflect.DeepEqual(got, want) {
+		t.Errorf("upgradeLadder = %v, want %v", got, want)
+	}
+}
+
+func TestTierTexts(t *testing.T) {
+	e := &store.Entity{
+		Name:       "Add",
+		EntityType: "function",
+		Signature:  "(a, b int) int",
+		DocComment: "// Add sums a and b",
+		Skeleton:   "// Add sums a and b\nfunc Add(a, b int) int {\n\t// ...\n}",
+		BodyText:   "{\n\treturn a + b\n}",
+	}
+	got := tierTexts(e)
+	want := [tierCount]string{
+		"",
+		"func Add(a, b int) int { ... }",
+		"// Add sums a and b\nfunc Add(a, b int) int {\n\t// ...\n}",
+		"// Add sums a and b\nfunc Add(a, b int) int {\n\treturn a + b\n}",
+	}
+	if got != want {
+		t.Errorf("tierTexts =\n%q\nwant\n%q", got, want)
+	}
+
+	// Without a doc comment or body only the skeleton remains
+	bare := tierTexts(&store.Entity{Name: "x", EntityType: "var", Skeleton: "var x int"})
+	if bare != [tierCount]string{"", "var x int", "", ""} {
+		t.Errorf("tierTexts of a bare var = %q", bare)
+	}
+}
+
+func TestPack(t *testing.T) {
+	s, g, _ := loginFixture(t)
+	sc := NewSmartContext(s, g, SmartContextOptions{
+		Budget:          1000,
+		DisableSemantic: true,
+		Tokenizer:       tokens.Heuristic{},
+		Pack:            true,
+		Expand:          []string{"Other3"},
+	})
+	login, _ := s.GetEntity("login")
+	login.Skeleton = "func HandleLogin() {\n\t// ...\n}"
+	login.BodyText = "{\n\tcheckCredentials()\n}"
+	relevant := []*RelevantEntity{{Entity: login, ID: login.ID, Name: login.Name, Relevance: 1}}
+
+	packed, excluded, provided := sc.pack(relevant)
+	if len(excluded) != 0 || len(provided) != 0 {
+		t.Errorf("excluded = %v, provided = %v", excluded, provided)
+	}
+	tiers := make(map[string]string)
+	for _, r := range packed {
+		tiers[r.Name] = r.Tier
+		if r.Code == "" || r.Tokens == 0 {
+			t.Errorf("%s packed without code or tokens", r.Name)
+		}
+	}
+	if tiers["HandleLogin"] != TierFull {
+		t.Errorf("HandleLogin tier = %q, want full", tiers["HandleLogin"])
+	}
+	if _, ok := tiers["Other3"]; !ok {
+		t.Errorf("expanded entity outside the ranking not packed: %v", tiers)
+	}
+}
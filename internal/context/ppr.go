@@ -0,0 +1,256 @@
+package context
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/metrics"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// Ranking strategies for smart context expansion.
+const (
+	// RankPPR ranks entities by personalized PageRank seeded on the entry points.
+	RankPPR = "ppr"
+	// RankHops expands breadth-first by hop count from the entry points.
+	RankHops = "hops"
+)
+
+// pprEdgeWeights weights the dependency types the random walk follows:
+// calls tie code to a task more closely than shared types or imports.
+var pprEdgeWeights = map[string]float64{
+	"calls":      1.0,
+	"implements": 0.8,
+	"extends":    0.8,
+	"uses_type":  0.6,
+	"references": 0.4,
+	"imports":    0.2,
+}
+
+// pprReverseWeight scales the edges from an entry point back to its
+// callers. Only entry points link back: reverse edges everywhere would let
+// the walk pile up at utilities with many unrelated callers.
+const pprReverseWeight = 0.5
+
+// pprMinShare drops entities whose score is below this share of the best
+// non-entry score: the walk reaches them only by accident.
+const pprMinShare = 0.001
+
+// buildWeightedGraph builds the weighted dependency graph the random walk
+// runs on, with reverse edges from the seeds to their dependents.
+func buildWeightedGraph(s store.Store, seeds map[string]float64) (map[string]map[string]float64, error) {
+	deps, err := s.GetAllDependencies()
+	if err != nil {
+		return nil, err
+	}
+	weighted := make(map[string]map[string]float64)
+	link := func(from, to string, w float64) {
+		if weighted[from] == nil {
+			weighted[from] = make(map[string]float64)
+		}
+		weighted[from][to] += w
+	}
+	for _, dep := range deps {
+		w, ok := pprEdgeWeights[dep.DepType]
+		if !ok || dep.FromID == dep.ToID {
+			continue
+		}
+		link(dep.FromID, dep.ToID, w)
+		if seeds[dep.ToID] > 0 {
+			link(dep.ToID, dep.FromID, w*pprReverseWeight)
+		}
+	}
+	return weighted, nil
+}
+
+// hopDistances returns the undirected hop count from the nearest entry point
+// to every entity reachable from one.
+func hopDistances(g *graph.Graph, entryPoints []*EntryPoint) map[string]int {
+	hops := make(map[string]int)
+	var queue []string
+	for _, ep := range entryPoints {
+		if _, ok := hops[ep.ID]; !ok {
+			hops[ep.ID] = 0
+			queue = append(queue, ep.ID)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, neighbors := range [][]string{g.Successors(id), g.Predecessors(id)} {
+			for _, next := range neighbors {
+				if _, ok := hops[next]; !ok {
+					hops[next] = hops[id] + 1
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return hops
+}
+
+// rankByPPR fills the budget with the entities a random walk from the entry
+// points visits most. Entry points restart the walk in proportion to their
+// relevance, so the ranking favors code close to the task over globally
+// important utilities. The walk follows dependencies and, from the entry
+// points only, dependents.
+func (sc *SmartContext) rankByPPR(entryPoints []*EntryPoint, intent *Intent) ([]*RelevantEntity, []*ExcludedEntity, error) {
+	seeds := make(map[string]float64, len(entryPoints))
+	isEntry := make(map[string]bool, len(entryPoints))
+	for _, ep := range entryPoints {
+		seeds[ep.ID] += max(ep.Relevance, 0.01)
+		isEntry[ep.ID] = true
+	}
+	weighted, err := buildWeightedGraph(sc.store, seeds)
+	if err != nil {
+		return nil, nil, err
+	}
+	scores := metrics.ComputePersonalizedPageRank(weighted, seeds, metrics.DefaultPageRankConfig())
+	hops := hopDistances(sc.graph, entryPoints)
+
+	var relevant []*RelevantEntity
+	var excluded []*ExcludedEntity
+	tokensUsed := 0
+
+	for _, ep := range entryPoints {
+		if containsID(relevant, ep.ID) {
+			continue
+		}
+		tokens := sc.estimateTokens(ep.Entity, ep.IsKeystone)
+		if tokensUsed+tokens > sc.rankBudget() {
+			excluded = append(excluded, &ExcludedEntity{Name: ep.Name, Reason: "Over budget"})
+			continue
+		}
+		tokensUsed += tokens
+		relevant = append(relevant, &RelevantEntity{
+			Entity:     ep.Entity,
+			ID:         ep.ID,
+			Name:       ep.Name,
+			Type:       ep.Type,
+			Location:   ep.Location,
+			Hop:        0,
+			Relevance:  ep.Relevance,
+			Reason:     ep.Reason,
+			PageRank:   ep.PageRank,
+			IsKeystone: ep.IsKeystone,
+			PPR:        scores[ep.ID],
+			Tokens:     tokens,
+		})
+	}
+
+	// Rank the rest by score, boosted when the name matches the task
+	type candidate struct {
+		id    string
+		score float64
+	}
+	var candidates []candidate
+	best := 0.0
+	for id, s := range scores {
+		if isEntry[id] {
+			continue
+		}
+		candidates = append(candidates, candidate{id, s})
+		best = max(best, s)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].id < candidates[j].id
+	})
+
+	minTokens := sc.estimateTokens(&store.Entity{}, false)
+	var ranked []*RelevantEntity
+	for _, c := range candidates {
+		if sc.rankBudget()-tokensUsed < minTokens || c.score < best*pprMinShare {
+			break
+		}
+		entity, err := sc.store.GetEntity(c.id)
+		if err != nil || entity == nil || entity.EntityType == "import" {
+			continue
+		}
+		if shouldExclude(entity) {
+			excluded = append(excluded, &ExcludedEntity{Name: entity.Name, Reason: "Test/mock code"})
+			continue
+		}
+
+		pageRank, isKeystone := 0.0, false
+		if m, _ := sc.store.GetMetrics(c.id); m != nil {
+			pageRank = m.PageRank
+			isKeystone = m.PageRank >= 0.15 || m.InDegree >= 10
+		}
+		tokens := sc.estimateTokens(entity, isKeystone)
+		if tokensUsed+tokens > sc.rankBudget() {
+			excluded = append(excluded, &ExcludedEntity{Name: entity.Name, Reason: "Over budget"})
+			continue
+		}
+		tokensUsed += tokens
+
+		ranked = append(ranked, &RelevantEntity{
+			Entity:     entity,
+			ID:         entity.ID,
+			Name:       entity.Name,
+			Type:       entity.EntityType,
+			Location:   formatLocation(entity),
+			Hop:        hops[c.id],
+			Relevance:  c.score / best * nameBoost(entity.Name, intent),
+			Reason:     sc.pprReason(c.id, hops[c.id], entryPoints),
+			PageRank:   pageRank,
+			IsKeystone: isKeystone,
+			PPR:        c.score,
+			Tokens:     tokens,
+		})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Relevance > ranked[j].Relevance })
+
+	return append(relevant, ranked...), excluded, nil
+}
+
+// nameBoost is the relevance multiplier for a name that matches the task's
+// keywords, as in hop-based expansion
+func nameBoost(name string, intent *Intent) float64 {
+	boost := 1.0
+	nameLower := strings.ToLower(name)
+	for _, kw := range intent.IdentifierKeywords {
+		if strings.EqualFold(name, kw) {
+			boost *= 2.5
+			break
+		} else if strings.Contains(nameLower, kw) {
+			boost *= 1.8
+			break
+		}
+	}
+	for _, kw := range intent.Keywords {
+		if strings.Contains(nameLower, kw) {
+			boost *= 1.2
+			break
+		}
+	}
+	return boost
+}
+
+// pprReason explains how the walk reached an entity
+func (sc *SmartContext) pprReason(id string, hop int, entryPoints []*EntryPoint) string {
+	if hop == 1 {
+		for _, ep := range entryPoints {
+			for _, s := range sc.graph.Successors(ep.ID) {
+				if s == id {
+					return "Called by entry point"
+				}
+			}
+		}
+		return "Calls entry point"
+	}
+	return "Random walk from entry points (" + itoa(hop) + " hops)"
+}
+
+// containsID reports whether an entity is already in the list
+func containsID(entities []*RelevantEntity, id string) bool {
+	for _, e := range entities {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package context
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// loginFixture is a login handler calling task code and a logging utility
+// that twenty unrelated functions also call
+func loginFixture(t *testing.T) (*store.MemoryStore, *graph.Graph, []*EntryPoint) {
+	t.Helper()
+	s := store.NewMemoryStore()
+	add := func(id, name, path string) {
+		t.Helper()
+		if err := s.CreateEntity(&store.Entity{ID: id, Name: name, EntityType: "function", FilePath: path, LineStart: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dep := func(from, to, typ string) {
+		t.Helper()
+		if err := s.CreateDependency(&store.Dependency{FromID: from, ToID: to, DepType: typ}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("login", "HandleLogin", "auth/login.go")
+	add("route", "LoginRoute", "auth/routes.go")
+	add("check", "checkCredentials", "auth/check.go")
+	add("hash", "compareHash", "auth/hash.go")
+	add("logf", "logf", "util/log.go")
+	dep("route", "login", "calls")
+	dep("login", "check", "calls")
+	dep("login", "logf", "calls")
+	dep("check", "hash", "calls")
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("other%d", i)
+		add(id, fmt.Sprintf("Other%d", i), "other/other.go")
+		dep(id, "logf", "calls")
+	}
+	if err := s.SaveMetrics(&store.Metrics{EntityID: "logf", PageRank: 0.3, InDegree: 21}); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := graph.BuildFromStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, _ := s.GetEntity("login")
+	entryPoints := []*EntryPoint{{Entity: e, ID: e.ID, Name: e.Name, Type: e.EntityType, Relevance: 1}}
+	return s, g, entryPoints
+}
+
+func TestRankByPPR(t *testing.T) {
+	s, g, entryPoints := loginFixture(t)
+	sc := NewSmartContext(s, g, SmartContextOptions{Budget: 10000, DisableSemantic: true})
+	intent := ExtractIntent("fix login")
+
+	relevant, _, err := sc.rankByPPR(entryPoints, intent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := make(map[string]int)
+	ppr := make(map[string]float64)
+	for i, r := range relevant {
+		pos[r.Name] = i
+		ppr[r.Name] = r.PPR
+		if r.PPR <= 0 {
+			t.Errorf("%s has no PPR score", r.Name)
+		}
+	}
+	for _, name := range []string{"HandleLogin", "LoginRoute", "checkCredentials", "compareHash", "logf"} {
+		if _, ok := pos[name]; !ok {
+			t.Errorf("%s missing from %v", name, pos)
+		}
+	}
+	if _, ok := pos["Other0"]; ok {
+		t.Errorf("unrelated caller of logf included: %v", pos)
+	}
+	if pos["HandleLogin"] != 0 {
+		t.Errorf("entry point should come first: %v", pos)
+	}
+	if ppr["logf"] > ppr["checkCredentials"] {
+		t.Errorf("global utility logf scored above task code checkCredentials: %v", ppr)
+	}
+	if pos["compareHash"] < pos["checkCredentials"] {
+		t.Errorf("two hops ranked above one: %v", pos)
+	}
+	for _, r := range relevant {
+		if r.Name == "compareHash" && r.Hop != 2 {
+			t.Errorf("compareHash hop = %d, want 2", r.Hop)
+		}
+	}
+
+	// Hop-based expansion puts the keystone utility ahead of task code
+	hops, _ := sc.traceFlow(entryPoints, intent)
+	if hops[1].Name != "logf" {
+		t.Errorf("hops ranking = %s first after the entry point, want logf", hops[1].Name)
+	}
+}
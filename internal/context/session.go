@@ -0,0 +1,249 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/anthropics/cx/internal/store"
+)
+
+// SessionsDir is the directory under .cx that holds context sessions.
+const SessionsDir = "sessions"
+
+// Delta of an entity delivered in a session.
+const (
+	DeltaNew     = "new"     // not delivered before
+	DeltaChanged = "changed" // delivered before, body changed since
+)
+
+// sessionIDPattern limits session IDs to names safe as file names
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// Session records the entities delivered to an agent across context calls,
+// so that later calls send only what is new or changed. Sessions persist in
+// .cx/sessions/<id>.json.
+type Session struct {
+	ID        string               `json:"id"`
+	Created   time.Time            `json:"created"`
+	Updated   time.Time            `json:"updated"`
+	Calls     int                  `json:"calls"`
+	Delivered map[string]*Delivery `json:"delivered"` // by entity ID
+
+	path string
+}
+
+// Delivery is an entity as it was last delivered in a session.
+type Delivery struct {
+	Name     string `json:"name"`
+	BodyHash string `json:"body_hash"`
+	Tier     string `json:"tier,omitempty"`  // packed tier, empty for plain entries
+	Call     int    `json:"call"`            // call that delivered it
+	Stale    bool   `json:"stale,omitempty"` // a rescan changed its body
+}
+
+// OpenSession loads the session id from cxDir, or starts it if it does not
+// exist yet.
+func OpenSession(cxDir, id string) (*Session, error) {
+	if !sessionIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid session id %q: use letters, digits, '.', '_' and '-'", id)
+	}
+	path := filepath.Join(cxDir, SessionsDir, id+".json")
+	s, err := loadSession(path)
+	if errors.Is(err, os.ErrNotExist) {
+		now := time.Now().UTC()
+		return &Session{ID: id, Created: now, Updated: now, Delivered: make(map[string]*Delivery), path: path}, nil
+	}
+	return s, err
+}
+
+// loadSession reads a session file
+func loadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("read session %s: %w", filepath.Base(path), err)
+	}
+	if s.Delivered == nil {
+		s.Delivered = make(map[string]*Delivery)
+	}
+	s.path = path
+	return &s, nil
+}
+
+// Save writes the session atomically.
+func (s *Session) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create sessions directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("save session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Reset forgets every delivery, for an agent that lost its context.
+func (s *Session) Reset() {
+	s.Delivered = make(map[string]*Delivery)
+}
+
+// delta returns how an entity stands in the session: DeltaNew, DeltaChanged,
+// or "" when it was delivered with its current body at tier or above
+func (s *Session) delta(r *RelevantEntity, tier string) string {
+	d, ok := s.Delivered[r.ID]
+	if !ok {
+		return DeltaNew
+	}
+	if d.Stale || r.Entity == nil || d.BodyHash != r.Entity.BodyHash {
+		return DeltaChanged
+	}
+	if tierRank(tier) > tierRank(d.Tier) {
+		return DeltaNew // more detail than was delivered
+	}
+	return ""
+}
+
+// record marks the entities as delivered in this call
+func (s *Session) record(relevant []*RelevantEntity) {
+	s.Calls++
+	s.Updated = time.Now().UTC()
+	for _, r := range relevant {
+		d := &Delivery{Name: r.Name, Tier: r.Tier, Call: s.Calls}
+		if r.Entity != nil {
+			d.BodyHash = r.Entity.BodyHash
+		}
+		if old, ok := s.Delivered[r.ID]; ok && !old.Stale && old.BodyHash == d.BodyHash && tierRank(old.Tier) > tierRank(d.Tier) {
+			d.Tier = old.Tier
+		}
+		s.Delivered[r.ID] = d
+	}
+}
+
+// tierRank orders tiers by detail. A plain entry, with no tier, stands for
+// the signature it lists.
+func tierRank(tier string) int {
+	switch tier {
+	case TierFull:
+		return tierFull
+	case TierSignature, "":
+		return tierSignature
+	case TierSkeleton:
+		return tierSkeleton
+	}
+	return tierNone
+}
+
+// withhold removes the entities already provided in the session from
+// relevant, unless they are asked for with Expand, and marks the others
+// with their delta. It returns the IDs of the entities withheld.
+func (sc *SmartContext) withhold(relevant []*RelevantEntity) (kept []*RelevantEntity, provided []string) {
+	expand := make(map[string]bool, len(sc.options.Expand))
+	for _, name := range sc.options.Expand {
+		expand[name] = true
+	}
+	for _, r := range relevant {
+		delta := sc.options.Session.delta(r, "")
+		if delta == "" && !expand[r.Name] && !expand[r.ID] {
+			provided = append(provided, r.ID)
+			continue
+		}
+		r.Delta = delta
+		kept = append(kept, r)
+	}
+	return kept, provided
+}
+
+// providedTier returns the most detailed tier the session delivered r at
+// with its current body, or tierNone
+func (sc *SmartContext) providedTier(r *RelevantEntity) int {
+	if sc.options.Session == nil {
+		return tierNone
+	}
+	for t := tierFull; t > tierNone; t-- {
+		if sc.options.Session.delta(r, tierNames[t]) == "" {
+			return t
+		}
+	}
+	return tierNone
+}
+
+// trimToBudget keeps the leading entities that fit the budget, for ranking
+// done over the wider candidate budget of a session
+func (sc *SmartContext) trimToBudget(relevant []*RelevantEntity) ([]*RelevantEntity, []*ExcludedEntity) {
+	var excluded []*ExcludedEntity
+	used := 0
+	for i, r := range relevant {
+		if used+r.Tokens > sc.options.Budget {
+			for _, over := range relevant[i:] {
+				excluded = append(excluded, &ExcludedEntity{Name: over.Name, Reason: "Over budget"})
+			}
+			return relevant[:i], excluded
+		}
+		used += r.Tokens
+	}
+	return relevant, nil
+}
+
+// InvalidateSessions marks the deliveries in every session under cxDir
+// whose entity's body_hash changed in a rescan as stale, and drops those of
+// entities that no longer exist. It returns the number of deliveries
+// invalidated.
+func InvalidateSessions(cxDir string, s store.Store) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(cxDir, SessionsDir, "*.json"))
+	if err != nil || len(paths) == 0 {
+		return 0, err
+	}
+	sort.Strings(paths)
+
+	invalidated := 0
+	for _, path := range paths {
+		sess, err := loadSession(path)
+		if err != nil {
+			return invalidated, err
+		}
+		changed := false
+		for id, d := range sess.Delivered {
+			if d.Stale {
+				continue
+			}
+			e, err := s.GetEntity(id)
+			switch {
+			case err != nil || e == nil || e.Status == "archived":
+				delete(sess.Delivered, id)
+			case e.BodyHash != d.BodyHash:
+				d.Stale = true
+			default:
+				continue
+			}
+			changed = true
+			invalidated++
+		}
+		if changed {
+			if err := sess.Save(); err != nil {
+				return invalidated, err
+			}
+		}
+	}
+	return invalidated, nil
+}
@@ -0,0 +1,188 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
+)
+
+func TestOpenSession(t *testing.T) {
+	dir := t.TempDir()
+	for _, id := range []string{"", "../escape", "a/b", ".hidden"} {
+		if _, err := OpenSession(dir, id); err == nil {
+			t.Errorf("OpenSession(%q) accepted an unsafe id", id)
+		}
+	}
+
+	sess, err := OpenSession(dir, "agent-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess.record([]*RelevantEntity{{ID: "e1", Name: "Foo", Entity: &store.Entity{BodyHash: "aaaa"}}})
+	if err := sess.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SessionsDir, "agent-1.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := OpenSession(dir, "agent-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Calls != 1 || again.Delivered["e1"] == nil || again.Delivered["e1"].BodyHash != "aaaa" {
+		t.Errorf("reopened session = %+v", again)
+	}
+}
+
+func TestSessionDelta(t *testing.T) {
+	sess := &Session{Delivered: map[string]*Delivery{
+		"same":    {BodyHash: "h1", Tier: TierSignature},
+		"edited":  {BodyHash: "h1"},
+		"stale":   {BodyHash: "h1", Stale: true},
+		"skimmed": {BodyHash: "h1", Tier: TierSkeleton},
+	}}
+	entity := func(id, hash string) *RelevantEntity {
+		return &RelevantEntity{ID: id, Entity: &store.Entity{BodyHash: hash}}
+	}
+	tests := []struct {
+		r    *RelevantEntity
+		tier string
+		want string
+	}{
+		{entity("same", "h1"), "", ""},
+		{entity("same", "h1"), TierSkeleton, ""},
+		{entity("same", "h1"), TierFull, DeltaNew},
+		{entity("edited", "h2"), "", DeltaChanged},
+		{entity("stale", "h1"), "", DeltaChanged},
+		{entity("skimmed", "h1"), TierSignature, DeltaNew},
+		{entity("unseen", "h1"), "", DeltaNew},
+	}
+	for _, tt := range tests {
+		if got := sess.delta(tt.r, tt.tier); got != tt.want {
+			t.Errorf("delta(%s at %q) = %q, want %q", tt.r.ID, tt.tier, got, tt.want)
+		}
+	}
+}
+
+func TestAssembleWithSession(t *testing.T) {
+	s, g, _ := loginFixture(t)
+	sess, err := OpenSession(t.TempDir(), "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assemble := func() *SmartContextResult {
+		t.Helper()
+		sc := NewSmartContext(s, g, SmartContextOptions{
+			TaskDescription: "fix HandleLogin",
+			Budget:          4000,
+			DisableSemantic: true,
+			Tokenizer:       tokens.Heuristic{},
+			Session:         sess,
+		})
+		result, err := sc.Assemble()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	first := assemble()
+	if len(first.Relevant) == 0 || len(first.AlreadyProvided) != 0 {
+		t.Fatalf("first call: %d relevant, provided %v", len(first.Relevant), first.AlreadyProvided)
+	}
+	for _, r := range first.Relevant {
+		if r.Delta != DeltaNew {
+			t.Errorf("first call: %s delta = %q, want new", r.Name, r.Delta)
+		}
+	}
+
+	second := assemble()
+	if len(second.Relevant) != 0 {
+		t.Errorf("second call resent %d entities", len(second.Relevant))
+	}
+	var firstIDs []string
+	for _, r := range first.Relevant {
+		firstIDs = append(firstIDs, r.ID)
+	}
+	if !reflect.DeepEqual(second.AlreadyProvided, firstIDs) {
+		t.Errorf("second call provided %v, want the IDs %v", second.AlreadyProvided, firstIDs)
+	}
+
+	// A rescan that changes a delivered body invalidates it
+	check, _ := s.GetEntity("check")
+	check.BodyHash = "changed1"
+	if err := s.UpdateEntity(check); err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.Save(); err != nil {
+		t.Fatal(err)
+	}
+	n, err := InvalidateSessions(filepath.Dir(filepath.Dir(sess.path)), s)
+	if err != nil || n != 1 {
+		t.Fatalf("InvalidateSessions = %d, %v; want 1", n, err)
+	}
+	if sess, err = OpenSession(filepath.Dir(filepath.Dir(sess.path)), "s1"); err != nil {
+		t.Fatal(err)
+	}
+	third := assemble()
+	var sent []string
+	for _, r := range third.Relevant {
+		sent = append(sent, r.Name+":"+r.Delta)
+	}
+	if !reflect.DeepEqual(sent, []string{"checkCredentials:changed"}) {
+		t.Errorf("third call sent %v, want only checkCredentials changed", sent)
+	}
+}
+
+func TestAssembleWithSessionPack(t *testing.T) {
+	s, g, _ := loginFixture(t)
+	sess, err := OpenSession(t.TempDir(), "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assemble := func() *SmartContextResult {
+		t.Helper()
+		sc := NewSmartContext(s, g, SmartContextOptions{
+			TaskDescription: "fix HandleLogin",
+			Budget:          100,
+			DisableSemantic: true,
+			Tokenizer:       tokens.Heuristic{},
+			Pack:            true,
+			Session:         sess,
+		})
+		result, err := sc.Assemble()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	first := assemble()
+	if len(first.Relevant) == 0 {
+		t.Fatal("first call packed nothing")
+	}
+	// The second call spends the budget on entities not provided yet
+	second := assemble()
+	provided := make(map[string]bool)
+	for _, id := range second.AlreadyProvided {
+		provided[id] = true
+	}
+	for _, r := range first.Relevant {
+		if !provided[r.ID] {
+			t.Errorf("%s not listed as provided: %v", r.ID, second.AlreadyProvided)
+		}
+	}
+	if len(second.Relevant) == 0 || second.TokensUsed > 100 {
+		t.Errorf("second call packed %d entities in %d tokens", len(second.Relevant), second.TokensUsed)
+	}
+	for _, r := range second.Relevant {
+		if provided[r.ID] || r.Delta != DeltaNew {
+			t.Errorf("second call sent %s again (delta %q)", r.ID, r.Delta)
+		}
+	}
+}
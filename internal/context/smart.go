@@ -5,6 +5,7 @@ package context
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/anthropics/cx/internal/embeddings"
 	"github.com/anthropics/cx/internal/graph"
 	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
 )
 
 // Intent represents the extracted intent from a task description.
@@ -71,7 +73,11 @@ type RelevantEntity struct {
 	Reason     string        `yaml:"reason" json:"reason"`
 	PageRank   float64       `yaml:"pagerank,omitempty" json:"pagerank,omitempty"`
 	IsKeystone bool          `yaml:"is_keystone,omitempty" json:"is_keystone,omitempty"`
+	PPR        float64       `yaml:"ppr,omitempty" json:"ppr,omitempty"` // personalized PageRank from the entry points
 	Tokens     int           `yaml:"tokens" json:"tokens"`
+	Tier       string        `yaml:"tier,omitempty" json:"tier,omitempty"`   // detail packed with Pack: full, signature or skeleton
+	Code       string        `yaml:"code,omitempty" json:"code,omitempty"`   // source at Tier
+	Delta      string        `yaml:"delta,omitempty" json:"delta,omitempty"` // new or changed in the Session
 }
 
 // ExcludedEntity represents an entity excluded from context.
@@ -83,11 +89,15 @@ type ExcludedEntity struct {
 // SmartContextResult contains the assembled smart context.
 type SmartContextResult struct {
 	Intent           *Intent           `yaml:"intent" json:"intent"`
+	Rank             string            `yaml:"rank" json:"rank"`
 	EntryPoints      []*EntryPoint     `yaml:"entry_points" json:"entry_points"`
 	Relevant         []*RelevantEntity `yaml:"relevant_entities" json:"relevant_entities"`
 	Excluded         []*ExcludedEntity `yaml:"excluded,omitempty" json:"excluded,omitempty"`
 	TokensUsed       int               `yaml:"tokens_used" json:"tokens_used"`
 	TokensBudget     int               `yaml:"tokens_budget" json:"tokens_budget"`
+	Tokenizer        string            `yaml:"tokenizer" json:"tokenizer"`
+	Session          string            `yaml:"session,omitempty" json:"session,omitempty"`
+	AlreadyProvided  []string          `yaml:"already_provided,omitempty" json:"already_provided,omitempty"` // IDs delivered earlier in the session, unchanged
 	Warnings         []string          `yaml:"warnings,omitempty" json:"warnings,omitempty"`
 	HybridSearchUsed bool              `yaml:"hybrid_search_used,omitempty" json:"hybrid_search_used,omitempty"`
 }
@@ -96,11 +106,26 @@ type SmartContextResult struct {
 type SmartContextOptions struct {
 	TaskDescription string  // Natural language task description
 	Budget          int     // Token budget (default: 4000)
-	Depth           int     // Max hops from entry points (default: 2)
+	Depth           int     // Max hops from entry points with RankHops (default: 2)
+	Rank            string  // Expansion strategy: RankPPR (default) or RankHops
 	SearchLimit     int     // Max search results for entry points (default: 20)
 	KeystoneBoost   float64 // Multiplier for keystone entities (default: 2.0)
 	TagBoost        float64 // Multiplier for tagged entities (default: 1.5)
 	DisableSemantic bool    // Disable semantic search even if embeddings exist
+
+	// Tokenizer counts tokens against Budget (default: the configured
+	// tokenizer, see tokens.Default)
+	Tokenizer tokens.Counter
+
+	// Pack fills the budget with source at progressive detail: bodies for
+	// the most relevant entities, signatures and skeletons for the rest
+	Pack bool
+	// Expand names entities (by ID or name) to pack with their full body
+	Expand []string
+
+	// Session withholds the entities it already delivered unchanged and
+	// records the ones sent; the caller saves it
+	Session *Session
 }
 
 // HybridWeights configures the hybrid scoring algorithm.
@@ -138,12 +163,13 @@ func DefaultSmartContextOptions() SmartContextOptions {
 		SearchLimit:   20,
 		KeystoneBoost: 2.0,
 		TagBoost:      1.5,
+		Rank:          RankPPR,
 	}
 }
 
 // SmartContext assembles intent-aware context for a task description.
 type SmartContext struct {
-	store         *store.Store
+	store         store.Store
 	graph         *graph.Graph
 	options       SmartContextOptions
 	embedder      embeddings.Embedder
@@ -151,7 +177,7 @@ type SmartContext struct {
 }
 
 // NewSmartContext creates a new smart context assembler.
-func NewSmartContext(s *store.Store, g *graph.Graph, opts SmartContextOptions) *SmartContext {
+func NewSmartContext(s store.Store, g *graph.Graph, opts SmartContextOptions) *SmartContext {
 	if opts.Budget <= 0 {
 		opts.Budget = 4000
 	}
@@ -167,6 +193,12 @@ func NewSmartContext(s *store.Store, g *graph.Graph, opts SmartContextOptions) *
 	if opts.TagBoost <= 0 {
 		opts.TagBoost = 1.5
 	}
+	if opts.Rank == "" {
+		opts.Rank = RankPPR
+	}
+	if opts.Tokenizer == nil {
+		opts.Tokenizer = tokens.Default()
+	}
 
 	sc := &SmartContext{
 		store:   s,
@@ -196,7 +228,7 @@ func (sc *SmartContext) initEmbedder() error {
 	if sc.embedder != nil {
 		return nil
 	}
-	emb, err := embeddings.NewLocalEmbedder()
+	emb, err := embeddings.NewFromConfig()
 	if err != nil {
 		return err
 	}
@@ -215,7 +247,9 @@ func (sc *SmartContext) Close() {
 // Assemble builds the smart context for the configured task.
 func (sc *SmartContext) Assemble() (*SmartContextResult, error) {
 	result := &SmartContextResult{
+		Rank:         sc.options.Rank,
 		TokensBudget: sc.options.Budget,
+		Tokenizer:    sc.options.Tokenizer.Name(),
 	}
 
 	// Step 1: Extract intent from task description
@@ -267,8 +301,32 @@ func (sc *SmartContext) Assemble() (*SmartContextResult, error) {
 		return result, nil
 	}
 
-	// Step 3: Trace flow from entry points
-	relevantEntities, excluded := sc.traceFlow(entryPoints, intent)
+	// Step 3: Expand from entry points, by random walk or by hops
+	var relevantEntities []*RelevantEntity
+	var excluded []*ExcludedEntity
+	if sc.options.Rank == RankHops {
+		relevantEntities, excluded = sc.traceFlow(entryPoints, intent)
+	} else {
+		relevantEntities, excluded, err = sc.rankByPPR(entryPoints, intent)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Step 4: Fit to the budget what the session did not already provide
+	sess := sc.options.Session
+	var over []*ExcludedEntity
+	switch {
+	case sc.options.Pack:
+		relevantEntities, over, result.AlreadyProvided = sc.pack(relevantEntities)
+	case sess != nil:
+		relevantEntities, result.AlreadyProvided = sc.withhold(relevantEntities)
+		relevantEntities, over = sc.trimToBudget(relevantEntities)
+	}
+	excluded = append(excluded, over...)
+	if sess != nil {
+		sess.record(relevantEntities)
+		result.Session = sess.ID
+	}
 	result.Relevant = relevantEntities
 	result.Excluded = excluded
 
@@ -768,8 +826,8 @@ func (sc *SmartContext) traceFlow(entryPoints []*EntryPoint, intent *Intent) ([]
 			continue
 		}
 
-		tokens := estimateTokens(ep.Entity, ep.IsKeystone)
-		if tokensUsed+tokens > sc.options.Budget {
+		tokens := sc.estimateTokens(ep.Entity, ep.IsKeystone)
+		if tokensUsed+tokens > sc.rankBudget() {
 			excluded = append(excluded, &ExcludedEntity{
 				Name:   ep.Name,
 				Reason: "Over budget",
@@ -828,7 +886,7 @@ func (sc *SmartContext) traceFlow(entryPoints []*EntryPoint, intent *Intent) ([]
 	}
 
 	// Process queue
-	for len(queue) > 0 && tokensUsed < sc.options.Budget {
+	for len(queue) > 0 && tokensUsed < sc.rankBudget() {
 		item := queue[0]
 		queue = queue[1:]
 
@@ -864,9 +922,9 @@ func (sc *SmartContext) traceFlow(entryPoints []*EntryPoint, intent *Intent) ([]
 			isKeystone = metrics.PageRank >= 0.15 || metrics.InDegree >= 10
 		}
 
-		tokens := estimateTokens(entity, isKeystone)
+		tokens := sc.estimateTokens(entity, isKeystone)
 
-		if tokensUsed+tokens > sc.options.Budget {
+		if tokensUsed+tokens > sc.rankBudget() {
 			excluded = append(excluded, &ExcludedEntity{
 				Name:   entity.Name,
 				Reason: "Over budget",
@@ -893,27 +951,8 @@ func (sc *SmartContext) traceFlow(entryPoints []*EntryPoint, intent *Intent) ([]
 			}
 		}
 
-		// Boost entities that match identifier-like keywords (strong boost for names)
-		entityNameLower := strings.ToLower(entity.Name)
-		for _, kw := range intent.IdentifierKeywords {
-			if strings.EqualFold(entity.Name, kw) {
-				// Exact name match - very strong boost
-				relevance *= 2.5
-				break
-			} else if strings.Contains(entityNameLower, kw) {
-				// Partial name match - strong boost
-				relevance *= 1.8
-				break
-			}
-		}
-
-		// Boost entities that match generic keywords (name only, no body text)
-		for _, kw := range intent.Keywords {
-			if strings.Contains(entityNameLower, kw) {
-				relevance *= 1.2
-				break
-			}
-		}
+		// Boost entities whose names match the task's keywords
+		relevance *= nameBoost(entity.Name, intent)
 
 		reason := "Flow trace from " + item.fromID
 		if item.hop == 1 {
@@ -975,6 +1014,19 @@ func (sc *SmartContext) traceFlow(entryPoints []*EntryPoint, intent *Intent) ([]
 	return relevant, excluded
 }
 
+// candidateFactor widens the budget ranking fills when the candidates are
+// cut down afterwards: packing fits more skeletons than plain entries, and
+// a session withholds what it already provided.
+const candidateFactor = 3
+
+// rankBudget is the token budget ranking fills with candidates
+func (sc *SmartContext) rankBudget() int {
+	if sc.options.Pack || (sc.options.Session != nil && len(sc.options.Session.Delivered) > 0) {
+		return sc.options.Budget * candidateFactor
+	}
+	return sc.options.Budget
+}
+
 // formatLocation formats an entity location string.
 func formatLocation(e *store.Entity) string {
 	if e.LineEnd != nil && *e.LineEnd != e.LineStart {
@@ -1006,28 +1058,24 @@ func itoa(i int) string {
 	return string(buf[pos:])
 }
 
-// estimateTokens estimates the token count for an entity.
-func estimateTokens(e *store.Entity, isKeystone bool) int {
+// estimateTokens counts the tokens an entity costs in context: its entry
+// with type, location and reason, and its signature. Keystones add their
+// doc comment, which agents read before changing them.
+func (sc *SmartContext) estimateTokens(e *store.Entity, isKeystone bool) int {
 	if e == nil {
 		return 0
 	}
-
-	// Base tokens for metadata
-	base := 50
-
-	// Name tokens
-	nameTokens := len(strings.Fields(e.Name)) + 2
-
-	// Signature tokens
-	sigTokens := len(strings.Fields(e.Signature))
-
-	// Keystones get more detail
-	if isKeystone {
-		// Include more context for important entities
-		return base + nameTokens + sigTokens + 100
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  %s:\n    type: %s\n    location: %s\n    relevance: medium\n    reason: Called by entry point\n",
+		e.Name, e.EntityType, formatLocation(e))
+	if e.Signature != "" {
+		fmt.Fprintf(&sb, "    signature: %s\n", e.Signature)
 	}
-
-	return base + nameTokens + sigTokens + 30
+	if isKeystone && e.DocComment != "" {
+		sb.WriteString(e.DocComment)
+		sb.WriteByte('\n')
+	}
+	return sc.options.Tokenizer.Count(sb.String())
 }
 
 // shouldExclude checks if an entity should be excluded from context.
@@ -1,8 +1,12 @@
 package context
 
 import (
+	"math"
 	"strings"
 	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
 )
 
 func TestExtractIntent(t *testing.T) {
@@ -230,72 +234,45 @@ func TestDetectActionPattern(t *testing.T) {
 }
 
 func TestEstimateTokens(t *testing.T) {
+	lineEnd := 42
+	e := &store.Entity{
+		Name:       "handleRequest",
+		EntityType: "function",
+		FilePath:   "internal/server/handler.go",
+		LineStart:  10,
+		LineEnd:    &lineEnd,
+		Signature:  "(ctx context.Context, req *Request) (*Response, error)",
+		DocComment: "// handleRequest routes a request to its handler and writes the response,\n// retrying once when the backend is unavailable.",
+	}
+	bpe, err := tokens.Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cl100k_base counts of the entry, and the heuristic's within 10%
 	tests := []struct {
-		name       string
-		entityName string
-		signature  string
-		isKeystone bool
-		wantMin    int
-		wantMax    int
+		counter         tokens.Counter
+		plain, keystone int
+		tolerance       float64
 	}{
-		{
-			name:       "simple function",
-			entityName: "main",
-			signature:  "()",
-			isKeystone: false,
-			wantMin:    50,
-			wantMax:    150,
-		},
-		{
-			name:       "keystone function",
-			entityName: "handleRequest",
-			signature:  "(ctx context.Context, req *Request) (*Response, error)",
-			isKeystone: true,
-			wantMin:    100,
-			wantMax:    300,
-		},
-		{
-			name:       "complex signature",
-			entityName: "ProcessUserAuthentication",
-			signature:  "(email string, password string, opts ...Option) (*User, *Token, error)",
-			isKeystone: false,
-			wantMin:    50,
-			wantMax:    200,
-		},
+		{bpe, 52, 76, 0},
+		{tokens.Heuristic{}, 52, 76, 0.1},
 	}
-
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Mock entity
-			entity := &mockEntity{
-				name:      tt.entityName,
-				signature: tt.signature,
-			}
-
-			tokens := estimateTokensFromEntity(entity.name, entity.signature, tt.isKeystone)
-
-			if tokens < tt.wantMin || tokens > tt.wantMax {
-				t.Errorf("tokens = %d, want between %d and %d", tokens, tt.wantMin, tt.wantMax)
-			}
-		})
-	}
-}
-
-type mockEntity struct {
-	name      string
-	signature string
-}
-
-// estimateTokensFromEntity is a helper that mirrors the logic in estimateTokens
-func estimateTokensFromEntity(name, signature string, isKeystone bool) int {
-	base := 50
-	nameTokens := len(name)/4 + 2
-	sigTokens := len(signature) / 4
-
-	if isKeystone {
-		return base + nameTokens + sigTokens + 100
+		sc := NewSmartContext(store.NewMemoryStore(), nil, SmartContextOptions{DisableSemantic: true, Tokenizer: tt.counter})
+		within := func(got, want int) bool {
+			return math.Abs(float64(got-want)) <= tt.tolerance*float64(want)
+		}
+		if plain := sc.estimateTokens(e, false); !within(plain, tt.plain) {
+			t.Errorf("%s: entry with signature = %d tokens, want %d", tt.counter.Name(), plain, tt.plain)
+		}
+		if keystone := sc.estimateTokens(e, true); !within(keystone, tt.keystone) {
+			t.Errorf("%s: keystone with doc comment = %d tokens, want %d", tt.counter.Name(), keystone, tt.keystone)
+		}
+		if sc.estimateTokens(nil, false) != 0 {
+			t.Errorf("%s: nil entity has tokens", tt.counter.Name())
+		}
 	}
-	return base + nameTokens + sigTokens + 30
 }
 
 func TestShouldExclude(t *testing.T) {
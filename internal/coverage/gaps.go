@@ -118,7 +118,7 @@ func DefaultGapsReportOptions() GapsReportOptions {
 
 // GenerateGapsReport analyzes coverage data and generates a prioritized report
 // of coverage gaps based on entity importance.
-func GenerateGapsReport(s *store.Store, cfg *config.Config, opts GapsReportOptions) (*GapsReport, error) {
+func GenerateGapsReport(s store.Store, cfg *config.Config, opts GapsReportOptions) (*GapsReport, error) {
 	if cfg == nil {
 		cfg = config.DefaultConfig()
 	}
@@ -119,7 +119,7 @@ type CoverageRecommendation struct {
 }
 
 // AnalyzeImpact analyzes test coverage impact for a file or entity.
-func AnalyzeImpact(s *store.Store, target string, cfg *config.Config) (*ImpactAnalysis, error) {
+func AnalyzeImpact(s store.Store, target string, cfg *config.Config) (*ImpactAnalysis, error) {
 	// Determine if target is a file or entity
 	var entities []*store.Entity
 	var targetType string
@@ -219,7 +219,7 @@ func AnalyzeImpact(s *store.Store, target string, cfg *config.Config) (*ImpactAn
 }
 
 // analyzeEntityImpact analyzes coverage for a single entity.
-func analyzeEntityImpact(s *store.Store, e *store.Entity, cfg *config.Config) (*EntityImpact, error) {
+func analyzeEntityImpact(s store.Store, e *store.Entity, cfg *config.Config) (*EntityImpact, error) {
 	// Get coverage data
 	cov, err := GetEntityCoverage(s, e.ID)
 	coveragePercent := 0.0
@@ -273,7 +273,7 @@ func analyzeEntityImpact(s *store.Store, e *store.Entity, cfg *config.Config) (*
 }
 
 // GetCoveringTests returns all tests that cover a specific entity.
-func GetCoveringTests(s *store.Store, entityID string) ([]CoveringTest, error) {
+func GetCoveringTests(s store.Store, entityID string) ([]CoveringTest, error) {
 	tests, err := GetTestsForEntity(s, entityID)
 	if err != nil {
 		return nil, fmt.Errorf("get tests for entity %s: %w", entityID, err)
@@ -291,7 +291,7 @@ func GetCoveringTests(s *store.Store, entityID string) ([]CoveringTest, error) {
 }
 
 // GetUncoveredEntities returns all entities with 0% coverage, grouped by file.
-func GetUncoveredEntities(s *store.Store, cfg *config.Config) (*UncoveredEntitiesByFile, error) {
+func GetUncoveredEntities(s store.Store, cfg *config.Config) (*UncoveredEntitiesByFile, error) {
 	// Get all active entities
 	entities, err := s.QueryEntities(store.EntityFilter{Status: "active"})
 	if err != nil {
@@ -353,7 +353,7 @@ func GetUncoveredEntities(s *store.Store, cfg *config.Config) (*UncoveredEntitie
 }
 
 // GenerateCoverageRecommendations generates prioritized recommendations for what to test next.
-func GenerateCoverageRecommendations(s *store.Store, cfg *config.Config, limit int) ([]CoverageRecommendation, error) {
+func GenerateCoverageRecommendations(s store.Store, cfg *config.Config, limit int) ([]CoverageRecommendation, error) {
 	// Get all active entities
 	entities, err := s.QueryEntities(store.EntityFilter{Status: "active"})
 	if err != nil {
@@ -10,7 +10,7 @@ import (
 )
 
 // setupTestStore creates a test store with sample entities and coverage data
-func setupTestStoreForImpact(t *testing.T) (*store.Store, func()) {
+func setupTestStoreForImpact(t *testing.T) (*store.SQLStore, func()) {
 	t.Helper()
 
 	// Create temp directory
@@ -42,7 +42,7 @@ func setupTestStoreForImpact(t *testing.T) (*store.Store, func()) {
 }
 
 // createTestEntities creates sample entities for testing
-func createTestEntities(t *testing.T, s *store.Store) {
+func createTestEntities(t *testing.T, s *store.SQLStore) {
 	t.Helper()
 
 	entities := []*store.Entity{
@@ -86,7 +86,7 @@ func createTestEntities(t *testing.T, s *store.Store) {
 }
 
 // createTestCoverage creates sample coverage data
-func createTestCoverage(t *testing.T, s *store.Store) {
+func createTestCoverage(t *testing.T, s *store.SQLStore) {
 	t.Helper()
 
 	coverages := []EntityCoverage{
@@ -116,7 +116,7 @@ func createTestCoverage(t *testing.T, s *store.Store) {
 }
 
 // createTestMappings creates test-to-entity mappings
-func createTestMappings(t *testing.T, s *store.Store) {
+func createTestMappings(t *testing.T, s *store.SQLStore) {
 	t.Helper()
 
 	mappings := []struct {
@@ -141,7 +141,7 @@ func createTestMappings(t *testing.T, s *store.Store) {
 }
 
 // createTestMetrics creates metrics for entities
-func createTestMetrics(t *testing.T, s *store.Store) {
+func createTestMetrics(t *testing.T, s *store.SQLStore) {
 	t.Helper()
 
 	metrics := []struct {
@@ -1,32 +1,26 @@
 package coverage
 
 import (
-	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/anthropics/cx/internal/store"
 )
 
 // EntityCoverage represents coverage information for a single entity.
-type EntityCoverage struct {
-	EntityID        string    `json:"entity_id"`
-	CoveragePercent float64   `json:"coverage_percent"`
-	CoveredLines    []int     `json:"covered_lines"`
-	UncoveredLines  []int     `json:"uncovered_lines"`
-	LastRun         time.Time `json:"last_run"`
-}
+type EntityCoverage = store.EntityCoverage
 
 // Mapper maps coverage blocks to code entities in the cx store.
 type Mapper struct {
-	store        *store.Store
+	store        store.Store
 	coverageData *CoverageData
 	basePath     string // Base path for normalizing file paths
 }
 
 // NewMapper creates a new coverage mapper.
-func NewMapper(s *store.Store, coverageData *CoverageData, basePath string) *Mapper {
+func NewMapper(s store.Store, coverageData *CoverageData, basePath string) *Mapper {
 	return &Mapper{
 		store:        s,
 		coverageData: coverageData,
@@ -234,115 +228,56 @@ func min(a, b int) int {
 }
 
 // StoreCoverage persists entity coverage data to the database.
-func StoreCoverage(s *store.Store, coverages []EntityCoverage) error {
-	for _, cov := range coverages {
-		// Marshal line arrays to JSON
-		coveredJSON, err := json.Marshal(cov.CoveredLines)
-		if err != nil {
-			return fmt.Errorf("marshal covered lines for %s: %w", cov.EntityID, err)
-		}
-
-		uncoveredJSON, err := json.Marshal(cov.UncoveredLines)
-		if err != nil {
-			return fmt.Errorf("marshal uncovered lines for %s: %w", cov.EntityID, err)
-		}
-
-		// Insert or replace coverage data
-		_, err = s.DB().Exec(`
-			REPLACE INTO entity_coverage (
-				entity_id, coverage_percent, covered_lines, uncovered_lines, last_run
-			) VALUES (?, ?, ?, ?, ?)
-		`, cov.EntityID, cov.CoveragePercent, string(coveredJSON), string(uncoveredJSON), cov.LastRun.Format(time.RFC3339))
-
-		if err != nil {
-			return fmt.Errorf("store coverage for %s: %w", cov.EntityID, err)
+func StoreCoverage(s store.Store, coverages []EntityCoverage) error {
+	for i := range coverages {
+		if err := s.SaveCoverage(&coverages[i]); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
 // GetEntityCoverage retrieves coverage data for a specific entity.
-func GetEntityCoverage(s *store.Store, entityID string) (*EntityCoverage, error) {
-	var cov EntityCoverage
-	var coveredJSON, uncoveredJSON, lastRunStr string
-
-	err := s.DB().QueryRow(`
-		SELECT entity_id, coverage_percent, covered_lines, uncovered_lines, last_run
-		FROM entity_coverage
-		WHERE entity_id = ?
-	`, entityID).Scan(&cov.EntityID, &cov.CoveragePercent, &coveredJSON, &uncoveredJSON, &lastRunStr)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Unmarshal JSON arrays
-	if err := json.Unmarshal([]byte(coveredJSON), &cov.CoveredLines); err != nil {
-		return nil, fmt.Errorf("unmarshal covered lines: %w", err)
-	}
-
-	if err := json.Unmarshal([]byte(uncoveredJSON), &cov.UncoveredLines); err != nil {
-		return nil, fmt.Errorf("unmarshal uncovered lines: %w", err)
-	}
-
-	// Parse timestamp
-	lastRun, err := time.Parse(time.RFC3339, lastRunStr)
-	if err != nil {
-		return nil, fmt.Errorf("parse last_run: %w", err)
-	}
-	cov.LastRun = lastRun
-
-	return &cov, nil
+func GetEntityCoverage(s store.Store, entityID string) (*EntityCoverage, error) {
+	return s.GetCoverage(entityID)
 }
 
 // GetCoverageStats returns overall coverage statistics.
-func GetCoverageStats(s *store.Store) (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
-
-	// Count total entities with coverage
-	var totalWithCoverage int
-	err := s.DB().QueryRow(`
-		SELECT COUNT(*) FROM entity_coverage
-	`).Scan(&totalWithCoverage)
+func GetCoverageStats(s store.Store) (map[string]interface{}, error) {
+	coverages, err := s.GetAllCoverage()
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate average coverage
-	var avgCoverage float64
-	err = s.DB().QueryRow(`
-		SELECT AVG(coverage_percent) FROM entity_coverage
-	`).Scan(&avgCoverage)
-	if err != nil {
-		return nil, err
-	}
+	stats := make(map[string]interface{})
 
-	// Count entities by coverage ranges
+	var total float64
 	var fullyCovered, partiallyCovered, notCovered int
-	err = s.DB().QueryRow(`
-		SELECT
-			SUM(CASE WHEN coverage_percent = 100 THEN 1 ELSE 0 END) as fully_covered,
-			SUM(CASE WHEN coverage_percent > 0 AND coverage_percent < 100 THEN 1 ELSE 0 END) as partially_covered,
-			SUM(CASE WHEN coverage_percent = 0 THEN 1 ELSE 0 END) as not_covered
-		FROM entity_coverage
-	`).Scan(&fullyCovered, &partiallyCovered, &notCovered)
-	if err != nil {
-		return nil, err
+	var lastRun time.Time
+	for _, cov := range coverages {
+		total += cov.CoveragePercent
+		switch {
+		case cov.CoveragePercent == 100:
+			fullyCovered++
+		case cov.CoveragePercent > 0:
+			partiallyCovered++
+		default:
+			notCovered++
+		}
+		if cov.LastRun.After(lastRun) {
+			lastRun = cov.LastRun
+		}
 	}
 
-	// Get last run time
-	var lastRunStr string
-	err = s.DB().QueryRow(`
-		SELECT MAX(last_run) FROM entity_coverage
-	`).Scan(&lastRunStr)
-	if err == nil && lastRunStr != "" {
-		if lastRun, err := time.Parse(time.RFC3339, lastRunStr); err == nil {
-			stats["last_run"] = lastRun.Format(time.RFC3339)
-		}
+	var avgCoverage float64
+	if len(coverages) > 0 {
+		avgCoverage = total / float64(len(coverages))
+	}
+	if !lastRun.IsZero() {
+		stats["last_run"] = lastRun.Format(time.RFC3339)
 	}
 
-	stats["total_entities_with_coverage"] = totalWithCoverage
+	stats["total_entities_with_coverage"] = len(coverages)
 	stats["average_coverage_percent"] = avgCoverage
 	stats["fully_covered"] = fullyCovered
 	stats["partially_covered"] = partiallyCovered
@@ -358,46 +293,30 @@ type TestInfo struct {
 }
 
 // GetTestsForEntity retrieves all tests that cover a specific entity.
-func GetTestsForEntity(s *store.Store, entityID string) ([]TestInfo, error) {
-	rows, err := s.DB().Query(`
-		SELECT test_file, test_name
-		FROM test_entity_map
-		WHERE entity_id = ?
-		ORDER BY test_file, test_name
-	`, entityID)
+func GetTestsForEntity(s store.Store, entityID string) ([]TestInfo, error) {
+	mappings, err := s.GetTestMappings(store.TestMappingFilter{EntityID: entityID})
 	if err != nil {
-		return nil, fmt.Errorf("query test_entity_map: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
 	var tests []TestInfo
-	for rows.Next() {
-		var test TestInfo
-		if err := rows.Scan(&test.TestFile, &test.TestName); err != nil {
-			return nil, fmt.Errorf("scan test row: %w", err)
-		}
-		tests = append(tests, test)
+	for _, m := range mappings {
+		tests = append(tests, TestInfo{TestFile: m.TestFile, TestName: m.TestName})
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate test rows: %w", err)
-	}
-
 	return tests, nil
 }
 
 // StoreTestEntityMappings populates the test_entity_map table from per-test GOCOVERDIR data.
 // For each test in the GOCOVERDIRData, it maps the coverage to entities and stores which
 // tests cover which entities. Returns the number of mappings stored.
-func StoreTestEntityMappings(s *store.Store, gocoverData *GOCOVERDIRData, basePath string) (int, error) {
+func StoreTestEntityMappings(s store.Store, gocoverData *GOCOVERDIRData, basePath string) (int, error) {
 	if !gocoverData.HasPerTestAttribution() {
 		return 0, nil
 	}
 
 	// Clear existing test mappings (we're replacing them)
-	_, err := s.DB().Exec(`DELETE FROM test_entity_map`)
-	if err != nil {
-		return 0, fmt.Errorf("clear test_entity_map: %w", err)
+	if err := s.ClearTestMappings(); err != nil {
+		return 0, err
 	}
 
 	totalMappings := 0
@@ -423,12 +342,8 @@ func StoreTestEntityMappings(s *store.Store, gocoverData *GOCOVERDIRData, basePa
 			testFile := deriveTestFile(testName)
 
 			// Insert the mapping
-			_, err := s.DB().Exec(`
-				INSERT IGNORE INTO test_entity_map (test_file, test_name, entity_id)
-				VALUES (?, ?, ?)
-			`, testFile, testName, cov.EntityID)
-			if err != nil {
-				return totalMappings, fmt.Errorf("insert test mapping for %s -> %s: %w", testName, cov.EntityID, err)
+			if err := s.AddTestMapping(testFile, testName, cov.EntityID); err != nil {
+				return totalMappings, err
 			}
 			totalMappings++
 		}
@@ -447,36 +362,27 @@ func deriveTestFile(testName string) string {
 }
 
 // GetTestMappingStats returns statistics about test-to-entity mappings.
-func GetTestMappingStats(s *store.Store) (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
-
-	// Count total mappings
-	var totalMappings int
-	err := s.DB().QueryRow(`SELECT COUNT(*) FROM test_entity_map`).Scan(&totalMappings)
+func GetTestMappingStats(s store.Store) (map[string]interface{}, error) {
+	mappings, err := s.GetTestMappings(store.TestMappingFilter{})
 	if err != nil {
 		return nil, fmt.Errorf("count mappings: %w", err)
 	}
-	stats["total_mappings"] = totalMappings
 
-	// Count unique tests
-	var uniqueTests int
-	err = s.DB().QueryRow(`SELECT COUNT(DISTINCT test_name) FROM test_entity_map`).Scan(&uniqueTests)
-	if err != nil {
-		return nil, fmt.Errorf("count tests: %w", err)
+	tests := make(map[string]bool)
+	entities := make(map[string]bool)
+	for _, m := range mappings {
+		tests[m.TestName] = true
+		entities[m.EntityID] = true
 	}
-	stats["unique_tests"] = uniqueTests
 
-	// Count unique entities covered
-	var uniqueEntities int
-	err = s.DB().QueryRow(`SELECT COUNT(DISTINCT entity_id) FROM test_entity_map`).Scan(&uniqueEntities)
-	if err != nil {
-		return nil, fmt.Errorf("count entities: %w", err)
-	}
-	stats["unique_entities_covered"] = uniqueEntities
+	stats := make(map[string]interface{})
+	stats["total_mappings"] = len(mappings)
+	stats["unique_tests"] = len(tests)
+	stats["unique_entities_covered"] = len(entities)
 
 	// Average entities per test
-	if uniqueTests > 0 {
-		stats["avg_entities_per_test"] = float64(totalMappings) / float64(uniqueTests)
+	if len(tests) > 0 {
+		stats["avg_entities_per_test"] = float64(len(mappings)) / float64(len(tests))
 	}
 
 	return stats, nil
@@ -484,32 +390,36 @@ func GetTestMappingStats(s *store.Store) (map[string]interface{}, error) {
 
 // LookupTestFileFromDiscovery tries to find the actual test file for a test name
 // by querying discovered test entities in the database.
-func LookupTestFileFromDiscovery(s *store.Store, testName string) (string, bool) {
-	var filePath string
-	err := s.DB().QueryRow(`
-		SELECT file_path FROM entities
-		WHERE name = ? AND entity_type = 'function' AND status = 'active'
-		AND file_path LIKE '%_test.go'
-		LIMIT 1
-	`, testName).Scan(&filePath)
-
+func LookupTestFileFromDiscovery(s store.Store, testName string) (string, bool) {
+	entities, err := s.QueryEntities(store.EntityFilter{
+		EntityType:     "function",
+		Status:         "active",
+		Name:           testName,
+		FilePathSuffix: "_test.go",
+	})
 	if err != nil {
 		return "", false
 	}
-	return filePath, true
+
+	// The Name filter is a substring match; only accept the exact test function
+	for _, e := range entities {
+		if e.Name == testName {
+			return e.FilePath, true
+		}
+	}
+	return "", false
 }
 
 // StoreTestEntityMappingsWithDiscovery is an enhanced version of StoreTestEntityMappings
 // that uses discovered test functions to get accurate test file paths.
-func StoreTestEntityMappingsWithDiscovery(s *store.Store, gocoverData *GOCOVERDIRData, basePath string) (int, error) {
+func StoreTestEntityMappingsWithDiscovery(s store.Store, gocoverData *GOCOVERDIRData, basePath string) (int, error) {
 	if !gocoverData.HasPerTestAttribution() {
 		return 0, nil
 	}
 
 	// Clear existing test mappings (we're replacing them)
-	_, err := s.DB().Exec(`DELETE FROM test_entity_map`)
-	if err != nil {
-		return 0, fmt.Errorf("clear test_entity_map: %w", err)
+	if err := s.ClearTestMappings(); err != nil {
+		return 0, err
 	}
 
 	totalMappings := 0
@@ -538,12 +448,8 @@ func StoreTestEntityMappingsWithDiscovery(s *store.Store, gocoverData *GOCOVERDI
 			}
 
 			// Insert the mapping
-			_, err := s.DB().Exec(`
-				INSERT IGNORE INTO test_entity_map (test_file, test_name, entity_id)
-				VALUES (?, ?, ?)
-			`, testFile, testName, cov.EntityID)
-			if err != nil {
-				return totalMappings, fmt.Errorf("insert test mapping for %s -> %s: %w", testName, cov.EntityID, err)
+			if err := s.AddTestMapping(testFile, testName, cov.EntityID); err != nil {
+				return totalMappings, err
 			}
 			totalMappings++
 		}
@@ -553,58 +459,33 @@ func StoreTestEntityMappingsWithDiscovery(s *store.Store, gocoverData *GOCOVERDI
 }
 
 // GetEntitiesForTest retrieves all entities covered by a specific test.
-func GetEntitiesForTest(s *store.Store, testName string) ([]string, error) {
-	rows, err := s.DB().Query(`
-		SELECT entity_id
-		FROM test_entity_map
-		WHERE test_name = ?
-		ORDER BY entity_id
-	`, testName)
+func GetEntitiesForTest(s store.Store, testName string) ([]string, error) {
+	mappings, err := s.GetTestMappings(store.TestMappingFilter{TestName: testName})
 	if err != nil {
-		return nil, fmt.Errorf("query test_entity_map: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
 	var entityIDs []string
-	for rows.Next() {
-		var entityID string
-		if err := rows.Scan(&entityID); err != nil {
-			return nil, fmt.Errorf("scan entity row: %w", err)
-		}
-		entityIDs = append(entityIDs, entityID)
+	for _, m := range mappings {
+		entityIDs = append(entityIDs, m.EntityID)
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate entity rows: %w", err)
-	}
-
+	sort.Strings(entityIDs)
 	return entityIDs, nil
 }
 
 // GetAllTestMappings retrieves all test→entity mappings from the database.
-func GetAllTestMappings(s *store.Store) (map[string][]string, error) {
-	rows, err := s.DB().Query(`
-		SELECT test_name, entity_id
-		FROM test_entity_map
-		ORDER BY test_name, entity_id
-	`)
+func GetAllTestMappings(s store.Store) (map[string][]string, error) {
+	mappings, err := s.GetTestMappings(store.TestMappingFilter{})
 	if err != nil {
-		return nil, fmt.Errorf("query test_entity_map: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	mappings := make(map[string][]string)
-	for rows.Next() {
-		var testName, entityID string
-		if err := rows.Scan(&testName, &entityID); err != nil {
-			return nil, fmt.Errorf("scan mapping row: %w", err)
-		}
-		mappings[testName] = append(mappings[testName], entityID)
+	result := make(map[string][]string)
+	for _, m := range mappings {
+		result[m.TestName] = append(result[m.TestName], m.EntityID)
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate mapping rows: %w", err)
+	for _, ids := range result {
+		sort.Strings(ids)
 	}
-
-	return mappings, nil
+	return result, nil
 }
@@ -79,7 +79,7 @@ type SuggestionSummary struct {
 }
 
 // GenerateSuggestions creates prioritized test suggestions combining coverage and importance.
-func GenerateSuggestions(s *store.Store, opts SuggestionOptions) (*SuggestionResult, error) {
+func GenerateSuggestions(s store.Store, opts SuggestionOptions) (*SuggestionResult, error) {
 	// Load config for thresholds
 	cfg, err := config.Load(".")
 	if err != nil {
@@ -222,7 +222,7 @@ func GenerateSuggestions(s *store.Store, opts SuggestionOptions) (*SuggestionRes
 }
 
 // generateSuggestionForEntity creates suggestions for a specific entity.
-func generateSuggestionForEntity(s *store.Store, cfg *config.Config, opts SuggestionOptions) (*SuggestionResult, error) {
+func generateSuggestionForEntity(s store.Store, cfg *config.Config, opts SuggestionOptions) (*SuggestionResult, error) {
 	// Get the entity
 	e, err := s.GetEntity(opts.EntityID)
 	if err != nil {
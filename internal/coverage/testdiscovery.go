@@ -16,12 +16,12 @@ import (
 
 // TestDiscovery handles scanning and discovering test functions in a codebase.
 type TestDiscovery struct {
-	store    *store.Store
+	store    store.Store
 	basePath string
 }
 
 // NewTestDiscovery creates a new test discovery instance.
-func NewTestDiscovery(s *store.Store, basePath string) *TestDiscovery {
+func NewTestDiscovery(s store.Store, basePath string) *TestDiscovery {
 	return &TestDiscovery{
 		store:    s,
 		basePath: basePath,
@@ -267,7 +267,7 @@ func (td *TestDiscovery) StoreDiscoveredTests(tests []DiscoveredTest) error {
 }
 
 // GetDiscoveredTests retrieves all discovered test functions from the database.
-func GetDiscoveredTests(s *store.Store, language string) ([]DiscoveredTest, error) {
+func GetDiscoveredTests(s store.Store, language string) ([]DiscoveredTest, error) {
 	// Query entities that are test functions
 	// Test functions are stored with Kind set to test type
 	filter := store.EntityFilter{
@@ -321,7 +321,7 @@ func GetDiscoveredTests(s *store.Store, language string) ([]DiscoveredTest, erro
 
 // MapTestToCoverage maps a discovered test to the entities it covers.
 // This uses per-test coverage data from GOCOVERDIR or per-test coverage.out files.
-func MapTestToCoverage(s *store.Store, testName string, coverageData *CoverageData, basePath string) ([]string, error) {
+func MapTestToCoverage(s store.Store, testName string, coverageData *CoverageData, basePath string) ([]string, error) {
 	// Create mapper for this test's coverage
 	mapper := NewMapper(s, coverageData, basePath)
 	entityCoverages, err := mapper.MapCoverageToEntities()
@@ -121,7 +121,7 @@ type Daemon struct {
 	config Config
 
 	// Core components
-	store       *store.Store
+	store       *store.SQLStore
 	graph       *graph.Graph
 	socket      *Socket
 	projectRoot string
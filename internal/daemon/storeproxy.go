@@ -14,7 +14,7 @@ import (
 // It abstracts whether access is via daemon or direct database.
 type StoreProvider struct {
 	// Store is the store instance (for direct mode)
-	store *store.Store
+	store *store.SQLStore
 
 	// Client is the daemon client (for daemon mode)
 	client *Client
@@ -106,7 +106,7 @@ func NewStoreProvider(opts StoreProviderOptions) (*StoreProvider, error) {
 
 // Store returns the underlying store instance.
 // Returns nil if using daemon mode.
-func (p *StoreProvider) Store() *store.Store {
+func (p *StoreProvider) Store() *store.SQLStore {
 	return p.store
 }
 
@@ -137,7 +137,7 @@ func (p *StoreProvider) CXDir() string {
 
 // GetDirectStore opens a direct store connection, bypassing the daemon.
 // Use this for operations that must run locally or when daemon is not suitable.
-func GetDirectStore(cxDir string) (*store.Store, error) {
+func GetDirectStore(cxDir string) (*store.SQLStore, error) {
 	if cxDir == "" {
 		var err error
 		cxDir, err = config.FindConfigDir(".")
@@ -184,7 +184,7 @@ func GetStoreWithOptions(opts StoreProviderOptions) (*StoreProvider, error) {
 
 // MustGetDirectStore opens a direct store or panics.
 // Use sparingly, prefer GetStore for graceful error handling.
-func MustGetDirectStore() *store.Store {
+func MustGetDirectStore() *store.SQLStore {
 	storeDB, err := GetDirectStore("")
 	if err != nil {
 		panic(err)
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is the author of one line of a file.
+type BlameLine struct {
+	Commit string
+	Author string
+	Email  string
+	Time   time.Time
+}
+
+// uncommittedEmail is the author email git blame reports for lines that are
+// not committed yet
+const uncommittedEmail = "not.committed.yet"
+
+// BlameFile returns the author of every line of the working tree version
+// of a file; index i holds line i+1. Whitespace-only changes are ignored.
+// Lines that are not committed yet have an empty Commit.
+func (gd *GitDiff) BlameFile(path string) ([]BlameLine, error) {
+	cmd := exec.Command("git", "blame", "--line-porcelain", "-w", "--", path)
+	cmd.Dir = gd.projectRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s failed: %w", path, err)
+	}
+	return parseLinePorcelain(string(out)), nil
+}
+
+// parseLinePorcelain parses git blame --line-porcelain output: a header
+// "<sha> <orig-line> <final-line> [<group-size>]" per line, then key-value
+// lines, then the content prefixed by a tab.
+func parseLinePorcelain(output string) []BlameLine {
+	var result []BlameLine
+	var cur BlameLine
+	final := 0
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			if final < 1 {
+				continue
+			}
+			for len(result) < final {
+				result = append(result, BlameLine{})
+			}
+			result[final-1] = cur
+			final = 0
+		case strings.HasPrefix(line, "author "):
+			cur.Author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			cur.Email = strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+			if cur.Email == uncommittedEmail {
+				cur.Commit, cur.Email = "", ""
+			}
+		case strings.HasPrefix(line, "author-time "):
+			if secs, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64); err == nil {
+				cur.Time = time.Unix(secs, 0).UTC()
+			}
+		default:
+			fields := strings.Fields(line)
+			if len(fields) >= 3 && len(fields[0]) >= 40 && isHex(fields[0]) {
+				n, err := strconv.Atoi(fields[2])
+				if err != nil {
+					continue
+				}
+				cur = BlameLine{Commit: fields[0]}
+				if strings.Trim(fields[0], "0") == "" {
+					cur.Commit = ""
+				}
+				final = n
+			}
+		}
+	}
+	return result
+}
+
+// isHex reports whether s consists of hexadecimal digits
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
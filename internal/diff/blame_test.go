@@ -0,0 +1,27 @@
+package diff
+
+import "testing"
+
+func TestParseLinePorcelain(t *testing.T) {
+	sha1 := "739905a82465a92f18cba1f93d1073736caa33e0"
+	output := sha1 + " 1 1 2\n" +
+		"author Ada\nauthor-mail <ada@example.com>\nauthor-time 1700000000\nsummary first\nfilename f.go\n" +
+		"\tpackage f\n" +
+		sha1 + " 2 2\n" +
+		"author Ada\nauthor-mail <ada@example.com>\nauthor-time 1700000000\nsummary first\nfilename f.go\n" +
+		"\t\n" +
+		"0000000000000000000000000000000000000000 3 3 1\n" +
+		"author Not Committed Yet\nauthor-mail <not.committed.yet>\nauthor-time 1700000100\nfilename f.go\n" +
+		"\tfunc F() {}\n"
+
+	lines := parseLinePorcelain(output)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if lines[1].Commit != sha1 || lines[1].Author != "Ada" || lines[1].Email != "ada@example.com" {
+		t.Errorf("line 2 = %+v", lines[1])
+	}
+	if lines[2].Commit != "" || lines[2].Email != "" {
+		t.Errorf("uncommitted line 3 = %+v, want no commit", lines[2])
+	}
+}
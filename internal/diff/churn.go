@@ -0,0 +1,221 @@
+package diff
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hunk is one changed region of a file in a commit, from a zero-context
+// unified diff. Old lines are in the parent, new lines in the commit. A
+// hunk with OldLines 0 inserts after OldStart; one with NewLines 0 deletes
+// after NewStart.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+}
+
+// CommitHunks is the diff of one commit.
+type CommitHunks struct {
+	Hash   string
+	Author string
+	Email  string
+	Time   time.Time
+	Files  map[string][]Hunk // hunks per file, in file order
+}
+
+// LineChange is a region changed by a commit, in line numbers of the
+// current version of the file.
+type LineChange struct {
+	Commit string
+	Author string
+	Email  string
+	Time   time.Time
+	Start  int // first line, 1-based
+	End    int // last line, inclusive
+	Lines  int // lines added or removed by the hunk
+}
+
+// fieldSeparator separates the commit header fields in CommitHunksSince
+const fieldSeparator = "\x1f"
+
+// CommitHunksSince returns the zero-context diff of every non-merge commit
+// since the given time, newest first. Renames are reported as a deletion
+// and an addition, so history stays attached to the paths it was made on.
+func (gd *GitDiff) CommitHunksSince(since time.Time) ([]CommitHunks, error) {
+	args := []string{"log", "--no-merges", "--no-renames", "--relative", "-p", "--unified=0",
+		"--format=%x1e%H%x1f%an%x1f%ae%x1f%at"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gd.projectRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	return parseUnifiedLog(string(out)), nil
+}
+
+// parseUnifiedLog parses git log -p --unified=0 output with the header
+// format used by CommitHunksSince.
+func parseUnifiedLog(output string) []CommitHunks {
+	var commits []CommitHunks
+	for _, chunk := range strings.Split(output, commitSeparator) {
+		lines := strings.Split(chunk, "\n")
+		header := strings.Split(lines[0], fieldSeparator)
+		if len(header) < 4 {
+			continue
+		}
+		c := CommitHunks{Hash: header[0], Author: header[1], Email: header[2], Files: make(map[string][]Hunk)}
+		if secs, err := strconv.ParseInt(strings.TrimSpace(header[3]), 10, 64); err == nil {
+			c.Time = time.Unix(secs, 0).UTC()
+		}
+
+		// File names come from the "--- " and "+++ " lines of a file's
+		// header, between "diff --git" and its first hunk. In hunks they
+		// are removed and added lines that begin with "-- " or "++ ".
+		file := ""
+		inHeader, sawOld := false, false
+		for _, line := range lines[1:] {
+			switch {
+			case strings.HasPrefix(line, "diff --git "):
+				file = ""
+				inHeader, sawOld = true, false
+			case inHeader && strings.HasPrefix(line, "--- "):
+				sawOld = true
+			case inHeader && sawOld && strings.HasPrefix(line, "+++ "):
+				file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+				if file == "/dev/null" {
+					file = ""
+				}
+			case strings.HasPrefix(line, "@@ "):
+				inHeader = false
+				if file == "" {
+					continue
+				}
+				if h, ok := parseHunkHeader(line); ok {
+					c.Files[file] = append(c.Files[file], h)
+				}
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// parseHunkHeader parses "@@ -a,b +c,d @@"; a missing count means 1
+func parseHunkHeader(line string) (Hunk, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return Hunk{}, false
+	}
+	parse := func(s string) (int, int, bool) {
+		start, count, found := strings.Cut(s[1:], ",")
+		n, err := strconv.Atoi(start)
+		if err != nil {
+			return 0, 0, false
+		}
+		c := 1
+		if found {
+			if c, err = strconv.Atoi(count); err != nil {
+				return 0, 0, false
+			}
+		}
+		return n, c, true
+	}
+	oldStart, oldLines, ok1 := parse(fields[1])
+	newStart, newLines, ok2 := parse(fields[2])
+	if !ok1 || !ok2 {
+		return Hunk{}, false
+	}
+	return Hunk{OldStart: oldStart, OldLines: oldLines, NewStart: newStart, NewLines: newLines}, true
+}
+
+// ProjectToHead maps the hunks of commits (newest first) onto line numbers of
+// the newest version of each file, following the shifts that later commits
+// introduced. Lines a later commit rewrote map onto the region that replaced
+// them. Returns the changes per file.
+func ProjectToHead(commits []CommitHunks) map[string][]LineChange {
+	result := make(map[string][]LineChange)
+	// later[file] holds the hunks of already visited (newer) commits, newest first
+	later := make(map[string][][]Hunk)
+	for _, c := range commits {
+		for file, hunks := range c.Files {
+			newer := later[file]
+			toHead := func(line int) int {
+				for i := len(newer) - 1; i >= 0; i-- {
+					line = mapLine(newer[i], line)
+				}
+				return line
+			}
+			for _, h := range hunks {
+				start, end := h.NewStart, h.NewStart+h.NewLines-1
+				if h.NewLines == 0 {
+					end = start
+				}
+				if start < 1 {
+					start = 1
+				}
+				if end < start {
+					end = start
+				}
+				start, end = toHead(start), toHead(end)
+				if end < start {
+					start, end = end, start
+				}
+				lines := h.NewLines
+				if h.OldLines > lines {
+					lines = h.OldLines
+				}
+				result[file] = append(result[file], LineChange{
+					Commit: c.Hash, Author: c.Author, Email: c.Email, Time: c.Time,
+					Start: start, End: end, Lines: lines,
+				})
+			}
+			sorted := append([]Hunk(nil), hunks...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i].OldStart < sorted[j].OldStart })
+			later[file] = append(later[file], sorted)
+		}
+	}
+	return result
+}
+
+// mapLine maps a line of a commit's parent onto the commit, given the
+// commit's hunks sorted by OldStart
+func mapLine(hunks []Hunk, line int) int {
+	delta := 0
+	for _, h := range hunks {
+		if h.OldLines == 0 {
+			// Pure insertion after OldStart
+			if line > h.OldStart {
+				delta += h.NewLines
+				continue
+			}
+			break
+		}
+		if line < h.OldStart {
+			break
+		}
+		if line < h.OldStart+h.OldLines {
+			// Rewritten or deleted: land on the replacing region
+			offset := line - h.OldStart
+			if offset >= h.NewLines {
+				offset = h.NewLines - 1
+			}
+			if offset < 0 {
+				offset = 0
+			}
+			mapped := h.NewStart + offset
+			if mapped < 1 {
+				mapped = 1
+			}
+			return mapped
+		}
+		delta += h.NewLines - h.OldLines
+	}
+	return line + delta
+}
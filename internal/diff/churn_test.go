@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUnifiedLog(t *testing.T) {
+	output := "\x1eabc123\x1fAda\x1fada@example.com\x1f1700000000\n\n" +
+		"diff --git a/api/users.go b/api/users.go\n" +
+		"index 1..2 100644\n" +
+		"--- a/api/users.go\n" +
+		"+++ b/api/users.go\n" +
+		"@@ -10,2 +10,3 @@ func CreateUser() {\n" +
+		"-old\n-old\n+new\n+new\n+new\n" +
+		"@@ -40 +41,0 @@\n" +
+		"-gone\n" +
+		"diff --git a/old.go b/old.go\n" +
+		"deleted file mode 100644\n" +
+		"--- a/old.go\n" +
+		"+++ /dev/null\n" +
+		"@@ -1,3 +0,0 @@\n" +
+		"-a\n-b\n-c\n"
+
+	commits := parseUnifiedLog(output)
+	if len(commits) != 1 {
+		t.Fatalf("got %d commits, want 1", len(commits))
+	}
+	c := commits[0]
+	if c.Hash != "abc123" || c.Author != "Ada" || c.Email != "ada@example.com" || !c.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("header = %+v", c)
+	}
+	hunks := c.Files["api/users.go"]
+	if len(hunks) != 2 {
+		t.Fatalf("api/users.go hunks = %+v, want 2", hunks)
+	}
+	if hunks[0] != (Hunk{OldStart: 10, OldLines: 2, NewStart: 10, NewLines: 3}) {
+		t.Errorf("first hunk = %+v", hunks[0])
+	}
+	if hunks[1] != (Hunk{OldStart: 40, OldLines: 1, NewStart: 41, NewLines: 0}) {
+		t.Errorf("second hunk = %+v", hunks[1])
+	}
+	if _, ok := c.Files["old.go"]; ok {
+		t.Errorf("deleted file should have no hunks: %+v", c.Files)
+	}
+}
+
+func TestParseUnifiedLogMarkerLines(t *testing.T) {
+	// Removed and added lines that begin with "-- " and "++ " look like
+	// file header lines
+	output := "\x1eabc123\x1fAda\x1fada@example.com\x1f1700000000\n\n" +
+		"diff --git a/schema.sql b/schema.sql\n" +
+		"--- a/schema.sql\n" +
+		"+++ b/schema.sql\n" +
+		"@@ -1 +1 @@\n" +
+		"--- old comment\n" +
+		"+++ b/other.sql\n" +
+		"@@ -9,0 +10 @@\n" +
+		"+x\n"
+
+	commits := parseUnifiedLog(output)
+	if len(commits) != 1 {
+		t.Fatalf("got %d commits, want 1", len(commits))
+	}
+	files := commits[0].Files
+	if len(files) != 1 || len(files["schema.sql"]) != 2 {
+		t.Errorf("files = %+v, want two hunks in schema.sql", files)
+	}
+}
+
+func TestProjectToHead(t *testing.T) {
+	// Oldest first: create the file, rewrite line 8, insert 3 lines after line 2
+	commits := []CommitHunks{
+		{Hash: "insert", Files: map[string][]Hunk{"f.go": {{OldStart: 2, OldLines: 0, NewStart: 3, NewLines: 3}}}},
+		{Hash: "rewrite", Files: map[string][]Hunk{"f.go": {{OldStart: 8, OldLines: 1, NewStart: 8, NewLines: 1}}}},
+		{Hash: "create", Files: map[string][]Hunk{"f.go": {{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 10}}}},
+	}
+
+	got := make(map[string]LineChange)
+	for _, c := range ProjectToHead(commits)["f.go"] {
+		got[c.Commit] = c
+	}
+	tests := []struct {
+		commit     string
+		start, end int
+		lines      int
+	}{
+		{"insert", 3, 5, 3},
+		{"rewrite", 11, 11, 1},
+		{"create", 1, 13, 10},
+	}
+	for _, tt := range tests {
+		c := got[tt.commit]
+		if c.Start != tt.start || c.End != tt.end || c.Lines != tt.lines {
+			t.Errorf("%s: lines %d-%d (%d changed), want %d-%d (%d)", tt.commit, c.Start, c.End, c.Lines, tt.start, tt.end, tt.lines)
+		}
+	}
+}
+
+func TestMapLine(t *testing.T) {
+	hunks := []Hunk{
+		{OldStart: 5, OldLines: 0, NewStart: 6, NewLines: 2},   // insert 2 after line 5
+		{OldStart: 10, OldLines: 3, NewStart: 12, NewLines: 1}, // rewrite 10-12 as one line
+		{OldStart: 20, OldLines: 2, NewStart: 19, NewLines: 0}, // delete 20-21
+	}
+	tests := []struct{ in, want int }{
+		{1, 1}, {5, 5}, {6, 8}, {9, 11}, {10, 12}, {12, 12}, {13, 13}, {19, 19}, {20, 19}, {22, 20},
+	}
+	for _, tt := range tests {
+		if got := mapLine(hunks, tt.in); got != tt.want {
+			t.Errorf("mapLine(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/anthropics/cx/internal/parser"
 	"github.com/anthropics/cx/internal/semdiff"
 	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
+	"gopkg.in/yaml.v3"
 )
 
 // DiffContextOptions configures diff-based context assembly.
@@ -29,6 +31,9 @@ type DiffContextOptions struct {
 	IncludeCallers bool
 	// IncludeCallees includes entities called by modified entities.
 	IncludeCallees bool
+	// Tokenizer counts the tokens of the result (default: the tokenizer
+	// configured in cfg).
+	Tokenizer tokens.Counter
 }
 
 // DefaultDiffContextOptions returns sensible defaults.
@@ -93,6 +98,8 @@ type DiffContextResult struct {
 	TokensUsed int `yaml:"tokens_used" json:"tokens_used"`
 	// TokensBudget is the configured budget.
 	TokensBudget int `yaml:"tokens_budget" json:"tokens_budget"`
+	// Tokenizer names the counter of TokensUsed.
+	Tokenizer string `yaml:"tokenizer" json:"tokenizer"`
 	// Warnings contains any warnings during analysis.
 	Warnings []string `yaml:"warnings,omitempty" json:"warnings,omitempty"`
 }
@@ -121,7 +128,7 @@ type DiffContextSummary struct {
 
 // DiffContext assembles context based on git diff.
 type DiffContext struct {
-	store       *store.Store
+	store       *store.SQLStore
 	graph       *graph.Graph
 	projectRoot string
 	cfg         *config.Config
@@ -129,13 +136,21 @@ type DiffContext struct {
 }
 
 // NewDiffContext creates a new diff-based context assembler.
-func NewDiffContext(s *store.Store, g *graph.Graph, projectRoot string, cfg *config.Config, opts DiffContextOptions) *DiffContext {
+func NewDiffContext(s *store.SQLStore, g *graph.Graph, projectRoot string, cfg *config.Config, opts DiffContextOptions) *DiffContext {
 	if opts.Budget <= 0 {
 		opts.Budget = 8000
 	}
 	if opts.Depth <= 0 {
 		opts.Depth = 2
 	}
+	if opts.Tokenizer == nil {
+		opts.Tokenizer = tokens.Heuristic{}
+		if cfg != nil {
+			if counter, err := tokens.New(cfg.Tokenizer); err == nil {
+				opts.Tokenizer = counter
+			}
+		}
+	}
 
 	return &DiffContext{
 		store:       s,
@@ -244,8 +259,12 @@ func (dc *DiffContext) Assemble() (*DiffContextResult, error) {
 		result.Summary.TotalCallersAffected = len(callers)
 	}
 
-	// Estimate tokens
-	result.TokensUsed = dc.estimateTokens(result)
+	// Count tokens
+	result.Tokenizer = dc.options.Tokenizer.Name()
+	result.TokensUsed = dc.countTokens(result)
+	if result.TokensUsed > dc.options.Budget {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Context uses %d tokens, over the budget of %d", result.TokensUsed, dc.options.Budget))
+	}
 
 	return result, nil
 }
@@ -550,22 +569,13 @@ func (dc *DiffContext) extractEntitiesFromContent(filePath string, content []byt
 	return entities, nil
 }
 
-// estimateTokens estimates the token count for the result.
-func (dc *DiffContext) estimateTokens(result *DiffContextResult) int {
-	tokens := 100 // Base overhead
-
-	// Each file change
-	tokens += len(result.ChangedFiles) * 20
-
-	// Each entity change
-	tokens += len(result.EntitiesModified) * 80
-	tokens += len(result.EntitiesAdded) * 50
-	tokens += len(result.EntitiesRemoved) * 50
-
-	// Each affected caller
-	tokens += len(result.CallersAffected) * 60
-
-	return tokens
+// countTokens counts the tokens of the result as it is output
+func (dc *DiffContext) countTokens(result *DiffContextResult) int {
+	data, err := yaml.Marshal(result)
+	if err != nil {
+		return 0
+	}
+	return dc.options.Tokenizer.Count(string(data))
 }
 
 // formatLocation formats an entity location.
@@ -0,0 +1,47 @@
+package diff
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// commitSeparator is what --format=%x1e prints at the start of each commit
+const commitSeparator = "\x1e"
+
+// CommitFileSets returns the files touched by each of the last maxCommits
+// non-merge commits, newest first, as paths relative to the project root.
+// Unlike the diff helpers it keeps every file, not just source files, so
+// hidden coupling to migrations, schemas or configs shows up.
+func (gd *GitDiff) CommitFileSets(maxCommits int) ([][]string, error) {
+	args := []string{"log", "--no-merges", "--name-only", "--relative", "--format=%x1e"}
+	if maxCommits > 0 {
+		args = append(args, "-n", strconv.Itoa(maxCommits))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gd.projectRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	return parseNameOnlyLog(string(out)), nil
+}
+
+// parseNameOnlyLog splits git log --name-only output into per-commit file
+// lists. Commits without files are dropped.
+func parseNameOnlyLog(output string) [][]string {
+	var sets [][]string
+	for _, chunk := range strings.Split(output, commitSeparator) {
+		var files []string
+		for _, line := range strings.Split(chunk, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				files = append(files, line)
+			}
+		}
+		if len(files) > 0 {
+			sets = append(sets, files)
+		}
+	}
+	return sets
+}
@@ -0,0 +1,19 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNameOnlyLog(t *testing.T) {
+	output := "\x1e\n\nsrc/handler.go\nmigrations/001.sql\n\x1e\n\x1e\n\nREADME.md\n"
+
+	got := parseNameOnlyLog(output)
+	want := [][]string{
+		{"src/handler.go", "migrations/001.sql"},
+		{"README.md"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNameOnlyLog() = %v, want %v", got, want)
+	}
+}
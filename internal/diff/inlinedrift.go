@@ -114,12 +114,12 @@ type InlineDriftSummary struct {
 
 // InlineDriftAnalyzer performs inline drift analysis for a file.
 type InlineDriftAnalyzer struct {
-	store       *store.Store
+	store       *store.SQLStore
 	projectRoot string
 }
 
 // NewInlineDriftAnalyzer creates a new inline drift analyzer.
-func NewInlineDriftAnalyzer(s *store.Store, projectRoot string) *InlineDriftAnalyzer {
+func NewInlineDriftAnalyzer(s *store.SQLStore, projectRoot string) *InlineDriftAnalyzer {
 	return &InlineDriftAnalyzer{
 		store:       s,
 		projectRoot: projectRoot,
@@ -1,6 +1,11 @@
 package embeddings
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"github.com/anthropics/cx/internal/config"
+)
 
 // Embedder generates vector embeddings from text.
 type Embedder interface {
@@ -11,11 +16,85 @@ type Embedder interface {
 	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
 
 	// ModelVersion returns the model identifier for cache invalidation.
+	// It has the form "provider:model", so changing either re-embeds.
 	ModelVersion() string
 
-	// Dimensions returns the embedding vector dimension.
+	// Dimensions returns the embedding vector dimension, or 0 if it is not
+	// known until the first embedding is generated.
 	Dimensions() int
 
 	// Close releases resources held by the embedder.
 	Close() error
 }
+
+// Embedding providers selectable with embeddings.provider in .cx/config.yaml.
+const (
+	ProviderLocal  = "local"  // all-MiniLM-L6-v2 via Hugot, downloaded from HuggingFace
+	ProviderONNX   = "onnx"   // any sentence-transformers ONNX model on disk, via Hugot
+	ProviderOpenAI = "openai" // an OpenAI-compatible /v1/embeddings endpoint
+	ProviderOllama = "ollama" // an Ollama server's /api/embed endpoint
+)
+
+// New creates the embedder selected by cfg.
+func New(cfg config.EmbeddingsConfig) (Embedder, error) {
+	switch cfg.Provider {
+	case ProviderLocal, "":
+		return NewLocalEmbedder()
+	case ProviderONNX:
+		return NewONNXEmbedder(cfg.ModelPath, modelName(cfg))
+	case ProviderOpenAI:
+		return NewOpenAIEmbedder(cfg)
+	case ProviderOllama:
+		return NewOllamaEmbedder(cfg)
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q (valid: %v)", cfg.Provider, config.ValidEmbeddingProviders)
+	}
+}
+
+// NewFromConfig creates the embedder configured in the .cx/config.yaml found
+// from the current directory, or the local embedder if there is none.
+func NewFromConfig() (Embedder, error) {
+	return New(LoadConfig())
+}
+
+// LoadConfig returns the embeddings section of the .cx/config.yaml found from
+// the current directory, or the defaults if it cannot be loaded.
+func LoadConfig() config.EmbeddingsConfig {
+	cfg, err := config.Load(".")
+	if err != nil || cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	return cfg.Embeddings
+}
+
+// ModelVersionFor returns the model version the embedder New(cfg) would
+// record, without loading a model or contacting a server. Used to find
+// entities that need re-embedding before deciding whether to create one.
+func ModelVersionFor(cfg config.EmbeddingsConfig) string {
+	provider := cfg.Provider
+	if provider == "" {
+		provider = ProviderLocal
+	}
+	version := provider + ":" + modelName(cfg)
+	if provider == ProviderOpenAI && cfg.Dimensions > 0 {
+		version += fmt.Sprintf("/%d", cfg.Dimensions)
+	}
+	return version
+}
+
+// modelName returns the configured model name or the provider's default.
+func modelName(cfg config.EmbeddingsConfig) string {
+	if cfg.Model != "" {
+		return cfg.Model
+	}
+	switch cfg.Provider {
+	case ProviderONNX:
+		return onnxModelName(cfg.ModelPath)
+	case ProviderOpenAI:
+		return DefaultOpenAIModel
+	case ProviderOllama:
+		return DefaultOllamaModel
+	default:
+		return LocalModelName
+	}
+}
@@ -2,9 +2,13 @@ package embeddings
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/knights-analytics/hugot"
@@ -14,17 +18,21 @@ import (
 const (
 	// DefaultModel is the HuggingFace model ID for embeddings.
 	DefaultModel = "sentence-transformers/all-MiniLM-L6-v2"
-	// ModelVersion is the identifier for cache invalidation.
-	ModelVersion = "all-MiniLM-L6-v2"
+	// LocalModelName is the model name recorded for the local provider.
+	LocalModelName = "all-MiniLM-L6-v2"
+	// ModelVersion is the identifier for cache invalidation of the default local embedder.
+	ModelVersion = ProviderLocal + ":" + LocalModelName
 	// EmbeddingDimensions is the output dimension of all-MiniLM-L6-v2.
 	EmbeddingDimensions = 384
 )
 
 // HugotEmbedder implements Embedder using the Hugot library with pure Go inference.
 type HugotEmbedder struct {
-	session  *hugot.Session
-	pipeline *pipelines.FeatureExtractionPipeline
-	mu       sync.Mutex
+	session      *hugot.Session
+	pipeline     *pipelines.FeatureExtractionPipeline
+	modelVersion string
+	dimensions   int
+	mu           sync.Mutex
 }
 
 // NewLocalEmbedder creates an embedder using Hugot with the pure Go backend.
@@ -67,11 +75,87 @@ func NewHugotEmbedder() (*HugotEmbedder, error) {
 	}
 
 	return &HugotEmbedder{
-		session:  session,
-		pipeline: pipeline,
+		session:      session,
+		pipeline:     pipeline,
+		modelVersion: ModelVersion,
+		dimensions:   EmbeddingDimensions,
 	}, nil
 }
 
+// NewONNXEmbedder creates a HugotEmbedder from a sentence-transformers model
+// exported to ONNX on the local filesystem, for machines that cannot reach
+// HuggingFace. modelPath is the model directory (containing tokenizer.json),
+// or a .onnx file inside it when the directory holds several variants.
+// name is recorded in the model version.
+func NewONNXEmbedder(modelPath, name string) (*HugotEmbedder, error) {
+	config := hugot.FeatureExtractionConfig{
+		ModelPath: modelPath,
+		Name:      "cxEmbeddings",
+	}
+	if strings.HasSuffix(modelPath, ".onnx") {
+		config.ModelPath = filepath.Dir(modelPath)
+		config.OnnxFilename = filepath.Base(modelPath)
+	}
+	if _, err := os.Stat(config.ModelPath); err != nil {
+		return nil, fmt.Errorf("onnx model: %w", err)
+	}
+
+	session, err := hugot.NewGoSession()
+	if err != nil {
+		return nil, fmt.Errorf("create hugot session: %w", err)
+	}
+	pipeline, err := hugot.NewPipeline(session, config)
+	if err != nil {
+		session.Destroy()
+		return nil, fmt.Errorf("load onnx model %s: %w", modelPath, err)
+	}
+
+	// The output size depends on the model; embed a probe to find it
+	probe, err := pipeline.RunPipeline([]string{"probe"})
+	if err == nil && len(probe.Embeddings) == 0 {
+		err = fmt.Errorf("no embeddings returned")
+	}
+	if err != nil {
+		session.Destroy()
+		return nil, fmt.Errorf("run onnx model %s: %w", modelPath, err)
+	}
+
+	return &HugotEmbedder{
+		session:      session,
+		pipeline:     pipeline,
+		modelVersion: ProviderONNX + ":" + name,
+		dimensions:   len(probe.Embeddings[0]),
+	}, nil
+}
+
+// onnxModelName names an ONNX model after its path and a hash of its .onnx
+// files, so that replacing the model re-embeds even though exported models
+// are usually all called model.onnx. A model that cannot be read is named
+// by its path alone; loading it fails anyway.
+func onnxModelName(modelPath string) string {
+	name := strings.TrimSuffix(filepath.Base(modelPath), ".onnx")
+	files := []string{modelPath}
+	if !strings.HasSuffix(modelPath, ".onnx") {
+		files, _ = filepath.Glob(filepath.Join(modelPath, "*.onnx"))
+	}
+	if len(files) == 0 {
+		return name
+	}
+	h := sha256.New()
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return name
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return name
+		}
+	}
+	return name + "@" + hex.EncodeToString(h.Sum(nil))[:12]
+}
+
 // getModelCacheDir returns the directory for caching downloaded models.
 func getModelCacheDir() string {
 	// Check for custom model directory
@@ -151,12 +235,12 @@ func (e *HugotEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]flo
 
 // ModelVersion returns the model identifier for cache invalidation.
 func (e *HugotEmbedder) ModelVersion() string {
-	return ModelVersion
+	return e.modelVersion
 }
 
 // Dimensions returns the embedding vector dimension.
 func (e *HugotEmbedder) Dimensions() int {
-	return EmbeddingDimensions
+	return e.dimensions
 }
 
 // Close releases resources held by the embedder.
@@ -0,0 +1,67 @@
+package embeddings
+
+import (
+	"context"
+
+	"github.com/anthropics/cx/internal/config"
+)
+
+const (
+	// DefaultOllamaEndpoint is the base URL used when embeddings.endpoint is unset.
+	DefaultOllamaEndpoint = "http://localhost:11434"
+	// DefaultOllamaModel is the model used when embeddings.model is unset.
+	DefaultOllamaModel = "nomic-embed-text"
+)
+
+// OllamaEmbedder implements Embedder using an Ollama server's /api/embed endpoint.
+type OllamaEmbedder struct {
+	remoteEmbedder
+}
+
+// NewOllamaEmbedder creates an embedder for an Ollama server. The model must
+// already be pulled (ollama pull nomic-embed-text).
+func NewOllamaEmbedder(cfg config.EmbeddingsConfig) (*OllamaEmbedder, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultOllamaEndpoint
+	}
+	cfg.Provider = ProviderOllama
+	return &OllamaEmbedder{
+		remoteEmbedder: newRemoteEmbedder(endpoint, modelName(cfg), ModelVersionFor(cfg)),
+	}, nil
+}
+
+type ollamaRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed generates an embedding vector for the given text.
+func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vecs, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+// EmbedBatch generates embeddings for multiple texts, several per request.
+func (e *OllamaEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	return e.embedInBatches(ctx, texts, e.request)
+}
+
+func (e *OllamaEmbedder) request(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp ollamaResponse
+	req := ollamaRequest{Model: e.model, Input: texts}
+	if err := e.postJSON(ctx, e.endpoint+"/api/embed", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Embeddings, nil
+}
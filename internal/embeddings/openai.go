@@ -0,0 +1,105 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/anthropics/cx/internal/config"
+)
+
+const (
+	// DefaultOpenAIEndpoint is the base URL used when embeddings.endpoint is unset.
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+	// DefaultOpenAIModel is the model used when embeddings.model is unset.
+	DefaultOpenAIModel = "text-embedding-3-small"
+	// DefaultOpenAIKeyEnv is the environment variable read for the API key
+	// when embeddings.api_key_env is unset.
+	DefaultOpenAIKeyEnv = "OPENAI_API_KEY"
+)
+
+// OpenAIEmbedder implements Embedder against an OpenAI-compatible
+// embeddings API (OpenAI, Azure-style proxies, vLLM, LM Studio, llama.cpp
+// server, ...). The endpoint is the API base URL; requests go to
+// {endpoint}/embeddings.
+type OpenAIEmbedder struct {
+	remoteEmbedder
+	apiKey      string
+	requestDims int // requested output size, 0 for the model default
+}
+
+// NewOpenAIEmbedder creates an embedder for an OpenAI-compatible endpoint.
+// The API key is read from the environment variable named by
+// cfg.APIKeyEnv; local servers that need no key may leave it unset.
+func NewOpenAIEmbedder(cfg config.EmbeddingsConfig) (*OpenAIEmbedder, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultOpenAIEndpoint
+	}
+	keyEnv := cfg.APIKeyEnv
+	if keyEnv == "" {
+		keyEnv = DefaultOpenAIKeyEnv
+	}
+	apiKey := os.Getenv(keyEnv)
+	if apiKey == "" && endpoint == DefaultOpenAIEndpoint {
+		return nil, fmt.Errorf("openai embeddings: %s is not set", keyEnv)
+	}
+
+	cfg.Provider = ProviderOpenAI
+	return &OpenAIEmbedder{
+		remoteEmbedder: newRemoteEmbedder(endpoint, modelName(cfg), ModelVersionFor(cfg)),
+		apiKey:         apiKey,
+		requestDims:    cfg.Dimensions,
+	}, nil
+}
+
+type openAIRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type openAIResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed generates an embedding vector for the given text.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vecs, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+// EmbedBatch generates embeddings for multiple texts, several per request.
+func (e *OpenAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	return e.embedInBatches(ctx, texts, e.request)
+}
+
+func (e *OpenAIEmbedder) request(ctx context.Context, texts []string) ([][]float32, error) {
+	headers := map[string]string{}
+	if e.apiKey != "" {
+		headers["Authorization"] = "Bearer " + e.apiKey
+	}
+	var resp openAIResponse
+	req := openAIRequest{Model: e.model, Input: texts, Dimensions: e.requestDims}
+	if err := e.postJSON(ctx, e.endpoint+"/embeddings", headers, req, &resp); err != nil {
+		return nil, err
+	}
+
+	// Results carry their input index; don't rely on response order
+	sort.Slice(resp.Data, func(i, j int) bool { return resp.Data[i].Index < resp.Data[j].Index })
+	vecs := make([][]float32, len(resp.Data))
+	for i, d := range resp.Data {
+		vecs[i] = d.Embedding
+	}
+	return vecs, nil
+}
@@ -0,0 +1,207 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/cx/internal/config"
+)
+
+// fakeVector returns a deterministic vector for a text, so tests can check
+// which input each output belongs to.
+func fakeVector(text string, dims int) []float32 {
+	v := make([]float32, dims)
+	v[0] = float32(len(text))
+	return v
+}
+
+func TestModelVersionFor(t *testing.T) {
+	tests := []struct {
+		cfg  config.EmbeddingsConfig
+		want string
+	}{
+		{config.EmbeddingsConfig{}, ModelVersion},
+		{config.EmbeddingsConfig{Provider: "local"}, "local:all-MiniLM-L6-v2"},
+		{config.EmbeddingsConfig{Provider: "onnx", ModelPath: "/models/bge-small-en"}, "onnx:bge-small-en"},
+		{config.EmbeddingsConfig{Provider: "onnx", ModelPath: "/models/e5/model_q8.onnx", Model: "e5-q8"}, "onnx:e5-q8"},
+		{config.EmbeddingsConfig{Provider: "openai"}, "openai:text-embedding-3-small"},
+		{config.EmbeddingsConfig{Provider: "openai", Model: "text-embedding-3-large", Dimensions: 256}, "openai:text-embedding-3-large/256"},
+		{config.EmbeddingsConfig{Provider: "ollama"}, "ollama:nomic-embed-text"},
+	}
+	for _, tt := range tests {
+		if got := ModelVersionFor(tt.cfg); got != tt.want {
+			t.Errorf("ModelVersionFor(%+v) = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/embeddings" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			http.Error(w, `{"error":{"message":"bad key"}}`, http.StatusUnauthorized)
+			return
+		}
+		var req openAIRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "embed-small" || req.Dimensions != 8 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		// Respond out of order; the embedder must use the index field
+		var resp openAIResponse
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			resp.Data = append(resp.Data, struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			}{i, fakeVector(req.Input[i], 8)})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_EMBED_KEY", "test-key")
+	emb, err := New(config.EmbeddingsConfig{
+		Provider: "openai", Endpoint: server.URL + "/v1", Model: "embed-small",
+		APIKeyEnv: "TEST_EMBED_KEY", Dimensions: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emb.Close()
+
+	texts := make([]string, remoteBatchSize+3)
+	for i := range texts {
+		texts[i] = strings.Repeat("x", i+1)
+	}
+	vecs, err := emb.EmbedBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != len(texts) || requests != 2 {
+		t.Fatalf("got %d vectors in %d requests, want %d in 2", len(vecs), requests, len(texts))
+	}
+	for i, v := range vecs {
+		if v[0] != float32(i+1) {
+			t.Fatalf("vector %d belongs to input of length %v", i, v[0])
+		}
+	}
+	if emb.Dimensions() != 8 || emb.ModelVersion() != "openai:embed-small/8" {
+		t.Errorf("Dimensions=%d ModelVersion=%q", emb.Dimensions(), emb.ModelVersion())
+	}
+
+	t.Setenv("TEST_EMBED_KEY", "wrong")
+	bad, _ := New(config.EmbeddingsConfig{Provider: "openai", Endpoint: server.URL + "/v1", APIKeyEnv: "TEST_EMBED_KEY"})
+	if _, err := bad.Embed(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("error should include the server's message, got %v", err)
+	}
+}
+
+func TestOpenAIEmbedderRequiresKeyForOpenAI(t *testing.T) {
+	t.Setenv("TEST_MISSING_KEY", "")
+	if _, err := New(config.EmbeddingsConfig{Provider: "openai", APIKeyEnv: "TEST_MISSING_KEY"}); err == nil {
+		t.Error("expected an error when the API key is missing for api.openai.com")
+	}
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req ollamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != DefaultOllamaModel {
+			http.Error(w, `{"error":"model not found, try pulling it first"}`, http.StatusNotFound)
+			return
+		}
+		var resp ollamaResponse
+		for _, text := range req.Input {
+			resp.Embeddings = append(resp.Embeddings, fakeVector(text, 4))
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	emb, err := New(config.EmbeddingsConfig{Provider: "ollama", Endpoint: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer emb.Close()
+
+	vec, err := emb.Embed(context.Background(), "hello")
+	if err != nil || len(vec) != 4 || vec[0] != 5 {
+		t.Fatalf("Embed = %v, %v", vec, err)
+	}
+	if emb.ModelVersion() != "ollama:nomic-embed-text" || emb.Dimensions() != 4 {
+		t.Errorf("ModelVersion=%q Dimensions=%d", emb.ModelVersion(), emb.Dimensions())
+	}
+
+	missing, _ := New(config.EmbeddingsConfig{Provider: "ollama", Endpoint: server.URL, Model: "unpulled"})
+	if _, err := missing.Embed(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "try pulling") {
+		t.Errorf("error should include the server's message, got %v", err)
+	}
+}
+
+func TestRemoteEmbedderRejectsMismatchedResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ollamaResponse{Embeddings: [][]float32{{1, 2}}})
+	}))
+	defer server.Close()
+
+	emb, _ := New(config.EmbeddingsConfig{Provider: "ollama", Endpoint: server.URL})
+	if _, err := emb.EmbedBatch(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("expected an error when the server returns fewer embeddings than inputs")
+	}
+}
+
+func TestModelVersionForONNXHashesModel(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bge-small-en")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	model := filepath.Join(dir, "model.onnx")
+	version := func(content string, cfg config.EmbeddingsConfig) string {
+		t.Helper()
+		if err := os.WriteFile(model, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return ModelVersionFor(cfg)
+	}
+
+	byFile := config.EmbeddingsConfig{Provider: "onnx", ModelPath: model}
+	first := version("weights v1", byFile)
+	if !strings.HasPrefix(first, "onnx:model@") || len(first) != len("onnx:model@")+12 {
+		t.Errorf("version = %q, want onnx:model@<hash>", first)
+	}
+	if second := version("weights v2", byFile); second == first {
+		t.Errorf("version %q did not change with the model file", second)
+	}
+	byDir := config.EmbeddingsConfig{Provider: "onnx", ModelPath: dir}
+	if got := version("weights v1", byDir); got != "onnx:bge-small-en"+strings.TrimPrefix(first, "onnx:model") {
+		t.Errorf("directory version = %q, want the hash of its model.onnx", got)
+	}
+	named := config.EmbeddingsConfig{Provider: "onnx", ModelPath: dir, Model: "bge-small"}
+	if got := version("weights v3", named); got != "onnx:bge-small" {
+		t.Errorf("named version = %q, want onnx:bge-small", got)
+	}
+}
+
+func TestNewONNXEmbedderMissingPath(t *testing.T) {
+	_, err := New(config.EmbeddingsConfig{Provider: "onnx", ModelPath: t.TempDir() + "/missing"})
+	if err == nil {
+		t.Error("expected an error for a missing model path")
+	}
+}
@@ -0,0 +1,137 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// remoteTimeout bounds each request to an embedding server.
+const remoteTimeout = 60 * time.Second
+
+// remoteBatchSize is the number of texts sent per request.
+const remoteBatchSize = 64
+
+// remoteEmbedder holds what the HTTP-based providers share: the endpoint,
+// the client, and the dimension learned from the first response.
+type remoteEmbedder struct {
+	endpoint     string
+	model        string
+	modelVersion string
+	client       *http.Client
+
+	mu         sync.Mutex
+	dimensions int
+}
+
+func newRemoteEmbedder(endpoint, model, modelVersion string) remoteEmbedder {
+	return remoteEmbedder{
+		endpoint:     strings.TrimRight(endpoint, "/"),
+		model:        model,
+		modelVersion: modelVersion,
+		client:       &http.Client{Timeout: remoteTimeout},
+	}
+}
+
+// ModelVersion returns the model identifier for cache invalidation.
+func (r *remoteEmbedder) ModelVersion() string {
+	return r.modelVersion
+}
+
+// Dimensions returns the embedding dimension, once a response has been seen.
+func (r *remoteEmbedder) Dimensions() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dimensions
+}
+
+// Close releases resources held by the embedder.
+func (r *remoteEmbedder) Close() error {
+	r.client.CloseIdleConnections()
+	return nil
+}
+
+// checkVectors validates a response and records its dimension. Every vector
+// must match the others and any dimension seen before.
+func (r *remoteEmbedder) checkVectors(vecs [][]float32, want int) error {
+	if len(vecs) != want {
+		return fmt.Errorf("%s returned %d embeddings for %d inputs", r.endpoint, len(vecs), want)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range vecs {
+		if len(v) == 0 {
+			return fmt.Errorf("%s returned an empty embedding", r.endpoint)
+		}
+		if r.dimensions == 0 {
+			r.dimensions = len(v)
+		}
+		if len(v) != r.dimensions {
+			return fmt.Errorf("%s returned embeddings of %d and %d dimensions", r.endpoint, r.dimensions, len(v))
+		}
+	}
+	return nil
+}
+
+// embedInBatches splits texts into requests of remoteBatchSize.
+func (r *remoteEmbedder) embedInBatches(ctx context.Context, texts []string, embed func(context.Context, []string) ([][]float32, error)) ([][]float32, error) {
+	all := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += remoteBatchSize {
+		batch := texts[start:min(start+remoteBatchSize, len(texts))]
+		vecs, err := embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.checkVectors(vecs, len(batch)); err != nil {
+			return nil, err
+		}
+		all = append(all, vecs...)
+	}
+	return all, nil
+}
+
+// postJSON sends body as JSON to url and decodes the JSON response into out.
+// Non-2xx responses are returned as errors including the start of the body,
+// which is where servers put their error message.
+func (r *remoteEmbedder) postJSON(ctx context.Context, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("embedding request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 256<<20))
+	if err != nil {
+		return fmt.Errorf("read response from %s: %w", url, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 300 {
+			msg = msg[:300] + "..."
+		}
+		return fmt.Errorf("embedding request to %s: %s: %s", url, resp.Status, msg)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response from %s: %w", url, err)
+	}
+	return nil
+}
@@ -131,6 +131,11 @@ type Entity struct {
 	BodyHash string
 	// RawBody is the raw body content (used for hash computation, not stored).
 	RawBody string
+	// Fingerprint is the winnowed token fingerprint of a function body, used
+	// to find near-copies (see ComputeFingerprint).
+	Fingerprint []uint32
+	// FingerprintTokens is the number of tokens the fingerprint covers.
+	FingerprintTokens int
 
 	// Language-specific fields
 	// Language is the programming language (go, python, rust, etc.).
@@ -0,0 +1,167 @@
+package extract
+
+import (
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Fingerprints find Type-2 and Type-3 clones: bodies that differ in
+// identifier names, literal values or small edits. A body is reduced to its
+// leaf tokens with every identifier abstracted to one token and every literal
+// to another, so only its structure is left. Each run of FingerprintK tokens
+// is hashed and winnowing keeps the smallest hash of every FingerprintWindow
+// consecutive hashes (Schleimer et al., 2003). Any shared run of
+// FingerprintK+FingerprintWindow-1 tokens is guaranteed to share a hash.
+const (
+	// FingerprintK is the number of tokens hashed together.
+	FingerprintK = 5
+	// FingerprintWindow is the number of consecutive hashes winnowed to one.
+	FingerprintWindow = 4
+)
+
+// Abstract tokens that identifiers and literals are reduced to.
+const (
+	identToken   = "$id"
+	literalToken = "$lit"
+)
+
+// literalTypes are literal node types that neither contain "string" nor end
+// in "_literal" across the supported grammars.
+var literalTypes = map[string]bool{
+	"number": true, "integer": true, "float": true, "true": true, "false": true,
+	"nil": true, "null": true, "none": true, "undefined": true, "char": true,
+	"boolean": true, "symbol": true, "simple_symbol": true, "regex": true,
+}
+
+// identTypes are identifier node types whose name lacks "identifier".
+var identTypes = map[string]bool{
+	"name": true, "constant": true, "instance_variable": true,
+	"class_variable": true, "global_variable": true, "variable_name": true,
+}
+
+// ComputeFingerprint computes the winnowed fingerprint of a function body:
+// the sorted, distinct hashes selected from its abstracted token stream, and
+// the number of tokens. It falls back to the whole node for grammars whose
+// functions have no body field, and returns nil for bodies shorter than
+// FingerprintK tokens.
+func ComputeFingerprint(funcNode *sitter.Node) ([]uint32, int) {
+	if funcNode == nil {
+		return nil, 0
+	}
+	bodyNode := funcNode.ChildByFieldName("body")
+	if bodyNode == nil {
+		bodyNode = funcNode
+	}
+	tokens := abstractTokens(bodyNode)
+	return winnow(tokens), len(tokens)
+}
+
+// FingerprintEntities sets the fingerprint of every function and method
+// extracted with its node.
+func FingerprintEntities(entities []EntityWithNode) {
+	for _, ewn := range entities {
+		if ewn.Entity == nil || (ewn.Entity.Kind != FunctionEntity && ewn.Entity.Kind != MethodEntity) {
+			continue
+		}
+		ewn.Entity.Fingerprint, ewn.Entity.FingerprintTokens = ComputeFingerprint(ewn.Node)
+	}
+}
+
+// FingerprintSimilarity is the Jaccard similarity of two fingerprints: the
+// share of their distinct hashes they have in common. Both must be sorted.
+func FingerprintSimilarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// abstractTokens lists the leaf tokens of a node in source order, skipping
+// comments and reducing identifiers and literals to abstract tokens.
+// Keywords and operators keep their node type, which is their text.
+func abstractTokens(node *sitter.Node) []string {
+	var tokens []string
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		typ := n.Type()
+		switch {
+		case strings.HasSuffix(typ, "comment"):
+			return
+		case n.IsNamed() && isLiteralType(n, typ):
+			tokens = append(tokens, literalToken)
+			return
+		case n.IsNamed() && (strings.Contains(typ, "identifier") || identTypes[typ]):
+			tokens = append(tokens, identToken)
+			return
+		case n.ChildCount() == 0:
+			tokens = append(tokens, typ)
+			return
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			walk(n.Child(i))
+		}
+	}
+	walk(node)
+	return tokens
+}
+
+// isLiteralType reports whether a named node is a literal. Compound
+// "_literal" nodes such as Go composite and function literals have named
+// children and are walked instead.
+func isLiteralType(n *sitter.Node, typ string) bool {
+	if literalTypes[typ] || strings.Contains(typ, "string") {
+		return true
+	}
+	return strings.HasSuffix(typ, "_literal") && n.NamedChildCount() == 0
+}
+
+// winnow hashes every FingerprintK-gram of tokens and keeps the rightmost
+// minimum of every window of FingerprintWindow hashes.
+func winnow(tokens []string) []uint32 {
+	if len(tokens) < FingerprintK {
+		return nil
+	}
+	grams := make([]uint32, len(tokens)-FingerprintK+1)
+	for i := range grams {
+		h := fnv.New32a()
+		for _, tok := range tokens[i : i+FingerprintK] {
+			h.Write([]byte(tok))
+			h.Write([]byte{0})
+		}
+		grams[i] = h.Sum32()
+	}
+
+	window := min(FingerprintWindow, len(grams))
+	var selected []uint32
+	last := -1
+	for start := 0; start+window <= len(grams); start++ {
+		minPos := start
+		for i := start + 1; i < start+window; i++ {
+			if grams[i] <= grams[minPos] {
+				minPos = i
+			}
+		}
+		if minPos != last {
+			selected = append(selected, grams[minPos])
+			last = minPos
+		}
+	}
+	slices.Sort(selected)
+	return slices.Compact(selected)
+}
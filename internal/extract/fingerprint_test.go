@@ -0,0 +1,103 @@
+package extract
+
+import (
+	"context"
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+)
+
+// fingerprintGo parses a Go function and returns its fingerprint.
+func fingerprintGo(t *testing.T, src string) []uint32 {
+	t.Helper()
+	source := []byte("package main\n\n" + src)
+	parser := sitter.NewParser()
+	parser.SetLanguage(golang.GetLanguage())
+	tree, err := parser.ParseCtx(context.Background(), nil, source)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	t.Cleanup(tree.Close)
+	funcNode := findFunctionDeclaration(tree.RootNode())
+	if funcNode == nil {
+		t.Fatal("Could not find function declaration")
+	}
+	fp, tokens := ComputeFingerprint(funcNode)
+	if len(fp) == 0 || tokens < FingerprintK {
+		t.Fatalf("empty fingerprint for %q (%d tokens)", src, tokens)
+	}
+	return fp
+}
+
+func TestComputeFingerprint_Clones(t *testing.T) {
+	original := fingerprintGo(t, `func Sum(items []int, limit int) int {
+	total := 0
+	for i, v := range items {
+		if i >= limit {
+			break
+		}
+		total += v * 2
+	}
+	return total
+}`)
+
+	// Type-2: identifiers, literals and comments differ
+	renamed := fingerprintGo(t, `func Add(values []int, max int) int {
+	acc := 1 // start at one
+	for n, x := range values {
+		if n >= max {
+			break
+		}
+		acc += x * 3
+	}
+	return acc
+}`)
+	if sim := FingerprintSimilarity(original, renamed); sim != 1 {
+		t.Errorf("renamed clone similarity = %.2f, want 1", sim)
+	}
+
+	// Type-3: a statement added
+	edited := fingerprintGo(t, `func Sum(items []int, limit int) int {
+	total := 0
+	for i, v := range items {
+		if i >= limit {
+			break
+		}
+		total += v * 2
+	}
+	log.Println(total)
+	return total
+}`)
+	if sim := FingerprintSimilarity(original, edited); sim < 0.5 || sim == 1 {
+		t.Errorf("edited clone similarity = %.2f, want in [0.5, 1)", sim)
+	}
+
+	different := fingerprintGo(t, `func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &File{f: f}, nil
+}`)
+	if sim := FingerprintSimilarity(original, different); sim > 0.3 {
+		t.Errorf("unrelated similarity = %.2f, want at most 0.3", sim)
+	}
+}
+
+func TestFingerprintSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b []uint32
+		want float64
+	}{
+		{[]uint32{1, 2, 3}, []uint32{1, 2, 3}, 1},
+		{[]uint32{1, 2, 3}, []uint32{2, 3, 4}, 0.5},
+		{[]uint32{1, 2}, []uint32{3, 4}, 0},
+		{nil, []uint32{1}, 0},
+	}
+	for _, tt := range tests {
+		if got := FingerprintSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("FingerprintSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
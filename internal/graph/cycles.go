@@ -0,0 +1,344 @@
+package graph
+
+import (
+	"sort"
+)
+
+// WeightedEdge is a dependency between two nodes of a condensed graph.
+// Weight counts the underlying entity dependencies.
+type WeightedEdge struct {
+	From   string `yaml:"from" json:"from"`
+	To     string `yaml:"to" json:"to"`
+	Weight int    `yaml:"weight" json:"weight"`
+}
+
+// Cycle is a strongly connected component of two or more nodes: every
+// member depends, directly or indirectly, on every other member.
+type Cycle struct {
+	Members []string       // sorted
+	Edges   []WeightedEdge // dependencies between members
+}
+
+// StronglyConnectedComponents returns every strongly connected component of
+// the graph using Tarjan's algorithm. Members of each component are sorted
+// and components are listed in reverse topological order: a component
+// comes before the components that depend on it.
+func (g *Graph) StronglyConnectedComponents() [][]string {
+	return tarjan(g.Edges)
+}
+
+// Cycles groups nodes with groupOf, merges the dependencies between groups
+// and returns every cycle among the groups, largest first. A nil groupOf
+// keeps entity granularity; nodes it maps to "" are left out. Dependencies
+// within a group, including self-recursion, are not cycles.
+func (g *Graph) Cycles(groupOf func(node string) string) []Cycle {
+	if groupOf == nil {
+		groupOf = func(node string) string { return node }
+	}
+	weights := make(map[[2]string]int)
+	adj := make(map[string][]string)
+	for from, targets := range g.Edges {
+		gf := groupOf(from)
+		if gf == "" {
+			continue
+		}
+		if _, ok := adj[gf]; !ok {
+			adj[gf] = nil
+		}
+		for _, to := range targets {
+			gt := groupOf(to)
+			if gt == "" || gt == gf {
+				continue
+			}
+			key := [2]string{gf, gt}
+			if weights[key] == 0 {
+				adj[gf] = append(adj[gf], gt)
+			}
+			weights[key]++
+		}
+	}
+
+	var cycles []Cycle
+	for _, members := range tarjan(adj) {
+		if len(members) < 2 {
+			continue
+		}
+		in := make(map[string]bool, len(members))
+		for _, m := range members {
+			in[m] = true
+		}
+		c := Cycle{Members: members}
+		for _, m := range members {
+			for _, to := range adj[m] {
+				if in[to] {
+					c.Edges = append(c.Edges, WeightedEdge{From: m, To: to, Weight: weights[[2]string{m, to}]})
+				}
+			}
+		}
+		sortEdges(c.Edges)
+		cycles = append(cycles, c)
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		if len(cycles[i].Members) != len(cycles[j].Members) {
+			return len(cycles[i].Members) > len(cycles[j].Members)
+		}
+		return cycles[i].Members[0] < cycles[j].Members[0]
+	})
+	return cycles
+}
+
+// tarjan computes strongly connected components iteratively, so deep call
+// chains cannot overflow the stack. Nodes are visited in sorted order to
+// make the result deterministic.
+func tarjan(adj map[string][]string) [][]string {
+	nodes := make([]string, 0, len(adj))
+	for n := range adj {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	index := make(map[string]int, len(nodes))
+	low := make(map[string]int, len(nodes))
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	type frame struct {
+		node string
+		edge int
+	}
+	for _, root := range nodes {
+		if _, seen := index[root]; seen {
+			continue
+		}
+		index[root], low[root] = next, next
+		next++
+		stack = append(stack, root)
+		onStack[root] = true
+		work := []frame{{node: root}}
+
+		for len(work) > 0 {
+			f := &work[len(work)-1]
+			if f.edge < len(adj[f.node]) {
+				w := adj[f.node][f.edge]
+				f.edge++
+				if _, seen := index[w]; !seen {
+					index[w], low[w] = next, next
+					next++
+					stack = append(stack, w)
+					onStack[w] = true
+					work = append(work, frame{node: w})
+				} else if onStack[w] && index[w] < low[f.node] {
+					low[f.node] = index[w]
+				}
+				continue
+			}
+
+			v := f.node
+			work = work[:len(work)-1]
+			if len(work) > 0 {
+				if parent := work[len(work)-1].node; low[v] < low[parent] {
+					low[parent] = low[v]
+				}
+			}
+			if low[v] == index[v] {
+				var comp []string
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					comp = append(comp, w)
+					if w == v {
+						break
+					}
+				}
+				sort.Strings(comp)
+				components = append(components, comp)
+			}
+		}
+	}
+	return components
+}
+
+// FeedbackArcSet returns dependencies whose removal breaks every cycle in c.
+// Finding the minimum set is NP-hard; this orders members with the
+// Eades-Lin-Smyth heuristic, weighted so light dependencies are preferred,
+// takes the edges pointing backwards in that order, and then restores any
+// that are not needed, so no edge in the result can be kept.
+func FeedbackArcSet(c Cycle) []WeightedEdge {
+	out := make(map[string][]WeightedEdge)
+	in := make(map[string][]WeightedEdge)
+	for _, e := range c.Edges {
+		out[e.From] = append(out[e.From], e)
+		in[e.To] = append(in[e.To], e)
+	}
+
+	// Order members: sinks go to the end, sources to the front, otherwise
+	// the node with the largest out-weight minus in-weight goes next.
+	remaining := make(map[string]bool, len(c.Members))
+	outW := make(map[string]int, len(c.Members))
+	inW := make(map[string]int, len(c.Members))
+	for _, e := range c.Edges {
+		outW[e.From] += e.Weight
+		inW[e.To] += e.Weight
+	}
+	for _, m := range c.Members {
+		remaining[m] = true
+	}
+	remove := func(n string) {
+		delete(remaining, n)
+		for _, e := range out[n] {
+			inW[e.To] -= e.Weight
+		}
+		for _, e := range in[n] {
+			outW[e.From] -= e.Weight
+		}
+	}
+	pick := func(ok func(string) bool) string {
+		for _, m := range c.Members {
+			if remaining[m] && ok(m) {
+				return m
+			}
+		}
+		return ""
+	}
+
+	var head, tail []string
+	for len(remaining) > 0 {
+		if n := pick(func(m string) bool { return outW[m] == 0 }); n != "" {
+			tail = append(tail, n)
+			remove(n)
+			continue
+		}
+		if n := pick(func(m string) bool { return inW[m] == 0 }); n != "" {
+			head = append(head, n)
+			remove(n)
+			continue
+		}
+		best, bestDelta := "", 0
+		for _, m := range c.Members {
+			if d := outW[m] - inW[m]; remaining[m] && (best == "" || d > bestDelta) {
+				best, bestDelta = m, d
+			}
+		}
+		head = append(head, best)
+		remove(best)
+	}
+	pos := make(map[string]int, len(c.Members))
+	for i, m := range head {
+		pos[m] = i
+	}
+	for i := len(tail) - 1; i >= 0; i-- {
+		pos[tail[i]] = len(head) + len(tail) - 1 - i
+	}
+
+	var cut, kept []WeightedEdge
+	for _, e := range c.Edges {
+		if pos[e.From] > pos[e.To] {
+			cut = append(cut, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+
+	// Restore the heaviest cut edges first while the rest stays acyclic
+	sort.SliceStable(cut, func(i, j int) bool { return cut[i].Weight > cut[j].Weight })
+	var needed []WeightedEdge
+	for _, e := range cut {
+		if acyclic(c.Members, append(kept, e)) {
+			kept = append(kept, e)
+		} else {
+			needed = append(needed, e)
+		}
+	}
+	sortEdges(needed)
+	return needed
+}
+
+// acyclic reports whether edges over nodes contain no cycle (Kahn's algorithm).
+func acyclic(nodes []string, edges []WeightedEdge) bool {
+	indeg := make(map[string]int, len(nodes))
+	adj := make(map[string][]string, len(nodes))
+	for _, e := range edges {
+		adj[e.From] = append(adj[e.From], e.To)
+		indeg[e.To]++
+	}
+	var queue []string
+	for _, n := range nodes {
+		if indeg[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, t := range adj[n] {
+			indeg[t]--
+			if indeg[t] == 0 {
+				queue = append(queue, t)
+			}
+		}
+	}
+	return visited == len(nodes)
+}
+
+func sortEdges(edges []WeightedEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
+
+// CycleTrend compares a cycle with the cycles recorded for an earlier scan.
+// It returns "new" when no earlier cycle shares a member, "grew" when the
+// cycle gained members or swallowed earlier cycles, "shrank" when it only lost members and "unchanged"
+// otherwise, along with the size of the largest overlapping earlier cycle.
+func CycleTrend(members []string, previous [][]string) (string, int) {
+	current := make(map[string]bool, len(members))
+	for _, m := range members {
+		current[m] = true
+	}
+	seen := make(map[string]bool)
+	prevSize := 0
+	for _, p := range previous {
+		overlap := false
+		for _, m := range p {
+			if current[m] {
+				overlap = true
+				break
+			}
+		}
+		if !overlap {
+			continue
+		}
+		if len(p) > prevSize {
+			prevSize = len(p)
+		}
+		for _, m := range p {
+			seen[m] = true
+		}
+	}
+	if prevSize == 0 {
+		return "new", 0
+	}
+	gained, kept := false, 0
+	for _, m := range members {
+		if seen[m] {
+			kept++
+		} else {
+			gained = true
+		}
+	}
+	switch {
+	case gained || len(members) > prevSize: // new members, or earlier cycles merged
+		return "grew", prevSize
+	case kept < len(seen):
+		return "shrank", prevSize
+	}
+	return "unchanged", prevSize
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	g := newTestGraph()
+	// a <-> b, b -> c, c -> d -> e -> c, f alone, g -> g
+	g.addEdge("a", "b")
+	g.addEdge("b", "a")
+	g.addEdge("b", "c")
+	g.addEdge("c", "d")
+	g.addEdge("d", "e")
+	g.addEdge("e", "c")
+	g.addEdge("f", "a")
+	g.addEdge("g", "g")
+
+	got := g.StronglyConnectedComponents()
+	want := [][]string{{"c", "d", "e"}, {"a", "b"}, {"f"}, {"g"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StronglyConnectedComponents() = %v, want %v", got, want)
+	}
+}
+
+func TestStronglyConnectedComponents_DeepChain(t *testing.T) {
+	g := newTestGraph()
+	const n = 200000
+	name := func(i int) string { return "n" + itoa(i) }
+	for i := 0; i < n; i++ {
+		g.addEdge(name(i), name((i+1)%n))
+	}
+	comps := g.StronglyConnectedComponents()
+	if len(comps) != 1 || len(comps[0]) != n {
+		t.Fatalf("got %d components, want one of %d nodes", len(comps), n)
+	}
+}
+
+func TestCycles_PackageLevel(t *testing.T) {
+	g := newTestGraph()
+	pkg := map[string]string{
+		"cmd.Run": "cmd", "cmd.Help": "cmd",
+		"store.Open": "store", "store.Exec": "store",
+		"graph.Build": "graph",
+		"util.Log":    "util",
+	}
+	g.addEdge("cmd.Run", "store.Open")
+	g.addEdge("cmd.Help", "store.Exec")
+	g.addEdge("store.Open", "graph.Build")
+	g.addEdge("graph.Build", "cmd.Help") // closes cmd -> store -> graph -> cmd
+	g.addEdge("store.Open", "store.Exec")
+	g.addEdge("cmd.Run", "util.Log")
+	g.addEdge("util.Log", "util.Log")
+
+	cycles := g.Cycles(func(id string) string { return pkg[id] })
+	if len(cycles) != 1 {
+		t.Fatalf("got %d cycles, want 1: %v", len(cycles), cycles)
+	}
+	c := cycles[0]
+	if want := []string{"cmd", "graph", "store"}; !reflect.DeepEqual(c.Members, want) {
+		t.Errorf("members = %v, want %v", c.Members, want)
+	}
+	wantEdges := []WeightedEdge{{"cmd", "store", 2}, {"graph", "cmd", 1}, {"store", "graph", 1}}
+	if !reflect.DeepEqual(c.Edges, wantEdges) {
+		t.Errorf("edges = %v, want %v", c.Edges, wantEdges)
+	}
+
+	// The cut must avoid the heavy cmd -> store dependency
+	cut := FeedbackArcSet(c)
+	if len(cut) != 1 || cut[0].Weight != 1 {
+		t.Errorf("FeedbackArcSet() = %v, want one light edge", cut)
+	}
+
+	// Entity level has no cycle: self-recursion does not count
+	if got := g.Cycles(nil); len(got) != 0 {
+		t.Errorf("entity cycles = %v, want none", got)
+	}
+}
+
+func TestFeedbackArcSet_BreaksAllCycles(t *testing.T) {
+	g := newTestGraph()
+	// Dense strongly connected graph over 6 nodes
+	nodes := []string{"a", "b", "c", "d", "e", "f"}
+	for i, from := range nodes {
+		for j, to := range nodes {
+			if i != j && (i+j)%3 != 0 {
+				g.addEdge(from, to)
+			}
+		}
+	}
+	cycles := g.Cycles(nil)
+	if len(cycles) != 1 {
+		t.Fatalf("got %d cycles, want 1", len(cycles))
+	}
+	c := cycles[0]
+	cut := FeedbackArcSet(c)
+
+	removed := make(map[WeightedEdge]bool)
+	for _, e := range cut {
+		removed[e] = true
+	}
+	var rest []WeightedEdge
+	for _, e := range c.Edges {
+		if !removed[e] {
+			rest = append(rest, e)
+		}
+	}
+	if !acyclic(c.Members, rest) {
+		t.Fatalf("graph still has a cycle after removing %v", cut)
+	}
+	// Minimal: putting any cut edge back creates a cycle
+	for _, e := range cut {
+		if acyclic(c.Members, append(append([]WeightedEdge{}, rest...), e)) {
+			t.Errorf("cut edge %v is not needed", e)
+		}
+	}
+}
+
+func TestCycleTrend(t *testing.T) {
+	previous := [][]string{{"a", "b"}, {"x", "y", "z"}}
+	tests := []struct {
+		members  []string
+		want     string
+		wantSize int
+	}{
+		{[]string{"a", "b"}, "unchanged", 2},
+		{[]string{"a", "b", "c"}, "grew", 2},
+		{[]string{"x", "y"}, "shrank", 3},
+		{[]string{"p", "q"}, "new", 0},
+		{[]string{"a", "b", "x", "y", "z"}, "grew", 3}, // merged
+	}
+	for _, tt := range tests {
+		got, size := CycleTrend(tt.members, previous)
+		if got != tt.want || size != tt.wantSize {
+			t.Errorf("CycleTrend(%v) = %s, %d; want %s, %d", tt.members, got, size, tt.want, tt.wantSize)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// couplingBins is the number of bins per axis of the coupling scatter
+const couplingBins = 5
+
+// maxCouplingLabels caps the package names shown in one scatter cell
+const maxCouplingLabels = 4
+
+// CouplingPoint places a package on the abstractness/instability plane.
+type CouplingPoint struct {
+	Package      string
+	Instability  float64
+	Abstractness float64
+}
+
+// BuildCouplingDiagram creates a D2 scatter of packages over abstractness
+// (rows, abstract at the top) and instability (columns, unstable to the
+// right). Cells far from the main sequence A + I = 1 are colored as the
+// zone of pain (bottom left) or the zone of uselessness (top right).
+func BuildCouplingDiagram(points []CouplingPoint, title string, theme ...string) string {
+	config := DefaultDiagramConfig()
+	config.Title = title
+	if len(theme) > 0 && theme[0] != "" {
+		config.Theme = theme[0]
+	}
+
+	bin := func(v float64) int {
+		b := int(v * couplingBins)
+		if b >= couplingBins {
+			b = couplingBins - 1
+		}
+		if b < 0 {
+			b = 0
+		}
+		return b
+	}
+	cells := make(map[[2]int][]string)
+	for _, p := range points {
+		key := [2]int{bin(p.Abstractness), bin(p.Instability)}
+		cells[key] = append(cells[key], p.Package)
+	}
+
+	var sb strings.Builder
+	NewD2Generator(config).writeThemeConfig(&sb)
+	if config.Title != "" {
+		sb.WriteString(fmt.Sprintf("\ntitle: {\n  label: %q\n  near: top-center\n  style: {\n    font-size: 24\n    bold: true\n  }\n}\n", config.Title))
+	}
+
+	sb.WriteString("\nplane: \"abstractness ↑   instability →\" {\n")
+	sb.WriteString("  grid-rows: " + itoa(couplingBins) + "\n")
+	sb.WriteString("  grid-columns: " + itoa(couplingBins) + "\n")
+	sb.WriteString("  grid-gap: 0\n")
+	for a := couplingBins - 1; a >= 0; a-- {
+		for i := 0; i < couplingBins; i++ {
+			// Color by where the cell's center lies relative to the main sequence
+			ca := (float64(a) + 0.5) / couplingBins
+			ci := (float64(i) + 0.5) / couplingBins
+			color := D2RiskColors["ok"]
+			if math.Abs(ca+ci-1) > 0.5 {
+				if ca+ci < 1 {
+					color = D2RiskColors["critical"]
+				} else {
+					color = D2RiskColors["warning"]
+				}
+			}
+
+			names := cells[[2]int{a, i}]
+			label := " "
+			if len(names) > 0 {
+				shown := names
+				if len(shown) > maxCouplingLabels {
+					shown = shown[:maxCouplingLabels]
+				}
+				label = strings.Join(shown, "\\n")
+				if extra := len(names) - len(shown); extra > 0 {
+					label += fmt.Sprintf("\\n+%d more", extra)
+				}
+			}
+			sb.WriteString(fmt.Sprintf("  a%d_i%d: \"%s\" {\n", a, i, strings.ReplaceAll(label, `"`, `'`)))
+			sb.WriteString(fmt.Sprintf("    width: 180\n    height: 90\n    style.fill: %q\n    style.stroke: %q\n    style.font-size: 11\n  }\n", color.Fill, color.Stroke))
+		}
+	}
+	sb.WriteString("}\n")
+
+	sb.WriteString("\nlegend: {\n  near: bottom-center\n  grid-columns: 3\n")
+	for _, z := range []struct{ name, label, color string }{
+		{"pain", "Zone of pain: concrete and stable", "critical"},
+		{"sequence", "Near the main sequence", "ok"},
+		{"uselessness", "Zone of uselessness: abstract and unstable", "warning"},
+	} {
+		c := D2RiskColors[z.color]
+		sb.WriteString(fmt.Sprintf("  %s: %q {style.fill: %q; style.stroke: %q}\n", z.name, z.label, c.Fill, c.Stroke))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,29 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCouplingDiagram(t *testing.T) {
+	points := []CouplingPoint{
+		{Package: "internal/store", Instability: 0.1, Abstractness: 0.0},
+		{Package: "internal/api", Instability: 0.9, Abstractness: 1.0},
+		{Package: "cmd/cx", Instability: 1.0, Abstractness: 0.0},
+	}
+	d2 := BuildCouplingDiagram(points, "Package Coupling")
+
+	for _, want := range []string{
+		"grid-rows: 5",
+		`a0_i0: "internal/store"`,                 // bottom left
+		`a4_i4: "internal/api"`,                   // top right
+		`a0_i4: "cmd/cx"`,                         // bottom right, on the main sequence
+		`fill: "` + D2RiskColors["critical"].Fill, // zone of pain
+		`fill: "` + D2RiskColors["warning"].Fill,  // zone of uselessness
+		"Package Coupling",
+	} {
+		if !strings.Contains(d2, want) {
+			t.Errorf("diagram missing %q:\n%s", want, d2)
+		}
+	}
+}
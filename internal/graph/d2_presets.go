@@ -142,7 +142,7 @@ func DependencyToDiagramEdge(dep *store.Dependency) DiagramEdge {
 // BuildArchitectureDiagram creates a D2 architecture diagram from store data.
 // It queries the store for entities and dependencies, then generates D2 code.
 // The theme parameter is optional - pass empty string for default theme.
-func BuildArchitectureDiagram(s *store.Store, title string, maxEntities int, theme ...string) (string, error) {
+func BuildArchitectureDiagram(s store.Store, title string, maxEntities int, theme ...string) (string, error) {
 	config := ArchitecturePreset()
 	config.Title = title
 	if maxEntities > 0 {
@@ -215,7 +215,7 @@ func BuildArchitectureDiagram(s *store.Store, title string, maxEntities int, the
 // BuildFilteredArchitectureDiagram creates a D2 architecture diagram filtered by layers.
 // allowedLayers specifies which layers to include (e.g., ["core", "parser"]).
 // If allowedLayers is empty or nil, all layers are included.
-func BuildFilteredArchitectureDiagram(s *store.Store, title string, maxEntities int, allowedLayers []string, theme ...string) (string, error) {
+func BuildFilteredArchitectureDiagram(s store.Store, title string, maxEntities int, allowedLayers []string, theme ...string) (string, error) {
 	config := ArchitecturePreset()
 	config.Title = title
 	if maxEntities > 0 {
@@ -305,7 +305,7 @@ func BuildFilteredArchitectureDiagram(s *store.Store, title string, maxEntities
 
 // BuildModuleArchitectureDiagram creates a D2 architecture diagram focused on modules.
 // It collapses entities into their modules and shows inter-module relationships.
-func BuildModuleArchitectureDiagram(s *store.Store, title string) (string, error) {
+func BuildModuleArchitectureDiagram(s store.Store, title string) (string, error) {
 	config := ArchitecturePreset()
 	config.Title = title
 
@@ -530,7 +530,7 @@ func classifyImportanceByRank(pageRank float64) string {
 // It performs BFS traversal following outgoing calls to the specified depth.
 // The diagram shows the call chain with entities ordered top-to-bottom.
 // The theme parameter is optional - pass empty string for default theme.
-func BuildCallFlowDiagram(s *store.Store, rootEntityID string, depth int, title string, theme ...string) (string, error) {
+func BuildCallFlowDiagram(s store.Store, rootEntityID string, depth int, title string, theme ...string) (string, error) {
 	if depth <= 0 {
 		depth = 3 // Default depth
 	}
@@ -658,7 +658,7 @@ func BuildCallFlowDiagram(s *store.Store, rootEntityID string, depth int, title
 
 // BuildCallFlowDiagramFromName creates a call flow diagram by finding an entity by name.
 // This is a convenience wrapper for cases where only the entity name is known.
-func BuildCallFlowDiagramFromName(s *store.Store, entityName string, depth int, title string) (string, error) {
+func BuildCallFlowDiagramFromName(s store.Store, entityName string, depth int, title string) (string, error) {
 	// Search for the entity by name
 	opts := store.DefaultSearchOptions()
 	opts.Query = entityName
@@ -684,7 +684,7 @@ func BuildCallFlowDiagramFromName(s *store.Store, entityName string, depth int,
 // BuildCallersFlowDiagram creates a diagram showing what calls a given entity.
 // It traverses incoming dependencies (callers) instead of outgoing calls.
 // The theme parameter is optional - pass empty string for default theme.
-func BuildCallersFlowDiagram(s *store.Store, targetEntityID string, depth int, title string, theme ...string) (string, error) {
+func BuildCallersFlowDiagram(s store.Store, targetEntityID string, depth int, title string, theme ...string) (string, error) {
 	if depth <= 0 {
 		depth = 3
 	}
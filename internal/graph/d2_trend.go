@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// trendBins is the number of value rows of a trend chart
+const trendBins = 6
+
+// TrendPoint is one value of a metric series, labeled by scan or commit.
+type TrendPoint struct {
+	Label string
+	Value float64
+}
+
+// BuildTrendDiagram creates a D2 line chart of a metric series as a grid:
+// one column per point, oldest on the left, with the cell at the point's
+// value marked and labeled. D2 has no native line chart, so the grid keeps
+// the shape of the series readable in rendered reports.
+func BuildTrendDiagram(points []TrendPoint, title string, theme ...string) string {
+	config := DefaultDiagramConfig()
+	config.Title = title
+	if len(theme) > 0 && theme[0] != "" {
+		config.Theme = theme[0]
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+	}
+	bin := func(v float64) int {
+		if hi == lo {
+			return trendBins / 2
+		}
+		return min(int((v-lo)/(hi-lo)*trendBins), trendBins-1)
+	}
+
+	var sb strings.Builder
+	NewD2Generator(config).writeThemeConfig(&sb)
+	if config.Title != "" {
+		sb.WriteString(fmt.Sprintf("\ntitle: {\n  label: %q\n  near: top-center\n  style: {\n    font-size: 24\n    bold: true\n  }\n}\n", config.Title))
+	}
+	if len(points) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\nchart: %q {\n", fmt.Sprintf("%s … %s", formatTrendValue(lo), formatTrendValue(hi))))
+	sb.WriteString("  grid-rows: " + itoa(trendBins+1) + "\n")
+	sb.WriteString("  grid-columns: " + itoa(len(points)) + "\n")
+	sb.WriteString("  grid-gap: 0\n")
+	point, empty := D2RiskColors["info"], D2CoverageColors["none"]
+	for row := trendBins - 1; row >= 0; row-- {
+		for i, p := range points {
+			label, color := " ", empty
+			if bin(p.Value) == row {
+				label, color = formatTrendValue(p.Value), point
+			}
+			sb.WriteString(fmt.Sprintf("  r%d_c%d: %q {\n", row, i, label))
+			sb.WriteString(fmt.Sprintf("    width: 90\n    height: 40\n    style.fill: %q\n    style.stroke: %q\n    style.font-size: 11\n  }\n", color.Fill, color.Stroke))
+		}
+	}
+	// The bottom row labels the points
+	for i, p := range points {
+		sb.WriteString(fmt.Sprintf("  x_c%d: %q {\n    width: 90\n    height: 30\n    style.fill: transparent\n    style.stroke: transparent\n    style.font-size: 10\n  }\n", i, p.Label))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// formatTrendValue formats a metric value compactly: integers as such, small
+// values with enough precision to tell PageRank apart.
+func formatTrendValue(v float64) string {
+	switch {
+	case v == math.Trunc(v) && math.Abs(v) < 1e9:
+		return fmt.Sprintf("%.0f", v)
+	case math.Abs(v) < 0.01:
+		return fmt.Sprintf("%.2g", v)
+	default:
+		return fmt.Sprintf("%.2f", v)
+	}
+}
@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildTrendDiagram(t *testing.T) {
+	points := []TrendPoint{{"a1b2c3d", 10}, {"e4f5a6b", 40}, {"c7d8e9f", 25}}
+	d2 := BuildTrendDiagram(points, "Coverage")
+
+	for _, want := range []string{
+		"grid-columns: 3",
+		`chart: "10 … 40"`,
+		`r0_c0: "10"`, // lowest row
+		`r5_c1: "40"`, // highest row
+		`r3_c2: "25"`,
+		`x_c1: "e4f5a6b"`,
+		"Coverage",
+	} {
+		if !strings.Contains(d2, want) {
+			t.Errorf("diagram missing %q:\n%s", want, d2)
+		}
+	}
+	if strings.Contains(d2, `r5_c0: "10"`) {
+		t.Error("a point should only be marked at its own value")
+	}
+
+	// A flat series sits in the middle row
+	if flat := BuildTrendDiagram([]TrendPoint{{"a", 0.5}, {"b", 0.5}}, ""); !strings.Contains(flat, `r3_c1: "0.50"`) {
+		t.Errorf("flat series not in the middle row:\n%s", flat)
+	}
+}
+
+func TestGenerateLineChart(t *testing.T) {
+	chart := GenerateLineChart([]TrendPoint{{"scan 1", 0.0012}, {"scan 2", 3}}, "PageRank of Execute", "pagerank")
+	for _, want := range []string{
+		"xychart-beta",
+		`title "PageRank of Execute"`,
+		`x-axis ["scan 1", "scan 2"]`,
+		`y-axis "pagerank"`,
+		"line [0.0012, 3]",
+	} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("chart missing %q:\n%s", want, chart)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package graph
+
+import (
+	"slices"
+	"sort"
+)
+
+// DominatorTree holds the dominators of the nodes reachable from a set of
+// roots. A node d dominates n when every path from any root to n passes
+// through d. The roots hang off a virtual root, so a node reachable from
+// two roots along disjoint paths has no dominator.
+type DominatorTree struct {
+	// Idom maps each reachable node to its immediate dominator, or "" when
+	// only the virtual root dominates it (roots and nodes reached from
+	// several roots independently).
+	Idom map[string]string
+
+	// Roots are the roots the tree was built from, in the given order.
+	Roots []string
+
+	children map[string][]string
+	size     map[string]int // nodes in the dominator subtree, itself included
+}
+
+// Dominators computes the dominator tree of the nodes reachable from roots
+// along dependency edges with the Lengauer-Tarjan algorithm. Roots that are
+// not in the graph are ignored.
+func (g *Graph) Dominators(roots []string) *DominatorTree {
+	t := &DominatorTree{
+		Idom:     make(map[string]string),
+		children: make(map[string][]string),
+		size:     make(map[string]int),
+	}
+
+	// Number nodes in DFS preorder from a virtual root (number 0)
+	index := map[string]int{}
+	nodes := []string{""}
+	parent := []int{0}
+	type frame struct {
+		node string
+		next int
+	}
+	for _, r := range roots {
+		if _, ok := g.Edges[r]; !ok {
+			continue
+		}
+		if slices.Contains(t.Roots, r) {
+			continue
+		}
+		t.Roots = append(t.Roots, r)
+		if _, seen := index[r]; seen {
+			// Reached from an earlier root; the virtual root edge is added below
+			continue
+		}
+		index[r] = len(nodes)
+		nodes = append(nodes, r)
+		parent = append(parent, 0)
+		stack := []frame{{node: r}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			succ := g.Edges[top.node]
+			if top.next == len(succ) {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			next := succ[top.next]
+			top.next++
+			if _, seen := index[next]; seen {
+				continue
+			}
+			index[next] = len(nodes)
+			nodes = append(nodes, next)
+			parent = append(parent, index[top.node])
+			stack = append(stack, frame{node: next})
+		}
+	}
+	n := len(nodes)
+	if n == 1 {
+		return t
+	}
+
+	// Predecessors by number; the virtual root precedes every root
+	preds := make([][]int, n)
+	for v := 1; v < n; v++ {
+		for _, p := range g.ReverseEdges[nodes[v]] {
+			if u, ok := index[p]; ok {
+				preds[v] = append(preds[v], u)
+			}
+		}
+	}
+	for _, r := range t.Roots {
+		preds[index[r]] = append(preds[index[r]], 0)
+	}
+
+	semi := make([]int, n)
+	idom := make([]int, n)
+	ancestor := make([]int, n)
+	label := make([]int, n)
+	bucket := make([][]int, n)
+	for v := range semi {
+		semi[v], label[v], ancestor[v] = v, v, -1
+	}
+
+	// eval with iterative path compression
+	eval := func(v int) int {
+		if ancestor[v] < 0 {
+			return v
+		}
+		var path []int
+		for u := v; ancestor[ancestor[u]] >= 0; u = ancestor[u] {
+			path = append(path, u)
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			u := path[i]
+			a := ancestor[u]
+			if semi[label[a]] < semi[label[u]] {
+				label[u] = label[a]
+			}
+			ancestor[u] = ancestor[a]
+		}
+		return label[v]
+	}
+
+	for w := n - 1; w >= 1; w-- {
+		for _, v := range preds[w] {
+			if u := eval(v); semi[u] < semi[w] {
+				semi[w] = semi[u]
+			}
+		}
+		bucket[semi[w]] = append(bucket[semi[w]], w)
+		p := parent[w]
+		ancestor[w] = p
+		for _, v := range bucket[p] {
+			if u := eval(v); semi[u] < semi[v] {
+				idom[v] = u
+			} else {
+				idom[v] = p
+			}
+		}
+		bucket[p] = nil
+	}
+	for w := 1; w < n; w++ {
+		if idom[w] != semi[w] {
+			idom[w] = idom[idom[w]]
+		}
+	}
+
+	for w := 1; w < n; w++ {
+		d := nodes[idom[w]]
+		t.Idom[nodes[w]] = d
+		if d != "" {
+			t.children[d] = append(t.children[d], nodes[w])
+		}
+	}
+	// Subtree sizes: children have larger preorder numbers than their
+	// dominators, so accumulate in reverse order
+	for w := n - 1; w >= 1; w-- {
+		t.size[nodes[w]]++
+		if d := nodes[idom[w]]; d != "" {
+			t.size[d] += t.size[nodes[w]]
+		}
+	}
+	return t
+}
+
+// Reachable reports whether node is reachable from a root.
+func (t *DominatorTree) Reachable(node string) bool {
+	_, ok := t.Idom[node]
+	return ok
+}
+
+// Len returns the number of nodes reachable from the roots.
+func (t *DominatorTree) Len() int {
+	return len(t.Idom)
+}
+
+// Dominated returns how many other nodes node dominates: the nodes that
+// can only be reached from the roots through it.
+func (t *DominatorTree) Dominated(node string) int {
+	if t.size[node] == 0 {
+		return 0
+	}
+	return t.size[node] - 1
+}
+
+// DominatorsOf returns the strict dominators of node, outermost first and
+// ending with its immediate dominator.
+func (t *DominatorTree) DominatorsOf(node string) []string {
+	var chain []string
+	for d := t.Idom[node]; d != ""; d = t.Idom[d] {
+		chain = append(chain, d)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// Children returns the nodes node immediately dominates, sorted.
+func (t *DominatorTree) Children(node string) []string {
+	children := append([]string(nil), t.children[node]...)
+	sort.Strings(children)
+	return children
+}
+
+// Bridge is an edge whose removal disconnects the undirected graph.
+type Bridge struct {
+	From, To string
+	// Smaller is the number of nodes on the smaller side of the cut.
+	Smaller int
+}
+
+// CutAnalysis holds the articulation points and bridges of the graph with
+// edge directions ignored.
+type CutAnalysis struct {
+	// Articulation maps each articulation point to the number of nodes cut
+	// off from the rest of its component when it is removed: the nodes not
+	// in the largest remaining piece.
+	Articulation map[string]int
+
+	// Bridges are sorted by Smaller descending.
+	Bridges []Bridge
+}
+
+// CutVertices finds the articulation points and bridges of the undirected
+// view of the graph with Tarjan's low-link DFS. Parallel and opposite edges
+// between the same nodes count as more than one edge, so they are never
+// bridges.
+func (g *Graph) CutVertices() *CutAnalysis {
+	// Undirected adjacency with edge IDs so that only the tree edge back to
+	// the parent is skipped, not a parallel edge
+	type arc struct{ to, id int }
+	nodes := g.Nodes()
+	sort.Strings(nodes)
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+	adj := make([][]arc, len(nodes))
+	id := 0
+	for _, from := range nodes {
+		for _, to := range g.Edges[from] {
+			u, v := index[from], index[to]
+			if u == v {
+				continue
+			}
+			adj[u] = append(adj[u], arc{v, id})
+			adj[v] = append(adj[v], arc{u, id})
+			id++
+		}
+	}
+
+	result := &CutAnalysis{Articulation: make(map[string]int)}
+	disc := make([]int, len(nodes))
+	low := make([]int, len(nodes))
+	size := make([]int, len(nodes))
+	for i := range disc {
+		disc[i] = -1
+	}
+	type frame struct {
+		node, parentEdge, next int
+		cut                    []int // sizes of child subtrees that low-link cannot leave
+		children               int
+	}
+	timer := 0
+	for start := range nodes {
+		if disc[start] >= 0 {
+			continue
+		}
+		componentStart := timer
+		disc[start], low[start], size[start] = timer, timer, 1
+		timer++
+		stack := []*frame{{node: start, parentEdge: -1}}
+		var finished []*frame
+		for len(stack) > 0 {
+			f := stack[len(stack)-1]
+			if f.next < len(adj[f.node]) {
+				a := adj[f.node][f.next]
+				f.next++
+				if a.id == f.parentEdge {
+					continue
+				}
+				if disc[a.to] >= 0 {
+					low[f.node] = min(low[f.node], disc[a.to])
+					continue
+				}
+				disc[a.to], low[a.to], size[a.to] = timer, timer, 1
+				timer++
+				f.children++
+				stack = append(stack, &frame{node: a.to, parentEdge: a.id})
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			finished = append(finished, f)
+			if len(stack) == 0 {
+				break
+			}
+			p := stack[len(stack)-1]
+			low[p.node] = min(low[p.node], low[f.node])
+			size[p.node] += size[f.node]
+			if low[f.node] >= disc[p.node] {
+				p.cut = append(p.cut, size[f.node])
+			}
+		}
+
+		total := timer - componentStart
+		for _, f := range finished {
+			node := nodes[f.node]
+			isRoot := f.parentEdge < 0
+			if (isRoot && f.children > 1) || (!isRoot && len(f.cut) > 0) {
+				// The pieces are the cut-off child subtrees and, for a
+				// non-root, the rest of the component
+				largest, sum := 0, 0
+				for _, s := range f.cut {
+					largest = max(largest, s)
+					sum += s
+				}
+				if !isRoot {
+					rest := total - 1 - sum
+					largest = max(largest, rest)
+					sum += rest
+				}
+				result.Articulation[node] = sum - largest
+			}
+		}
+		// Bridges: tree edges whose child cannot reach above itself
+		for _, f := range finished {
+			if f.parentEdge < 0 || low[f.node] < disc[f.node] {
+				continue
+			}
+			var parent string
+			for _, a := range adj[f.node] {
+				if a.id == f.parentEdge {
+					parent = nodes[a.to]
+				}
+			}
+			child := nodes[f.node]
+			from, to := parent, child
+			if !g.hasEdge(parent, child) {
+				from, to = child, parent
+			}
+			result.Bridges = append(result.Bridges, Bridge{From: from, To: to, Smaller: min(size[f.node], total-size[f.node])})
+		}
+	}
+	sort.Slice(result.Bridges, func(i, j int) bool {
+		a, b := result.Bridges[i], result.Bridges[j]
+		if a.Smaller != b.Smaller {
+			return a.Smaller > b.Smaller
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return result
+}
+
+// hasEdge reports whether from depends on to
+func (g *Graph) hasEdge(from, to string) bool {
+	for _, t := range g.Edges[from] {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
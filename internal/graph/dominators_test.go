@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDominators(t *testing.T) {
+	g := newTestGraph()
+	// main reaches db only through c; x is unreachable
+	g.addEdge("main", "a")
+	g.addEdge("main", "b")
+	g.addEdge("a", "c")
+	g.addEdge("b", "c")
+	g.addEdge("c", "d")
+	g.addEdge("c", "db")
+	g.addEdge("d", "db")
+	g.addEdge("x", "db")
+
+	dt := g.Dominators([]string{"main"})
+	want := map[string]string{"main": "", "a": "main", "b": "main", "c": "main", "d": "c", "db": "c"}
+	if !reflect.DeepEqual(dt.Idom, want) {
+		t.Errorf("Idom = %v, want %v", dt.Idom, want)
+	}
+	if dt.Reachable("x") {
+		t.Error("x is not reachable from main")
+	}
+	if got := dt.DominatorsOf("db"); !reflect.DeepEqual(got, []string{"main", "c"}) {
+		t.Errorf("DominatorsOf(db) = %v, want [main c]", got)
+	}
+	if got := dt.Dominated("c"); got != 2 {
+		t.Errorf("Dominated(c) = %d, want 2", got)
+	}
+	if got := dt.Children("main"); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Children(main) = %v", got)
+	}
+
+	// A second root that reaches c directly: main no longer dominates it
+	g.addEdge("cli", "c")
+	dt = g.Dominators([]string{"main", "cli", "missing"})
+	if dt.Idom["c"] != "" || dt.Idom["db"] != "c" {
+		t.Errorf("with two roots Idom = %v", dt.Idom)
+	}
+	if !reflect.DeepEqual(dt.Roots, []string{"main", "cli"}) {
+		t.Errorf("Roots = %v, want the roots in the graph", dt.Roots)
+	}
+}
+
+// TestDominators_Naive compares Lengauer-Tarjan with the definition on
+// random graphs: d dominates n when n is unreachable from the roots once d
+// is removed.
+func TestDominators_Naive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		g := newTestGraph()
+		n := 4 + rng.Intn(12)
+		names := make([]string, n)
+		for i := range names {
+			names[i] = "n" + itoa(i)
+			g.addEdge(names[i], names[i])
+		}
+		for e := 0; e < n*2; e++ {
+			g.addEdge(names[rng.Intn(n)], names[rng.Intn(n)])
+		}
+		roots := []string{names[0], names[1]}
+		dt := g.Dominators(roots)
+
+		reach := func(skip string) map[string]bool {
+			seen := map[string]bool{}
+			var stack []string
+			for _, r := range roots {
+				if r != skip {
+					seen[r] = true
+					stack = append(stack, r)
+				}
+			}
+			for len(stack) > 0 {
+				v := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, w := range g.Edges[v] {
+					if w != skip && !seen[w] {
+						seen[w] = true
+						stack = append(stack, w)
+					}
+				}
+			}
+			return seen
+		}
+		all := reach("")
+		for v := range all {
+			want := map[string]bool{}
+			for _, d := range names {
+				if d != v && all[d] && !reach(d)[v] {
+					want[d] = true
+				}
+			}
+			got := map[string]bool{}
+			for _, d := range dt.DominatorsOf(v) {
+				got[d] = true
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("round %d: dominators of %s = %v, want %v (edges %v)", round, v, got, want, g.Edges)
+			}
+		}
+		if dt.Len() != len(all) {
+			t.Fatalf("round %d: %d reachable, want %d", round, dt.Len(), len(all))
+		}
+	}
+}
+
+func TestCutVertices(t *testing.T) {
+	g := newTestGraph()
+	// a - b - c, then the triangle c - d - e; x <-> y is a double edge
+	g.addEdge("a", "b")
+	g.addEdge("b", "c")
+	g.addEdge("c", "d")
+	g.addEdge("d", "e")
+	g.addEdge("e", "c")
+	g.addEdge("x", "y")
+	g.addEdge("y", "x")
+
+	cuts := g.CutVertices()
+	want := map[string]int{"b": 1, "c": 2}
+	if !reflect.DeepEqual(cuts.Articulation, want) {
+		t.Errorf("Articulation = %v, want %v", cuts.Articulation, want)
+	}
+	wantBridges := []Bridge{{From: "b", To: "c", Smaller: 2}, {From: "a", To: "b", Smaller: 1}}
+	if !reflect.DeepEqual(cuts.Bridges, wantBridges) {
+		t.Errorf("Bridges = %v, want %v", cuts.Bridges, wantBridges)
+	}
+}
+
+// TestCutVertices_Naive checks articulation points against the definition:
+// removing one splits its component.
+func TestCutVertices_Naive(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for round := 0; round < 50; round++ {
+		g := newTestGraph()
+		n := 4 + rng.Intn(10)
+		for e := 0; e < n+rng.Intn(n); e++ {
+			g.addEdge("n"+itoa(rng.Intn(n)), "n"+itoa(rng.Intn(n)))
+		}
+		components := func(skip string) int {
+			seen := map[string]bool{}
+			count := 0
+			for _, start := range g.Nodes() {
+				if start == skip || seen[start] {
+					continue
+				}
+				count++
+				stack := []string{start}
+				seen[start] = true
+				for len(stack) > 0 {
+					v := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					for _, w := range append(g.Successors(v), g.Predecessors(v)...) {
+						if w != skip && !seen[w] {
+							seen[w] = true
+							stack = append(stack, w)
+						}
+					}
+				}
+			}
+			return count
+		}
+		base := components("")
+		cuts := g.CutVertices()
+		for _, v := range g.Nodes() {
+			// Removing an isolated node drops a component instead
+			_, got := cuts.Articulation[v]
+			want := components(v) > base
+			if got != want {
+				t.Fatalf("round %d: %s articulation = %v, want %v (edges %v)", round, v, got, want, g.Edges)
+			}
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package graph
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anthropics/cx/internal/store"
+)
+
+// Export formats supported by WriteExport.
+const (
+	ExportGraphML  = "graphml"
+	ExportGEXF     = "gexf"
+	ExportDOT      = "dot"
+	ExportNeo4jCSV = "neo4j-csv"
+)
+
+// ExportFormats lists the formats accepted by cx export graph.
+var ExportFormats = []string{ExportGraphML, ExportGEXF, ExportDOT, ExportNeo4jCSV}
+
+// ExportFilter selects the part of the graph to export. Zero values match everything.
+type ExportFilter struct {
+	PathPrefix  string   // only entities whose file path starts with this
+	Language    string   // only entities in this language
+	DepTypes    []string // only edges of these types
+	MinPageRank float64  // only entities with at least this PageRank
+}
+
+// ExportNode is an entity with everything known about it.
+type ExportNode struct {
+	Entity   *store.Entity
+	Metrics  *store.Metrics        // nil if metrics have not been computed
+	Coverage *store.EntityCoverage // nil if no coverage has been imported
+	Tags     []string
+}
+
+// ExportGraph is the subgraph written by the exporters: active entities that
+// pass the filter and the dependencies between them.
+type ExportGraph struct {
+	Nodes []*ExportNode
+	Edges []*store.Dependency
+}
+
+// BuildExport collects the entities, metrics, coverage, tags and
+// dependencies selected by filter. Nodes are ordered by ID and edges by
+// (from, to, type) so exports are stable across runs.
+func BuildExport(s store.Store, filter ExportFilter) (*ExportGraph, error) {
+	entities, err := s.QueryEntities(store.EntityFilter{
+		Status:   "active",
+		FilePath: filter.PathPrefix,
+		Language: filter.Language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+
+	metrics, err := s.GetAllMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("get metrics: %w", err)
+	}
+	metricsByID := make(map[string]*store.Metrics, len(metrics))
+	for _, m := range metrics {
+		metricsByID[m.EntityID] = m
+	}
+
+	coverage, err := s.GetAllCoverage()
+	if err != nil {
+		return nil, fmt.Errorf("get coverage: %w", err)
+	}
+	coverageByID := make(map[string]*store.EntityCoverage, len(coverage))
+	for _, c := range coverage {
+		coverageByID[c.EntityID] = c
+	}
+
+	tags, err := s.GetAllTagsWithEntity()
+	if err != nil {
+		return nil, fmt.Errorf("get tags: %w", err)
+	}
+	tagsByID := make(map[string][]string)
+	for _, t := range tags {
+		tagsByID[t.EntityID] = append(tagsByID[t.EntityID], t.Tag)
+	}
+
+	g := &ExportGraph{}
+	included := make(map[string]bool)
+	for _, e := range entities {
+		m := metricsByID[e.ID]
+		if filter.MinPageRank > 0 && (m == nil || m.PageRank < filter.MinPageRank) {
+			continue
+		}
+		g.Nodes = append(g.Nodes, &ExportNode{
+			Entity:   e,
+			Metrics:  m,
+			Coverage: coverageByID[e.ID],
+			Tags:     tagsByID[e.ID],
+		})
+		included[e.ID] = true
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].Entity.ID < g.Nodes[j].Entity.ID })
+
+	deps, err := s.GetAllDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("get dependencies: %w", err)
+	}
+	depTypes := make(map[string]bool)
+	for _, t := range filter.DepTypes {
+		depTypes[t] = true
+	}
+	for _, d := range deps {
+		if !included[d.FromID] || !included[d.ToID] {
+			continue
+		}
+		if len(depTypes) > 0 && !depTypes[d.DepType] {
+			continue
+		}
+		g.Edges = append(g.Edges, d)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.FromID != b.FromID {
+			return a.FromID < b.FromID
+		}
+		if a.ToID != b.ToID {
+			return a.ToID < b.ToID
+		}
+		return a.DepType < b.DepType
+	})
+	return g, nil
+}
+
+// exportAttr is a node attribute written by every format.
+type exportAttr struct {
+	name  string
+	kind  string // "string", "int", "double"
+	value func(n *ExportNode) (string, bool)
+}
+
+// nodeAttrs lists node attributes in output order. value reports false when
+// the attribute is unknown for a node (no metrics, no coverage), so formats
+// can leave it out rather than write a misleading zero.
+var nodeAttrs = []exportAttr{
+	{"name", "string", func(n *ExportNode) (string, bool) { return n.Entity.Name, true }},
+	{"type", "string", func(n *ExportNode) (string, bool) { return n.Entity.EntityType, true }},
+	{"kind", "string", func(n *ExportNode) (string, bool) { return n.Entity.Kind, n.Entity.Kind != "" }},
+	{"file", "string", func(n *ExportNode) (string, bool) { return n.Entity.FilePath, true }},
+	{"line_start", "int", func(n *ExportNode) (string, bool) { return strconv.Itoa(n.Entity.LineStart), true }},
+	{"line_end", "int", func(n *ExportNode) (string, bool) {
+		if n.Entity.LineEnd == nil {
+			return "", false
+		}
+		return strconv.Itoa(*n.Entity.LineEnd), true
+	}},
+	{"language", "string", func(n *ExportNode) (string, bool) { return n.Entity.Language, true }},
+	{"signature", "string", func(n *ExportNode) (string, bool) { return n.Entity.Signature, n.Entity.Signature != "" }},
+	{"visibility", "string", func(n *ExportNode) (string, bool) { return n.Entity.Visibility, n.Entity.Visibility != "" }},
+	{"pagerank", "double", func(n *ExportNode) (string, bool) {
+		if n.Metrics == nil {
+			return "", false
+		}
+		return formatFloat(n.Metrics.PageRank), true
+	}},
+	{"betweenness", "double", func(n *ExportNode) (string, bool) {
+		if n.Metrics == nil {
+			return "", false
+		}
+		return formatFloat(n.Metrics.Betweenness), true
+	}},
+	{"in_degree", "int", func(n *ExportNode) (string, bool) {
+		if n.Metrics == nil {
+			return "", false
+		}
+		return strconv.Itoa(n.Metrics.InDegree), true
+	}},
+	{"out_degree", "int", func(n *ExportNode) (string, bool) {
+		if n.Metrics == nil {
+			return "", false
+		}
+		return strconv.Itoa(n.Metrics.OutDegree), true
+	}},
+	{"coverage", "double", func(n *ExportNode) (string, bool) {
+		if n.Coverage == nil {
+			return "", false
+		}
+		return formatFloat(n.Coverage.CoveragePercent), true
+	}},
+	{"tags", "string", func(n *ExportNode) (string, bool) { return strings.Join(n.Tags, ","), len(n.Tags) > 0 }},
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// WriteExport writes g to w in the given format. The neo4j-csv format needs
+// two files; use WriteNeo4jCSV for it.
+func WriteExport(w io.Writer, g *ExportGraph, format string) error {
+	switch format {
+	case ExportGraphML:
+		return WriteGraphML(w, g)
+	case ExportGEXF:
+		return WriteGEXF(w, g)
+	case ExportDOT:
+		return WriteDOT(w, g)
+	case ExportNeo4jCSV:
+		return fmt.Errorf("%s writes a nodes file and a relationships file; use WriteNeo4jCSV", format)
+	default:
+		return fmt.Errorf("unknown export format %q (valid: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// xmlEscape escapes s for use in XML text or attribute values.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// WriteGraphML writes g as GraphML, readable by yEd, Gephi, Cytoscape and NetworkX.
+func WriteGraphML(w io.Writer, g *ExportGraph) error {
+	ew := &errWriter{w: w}
+	ew.printf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	ew.printf("<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	for _, a := range nodeAttrs {
+		ew.printf("  <key id=\"%s\" for=\"node\" attr.name=\"%s\" attr.type=\"%s\"/>\n", a.name, a.name, a.kind)
+	}
+	ew.printf("  <key id=\"dep_type\" for=\"edge\" attr.name=\"dep_type\" attr.type=\"string\"/>\n")
+	ew.printf("  <key id=\"optional\" for=\"edge\" attr.name=\"optional\" attr.type=\"boolean\"/>\n")
+	ew.printf("  <key id=\"source\" for=\"edge\" attr.name=\"source\" attr.type=\"string\"/>\n")
+	ew.printf("  <graph id=\"cx\" edgedefault=\"directed\">\n")
+	for _, n := range g.Nodes {
+		ew.printf("    <node id=\"%s\">\n", xmlEscape(n.Entity.ID))
+		for _, a := range nodeAttrs {
+			if v, ok := a.value(n); ok {
+				ew.printf("      <data key=\"%s\">%s</data>\n", a.name, xmlEscape(v))
+			}
+		}
+		ew.printf("    </node>\n")
+	}
+	for i, e := range g.Edges {
+		ew.printf("    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, xmlEscape(e.FromID), xmlEscape(e.ToID))
+		ew.printf("      <data key=\"dep_type\">%s</data>\n", xmlEscape(e.DepType))
+		ew.printf("      <data key=\"optional\">%t</data>\n", e.Optional)
+		ew.printf("      <data key=\"source\">%s</data>\n", xmlEscape(e.Source))
+		ew.printf("    </edge>\n")
+	}
+	ew.printf("  </graph>\n</graphml>\n")
+	return ew.err
+}
+
+// WriteGEXF writes g as GEXF 1.3, Gephi's native format.
+func WriteGEXF(w io.Writer, g *ExportGraph) error {
+	gexfType := map[string]string{"string": "string", "int": "integer", "double": "double"}
+
+	ew := &errWriter{w: w}
+	ew.printf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	ew.printf("<gexf xmlns=\"http://gexf.net/1.3\" version=\"1.3\">\n")
+	ew.printf("  <meta><creator>cx</creator></meta>\n")
+	ew.printf("  <graph mode=\"static\" defaultedgetype=\"directed\">\n")
+	ew.printf("    <attributes class=\"node\">\n")
+	for i, a := range nodeAttrs {
+		ew.printf("      <attribute id=\"%d\" title=\"%s\" type=\"%s\"/>\n", i, a.name, gexfType[a.kind])
+	}
+	ew.printf("    </attributes>\n")
+	ew.printf("    <attributes class=\"edge\">\n")
+	ew.printf("      <attribute id=\"0\" title=\"dep_type\" type=\"string\"/>\n")
+	ew.printf("      <attribute id=\"1\" title=\"optional\" type=\"boolean\"/>\n")
+	ew.printf("      <attribute id=\"2\" title=\"source\" type=\"string\"/>\n")
+	ew.printf("    </attributes>\n")
+
+	ew.printf("    <nodes>\n")
+	for _, n := range g.Nodes {
+		ew.printf("      <node id=\"%s\" label=\"%s\">\n", xmlEscape(n.Entity.ID), xmlEscape(n.Entity.Name))
+		ew.printf("        <attvalues>\n")
+		for i, a := range nodeAttrs {
+			if v, ok := a.value(n); ok {
+				ew.printf("          <attvalue for=\"%d\" value=\"%s\"/>\n", i, xmlEscape(v))
+			}
+		}
+		ew.printf("        </attvalues>\n")
+		ew.printf("      </node>\n")
+	}
+	ew.printf("    </nodes>\n")
+
+	ew.printf("    <edges>\n")
+	for i, e := range g.Edges {
+		ew.printf("      <edge id=\"%d\" source=\"%s\" target=\"%s\" label=\"%s\">\n",
+			i, xmlEscape(e.FromID), xmlEscape(e.ToID), xmlEscape(e.DepType))
+		ew.printf("        <attvalues>\n")
+		ew.printf("          <attvalue for=\"0\" value=\"%s\"/>\n", xmlEscape(e.DepType))
+		ew.printf("          <attvalue for=\"1\" value=\"%t\"/>\n", e.Optional)
+		ew.printf("          <attvalue for=\"2\" value=\"%s\"/>\n", xmlEscape(e.Source))
+		ew.printf("        </attvalues>\n")
+		ew.printf("      </edge>\n")
+	}
+	ew.printf("    </edges>\n")
+	ew.printf("  </graph>\n</gexf>\n")
+	return ew.err
+}
+
+// dotQuote returns s as a quoted Graphviz ID.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// WriteDOT writes g in Graphviz DOT. Attributes are written as DOT
+// attributes, so tools such as NetworkX's read_dot keep them; optional
+// edges are dashed.
+func WriteDOT(w io.Writer, g *ExportGraph) error {
+	ew := &errWriter{w: w}
+	ew.printf("digraph cx {\n")
+	ew.printf("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{"label=" + dotQuote(n.Entity.Name)}
+		for _, a := range nodeAttrs {
+			if v, ok := a.value(n); ok && a.name != "name" {
+				attrs = append(attrs, a.name+"="+dotQuote(v))
+			}
+		}
+		ew.printf("  %s [%s];\n", dotQuote(n.Entity.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		attrs := "dep_type=" + dotQuote(e.DepType) + ", source=" + dotQuote(e.Source)
+		if e.Optional {
+			attrs += `, optional="true", style=dashed`
+		}
+		ew.printf("  %s -> %s [%s];\n", dotQuote(e.FromID), dotQuote(e.ToID), attrs)
+	}
+	ew.printf("}\n")
+	return ew.err
+}
+
+// neo4jType maps attribute kinds to neo4j-admin import header types.
+var neo4jType = map[string]string{"string": "", "int": ":int", "double": ":double"}
+
+// WriteNeo4jCSV writes g in the CSV layout read by `neo4j-admin database
+// import`: one file of nodes, labelled Entity plus their entity type, and one
+// of relationships, typed by the upper-cased dependency type (CALLS,
+// USES_TYPE, ...). Tags are a string array separated by ';'.
+func WriteNeo4jCSV(nodes, relationships io.Writer, g *ExportGraph) error {
+	nw := csv.NewWriter(nodes)
+	header := []string{"id:ID"}
+	for _, a := range nodeAttrs {
+		if a.name == "tags" {
+			header = append(header, "tags:string[]")
+			continue
+		}
+		header = append(header, a.name+neo4jType[a.kind])
+	}
+	header = append(header, ":LABEL")
+	nw.Write(header)
+	for _, n := range g.Nodes {
+		row := []string{n.Entity.ID}
+		for _, a := range nodeAttrs {
+			v, ok := a.value(n)
+			if a.name == "tags" {
+				v = strings.Join(n.Tags, ";")
+			}
+			if !ok {
+				v = ""
+			}
+			row = append(row, v)
+		}
+		row = append(row, "Entity;"+neo4jLabel(n.Entity.EntityType))
+		nw.Write(row)
+	}
+	nw.Flush()
+	if err := nw.Error(); err != nil {
+		return err
+	}
+
+	rw := csv.NewWriter(relationships)
+	rw.Write([]string{":START_ID", ":END_ID", ":TYPE", "optional:boolean", "source"})
+	for _, e := range g.Edges {
+		rw.Write([]string{e.FromID, e.ToID, strings.ToUpper(e.DepType), strconv.FormatBool(e.Optional), e.Source})
+	}
+	rw.Flush()
+	return rw.Error()
+}
+
+// neo4jLabel turns an entity type into a node label: "function" -> "Function".
+func neo4jLabel(entityType string) string {
+	if entityType == "" {
+		return "Unknown"
+	}
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(entityType, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// errWriter remembers the first write error so exporters can check once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
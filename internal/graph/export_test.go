@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+)
+
+func newExportStore(t *testing.T) *store.MemoryStore {
+	t.Helper()
+	s := store.NewMemoryStore()
+	s.CreateEntitiesBulk([]*store.Entity{
+		{ID: "fn-login", Name: "Login", EntityType: "function", FilePath: "auth/login.go", LineStart: 10, Language: "go", Status: "active", Visibility: "pub"},
+		{ID: "tp-user", Name: "User", EntityType: "type", Kind: "struct", FilePath: "auth/user.go", LineStart: 3, Language: "go", Status: "active"},
+		{ID: "fn-log", Name: "Log<T>", EntityType: "function", FilePath: "util/log.go", LineStart: 1, Language: "go", Status: "active"},
+		{ID: "fn-old", Name: "Old", EntityType: "function", FilePath: "auth/old.go", LineStart: 1, Language: "go", Status: "archived"},
+	})
+	s.CreateDependenciesBulk([]*store.Dependency{
+		{FromID: "fn-login", ToID: "tp-user", DepType: "uses_type"},
+		{FromID: "fn-login", ToID: "fn-log", DepType: "calls", Optional: true},
+		{FromID: "fn-login", ToID: "fn-old", DepType: "calls"},
+	})
+	s.SaveBulkMetrics([]*store.Metrics{
+		{EntityID: "fn-login", PageRank: 0.2, InDegree: 0, OutDegree: 2},
+		{EntityID: "tp-user", PageRank: 0.5, InDegree: 1},
+		{EntityID: "fn-log", PageRank: 0.05, InDegree: 1},
+	})
+	s.SaveCoverage(&store.EntityCoverage{EntityID: "fn-login", CoveragePercent: 75})
+	s.AddTag("fn-login", "auth", "test")
+	s.AddTag("fn-login", "critical", "test")
+	return s
+}
+
+func TestBuildExport(t *testing.T) {
+	s := newExportStore(t)
+
+	g, err := BuildExport(s, ExportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 3 || len(g.Edges) != 2 {
+		t.Fatalf("got %d nodes, %d edges; want 3 active nodes and the 2 edges between them", len(g.Nodes), len(g.Edges))
+	}
+	if first := g.Nodes[0].Entity.ID; first != "fn-log" {
+		t.Errorf("nodes not sorted by ID: first is %s", first)
+	}
+	if login := g.Nodes[1]; login.Metrics == nil || login.Coverage == nil || len(login.Tags) != 2 {
+		t.Errorf("login node missing metrics, coverage or tags: %+v", login)
+	}
+
+	g, _ = BuildExport(s, ExportFilter{PathPrefix: "auth/"})
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 || g.Edges[0].ToID != "tp-user" {
+		t.Errorf("path filter: got %d nodes, %+v", len(g.Nodes), g.Edges)
+	}
+
+	g, _ = BuildExport(s, ExportFilter{DepTypes: []string{"calls"}})
+	if len(g.Nodes) != 3 || len(g.Edges) != 1 || !g.Edges[0].Optional {
+		t.Errorf("dep type filter: got %d nodes, %+v", len(g.Nodes), g.Edges)
+	}
+
+	g, _ = BuildExport(s, ExportFilter{MinPageRank: 0.1})
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 {
+		t.Errorf("pagerank filter: got %d nodes, %d edges; want 2, 1", len(g.Nodes), len(g.Edges))
+	}
+}
+
+func TestWriteExportXMLFormats(t *testing.T) {
+	g, err := BuildExport(newExportStore(t), ExportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{ExportGraphML, ExportGEXF} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteExport(&buf, g, format); err != nil {
+				t.Fatal(err)
+			}
+			// Must be well-formed XML despite names like Log<T>
+			dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+			for {
+				if _, err := dec.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+				}
+			}
+			out := buf.String()
+			for _, want := range []string{"fn-login", "Log&lt;T&gt;", "uses_type", "0.2", "75", "auth,critical"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("%s output missing %q", format, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g, _ := BuildExport(newExportStore(t), ExportFilter{})
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"fn-login" -> "fn-log" [dep_type="calls", source="treesitter", optional="true", style=dashed];`) {
+		t.Errorf("optional edge not dashed:\n%s", out)
+	}
+	if !strings.Contains(out, `pagerank="0.5"`) {
+		t.Errorf("node metrics missing:\n%s", out)
+	}
+}
+
+func TestWriteNeo4jCSV(t *testing.T) {
+	g, _ := BuildExport(newExportStore(t), ExportFilter{})
+	var nodes, rels bytes.Buffer
+	if err := WriteNeo4jCSV(&nodes, &rels, g); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&nodes).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := rows[0]
+	if header[0] != "id:ID" || header[len(header)-1] != ":LABEL" {
+		t.Errorf("node header = %v", header)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d node rows, want header + 3", len(rows))
+	}
+	login := rows[2]
+	if login[0] != "fn-login" || login[len(login)-1] != "Entity;Function" || login[len(login)-2] != "auth;critical" {
+		t.Errorf("login row = %v", login)
+	}
+
+	rows, err = csv.NewReader(&rels).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[1][2] != "CALLS" || rows[1][3] != "true" || rows[2][2] != "USES_TYPE" {
+		t.Errorf("relationships = %v", rows)
+	}
+}
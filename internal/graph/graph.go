@@ -13,7 +13,7 @@ type Graph struct {
 }
 
 // BuildFromStore loads the dependency graph from the store.
-func BuildFromStore(s *store.Store) (*Graph, error) {
+func BuildFromStore(s store.Store) (*Graph, error) {
 	deps, err := s.GetAllDependencies()
 	if err != nil {
 		return nil, err
@@ -25,7 +25,7 @@ func BuildFromStore(s *store.Store) (*Graph, error) {
 	}
 
 	for _, dep := range deps {
-		if !isCodeDependency(dep.DepType) {
+		if !IsCodeDependency(dep.DepType) {
 			continue
 		}
 
@@ -51,8 +51,9 @@ func BuildFromStore(s *store.Store) (*Graph, error) {
 	return g, nil
 }
 
-// isCodeDependency returns true if the dependency type represents a code relationship
-func isCodeDependency(depType string) bool {
+// IsCodeDependency reports whether the dependency type represents a code relationship,
+// the edges BuildFromStore keeps.
+func IsCodeDependency(depType string) bool {
 	switch depType {
 	case "calls", "uses_type", "imports", "extends", "implements", "references":
 		return true
@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/anthropics/cx/internal/store"
 )
 
 // newTestGraph creates a graph directly for testing without store dependency.
@@ -33,6 +35,26 @@ func (g *Graph) addEdge(from, to string) {
 	g.ReverseEdges[to] = append(g.ReverseEdges[to], from)
 }
 
+func TestBuildFromStore(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.CreateDependenciesBulk([]*store.Dependency{
+		{FromID: "a", ToID: "b", DepType: "calls"},
+		{FromID: "b", ToID: "c", DepType: "uses_type"},
+		{FromID: "a", ToID: "doc-1", DepType: "mentions"}, // not a code dependency
+	})
+
+	g, err := BuildFromStore(s)
+	if err != nil {
+		t.Fatalf("BuildFromStore: %v", err)
+	}
+	if g.NodeCount() != 3 || g.EdgeCount() != 2 {
+		t.Errorf("got %d nodes, %d edges; want 3, 2", g.NodeCount(), g.EdgeCount())
+	}
+	if !reflect.DeepEqual(g.Predecessors("b"), []string{"a"}) {
+		t.Errorf("Predecessors(b) = %v, want [a]", g.Predecessors("b"))
+	}
+}
+
 func TestGraph_NodeCount(t *testing.T) {
 	g := newTestGraph()
 
@@ -219,9 +241,9 @@ func TestIsCodeDependency(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := isCodeDependency(tt.depType)
+		got := IsCodeDependency(tt.depType)
 		if got != tt.want {
-			t.Errorf("isCodeDependency(%q) = %v, want %v", tt.depType, got, tt.want)
+			t.Errorf("IsCodeDependency(%q) = %v, want %v", tt.depType, got, tt.want)
 		}
 	}
 }
@@ -263,3 +263,23 @@ func GeneratePieChart(stats map[string]int, title string) string {
 
 	return sb.String()
 }
+
+// GenerateLineChart generates a Mermaid xychart with one line through the
+// points, labeled on the x axis. yLabel names the metric.
+func GenerateLineChart(points []TrendPoint, title, yLabel string) string {
+	var sb strings.Builder
+	sb.WriteString("xychart-beta\n")
+	if title != "" {
+		sb.WriteString(fmt.Sprintf("    title \"%s\"\n", escapeMermaidString(title)))
+	}
+	labels := make([]string, len(points))
+	values := make([]string, len(points))
+	for i, p := range points {
+		labels[i] = fmt.Sprintf("\"%s\"", escapeMermaidString(p.Label))
+		values[i] = formatTrendValue(p.Value)
+	}
+	sb.WriteString(fmt.Sprintf("    x-axis [%s]\n", strings.Join(labels, ", ")))
+	sb.WriteString(fmt.Sprintf("    y-axis \"%s\"\n", escapeMermaidString(yLabel)))
+	sb.WriteString(fmt.Sprintf("    line [%s]\n", strings.Join(values, ", ")))
+	return sb.String()
+}
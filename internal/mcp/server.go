@@ -20,6 +20,7 @@ import (
 	"github.com/anthropics/cx/internal/graph"
 	"github.com/anthropics/cx/internal/parser"
 	"github.com/anthropics/cx/internal/store"
+	"github.com/anthropics/cx/internal/tokens"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -32,14 +33,16 @@ func hasStringSuffix(s, suffix string) bool {
 // Server wraps the MCP server with cx-specific functionality
 type Server struct {
 	mcpServer    *server.MCPServer
-	store        *store.Store
+	store        store.Store
 	graph        *graph.Graph
 	cxDir        string
 	projectRoot  string
 	tools        map[string]bool
+	tokens       tokens.Counter
 	lastActivity time.Time
 	timeout      time.Duration
 	mu           sync.RWMutex
+	sessionMu    sync.Mutex // serializes context session updates
 }
 
 // Config holds server configuration
@@ -90,6 +93,7 @@ func New(cfg Config) (*Server, error) {
 		cxDir:        cxDir,
 		projectRoot:  projectRoot,
 		tools:        make(map[string]bool),
+		tokens:       tokens.Default(),
 		lastActivity: time.Now(),
 		timeout:      cfg.Timeout,
 	}
@@ -245,6 +249,10 @@ var toolSchemaRegistry = map[string]ToolSchema{
 			{Name: "target", Type: "string", Description: "Entity ID, file path, or bead ID for direct context"},
 			{Name: "budget", Type: "number", Description: "Token budget (default: 4000)"},
 			{Name: "depth", Type: "number", Description: "Max hops from entry points (default: 2)"},
+			{Name: "pack", Type: "boolean", Description: "Pack source with smart: full bodies, signatures or skeletons by relevance per token"},
+			{Name: "expand", Type: "string", Description: "Comma-separated entity names or IDs to pack with full bodies (implies pack)"},
+			{Name: "session", Type: "string", Description: "Session ID: later calls send only entities new or changed since, and list the rest as already provided"},
+			{Name: "session_reset", Type: "boolean", Description: "Forget what the session delivered, e.g. after your context was cleared"},
 		},
 	},
 	"cx_show": {
@@ -289,6 +297,7 @@ var toolSchemaRegistry = map[string]ToolSchema{
 			{Name: "path", Type: "string", Description: "Subdirectory to map (default: project root)"},
 			{Name: "filter", Type: "string", Description: "Filter by entity type: F (function), T (type), M (method), C (constant)"},
 			{Name: "lang", Type: "string", Description: "Filter by language (go, typescript, python, rust, java)"},
+			{Name: "max_tokens", Type: "number", Description: "Token budget: keep whole files in path order while they fit (default: no limit)"},
 		},
 	},
 	"cx_blame": {
@@ -412,7 +421,11 @@ func (s *Server) CallTool(name string, args map[string]interface{}) (string, err
 		if d, ok := args["depth"].(float64); ok {
 			depth = int(d)
 		}
-		return s.executeContext(smart, target, budget, depth)
+		pack, _ := args["pack"].(bool)
+		expand, _ := args["expand"].(string)
+		session, _ := args["session"].(string)
+		sessionReset, _ := args["session_reset"].(bool)
+		return s.executeContext(smart, target, budget, depth, pack, expand, session, sessionReset)
 
 	case "cx_show":
 		name, _ := args["name"].(string)
@@ -462,7 +475,11 @@ func (s *Server) CallTool(name string, args map[string]interface{}) (string, err
 		path, _ := args["path"].(string)
 		filter, _ := args["filter"].(string)
 		lang, _ := args["lang"].(string)
-		return s.executeMap(path, filter, lang)
+		maxTokens := 0
+		if m, ok := args["max_tokens"].(float64); ok {
+			maxTokens = int(m)
+		}
+		return s.executeMap(path, filter, lang, maxTokens)
 
 	case "cx_blame":
 		entity, _ := args["entity"].(string)
@@ -599,6 +616,18 @@ func (s *Server) registerContextTool() error {
 		mcp.WithNumber("depth",
 			mcp.Description("Max hops from entry points (default: 2)"),
 		),
+		mcp.WithBoolean("pack",
+			mcp.Description("Pack source with smart: full bodies, signatures or skeletons by relevance per token"),
+		),
+		mcp.WithString("expand",
+			mcp.Description("Comma-separated entity names or IDs to pack with full bodies (implies pack)"),
+		),
+		mcp.WithString("session",
+			mcp.Description("Session ID: later calls send only entities new or changed since, and list the rest as already provided"),
+		),
+		mcp.WithBoolean("session_reset",
+			mcp.Description("Forget what the session delivered, e.g. after your context was cleared"),
+		),
 	)
 
 	s.mcpServer.AddTool(tool, s.handleContext)
@@ -694,6 +723,9 @@ func (s *Server) registerMapTool() error {
 		mcp.WithString("lang",
 			mcp.Description("Filter by language (go, typescript, python, rust, java)"),
 		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Token budget: keep whole files in path order while they fit (default: no limit)"),
+		),
 	)
 
 	s.mcpServer.AddTool(tool, s.handleMap)
@@ -761,7 +793,12 @@ func (s *Server) handleContext(ctx context.Context, req mcp.CallToolRequest) (*m
 		depth = int(d)
 	}
 
-	result, err := s.executeContext(smart, target, budget, depth)
+	pack, _ := args["pack"].(bool)
+	expand, _ := args["expand"].(string)
+	session, _ := args["session"].(string)
+	sessionReset, _ := args["session_reset"].(bool)
+
+	result, err := s.executeContext(smart, target, budget, depth, pack, expand, session, sessionReset)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -867,8 +904,12 @@ func (s *Server) handleMap(ctx context.Context, req mcp.CallToolRequest) (*mcp.C
 	path, _ := args["path"].(string)
 	filter, _ := args["filter"].(string)
 	lang, _ := args["lang"].(string)
+	maxTokens := 0
+	if m, ok := args["max_tokens"].(float64); ok {
+		maxTokens = int(m)
+	}
 
-	result, err := s.executeMap(path, filter, lang)
+	result, err := s.executeMap(path, filter, lang, maxTokens)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -1023,19 +1064,51 @@ func (s *Server) executeImpact(target string, depth int, threshold float64) (str
 	return toJSON(result)
 }
 
-func (s *Server) executeContext(smart, target string, budget, depth int) (string, error) {
+func (s *Server) executeContext(smart, target string, budget, depth int, pack bool, expand, session string, sessionReset bool) (string, error) {
+	if session != "" && smart == "" {
+		return "", fmt.Errorf("session requires smart")
+	}
 	if smart != "" {
+		var expandList []string
+		for _, name := range strings.Split(expand, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				expandList = append(expandList, name)
+			}
+		}
+
+		var sess *cxcontext.Session
+		if session != "" {
+			s.sessionMu.Lock()
+			defer s.sessionMu.Unlock()
+			var err error
+			if sess, err = cxcontext.OpenSession(s.cxDir, session); err != nil {
+				return "", err
+			}
+			if sessionReset {
+				sess.Reset()
+			}
+		}
+
 		// Use smart context assembly
 		assembler := cxcontext.NewSmartContext(s.store, s.graph, cxcontext.SmartContextOptions{
 			TaskDescription: smart,
 			Budget:          budget,
 			Depth:           depth,
+			Tokenizer:       s.tokens,
+			Pack:            pack || len(expandList) > 0,
+			Expand:          expandList,
+			Session:         sess,
 		})
 
 		result, err := assembler.Assemble()
 		if err != nil {
 			return "", fmt.Errorf("smart context assembly failed: %w", err)
 		}
+		if sess != nil {
+			if err := sess.Save(); err != nil {
+				return "", fmt.Errorf("failed to save session: %w", err)
+			}
+		}
 
 		return toJSON(result)
 	}
@@ -1589,7 +1662,7 @@ func (s *Server) buildRecommendations(riskLevel string, coverageGaps, keystoneCo
 	return recs
 }
 
-func (s *Server) executeMap(path, filter, lang string) (string, error) {
+func (s *Server) executeMap(path, filter, lang string, maxTokens int) (string, error) {
 	// Build filter from parameters
 	queryFilter := store.EntityFilter{
 		Status: "active",
@@ -1619,6 +1692,7 @@ func (s *Server) executeMap(path, filter, lang string) (string, error) {
 
 	// Group entities by file
 	fileMap := make(map[string][]map[string]interface{})
+	fileText := make(map[string]string)
 
 	for _, e := range entities {
 		skeleton := e.Skeleton
@@ -1638,13 +1712,38 @@ func (s *Server) executeMap(path, filter, lang string) (string, error) {
 		}
 
 		fileMap[e.FilePath] = append(fileMap[e.FilePath], entry)
+		fileText[e.FilePath] += skeleton + "\n\n"
 	}
 
-	return toJSON(map[string]interface{}{
-		"files": fileMap,
-		"count": len(entities),
-		"path":  path,
-	})
+	// Keep whole files, in path order, while their skeletons fit the budget
+	paths := make([]string, 0, len(fileMap))
+	for p := range fileMap {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	used, count, omitted := 0, 0, 0
+	for _, p := range paths {
+		cost := s.tokens.Count("// " + p + "\n" + fileText[p])
+		if maxTokens > 0 && used+cost > maxTokens {
+			omitted += len(fileMap[p])
+			delete(fileMap, p)
+			continue
+		}
+		used += cost
+		count += len(fileMap[p])
+	}
+
+	result := map[string]interface{}{
+		"files":     fileMap,
+		"count":     count,
+		"path":      path,
+		"tokens":    used,
+		"tokenizer": s.tokens.Name(),
+	}
+	if omitted > 0 {
+		result["omitted"] = omitted
+	}
+	return toJSON(result)
 }
 
 // --- cx_blame: entity commit history ---
@@ -2339,18 +2438,18 @@ func (s *Server) executeTest(mode, file, diff string, depth int, keystonesOnly b
 }
 
 func (s *Server) executeTestGaps(keystonesOnly bool, threshold float64) (string, error) {
-	// Query entities with coverage data from entity_coverage table
-	rows, err := s.store.DB().Query(`
-		SELECT e.id, e.name, e.entity_type, e.file_path, e.line_start, e.visibility,
-			COALESCE(c.coverage_percent, -1) as coverage_pct
-		FROM entities e
-		LEFT JOIN entity_coverage c ON e.id = c.entity_id
-		WHERE e.status = 'active'
-		ORDER BY COALESCE(c.coverage_percent, -1) ASC`)
+	entities, err := s.store.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return "", fmt.Errorf("query entities: %w", err)
+	}
+	coverages, err := s.store.GetAllCoverage()
 	if err != nil {
 		return "", fmt.Errorf("query coverage: %w", err)
 	}
-	defer rows.Close()
+	coverageByID := make(map[string]float64, len(coverages))
+	for _, c := range coverages {
+		coverageByID[c.EntityID] = c.CoveragePercent
+	}
 
 	type gapEntry struct {
 		ID          string
@@ -2363,17 +2462,27 @@ func (s *Server) executeTestGaps(keystonesOnly bool, threshold float64) (string,
 	}
 
 	var gaps []gapEntry
-	for rows.Next() {
-		var g gapEntry
-		if err := rows.Scan(&g.ID, &g.Name, &g.Type, &g.FilePath, &g.LineStart, &g.Visibility, &g.CoveragePct); err != nil {
-			continue
+	for _, e := range entities {
+		pct, ok := coverageByID[e.ID]
+		if !ok {
+			pct = -1
 		}
 		// Filter: below threshold or no coverage (-1)
-		if g.CoveragePct >= 0 && g.CoveragePct >= threshold {
+		if pct >= 0 && pct >= threshold {
 			continue
 		}
-		gaps = append(gaps, g)
+		gaps = append(gaps, gapEntry{
+			ID:          e.ID,
+			Name:        e.Name,
+			Type:        e.EntityType,
+			FilePath:    e.FilePath,
+			LineStart:   e.LineStart,
+			Visibility:  e.Visibility,
+			CoveragePct: pct,
+		})
 	}
+	// Least covered first
+	sort.SliceStable(gaps, func(i, j int) bool { return gaps[i].CoveragePct < gaps[j].CoveragePct })
 
 	// If keystones_only, filter to high-importance entities
 	if keystonesOnly {
@@ -2,6 +2,7 @@
 package metrics
 
 import (
+	"math/rand"
 	"sort"
 )
 
@@ -38,71 +39,110 @@ func ComputeBetweenness(graph map[string][]string) map[string]float64 {
 
 	// Brandes algorithm: BFS from each source
 	for source := range graph {
-		// Single-source shortest paths
-		stack := make([]string, 0)
-		pred := make(map[string][]string) // predecessors on shortest paths
-		sigma := make(map[string]float64) // number of shortest paths
-		dist := make(map[string]int)      // distance from source
-
-		for node := range graph {
-			pred[node] = make([]string, 0)
-			sigma[node] = 0.0
-			dist[node] = -1
-		}
+		accumulateBrandes(graph, source, bc)
+	}
 
-		sigma[source] = 1.0
-		dist[source] = 0
-
-		// BFS
-		queue := []string{source}
-		for len(queue) > 0 {
-			v := queue[0]
-			queue = queue[1:]
-			stack = append(stack, v)
-
-			for _, w := range graph[v] {
-				// w found for first time?
-				if dist[w] < 0 {
-					dist[w] = dist[v] + 1
-					queue = append(queue, w)
-				}
-				// shortest path to w via v?
-				if dist[w] == dist[v]+1 {
-					sigma[w] += sigma[v]
-					pred[w] = append(pred[w], v)
-				}
-			}
+	// Normalize by (n-1)(n-2) for directed graph
+	normFactor := float64((n - 1) * (n - 2))
+	if normFactor > 0 {
+		for node := range bc {
+			bc[node] /= normFactor
 		}
+	}
 
-		// Accumulation
-		delta := make(map[string]float64)
-		for node := range graph {
-			delta[node] = 0.0
-		}
+	return bc
+}
+
+// ComputeBetweennessSampled approximates betweenness centrality by running
+// Brandes' single-source step from pivots sources instead of every node and
+// scaling the result by nodes/pivots (Brandes and Pich, 2007). Pivots are
+// drawn with a fixed seed, so repeated runs on the same graph agree. With
+// pivots <= 0 or at least as many pivots as nodes it computes exact
+// betweenness. Scores are normalized like ComputeBetweenness.
+func ComputeBetweennessSampled(graph map[string][]string, pivots int) map[string]float64 {
+	n := len(graph)
+	if pivots <= 0 || pivots >= n || n < 3 {
+		return ComputeBetweenness(graph)
+	}
+
+	bc := make(map[string]float64, n)
+	nodes := make([]string, 0, n)
+	for node := range graph {
+		bc[node] = 0.0
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	rng := rand.New(rand.NewSource(1))
+	rng.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+
+	for _, source := range nodes[:pivots] {
+		accumulateBrandes(graph, source, bc)
+	}
+
+	scale := float64(n) / float64(pivots) / float64((n-1)*(n-2))
+	for node := range bc {
+		bc[node] *= scale
+	}
+	return bc
+}
 
-		// Stack returns vertices in order of non-increasing distance from source
-		for len(stack) > 0 {
-			w := stack[len(stack)-1]
-			stack = stack[:len(stack)-1]
+// accumulateBrandes adds the dependencies of source on every other node,
+// one single-source step of Brandes' algorithm, to bc.
+func accumulateBrandes(graph map[string][]string, source string, bc map[string]float64) {
+	// Single-source shortest paths
+	stack := make([]string, 0)
+	pred := make(map[string][]string) // predecessors on shortest paths
+	sigma := make(map[string]float64) // number of shortest paths
+	dist := make(map[string]int)      // distance from source
 
-			for _, v := range pred[w] {
-				delta[v] += (sigma[v] / sigma[w]) * (1 + delta[w])
+	for node := range graph {
+		pred[node] = make([]string, 0)
+		sigma[node] = 0.0
+		dist[node] = -1
+	}
+
+	sigma[source] = 1.0
+	dist[source] = 0
+
+	// BFS
+	queue := []string{source}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		stack = append(stack, v)
+
+		for _, w := range graph[v] {
+			// w found for first time?
+			if dist[w] < 0 {
+				dist[w] = dist[v] + 1
+				queue = append(queue, w)
 			}
-			if w != source {
-				bc[w] += delta[w]
+			// shortest path to w via v?
+			if dist[w] == dist[v]+1 {
+				sigma[w] += sigma[v]
+				pred[w] = append(pred[w], v)
 			}
 		}
 	}
 
-	// Normalize by (n-1)(n-2) for directed graph
-	normFactor := float64((n - 1) * (n - 2))
-	if normFactor > 0 {
-		for node := range bc {
-			bc[node] /= normFactor
-		}
+	// Accumulation
+	delta := make(map[string]float64)
+	for node := range graph {
+		delta[node] = 0.0
 	}
 
-	return bc
+	// Stack returns vertices in order of non-increasing distance from source
+	for len(stack) > 0 {
+		w := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, v := range pred[w] {
+			delta[v] += (sigma[v] / sigma[w]) * (1 + delta[w])
+		}
+		if w != source {
+			bc[w] += delta[w]
+		}
+	}
 }
 
 // FindBottlenecks returns nodes with betweenness above threshold.
@@ -270,3 +270,44 @@ func TestComputeBetweenness_DirectedGraph(t *testing.T) {
 		t.Errorf("C should have higher betweenness than D: C=%f, D=%f", bc["C"], bc["D"])
 	}
 }
+
+func TestComputeBetweennessSampled(t *testing.T) {
+	// Two clusters joined through a single bridge node
+	graph := make(map[string][]string)
+	for _, prefix := range []string{"a", "b"} {
+		for i := 0; i < 20; i++ {
+			from := prefix + string(rune('a'+i))
+			for j := 0; j < 20; j++ {
+				if i != j {
+					graph[from] = append(graph[from], prefix+string(rune('a'+j)))
+				}
+			}
+		}
+	}
+	graph["aa"] = append(graph["aa"], "bridge")
+	graph["bridge"] = []string{"ba"}
+	graph["ba"] = append(graph["ba"], "bridge")
+	graph["bridge"] = append(graph["bridge"], "aa")
+
+	exact := ComputeBetweenness(graph)
+	if got := ComputeBetweennessSampled(graph, len(graph)); !floatEquals(got["bridge"], exact["bridge"], 1e-12) {
+		t.Errorf("with all pivots got %f, want exact %f", got["bridge"], exact["bridge"])
+	}
+
+	sampled := ComputeBetweennessSampled(graph, 12)
+	top := GetTopByBetweenness(sampled, 3)
+	found := false
+	for _, s := range top {
+		found = found || s.Node == "bridge"
+	}
+	if !found {
+		t.Errorf("bridge not among top sampled nodes: %v", top)
+	}
+	// Same pivots on every run
+	again := ComputeBetweennessSampled(graph, 12)
+	for node, score := range sampled {
+		if again[node] != score {
+			t.Fatalf("sampled betweenness not deterministic for %s", node)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import "sort"
+
+// CoChangeConfig holds the thresholds for co-change mining.
+type CoChangeConfig struct {
+	// MinSupport is the minimum number of change sets two items must share.
+	MinSupport int
+
+	// MinConfidence is the minimum share of one item's change sets that
+	// also contain the other.
+	MinConfidence float64
+
+	// MaxSetSize skips larger change sets: bulk renames, reformats and
+	// vendoring couple everything with everything.
+	MaxSetSize int
+}
+
+// DefaultCoChangeConfig returns the default co-change thresholds.
+func DefaultCoChangeConfig() CoChangeConfig {
+	return CoChangeConfig{
+		MinSupport:    3,
+		MinConfidence: 0.25,
+		MaxSetSize:    30,
+	}
+}
+
+// CoChange is a directed association rule From => To between two items
+// that change together.
+type CoChange struct {
+	From string
+	To   string
+
+	// Support is the number of change sets containing both items.
+	Support int
+
+	// Confidence is Support divided by the number of change sets containing
+	// From: how often a change to From came with a change to To.
+	Confidence float64
+}
+
+// ComputeCoChanges mines co-change rules from change sets, such as the files
+// of each commit or the entities changed by each scan. Both directions of a
+// pair are returned when they pass the thresholds, sorted by From and then
+// by confidence descending. Duplicate items within a set count once.
+func ComputeCoChanges(changeSets [][]string, config CoChangeConfig) []CoChange {
+	changes := make(map[string]int)
+	pairs := make(map[[2]string]int)
+	for _, set := range changeSets {
+		items := uniqueSorted(set)
+		if config.MaxSetSize > 0 && len(items) > config.MaxSetSize {
+			continue
+		}
+		for i, a := range items {
+			changes[a]++
+			for _, b := range items[i+1:] {
+				pairs[[2]string{a, b}]++
+			}
+		}
+	}
+
+	var result []CoChange
+	add := func(from, to string, support int) {
+		confidence := float64(support) / float64(changes[from])
+		if confidence >= config.MinConfidence {
+			result = append(result, CoChange{From: from, To: to, Support: support, Confidence: confidence})
+		}
+	}
+	for pair, support := range pairs {
+		if support < config.MinSupport {
+			continue
+		}
+		add(pair[0], pair[1], support)
+		add(pair[1], pair[0], support)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence > result[j].Confidence
+		}
+		return result[i].To < result[j].To
+	})
+	return result
+}
+
+// uniqueSorted returns the distinct items of set in sorted order
+func uniqueSorted(set []string) []string {
+	items := append([]string(nil), set...)
+	sort.Strings(items)
+	out := items[:0]
+	for i, item := range items {
+		if item == "" || (i > 0 && item == items[i-1]) {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeCoChanges(t *testing.T) {
+	sets := [][]string{
+		{"handler.go", "migration.sql"},
+		{"handler.go", "migration.sql", "README.md"},
+		{"handler.go", "migration.sql"},
+		{"handler.go"},
+		{"README.md", "docs.md"},
+		{"a", "b", "c", "d", "e"}, // too large
+		{"handler.go", "handler.go"},
+	}
+	config := CoChangeConfig{MinSupport: 2, MinConfidence: 0.5, MaxSetSize: 4}
+
+	got := make(map[[2]string]CoChange)
+	for _, c := range ComputeCoChanges(sets, config) {
+		got[[2]string{c.From, c.To}] = c
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d rules, want 2: %v", len(got), got)
+	}
+
+	// handler.go also changed alone, twice
+	h := got[[2]string{"handler.go", "migration.sql"}]
+	if h.Support != 3 || math.Abs(h.Confidence-0.6) > 1e-9 {
+		t.Errorf("handler.go => migration.sql = %+v, want support 3 confidence 0.6", h)
+	}
+	m := got[[2]string{"migration.sql", "handler.go"}]
+	if m.Support != 3 || math.Abs(m.Confidence-1) > 1e-9 {
+		t.Errorf("migration.sql => handler.go = %+v, want support 3 confidence 1", m)
+	}
+}
+
+func TestComputeCoChanges_Confidence(t *testing.T) {
+	// util changes with everything; api rarely needs util
+	sets := [][]string{
+		{"util", "api"},
+		{"util", "api"},
+		{"util", "db"},
+		{"util", "db"},
+		{"util", "cli"},
+		{"util", "cli"},
+	}
+	config := CoChangeConfig{MinSupport: 2, MinConfidence: 0.5}
+
+	rules := ComputeCoChanges(sets, config)
+	for _, c := range rules {
+		if c.From == "util" {
+			t.Errorf("util => %s has confidence %.2f and should be filtered", c.To, c.Confidence)
+		}
+	}
+	if len(rules) != 3 {
+		t.Errorf("got %d rules, want 3 (api, db and cli => util): %v", len(rules), rules)
+	}
+}
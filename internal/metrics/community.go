@@ -0,0 +1,321 @@
+package metrics
+
+import "sort"
+
+// CommunityConfig holds algorithm parameters for community detection.
+type CommunityConfig struct {
+	// Resolution scales the modularity null model. Values above 1 favor
+	// more, smaller communities; below 1 fewer, larger ones. Default is 1.
+	Resolution float64
+
+	// MaxLevels caps how many times communities are merged into a coarser
+	// graph. Default is 10.
+	MaxLevels int
+}
+
+// DefaultCommunityConfig returns the default community detection configuration.
+func DefaultCommunityConfig() CommunityConfig {
+	return CommunityConfig{
+		Resolution: 1.0,
+		MaxLevels:  10,
+	}
+}
+
+// CommunityResult contains the community detection results.
+type CommunityResult struct {
+	// Communities maps node IDs to community numbers. Community 0 is the
+	// largest; ties are broken by the smallest member ID.
+	Communities map[string]int
+
+	// Count is the number of communities
+	Count int
+
+	// Modularity of the partition, from -0.5 to 1; above 0.3 usually
+	// indicates meaningful structure
+	Modularity float64
+
+	// Levels is the number of aggregation levels performed
+	Levels int
+}
+
+// DetectCommunities partitions the graph into densely connected communities
+// by maximizing modularity. Edges are treated as undirected, weighted by the
+// number of dependencies between two nodes in either direction.
+// The graph is represented as map[nodeID][]outgoingNodeIDs.
+//
+// It runs the Louvain method (local node moves, then aggregation of each
+// community into a single node, repeated) with Leiden's connectivity
+// guarantee: before aggregating, each community is split into its connected
+// parts, so no community is held together only through other communities.
+func DetectCommunities(graph map[string][]string, config CommunityConfig) CommunityResult {
+	if config.Resolution <= 0 {
+		config.Resolution = 1.0
+	}
+	if config.MaxLevels <= 0 {
+		config.MaxLevels = 10
+	}
+
+	// Index nodes in sorted order so results are deterministic
+	seen := make(map[string]struct{})
+	for node, targets := range graph {
+		seen[node] = struct{}{}
+		for _, t := range targets {
+			seen[t] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for node := range seen {
+		names = append(names, node)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return CommunityResult{Communities: map[string]int{}}
+	}
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	base := newWeightedGraph(len(names))
+	for node, targets := range graph {
+		for _, t := range targets {
+			if t != node {
+				base.addEdge(index[node], index[t], 1)
+			}
+		}
+	}
+
+	// membership[i] is the community of original node i at the current level
+	membership := make([]int, len(names))
+	for i := range membership {
+		membership[i] = i
+	}
+
+	g := base
+	levels := 0
+	for levels < config.MaxLevels {
+		comm, moved := g.localMoving(config.Resolution)
+		comm = g.splitDisconnected(comm)
+		count := renumber(comm)
+		if !moved || count == g.n {
+			break
+		}
+		for i := range membership {
+			membership[i] = comm[membership[i]]
+		}
+		g = g.aggregate(comm, count)
+		levels++
+	}
+
+	// Number communities by size, largest first
+	members := make(map[int][]int)
+	for i, c := range membership {
+		members[c] = append(members[c], i)
+	}
+	order := make([]int, 0, len(members))
+	for c := range members {
+		order = append(order, c)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := members[order[i]], members[order[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a[0] < b[0] // members are in index (name) order
+	})
+	final := make([]int, len(names))
+	result := CommunityResult{Communities: make(map[string]int, len(names)), Count: len(order), Levels: levels}
+	for rank, c := range order {
+		for _, i := range members[c] {
+			final[i] = rank
+			result.Communities[names[i]] = rank
+		}
+	}
+	result.Modularity = base.modularity(final, config.Resolution)
+	return result
+}
+
+// weightedGraph is an undirected weighted graph. A self-loop of weight w
+// stands for w internal edges of an aggregated community.
+type weightedGraph struct {
+	n      int
+	adj    []map[int]float64 // neighbor -> weight, excluding self-loops
+	self   []float64
+	degree []float64 // self-loops count twice
+	total  float64   // sum of degrees, twice the edge weight
+}
+
+func newWeightedGraph(n int) *weightedGraph {
+	g := &weightedGraph{n: n, adj: make([]map[int]float64, n), self: make([]float64, n), degree: make([]float64, n)}
+	for i := range g.adj {
+		g.adj[i] = make(map[int]float64)
+	}
+	return g
+}
+
+func (g *weightedGraph) addEdge(u, v int, w float64) {
+	if u == v {
+		g.self[u] += w
+		g.degree[u] += 2 * w
+	} else {
+		g.adj[u][v] += w
+		g.adj[v][u] += w
+		g.degree[u] += w
+		g.degree[v] += w
+	}
+	g.total += 2 * w
+}
+
+// sortedNeighbors returns the neighbors of u in index order, so moves do
+// not depend on map iteration.
+func (g *weightedGraph) sortedNeighbors(u int) []int {
+	nbrs := make([]int, 0, len(g.adj[u]))
+	for v := range g.adj[u] {
+		nbrs = append(nbrs, v)
+	}
+	sort.Ints(nbrs)
+	return nbrs
+}
+
+// localMoving moves nodes to the neighboring community with the largest
+// modularity gain until no move improves it. It reports whether any node
+// changed community.
+func (g *weightedGraph) localMoving(resolution float64) ([]int, bool) {
+	comm := make([]int, g.n)
+	tot := make([]float64, g.n)
+	for i := range comm {
+		comm[i] = i
+		tot[i] = g.degree[i]
+	}
+	if g.total == 0 {
+		return comm, false
+	}
+
+	neighbors := make([][]int, g.n)
+	for u := range neighbors {
+		neighbors[u] = g.sortedNeighbors(u)
+	}
+
+	moved := false
+	for pass := 0; pass < 100; pass++ {
+		improved := false
+		for u := 0; u < g.n; u++ {
+			cu := comm[u]
+			ku := g.degree[u]
+
+			links := make(map[int]float64)
+			for _, v := range neighbors[u] {
+				links[comm[v]] += g.adj[u][v]
+			}
+
+			tot[cu] -= ku
+			best := cu
+			bestGain := links[cu] - resolution*tot[cu]*ku/g.total
+			for _, v := range neighbors[u] {
+				c := comm[v]
+				if c == best {
+					continue
+				}
+				gain := links[c] - resolution*tot[c]*ku/g.total
+				if gain > bestGain+1e-12 || (gain > bestGain-1e-12 && c < best && best != cu) {
+					best, bestGain = c, gain
+				}
+			}
+			tot[best] += ku
+			if best != cu {
+				comm[u] = best
+				improved = true
+				moved = true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return comm, moved
+}
+
+// splitDisconnected gives each connected part of a community its own
+// community number (Leiden's refinement guarantee).
+func (g *weightedGraph) splitDisconnected(comm []int) []int {
+	out := make([]int, g.n)
+	for i := range out {
+		out[i] = -1
+	}
+	next := 0
+	for start := 0; start < g.n; start++ {
+		if out[start] >= 0 {
+			continue
+		}
+		out[start] = next
+		stack := []int{start}
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for v := range g.adj[u] {
+				if out[v] < 0 && comm[v] == comm[start] {
+					out[v] = next
+					stack = append(stack, v)
+				}
+			}
+		}
+		next++
+	}
+	return out
+}
+
+// renumber maps community numbers to 0..count-1 in order of first
+// appearance and returns count.
+func renumber(comm []int) int {
+	ids := make(map[int]int)
+	for i, c := range comm {
+		id, ok := ids[c]
+		if !ok {
+			id = len(ids)
+			ids[c] = id
+		}
+		comm[i] = id
+	}
+	return len(ids)
+}
+
+// aggregate collapses each community into one node.
+func (g *weightedGraph) aggregate(comm []int, count int) *weightedGraph {
+	agg := newWeightedGraph(count)
+	for u := 0; u < g.n; u++ {
+		if g.self[u] > 0 {
+			agg.addEdge(comm[u], comm[u], g.self[u])
+		}
+		for v, w := range g.adj[u] {
+			if u < v {
+				agg.addEdge(comm[u], comm[v], w)
+			}
+		}
+	}
+	return agg
+}
+
+// modularity computes Q = sum over communities of
+// internal/m - resolution * (degree/2m)^2.
+func (g *weightedGraph) modularity(comm []int, resolution float64) float64 {
+	if g.total == 0 {
+		return 0
+	}
+	internal := make(map[int]float64)
+	degree := make(map[int]float64)
+	for u := 0; u < g.n; u++ {
+		degree[comm[u]] += g.degree[u]
+		internal[comm[u]] += g.self[u]
+		for v, w := range g.adj[u] {
+			if u < v && comm[u] == comm[v] {
+				internal[comm[u]] += w
+			}
+		}
+	}
+	m := g.total / 2
+	q := 0.0
+	for c, d := range degree {
+		q += internal[c]/m - resolution*(d/g.total)*(d/g.total)
+	}
+	return q
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+)
+
+// cliques builds k fully connected groups of size n, joined in a ring by a
+// single edge between consecutive groups.
+func cliques(k, n int) map[string][]string {
+	graph := make(map[string][]string)
+	node := func(c, i int) string { return fmt.Sprintf("c%d-n%d", c, i) }
+	for c := 0; c < k; c++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i != j {
+					graph[node(c, i)] = append(graph[node(c, i)], node(c, j))
+				}
+			}
+		}
+		graph[node(c, 0)] = append(graph[node(c, 0)], node((c+1)%k, 1))
+	}
+	return graph
+}
+
+func TestDetectCommunities_Cliques(t *testing.T) {
+	graph := cliques(6, 5)
+	result := DetectCommunities(graph, DefaultCommunityConfig())
+
+	if result.Count != 6 {
+		t.Fatalf("Count = %d, want 6", result.Count)
+	}
+	for c := 0; c < 6; c++ {
+		want := result.Communities[fmt.Sprintf("c%d-n0", c)]
+		for i := 1; i < 5; i++ {
+			if got := result.Communities[fmt.Sprintf("c%d-n%d", c, i)]; got != want {
+				t.Errorf("c%d-n%d in community %d, want %d", c, i, got, want)
+			}
+		}
+	}
+	if result.Modularity < 0.6 {
+		t.Errorf("Modularity = %.3f, want > 0.6", result.Modularity)
+	}
+}
+
+func TestDetectCommunities_Deterministic(t *testing.T) {
+	graph := cliques(8, 4)
+	first := DetectCommunities(graph, DefaultCommunityConfig())
+	for i := 0; i < 5; i++ {
+		again := DetectCommunities(graph, DefaultCommunityConfig())
+		for node, c := range first.Communities {
+			if again.Communities[node] != c {
+				t.Fatalf("run %d: %s in community %d, first run %d", i, node, again.Communities[node], c)
+			}
+		}
+	}
+	// Community 0 is the largest; with equal sizes, the one with the smallest member
+	if got := first.Communities["c0-n0"]; got != 0 {
+		t.Errorf("c0-n0 in community %d, want 0", got)
+	}
+}
+
+func TestDetectCommunities_Resolution(t *testing.T) {
+	graph := cliques(6, 5)
+	coarse := DetectCommunities(graph, CommunityConfig{Resolution: 0.05})
+	if coarse.Count >= 6 {
+		t.Errorf("low resolution: Count = %d, want fewer than 6", coarse.Count)
+	}
+}
+
+func TestDetectCommunities_Edges(t *testing.T) {
+	if got := DetectCommunities(map[string][]string{}, DefaultCommunityConfig()); got.Count != 0 {
+		t.Errorf("empty graph: Count = %d, want 0", got.Count)
+	}
+
+	// Disconnected pairs stay apart; isolated nodes get their own community
+	graph := map[string][]string{"a": {"b"}, "c": {"d"}, "e": nil}
+	result := DetectCommunities(graph, DefaultCommunityConfig())
+	if result.Count != 3 {
+		t.Errorf("Count = %d, want 3", result.Count)
+	}
+	if result.Communities["a"] != result.Communities["b"] || result.Communities["a"] == result.Communities["c"] {
+		t.Errorf("Communities = %v", result.Communities)
+	}
+}
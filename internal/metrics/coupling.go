@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// Zones of the abstractness/instability plane, far from the main sequence
+const (
+	// ZonePain holds concrete, stable packages: heavily depended on and hard
+	// to change.
+	ZonePain = "pain"
+	// ZoneUselessness holds abstract, unstable packages: abstractions that
+	// nothing depends on.
+	ZoneUselessness = "uselessness"
+)
+
+// zoneDistance is the distance from the main sequence beyond which a
+// package is placed in a zone
+const zoneDistance = 0.5
+
+// CouplingEntity describes one node of the graph for package coupling
+type CouplingEntity struct {
+	Package  string // package or directory the entity belongs to
+	Type     bool   // whether the entity is a type definition
+	Abstract bool   // interface or abstract class
+}
+
+// PackageCoupling holds Robert Martin's package metrics for one package.
+type PackageCoupling struct {
+	Package       string
+	Entities      int
+	Types         int
+	AbstractTypes int
+
+	// Ca (afferent coupling) counts entities outside the package that
+	// depend on entities inside it.
+	Ca int
+
+	// Ce (efferent coupling) counts entities inside the package that depend
+	// on entities outside it.
+	Ce int
+
+	// Instability is Ce / (Ca + Ce): 0 for maximally stable packages, 1 for
+	// packages nothing depends on. 0 when the package has no coupling.
+	Instability float64
+
+	// Abstractness is the share of the package's types that are abstract.
+	// 0 when the package has no types.
+	Abstractness float64
+
+	// Distance from the main sequence A + I = 1, from 0 to 1
+	Distance float64
+
+	// Zone is ZonePain, ZoneUselessness or "" near the main sequence
+	Zone string
+}
+
+// ComputePackageCoupling aggregates the entity graph to package level and
+// computes afferent and efferent coupling, instability, abstractness and
+// distance from the main sequence for every package. Nodes missing from
+// entities are ignored. Results are sorted by package.
+// The graph is represented as map[nodeID][]outgoingNodeIDs.
+func ComputePackageCoupling(graph map[string][]string, entities map[string]CouplingEntity) []PackageCoupling {
+	pkgs := make(map[string]*PackageCoupling)
+	get := func(name string) *PackageCoupling {
+		p := pkgs[name]
+		if p == nil {
+			p = &PackageCoupling{Package: name}
+			pkgs[name] = p
+		}
+		return p
+	}
+	for _, e := range entities {
+		p := get(e.Package)
+		p.Entities++
+		if e.Type {
+			p.Types++
+			if e.Abstract {
+				p.AbstractTypes++
+			}
+		}
+	}
+
+	// Count each outside entity once per package it reaches into
+	afferent := make(map[string]map[string]bool)
+	for from, targets := range graph {
+		src, ok := entities[from]
+		if !ok {
+			continue
+		}
+		outgoing := false
+		for _, to := range targets {
+			dst, ok := entities[to]
+			if !ok || dst.Package == src.Package {
+				continue
+			}
+			outgoing = true
+			if afferent[dst.Package] == nil {
+				afferent[dst.Package] = make(map[string]bool)
+			}
+			afferent[dst.Package][from] = true
+		}
+		if outgoing {
+			get(src.Package).Ce++
+		}
+	}
+	for name, callers := range afferent {
+		get(name).Ca = len(callers)
+	}
+
+	result := make([]PackageCoupling, 0, len(pkgs))
+	for _, p := range pkgs {
+		if total := p.Ca + p.Ce; total > 0 {
+			p.Instability = float64(p.Ce) / float64(total)
+		}
+		if p.Types > 0 {
+			p.Abstractness = float64(p.AbstractTypes) / float64(p.Types)
+		}
+		p.Distance = math.Abs(p.Abstractness + p.Instability - 1)
+		if p.Distance > zoneDistance {
+			if p.Abstractness+p.Instability < 1 {
+				p.Zone = ZonePain
+			} else {
+				p.Zone = ZoneUselessness
+			}
+		}
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Package < result[j].Package })
+	return result
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputePackageCoupling(t *testing.T) {
+	// app uses store and api; store implements api's interfaces
+	entities := map[string]CouplingEntity{
+		"app.Main":     {Package: "app"},
+		"app.Run":      {Package: "app"},
+		"store.DB":     {Package: "store", Type: true},
+		"store.Open":   {Package: "store"},
+		"api.Reader":   {Package: "api", Type: true, Abstract: true},
+		"api.Writer":   {Package: "api", Type: true, Abstract: true},
+		"util.Helpers": {Package: "util", Type: true, Abstract: true},
+	}
+	graph := map[string][]string{
+		"app.Main":   {"app.Run", "store.Open"},
+		"app.Run":    {"store.DB", "api.Reader", "external.Thing"},
+		"store.Open": {"store.DB", "api.Writer"},
+	}
+
+	got := make(map[string]PackageCoupling)
+	for _, p := range ComputePackageCoupling(graph, entities) {
+		got[p.Package] = p
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d packages, want 4: %v", len(got), got)
+	}
+
+	tests := []struct {
+		pkg        string
+		ca, ce     int
+		i, a, dist float64
+		zone       string
+	}{
+		{"app", 0, 2, 1, 0, 0, ""},
+		{"store", 2, 1, 1.0 / 3, 0, 2.0 / 3, ZonePain},
+		{"api", 2, 0, 0, 1, 0, ""},
+		{"util", 0, 0, 0, 1, 0, ""},
+	}
+	for _, tt := range tests {
+		p := got[tt.pkg]
+		if p.Ca != tt.ca || p.Ce != tt.ce {
+			t.Errorf("%s: Ca=%d Ce=%d, want %d %d", tt.pkg, p.Ca, p.Ce, tt.ca, tt.ce)
+		}
+		if math.Abs(p.Instability-tt.i) > 1e-9 || math.Abs(p.Abstractness-tt.a) > 1e-9 || math.Abs(p.Distance-tt.dist) > 1e-9 {
+			t.Errorf("%s: I=%.3f A=%.3f D=%.3f, want %.3f %.3f %.3f", tt.pkg, p.Instability, p.Abstractness, p.Distance, tt.i, tt.a, tt.dist)
+		}
+		if p.Zone != tt.zone {
+			t.Errorf("%s: zone %q, want %q", tt.pkg, p.Zone, tt.zone)
+		}
+	}
+}
+
+func TestComputePackageCoupling_Uselessness(t *testing.T) {
+	// An interface package that depends on others but has no dependents
+	entities := map[string]CouplingEntity{
+		"spi.Plugin": {Package: "spi", Type: true, Abstract: true},
+		"core.Value": {Package: "core", Type: true},
+	}
+	graph := map[string][]string{"spi.Plugin": {"core.Value"}}
+
+	for _, p := range ComputePackageCoupling(graph, entities) {
+		if p.Package == "spi" && p.Zone != ZoneUselessness {
+			t.Errorf("spi zone = %q (A=%.2f I=%.2f), want %q", p.Zone, p.Abstractness, p.Instability, ZoneUselessness)
+		}
+		if p.Package == "core" && p.Zone != ZonePain {
+			t.Errorf("core zone = %q, want %q", p.Zone, ZonePain)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Churn summarizes how an entity changed within a time window.
+type Churn struct {
+	Commits      int       // commits that touched the entity
+	LinesChanged int       // lines those commits added or removed in it
+	LastChanged  time.Time // most recent of those commits
+}
+
+// HotspotInput describes one entity for hotspot scoring.
+type HotspotInput struct {
+	ID         string
+	Churn      Churn
+	Lines      int     // size in lines
+	Cyclomatic int     // estimated cyclomatic complexity, 0 if unknown
+	PageRank   float64 // importance in the dependency graph
+}
+
+// Hotspot is an entity that is both complex and changed often.
+type Hotspot struct {
+	HotspotInput
+
+	// Score from 0 to 1: churn × complexity, weighted up to double by
+	// PageRank. Churn and complexity are log-scaled against the largest
+	// value among the inputs.
+	Score float64
+}
+
+// ComputeHotspots scores every input that changed at least once and returns
+// them by score descending. Complexity is the mean of the scaled
+// cyclomatic complexity and size, so types and other entities without
+// control flow still rank by size.
+func ComputeHotspots(inputs []HotspotInput) []Hotspot {
+	maxCommits, maxLines, maxCyclomatic, maxPageRank := 0, 0, 0, 0.0
+	for _, in := range inputs {
+		maxCommits = max(maxCommits, in.Churn.Commits)
+		maxLines = max(maxLines, in.Lines)
+		maxCyclomatic = max(maxCyclomatic, in.Cyclomatic)
+		maxPageRank = math.Max(maxPageRank, in.PageRank)
+	}
+	scale := func(v, top int) float64 {
+		if top <= 0 || v <= 0 {
+			return 0
+		}
+		return math.Log1p(float64(v)) / math.Log1p(float64(top))
+	}
+
+	var result []Hotspot
+	for _, in := range inputs {
+		if in.Churn.Commits == 0 {
+			continue
+		}
+		churn := scale(in.Churn.Commits, maxCommits)
+		complexity := (scale(in.Cyclomatic, maxCyclomatic) + scale(in.Lines, maxLines)) / 2
+		importance := 0.0
+		if maxPageRank > 0 {
+			importance = in.PageRank / maxPageRank
+		}
+		result = append(result, Hotspot{
+			HotspotInput: in,
+			Score:        churn * complexity * (1 + importance) / 2,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// decisionPoint matches the branching constructs of the supported languages
+var decisionPoint = regexp.MustCompile(`\b(if|elif|for|foreach|while|case|catch|except|when)\b|&&|\|\|`)
+
+// EstimateCyclomatic approximates the cyclomatic complexity of a function
+// body as one plus its decision points. Keywords inside strings and
+// comments are counted too, so treat it as an estimate.
+func EstimateCyclomatic(body string) int {
+	if body == "" {
+		return 0
+	}
+	return 1 + len(decisionPoint.FindAllStringIndex(body, -1))
+}
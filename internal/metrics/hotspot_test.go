@@ -0,0 +1,59 @@
+package metrics
+
+import "testing"
+
+func TestComputeHotspots(t *testing.T) {
+	inputs := []HotspotInput{
+		// Complex and changed often
+		{ID: "parse", Churn: Churn{Commits: 12}, Lines: 200, Cyclomatic: 30, PageRank: 0.01},
+		// Changed often but trivial
+		{ID: "version", Churn: Churn{Commits: 12}, Lines: 3, Cyclomatic: 1, PageRank: 0.01},
+		// Complex but stable
+		{ID: "legacy", Churn: Churn{Commits: 1}, Lines: 200, Cyclomatic: 30, PageRank: 0.01},
+		// Never changed in the window
+		{ID: "untouched", Lines: 500, Cyclomatic: 60},
+	}
+
+	got := ComputeHotspots(inputs)
+	if len(got) != 3 {
+		t.Fatalf("got %d hotspots, want 3 (unchanged entities excluded): %+v", len(got), got)
+	}
+	if got[0].ID != "parse" {
+		t.Errorf("top hotspot = %s, want parse", got[0].ID)
+	}
+	for _, h := range got {
+		if h.Score < 0 || h.Score > 1 {
+			t.Errorf("%s score %.3f out of [0, 1]", h.ID, h.Score)
+		}
+	}
+}
+
+func TestComputeHotspots_PageRankWeight(t *testing.T) {
+	inputs := []HotspotInput{
+		{ID: "leaf", Churn: Churn{Commits: 5}, Lines: 50, Cyclomatic: 5},
+		{ID: "core", Churn: Churn{Commits: 5}, Lines: 50, Cyclomatic: 5, PageRank: 0.2},
+	}
+	got := ComputeHotspots(inputs)
+	if got[0].ID != "core" || got[0].Score <= got[1].Score {
+		t.Errorf("the important entity should rank first: %+v", got)
+	}
+}
+
+func TestEstimateCyclomatic(t *testing.T) {
+	tests := []struct {
+		body string
+		want int
+	}{
+		{"", 0},
+		{"return x", 1},
+		{"if a && b { for i := range xs { } } else if c || d { }", 6},
+		{"switch x { case 1: case 2: }", 3},
+		{"try { } catch (e) { } while (ok) { }", 3},
+		{"ifdef := notify(before)", 1},
+	}
+	for _, tt := range tests {
+		if got := EstimateCyclomatic(tt.body); got != tt.want {
+			t.Errorf("EstimateCyclomatic(%q) = %d, want %d", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+// AuthorLines is the number of lines one author last changed.
+type AuthorLines struct {
+	Name  string
+	Email string
+	Lines int
+}
+
+// AuthorShare is an author's share of a body of code.
+type AuthorShare struct {
+	AuthorLines
+	Share float64 // fraction of the lines, 0 to 1
+}
+
+// Ownership describes how knowledge of a body of code is spread over its
+// authors.
+type Ownership struct {
+	Authors []AuthorShare // by lines descending
+	Lines   int           // lines with a known author
+
+	// BusFactor is the smallest number of authors who together wrote more
+	// than half of the lines: how many people would have to leave before
+	// most of the code has no author left to ask.
+	BusFactor int
+
+	// MainShare is the share of the top author.
+	MainShare float64
+}
+
+// ComputeOwnership merges the lines of authors with the same email (or name
+// when the email is empty), case-insensitively, and computes their shares
+// and the bus factor.
+func ComputeOwnership(lines []AuthorLines) Ownership {
+	byKey := make(map[string]*AuthorLines)
+	var order []string
+	for _, l := range lines {
+		if l.Lines <= 0 {
+			continue
+		}
+		key := strings.ToLower(l.Email)
+		if key == "" {
+			key = strings.ToLower(l.Name)
+		}
+		if a, ok := byKey[key]; ok {
+			a.Lines += l.Lines
+			continue
+		}
+		copied := l
+		byKey[key] = &copied
+		order = append(order, key)
+	}
+
+	var o Ownership
+	for _, key := range order {
+		a := byKey[key]
+		o.Lines += a.Lines
+		o.Authors = append(o.Authors, AuthorShare{AuthorLines: *a})
+	}
+	if o.Lines == 0 {
+		return o
+	}
+	sort.SliceStable(o.Authors, func(i, j int) bool {
+		return o.Authors[i].Lines > o.Authors[j].Lines
+	})
+
+	covered := 0
+	for i := range o.Authors {
+		o.Authors[i].Share = float64(o.Authors[i].Lines) / float64(o.Lines)
+		if covered*2 <= o.Lines {
+			covered += o.Authors[i].Lines
+			o.BusFactor++
+		}
+	}
+	o.MainShare = o.Authors[0].Share
+	return o
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeOwnership(t *testing.T) {
+	o := ComputeOwnership([]AuthorLines{
+		{Name: "Ada", Email: "ada@example.com", Lines: 30},
+		{Name: "Bob", Email: "bob@example.com", Lines: 50},
+		{Name: "Ada L", Email: "ADA@example.com", Lines: 10},
+		{Name: "Cy", Email: "cy@example.com", Lines: 10},
+	})
+	if o.Lines != 100 || len(o.Authors) != 3 {
+		t.Fatalf("ownership = %+v, want 3 authors over 100 lines", o)
+	}
+	if o.Authors[0].Email != "bob@example.com" || o.Authors[1].Lines != 40 {
+		t.Errorf("authors = %+v, want Bob first and Ada's emails merged", o.Authors)
+	}
+	// Bob alone wrote exactly half: not a majority
+	if o.BusFactor != 2 {
+		t.Errorf("bus factor = %d, want 2", o.BusFactor)
+	}
+	if math.Abs(o.MainShare-0.5) > 1e-9 {
+		t.Errorf("main share = %.2f, want 0.50", o.MainShare)
+	}
+}
+
+func TestComputeOwnership_SingleAuthor(t *testing.T) {
+	o := ComputeOwnership([]AuthorLines{{Name: "Ada", Lines: 12}, {Name: "ada", Lines: 3}})
+	if o.BusFactor != 1 || o.MainShare != 1 || len(o.Authors) != 1 {
+		t.Errorf("ownership = %+v, want one author owning everything", o)
+	}
+	if empty := ComputeOwnership(nil); empty.BusFactor != 0 || empty.Lines != 0 {
+		t.Errorf("empty ownership = %+v", empty)
+	}
+}
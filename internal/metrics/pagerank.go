@@ -40,6 +40,11 @@ type PageRankResult struct {
 
 	// FinalDelta is the maximum change in the last iteration
 	FinalDelta float64
+
+	// Updated is the number of nodes whose score was recomputed. A full
+	// computation updates every node; an incremental one only the region
+	// around the changes.
+	Updated int
 }
 
 // ComputePageRank calculates PageRank for all nodes in the graph.
@@ -126,6 +131,134 @@ func ComputePageRankWithInfo(graph map[string][]string, config PageRankConfig) P
 	}
 
 	result.Scores = pr
+	result.Updated = n
+	return result
+}
+
+// ComputePageRankIncremental updates PageRank scores after part of the graph
+// changed. It warm-starts from previous and only recomputes nodes whose
+// inputs moved: the changed nodes and their successors first, then the
+// successors of every node whose score changed by Tolerance or more, until
+// that region converges. New nodes start at 1/N and count as changed; when
+// nodes were added or removed the first sweep covers every node.
+// Without previous scores it falls back to ComputePageRankWithInfo.
+// The graph is represented as map[nodeID][]outgoingNodeIDs.
+func ComputePageRankIncremental(graph map[string][]string, previous map[string]float64, changed []string, config PageRankConfig) PageRankResult {
+	if len(previous) == 0 || len(graph) == 0 {
+		return ComputePageRankWithInfo(graph, config)
+	}
+
+	allNodes := collectAllNodes(graph)
+	n := len(allNodes)
+
+	// Warm start, renormalized so added and removed nodes keep the sum at 1
+	pr := make(map[string]float64, n)
+	seeds := make(map[string]bool)
+	sum := 0.0
+	for node := range allNodes {
+		score, ok := previous[node]
+		if !ok {
+			score = 1.0 / float64(n)
+			seeds[node] = true
+		}
+		pr[node] = score
+		sum += score
+	}
+	if sum > 0 {
+		for node := range pr {
+			pr[node] /= sum
+		}
+	}
+
+	// Adding or removing nodes changes the teleport share of every node, so
+	// the first sweep then covers the whole graph
+	resized := len(seeds) > 0
+	for node := range previous {
+		if _, ok := allNodes[node]; !ok {
+			resized = true
+			break
+		}
+	}
+
+	for _, node := range changed {
+		if _, ok := allNodes[node]; ok {
+			seeds[node] = true
+		}
+	}
+	active := make(map[string]bool)
+	if resized {
+		for node := range allNodes {
+			active[node] = true
+		}
+	}
+	for node := range seeds {
+		active[node] = true
+		for _, target := range graph[node] {
+			active[target] = true
+		}
+	}
+
+	incomingLinks := buildIncomingLinks(graph, allNodes)
+	var dangling []string
+	for node := range allNodes {
+		if len(graph[node]) == 0 {
+			dangling = append(dangling, node)
+		}
+	}
+
+	result := PageRankResult{Scores: pr, Converged: len(active) == 0}
+	updated := make(map[string]bool)
+	lastBase := -1.0
+	for iter := 0; iter < config.MaxIterations && len(active) > 0; iter++ {
+		danglingSum := 0.0
+		for _, node := range dangling {
+			danglingSum += pr[node]
+		}
+		base := (1.0-config.Damping)/float64(n) + config.Damping*danglingSum/float64(n)
+
+		// A shift in the teleport and dangling share moves every node
+		if lastBase >= 0 && abs(base-lastBase) >= config.Tolerance {
+			for node := range allNodes {
+				active[node] = true
+			}
+		}
+		lastBase = base
+
+		newScores := make(map[string]float64, len(active))
+		maxDelta := 0.0
+		next := make(map[string]bool)
+		for node := range active {
+			score := base
+			for _, incoming := range incomingLinks[node] {
+				score += config.Damping * pr[incoming.source] / float64(incoming.outDegree)
+			}
+			newScores[node] = score
+			updated[node] = true
+
+			delta := abs(score - pr[node])
+			if delta > maxDelta {
+				maxDelta = delta
+			}
+			if delta >= config.Tolerance {
+				for _, target := range graph[node] {
+					next[target] = true
+				}
+			}
+		}
+		for node, score := range newScores {
+			pr[node] = score
+		}
+
+		result.Iterations = iter + 1
+		result.FinalDelta = maxDelta
+		active = next
+		if len(active) == 0 {
+			result.Converged = true
+		}
+	}
+
+	result.Scores = pr
+	result.Updated = len(updated)
 	return result
 }
 
@@ -275,3 +275,73 @@ func TestTopN_Zero(t *testing.T) {
 		t.Errorf("expected nil for n=0, got %v", top0)
 	}
 }
+
+// chainGraph builds n nodes named "naa", "nab", ... in a chain, with every
+// tenth node also linking back to the start so scores differ along the chain.
+func chainGraph(n int) map[string][]string {
+	graph := make(map[string][]string)
+	for i := 0; i < n; i++ {
+		node := "n" + string(rune('a'+i/26)) + string(rune('a'+i%26))
+		if i+1 < n {
+			graph[node] = append(graph[node], "n"+string(rune('a'+(i+1)/26))+string(rune('a'+(i+1)%26)))
+		} else {
+			graph[node] = []string{}
+		}
+		if i%10 == 9 {
+			graph[node] = append(graph[node], "naa")
+		}
+	}
+	return graph
+}
+
+func TestComputePageRankIncremental_MatchesFull(t *testing.T) {
+	config := DefaultPageRankConfig()
+	config.Tolerance = 1e-9
+	config.MaxIterations = 1000
+
+	graph := chainGraph(200)
+	previous := ComputePageRank(graph, config)
+
+	// Add a node and an edge near the end of the chain
+	graph["added"] = []string{"nhq"}
+	graph["nhp"] = append(graph["nhp"], "added")
+
+	full := ComputePageRank(graph, config)
+	inc := ComputePageRankIncremental(graph, previous, []string{"added", "nhp"}, config)
+
+	if !inc.Converged {
+		t.Fatalf("incremental did not converge in %d iterations", inc.Iterations)
+	}
+	for node, want := range full {
+		if !floatEquals(inc.Scores[node], want, 1e-6) {
+			t.Errorf("%s: incremental %f, full %f", node, inc.Scores[node], want)
+		}
+	}
+}
+
+func TestComputePageRankIncremental_Local(t *testing.T) {
+	// Two components: a change in one must not touch the other
+	graph := chainGraph(100)
+	graph["x1"] = []string{"x2"}
+	graph["x2"] = []string{"x3"}
+	graph["x3"] = []string{"x1"}
+	config := DefaultPageRankConfig()
+	previous := ComputePageRank(graph, config)
+
+	graph["x2"] = append(graph["x2"], "x1")
+	inc := ComputePageRankIncremental(graph, previous, []string{"x2"}, config)
+
+	if inc.Updated == 0 || inc.Updated > 10 {
+		t.Errorf("Updated = %d, want only the changed cycle", inc.Updated)
+	}
+}
+
+func TestComputePageRankIncremental_NoPrevious(t *testing.T) {
+	graph := chainGraph(20)
+	config := DefaultPageRankConfig()
+	inc := ComputePageRankIncremental(graph, nil, nil, config)
+	full := ComputePageRankWithInfo(graph, config)
+	if inc.Updated != len(graph) || inc.Iterations != full.Iterations {
+		t.Errorf("without previous scores got %d updated in %d iterations, want full computation", inc.Updated, inc.Iterations)
+	}
+}
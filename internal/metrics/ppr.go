@@ -0,0 +1,113 @@
+package metrics
+
+import "math"
+
+// ComputePersonalizedPageRank runs a random walk with restart over a
+// weighted graph, represented as map[nodeID]map[targetID]weight. At each
+// step the walker follows an outgoing edge with probability proportional to
+// its weight, or with probability 1-Damping jumps back to a seed picked by
+// seed weight. Walkers on nodes without outgoing edges also jump back.
+//
+// The scores sum to 1 and concentrate around the seeds, so they rank nodes
+// by relevance to the seeds rather than by global importance. Returns nil
+// without seeds; nodes the walk cannot reach are left out.
+func ComputePersonalizedPageRank(graph map[string]map[string]float64, seeds map[string]float64, config PageRankConfig) map[string]float64 {
+	index := make(map[string]int)
+	var nodes []string
+	add := func(id string) int {
+		i, ok := index[id]
+		if !ok {
+			i = len(nodes)
+			index[id] = i
+			nodes = append(nodes, id)
+		}
+		return i
+	}
+
+	seedTotal := 0.0
+	for id, w := range seeds {
+		if w > 0 {
+			add(id)
+			seedTotal += w
+		}
+	}
+	if seedTotal == 0 {
+		return nil
+	}
+
+	type edge struct {
+		to     int
+		weight float64
+	}
+	var out [][]edge
+	for from, targets := range graph {
+		i := add(from)
+		for to, w := range targets {
+			if w <= 0 {
+				continue
+			}
+			j := add(to)
+			for len(out) <= max(i, j) {
+				out = append(out, nil)
+			}
+			out[i] = append(out[i], edge{j, w})
+		}
+	}
+	for len(out) < len(nodes) {
+		out = append(out, nil)
+	}
+	outWeight := make([]float64, len(nodes))
+	for i, edges := range out {
+		for _, e := range edges {
+			outWeight[i] += e.weight
+		}
+	}
+
+	restart := make([]float64, len(nodes))
+	for id, w := range seeds {
+		if w > 0 {
+			restart[index[id]] = w / seedTotal
+		}
+	}
+
+	p := append([]float64(nil), restart...)
+	next := make([]float64, len(nodes))
+	for iter := 0; iter < config.MaxIterations; iter++ {
+		// Mass on dangling nodes and the teleport share restart at the seeds
+		jump := 1 - config.Damping
+		for i := range p {
+			if outWeight[i] == 0 {
+				jump += config.Damping * p[i]
+			}
+		}
+		for i := range next {
+			next[i] = jump * restart[i]
+		}
+		for i, edges := range out {
+			if outWeight[i] == 0 || p[i] == 0 {
+				continue
+			}
+			share := config.Damping * p[i] / outWeight[i]
+			for _, e := range edges {
+				next[e.to] += share * e.weight
+			}
+		}
+
+		delta := 0.0
+		for i := range p {
+			delta += math.Abs(next[i] - p[i])
+		}
+		p, next = next, p
+		if delta < config.Tolerance {
+			break
+		}
+	}
+
+	scores := make(map[string]float64)
+	for i, s := range p {
+		if s > 0 {
+			scores[nodes[i]] = s
+		}
+	}
+	return scores
+}
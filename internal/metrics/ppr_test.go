@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestComputePersonalizedPageRank_NoSeeds(t *testing.T) {
+	graph := map[string]map[string]float64{"A": {"B": 1}}
+	if scores := ComputePersonalizedPageRank(graph, nil, DefaultPageRankConfig()); scores != nil {
+		t.Errorf("expected nil without seeds, got %v", scores)
+	}
+}
+
+func TestComputePersonalizedPageRank_Locality(t *testing.T) {
+	// seed -> a -> b -> c, and a hub every node of another cluster calls
+	graph := map[string]map[string]float64{
+		"seed": {"a": 1},
+		"a":    {"b": 1},
+		"b":    {"c": 1},
+		"x":    {"hub": 1},
+		"y":    {"hub": 1},
+		"z":    {"hub": 1},
+	}
+	scores := ComputePersonalizedPageRank(graph, map[string]float64{"seed": 1}, DefaultPageRankConfig())
+
+	total := 0.0
+	for _, s := range scores {
+		total += s
+	}
+	if !floatEquals(total, 1.0, 0.001) {
+		t.Errorf("scores sum to %f, want 1", total)
+	}
+	if !(scores["seed"] > scores["a"] && scores["a"] > scores["b"] && scores["b"] > scores["c"]) {
+		t.Errorf("scores should fall with distance from the seed: %v", scores)
+	}
+	if _, ok := scores["hub"]; ok {
+		t.Errorf("unreachable hub should have no score, got %f", scores["hub"])
+	}
+}
+
+func TestComputePersonalizedPageRank_Weights(t *testing.T) {
+	graph := map[string]map[string]float64{
+		"seed": {"strong": 1, "weak": 0.25},
+	}
+	scores := ComputePersonalizedPageRank(graph, map[string]float64{"seed": 1}, DefaultPageRankConfig())
+	if !floatEquals(scores["strong"], 4*scores["weak"], 0.0001) {
+		t.Errorf("strong = %f, want 4 × weak = %f", scores["strong"], 4*scores["weak"])
+	}
+
+	// Two seeds split the restart mass by weight
+	scores = ComputePersonalizedPageRank(map[string]map[string]float64{}, map[string]float64{"s1": 3, "s2": 1}, DefaultPageRankConfig())
+	if !floatEquals(scores["s1"], 0.75, 0.0001) || !floatEquals(scores["s2"], 0.25, 0.0001) {
+		t.Errorf("seed scores = %v, want s1 0.75 and s2 0.25", scores)
+	}
+}
@@ -173,7 +173,7 @@ func (f *JSONLFormatter) FormatToWriter(w io.Writer, entity interface{}, density
 				return err
 			}
 		}
-		return nil
+		return f.writeCoChanges(w, v.CoChanges)
 
 	case *ContextOutput:
 		// Output metadata
@@ -208,7 +208,7 @@ func (f *JSONLFormatter) FormatToWriter(w io.Writer, entity interface{}, density
 				return err
 			}
 		}
-		return nil
+		return f.writeCoChanges(w, v.CoChanges)
 
 	case *NearOutput:
 		// Output center entity
@@ -250,6 +250,15 @@ func (f *JSONLFormatter) FormatToWriter(w io.Writer, entity interface{}, density
 		}
 		return nil
 
+	case *QueryOutput:
+		// Output each row as a separate JSON line
+		for _, row := range v.Rows {
+			if err := f.writeLine(w, row); err != nil {
+				return err
+			}
+		}
+		return nil
+
 	default:
 		// Single object - output as one line
 		return f.writeLine(w, filtered)
@@ -266,6 +275,20 @@ func (f *JSONLFormatter) writeLine(w io.Writer, v interface{}) error {
 	return err
 }
 
+// writeCoChanges writes one line per co-change counterpart of a target
+func (f *JSONLFormatter) writeCoChanges(w io.Writer, coChanges []*CoChangeEntry) error {
+	for _, c := range coChanges {
+		line := map[string]interface{}{
+			"type": "co_change",
+			"data": c,
+		}
+		if err := f.writeLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CGFFormatter formats entities in the deprecated CGF (Cortex Graph Format).
 // This formatter is maintained for backward compatibility but emits a deprecation warning.
 type CGFFormatter struct {
@@ -307,6 +330,8 @@ func (f *CGFFormatter) FormatToWriter(w io.Writer, entity interface{}, density D
 		return f.writeImpactOutput(w, v, cgfDensity)
 	case *ContextOutput:
 		return f.writeContextOutput(w, v, cgfDensity)
+	case *QueryOutput:
+		return f.writeQueryOutput(w, v, cgfDensity)
 	default:
 		return fmt.Errorf("CGF formatter does not support type %T", entity)
 	}
@@ -383,6 +408,43 @@ func (f *CGFFormatter) writeListOutput(w io.Writer, list *ListOutput, density st
 	return nil
 }
 
+// writeQueryOutput writes a QueryOutput in CGF format, one row per line as
+// tab-separated column=value pairs.
+func (f *CGFFormatter) writeQueryOutput(w io.Writer, q *QueryOutput, density string) error {
+	fmt.Fprintf(w, "#cgf v1 d=%s\n", density)
+	fmt.Fprintf(w, "; === QUERY (count=%d) ===\n\n", q.Count)
+
+	for _, row := range q.Rows {
+		cells := make([]string, 0, len(q.Columns))
+		for _, col := range q.Columns {
+			cells = append(cells, col+"="+cgfValue(row[col]))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return nil
+}
+
+// cgfValue renders a query value compactly: nodes as their location and
+// name, lists in brackets.
+func cgfValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "-"
+	case map[string]any:
+		if loc, ok := x["location"]; ok {
+			return fmt.Sprintf("%s@%s", x["name"], loc)
+		}
+		return fmt.Sprintf("%s-%s->%s", x["from"], x["type"], x["to"])
+	case []any:
+		items := make([]string, len(x))
+		for i, item := range x {
+			items[i] = cgfValue(item)
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
 // writeGraphOutput writes a GraphOutput in CGF format.
 func (f *CGFFormatter) writeGraphOutput(w io.Writer, graph *GraphOutput, density string) error {
 	// Write CGF header
@@ -193,6 +193,27 @@ type ListOutput struct {
 
 	// Count is the total number of results
 	Count int `yaml:"count" json:"count"`
+
+	// Freshness describes when the ranking metrics were computed
+	Freshness *MetricsFreshness `yaml:"metrics_freshness,omitempty" json:"metrics_freshness,omitempty"`
+}
+
+// MetricsFreshness describes when and how graph metrics were last computed.
+type MetricsFreshness struct {
+	// ComputedAt is the RFC3339 time of the computation
+	ComputedAt string `yaml:"computed_at" json:"computed_at"`
+
+	// Ago is the time since the computation (e.g. "5m")
+	Ago string `yaml:"ago" json:"ago"`
+
+	// PageRank is "full" or "incremental (N updated)"
+	PageRank string `yaml:"pagerank" json:"pagerank"`
+
+	// Betweenness is "exact" or "sampled (N pivots)"
+	Betweenness string `yaml:"betweenness" json:"betweenness"`
+
+	// Stale is set when the graph was scanned again after the computation
+	Stale bool `yaml:"stale,omitempty" json:"stale,omitempty"`
 }
 
 // GraphOutput represents graph traversal results for cx graph.
@@ -247,6 +268,36 @@ type ImpactOutput struct {
 
 	// Recommendations contains suggested actions
 	Recommendations []string `yaml:"recommendations,omitempty" json:"recommendations,omitempty"`
+
+	// CoChanges lists files and entities that historically change together
+	// with the target
+	CoChanges []*CoChangeEntry `yaml:"co_changes,omitempty" json:"co_changes,omitempty"`
+}
+
+// CoChangeEntry is a file or entity that historically changes together with
+// a target, whether or not a dependency links them.
+type CoChangeEntry struct {
+	// Name is the file path or entity name
+	Name string `yaml:"name" json:"name"`
+
+	// Level is file or entity
+	Level string `yaml:"level" json:"level"`
+
+	// ID is the entity ID at entity level
+	ID string `yaml:"id,omitempty" json:"id,omitempty"`
+
+	// Location is the file:line-line location of an entity
+	Location string `yaml:"location,omitempty" json:"location,omitempty"`
+
+	// With is the target file or entity it changes with
+	With string `yaml:"with" json:"with"`
+
+	// Support is the number of commits (file level) or scans (entity level)
+	// that changed both
+	Support int `yaml:"support" json:"support"`
+
+	// Confidence is the share of With's changes that also changed Name
+	Confidence float64 `yaml:"confidence" json:"confidence"`
 }
 
 // ImpactMetadata contains metadata about an impact query.
@@ -301,6 +352,10 @@ type ContextOutput struct {
 
 	// Excluded contains entities excluded due to budget/relevance
 	Excluded map[string]string `yaml:"excluded,omitempty" json:"excluded,omitempty"`
+
+	// CoChanges lists files and entities that historically change together
+	// with the target (cx context --for)
+	CoChanges []*CoChangeEntry `yaml:"co_changes,omitempty" json:"co_changes,omitempty"`
 }
 
 // ContextMetadata contains metadata about a context query.
@@ -313,6 +368,9 @@ type ContextMetadata struct {
 
 	// TokensUsed is the actual tokens used
 	TokensUsed int `yaml:"tokens_used" json:"tokens_used"`
+
+	// Tokenizer names the counter of TokensUsed
+	Tokenizer string `yaml:"tokenizer,omitempty" json:"tokenizer,omitempty"`
 }
 
 // EntryPoint represents an entry point for a task.
@@ -341,6 +399,15 @@ type RelevantEntity struct {
 	// Reason explains why this entity is relevant
 	Reason string `yaml:"reason" json:"reason"`
 
+	// PPR is the personalized PageRank from the entry points (--rank ppr)
+	PPR float64 `yaml:"ppr,omitempty" json:"ppr,omitempty"`
+
+	// Delta is new or changed since the session last delivered it (--session)
+	Delta string `yaml:"delta,omitempty" json:"delta,omitempty"`
+
+	// Tier is the detail Code is packed at (--pack): full, signature or skeleton
+	Tier string `yaml:"tier,omitempty" json:"tier,omitempty"`
+
 	// Code contains the entity code (skeleton or full based on relevance)
 	Code string `yaml:"code,omitempty" json:"code,omitempty"`
 
@@ -482,3 +549,19 @@ type TracePathList struct {
 	// Nodes contains the entities in this path
 	Nodes []string `yaml:"nodes" json:"nodes"`
 }
+
+// QueryOutput represents the result table of a graph query for cx query.
+type QueryOutput struct {
+	// Query is the query text
+	Query string `yaml:"query" json:"query"`
+
+	// Columns lists the RETURN columns in order
+	Columns []string `yaml:"columns" json:"columns"`
+
+	// Rows maps each column name to its value. Nodes are maps of their main
+	// properties and paths are lists of entity names.
+	Rows []map[string]any `yaml:"rows" json:"rows"`
+
+	// Count is the number of rows
+	Count int `yaml:"count" json:"count"`
+}
@@ -735,6 +735,36 @@ func TestYAMLJSONConsistency(t *testing.T) {
 	}
 }
 
+// TestQueryOutputFormats tests JSONL and CGF output of query results
+func TestQueryOutputFormats(t *testing.T) {
+	q := &QueryOutput{
+		Query:   "MATCH (a)-[:calls]->(b) RETURN a, b.name",
+		Columns: []string{"a", "b.name"},
+		Rows: []map[string]any{
+			{"a": map[string]any{"name": "LoginUser", "location": "internal/auth/login.go:45-89"}, "b.name": "ValidateEmail"},
+			{"a": map[string]any{"name": "LoginUser", "location": "internal/auth/login.go:45-89"}, "b.name": "HashPassword"},
+		},
+		Count: 2,
+	}
+
+	var buf bytes.Buffer
+	if err := NewJSONLFormatter().FormatToWriter(&buf, q, DensityMedium); err != nil {
+		t.Fatalf("failed to format JSONL: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !contains(lines[1], `"b.name":"HashPassword"`) {
+		t.Errorf("JSONL should have one line per row, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewCGFFormatter().FormatToWriter(&buf, q, DensityMedium); err != nil {
+		t.Fatalf("failed to format CGF: %v", err)
+	}
+	if !contains(buf.String(), "a=LoginUser@internal/auth/login.go:45-89\tb.name=ValidateEmail") {
+		t.Errorf("CGF row missing, got %q", buf.String())
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
@@ -0,0 +1,977 @@
+// Package query implements a small Cypher-like language over the code graph:
+//
+//	MATCH p = (a:function {file: "internal/mcp/**"})-[:calls*]->(b {name: "Open"})
+//	WHERE NONE(n IN nodes(p) WHERE n.file GLOB "internal/daemon/**")
+//	RETURN a.name, a.file, length(p) AS hops ORDER BY hops LIMIT 20
+//
+// Patterns match entities (nodes) and dependencies (relationships) of the
+// in-memory dependency graph; node properties come from the store (entity
+// attributes, metrics, coverage and tags). Variable-length relationships
+// match each pair of endpoints once, bound to the shortest path between
+// them that satisfies the query's NONE/ALL predicates over nodes(p).
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// Engine runs queries against a snapshot of the graph.
+type Engine struct {
+	g      *graph.Graph
+	nodes  map[string]*graph.ExportNode
+	order  []*graph.ExportNode // sorted by ID
+	byName map[string][]*graph.ExportNode
+	deps   map[[2]string][]*store.Dependency // code dependencies by (from, to)
+}
+
+// NewEngine loads the dependency graph and the attributes of active entities.
+func NewEngine(s store.Store) (*Engine, error) {
+	g, err := graph.BuildFromStore(s)
+	if err != nil {
+		return nil, fmt.Errorf("build graph: %w", err)
+	}
+	attrs, err := graph.BuildExport(s, graph.ExportFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		g:      g,
+		nodes:  make(map[string]*graph.ExportNode, len(attrs.Nodes)),
+		order:  attrs.Nodes,
+		byName: make(map[string][]*graph.ExportNode),
+		deps:   make(map[[2]string][]*store.Dependency),
+	}
+	for _, n := range attrs.Nodes {
+		e.nodes[n.Entity.ID] = n
+		e.byName[n.Entity.Name] = append(e.byName[n.Entity.Name], n)
+	}
+	for _, d := range attrs.Edges {
+		if graph.IsCodeDependency(d.DepType) {
+			key := [2]string{d.FromID, d.ToID}
+			e.deps[key] = append(e.deps[key], d)
+		}
+	}
+	return e, nil
+}
+
+// Result is the table produced by a query. Values are plain data (see Value).
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Run parses and executes a query.
+func (e *Engine) Run(src string) (*Result, error) {
+	q, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return e.Execute(q)
+}
+
+// errStop ends matching once enough rows are found.
+var errStop = errors.New("stop")
+
+// Execute runs a parsed query.
+func (e *Engine) Execute(q *Query) (*Result, error) {
+	m := &matcher{e: e, q: q}
+	if err := m.plan(); err != nil {
+		return nil, err
+	}
+
+	err := m.run(0, scope{})
+	if err != nil && !errors.Is(err, errStop) {
+		return nil, err
+	}
+	return m.project()
+}
+
+// matcher enumerates pattern matches by backtracking, checking each WHERE
+// conjunct as soon as its variables are bound.
+type matcher struct {
+	e        *Engine
+	q        *Query
+	patterns []*plannedPattern
+	steps    int
+	conds    map[int][]Expr // binding step -> conjuncts decidable there
+	vars     []string       // named variables, in binding order
+	rows     []scope
+	maxRows  int // stop after this many matches; 0 for all
+}
+
+type plannedPattern struct {
+	*Pattern
+	reversed bool             // matched from the last node; the path is reversed when bound
+	prune    []*ListPredicate // NONE/ALL(x IN nodes(p) WHERE ...) predicates on every node
+	step     int              // binding step of Nodes[0]; rel i and node i+1 bind at step+1+i
+}
+
+// plan orders the patterns' bindings, pushes WHERE conjuncts down to the
+// earliest step where they can be decided, and anchors each pattern at its
+// most selective end.
+func (m *matcher) plan() error {
+	var conjuncts []Expr
+	splitAnd(m.q.Where, &conjuncts)
+
+	// v.name = "x" and v.id = "x" become node properties, which are indexed.
+	var residual []Expr
+	for _, c := range conjuncts {
+		if !m.pushProperty(c) {
+			residual = append(residual, c)
+		}
+	}
+
+	seen := make(map[string]bool)
+	addVar := func(v string) {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			m.vars = append(m.vars, v)
+		}
+	}
+	boundAt := make(map[string]int)
+	step := 0
+	for _, pat := range m.q.Patterns {
+		pp := &plannedPattern{Pattern: pat}
+		if anchored(pat.Nodes[len(pat.Nodes)-1]) && !anchored(pat.Nodes[0]) {
+			pp.Pattern = reversePattern(pat)
+			pp.reversed = true
+		}
+		pp.step = step
+		for i, n := range pp.Nodes {
+			if i > 0 {
+				bindAt(boundAt, pp.Rels[i-1].Var, step)
+			}
+			bindAt(boundAt, n.Var, step)
+			step++
+		}
+		if pat.PathVar != "" {
+			bindAt(boundAt, pat.PathVar, step)
+			step++
+		}
+		for _, c := range residual {
+			if pat.PathVar != "" {
+				if x, ok := pathPrune(c, pat.PathVar); ok {
+					pp.prune = append(pp.prune, x)
+				}
+			}
+		}
+		m.patterns = append(m.patterns, pp)
+
+		// Variables in pattern order for RETURN *
+		for i, n := range pat.Nodes {
+			if i > 0 {
+				addVar(pat.Rels[i-1].Var)
+			}
+			addVar(n.Var)
+		}
+		addVar(pat.PathVar)
+	}
+	m.steps = step
+
+	m.conds = make(map[int][]Expr)
+	for _, c := range residual {
+		at := 0
+		for v := range freeVars(c) {
+			s, ok := boundAt[v]
+			if !ok {
+				return fmt.Errorf("unknown variable %s in WHERE", v)
+			}
+			at = max(at, s)
+		}
+		m.conds[at] = append(m.conds[at], c)
+	}
+	for _, item := range m.q.Return {
+		for v := range freeVars(item.Expr) {
+			if _, ok := boundAt[v]; !ok {
+				return fmt.Errorf("unknown variable %s in RETURN", v)
+			}
+		}
+	}
+
+	if m.q.Limit > 0 && len(m.q.OrderBy) == 0 && !m.q.Distinct && !m.aggregated() {
+		m.maxRows = m.q.Skip + m.q.Limit
+	}
+	return nil
+}
+
+func bindAt(boundAt map[string]int, v string, step int) {
+	if _, ok := boundAt[v]; v != "" && !ok {
+		boundAt[v] = step
+	}
+}
+
+// pushProperty turns v.name = "x" or v.id = "x" into a property of v's
+// first node pattern, so candidates come from the name index.
+func (m *matcher) pushProperty(c Expr) bool {
+	b, ok := c.(*Binary)
+	if !ok || b.Op != "=" {
+		return false
+	}
+	p, ok := b.L.(*Prop)
+	lit, lok := b.R.(*Literal)
+	if !ok || !lok {
+		return false
+	}
+	v, ok := p.X.(*Var)
+	s, sok := lit.Value.(string)
+	if !ok || !sok || (p.Name != "name" && p.Name != "id") || hasWildcard(s) {
+		return false
+	}
+	for _, pat := range m.q.Patterns {
+		for _, n := range pat.Nodes {
+			if n.Var != v.Name {
+				continue
+			}
+			if _, taken := n.Props[p.Name]; taken {
+				return false
+			}
+			if n.Props == nil {
+				n.Props = make(map[string]any)
+			}
+			n.Props[p.Name] = s
+			return true
+		}
+	}
+	return false
+}
+
+// anchored reports whether a node pattern selects entities by exact name or ID.
+func anchored(n *NodePattern) bool {
+	for _, key := range []string{"id", "name"} {
+		if s, ok := n.Props[key].(string); ok && !hasWildcard(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func reversePattern(p *Pattern) *Pattern {
+	r := &Pattern{PathVar: p.PathVar}
+	for i := len(p.Nodes) - 1; i >= 0; i-- {
+		r.Nodes = append(r.Nodes, p.Nodes[i])
+	}
+	for i := len(p.Rels) - 1; i >= 0; i-- {
+		rel := *p.Rels[i]
+		switch rel.Dir {
+		case Outgoing:
+			rel.Dir = Incoming
+		case Incoming:
+			rel.Dir = Outgoing
+		}
+		r.Rels = append(r.Rels, &rel)
+	}
+	return r
+}
+
+// pathPrune recognizes NONE(x IN nodes(p) WHERE pred) and ALL(...) whose
+// predicate only reads x. Such a predicate must hold for every node on p,
+// so traversal can skip failing nodes instead of filtering paths afterwards.
+func pathPrune(c Expr, pathVar string) (*ListPredicate, bool) {
+	lp, ok := c.(*ListPredicate)
+	if !ok || (lp.Kind != "none" && lp.Kind != "all") {
+		return nil, false
+	}
+	call, ok := lp.List.(*Call)
+	if !ok || call.Name != "nodes" || len(call.Args) != 1 {
+		return nil, false
+	}
+	if v, ok := call.Args[0].(*Var); !ok || v.Name != pathVar {
+		return nil, false
+	}
+	for v := range freeVars(lp.Where) {
+		if v != lp.Var {
+			return nil, false
+		}
+	}
+	return lp, true
+}
+
+func splitAnd(e Expr, out *[]Expr) {
+	if e == nil {
+		return
+	}
+	if b, ok := e.(*Binary); ok && b.Op == "AND" {
+		splitAnd(b.L, out)
+		splitAnd(b.R, out)
+		return
+	}
+	*out = append(*out, e)
+}
+
+// freeVars returns the variables an expression reads.
+func freeVars(e Expr) map[string]bool {
+	vars := make(map[string]bool)
+	var walk func(e Expr, local map[string]bool)
+	walk = func(e Expr, local map[string]bool) {
+		switch e := e.(type) {
+		case *Var:
+			if !local[e.Name] {
+				vars[e.Name] = true
+			}
+		case *Prop:
+			walk(e.X, local)
+		case *List:
+			for _, x := range e.Items {
+				walk(x, local)
+			}
+		case *Unary:
+			walk(e.X, local)
+		case *Binary:
+			walk(e.L, local)
+			walk(e.R, local)
+		case *IsNull:
+			walk(e.X, local)
+		case *Call:
+			for _, x := range e.Args {
+				walk(x, local)
+			}
+		case *ListPredicate:
+			walk(e.List, local)
+			inner := map[string]bool{e.Var: true}
+			for v := range local {
+				inner[v] = true
+			}
+			walk(e.Where, inner)
+		}
+	}
+	walk(e, map[string]bool{})
+	return vars
+}
+
+// check evaluates the conjuncts decidable at a binding step.
+func (m *matcher) check(step int, s scope) (bool, error) {
+	for _, c := range m.conds[step] {
+		v, err := eval(c, s)
+		if err != nil {
+			return false, err
+		}
+		if v != true {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// bind sets v to x, or checks x against v's existing binding. It returns
+// whether the binding holds and a function undoing it.
+func bind(s scope, v string, x any) (bool, func()) {
+	if v == "" {
+		return true, func() {}
+	}
+	if old, ok := s[v]; ok {
+		return old == x, func() {}
+	}
+	s[v] = x
+	return true, func() { delete(s, v) }
+}
+
+func (m *matcher) run(pi int, s scope) error {
+	if pi == len(m.patterns) {
+		row := make(scope, len(s))
+		for k, v := range s {
+			row[k] = v
+		}
+		m.rows = append(m.rows, row)
+		if m.maxRows > 0 && len(m.rows) >= m.maxRows {
+			return errStop
+		}
+		return nil
+	}
+
+	pat := m.patterns[pi]
+	for _, n := range m.candidates(pat, s) {
+		ok, undo := bind(s, pat.Nodes[0].Var, n)
+		if ok {
+			ok, err := m.check(pat.step, s)
+			if err != nil {
+				undo()
+				return err
+			}
+			if ok {
+				if err := m.extend(pi, 0, &Path{Nodes: []*graph.ExportNode{n}}, s); err != nil {
+					undo()
+					return err
+				}
+			}
+		}
+		undo()
+	}
+	return nil
+}
+
+// candidates returns the entities the first node of a pattern can bind to.
+func (m *matcher) candidates(pat *plannedPattern, s scope) []*graph.ExportNode {
+	np := pat.Nodes[0]
+	var pool []*graph.ExportNode
+	if x, ok := s[np.Var]; ok && np.Var != "" {
+		if n, ok := x.(*graph.ExportNode); ok {
+			pool = []*graph.ExportNode{n}
+		}
+	} else if id, ok := np.Props["id"].(string); ok && !hasWildcard(id) {
+		if n := m.e.nodes[id]; n != nil {
+			pool = []*graph.ExportNode{n}
+		}
+	} else if name, ok := np.Props["name"].(string); ok && !hasWildcard(name) {
+		pool = m.e.byName[name]
+	} else {
+		pool = m.e.order
+	}
+
+	var out []*graph.ExportNode
+	for _, n := range pool {
+		if m.nodeMatches(np, n) && m.allowed(pat, n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (m *matcher) nodeMatches(np *NodePattern, n *graph.ExportNode) bool {
+	if len(np.Labels) > 0 {
+		found := false
+		for _, l := range np.Labels {
+			if strings.EqualFold(l, n.Entity.EntityType) || (n.Entity.Kind != "" && strings.EqualFold(l, n.Entity.Kind)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for key, want := range np.Props {
+		if !matchProperty(n, key, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowed reports whether n may appear on the pattern's path at all.
+func (m *matcher) allowed(pat *plannedPattern, n *graph.ExportNode) bool {
+	for _, lp := range pat.prune {
+		v, err := eval(lp.Where, scope{lp.Var: n})
+		if err != nil {
+			return true // leave the error to the full predicate
+		}
+		if (lp.Kind == "none" && v == true) || (lp.Kind == "all" && v != true) {
+			return false
+		}
+	}
+	return true
+}
+
+// extend matches relationship ri of a pattern and the rest after it.
+func (m *matcher) extend(pi, ri int, path *Path, s scope) error {
+	pat := m.patterns[pi]
+	if ri == len(pat.Rels) {
+		if pat.PathVar == "" {
+			return m.run(pi+1, s)
+		}
+		p := path
+		if pat.reversed {
+			p = reversePath(path)
+		}
+		ok, undo := bind(s, pat.PathVar, p)
+		defer undo()
+		if !ok {
+			return nil
+		}
+		if ok, err := m.check(pat.step+len(pat.Nodes), s); err != nil || !ok {
+			return err
+		}
+		return m.run(pi+1, s)
+	}
+
+	rel, next := pat.Rels[ri], pat.Nodes[ri+1]
+	cur := path.Nodes[len(path.Nodes)-1]
+	for _, h := range m.hops(pat, cur, rel) {
+		end := h.end
+		if !m.nodeMatches(next, end) {
+			continue
+		}
+		var relValue any
+		if rel.VarLen {
+			rels := make([]any, len(h.rels))
+			for i, r := range h.rels {
+				rels[i] = r
+			}
+			relValue = rels
+		} else {
+			relValue = h.rels[0]
+		}
+
+		okRel, undoRel := bind(s, rel.Var, relValue)
+		okNode, undoNode := bind(s, next.Var, end)
+		var err error
+		if okRel && okNode {
+			var ok bool
+			if ok, err = m.check(pat.step+ri+1, s); err == nil && ok {
+				err = m.extend(pi, ri+1, &Path{
+					Nodes: append(append([]*graph.ExportNode{}, path.Nodes...), h.nodes...),
+					Rels:  append(append([]*store.Dependency{}, path.Rels...), h.rels...),
+				}, s)
+			}
+		}
+		undoNode()
+		undoRel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reversePath(p *Path) *Path {
+	r := &Path{}
+	for i := len(p.Nodes) - 1; i >= 0; i-- {
+		r.Nodes = append(r.Nodes, p.Nodes[i])
+	}
+	for i := len(p.Rels) - 1; i >= 0; i-- {
+		r.Rels = append(r.Rels, p.Rels[i])
+	}
+	return r
+}
+
+// hop is one way across a relationship pattern: the dependencies taken, the
+// nodes reached after each, and the far endpoint (the start node itself for
+// a zero-length hop).
+type hop struct {
+	nodes []*graph.ExportNode
+	rels  []*store.Dependency
+	end   *graph.ExportNode
+}
+
+// step is a single dependency from one node to a neighbour.
+type step struct {
+	to  *graph.ExportNode
+	dep *store.Dependency
+}
+
+// neighbours returns the nodes one dependency away from n that match the
+// relationship's types and direction, once each.
+func (m *matcher) neighbours(pat *plannedPattern, n *graph.ExportNode, rel *RelPattern) []step {
+	var out []step
+	seen := make(map[string]bool)
+	visit := func(ids []string, forward bool) {
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			to := m.e.nodes[id]
+			if to == nil || !m.allowed(pat, to) {
+				continue
+			}
+			key := [2]string{n.Entity.ID, id}
+			if !forward {
+				key = [2]string{id, n.Entity.ID}
+			}
+			if d := m.e.dependency(key, rel.Types); d != nil {
+				seen[id] = true
+				out = append(out, step{to: to, dep: d})
+			}
+		}
+	}
+	if rel.Dir != Incoming {
+		visit(m.e.g.Edges[n.Entity.ID], true)
+	}
+	if rel.Dir != Outgoing {
+		visit(m.e.g.ReverseEdges[n.Entity.ID], false)
+	}
+	return out
+}
+
+// dependency returns the first dependency between a pair with one of the
+// given types (any type if none are given).
+func (e *Engine) dependency(key [2]string, types []string) *store.Dependency {
+	for _, d := range e.deps[key] {
+		if len(types) == 0 {
+			return d
+		}
+		for _, t := range types {
+			if strings.EqualFold(d.DepType, t) {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// hops expands a relationship pattern from cur. A fixed single hop yields
+// every matching neighbour; a variable-length one runs a breadth-first
+// search and yields each reachable node once, by its shortest path of
+// min..max dependencies.
+func (m *matcher) hops(pat *plannedPattern, cur *graph.ExportNode, rel *RelPattern) []hop {
+	if !rel.VarLen {
+		var out []hop
+		for _, st := range m.neighbours(pat, cur, rel) {
+			out = append(out, hop{nodes: []*graph.ExportNode{st.to}, rels: []*store.Dependency{st.dep}, end: st.to})
+		}
+		return out
+	}
+
+	// Search states are (node, hops so far capped at Min): reaching
+	// (v, Min) means a walk of at least Min hops ends at v, and BFS order
+	// makes the first such walk the shortest.
+	type state struct {
+		id   string
+		hops int
+	}
+	type visit struct {
+		prev  state
+		dep   *store.Dependency
+		node  *graph.ExportNode
+		depth int
+	}
+	start := state{cur.Entity.ID, 0}
+	visits := map[state]visit{start: {node: cur}}
+	queue := []state{start}
+	var out []hop
+
+	for len(queue) > 0 {
+		st := queue[0]
+		queue = queue[1:]
+		v := visits[st]
+		if st.hops == rel.Min {
+			h := hop{end: v.node}
+			for at := st; at != start; at = visits[at].prev {
+				h.nodes = append([]*graph.ExportNode{visits[at].node}, h.nodes...)
+				h.rels = append([]*store.Dependency{visits[at].dep}, h.rels...)
+			}
+			out = append(out, h)
+		}
+		if rel.Max > 0 && v.depth >= rel.Max {
+			continue
+		}
+		for _, nb := range m.neighbours(pat, v.node, rel) {
+			next := state{nb.to.Entity.ID, min(st.hops+1, rel.Min)}
+			if _, ok := visits[next]; ok {
+				continue
+			}
+			visits[next] = visit{prev: st, dep: nb.dep, node: nb.to, depth: v.depth + 1}
+			queue = append(queue, next)
+		}
+	}
+	return out
+}
+
+func (m *matcher) aggregated() bool {
+	for _, item := range m.q.Return {
+		if hasAggregate(item.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAggregate(e Expr) bool {
+	found := false
+	walkExpr(e, func(e Expr) {
+		if c, ok := e.(*Call); ok && aggregates[c.Name] {
+			found = true
+		}
+	})
+	return found
+}
+
+func walkExpr(e Expr, fn func(Expr)) {
+	fn(e)
+	switch e := e.(type) {
+	case *Prop:
+		walkExpr(e.X, fn)
+	case *List:
+		for _, x := range e.Items {
+			walkExpr(x, fn)
+		}
+	case *Unary:
+		walkExpr(e.X, fn)
+	case *Binary:
+		walkExpr(e.L, fn)
+		walkExpr(e.R, fn)
+	case *IsNull:
+		walkExpr(e.X, fn)
+	case *Call:
+		for _, x := range e.Args {
+			walkExpr(x, fn)
+		}
+	case *ListPredicate:
+		walkExpr(e.List, fn)
+		walkExpr(e.Where, fn)
+	}
+}
+
+// outRow is a projected row with the scope ORDER BY evaluates in.
+type outRow struct {
+	values []any
+	scope  scope
+	group  []scope
+}
+
+// project evaluates RETURN over the matched rows, grouping by the
+// non-aggregate columns when any column aggregates, then applies DISTINCT,
+// ORDER BY, SKIP and LIMIT.
+func (m *matcher) project() (*Result, error) {
+	items := m.q.Return
+	if items == nil {
+		if len(m.vars) == 0 {
+			return nil, fmt.Errorf("RETURN * needs at least one named variable")
+		}
+		for _, v := range m.vars {
+			items = append(items, &ReturnItem{Expr: &Var{Name: v}, Name: v})
+		}
+	}
+	res := &Result{}
+	for _, item := range items {
+		res.Columns = append(res.Columns, item.Name)
+	}
+
+	var rows []*outRow
+	if m.aggregated() {
+		groups := make(map[string]*outRow)
+		var order []string
+		for _, s := range m.rows {
+			var keys []string
+			for _, item := range items {
+				if hasAggregate(item.Expr) {
+					continue
+				}
+				v, err := eval(item.Expr, s)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, valueKey(v))
+			}
+			key := strings.Join(keys, "\x00")
+			g, ok := groups[key]
+			if !ok {
+				g = &outRow{scope: s}
+				groups[key] = g
+				order = append(order, key)
+			}
+			g.group = append(g.group, s)
+		}
+		// With no grouping columns, aggregates over no rows still give one row
+		if len(m.rows) == 0 && !hasGrouping(items) {
+			groups[""] = &outRow{scope: scope{}}
+			order = append(order, "")
+		}
+		for _, key := range order {
+			g := groups[key]
+			for _, item := range items {
+				e, err := substituteAggregates(item.Expr, g.group)
+				if err != nil {
+					return nil, err
+				}
+				v, err := eval(e, g.scope)
+				if err != nil {
+					return nil, err
+				}
+				g.values = append(g.values, v)
+			}
+			rows = append(rows, g)
+		}
+	} else {
+		for _, s := range m.rows {
+			r := &outRow{scope: s, group: []scope{s}}
+			for _, item := range items {
+				v, err := eval(item.Expr, s)
+				if err != nil {
+					return nil, err
+				}
+				r.values = append(r.values, v)
+			}
+			rows = append(rows, r)
+		}
+	}
+
+	if m.q.Distinct {
+		seen := make(map[string]bool)
+		unique := rows[:0]
+		for _, r := range rows {
+			var keys []string
+			for _, v := range r.values {
+				keys = append(keys, valueKey(v))
+			}
+			key := strings.Join(keys, "\x00")
+			if !seen[key] {
+				seen[key] = true
+				unique = append(unique, r)
+			}
+		}
+		rows = unique
+	}
+
+	if len(m.q.OrderBy) > 0 {
+		if err := m.sortRows(rows, items); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.q.Skip > 0 {
+		rows = rows[min(m.q.Skip, len(rows)):]
+	}
+	if m.q.Limit > 0 && len(rows) > m.q.Limit {
+		rows = rows[:m.q.Limit]
+	}
+	for _, r := range rows {
+		vals := make([]any, len(r.values))
+		for i, v := range r.values {
+			vals[i] = Value(v)
+		}
+		res.Rows = append(res.Rows, vals)
+	}
+	return res, nil
+}
+
+func hasGrouping(items []*ReturnItem) bool {
+	for _, item := range items {
+		if !hasAggregate(item.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRows orders rows by the ORDER BY keys. A key naming a column (by
+// alias or text) sorts by that column; other keys are evaluated with the
+// columns' aliases in scope.
+func (m *matcher) sortRows(rows []*outRow, items []*ReturnItem) error {
+	keys := make([][]any, len(rows))
+	for i, r := range rows {
+		s := make(scope, len(r.scope)+len(items))
+		for k, v := range r.scope {
+			s[k] = v
+		}
+		for j, item := range items {
+			s[item.Name] = r.values[j]
+		}
+		for _, o := range m.q.OrderBy {
+			if j := columnIndex(items, o.Text); j >= 0 {
+				keys[i] = append(keys[i], r.values[j])
+				continue
+			}
+			e, err := substituteAggregates(o.Expr, r.group)
+			if err != nil {
+				return err
+			}
+			v, err := eval(e, s)
+			if err != nil {
+				return err
+			}
+			keys[i] = append(keys[i], v)
+		}
+	}
+
+	idx := make([]int, len(rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		for k, o := range m.q.OrderBy {
+			c := sortKey(keys[idx[a]][k], keys[idx[b]][k])
+			if o.Desc {
+				// nulls stay last when descending
+				if keys[idx[a]][k] != nil && keys[idx[b]][k] != nil {
+					c = -c
+				}
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	sorted := make([]*outRow, len(rows))
+	for i, j := range idx {
+		sorted[i] = rows[j]
+	}
+	copy(rows, sorted)
+	return nil
+}
+
+func columnIndex(items []*ReturnItem, text string) int {
+	for i, item := range items {
+		if item.Name == text {
+			return i
+		}
+	}
+	return -1
+}
+
+// substituteAggregates replaces each aggregate call in e with its value over
+// the group's rows.
+func substituteAggregates(e Expr, group []scope) (Expr, error) {
+	var err error
+	sub := func(x Expr) Expr {
+		if err != nil {
+			return x
+		}
+		var out Expr
+		out, err = substituteAggregates(x, group)
+		return out
+	}
+	switch x := e.(type) {
+	case *Call:
+		if aggregates[x.Name] {
+			values := make([]any, 0, len(group))
+			for _, s := range group {
+				if x.Star {
+					values = append(values, true)
+					continue
+				}
+				if len(x.Args) != 1 {
+					return nil, fmt.Errorf("%s() takes one argument", x.Name)
+				}
+				v, err := eval(x.Args[0], s)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, v)
+			}
+			v, err := aggregate(x, values)
+			if err != nil {
+				return nil, err
+			}
+			return &Literal{Value: v}, nil
+		}
+		c := *x
+		c.Args = make([]Expr, len(x.Args))
+		for i, a := range x.Args {
+			c.Args[i] = sub(a)
+		}
+		return &c, err
+	case *Binary:
+		return &Binary{Op: x.Op, L: sub(x.L), R: sub(x.R)}, err
+	case *Unary:
+		return &Unary{Op: x.Op, X: sub(x.X)}, err
+	case *IsNull:
+		return &IsNull{X: sub(x.X), Not: x.Not}, err
+	case *Prop:
+		return &Prop{X: sub(x.X), Name: x.Name}, err
+	case *List:
+		l := &List{Items: make([]Expr, len(x.Items))}
+		for i, item := range x.Items {
+			l.Items[i] = sub(item)
+		}
+		return l, err
+	}
+	return e, nil
+}
+
+// valueKey identifies a value for grouping and DISTINCT.
+func valueKey(v any) string {
+	switch x := v.(type) {
+	case *graph.ExportNode:
+		return "n:" + x.Entity.ID
+	case *store.Dependency:
+		return "r:" + x.FromID + "\x00" + x.ToID + "\x00" + x.DepType
+	}
+	return fmt.Sprintf("%T:%v", v, Value(v))
+}
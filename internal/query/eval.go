@@ -0,0 +1,866 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// Path is the value bound to a path variable: alternating nodes and
+// relationships, len(Nodes) == len(Rels)+1.
+type Path struct {
+	Nodes []*graph.ExportNode
+	Rels  []*store.Dependency
+}
+
+// scope holds variable bindings during evaluation.
+type scope map[string]any
+
+// eval evaluates e. Values are nil, bool, float64, string, []any,
+// *graph.ExportNode, *store.Dependency and *Path. Operators on null yield
+// null, which WHERE treats as false.
+func eval(e Expr, s scope) (any, error) {
+	switch e := e.(type) {
+	case *Literal:
+		return e.Value, nil
+
+	case *Var:
+		v, ok := s[e.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %s", e.Name)
+		}
+		return v, nil
+
+	case *Prop:
+		x, err := eval(e.X, s)
+		if err != nil {
+			return nil, err
+		}
+		return property(x, e.Name)
+
+	case *List:
+		items := make([]any, len(e.Items))
+		for i, item := range e.Items {
+			v, err := eval(item, s)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+
+	case *Unary:
+		x, err := eval(e.X, s)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		if e.Op == "NOT" {
+			b, ok := x.(bool)
+			if !ok {
+				return nil, fmt.Errorf("NOT needs a boolean, got %s", typeName(x))
+			}
+			return !b, nil
+		}
+		f, ok := x.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", typeName(x))
+		}
+		return -f, nil
+
+	case *IsNull:
+		x, err := eval(e.X, s)
+		if err != nil {
+			return nil, err
+		}
+		return (x == nil) != e.Not, nil
+
+	case *Binary:
+		return evalBinary(e, s)
+
+	case *Call:
+		if aggregates[e.Name] {
+			return nil, fmt.Errorf("%s() is only allowed in RETURN", e.Name)
+		}
+		args := make([]any, len(e.Args))
+		for i, a := range e.Args {
+			v, err := eval(a, s)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return functions[e.Name](args)
+
+	case *ListPredicate:
+		return evalListPredicate(e, s)
+	}
+	return nil, fmt.Errorf("cannot evaluate %T", e)
+}
+
+func evalBinary(e *Binary, s scope) (any, error) {
+	l, err := eval(e.L, s)
+	if err != nil {
+		return nil, err
+	}
+	// AND and OR use three-valued logic and short-circuit on a decided side.
+	if e.Op == "AND" || e.Op == "OR" {
+		decided := e.Op == "OR"
+		if b, ok := l.(bool); ok && b == decided {
+			return decided, nil
+		}
+		r, err := eval(e.R, s)
+		if err != nil {
+			return nil, err
+		}
+		if b, ok := r.(bool); ok && b == decided {
+			return decided, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		if _, ok := l.(bool); !ok {
+			return nil, fmt.Errorf("%s needs booleans, got %s", e.Op, typeName(l))
+		}
+		if _, ok := r.(bool); !ok {
+			return nil, fmt.Errorf("%s needs booleans, got %s", e.Op, typeName(r))
+		}
+		return !decided, nil
+	}
+
+	r, err := eval(e.R, s)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	switch e.Op {
+	case "=":
+		return equal(l, r), nil
+	case "<>":
+		return !equal(l, r), nil
+	case "<", "<=", ">", ">=":
+		c, ok := compare(l, r)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %s with %s", typeName(l), typeName(r))
+		}
+		switch e.Op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "IN":
+		list, ok := r.([]any)
+		if !ok {
+			return nil, fmt.Errorf("IN needs a list, got %s", typeName(r))
+		}
+		for _, item := range list {
+			if equal(l, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if ls, ok := l.([]any); ok && e.Op == "CONTAINS" {
+		for _, item := range ls {
+			if equal(item, r) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if e.Op == "+" || e.Op == "-" || e.Op == "*" || e.Op == "/" || e.Op == "%" {
+		return arithmetic(e.Op, l, r)
+	}
+
+	ls, lok := l.(string)
+	rs, rok := r.(string)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s needs strings, got %s and %s", e.Op, typeName(l), typeName(r))
+	}
+	switch e.Op {
+	case "=~":
+		re, err := compileRegexp(rs)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(ls), nil
+	case "GLOB":
+		return globMatch(rs, ls), nil
+	case "CONTAINS":
+		return strings.Contains(ls, rs), nil
+	case "STARTS":
+		return strings.HasPrefix(ls, rs), nil
+	case "ENDS":
+		return strings.HasSuffix(ls, rs), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", e.Op)
+}
+
+func arithmetic(op string, l, r any) (any, error) {
+	if op == "+" {
+		if ls, ok := l.(string); ok {
+			return ls + toString(r), nil
+		}
+		if ll, ok := l.([]any); ok {
+			if rl, ok := r.([]any); ok {
+				return append(append([]any{}, ll...), rl...), nil
+			}
+			return append(append([]any{}, ll...), r), nil
+		}
+	}
+	lf, lok := l.(float64)
+	rf, rok := r.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s needs numbers, got %s and %s", op, typeName(l), typeName(r))
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, nil
+		}
+		return lf / rf, nil
+	default:
+		if rf == 0 {
+			return nil, nil
+		}
+		return math.Mod(lf, rf), nil
+	}
+}
+
+func evalListPredicate(e *ListPredicate, s scope) (any, error) {
+	x, err := eval(e.List, s)
+	if err != nil || x == nil {
+		return nil, err
+	}
+	list, ok := x.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s() needs a list, got %s", e.Kind, typeName(x))
+	}
+
+	saved, had := s[e.Var]
+	defer func() {
+		if had {
+			s[e.Var] = saved
+		} else {
+			delete(s, e.Var)
+		}
+	}()
+
+	matches := 0
+	for _, item := range list {
+		s[e.Var] = item
+		v, err := eval(e.Where, s)
+		if err != nil {
+			return nil, err
+		}
+		if v == true {
+			matches++
+			if e.Kind == "any" {
+				return true, nil
+			}
+			if e.Kind == "none" || (e.Kind == "single" && matches > 1) {
+				return false, nil
+			}
+		} else if e.Kind == "all" {
+			return false, nil
+		}
+	}
+	switch e.Kind {
+	case "any":
+		return false, nil
+	case "single":
+		return matches == 1, nil
+	default: // all, none
+		return true, nil
+	}
+}
+
+// evalConstant evaluates an expression that references no variables.
+func evalConstant(e Expr) (any, error) {
+	return eval(e, scope{})
+}
+
+// property returns a property of a node or relationship, or nil if unknown.
+func property(x any, name string) (any, error) {
+	switch v := x.(type) {
+	case nil:
+		return nil, nil
+	case *graph.ExportNode:
+		return nodeProperty(v, name), nil
+	case *store.Dependency:
+		switch name {
+		case "type":
+			return v.DepType, nil
+		case "source":
+			if v.Source == "" {
+				return store.DepSourceTreeSitter, nil
+			}
+			return v.Source, nil
+		case "optional":
+			return v.Optional, nil
+		case "from":
+			return v.FromID, nil
+		case "to":
+			return v.ToID, nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("cannot read .%s of %s", name, typeName(x))
+}
+
+// NodeProperties lists the properties of a node, for help text.
+var NodeProperties = []string{
+	"id", "name", "type", "kind", "file", "dir", "package", "line", "line_end",
+	"language", "signature", "visibility", "receiver", "doc",
+	"pagerank", "betweenness", "in_degree", "out_degree", "coverage", "tags",
+}
+
+// nodeProperty returns a node property. Metrics and coverage are null when
+// they have not been computed or imported.
+func nodeProperty(n *graph.ExportNode, name string) any {
+	e := n.Entity
+	str := func(s string) any {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+	switch name {
+	case "id":
+		return e.ID
+	case "name":
+		return e.Name
+	case "type":
+		return e.EntityType
+	case "kind":
+		return str(e.Kind)
+	case "file":
+		return e.FilePath
+	case "dir":
+		return path.Dir(e.FilePath)
+	case "package":
+		return path.Base(path.Dir(e.FilePath))
+	case "line":
+		return float64(e.LineStart)
+	case "line_end":
+		if e.LineEnd == nil {
+			return nil
+		}
+		return float64(*e.LineEnd)
+	case "language":
+		return str(e.Language)
+	case "signature":
+		return str(e.Signature)
+	case "visibility":
+		return str(e.Visibility)
+	case "receiver":
+		return str(e.Receiver)
+	case "doc":
+		return str(e.DocComment)
+	case "tags":
+		tags := make([]any, len(n.Tags))
+		for i, t := range n.Tags {
+			tags[i] = t
+		}
+		return tags
+	case "coverage":
+		if n.Coverage == nil {
+			return nil
+		}
+		return n.Coverage.CoveragePercent
+	}
+	if m := n.Metrics; m != nil {
+		switch name {
+		case "pagerank":
+			return m.PageRank
+		case "betweenness":
+			return m.Betweenness
+		case "in_degree":
+			return float64(m.InDegree)
+		case "out_degree":
+			return float64(m.OutDegree)
+		}
+	}
+	return nil
+}
+
+// matchProperty reports whether a node property matches a pattern value
+// from {key: value}. Strings with * or ? are globs; tags match when the
+// node has the tag (or all tags of a list).
+func matchProperty(n *graph.ExportNode, key string, want any) bool {
+	got := nodeProperty(n, key)
+	if key == "tags" {
+		wants, ok := want.([]any)
+		if !ok {
+			wants = []any{want}
+		}
+		for _, w := range wants {
+			ws, _ := w.(string)
+			found := false
+			for _, t := range n.Tags {
+				if t == ws || (hasWildcard(ws) && globMatch(ws, t)) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	if ws, ok := want.(string); ok {
+		gs, ok := got.(string)
+		if !ok {
+			return false
+		}
+		if hasWildcard(ws) {
+			return globMatch(ws, gs)
+		}
+		if key == "type" || key == "kind" || key == "language" {
+			return strings.EqualFold(gs, ws)
+		}
+		return gs == ws
+	}
+	return equal(got, want)
+}
+
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case []any:
+		bl, ok := b.([]any)
+		if !ok || len(a) != len(bl) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], bl[i]) {
+				return false
+			}
+		}
+		return true
+	case *Path:
+		bp, ok := b.(*Path)
+		return ok && equal(pathNodes(a), pathNodes(bp))
+	}
+	return a == b
+}
+
+// compare orders two numbers, strings or booleans.
+func compare(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			switch {
+			case a == b:
+				return 0, true
+			case !a:
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+// sortKey orders values for ORDER BY: nulls last, then by type, then by value.
+func sortKey(a, b any) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return 1
+		}
+		return -1
+	}
+	if c, ok := compare(a, b); ok {
+		return c
+	}
+	return strings.Compare(toString(a), toString(b))
+}
+
+func typeName(x any) string {
+	switch x.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	case *graph.ExportNode:
+		return "node"
+	case *store.Dependency:
+		return "relationship"
+	case *Path:
+		return "path"
+	}
+	return fmt.Sprintf("%T", x)
+}
+
+func toString(x any) string {
+	switch v := x.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%g", v)
+	case *graph.ExportNode:
+		return v.Entity.Name
+	case *store.Dependency:
+		return v.DepType
+	}
+	return fmt.Sprint(Value(x))
+}
+
+func pathNodes(p *Path) []any {
+	nodes := make([]any, len(p.Nodes))
+	for i, n := range p.Nodes {
+		nodes[i] = n
+	}
+	return nodes
+}
+
+// functions are the scalar functions callable in expressions.
+var functions = map[string]func(args []any) (any, error){
+	"length": func(args []any) (any, error) {
+		if err := arity("length", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		switch v := args[0].(type) {
+		case *Path:
+			return float64(len(v.Rels)), nil
+		case []any:
+			return float64(len(v)), nil
+		case string:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("length() needs a path, list or string, got %s", typeName(args[0]))
+	},
+	"size": func(args []any) (any, error) {
+		if err := arity("size", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		switch v := args[0].(type) {
+		case []any:
+			return float64(len(v)), nil
+		case string:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("size() needs a list or string, got %s", typeName(args[0]))
+	},
+	"nodes": func(args []any) (any, error) {
+		if err := arity("nodes", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		p, ok := args[0].(*Path)
+		if !ok {
+			return nil, fmt.Errorf("nodes() needs a path, got %s", typeName(args[0]))
+		}
+		return pathNodes(p), nil
+	},
+	"relationships": func(args []any) (any, error) {
+		if err := arity("relationships", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		p, ok := args[0].(*Path)
+		if !ok {
+			return nil, fmt.Errorf("relationships() needs a path, got %s", typeName(args[0]))
+		}
+		rels := make([]any, len(p.Rels))
+		for i, r := range p.Rels {
+			rels[i] = r
+		}
+		return rels, nil
+	},
+	"type": func(args []any) (any, error) {
+		if err := arity("type", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		return property(args[0], "type")
+	},
+	"id": func(args []any) (any, error) {
+		if err := arity("id", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		return property(args[0], "id")
+	},
+	"labels": func(args []any) (any, error) {
+		if err := arity("labels", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		n, ok := args[0].(*graph.ExportNode)
+		if !ok {
+			return nil, fmt.Errorf("labels() needs a node, got %s", typeName(args[0]))
+		}
+		labels := []any{n.Entity.EntityType}
+		if n.Entity.Kind != "" {
+			labels = append(labels, n.Entity.Kind)
+		}
+		return labels, nil
+	},
+	"tolower": func(args []any) (any, error) {
+		if err := arity("toLower", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		return strings.ToLower(toString(args[0])), nil
+	},
+	"toupper": func(args []any) (any, error) {
+		if err := arity("toUpper", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		return strings.ToUpper(toString(args[0])), nil
+	},
+	"tostring": func(args []any) (any, error) {
+		if err := arity("toString", args, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		return toString(args[0]), nil
+	},
+	"coalesce": func(args []any) (any, error) {
+		for _, a := range args {
+			if a != nil {
+				return a, nil
+			}
+		}
+		return nil, nil
+	},
+	"round": func(args []any) (any, error) {
+		if len(args) == 0 || len(args) > 2 {
+			return nil, fmt.Errorf("round() takes a number and optional precision")
+		}
+		f, ok := args[0].(float64)
+		if !ok {
+			return nil, nil
+		}
+		scale := 1.0
+		if len(args) == 2 {
+			if p, ok := args[1].(float64); ok {
+				scale = math.Pow(10, p)
+			}
+		}
+		return math.Round(f*scale) / scale, nil
+	},
+}
+
+func arity(name string, args []any, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("%s() takes %d argument(s), got %d", name, n, len(args))
+	}
+	return nil
+}
+
+// aggregates are the functions computed over groups of rows in RETURN.
+var aggregates = map[string]bool{"count": true, "collect": true, "sum": true, "avg": true, "min": true, "max": true}
+
+// aggregate computes an aggregate function over the values of a group.
+// Nulls are skipped, as in Cypher.
+func aggregate(c *Call, values []any) (any, error) {
+	var vals []any
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if v == nil && !c.Star {
+			continue
+		}
+		if c.Distinct {
+			key := fmt.Sprintf("%T:%v", v, Value(v))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		vals = append(vals, v)
+	}
+
+	switch c.Name {
+	case "count":
+		return float64(len(vals)), nil
+	case "collect":
+		if vals == nil {
+			vals = []any{}
+		}
+		return vals, nil
+	case "sum", "avg":
+		sum := 0.0
+		for _, v := range vals {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, fmt.Errorf("%s() needs numbers, got %s", c.Name, typeName(v))
+			}
+			sum += f
+		}
+		if c.Name == "avg" {
+			if len(vals) == 0 {
+				return nil, nil
+			}
+			return sum / float64(len(vals)), nil
+		}
+		return sum, nil
+	default: // min, max
+		var best any
+		for _, v := range vals {
+			if best == nil {
+				best = v
+				continue
+			}
+			c2, ok := compare(v, best)
+			if !ok {
+				return nil, fmt.Errorf("%s() cannot compare %s with %s", c.Name, typeName(v), typeName(best))
+			}
+			if (c.Name == "min" && c2 < 0) || (c.Name == "max" && c2 > 0) {
+				best = v
+			}
+		}
+		return best, nil
+	}
+}
+
+func hasWildcard(s string) bool {
+	return strings.ContainsAny(s, "*?")
+}
+
+var (
+	regexpMu    sync.Mutex
+	regexpCache = make(map[string]*regexp.Regexp)
+)
+
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpMu.Lock()
+	defer regexpMu.Unlock()
+	if re, ok := regexpCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("bad regular expression %q: %w", pattern, err)
+	}
+	regexpCache[pattern] = re
+	return re, nil
+}
+
+// globMatch matches s against a glob where * matches within a path segment,
+// ** matches across segments and ? matches one character.
+func globMatch(pattern, s string) bool {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?") // **/ also matches no directories
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re, err := compileRegexp(b.String())
+	return err == nil && re.MatchString(s)
+}
+
+// Value converts an evaluated value to plain data for output: nodes become
+// maps of their main properties, relationships maps of their endpoints and
+// type, paths lists of entity names, and whole numbers integers.
+func Value(x any) any {
+	switch v := x.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return int64(v)
+		}
+		return v
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = Value(item)
+		}
+		return out
+	case *graph.ExportNode:
+		e := v.Entity
+		node := map[string]any{
+			"id":       e.ID,
+			"name":     e.Name,
+			"type":     e.EntityType,
+			"location": location(e),
+		}
+		if v.Metrics != nil {
+			node["pagerank"] = v.Metrics.PageRank
+		}
+		if len(v.Tags) > 0 {
+			tags := append([]string(nil), v.Tags...)
+			sort.Strings(tags)
+			node["tags"] = tags
+		}
+		return node
+	case *store.Dependency:
+		rel := map[string]any{"from": v.FromID, "to": v.ToID, "type": v.DepType}
+		if v.Optional {
+			rel["optional"] = true
+		}
+		return rel
+	case *Path:
+		names := make([]any, len(v.Nodes))
+		for i, n := range v.Nodes {
+			names[i] = n.Entity.Name
+		}
+		return names
+	}
+	return x
+}
+
+func location(e *store.Entity) string {
+	if e.LineEnd != nil && *e.LineEnd > e.LineStart {
+		return fmt.Sprintf("%s:%d-%d", e.FilePath, e.LineStart, *e.LineEnd)
+	}
+	return fmt.Sprintf("%s:%d", e.FilePath, e.LineStart)
+}
@@ -0,0 +1,137 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+// token is a lexical token. Keywords are identifiers; the parser matches
+// them case-insensitively.
+type token struct {
+	kind tokenKind
+	text string // identifier, punctuation, unquoted string, or number literal
+	pos  int    // byte offset in the query
+	end  int
+}
+
+// punctuation, longest first so that "->" wins over "-".
+var punctuation = []string{
+	"..", "->", "<-", "<>", "!=", "<=", ">=", "=~",
+	"(", ")", "[", "]", "{", "}", ":", ",", ".", "|", "*", "=", "<", ">", "-", "+", "/", "%",
+}
+
+// lex splits a query into tokens.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case r == '/' && strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case r == '\'' || r == '"':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				return nil, &Error{Pos: i, Msg: err.Error()}
+			}
+			toks = append(toks, token{kind: tokString, text: s, pos: i, end: i + n})
+			i += n
+
+		case r == '`':
+			j := strings.IndexByte(src[i+1:], '`')
+			if j < 0 {
+				return nil, &Error{Pos: i, Msg: "unterminated `identifier`"}
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i+1 : i+1+j], pos: i, end: i + j + 2})
+			i += j + 2
+
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(src) && isDigit(src[j]) {
+				j++
+			}
+			if j+1 < len(src) && src[j] == '.' && isDigit(src[j+1]) {
+				j++
+				for j < len(src) && isDigit(src[j]) {
+					j++
+				}
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], pos: i, end: j})
+			i = j
+
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(src) {
+				r, size := utf8.DecodeRuneInString(src[j:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += size
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], pos: i, end: j})
+			i = j
+
+		default:
+			matched := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(src[i:], p) {
+					toks = append(toks, token{kind: tokPunct, text: p, pos: i, end: i + len(p)})
+					i += len(p)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &Error{Pos: i, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(src), end: len(src)}), nil
+}
+
+// lexString reads a quoted string with backslash escapes, returning its
+// value and the number of bytes consumed.
+func lexString(src string) (string, int, error) {
+	quote := src[0]
+	var b strings.Builder
+	for i := 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(src[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
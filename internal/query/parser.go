@@ -0,0 +1,757 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Error is a syntax or evaluation error at a position in the query.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query: at offset %d: %s", e.Pos, e.Msg)
+}
+
+// Query is a parsed query:
+//
+//	MATCH <pattern>, ... [WHERE <expr>]
+//	RETURN [DISTINCT] <expr> [AS <name>], ...
+//	[ORDER BY <expr> [ASC|DESC], ...] [SKIP <n>] [LIMIT <n>]
+type Query struct {
+	Patterns []*Pattern
+	Where    Expr
+	Distinct bool
+	Return   []*ReturnItem // nil for RETURN *
+	OrderBy  []*OrderItem
+	Skip     int
+	Limit    int // 0 for no limit
+}
+
+// Pattern is a chain of node patterns joined by relationship patterns,
+// optionally bound to a path variable: p = (a)-[:calls*]->(b).
+type Pattern struct {
+	PathVar string
+	Nodes   []*NodePattern
+	Rels    []*RelPattern // Rels[i] joins Nodes[i] and Nodes[i+1]
+}
+
+// NodePattern matches entities: (v:function|method {file: "internal/**"}).
+type NodePattern struct {
+	Var    string
+	Labels []string // entity types or kinds, any of which matches
+	Props  map[string]any
+}
+
+// Direction of a relationship pattern.
+type Direction int
+
+const (
+	Outgoing Direction = iota // (a)-->(b)
+	Incoming                  // (a)<--(b)
+	Both                      // (a)--(b)
+)
+
+// RelPattern matches dependencies: -[r:calls|uses_type*1..3]->.
+type RelPattern struct {
+	Var      string
+	Types    []string // dependency types, any of which matches; empty for all
+	Dir      Direction
+	VarLen   bool
+	Min, Max int // hop bounds for VarLen; Max 0 for unbounded
+}
+
+// ReturnItem is one RETURN column. Name is its alias, or the expression's
+// source text.
+type ReturnItem struct {
+	Expr Expr
+	Name string
+}
+
+// OrderItem is one ORDER BY key.
+type OrderItem struct {
+	Expr Expr
+	Text string
+	Desc bool
+}
+
+// Expr is an expression node.
+type Expr interface{}
+
+// Expression nodes.
+type (
+	Literal struct{ Value any }
+	Var     struct{ Name string }
+	Prop    struct {
+		X    Expr
+		Name string
+	}
+	List  struct{ Items []Expr }
+	Unary struct {
+		Op string // NOT or -
+		X  Expr
+	}
+	Binary struct {
+		Op   string // AND OR = <> < <= > >= =~ IN GLOB CONTAINS STARTS ENDS + - * / %
+		L, R Expr
+	}
+	IsNull struct {
+		X   Expr
+		Not bool
+	}
+	Call struct {
+		Name     string // lower case
+		Args     []Expr
+		Star     bool // count(*)
+		Distinct bool
+	}
+	// ListPredicate is any/all/none/single(x IN list WHERE pred).
+	ListPredicate struct {
+		Kind  string
+		Var   string
+		List  Expr
+		Where Expr
+	}
+)
+
+// Parse parses a query.
+func Parse(src string) (*Query, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, toks: toks}
+	q, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+type parser struct {
+	src  string
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &Error{Pos: p.peek().pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword reports whether the next token is the keyword kw.
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.isKeyword(kw) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.errorf("expected %s, found %s", kw, p.describe())
+	}
+	return nil
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+func (p *parser) accept(s string) bool {
+	if p.isPunct(s) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %q, found %s", s, p.describe())
+	}
+	return nil
+}
+
+func (p *parser) describe() string {
+	t := p.peek()
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.peek()
+	if t.kind != tokIdent {
+		return "", p.errorf("expected a name, found %s", p.describe())
+	}
+	p.i++
+	return t.text, nil
+}
+
+// reserved words that end an expression or pattern and cannot be variables.
+var reserved = map[string]bool{
+	"MATCH": true, "WHERE": true, "RETURN": true, "ORDER": true, "BY": true,
+	"SKIP": true, "LIMIT": true, "AS": true, "AND": true, "OR": true, "NOT": true,
+	"IN": true, "IS": true, "ASC": true, "DESC": true, "DISTINCT": true,
+}
+
+func (p *parser) query() (*Query, error) {
+	q := &Query{}
+	if err := p.expectKeyword("MATCH"); err != nil {
+		return nil, err
+	}
+	for {
+		pat, err := p.pattern()
+		if err != nil {
+			return nil, err
+		}
+		q.Patterns = append(q.Patterns, pat)
+		if p.accept(",") || p.acceptKeyword("MATCH") {
+			continue
+		}
+		break
+	}
+
+	if p.acceptKeyword("WHERE") {
+		where, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		q.Where = where
+	}
+
+	if err := p.expectKeyword("RETURN"); err != nil {
+		return nil, err
+	}
+	q.Distinct = p.acceptKeyword("DISTINCT")
+	if !p.accept("*") {
+		for {
+			start := p.peek().pos
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			item := &ReturnItem{Expr: e, Name: p.text(start)}
+			if p.acceptKeyword("AS") {
+				if item.Name, err = p.ident(); err != nil {
+					return nil, err
+				}
+			}
+			q.Return = append(q.Return, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			start := p.peek().pos
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			item := &OrderItem{Expr: e, Text: p.text(start)}
+			if p.acceptKeyword("DESC") {
+				item.Desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			q.OrderBy = append(q.OrderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	var err error
+	if p.acceptKeyword("SKIP") {
+		if q.Skip, err = p.count(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if q.Limit, err = p.count(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	return q, nil
+}
+
+// text returns the query source from offset start to the end of the last
+// consumed token.
+func (p *parser) text(start int) string {
+	return strings.TrimSpace(p.src[start:p.toks[p.i-1].end])
+}
+
+func (p *parser) count() (int, error) {
+	t := p.peek()
+	if t.kind != tokNumber {
+		return 0, p.errorf("expected a number, found %s", p.describe())
+	}
+	n, err := strconv.Atoi(t.text)
+	if err != nil {
+		return 0, p.errorf("expected a whole number, found %s", p.describe())
+	}
+	p.i++
+	return n, nil
+}
+
+func (p *parser) pattern() (*Pattern, error) {
+	pat := &Pattern{}
+	if p.peek().kind == tokIdent && p.toks[p.i+1].kind == tokPunct && p.toks[p.i+1].text == "=" {
+		pat.PathVar = p.next().text
+		p.next()
+	}
+	n, err := p.nodePattern()
+	if err != nil {
+		return nil, err
+	}
+	pat.Nodes = append(pat.Nodes, n)
+	for p.isPunct("-") || p.isPunct("<-") {
+		r, err := p.relPattern()
+		if err != nil {
+			return nil, err
+		}
+		n, err := p.nodePattern()
+		if err != nil {
+			return nil, err
+		}
+		pat.Rels = append(pat.Rels, r)
+		pat.Nodes = append(pat.Nodes, n)
+	}
+	return pat, nil
+}
+
+func (p *parser) nodePattern() (*NodePattern, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	n := &NodePattern{}
+	if t := p.peek(); t.kind == tokIdent {
+		n.Var = p.next().text
+	}
+	if p.accept(":") {
+		labels, err := p.alternatives()
+		if err != nil {
+			return nil, err
+		}
+		n.Labels = labels
+	}
+	if p.isPunct("{") {
+		props, err := p.properties()
+		if err != nil {
+			return nil, err
+		}
+		n.Props = props
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// alternatives parses name|name|... with an optional colon before each
+// alternative after the first, as in :calls|:uses_type.
+func (p *parser) alternatives() ([]string, error) {
+	var names []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, strings.ToLower(name))
+		if !p.accept("|") {
+			return names, nil
+		}
+		p.accept(":")
+	}
+}
+
+// properties parses a {key: value, ...} map of constant values.
+func (p *parser) properties() (map[string]any, error) {
+	props := make(map[string]any)
+	p.next() // {
+	for !p.accept("}") {
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		pos := p.peek().pos
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		v, err := evalConstant(e)
+		if err != nil {
+			return nil, &Error{Pos: pos, Msg: "property values must be constants"}
+		}
+		props[strings.ToLower(key)] = v
+		if !p.accept(",") {
+			if err := p.expect("}"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return props, nil
+}
+
+func (p *parser) relPattern() (*RelPattern, error) {
+	r := &RelPattern{Dir: Outgoing}
+	incoming := p.next().text == "<-"
+
+	if p.accept("[") {
+		if t := p.peek(); t.kind == tokIdent {
+			r.Var = p.next().text
+		}
+		if p.accept(":") {
+			types, err := p.alternatives()
+			if err != nil {
+				return nil, err
+			}
+			r.Types = types
+		}
+		if p.accept("*") {
+			r.VarLen = true
+			r.Min, r.Max = 1, 0
+			if p.peek().kind == tokNumber {
+				n, err := p.count()
+				if err != nil {
+					return nil, err
+				}
+				r.Min, r.Max = n, n
+			}
+			if p.accept("..") {
+				r.Max = 0
+				if p.peek().kind == tokNumber {
+					n, err := p.count()
+					if err != nil {
+						return nil, err
+					}
+					r.Max = n
+				}
+			}
+			if r.Max > 0 && r.Max < r.Min {
+				return nil, p.errorf("variable-length bounds *%d..%d are reversed", r.Min, r.Max)
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case p.accept("->"):
+		if incoming {
+			return nil, p.errorf("a relationship cannot point both ways")
+		}
+	case p.accept("-"):
+		r.Dir = Both
+		if incoming {
+			r.Dir = Incoming
+		}
+	default:
+		return nil, p.errorf("expected \"-\" or \"->\" to close the relationship, found %s", p.describe())
+	}
+	return r, nil
+}
+
+// Expressions, loosest binding first.
+
+func (p *parser) expr() (Expr, error) { return p.orExpr() }
+
+func (p *parser) orExpr() (Expr, error) {
+	l, err := p.andExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		r, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{Op: "OR", L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) andExpr() (Expr, error) {
+	l, err := p.notExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		r, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{Op: "AND", L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) notExpr() (Expr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: "NOT", X: x}, nil
+	}
+	return p.comparison()
+}
+
+var comparisonOps = map[string]bool{"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "=~": true}
+
+func (p *parser) comparison() (Expr, error) {
+	l, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		var op string
+		switch {
+		case t.kind == tokPunct && comparisonOps[t.text]:
+			p.next()
+			op = t.text
+			if op == "!=" {
+				op = "<>"
+			}
+		case p.acceptKeyword("IN"):
+			op = "IN"
+		case p.acceptKeyword("GLOB"):
+			op = "GLOB"
+		case p.acceptKeyword("CONTAINS"):
+			op = "CONTAINS"
+		case p.isKeyword("STARTS") || p.isKeyword("ENDS"):
+			op = strings.ToUpper(p.next().text)
+			if err := p.expectKeyword("WITH"); err != nil {
+				return nil, err
+			}
+		case p.acceptKeyword("IS"):
+			not := p.acceptKeyword("NOT")
+			if err := p.expectKeyword("NULL"); err != nil {
+				return nil, err
+			}
+			l = &IsNull{X: l, Not: not}
+			continue
+		default:
+			return l, nil
+		}
+		r, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{Op: op, L: l, R: r}
+	}
+}
+
+func (p *parser) additive() (Expr, error) {
+	l, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("+") || p.isPunct("-") {
+		op := p.next().text
+		r, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{Op: op, L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) multiplicative() (Expr, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("*") || p.isPunct("/") || p.isPunct("%") {
+		op := p.next().text
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{Op: op, L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.accept("-") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: "-", X: x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (Expr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(".") {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		x = &Prop{X: x, Name: strings.ToLower(name)}
+	}
+	return x, nil
+}
+
+// listPredicates are the functions taking "x IN list WHERE pred".
+var listPredicates = map[string]bool{"any": true, "all": true, "none": true, "single": true}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokString:
+		p.next()
+		return &Literal{Value: t.text}, nil
+	case tokNumber:
+		p.next()
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, &Error{Pos: t.pos, Msg: "bad number " + t.text}
+		}
+		return &Literal{Value: f}, nil
+	case tokPunct:
+		switch t.text {
+		case "(":
+			p.next()
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		case "[":
+			p.next()
+			l := &List{}
+			for !p.accept("]") {
+				x, err := p.expr()
+				if err != nil {
+					return nil, err
+				}
+				l.Items = append(l.Items, x)
+				if !p.accept(",") {
+					if err := p.expect("]"); err != nil {
+						return nil, err
+					}
+					break
+				}
+			}
+			return l, nil
+		}
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+
+	if t.kind != tokIdent {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	switch strings.ToUpper(t.text) {
+	case "TRUE":
+		p.next()
+		return &Literal{Value: true}, nil
+	case "FALSE":
+		p.next()
+		return &Literal{Value: false}, nil
+	case "NULL":
+		p.next()
+		return &Literal{Value: nil}, nil
+	}
+	if reserved[strings.ToUpper(t.text)] {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	p.next()
+	if !p.accept("(") {
+		return &Var{Name: t.text}, nil
+	}
+
+	name := strings.ToLower(t.text)
+	if listPredicates[name] {
+		v, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("IN"); err != nil {
+			return nil, err
+		}
+		list, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("WHERE"); err != nil {
+			return nil, err
+		}
+		where, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return &ListPredicate{Kind: name, Var: v, List: list, Where: where}, p.expect(")")
+	}
+
+	c := &Call{Name: name}
+	if _, ok := functions[name]; !ok && !aggregates[name] {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unknown function %s()", t.text)}
+	}
+	if name == "count" && p.accept("*") {
+		c.Star = true
+		return c, p.expect(")")
+	}
+	c.Distinct = p.acceptKeyword("DISTINCT")
+	for !p.accept(")") {
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		c.Args = append(c.Args, x)
+		if !p.accept(",") {
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return c, nil
+}
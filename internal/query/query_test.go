@@ -0,0 +1,199 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+)
+
+// newTestEngine builds a small graph:
+//
+//	HandleScan   (mcp) -> Dial (daemon) -> Open (store)
+//	HandleScan   (mcp) -> runTool (mcp) -> Load (cache) -> Open
+//	HandleStatus (mcp) -> Dial
+//	HandleScan uses_type Config
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	s := store.NewMemoryStore()
+	fn := func(id, name, file string, line int) *store.Entity {
+		return &store.Entity{ID: id, Name: name, EntityType: "function", FilePath: file, LineStart: line, Language: "go", Status: "active"}
+	}
+	s.CreateEntitiesBulk([]*store.Entity{
+		fn("scan", "HandleScan", "internal/mcp/server.go", 10),
+		fn("status", "HandleStatus", "internal/mcp/server.go", 40),
+		fn("tool", "runTool", "internal/mcp/tools.go", 5),
+		fn("dial", "Dial", "internal/daemon/client.go", 12),
+		fn("load", "Load", "internal/cache/cache.go", 3),
+		fn("open", "Open", "internal/store/db.go", 20),
+		{ID: "config", Name: "Config", EntityType: "type", Kind: "struct", FilePath: "internal/config/config.go", LineStart: 8, Language: "go", Status: "active"},
+	})
+	s.CreateDependenciesBulk([]*store.Dependency{
+		{FromID: "scan", ToID: "dial", DepType: "calls"},
+		{FromID: "scan", ToID: "tool", DepType: "calls"},
+		{FromID: "scan", ToID: "config", DepType: "uses_type"},
+		{FromID: "tool", ToID: "load", DepType: "calls", Optional: true},
+		{FromID: "load", ToID: "open", DepType: "calls"},
+		{FromID: "dial", ToID: "open", DepType: "calls"},
+		{FromID: "status", ToID: "dial", DepType: "calls"},
+	})
+	s.SaveBulkMetrics([]*store.Metrics{
+		{EntityID: "open", PageRank: 0.4, InDegree: 2},
+		{EntityID: "dial", PageRank: 0.2, InDegree: 2},
+		{EntityID: "scan", PageRank: 0.05},
+	})
+	s.SaveCoverage(&store.EntityCoverage{EntityID: "open", CoveragePercent: 30})
+	s.SaveCoverage(&store.EntityCoverage{EntityID: "dial", CoveragePercent: 90})
+	s.AddTag("open", "critical", "test")
+
+	e, err := NewEngine(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func run(t *testing.T, e *Engine, q string) *Result {
+	t.Helper()
+	res, err := e.Run(q)
+	if err != nil {
+		t.Fatalf("%s: %v", q, err)
+	}
+	return res
+}
+
+func TestVariableLengthAvoidingNodes(t *testing.T) {
+	e := newTestEngine(t)
+
+	// The shortest path from HandleScan to Open goes through the daemon;
+	// excluding daemon nodes must find the longer path through the cache.
+	res := run(t, e, `
+		MATCH p = (a:function {file: "internal/mcp/**"})-[:calls*]->(b {name: "Open"})
+		WHERE NONE(n IN nodes(p) WHERE n.file GLOB "internal/daemon/**")
+		RETURN a.name AS caller, length(p) AS hops, p
+		ORDER BY caller`)
+
+	want := [][]any{
+		{"HandleScan", int64(3), []any{"HandleScan", "runTool", "Load", "Open"}},
+		{"runTool", int64(2), []any{"runTool", "Load", "Open"}},
+	}
+	if !reflect.DeepEqual(res.Columns, []string{"caller", "hops", "p"}) {
+		t.Errorf("columns = %v", res.Columns)
+	}
+	if !reflect.DeepEqual(res.Rows, want) {
+		t.Errorf("rows = %v, want %v", res.Rows, want)
+	}
+
+	// Without the exclusion each caller is matched once, by its shortest path
+	res = run(t, e, `MATCH p = (a {file: "internal/mcp/*.go"})-[:calls*1..]->(:function {name: "Open"}) RETURN a.name, length(p) ORDER BY a.name`)
+	want = [][]any{{"HandleScan", int64(2)}, {"HandleStatus", int64(2)}, {"runTool", int64(2)}}
+	if !reflect.DeepEqual(res.Rows, want) {
+		t.Errorf("rows = %v, want %v", res.Rows, want)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	e := newTestEngine(t)
+	tests := []struct {
+		query string
+		want  [][]any
+	}{
+		{`MATCH (a {name: "HandleScan"})-[:calls*2]->(b) RETURN b.name ORDER BY b.name`, [][]any{{"Load"}, {"Open"}}},
+		{`MATCH (a {name: "HandleScan"})-[*..1]->(b) RETURN b.name ORDER BY b.name`, [][]any{{"Config"}, {"Dial"}, {"runTool"}}},
+		{`MATCH (a {name: "HandleScan"})-[:calls*0..1]->(b) RETURN b.name ORDER BY b.name`, [][]any{{"Dial"}, {"HandleScan"}, {"runTool"}}},
+		{`MATCH (a)<-[:calls*]-(b {name: "Open"}) RETURN a.name`, nil},
+		{`MATCH (a {name: "Open"})<-[:calls*3..]-(b) RETURN b.name`, [][]any{{"HandleScan"}}},
+	}
+	for _, tt := range tests {
+		if got := run(t, e, tt.query).Rows; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s\n got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestPropertyPredicates(t *testing.T) {
+	e := newTestEngine(t)
+	tests := []struct {
+		query string
+		want  [][]any
+	}{
+		{`MATCH (n) WHERE n.pagerank > 0.1 AND n.coverage < 50 RETURN n.name`, [][]any{{"Open"}}},
+		{`MATCH (n {tags: "critical"})<-[:calls]-(c) RETURN c.name ORDER BY c.name`, [][]any{{"Dial"}, {"Load"}}},
+		{`MATCH (n:struct) RETURN n.name, n.type, n.kind`, [][]any{{"Config", "type", "struct"}}},
+		{`MATCH (n:function) WHERE n.coverage IS NULL AND n.name =~ "Handle.*" RETURN n.name ORDER BY n.line DESC`, [][]any{{"HandleStatus"}, {"HandleScan"}}},
+		{`MATCH (n) WHERE n.package IN ["cache", "daemon"] RETURN n.name ORDER BY n.name`, [][]any{{"Dial"}, {"Load"}}},
+		{`MATCH (a)-[r]->(b) WHERE r.optional RETURN a.name, type(r), b.name`, [][]any{{"runTool", "calls", "Load"}}},
+		{`MATCH (a)-[r:uses_type]->(b) RETURN r`, [][]any{{map[string]any{"from": "scan", "to": "config", "type": "uses_type"}}}},
+	}
+	for _, tt := range tests {
+		if got := run(t, e, tt.query).Rows; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s\n got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestReturn(t *testing.T) {
+	e := newTestEngine(t)
+	tests := []struct {
+		query string
+		want  [][]any
+	}{
+		{`MATCH (a)-[:calls]->(b) RETURN b.name AS callee, count(*) AS callers ORDER BY callers DESC, callee LIMIT 2`,
+			[][]any{{"Dial", int64(2)}, {"Open", int64(2)}}},
+		{`MATCH (a)-[:calls]->(b) RETURN count(DISTINCT a) AS n, max(b.pagerank) AS top`, [][]any{{int64(5), 0.4}}},
+		{`MATCH (a {name: "HandleScan"})-->(b) RETURN collect(b.name) AS names`, [][]any{{[]any{"Config", "Dial", "runTool"}}}},
+		{`MATCH (a)-[:calls]->(b) RETURN DISTINCT b.package ORDER BY b.package SKIP 1`, [][]any{{"daemon"}, {"mcp"}, {"store"}}},
+		{`MATCH (a {name: "Nope"}) RETURN count(*)`, [][]any{{int64(0)}}},
+		{`MATCH (a:type) RETURN *`, [][]any{{map[string]any{"id": "config", "name": "Config", "type": "type", "location": "internal/config/config.go:8"}}}},
+	}
+	for _, tt := range tests {
+		if got := run(t, e, tt.query).Rows; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s\n got %#v\nwant %#v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	e := newTestEngine(t)
+	for _, q := range []string{
+		`MATCH (a RETURN a`,
+		`MATCH (a)-[:calls*3..1]->(b) RETURN a`,
+		`MATCH (a)<-[]->(b) RETURN a`,
+		`MATCH (a) RETURN frobnicate(a)`,
+		`RETURN 1`,
+	} {
+		_, err := e.Run(q)
+		var qerr *Error
+		if !errors.As(err, &qerr) {
+			t.Errorf("%s: got %v, want a syntax error", q, err)
+		}
+	}
+	if _, err := e.Run(`MATCH (a) WHERE b.name = "x" RETURN a`); err == nil {
+		t.Error("unknown variable: want an error")
+	}
+	if _, err := e.Run(`MATCH (a) WHERE count(a) > 1 RETURN a`); err == nil {
+		t.Error("aggregate in WHERE: want an error")
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"internal/**", "internal/mcp/server.go", true},
+		{"internal/*.go", "internal/mcp/server.go", false},
+		{"internal/*/*.go", "internal/mcp/server.go", true},
+		{"**/server.go", "server.go", true},
+		{"**/server.go", "internal/mcp/server.go", true},
+		{"*_test.go", "a_test.go", true},
+		{"serv?r.go", "server.go", true},
+		{"a.b", "axb", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,6 @@
 package report
 
 import (
-	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,13 +17,13 @@ import (
 
 // DataGatherer gathers data from the store for report generation.
 type DataGatherer struct {
-	store          *store.Store
+	store          store.Store
 	theme          string // Optional theme for D2 diagrams
 	playgroundMode bool   // Enable playground metadata generation
 }
 
 // NewDataGatherer creates a new DataGatherer with the given store.
-func NewDataGatherer(s *store.Store) *DataGatherer {
+func NewDataGatherer(s store.Store) *DataGatherer {
 	return &DataGatherer{store: s, theme: "", playgroundMode: false}
 }
 
@@ -361,6 +360,11 @@ func (g *DataGatherer) GatherHealthData(data *HealthReportData) error {
 		data.Complexity = complexity
 	}
 
+	// Package coupling and its main-sequence diagram
+	if err := g.gatherPackageCoupling(data); err != nil {
+		// Continue even if this fails
+	}
+
 	// Calculate risk score
 	data.RiskScore = g.calculateRiskScore(data)
 
@@ -1166,33 +1170,90 @@ func classifyImportance(pageRank float64, inDegree int) Importance {
 	return ImportanceNormal
 }
 
-// GetAllEntityCoverage retrieves all coverage data from the store.
-func GetAllEntityCoverage(s *store.Store) ([]coverage.EntityCoverage, error) {
-	rows, err := s.DB().Query(`
-		SELECT entity_id, coverage_percent, covered_lines, uncovered_lines, last_run
-		FROM entity_coverage
-	`)
+// PackageCoupling computes Robert Martin's package metrics over the code
+// dependency graph, with each directory as a package.
+func PackageCoupling(s store.Store) ([]metrics.PackageCoupling, error) {
+	graphData, err := graph.BuildFromStore(s)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+		return nil, fmt.Errorf("build graph: %w", err)
+	}
+	all, err := s.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+
+	entities := make(map[string]metrics.CouplingEntity, len(all))
+	for _, e := range all {
+		if e.EntityType == "import" {
+			continue
+		}
+		isType := e.EntityType == "type" || e.EntityType == "interface"
+		entities[e.ID] = metrics.CouplingEntity{
+			Package:  filepath.ToSlash(filepath.Dir(e.FilePath)),
+			Type:     isType,
+			Abstract: isType && (e.Kind == "interface" || e.Kind == "abstract" || e.EntityType == "interface"),
 		}
-		return nil, err
 	}
-	defer rows.Close()
+	return metrics.ComputePackageCoupling(graphData.Edges, entities), nil
+}
 
-	var results []coverage.EntityCoverage
-	for rows.Next() {
-		var cov coverage.EntityCoverage
-		var coveredJSON, uncoveredJSON, lastRunStr string
+// gatherPackageCoupling fills the coupling section and the coupling diagram.
+func (g *DataGatherer) gatherPackageCoupling(data *HealthReportData) error {
+	packages, err := PackageCoupling(g.store)
+	if err != nil {
+		return err
+	}
 
-		if err := rows.Scan(&cov.EntityID, &cov.CoveragePercent, &coveredJSON, &uncoveredJSON, &lastRunStr); err != nil {
-			return nil, err
+	points := make([]graph.CouplingPoint, 0, len(packages))
+	for _, p := range packages {
+		if p.Ca+p.Ce == 0 {
+			continue // not connected to the rest of the code
 		}
+		data.Coupling = append(data.Coupling, PackageCouplingData{
+			Package:      p.Package,
+			Ca:           p.Ca,
+			Ce:           p.Ce,
+			Instability:  p.Instability,
+			Abstractness: p.Abstractness,
+			Distance:     p.Distance,
+			Zone:         p.Zone,
+		})
+		points = append(points, graph.CouplingPoint{
+			Package:      p.Package,
+			Instability:  p.Instability,
+			Abstractness: p.Abstractness,
+		})
+	}
+	if len(data.Coupling) == 0 {
+		return nil
+	}
+	sort.SliceStable(data.Coupling, func(i, j int) bool {
+		return data.Coupling[i].Distance > data.Coupling[j].Distance
+	})
+
+	if data.Diagrams == nil {
+		data.Diagrams = make(map[string]DiagramData)
+	}
+	title := "Package Coupling: Distance from the Main Sequence"
+	data.Diagrams["coupling"] = DiagramData{
+		Title: title,
+		D2:    graph.BuildCouplingDiagram(points, title, g.theme),
+	}
+	return nil
+}
 
-		results = append(results, cov)
+// GetAllEntityCoverage retrieves all coverage data from the store.
+func GetAllEntityCoverage(s store.Store) ([]coverage.EntityCoverage, error) {
+	coverages, err := s.GetAllCoverage()
+	if err != nil {
+		return nil, err
 	}
 
-	return results, rows.Err()
+	results := make([]coverage.EntityCoverage, 0, len(coverages))
+	for _, cov := range coverages {
+		results = append(results, *cov)
+	}
+	return results, nil
 }
 
 // gatherPlaygroundMetadata populates playground-specific metadata for interactive reports.
@@ -2,6 +2,8 @@ package report
 
 import (
 	"testing"
+
+	"github.com/anthropics/cx/internal/store"
 )
 
 // TestClassifyImportance tests the importance classification logic.
@@ -188,3 +190,39 @@ func TestEntityModified(t *testing.T) {
 		t.Error("gatherer is nil")
 	}
 }
+
+func TestGatherPackageCoupling(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.CreateEntitiesBulk([]*store.Entity{
+		{ID: "run", Name: "Run", EntityType: "function", FilePath: "cmd/run.go", Status: "active"},
+		{ID: "db", Name: "DB", EntityType: "type", Kind: "struct", FilePath: "store/db.go", Status: "active"},
+		{ID: "open", Name: "Open", EntityType: "function", FilePath: "store/db.go", Status: "active"},
+		{ID: "reader", Name: "Reader", EntityType: "type", Kind: "interface", FilePath: "api/api.go", Status: "active"},
+	})
+	s.CreateDependenciesBulk([]*store.Dependency{
+		{FromID: "run", ToID: "open", DepType: "calls"},
+		{FromID: "run", ToID: "reader", DepType: "uses_type"},
+		{FromID: "open", ToID: "db", DepType: "uses_type"},
+	})
+
+	data := NewHealthReport()
+	if err := NewDataGatherer(s).gatherPackageCoupling(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Coupling) != 3 {
+		t.Fatalf("got %d packages, want 3: %+v", len(data.Coupling), data.Coupling)
+	}
+	// store is concrete and only depended on: farthest from the main sequence
+	first := data.Coupling[0]
+	if first.Package != "store" || first.Ca != 1 || first.Ce != 0 || first.Distance != 1 || first.Zone != "pain" {
+		t.Errorf("first package = %+v, want store in the zone of pain", first)
+	}
+	for _, p := range data.Coupling {
+		if p.Package == "api" && (p.Abstractness != 1 || p.Zone != "") {
+			t.Errorf("api = %+v, want abstract and on the main sequence", p)
+		}
+	}
+	if _, ok := data.Diagrams["coupling"]; !ok {
+		t.Error("coupling diagram missing")
+	}
+}
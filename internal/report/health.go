@@ -34,6 +34,14 @@ type HealthReportData struct {
 	// Optional but recommended for large codebases.
 	Complexity *ComplexityAnalysis `yaml:"complexity,omitempty" json:"complexity,omitempty"`
 
+	// Hotspots ranks entities that are both complex and changed often in a
+	// window of git history. Only set when the history is available.
+	Hotspots *HotspotAnalysis `yaml:"hotspots,omitempty" json:"hotspots,omitempty"`
+
+	// Coupling lists Robert Martin's package metrics, farthest from the main
+	// sequence first. Diagrams["coupling"] plots them.
+	Coupling []PackageCouplingData `yaml:"coupling,omitempty" json:"coupling,omitempty"`
+
 	// Diagrams maps diagram names to D2 diagram definitions for visualization.
 	// Typically includes a risk_map showing risk distribution across the codebase.
 	Diagrams map[string]DiagramData `yaml:"diagrams" json:"diagrams"`
@@ -54,6 +62,32 @@ type ComplexityAnalysis struct {
 	Hotspots []ComplexityHotspot `yaml:"hotspots" json:"hotspots"`
 }
 
+// PackageCouplingData holds the coupling metrics of one package.
+type PackageCouplingData struct {
+	// Package is the directory the metrics are aggregated over.
+	Package string `yaml:"package" json:"package"`
+
+	// Ca (afferent coupling) counts outside entities depending on the package.
+	Ca int `yaml:"ca" json:"ca"`
+
+	// Ce (efferent coupling) counts package entities depending on outside ones.
+	Ce int `yaml:"ce" json:"ce"`
+
+	// Instability is Ce / (Ca + Ce), from 0 (stable) to 1 (unstable).
+	Instability float64 `yaml:"instability" json:"instability"`
+
+	// Abstractness is the share of the package's types that are interfaces
+	// or abstract classes.
+	Abstractness float64 `yaml:"abstractness" json:"abstractness"`
+
+	// Distance from the main sequence |A + I - 1|, from 0 to 1.
+	Distance float64 `yaml:"distance" json:"distance"`
+
+	// Zone is "pain" (concrete and stable) or "uselessness" (abstract and
+	// unstable) for packages far from the main sequence.
+	Zone string `yaml:"zone,omitempty" json:"zone,omitempty"`
+}
+
 // NewHealthReport creates a new HealthReportData with initialized fields.
 // The report type is automatically set to ReportTypeHealth and generated_at is set to now.
 func NewHealthReport() *HealthReportData {
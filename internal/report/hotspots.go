@@ -0,0 +1,183 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/anthropics/cx/internal/diff"
+	"github.com/anthropics/cx/internal/metrics"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// HotspotAnalysis ranks code that is both complex and changed often within
+// a time window of git history.
+type HotspotAnalysis struct {
+	// Since is the start of the window (RFC 3339).
+	Since string `yaml:"since" json:"since"`
+
+	// Commits is the number of commits in the window.
+	Commits int `yaml:"commits" json:"commits"`
+
+	// Hotspots are the top entities by hotspot score.
+	Hotspots []HotspotData `yaml:"hotspots" json:"hotspots"`
+}
+
+// HotspotData is one churn × complexity hotspot.
+type HotspotData struct {
+	// Entity is the entity name.
+	Entity string `yaml:"entity" json:"entity"`
+
+	// Type is the entity type.
+	Type string `yaml:"type" json:"type"`
+
+	// File is the path to the source file containing the entity.
+	File string `yaml:"file" json:"file"`
+
+	// Lines contains the start and end line numbers [start, end].
+	Lines [2]int `yaml:"lines" json:"lines"`
+
+	// Score is churn × complexity weighted by PageRank, from 0 to 1.
+	Score float64 `yaml:"score" json:"score"`
+
+	// Commits is the number of commits in the window that touched the entity.
+	Commits int `yaml:"commits" json:"commits"`
+
+	// LinesChanged is the number of lines those commits added or removed.
+	LinesChanged int `yaml:"lines_changed" json:"lines_changed"`
+
+	// Size is the entity's length in lines.
+	Size int `yaml:"size" json:"size"`
+
+	// Cyclomatic is the estimated cyclomatic complexity of functions and methods.
+	Cyclomatic int `yaml:"cyclomatic,omitempty" json:"cyclomatic,omitempty"`
+
+	// PageRank is the entity's importance in the dependency graph.
+	PageRank float64 `yaml:"pagerank" json:"pagerank"`
+
+	// LastChanged is the date of the most recent commit that touched it.
+	LastChanged string `yaml:"last_changed" json:"last_changed"`
+}
+
+// hotspotEntityTypes are the entity types ranked as hotspots; imports,
+// constants and variables are too small to be one
+var hotspotEntityTypes = map[string]bool{
+	"function": true, "method": true, "type": true, "class": true, "interface": true,
+}
+
+// EntityChurn maps changed line regions (per file, in current line numbers)
+// onto the line ranges of entities and returns the churn of every entity
+// that changed, keyed by entity ID.
+func EntityChurn(entities []*store.Entity, changes map[string][]diff.LineChange) map[string]metrics.Churn {
+	byFile := make(map[string][]*store.Entity)
+	for _, e := range entities {
+		path := filepath.ToSlash(e.FilePath)
+		byFile[path] = append(byFile[path], e)
+	}
+
+	result := make(map[string]metrics.Churn)
+	for file, fileChanges := range changes {
+		for _, e := range byFile[file] {
+			end := e.LineStart
+			if e.LineEnd != nil {
+				end = *e.LineEnd
+			}
+			churn := metrics.Churn{}
+			commits := make(map[string]bool)
+			for _, c := range fileChanges {
+				if c.Start > end || c.End < e.LineStart {
+					continue
+				}
+				commits[c.Commit] = true
+				overlap := min(c.End, end) - max(c.Start, e.LineStart) + 1
+				churn.LinesChanged += min(overlap, c.Lines)
+				if c.Time.After(churn.LastChanged) {
+					churn.LastChanged = c.Time
+				}
+			}
+			if churn.Commits = len(commits); churn.Commits > 0 {
+				result[e.ID] = churn
+			}
+		}
+	}
+	return result
+}
+
+// Hotspots scores the active entities of s by churn × complexity with the
+// given line changes and returns the top limit (all when limit <= 0).
+func Hotspots(s store.Store, changes map[string][]diff.LineChange, limit int) ([]HotspotData, error) {
+	all, err := s.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+	var entities []*store.Entity
+	for _, e := range all {
+		if hotspotEntityTypes[e.EntityType] {
+			entities = append(entities, e)
+		}
+	}
+	churn := EntityChurn(entities, changes)
+
+	pageRank := make(map[string]float64)
+	if stored, err := s.GetAllMetrics(); err == nil {
+		for _, m := range stored {
+			pageRank[m.EntityID] = m.PageRank
+		}
+	}
+
+	byID := make(map[string]*store.Entity, len(entities))
+	inputs := make([]metrics.HotspotInput, 0, len(churn))
+	for _, e := range entities {
+		c, ok := churn[e.ID]
+		if !ok {
+			continue
+		}
+		byID[e.ID] = e
+		in := metrics.HotspotInput{ID: e.ID, Churn: c, Lines: 1, PageRank: pageRank[e.ID]}
+		if e.LineEnd != nil {
+			in.Lines = *e.LineEnd - e.LineStart + 1
+		}
+		if e.EntityType == "function" || e.EntityType == "method" {
+			in.Cyclomatic = metrics.EstimateCyclomatic(e.BodyText)
+		}
+		inputs = append(inputs, in)
+	}
+
+	ranked := metrics.ComputeHotspots(inputs)
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	result := make([]HotspotData, 0, len(ranked))
+	for _, h := range ranked {
+		e := byID[h.ID]
+		result = append(result, HotspotData{
+			Entity:       e.Name,
+			Type:         e.EntityType,
+			File:         e.FilePath,
+			Lines:        [2]int{e.LineStart, e.LineStart + h.Lines - 1},
+			Score:        h.Score,
+			Commits:      h.Churn.Commits,
+			LinesChanged: h.Churn.LinesChanged,
+			Size:         h.Lines,
+			Cyclomatic:   h.Cyclomatic,
+			PageRank:     h.PageRank,
+			LastChanged:  h.Churn.LastChanged.Format("2006-01-02"),
+		})
+	}
+	return result, nil
+}
+
+// GatherHotspots adds the hotspot section for commits since the given time
+// to a health report.
+func (g *DataGatherer) GatherHotspots(data *HealthReportData, commits []diff.CommitHunks, since time.Time, limit int) error {
+	hotspots, err := Hotspots(g.store, diff.ProjectToHead(commits), limit)
+	if err != nil {
+		return err
+	}
+	data.Hotspots = &HotspotAnalysis{
+		Since:    since.UTC().Format(time.RFC3339),
+		Commits:  len(commits),
+		Hotspots: hotspots,
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anthropics/cx/internal/diff"
+	"github.com/anthropics/cx/internal/store"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestEntityChurn(t *testing.T) {
+	entities := []*store.Entity{
+		{ID: "a", FilePath: "f.go", LineStart: 1, LineEnd: intPtr(10)},
+		{ID: "b", FilePath: "f.go", LineStart: 12, LineEnd: intPtr(30)},
+		{ID: "c", FilePath: "g.go", LineStart: 1, LineEnd: intPtr(5)},
+	}
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	changes := map[string][]diff.LineChange{
+		"f.go": {
+			{Commit: "1", Time: day, Start: 5, End: 14, Lines: 10},
+			{Commit: "2", Time: day.AddDate(0, 0, 1), Start: 20, End: 20, Lines: 1},
+			{Commit: "2", Time: day.AddDate(0, 0, 1), Start: 25, End: 26, Lines: 2},
+		},
+	}
+
+	churn := EntityChurn(entities, changes)
+	if _, ok := churn["c"]; ok {
+		t.Error("unchanged entity should have no churn")
+	}
+	if a := churn["a"]; a.Commits != 1 || a.LinesChanged != 6 {
+		t.Errorf("a = %+v, want 1 commit and 6 lines", a)
+	}
+	b := churn["b"]
+	if b.Commits != 2 || b.LinesChanged != 6 || !b.LastChanged.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("b = %+v, want 2 commits, 6 lines, last changed on day 2", b)
+	}
+}
+
+func TestHotspots(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.CreateEntitiesBulk([]*store.Entity{
+		{ID: "parse", Name: "Parse", EntityType: "function", FilePath: "p.go", LineStart: 1, LineEnd: intPtr(40),
+			BodyText: "if a { for { if b && c { } } }", Status: "active"},
+		{ID: "version", Name: "Version", EntityType: "constant", FilePath: "p.go", LineStart: 42, Status: "active"},
+		{ID: "old", Name: "Old", EntityType: "function", FilePath: "p.go", LineStart: 50, LineEnd: intPtr(60), Status: "archived"},
+	})
+	changes := map[string][]diff.LineChange{
+		"p.go": {
+			{Commit: "1", Start: 1, End: 60, Lines: 60},
+			{Commit: "2", Start: 10, End: 12, Lines: 3},
+		},
+	}
+
+	got, err := Hotspots(s, changes, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d hotspots, want only the active function: %+v", len(got), got)
+	}
+	h := got[0]
+	if h.Entity != "Parse" || h.Commits != 2 || h.Size != 40 || h.Cyclomatic != 5 || h.Lines != [2]int{1, 40} {
+		t.Errorf("hotspot = %+v", h)
+	}
+}
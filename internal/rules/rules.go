@@ -0,0 +1,565 @@
+// Package rules checks the dependency graph against architecture rules
+// declared in the rules section of .cx/config.yaml: layers of files,
+// allowed and forbidden dependency directions between them, and "only X
+// may depend on Y" constraints.
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// Violation kinds.
+const (
+	KindForbidden  = "forbidden"   // matches a forbid rule
+	KindNotAllowed = "not_allowed" // leaves a layer for one its allow rules don't list
+	KindOnly       = "only"        // depends on a target reserved for other callers
+)
+
+// Violation is a dependency that breaks a rule.
+type Violation struct {
+	Kind      string `yaml:"kind" json:"kind"`
+	Severity  string `yaml:"severity" json:"severity"`
+	Rule      string `yaml:"rule" json:"rule"`
+	Reason    string `yaml:"reason,omitempty" json:"reason,omitempty"`
+	From      string `yaml:"from" json:"from"`
+	FromLayer string `yaml:"from_layer,omitempty" json:"from_layer,omitempty"`
+	To        string `yaml:"to" json:"to"`
+	ToLayer   string `yaml:"to_layer,omitempty" json:"to_layer,omitempty"`
+	DepType   string `yaml:"dep_type" json:"dep_type"`
+	Location  string `yaml:"location" json:"location"` // call site, file:line
+
+	FromID string `yaml:"-" json:"-"`
+	ToID   string `yaml:"-" json:"-"`
+}
+
+// Message describes the violation in one line.
+func (v *Violation) Message() string {
+	msg := fmt.Sprintf("%s %s %s: %s", v.From, verb(v.DepType), v.To, v.Rule)
+	if v.Reason != "" {
+		msg += " (" + v.Reason + ")"
+	}
+	return msg
+}
+
+func verb(depType string) string {
+	switch depType {
+	case "calls":
+		return "calls"
+	case "uses_type":
+		return "uses"
+	case "implements":
+		return "implements"
+	case "extends":
+		return "extends"
+	}
+	return "depends on"
+}
+
+// Set is a compiled set of rules.
+type Set struct {
+	layers []layer
+	allow  map[string][]*depRule // from layer -> rules
+	forbid []*depRule
+	only   []*onlyRule
+}
+
+type layer struct {
+	name  string
+	paths []*glob
+}
+
+type depRule struct {
+	from     selector
+	to       []selector
+	types    map[string]bool
+	severity string
+	reason   string
+	text     string
+}
+
+type onlyRule struct {
+	target   selector
+	callers  []selector
+	types    map[string]bool
+	severity string
+	reason   string
+	text     string
+}
+
+// Compile builds a rule set from configuration.
+func Compile(cfg config.RulesConfig) (*Set, error) {
+	s := &Set{allow: make(map[string][]*depRule)}
+	names := make(map[string]bool)
+	for _, l := range cfg.Layers {
+		cl := layer{name: l.Name}
+		for _, p := range l.Paths {
+			g, err := compileGlob(p, true)
+			if err != nil {
+				return nil, fmt.Errorf("layer %s: %w", l.Name, err)
+			}
+			cl.paths = append(cl.paths, g)
+		}
+		s.layers = append(s.layers, cl)
+		names[l.Name] = true
+	}
+
+	for _, r := range cfg.Allow {
+		dr, err := compileDepRule(r, names, fmt.Sprintf("%s may only depend on %s", r.From, strings.Join(allowedTargets(cfg.Allow, r.From), ", ")))
+		if err != nil {
+			return nil, err
+		}
+		s.allow[r.From] = append(s.allow[r.From], dr)
+	}
+	for _, r := range cfg.Forbid {
+		dr, err := compileDepRule(r, names, fmt.Sprintf("%s must not depend on %s", r.From, strings.Join(r.To, ", ")))
+		if err != nil {
+			return nil, err
+		}
+		s.forbid = append(s.forbid, dr)
+	}
+	for _, r := range cfg.Only {
+		or := &onlyRule{
+			types:    typeSet(r.DepTypes),
+			severity: severity(r.Severity),
+			reason:   r.Reason,
+			text:     fmt.Sprintf("only %s may depend on %s", strings.Join(r.Callers, ", "), r.Target),
+		}
+		var err error
+		if or.target, err = compileSelector(r.Target, names); err != nil {
+			return nil, err
+		}
+		for _, c := range r.Callers {
+			sel, err := compileSelector(c, names)
+			if err != nil {
+				return nil, err
+			}
+			or.callers = append(or.callers, sel)
+		}
+		s.only = append(s.only, or)
+	}
+	return s, nil
+}
+
+func compileDepRule(r config.DependencyRule, layers map[string]bool, text string) (*depRule, error) {
+	dr := &depRule{types: typeSet(r.DepTypes), severity: severity(r.Severity), reason: r.Reason, text: text}
+	var err error
+	if dr.from, err = compileSelector(r.From, layers); err != nil {
+		return nil, err
+	}
+	for _, t := range r.To {
+		sel, err := compileSelector(t, layers)
+		if err != nil {
+			return nil, err
+		}
+		dr.to = append(dr.to, sel)
+	}
+	return dr, nil
+}
+
+// allowedTargets lists every target the allow rules give a layer.
+func allowedTargets(rules []config.DependencyRule, from string) []string {
+	var to []string
+	for _, r := range rules {
+		if r.From == from {
+			to = append(to, r.To...)
+		}
+	}
+	return to
+}
+
+func typeSet(types []string) map[string]bool {
+	if len(types) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(types))
+	for _, t := range types {
+		set[t] = true
+	}
+	return set
+}
+
+func severity(s string) string {
+	if s == "" {
+		return "error"
+	}
+	return s
+}
+
+// Empty reports whether the set has no rules to check.
+func (s *Set) Empty() bool {
+	return len(s.allow) == 0 && len(s.forbid) == 0 && len(s.only) == 0
+}
+
+// Layer returns the layer a file belongs to, or "" if none.
+func (s *Set) Layer(path string) string {
+	for _, l := range s.layers {
+		for _, g := range l.paths {
+			if g.match(path) {
+				return l.name
+			}
+		}
+	}
+	return ""
+}
+
+// CheckEdge returns the rules a dependency breaks. Location is left empty.
+func (s *Set) CheckEdge(from, to *store.Entity, depType string) []Violation {
+	fromLayer, toLayer := s.Layer(from.FilePath), s.Layer(to.FilePath)
+	var out []Violation
+	add := func(kind, severity, rule, reason string) {
+		out = append(out, Violation{
+			Kind: kind, Severity: severity, Rule: rule, Reason: reason,
+			From: from.Name, FromLayer: fromLayer, FromID: from.ID,
+			To: to.Name, ToLayer: toLayer, ToID: to.ID,
+			DepType: depType,
+		})
+	}
+	ctx := matchContext{s: s, fromLayer: fromLayer, toLayer: toLayer}
+
+	for _, r := range s.forbid {
+		if !applies(r.types, depType) || !r.from.match(from, ctx.fromLayer) {
+			continue
+		}
+		for _, sel := range r.to {
+			// A rule between overlapping selectors does not forbid edges within the overlap
+			if sel.match(to, ctx.toLayer) && !sel.match(from, ctx.fromLayer) {
+				add(KindForbidden, r.severity, r.text, r.reason)
+				break
+			}
+		}
+	}
+
+	if rules := s.allow[fromLayer]; len(rules) > 0 && toLayer != "" && toLayer != fromLayer {
+		allowed := false
+		sev := "warning"
+		for _, r := range rules {
+			if r.severity == "error" {
+				sev = "error"
+			}
+			if !applies(r.types, depType) {
+				allowed = true // the rule does not govern this dependency type
+				continue
+			}
+			for _, sel := range r.to {
+				if sel.match(to, ctx.toLayer) {
+					allowed = true
+				}
+			}
+		}
+		if !allowed {
+			add(KindNotAllowed, sev, rules[0].text, rules[0].reason)
+		}
+	}
+
+	for _, r := range s.only {
+		if !applies(r.types, depType) || !r.target.match(to, ctx.toLayer) || r.target.match(from, ctx.fromLayer) {
+			continue
+		}
+		permitted := false
+		for _, c := range r.callers {
+			if c.match(from, ctx.fromLayer) {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			add(KindOnly, r.severity, r.text, r.reason)
+		}
+	}
+	return out
+}
+
+type matchContext struct {
+	s                  *Set
+	fromLayer, toLayer string
+}
+
+func applies(types map[string]bool, depType string) bool {
+	return types == nil || types[depType]
+}
+
+// selector picks entities by layer or path glob, and optionally by name:
+// "store", "internal/store/**", "store#Open", "internal/**#New*".
+type selector struct {
+	layer string // set when the path part names a layer
+	path  *glob  // nil matches any file
+	name  *glob  // nil matches any name
+}
+
+func compileSelector(s string, layers map[string]bool) (selector, error) {
+	base, name, _ := strings.Cut(s, "#")
+	var sel selector
+	var err error
+	switch {
+	case layers[base]:
+		sel.layer = base
+	case base != "":
+		if sel.path, err = compileGlob(base, true); err != nil {
+			return sel, err
+		}
+	}
+	if name != "" {
+		if sel.name, err = compileGlob(name, false); err != nil {
+			return sel, err
+		}
+	}
+	return sel, nil
+}
+
+func (sel selector) match(e *store.Entity, layer string) bool {
+	if sel.layer != "" && sel.layer != layer {
+		return false
+	}
+	if sel.path != nil && !sel.path.match(e.FilePath) {
+		return false
+	}
+	return sel.name == nil || sel.name.match(e.Name)
+}
+
+// glob matches paths: * within a segment, ** across segments, ? one
+// character. A path glob without wildcards matches the file or directory
+// and everything under it.
+type glob struct {
+	re     *regexp.Regexp
+	prefix string // wildcard-free path: match it and its subtree
+}
+
+func compileGlob(pattern string, isPath bool) (*glob, error) {
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+	if isPath && !strings.ContainsAny(pattern, "*?") {
+		return &glob{prefix: pattern}, nil
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("bad pattern %q: %w", pattern, err)
+	}
+	return &glob{re: re}, nil
+}
+
+func (g *glob) match(s string) bool {
+	if g.re == nil {
+		return s == g.prefix || strings.HasPrefix(s, g.prefix+"/")
+	}
+	return g.re.MatchString(s)
+}
+
+// CheckOptions selects what Check evaluates.
+type CheckOptions struct {
+	// ProjectRoot locates source files for call-site lines; without it the
+	// location is the start of the depending entity.
+	ProjectRoot string
+	// From limits the check to dependencies of these entity IDs; nil checks
+	// the whole graph.
+	From map[string]bool
+	// Staged, if set, replaces the scanned entities and dependencies of
+	// some files with their current ones.
+	Staged *Staged
+}
+
+// Staged is the code of files as it is now, for checking changes that
+// have not been scanned: the entities in Files and the dependencies
+// leaving them replace those the last scan stored for the same files.
+type Staged struct {
+	Files        map[string]bool
+	Entities     []*store.Entity
+	Dependencies []*store.Dependency
+}
+
+// Check evaluates the rules against the code dependencies in the store and
+// returns the violations ordered by location.
+func Check(s store.Store, set *Set, opts CheckOptions) ([]Violation, error) {
+	if set.Empty() {
+		return nil, nil
+	}
+	entities, err := s.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+	byID := make(map[string]*store.Entity, len(entities))
+	for _, e := range entities {
+		byID[e.ID] = e
+	}
+	deps, err := s.GetAllDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("get dependencies: %w", err)
+	}
+	if st := opts.Staged; st != nil {
+		kept := deps[:0:0]
+		for _, d := range deps {
+			if from := byID[d.FromID]; from == nil || !st.Files[from.FilePath] {
+				kept = append(kept, d)
+			}
+		}
+		deps = append(kept, st.Dependencies...)
+		for id, e := range byID {
+			if st.Files[e.FilePath] {
+				delete(byID, id)
+			}
+		}
+		for _, e := range st.Entities {
+			byID[e.ID] = e
+		}
+	}
+
+	sites := &callSites{root: opts.ProjectRoot, files: make(map[string][]string)}
+	var out []Violation
+	for _, d := range deps {
+		if !graph.IsCodeDependency(d.DepType) || (opts.From != nil && !opts.From[d.FromID]) {
+			continue
+		}
+		from, to := byID[d.FromID], byID[d.ToID]
+		if from == nil || to == nil {
+			continue
+		}
+		for _, v := range set.CheckEdge(from, to, d.DepType) {
+			v.Location = sites.find(from, to)
+			out = append(out, v)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Location != out[j].Location {
+			return lessLocation(out[i].Location, out[j].Location)
+		}
+		return out[i].To < out[j].To
+	})
+	return out, nil
+}
+
+// lessLocation orders file:line locations by file, then numerically by line.
+func lessLocation(a, b string) bool {
+	af, al := splitLocation(a)
+	bf, bl := splitLocation(b)
+	if af != bf {
+		return af < bf
+	}
+	return al < bl
+}
+
+func splitLocation(loc string) (string, int) {
+	i := strings.LastIndexByte(loc, ':')
+	if i < 0 {
+		return loc, 0
+	}
+	var line int
+	fmt.Sscanf(loc[i+1:], "%d", &line)
+	return loc[:i], line
+}
+
+// callSites finds the line where one entity refers to another.
+type callSites struct {
+	root  string
+	files map[string][]string
+}
+
+// find returns file:line of the first whole-word use of to's name within
+// from's body, or from's first line if it cannot be found.
+func (c *callSites) find(from, to *store.Entity) string {
+	lines := c.lines(from.FilePath)
+	end := from.LineStart
+	if from.LineEnd != nil && *from.LineEnd > end {
+		end = *from.LineEnd
+	}
+	first := from.LineStart
+	if from.Name == to.Name {
+		first++ // skip the declaration, which names the entity itself
+	}
+	for ln := first; ln <= end && ln-1 < len(lines); ln++ {
+		if ln > 0 && containsWord(lines[ln-1], to.Name) {
+			return fmt.Sprintf("%s:%d", from.FilePath, ln)
+		}
+	}
+	return fmt.Sprintf("%s:%d", from.FilePath, from.LineStart)
+}
+
+func (c *callSites) lines(path string) []string {
+	if lines, ok := c.files[path]; ok {
+		return lines
+	}
+	var lines []string
+	if c.root != "" {
+		if data, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(path))); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+	}
+	c.files[path] = lines
+	return lines
+}
+
+func containsWord(line, word string) bool {
+	for off := 0; word != ""; {
+		i := strings.Index(line[off:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := off+i, off+i+len(word)
+		if (start == 0 || !isIdent(line[start-1])) && (end == len(line) || !isIdent(line[end])) {
+			return true
+		}
+		off = end
+	}
+	return false
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// Matcher selects entities by selectors outside of a rule set: a path glob,
+// optionally followed by #Name. Layer names are not available.
+type Matcher []selector
+
+// CompileMatcher compiles selectors into a Matcher.
+func CompileMatcher(selectors []string) (Matcher, error) {
+	m := make(Matcher, 0, len(selectors))
+	for _, s := range selectors {
+		sel, err := compileSelector(s, nil)
+		if err != nil {
+			return nil, fmt.Errorf("selector %q: %w", s, err)
+		}
+		m = append(m, sel)
+	}
+	return m, nil
+}
+
+// Match reports whether any selector matches the entity.
+func (m Matcher) Match(e *store.Entity) bool {
+	for _, sel := range m {
+		if sel.match(e, "") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anthropics/cx/internal/config"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// newTestStore builds a small layered graph:
+//
+//	HandleScan (cmd) -> Run (service) -> Open (store)
+//	HandleScan (cmd) -> Open
+//	Report     (cmd) -> Open
+//	Open       (store) -> Exec (store)
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+	s := store.NewMemoryStore()
+	fn := func(id, name, file string, start, end int) *store.Entity {
+		return &store.Entity{ID: id, Name: name, EntityType: "function", FilePath: file, LineStart: start, LineEnd: &end, Language: "go", Status: "active"}
+	}
+	s.CreateEntitiesBulk([]*store.Entity{
+		fn("scan", "HandleScan", "internal/cmd/scan.go", 3, 7),
+		fn("report", "Report", "internal/cmd/report.go", 10, 12),
+		fn("run", "Run", "internal/service/run.go", 1, 4),
+		fn("open", "Open", "internal/store/db.go", 5, 8),
+		fn("exec", "Exec", "internal/store/db.go", 10, 12),
+	})
+	s.CreateDependenciesBulk([]*store.Dependency{
+		{FromID: "scan", ToID: "run", DepType: "calls"},
+		{FromID: "scan", ToID: "open", DepType: "calls"},
+		{FromID: "report", ToID: "open", DepType: "calls"},
+		{FromID: "run", ToID: "open", DepType: "calls"},
+		{FromID: "open", ToID: "exec", DepType: "calls"},
+	})
+	return s
+}
+
+var layers = []config.LayerConfig{
+	{Name: "cmd", Paths: []string{"internal/cmd"}},
+	{Name: "service", Paths: []string{"internal/service/**"}},
+	{Name: "store", Paths: []string{"internal/store/*.go"}},
+}
+
+func check(t *testing.T, cfg config.RulesConfig, opts CheckOptions) []Violation {
+	t.Helper()
+	set, err := Compile(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, err := Check(newTestStore(t), set, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vs
+}
+
+func edges(vs []Violation) []string {
+	var out []string
+	for _, v := range vs {
+		out = append(out, v.Kind+" "+v.From+"->"+v.To)
+	}
+	return out
+}
+
+func TestLayer(t *testing.T) {
+	set, err := Compile(config.RulesConfig{Layers: layers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"internal/cmd/scan.go":        "cmd",
+		"internal/cmd/sub/x.go":       "cmd",
+		"internal/cmdx/x.go":          "",
+		"internal/service/a/b.go":     "service",
+		"internal/store/db.go":        "store",
+		"internal/store/sql/query.go": "",
+	}
+	for path, want := range tests {
+		if got := set.Layer(path); got != want {
+			t.Errorf("Layer(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if !set.Empty() {
+		t.Error("layers alone: want an empty rule set")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.RulesConfig
+		want []string
+	}{
+		{
+			name: "allow",
+			cfg: config.RulesConfig{Layers: layers, Allow: []config.DependencyRule{
+				{From: "cmd", To: []string{"service"}},
+			}},
+			want: []string{"not_allowed Report->Open", "not_allowed HandleScan->Open"},
+		},
+		{
+			name: "forbid by path and name",
+			cfg: config.RulesConfig{Layers: layers, Forbid: []config.DependencyRule{
+				{From: "internal/cmd/scan.go", To: []string{"store#Op*"}},
+			}},
+			want: []string{"forbidden HandleScan->Open"},
+		},
+		{
+			name: "only",
+			cfg: config.RulesConfig{Layers: layers, Only: []config.OnlyCallersRule{
+				{Target: "store", Callers: []string{"service", "internal/cmd/report.go"}},
+			}},
+			want: []string{"only HandleScan->Open"},
+		},
+		{
+			name: "dep types",
+			cfg: config.RulesConfig{Layers: layers, Forbid: []config.DependencyRule{
+				{From: "cmd", To: []string{"store"}, DepTypes: []string{"uses_type"}},
+			}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := edges(check(t, tt.cfg, CheckOptions{}))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCheckFromAndCallSite(t *testing.T) {
+	root := t.TempDir()
+	src := "package cmd\n\nfunc HandleScan() {\n\tr := service.Run()\n\tdb := store.Open()\n\t_ = db\n}\n"
+	if err := os.MkdirAll(filepath.Join(root, "internal/cmd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "internal/cmd/scan.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.RulesConfig{Layers: layers, Forbid: []config.DependencyRule{
+		{From: "cmd", To: []string{"store"}, Severity: "warning", Reason: "go through service"},
+	}}
+	vs := check(t, cfg, CheckOptions{ProjectRoot: root, From: map[string]bool{"scan": true}})
+	if len(vs) != 1 {
+		t.Fatalf("got %v, want one violation", edges(vs))
+	}
+	v := vs[0]
+	if v.Location != "internal/cmd/scan.go:5" {
+		t.Errorf("location = %s, want internal/cmd/scan.go:5", v.Location)
+	}
+	if v.Severity != "warning" || v.FromLayer != "cmd" || v.ToLayer != "store" {
+		t.Errorf("violation = %+v", v)
+	}
+	if want := "HandleScan calls Open: cmd must not depend on store (go through service)"; v.Message() != want {
+		t.Errorf("message = %q, want %q", v.Message(), want)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m, err := CompileMatcher([]string{"cmd/server/**#main", "#Handle*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, path string
+		want       bool
+	}{
+		{"main", "cmd/server/main.go", true},
+		{"main", "cmd/worker/main.go", false},
+		{"HandleLogin", "internal/api/auth.go", true},
+		{"handle", "internal/api/auth.go", false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(&store.Entity{Name: tt.name, FilePath: tt.path}); got != tt.want {
+			t.Errorf("Match(%s in %s) = %v, want %v", tt.name, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scip
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// textEncodingUTF8 is Metadata.text_document_encoding for UTF-8 sources.
+const textEncodingUTF8 = 1
+
+// Marshal encodes idx in the SCIP protobuf format.
+func Marshal(idx *Index) []byte {
+	var b []byte
+	b = appendMessage(b, 1, encodeMetadata(&idx.Metadata))
+	for _, doc := range idx.Documents {
+		b = appendMessage(b, 2, encodeDocument(doc))
+	}
+	for _, sym := range idx.ExternalSymbols {
+		b = appendMessage(b, 3, encodeSymbol(sym))
+	}
+	return b
+}
+
+// WriteFile encodes idx and writes it to path, replacing the file atomically.
+func WriteFile(path string, idx *Index) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".scip-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(Marshal(idx)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func encodeMetadata(m *Metadata) []byte {
+	var tool []byte
+	tool = appendString(tool, 1, m.ToolName)
+	tool = appendString(tool, 2, m.ToolVersion)
+
+	var b []byte
+	b = appendMessage(b, 2, tool)
+	b = appendString(b, 3, m.ProjectRoot)
+	return appendVarint(b, 4, textEncodingUTF8)
+}
+
+func encodeDocument(doc *Document) []byte {
+	var b []byte
+	b = appendString(b, 1, doc.RelativePath)
+	for _, occ := range doc.Occurrences {
+		b = appendMessage(b, 2, encodeOccurrence(occ))
+	}
+	for _, sym := range doc.Symbols {
+		b = appendMessage(b, 3, encodeSymbol(sym))
+	}
+	b = appendString(b, 4, doc.Language)
+	b = appendString(b, 5, doc.Text)
+	return appendVarint(b, 6, uint64(doc.PositionEncoding))
+}
+
+func encodeOccurrence(occ *Occurrence) []byte {
+	var b []byte
+	b = appendRange(b, 1, occ.Range)
+	b = appendString(b, 2, occ.Symbol)
+	b = appendVarint(b, 3, uint64(occ.SymbolRoles))
+	if occ.EnclosingRange != nil {
+		b = appendRange(b, 7, *occ.EnclosingRange)
+	}
+	return b
+}
+
+func encodeSymbol(sym *SymbolInformation) []byte {
+	var b []byte
+	b = appendString(b, 1, sym.Symbol)
+	for _, d := range sym.Documentation {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, d)
+	}
+	b = appendVarint(b, 5, uint64(sym.Kind))
+	b = appendString(b, 6, sym.DisplayName)
+	if sym.Signature != nil {
+		b = appendMessage(b, 7, encodeDocument(sym.Signature))
+	}
+	return appendString(b, 8, sym.EnclosingSymbol)
+}
+
+// appendRange appends r as a packed int32 field, in the three-element form
+// when it spans a single line.
+func appendRange(b []byte, num protowire.Number, r Range) []byte {
+	vals := []int32{r.StartLine, r.StartChar, r.EndLine, r.EndChar}
+	if r.StartLine == r.EndLine {
+		vals = []int32{r.StartLine, r.StartChar, r.EndChar}
+	}
+	var packed []byte
+	for _, v := range vals {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+	return appendMessage(b, num, packed)
+}
+
+// appendMessage appends a length-delimited field. Empty messages are still
+// written, since their presence can matter.
+func appendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendString appends a string field, omitting it when empty as proto3 does.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendVarint appends an integer field, omitting it when zero as proto3 does.
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
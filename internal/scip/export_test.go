@@ -0,0 +1,153 @@
+package scip
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/store"
+)
+
+const exportSource = `package auth
+
+type Service struct {
+	db *DB
+}
+
+func (s *Service) Login(name string) bool {
+	h := hash(name)
+	return h != "" && hash(h) != ""
+}
+
+func hash(s string) string { return s }
+`
+
+func newExportGraph(t *testing.T) (*store.MemoryStore, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "auth", "service.go"), []byte(exportSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	end := func(n int) *int { return &n }
+	s := store.NewMemoryStore()
+	s.CreateEntitiesBulk([]*store.Entity{
+		{ID: "tp-service", Name: "Service", EntityType: "type", Kind: "struct", FilePath: "auth/service.go", LineStart: 3, LineEnd: end(5), Language: "go", Status: "active"},
+		{ID: "fn-login", Name: "Login", EntityType: "method", Receiver: "*Service", FilePath: "auth/service.go", LineStart: 7, LineEnd: end(10), Language: "go", Status: "active", Signature: "(name: string) -> bool", DocComment: "Login checks a user."},
+		{ID: "fn-hash", Name: "hash", EntityType: "function", FilePath: "auth/service.go", LineStart: 12, LineEnd: end(12), Language: "go", Status: "active"},
+	})
+	s.CreateDependenciesBulk([]*store.Dependency{
+		{FromID: "fn-login", ToID: "fn-hash", DepType: "calls"},
+		{FromID: "fn-login", ToID: "tp-service", DepType: "uses_type"},
+	})
+	s.SaveBulkMetrics([]*store.Metrics{{EntityID: "fn-hash", PageRank: 0.25, InDegree: 1}})
+	return s, root
+}
+
+func TestFromGraph(t *testing.T) {
+	s, root := newExportGraph(t)
+	g, err := graph.BuildExport(s, graph.ExportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := FromGraph(g, ExportOptions{ProjectRoot: root, ToolVersion: "test"})
+	if len(idx.Documents) != 1 {
+		t.Fatalf("got %d documents, want 1", len(idx.Documents))
+	}
+	doc := idx.Documents[0]
+	if doc.Language != "Go" || len(doc.Symbols) != 3 {
+		t.Fatalf("document = %+v", doc)
+	}
+
+	syms := make(map[string]*SymbolInformation)
+	for _, sym := range doc.Symbols {
+		syms[sym.DisplayName] = sym
+	}
+	if got := syms["Login"].Symbol; got != "cx go . . auth/`service.go`/Service#Login()." {
+		t.Errorf("Login symbol = %q", got)
+	}
+	if syms["Service"].Kind != KindStruct || syms["Login"].Kind != KindMethod {
+		t.Errorf("kinds = %v, %v", syms["Service"].Kind, syms["Login"].Kind)
+	}
+	if doc := syms["hash"].Documentation; len(doc) != 1 || !strings.Contains(doc[0], "pagerank=0.25") {
+		t.Errorf("hash documentation = %q, want the PageRank", doc)
+	}
+	if syms["Login"].Signature == nil || syms["Login"].Documentation[0] != "Login checks a user." {
+		t.Errorf("Login info = %+v", syms["Login"])
+	}
+
+	// Definitions sit on the identifier; both calls to hash are references
+	var refs []Range
+	for _, occ := range doc.Occurrences {
+		if occ.Symbol != syms["hash"].Symbol {
+			continue
+		}
+		if occ.IsDefinition() {
+			if occ.Range != (Range{11, 5, 11, 9}) || occ.EnclosingRange == nil {
+				t.Errorf("hash definition = %+v", occ)
+			}
+			continue
+		}
+		refs = append(refs, occ.Range)
+	}
+	want := []Range{{7, 6, 7, 10}, {8, 19, 8, 23}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("hash references = %v, want %v", refs, want)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	s, root := newExportGraph(t)
+	g, _ := graph.BuildExport(s, graph.ExportFilter{})
+	path := filepath.Join(t.TempDir(), "index.scip")
+	if err := WriteFile(path, FromGraph(g, ExportOptions{ProjectRoot: root})); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Metadata.ToolName != "cx" || idx.Documents[0].PositionEncoding != PositionEncodingUTF8 {
+		t.Errorf("metadata lost: %+v, %+v", idx.Metadata, idx.Documents[0])
+	}
+	signatures := 0
+	for _, sym := range idx.Documents[0].Symbols {
+		if sym.Signature != nil && sym.Signature.Text != "" {
+			signatures++
+		}
+	}
+	if signatures != 1 {
+		t.Errorf("got %d signatures after round trip, want 1", signatures)
+	}
+
+	// Importing the export reproduces the graph's references
+	stats, err := Import(s, idx, ImportOptions{ProjectRoot: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Symbols != 3 || stats.Edges != 2 || stats.Confirmed != 2 {
+		t.Errorf("import stats = %+v, want every symbol mapped and both edges confirmed", stats)
+	}
+}
+
+func TestUniqueSymbol(t *testing.T) {
+	taken := make(map[string]bool)
+	fn := &store.Entity{Name: "parse", EntityType: "function", FilePath: "a/b.py", Language: "python"}
+	if got := uniqueSymbol(fn, taken); got != "cx python . . a/`b.py`/parse()." {
+		t.Errorf("symbol = %q", got)
+	}
+	if got := uniqueSymbol(fn, taken); got != "cx python . . a/`b.py`/parse(+1)." {
+		t.Errorf("overload symbol = %q", got)
+	}
+	for _, sym := range []string{"cx python . . a/`b.py`/parse().", "cx python . . a/`b.py`/parse(+1)."} {
+		if parsed, err := ParseSymbol(sym); err != nil || parsed.Name() != "parse" {
+			t.Errorf("ParseSymbol(%q) = %+v, %v", sym, parsed, err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package scip
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anthropics/cx/internal/extract"
+	"github.com/anthropics/cx/internal/graph"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// ExportOptions controls how the cx graph is written as a SCIP index.
+type ExportOptions struct {
+	// ProjectRoot is the absolute path of the cx project. Source files are
+	// read from it to place occurrences on the exact identifier; without it
+	// occurrences point at the start of the enclosing entity.
+	ProjectRoot string
+	ToolVersion string
+}
+
+// scipLanguages maps cx language names to SCIP's Language enum names.
+var scipLanguages = map[string]string{
+	"go":         "Go",
+	"typescript": "TypeScript",
+	"javascript": "JavaScript",
+	"python":     "Python",
+	"java":       "Java",
+	"rust":       "Rust",
+	"c":          "C",
+	"cpp":        "CPP",
+	"csharp":     "CSharp",
+	"php":        "PHP",
+	"kotlin":     "Kotlin",
+	"ruby":       "Ruby",
+}
+
+// FromGraph builds a SCIP index from an exported graph. Each entity becomes
+// a definition spanning its lines, with its doc comment, signature and
+// metrics (PageRank, betweenness, degree) as symbol documentation. Each
+// dependency becomes a reference to its target at every place the target's
+// name appears within the source entity.
+func FromGraph(g *graph.ExportGraph, opts ExportOptions) *Index {
+	idx := &Index{Metadata: Metadata{ToolName: "cx", ToolVersion: opts.ToolVersion}}
+	if opts.ProjectRoot != "" {
+		idx.Metadata.ProjectRoot = "file://" + filepath.ToSlash(opts.ProjectRoot)
+	}
+
+	src := &sourceCache{root: opts.ProjectRoot, files: make(map[string][]string)}
+	symbols := make(map[string]string) // entity ID -> symbol
+	taken := make(map[string]bool)
+	entities := make(map[string]*store.Entity)
+	docs := make(map[string]*Document)
+
+	for _, n := range g.Nodes {
+		e := n.Entity
+		if e.EntityType == string(extract.ImportEntity) {
+			continue
+		}
+		sym := uniqueSymbol(e, taken)
+		symbols[e.ID] = sym
+		entities[e.ID] = e
+
+		doc := docs[e.FilePath]
+		if doc == nil {
+			doc = &Document{RelativePath: e.FilePath, Language: scipLanguage(e.Language), PositionEncoding: PositionEncodingUTF8}
+			docs[e.FilePath] = doc
+		}
+
+		enclosing := entityRange(e, src.lines(e.FilePath))
+		doc.Occurrences = append(doc.Occurrences, &Occurrence{
+			Range:          src.find(e.FilePath, e.Name, e.LineStart, min(e.LineStart+5, endLine(e)), 1)[0],
+			Symbol:         sym,
+			SymbolRoles:    RoleDefinition,
+			EnclosingRange: &enclosing,
+		})
+		doc.Symbols = append(doc.Symbols, symbolInfo(n, sym))
+	}
+
+	for _, d := range g.Edges {
+		from, to := entities[d.FromID], entities[d.ToID]
+		if from == nil || to == nil {
+			continue
+		}
+		doc := docs[from.FilePath]
+		for _, r := range src.find(from.FilePath, to.Name, from.LineStart, endLine(from), -1) {
+			if r.StartLine == int32(from.LineStart-1) && from.Name == to.Name {
+				continue // the source's own name, not a reference
+			}
+			doc.Occurrences = append(doc.Occurrences, &Occurrence{Range: r, Symbol: symbols[to.ID]})
+		}
+	}
+
+	paths := make([]string, 0, len(docs))
+	for p := range docs {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		doc := docs[p]
+		sort.SliceStable(doc.Occurrences, func(i, j int) bool {
+			a, b := doc.Occurrences[i].Range, doc.Occurrences[j].Range
+			if a.StartLine != b.StartLine {
+				return a.StartLine < b.StartLine
+			}
+			return a.StartChar < b.StartChar
+		})
+		idx.Documents = append(idx.Documents, doc)
+	}
+	return idx
+}
+
+// symbolInfo describes an entity's symbol. SCIP has no field for arbitrary
+// metadata, so metrics are a documentation line of key=value pairs.
+func symbolInfo(n *graph.ExportNode, sym string) *SymbolInformation {
+	e := n.Entity
+	info := &SymbolInformation{Symbol: sym, DisplayName: e.Name, Kind: symbolKind(e)}
+	if e.Signature != "" {
+		info.Signature = &Document{Language: scipLanguage(e.Language), Text: e.Name + e.Signature}
+	}
+	if e.DocComment != "" {
+		info.Documentation = append(info.Documentation, e.DocComment)
+	}
+
+	var meta []string
+	if m := n.Metrics; m != nil {
+		meta = append(meta,
+			fmt.Sprintf("pagerank=%.6g", m.PageRank),
+			fmt.Sprintf("betweenness=%.6g", m.Betweenness),
+			fmt.Sprintf("in_degree=%d", m.InDegree),
+			fmt.Sprintf("out_degree=%d", m.OutDegree))
+	}
+	if c := n.Coverage; c != nil {
+		meta = append(meta, fmt.Sprintf("coverage=%.1f", c.CoveragePercent))
+	}
+	if len(n.Tags) > 0 {
+		meta = append(meta, "tags="+strings.Join(n.Tags, ","))
+	}
+	if len(meta) > 0 {
+		info.Documentation = append(info.Documentation, "cx: "+strings.Join(meta, " "))
+	}
+	return info
+}
+
+func symbolKind(e *store.Entity) SymbolKind {
+	switch e.EntityType {
+	case string(extract.FunctionEntity):
+		return KindFunction
+	case string(extract.MethodEntity):
+		return KindMethod
+	case string(extract.ConstEntity):
+		return KindConstant
+	case string(extract.VarEntity):
+		return KindVariable
+	case string(extract.EnumEntity):
+		return KindEnum
+	case string(extract.TypeEntity):
+		switch e.Kind {
+		case string(extract.StructKind):
+			return KindStruct
+		case string(extract.InterfaceKind):
+			return KindInterface
+		case string(extract.AliasKind):
+			return KindTypeAlias
+		case "class":
+			return KindClass
+		}
+		return KindType
+	}
+	return KindUnspecified
+}
+
+func scipLanguage(lang string) string {
+	if l, ok := scipLanguages[lang]; ok {
+		return l
+	}
+	return lang
+}
+
+// uniqueSymbol returns the SCIP symbol for e:
+//
+//	cx <language> . . <dir>/<dir>/`<file>`/<Receiver>#<Name>().
+//
+// Callables sharing a name in one file (overloads) are told apart with a
+// method disambiguator, other clashes with a numeric suffix on the name.
+func uniqueSymbol(e *store.Entity, taken map[string]bool) string {
+	lang := e.Language
+	if lang == "" {
+		lang = "."
+	}
+	var b strings.Builder
+	b.WriteString("cx " + strings.ReplaceAll(lang, " ", "  ") + " . . ")
+	for _, seg := range strings.Split(e.FilePath, "/") {
+		b.WriteString(formatName(seg) + "/")
+	}
+	prefix := b.String()
+
+	callable := e.EntityType == string(extract.FunctionEntity) || e.EntityType == string(extract.MethodEntity)
+	if recv := receiverName(e.Receiver); recv != "" && e.EntityType == string(extract.MethodEntity) {
+		prefix += formatName(recv) + "#"
+	}
+
+	for n := 0; ; n++ {
+		var sym string
+		switch {
+		case callable && n == 0:
+			sym = prefix + formatName(e.Name) + "()."
+		case callable:
+			sym = fmt.Sprintf("%s%s(+%d).", prefix, formatName(e.Name), n)
+		case n == 0:
+			sym = prefix + formatName(e.Name) + descriptorSuffix(e)
+		default:
+			sym = prefix + formatName(fmt.Sprintf("%s+%d", e.Name, n)) + descriptorSuffix(e)
+		}
+		if !taken[sym] {
+			taken[sym] = true
+			return sym
+		}
+	}
+}
+
+func descriptorSuffix(e *store.Entity) string {
+	if e.EntityType == string(extract.TypeEntity) || e.EntityType == string(extract.EnumEntity) {
+		return "#"
+	}
+	return "."
+}
+
+// receiverName strips pointers and type arguments: "*Cache[K, V]" -> "Cache".
+func receiverName(recv string) string {
+	recv = strings.TrimLeft(strings.TrimSpace(recv), "*&(")
+	if i := strings.IndexAny(recv, "[<) "); i >= 0 {
+		recv = recv[:i]
+	}
+	return recv
+}
+
+// formatName escapes name as a SCIP identifier when needed.
+func formatName(name string) string {
+	simple := name != ""
+	for i := 0; i < len(name); i++ {
+		if !isIdentChar(name[i]) {
+			simple = false
+			break
+		}
+	}
+	if simple {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func endLine(e *store.Entity) int {
+	if e.LineEnd == nil || *e.LineEnd < e.LineStart {
+		return e.LineStart
+	}
+	return *e.LineEnd
+}
+
+// entityRange spans an entity's lines, to the end of its last line when the
+// source is available.
+func entityRange(e *store.Entity, lines []string) Range {
+	end := endLine(e)
+	r := Range{StartLine: int32(e.LineStart - 1), EndLine: int32(end - 1)}
+	if end-1 < len(lines) {
+		r.EndChar = int32(len(lines[end-1]))
+	}
+	return r
+}
+
+// sourceCache reads project files once, split into lines.
+type sourceCache struct {
+	root  string
+	files map[string][]string
+}
+
+func (c *sourceCache) lines(path string) []string {
+	if lines, ok := c.files[path]; ok {
+		return lines
+	}
+	var lines []string
+	if c.root != "" {
+		if data, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(path))); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+	}
+	c.files[path] = lines
+	return lines
+}
+
+// find returns the ranges where name appears as a whole identifier on lines
+// first..last (1-based) of path, at most limit of them (-1 for all). If name
+// is not found, the single range returned is the start of line first.
+func (c *sourceCache) find(path, name string, first, last, limit int) []Range {
+	lines := c.lines(path)
+	var found []Range
+	for ln := first; ln <= last && ln-1 < len(lines) && ln > 0; ln++ {
+		line := lines[ln-1]
+		for off := 0; off < len(line); {
+			i := strings.Index(line[off:], name)
+			if i < 0 || name == "" {
+				break
+			}
+			start := off + i
+			end := start + len(name)
+			if (start == 0 || !isIdentChar(line[start-1])) && (end == len(line) || !isIdentChar(line[end])) {
+				found = append(found, Range{StartLine: int32(ln - 1), StartChar: int32(start), EndLine: int32(ln - 1), EndChar: int32(end)})
+				if len(found) == limit {
+					return found
+				}
+			}
+			off = end
+		}
+	}
+	if len(found) == 0 {
+		return []Range{{StartLine: int32(first - 1), EndLine: int32(first - 1)}}
+	}
+	return found
+}
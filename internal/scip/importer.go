@@ -0,0 +1,219 @@
+package scip
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/anthropics/cx/internal/extract"
+	"github.com/anthropics/cx/internal/store"
+)
+
+// ImportOptions controls how an index is applied to the store.
+type ImportOptions struct {
+	// ProjectRoot is the absolute path of the cx project. When the index was
+	// produced for a subdirectory (its metadata project_root is below
+	// ProjectRoot), document paths are rebased onto the project.
+	ProjectRoot string
+
+	// Replace removes tree-sitter calls and uses_type edges from entities in
+	// files the index covers when SCIP does not confirm them. Otherwise SCIP
+	// edges are added alongside the heuristic ones.
+	Replace bool
+}
+
+// ImportStats summarizes an import.
+type ImportStats struct {
+	Documents int // documents whose file has entities
+	Symbols   int // symbol definitions mapped onto entities
+	Unmapped  int // definitions with no matching entity (fields, locals, generated code)
+	Edges     int // precise reference edges written
+	Confirmed int // of Edges, how many tree-sitter had also found
+	Removed   int // unconfirmed tree-sitter edges removed (Replace only)
+}
+
+// Import maps the symbols defined in idx onto the active entities in s by
+// file, name and line, then writes a calls or uses_type edge with source=scip
+// from the entity enclosing each reference to the entity it refers to.
+func Import(s store.Store, idx *Index, opts ImportOptions) (*ImportStats, error) {
+	entities, err := s.QueryEntities(store.EntityFilter{Status: "active"})
+	if err != nil {
+		return nil, fmt.Errorf("query entities: %w", err)
+	}
+	byFile := make(map[string][]*store.Entity)
+	for _, e := range entities {
+		byFile[e.FilePath] = append(byFile[e.FilePath], e)
+	}
+
+	prefix := pathPrefix(idx.Metadata.ProjectRoot, opts.ProjectRoot)
+	stats := &ImportStats{}
+
+	// Pass 1: map symbol definitions onto entities across all documents, so
+	// references in one file resolve to definitions in another
+	defs := make(map[string]*store.Entity)
+	docEntities := make(map[*Document][]*store.Entity)
+	for _, doc := range idx.Documents {
+		ents := byFile[path.Join(prefix, doc.RelativePath)]
+		if len(ents) == 0 {
+			continue
+		}
+		docEntities[doc] = ents
+		stats.Documents++
+
+		for _, occ := range doc.Occurrences {
+			if !occ.IsDefinition() || defs[occ.Symbol] != nil {
+				continue
+			}
+			sym, err := ParseSymbol(occ.Symbol)
+			if err != nil || sym.IsLocal() {
+				continue
+			}
+			if e := matchDefinition(ents, sym.Name(), int(occ.Range.StartLine)+1); e != nil {
+				defs[occ.Symbol] = e
+				stats.Symbols++
+			} else {
+				stats.Unmapped++
+			}
+		}
+	}
+
+	// Pass 2: turn references into edges from the enclosing entity
+	edges := make(map[depKey]*store.Dependency)
+	covered := make(map[string]bool)
+	for doc, ents := range docEntities {
+		for _, e := range ents {
+			covered[e.ID] = true
+		}
+		for _, occ := range doc.Occurrences {
+			if occ.IsDefinition() || occ.SymbolRoles&RoleImport != 0 {
+				continue
+			}
+			target := defs[occ.Symbol]
+			if target == nil {
+				continue
+			}
+			depType := depTypeFor(target)
+			if depType == "" {
+				continue
+			}
+			from := enclosingEntity(ents, int(occ.Range.StartLine)+1)
+			if from == nil || from.ID == target.ID {
+				continue
+			}
+			k := depKey{from.ID, target.ID, depType}
+			if edges[k] == nil {
+				edges[k] = &store.Dependency{FromID: from.ID, ToID: target.ID, DepType: depType, Source: store.DepSourceSCIP}
+			}
+		}
+	}
+
+	heuristic, err := s.GetDependencies(store.DependencyFilter{Source: store.DepSourceTreeSitter})
+	if err != nil {
+		return nil, fmt.Errorf("get dependencies: %w", err)
+	}
+	var stale []*store.Dependency
+	for _, d := range heuristic {
+		k := depKey{d.FromID, d.ToID, d.DepType}
+		if e, ok := edges[k]; ok {
+			// Keep what tree-sitter knows that SCIP doesn't record
+			e.Optional = d.Optional
+			stats.Confirmed++
+		} else if opts.Replace && covered[d.FromID] && replaceable(d.DepType) {
+			stale = append(stale, d)
+		}
+	}
+
+	precise := make([]*store.Dependency, 0, len(edges))
+	for _, e := range edges {
+		precise = append(precise, e)
+	}
+	if err := s.CreateDependenciesBulk(precise); err != nil {
+		return nil, fmt.Errorf("save scip dependencies: %w", err)
+	}
+	stats.Edges = len(precise)
+
+	for _, d := range stale {
+		if err := s.DeleteDependency(d.FromID, d.ToID, d.DepType); err != nil {
+			return nil, fmt.Errorf("remove heuristic dependency: %w", err)
+		}
+		stats.Removed++
+	}
+	return stats, nil
+}
+
+type depKey struct{ from, to, depType string }
+
+// replaceable reports whether SCIP is authoritative for a dependency type.
+// Structural edges (implements, extends, contains, ...) are not references
+// and are always kept.
+func replaceable(depType string) bool {
+	return depType == string(extract.Calls) || depType == string(extract.UsesType)
+}
+
+// depTypeFor returns the edge type for a reference to e, or "" if references
+// to e are not tracked as dependencies.
+func depTypeFor(e *store.Entity) string {
+	switch e.EntityType {
+	case string(extract.FunctionEntity), string(extract.MethodEntity):
+		return string(extract.Calls)
+	case string(extract.TypeEntity), string(extract.EnumEntity):
+		return string(extract.UsesType)
+	}
+	return ""
+}
+
+// matchDefinition returns the innermost entity named name whose lines
+// contain line.
+func matchDefinition(ents []*store.Entity, name string, line int) *store.Entity {
+	var best *store.Entity
+	for _, e := range ents {
+		if e.Name == name && contains(e, line) && (best == nil || span(e) < span(best)) {
+			best = e
+		}
+	}
+	return best
+}
+
+// enclosingEntity returns the innermost entity whose lines contain line.
+func enclosingEntity(ents []*store.Entity, line int) *store.Entity {
+	var best *store.Entity
+	for _, e := range ents {
+		if e.EntityType == string(extract.ImportEntity) || !contains(e, line) {
+			continue
+		}
+		if best == nil || span(e) < span(best) {
+			best = e
+		}
+	}
+	return best
+}
+
+func contains(e *store.Entity, line int) bool {
+	return e.LineStart <= line && line <= e.LineStart+span(e)
+}
+
+func span(e *store.Entity) int {
+	if e.LineEnd == nil || *e.LineEnd < e.LineStart {
+		return 0
+	}
+	return *e.LineEnd - e.LineStart
+}
+
+// pathPrefix returns the path of the index's project root relative to the cx
+// project, or "" if it is the same directory or unrelated.
+func pathPrefix(indexRoot, projectRoot string) string {
+	if indexRoot == "" || projectRoot == "" {
+		return ""
+	}
+	u, err := url.Parse(indexRoot)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	rel, err := filepath.Rel(projectRoot, filepath.FromSlash(u.Path))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,325 @@
+// Package scip reads and writes SCIP code intelligence indexes
+// (https://github.com/sourcegraph/scip). Indexes produced by scip-go,
+// scip-typescript, scip-java and other indexers are mapped onto cx entities;
+// in the other direction, the cx graph is written as an index for languages
+// that have no indexer.
+//
+// Only the parts of the SCIP schema cx uses are supported: documents, their
+// occurrences and symbol information. Unknown fields are skipped, so newer
+// indexes remain readable.
+package scip
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SymbolRole bits set on Occurrence.SymbolRoles.
+const (
+	RoleDefinition        int32 = 0x1
+	RoleImport            int32 = 0x2
+	RoleWriteAccess       int32 = 0x4
+	RoleReadAccess        int32 = 0x8
+	RoleGenerated         int32 = 0x10
+	RoleTest              int32 = 0x20
+	RoleForwardDefinition int32 = 0x40
+)
+
+// SymbolKind is SymbolInformation.Kind. Only the kinds cx entities map to are named.
+type SymbolKind int32
+
+const (
+	KindUnspecified SymbolKind = 0
+	KindClass       SymbolKind = 7
+	KindConstant    SymbolKind = 8
+	KindEnum        SymbolKind = 11
+	KindFunction    SymbolKind = 17
+	KindInterface   SymbolKind = 21
+	KindMethod      SymbolKind = 26
+	KindStruct      SymbolKind = 51
+	KindType        SymbolKind = 56
+	KindTypeAlias   SymbolKind = 57
+	KindVariable    SymbolKind = 63
+)
+
+// Index is a SCIP index: the documents of a project and their symbols.
+type Index struct {
+	Metadata        Metadata
+	Documents       []*Document
+	ExternalSymbols []*SymbolInformation
+}
+
+// Metadata describes the tool that produced an index.
+type Metadata struct {
+	ToolName    string
+	ToolVersion string
+	ProjectRoot string // URI, usually file:///path/to/repo
+}
+
+// Document holds the occurrences and symbols of one source file. It is also
+// used for a symbol's signature, with only Language and Text set.
+type Document struct {
+	RelativePath     string // relative to Metadata.ProjectRoot, forward slashes
+	Language         string
+	Occurrences      []*Occurrence
+	Symbols          []*SymbolInformation
+	Text             string
+	PositionEncoding int32 // PositionEncodingUTF8 for byte offsets
+}
+
+// PositionEncodingUTF8 says character offsets count UTF-8 bytes from the line start.
+const PositionEncodingUTF8 int32 = 1
+
+// Occurrence is a reference to, or definition of, a symbol at a source range.
+type Occurrence struct {
+	Range          Range
+	Symbol         string
+	SymbolRoles    int32
+	EnclosingRange *Range // range of the enclosing definition, if the indexer emits it
+}
+
+// IsDefinition reports whether the occurrence defines its symbol.
+func (o *Occurrence) IsDefinition() bool {
+	return o.SymbolRoles&RoleDefinition != 0
+}
+
+// Range is a zero-based source range. Lines and characters follow SCIP's
+// encoding: [start line, start char, end line, end char], with the end line
+// omitted on the wire for single-line ranges.
+type Range struct {
+	StartLine, StartChar int32
+	EndLine, EndChar     int32
+}
+
+// SymbolInformation carries metadata about a symbol defined in a document.
+type SymbolInformation struct {
+	Symbol          string
+	Documentation   []string // Markdown
+	Kind            SymbolKind
+	DisplayName     string
+	Signature       *Document // signature_documentation
+	EnclosingSymbol string
+}
+
+// ErrBadFormat is returned for data that is not a valid SCIP index.
+var ErrBadFormat = errors.New("not a valid SCIP index")
+
+// ReadFile reads and decodes the SCIP index at path.
+func ReadFile(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// Unmarshal decodes a SCIP index from its protobuf encoding.
+func Unmarshal(data []byte) (*Index, error) {
+	idx := &Index{}
+	err := decodeFields(data, func(f field) error {
+		switch f.num {
+		case 1:
+			return decodeMetadata(f.bytes, &idx.Metadata)
+		case 2:
+			doc, err := decodeDocument(f.bytes)
+			if err != nil {
+				return err
+			}
+			idx.Documents = append(idx.Documents, doc)
+		case 3:
+			sym, err := decodeSymbol(f.bytes)
+			if err != nil {
+				return err
+			}
+			idx.ExternalSymbols = append(idx.ExternalSymbols, sym)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// field is one decoded protobuf field. Length-delimited values are in bytes,
+// varints in varint.
+type field struct {
+	num    protowire.Number
+	typ    protowire.Type
+	bytes  []byte
+	varint uint64
+}
+
+// decodeFields calls fn for each field of the message encoded in b.
+func decodeFields(b []byte, fn func(field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrBadFormat, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("%w: field %d: %v", ErrBadFormat, num, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeMetadata(b []byte, m *Metadata) error {
+	return decodeFields(b, func(f field) error {
+		switch f.num {
+		case 2: // tool_info
+			return decodeFields(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					m.ToolName = string(f.bytes)
+				case 2:
+					m.ToolVersion = string(f.bytes)
+				}
+				return nil
+			})
+		case 3:
+			m.ProjectRoot = string(f.bytes)
+		}
+		return nil
+	})
+}
+
+func decodeDocument(b []byte) (*Document, error) {
+	doc := &Document{}
+	err := decodeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			doc.RelativePath = string(f.bytes)
+		case 2:
+			occ, err := decodeOccurrence(f.bytes)
+			if err != nil {
+				return err
+			}
+			doc.Occurrences = append(doc.Occurrences, occ)
+		case 3:
+			sym, err := decodeSymbol(f.bytes)
+			if err != nil {
+				return err
+			}
+			doc.Symbols = append(doc.Symbols, sym)
+		case 4:
+			doc.Language = string(f.bytes)
+		case 5:
+			doc.Text = string(f.bytes)
+		case 6:
+			doc.PositionEncoding = int32(f.varint)
+		}
+		return nil
+	})
+	return doc, err
+}
+
+func decodeOccurrence(b []byte) (*Occurrence, error) {
+	occ := &Occurrence{}
+	var rng, enclosing []int32
+	err := decodeFields(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			rng, err = appendInt32s(rng, f)
+		case 2:
+			occ.Symbol = string(f.bytes)
+		case 3:
+			occ.SymbolRoles = int32(f.varint)
+		case 7:
+			enclosing, err = appendInt32s(enclosing, f)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if occ.Range, err = toRange(rng); err != nil {
+		return nil, err
+	}
+	if len(enclosing) > 0 {
+		r, err := toRange(enclosing)
+		if err != nil {
+			return nil, err
+		}
+		occ.EnclosingRange = &r
+	}
+	return occ, nil
+}
+
+func decodeSymbol(b []byte) (*SymbolInformation, error) {
+	sym := &SymbolInformation{}
+	err := decodeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			sym.Symbol = string(f.bytes)
+		case 3:
+			sym.Documentation = append(sym.Documentation, string(f.bytes))
+		case 5:
+			sym.Kind = SymbolKind(f.varint)
+		case 6:
+			sym.DisplayName = string(f.bytes)
+		case 7:
+			sig, err := decodeDocument(f.bytes)
+			if err != nil {
+				return err
+			}
+			sym.Signature = sig
+		case 8:
+			sym.EnclosingSymbol = string(f.bytes)
+		}
+		return nil
+	})
+	return sym, err
+}
+
+// appendInt32s appends a repeated int32 field, packed or not.
+func appendInt32s(dst []int32, f field) ([]int32, error) {
+	if f.typ == protowire.VarintType {
+		return append(dst, int32(f.varint)), nil
+	}
+	b := f.bytes
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, fmt.Errorf("%w: packed int32: %v", ErrBadFormat, protowire.ParseError(n))
+		}
+		dst = append(dst, int32(v))
+		b = b[n:]
+	}
+	return dst, nil
+}
+
+func toRange(v []int32) (Range, error) {
+	switch len(v) {
+	case 3:
+		return Range{StartLine: v[0], StartChar: v[1], EndLine: v[0], EndChar: v[2]}, nil
+	case 4:
+		return Range{StartLine: v[0], StartChar: v[1], EndLine: v[2], EndChar: v[3]}, nil
+	default:
+		return Range{}, fmt.Errorf("%w: range has %d elements, want 3 or 4", ErrBadFormat, len(v))
+	}
+}
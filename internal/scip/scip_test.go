@@ -0,0 +1,230 @@
+package scip
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/cx/internal/store"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// occ encodes an Occurrence with a packed range.
+func occ(symbol string, roles int32, rng ...int32) []byte {
+	var packed []byte
+	for _, v := range rng {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, packed)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, symbol)
+	if roles != 0 {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(roles))
+	}
+	return b
+}
+
+// doc encodes a Document from encoded occurrences.
+func doc(path string, occurrences ...[]byte) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, path)
+	for _, o := range occurrences {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, o)
+	}
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	return protowire.AppendString(b, "go")
+}
+
+// index encodes an Index from encoded documents.
+func index(projectRoot string, docs ...[]byte) []byte {
+	var meta []byte
+	meta = protowire.AppendTag(meta, 3, protowire.BytesType)
+	meta = protowire.AppendString(meta, projectRoot)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, meta)
+	for _, d := range docs {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, d)
+	}
+	// An unknown field must be skipped
+	b = protowire.AppendTag(b, 99, protowire.VarintType)
+	return protowire.AppendVarint(b, 7)
+}
+
+func TestParseSymbol(t *testing.T) {
+	tests := []struct {
+		symbol string
+		name   string
+		pkg    string
+		n      int
+	}{
+		{"scip-go gomod github.com/acme/app v1.0.0 `github.com/acme/app/auth`/Service#Login().", "Login", "github.com/acme/app", 3},
+		{"scip-typescript npm @acme/web 2.1.0 src/`api.ts`/fetchUser().(id)", "fetchUser", "@acme/web", 4},
+		{"scip-java maven . . com/acme/Repo#find(+1).[T]", "find", "", 5},
+		{"rust-analyzer cargo my  crate 0.1.0 util/`a``b`.", "a`b", "my crate", 2},
+	}
+	for _, tt := range tests {
+		sym, err := ParseSymbol(tt.symbol)
+		if err != nil {
+			t.Errorf("ParseSymbol(%q): %v", tt.symbol, err)
+			continue
+		}
+		if sym.Name() != tt.name || sym.Package != tt.pkg || len(sym.Descriptors) != tt.n {
+			t.Errorf("ParseSymbol(%q) = name %q, package %q, %d descriptors; want %q, %q, %d",
+				tt.symbol, sym.Name(), sym.Package, len(sym.Descriptors), tt.name, tt.pkg, tt.n)
+		}
+	}
+
+	if sym, err := ParseSymbol("local 12"); err != nil || !sym.IsLocal() {
+		t.Errorf("local symbol = %+v, %v", sym, err)
+	}
+	for _, bad := range []string{"scip-go gomod", "a b c d Foo", "a b c d `Foo"} {
+		if _, err := ParseSymbol(bad); err == nil {
+			t.Errorf("ParseSymbol(%q) should fail", bad)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := index("file:///repo",
+		doc("auth/service.go",
+			occ("s a b c Login().", RoleDefinition, 9, 5, 10),
+			occ("s a b c hash().", 0, 12, 1, 13, 2),
+		),
+	)
+	idx, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Metadata.ProjectRoot != "file:///repo" || len(idx.Documents) != 1 {
+		t.Fatalf("index = %+v", idx)
+	}
+	d := idx.Documents[0]
+	if d.RelativePath != "auth/service.go" || d.Language != "go" || len(d.Occurrences) != 2 {
+		t.Fatalf("document = %+v", d)
+	}
+	if got := d.Occurrences[0].Range; got != (Range{9, 5, 9, 10}) || !d.Occurrences[0].IsDefinition() {
+		t.Errorf("3-element range = %+v", got)
+	}
+	if got := d.Occurrences[1].Range; !reflect.DeepEqual(got, Range{12, 1, 13, 2}) {
+		t.Errorf("4-element range = %+v", got)
+	}
+
+	if _, err := Unmarshal(data[:len(data)-5]); !errors.Is(err, ErrBadFormat) {
+		t.Errorf("truncated index: err = %v, want ErrBadFormat", err)
+	}
+}
+
+func newImportStore() *store.MemoryStore {
+	end := func(n int) *int { return &n }
+	s := store.NewMemoryStore()
+	s.CreateEntitiesBulk([]*store.Entity{
+		{ID: "tp-service", Name: "Service", EntityType: "type", FilePath: "auth/service.go", LineStart: 5, LineEnd: end(8), Status: "active"},
+		{ID: "fn-login", Name: "Login", EntityType: "method", FilePath: "auth/service.go", LineStart: 10, LineEnd: end(20), Status: "active"},
+		{ID: "fn-hash", Name: "hash", EntityType: "function", FilePath: "auth/util.go", LineStart: 3, LineEnd: end(6), Status: "active"},
+		{ID: "fn-other-hash", Name: "hash", EntityType: "function", FilePath: "billing/hash.go", LineStart: 1, LineEnd: end(4), Status: "active"},
+	})
+	s.CreateDependenciesBulk([]*store.Dependency{
+		{FromID: "fn-login", ToID: "fn-hash", DepType: "calls", Optional: true},
+		{FromID: "fn-login", ToID: "fn-other-hash", DepType: "calls"}, // resolved to the wrong hash by name
+		{FromID: "fn-login", ToID: "tp-service", DepType: "method_of"},
+	})
+	return s
+}
+
+func newImportIndex(t *testing.T) *Index {
+	t.Helper()
+	const (
+		service = "scip-go gomod acme v1 `acme/auth`/Service#"
+		login   = "scip-go gomod acme v1 `acme/auth`/Service#Login()."
+		hash    = "scip-go gomod acme v1 `acme/auth`/hash()."
+	)
+	idx, err := Unmarshal(index("file:///repo",
+		doc("auth/service.go",
+			occ(service, RoleDefinition, 4, 5, 12),
+			occ(login, RoleDefinition, 9, 18, 23),
+			occ(service, 0, 9, 6, 14), // receiver type, inside Login
+			occ(hash, 0, 14, 8, 12),
+			occ("local 3", RoleDefinition, 11, 1, 2),
+		),
+		doc("auth/util.go", occ(hash, RoleDefinition, 2, 5, 9)),
+		doc("generated/unscanned.go", occ(hash, 0, 1, 1, 5)),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestImportAugments(t *testing.T) {
+	s := newImportStore()
+	stats, err := Import(s, newImportIndex(t), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Documents != 2 || stats.Symbols != 3 || stats.Edges != 2 || stats.Confirmed != 1 || stats.Removed != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	precise, _ := s.GetDependencies(store.DependencyFilter{Source: store.DepSourceSCIP})
+	want := []string{"fn-login->fn-hash calls", "fn-login->tp-service uses_type"}
+	var got []string
+	for _, d := range precise {
+		got = append(got, d.FromID+"->"+d.ToID+" "+d.DepType)
+		if d.ToID == "fn-hash" && !d.Optional {
+			t.Error("optional flag from tree-sitter was lost")
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scip edges = %v, want %v", got, want)
+	}
+	if n, _ := s.CountDependencies(); n != 4 {
+		t.Errorf("%d dependencies, want heuristic edges kept alongside (4)", n)
+	}
+}
+
+func TestImportReplaces(t *testing.T) {
+	s := newImportStore()
+	stats, err := Import(s, newImportIndex(t), ImportOptions{Replace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Removed != 1 {
+		t.Errorf("removed %d heuristic edges, want 1", stats.Removed)
+	}
+	deps, _ := s.GetDependenciesFrom("fn-login")
+	for _, d := range deps {
+		if d.ToID == "fn-other-hash" {
+			t.Error("unconfirmed heuristic call was not removed")
+		}
+	}
+	if len(deps) != 3 {
+		t.Errorf("got %d edges from Login, want 2 precise + method_of", len(deps))
+	}
+}
+
+func TestImportRebasesSubdirectoryIndex(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.CreateEntitiesBulk([]*store.Entity{
+		{ID: "fn-a", Name: "a", EntityType: "function", FilePath: "web/src/a.ts", LineStart: 1, Status: "active"},
+		{ID: "fn-b", Name: "b", EntityType: "function", FilePath: "web/src/b.ts", LineStart: 1, Status: "active"},
+	})
+	idx, err := Unmarshal(index("file:///repo/web",
+		doc("src/a.ts", occ("t npm w 1 a().", RoleDefinition, 0, 9, 10), occ("t npm w 1 b().", 0, 0, 20, 21)),
+		doc("src/b.ts", occ("t npm w 1 b().", RoleDefinition, 0, 9, 10)),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := Import(s, idx, ImportOptions{ProjectRoot: "/repo"})
+	if err != nil || stats.Edges != 1 {
+		t.Errorf("Import = %+v, %v; want 1 edge", stats, err)
+	}
+}
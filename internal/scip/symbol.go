@@ -0,0 +1,185 @@
+package scip
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Descriptor suffixes, which say what kind of name a descriptor is.
+const (
+	SuffixNamespace     = '/'
+	SuffixType          = '#'
+	SuffixTerm          = '.'
+	SuffixMeta          = ':'
+	SuffixMacro         = '!'
+	SuffixMethod        = '('
+	SuffixTypeParameter = '['
+	SuffixParameter     = ')'
+)
+
+// Symbol is a parsed SCIP symbol string such as
+//
+//	scip-go gomod github.com/acme/app v1.2.0 `internal/auth`/Service#Login().
+type Symbol struct {
+	Scheme      string
+	Manager     string
+	Package     string
+	Version     string
+	Descriptors []Descriptor
+	Local       string // set for document-local symbols ("local 42"), which have no descriptors
+}
+
+// Descriptor is one component of a symbol's path.
+type Descriptor struct {
+	Name          string
+	Suffix        rune
+	Disambiguator string // for methods: distinguishes overloads
+}
+
+// IsLocal reports whether the symbol is local to a document.
+func (s Symbol) IsLocal() bool {
+	return s.Local != ""
+}
+
+// Name returns the name of the innermost descriptor, skipping parameters and
+// type parameters: "Login" for Service#Login().
+func (s Symbol) Name() string {
+	for i := len(s.Descriptors) - 1; i >= 0; i-- {
+		d := s.Descriptors[i]
+		if d.Suffix != SuffixParameter && d.Suffix != SuffixTypeParameter {
+			return d.Name
+		}
+	}
+	return ""
+}
+
+// ParseSymbol parses a SCIP symbol string.
+func ParseSymbol(sym string) (Symbol, error) {
+	if local, ok := strings.CutPrefix(sym, "local "); ok {
+		return Symbol{Local: local}, nil
+	}
+
+	var s Symbol
+	rest := sym
+	for _, part := range []*string{&s.Scheme, &s.Manager, &s.Package, &s.Version} {
+		var ok bool
+		*part, rest, ok = cutSpaceEscaped(rest)
+		if !ok {
+			return Symbol{}, fmt.Errorf("symbol %q: missing scheme or package", sym)
+		}
+	}
+
+	for rest != "" {
+		var d Descriptor
+		var err error
+		d, rest, err = parseDescriptor(rest)
+		if err != nil {
+			return Symbol{}, fmt.Errorf("symbol %q: %w", sym, err)
+		}
+		s.Descriptors = append(s.Descriptors, d)
+	}
+	if len(s.Descriptors) == 0 {
+		return Symbol{}, fmt.Errorf("symbol %q: no descriptors", sym)
+	}
+	return s, nil
+}
+
+// cutSpaceEscaped splits s at the first single space. Double spaces are
+// escaped spaces within the field; "." stands for an empty field.
+func cutSpaceEscaped(s string) (field, rest string, ok bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != ' ' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == ' ' {
+			b.WriteByte(' ')
+			i++
+			continue
+		}
+		field = b.String()
+		if field == "." {
+			field = ""
+		}
+		return field, s[i+1:], true
+	}
+	return "", "", false
+}
+
+func parseDescriptor(s string) (Descriptor, string, error) {
+	switch s[0] {
+	case '[':
+		name, rest, err := parseName(s[1:])
+		if err != nil {
+			return Descriptor{}, "", err
+		}
+		if !strings.HasPrefix(rest, "]") {
+			return Descriptor{}, "", fmt.Errorf("unterminated type parameter %q", name)
+		}
+		return Descriptor{Name: name, Suffix: SuffixTypeParameter}, rest[1:], nil
+	case '(':
+		name, rest, err := parseName(s[1:])
+		if err != nil {
+			return Descriptor{}, "", err
+		}
+		if !strings.HasPrefix(rest, ")") {
+			return Descriptor{}, "", fmt.Errorf("unterminated parameter %q", name)
+		}
+		return Descriptor{Name: name, Suffix: SuffixParameter}, rest[1:], nil
+	}
+
+	name, rest, err := parseName(s)
+	if err != nil {
+		return Descriptor{}, "", err
+	}
+	if rest == "" {
+		return Descriptor{}, "", fmt.Errorf("descriptor %q has no suffix", name)
+	}
+	switch suffix := rune(rest[0]); suffix {
+	case SuffixNamespace, SuffixType, SuffixTerm, SuffixMeta, SuffixMacro:
+		return Descriptor{Name: name, Suffix: suffix}, rest[1:], nil
+	case SuffixMethod:
+		end := strings.Index(rest, ").")
+		if end < 0 {
+			return Descriptor{}, "", fmt.Errorf("unterminated method %q", name)
+		}
+		return Descriptor{Name: name, Suffix: SuffixMethod, Disambiguator: rest[1:end]}, rest[end+2:], nil
+	default:
+		return Descriptor{}, "", fmt.Errorf("descriptor %q has unknown suffix %q", name, suffix)
+	}
+}
+
+// parseName reads a simple identifier or a backtick-escaped one.
+func parseName(s string) (string, string, error) {
+	if strings.HasPrefix(s, "`") {
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '`' {
+				b.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '`' {
+				b.WriteByte('`')
+				i++
+				continue
+			}
+			return b.String(), s[i+1:], nil
+		}
+		return "", "", fmt.Errorf("unterminated escaped name")
+	}
+
+	i := 0
+	for i < len(s) && isIdentChar(s[i]) {
+		i++
+	}
+	if i == 0 {
+		return "", "", fmt.Errorf("expected a name at %q", s)
+	}
+	return s[:i], s[i:], nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '+' || c == '-' || c == '$' || c >= 0x80 ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -80,13 +80,13 @@ type SemanticSummary struct {
 
 // Analyzer performs semantic diff analysis.
 type Analyzer struct {
-	store       *store.Store
+	store       store.Store
 	projectRoot string
 	cfg         *config.Config
 }
 
 // NewAnalyzer creates a new semantic diff analyzer.
-func NewAnalyzer(s *store.Store, projectRoot string, cfg *config.Config) *Analyzer {
+func NewAnalyzer(s store.Store, projectRoot string, cfg *config.Config) *Analyzer {
 	return &Analyzer{
 		store:       s,
 		projectRoot: projectRoot,
@@ -0,0 +1,292 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Levels of co-change edges
+const (
+	CoChangeFile   = "file"   // from_id and to_id are file paths
+	CoChangeEntity = "entity" // from_id and to_id are entity IDs
+)
+
+// coChangesTable records which files and entities historically change
+// together, mined from git history and scan history
+const coChangesTable = `CREATE TABLE IF NOT EXISTS co_changes (
+    level VARCHAR(10) NOT NULL,
+    from_id VARCHAR(255) NOT NULL,
+    to_id VARCHAR(255) NOT NULL,
+    support INT NOT NULL,
+    confidence DOUBLE NOT NULL,
+    computed_at VARCHAR(40) NOT NULL,
+    PRIMARY KEY (level, from_id, to_id)
+)`
+
+// maxCoChangeKey is the longest file path or entity ID a co-change edge can
+// hold
+const maxCoChangeKey = 255
+
+// CoChange is a weighted co_changes edge: changes to From came with changes
+// to To in Confidence of From's commits (file level) or scans (entity level).
+type CoChange struct {
+	Level      string
+	From       string
+	To         string
+	Support    int // change sets containing both
+	Confidence float64
+}
+
+// SaveCoChanges replaces the co-change edges of a level.
+// Edges whose endpoints do not fit the table are skipped.
+func (s *SQLStore) SaveCoChanges(level string, edges []CoChange) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM co_changes WHERE level = ?`, level); err != nil {
+		return fmt.Errorf("clear co-changes: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO co_changes (level, from_id, to_id, support, confidence, computed_at)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, e := range edges {
+		if len(e.From) > maxCoChangeKey || len(e.To) > maxCoChangeKey {
+			continue
+		}
+		if _, err := stmt.Exec(level, e.From, e.To, e.Support, e.Confidence, now); err != nil {
+			return fmt.Errorf("insert co-change: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetCoChanges returns the co-change edges of a level leaving any of ids
+// with at least minConfidence, strongest first.
+func (s *SQLStore) GetCoChanges(level string, ids []string, minConfidence float64) ([]CoChange, error) {
+	var result []CoChange
+	for start := 0; start < len(ids); start += coChangeBatch {
+		end := start + coChangeBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch, err := s.getCoChanges(level, ids[start:end], minConfidence)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, batch...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence > result[j].Confidence
+		}
+		return result[i].Support > result[j].Support
+	})
+	return result, nil
+}
+
+// coChangeBatch caps the IDs passed to one co-change query
+const coChangeBatch = 500
+
+// getCoChanges queries the co-change edges leaving one batch of ids
+func (s *SQLStore) getCoChanges(level string, ids []string, minConfidence float64) ([]CoChange, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []interface{}{level, minConfidence}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	rows, err := s.db.Query(`SELECT from_id, to_id, support, confidence FROM co_changes
+		WHERE level = ? AND confidence >= ? AND from_id IN (`+placeholders+`)
+		ORDER BY confidence DESC, support DESC, from_id, to_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query co-changes: %w", err)
+	}
+	defer rows.Close()
+
+	var result []CoChange
+	for rows.Next() {
+		c := CoChange{Level: level}
+		if err := rows.Scan(&c.From, &c.To, &c.Support, &c.Confidence); err != nil {
+			return nil, fmt.Errorf("scan co-change: %w", err)
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// entityChangeSetsTable records the entities each scan added or changed,
+// one row per entity plus a row with an empty entity_id marking the scan,
+// so entity co-changes are mined without re-reading history
+const entityChangeSetsTable = `CREATE TABLE IF NOT EXISTS entity_change_sets (
+    scan_id INT NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (scan_id, entity_id)
+)`
+
+// RecordEntityChangeSet stores the entities the running scan added or whose
+// signature or body changed, comparing the working tables with the last
+// commit, and keeps the change sets of the newest keep scans. The first
+// call mines the sets of the keep-1 earlier scans from history.
+func (s *SQLStore) RecordEntityChangeSet(scanID, keep int) error {
+	var recorded int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM entity_change_sets`).Scan(&recorded); err != nil {
+		return fmt.Errorf("count entity change sets: %w", err)
+	}
+
+	log, err := s.DoltLog(1)
+	if err != nil {
+		return err
+	}
+	if recorded == 0 && keep > 1 {
+		log, err = s.DoltLog(keep)
+		if err != nil {
+			return err
+		}
+	}
+	refs := []string{"WORKING"}
+	for _, entry := range log {
+		refs = append(refs, entry.CommitHash)
+	}
+	sets, err := s.mineEntityChangeSets(refs)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(s.insertIgnore() + ` INTO entity_change_sets (scan_id, entity_id) VALUES (?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+	if _, err := tx.Exec(`DELETE FROM entity_change_sets WHERE scan_id = ?`, scanID); err != nil {
+		return fmt.Errorf("clear entity change set: %w", err)
+	}
+	if _, err := stmt.Exec(scanID, ""); err != nil {
+		return fmt.Errorf("insert entity change set: %w", err)
+	}
+	// Mined sets go under the IDs before this scan's, newest first
+	for i, set := range sets {
+		for _, id := range set {
+			if len(id) > maxCoChangeKey {
+				continue
+			}
+			if _, err := stmt.Exec(scanID-i, id); err != nil {
+				return fmt.Errorf("insert entity change set: %w", err)
+			}
+		}
+	}
+
+	var oldest sql.NullInt64
+	err = tx.QueryRow(`SELECT scan_id FROM (SELECT DISTINCT scan_id FROM entity_change_sets) AS scans
+		ORDER BY scan_id DESC LIMIT 1 OFFSET ?`, keep-1).Scan(&oldest)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("find oldest entity change set: %w", err)
+	}
+	if oldest.Valid {
+		if _, err := tx.Exec(`DELETE FROM entity_change_sets WHERE scan_id < ?`, oldest.Int64); err != nil {
+			return fmt.Errorf("prune entity change sets: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// EntityChangeSets returns the entity IDs recorded by RecordEntityChangeSet
+// for each of the last limit scans that changed any, newest first.
+func (s *SQLStore) EntityChangeSets(limit int) ([][]string, error) {
+	rows, err := s.db.Query(`SELECT scan_id, entity_id FROM entity_change_sets
+		WHERE entity_id <> '' ORDER BY scan_id DESC, entity_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query entity change sets: %w", err)
+	}
+	defer rows.Close()
+
+	var sets [][]string
+	last := 0
+	for rows.Next() {
+		var scanID int
+		var id string
+		if err := rows.Scan(&scanID, &id); err != nil {
+			return nil, fmt.Errorf("scan entity change set: %w", err)
+		}
+		if len(sets) == 0 || scanID != last {
+			if len(sets) == limit {
+				break
+			}
+			sets = append(sets, nil)
+			last = scanID
+		}
+		sets[len(sets)-1] = append(sets[len(sets)-1], id)
+	}
+	return sets, rows.Err()
+}
+
+// mineEntityChangeSets diffs the active entities between consecutive refs,
+// newest first, and returns the IDs changed at each step that changed any.
+// Mining stops at the first ref whose entities cannot be read.
+func (s *SQLStore) mineEntityChangeSets(refs []string) ([][]string, error) {
+	var sets [][]string
+	newer, err := s.entityHashesAt(refs[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs[1:] {
+		older, err := s.entityHashesAt(ref)
+		if err != nil {
+			// History older than the entities table has nothing to compare
+			break
+		}
+		var changed []string
+		for id, hash := range newer {
+			if prev, ok := older[id]; !ok || prev != hash {
+				changed = append(changed, id)
+			}
+		}
+		sort.Strings(changed)
+		sets = append(sets, changed)
+		newer = older
+	}
+	return sets, nil
+}
+
+// entityHashesAt returns the signature and body hash of every active entity
+// at ref, keyed by entity ID
+func (s *SQLStore) entityHashesAt(ref string) (map[string]string, error) {
+	from := "entities"
+	if ref != "WORKING" {
+		var err error
+		if from, err = s.tableAsOf("entities", ref); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT id, sig_hash, body_hash FROM %s
+		WHERE status = 'active' OR status IS NULL`, from))
+	if err != nil {
+		return nil, fmt.Errorf("query entities at %s: %w", ref, err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var id string
+		var sigHash, bodyHash sql.NullString
+		if err := rows.Scan(&id, &sigHash, &bodyHash); err != nil {
+			return nil, fmt.Errorf("scan entity row: %w", err)
+		}
+		result[id] = sigHash.String + ":" + bodyHash.String
+	}
+	return result, rows.Err()
+}
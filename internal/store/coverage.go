@@ -0,0 +1,157 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SaveCoverage stores coverage data for an entity, replacing any existing row.
+func (s *SQLStore) SaveCoverage(cov *EntityCoverage) error {
+	coveredJSON, err := json.Marshal(cov.CoveredLines)
+	if err != nil {
+		return fmt.Errorf("marshal covered lines for %s: %w", cov.EntityID, err)
+	}
+	uncoveredJSON, err := json.Marshal(cov.UncoveredLines)
+	if err != nil {
+		return fmt.Errorf("marshal uncovered lines for %s: %w", cov.EntityID, err)
+	}
+
+	_, err = s.db.Exec(`
+		REPLACE INTO entity_coverage (
+			entity_id, coverage_percent, covered_lines, uncovered_lines, last_run
+		) VALUES (?, ?, ?, ?, ?)`,
+		cov.EntityID, cov.CoveragePercent, string(coveredJSON), string(uncoveredJSON),
+		cov.LastRun.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("store coverage for %s: %w", cov.EntityID, err)
+	}
+	return nil
+}
+
+// GetCoverage retrieves coverage data for a specific entity.
+// Returns sql.ErrNoRows if the entity has no coverage data.
+func (s *SQLStore) GetCoverage(entityID string) (*EntityCoverage, error) {
+	row := s.db.QueryRow(`
+		SELECT entity_id, coverage_percent, covered_lines, uncovered_lines, last_run
+		FROM entity_coverage
+		WHERE entity_id = ?`, entityID)
+
+	cov, err := scanCoverage(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("get coverage for %s: %w", entityID, err)
+	}
+	return cov, nil
+}
+
+// GetAllCoverage retrieves coverage data for every entity that has it.
+func (s *SQLStore) GetAllCoverage() ([]*EntityCoverage, error) {
+	rows, err := s.db.Query(`
+		SELECT entity_id, coverage_percent, covered_lines, uncovered_lines, last_run
+		FROM entity_coverage
+		ORDER BY entity_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query entity_coverage: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*EntityCoverage
+	for rows.Next() {
+		cov, err := scanCoverage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan coverage row: %w", err)
+		}
+		results = append(results, cov)
+	}
+	return results, rows.Err()
+}
+
+// scanCoverage scans one entity_coverage row, decoding the JSON line arrays.
+func scanCoverage(row interface{ Scan(...interface{}) error }) (*EntityCoverage, error) {
+	var cov EntityCoverage
+	var coveredJSON, uncoveredJSON, lastRunStr sql.NullString
+
+	if err := row.Scan(&cov.EntityID, &cov.CoveragePercent, &coveredJSON, &uncoveredJSON, &lastRunStr); err != nil {
+		return nil, err
+	}
+
+	if coveredJSON.Valid && coveredJSON.String != "" {
+		if err := json.Unmarshal([]byte(coveredJSON.String), &cov.CoveredLines); err != nil {
+			return nil, fmt.Errorf("unmarshal covered lines: %w", err)
+		}
+	}
+	if uncoveredJSON.Valid && uncoveredJSON.String != "" {
+		if err := json.Unmarshal([]byte(uncoveredJSON.String), &cov.UncoveredLines); err != nil {
+			return nil, fmt.Errorf("unmarshal uncovered lines: %w", err)
+		}
+	}
+	if lastRunStr.Valid && lastRunStr.String != "" {
+		lastRun, err := time.Parse(time.RFC3339, lastRunStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse last_run: %w", err)
+		}
+		cov.LastRun = lastRun
+	}
+
+	return &cov, nil
+}
+
+// AddTestMapping records that a test covers an entity.
+// Existing mappings are left untouched.
+func (s *SQLStore) AddTestMapping(testFile, testName, entityID string) error {
+	_, err := s.db.Exec(s.insertIgnore()+` INTO test_entity_map (test_file, test_name, entity_id)
+		VALUES (?, ?, ?)`, testFile, testName, entityID)
+	if err != nil {
+		return fmt.Errorf("insert test mapping for %s -> %s: %w", testName, entityID, err)
+	}
+	return nil
+}
+
+// GetTestMappings returns test to entity mappings matching the filter,
+// ordered by test file, test name and entity ID.
+func (s *SQLStore) GetTestMappings(filter TestMappingFilter) ([]*TestMapping, error) {
+	query := `SELECT test_file, test_name, entity_id FROM test_entity_map WHERE 1=1`
+	var args []interface{}
+
+	if filter.EntityID != "" {
+		query += ` AND entity_id = ?`
+		args = append(args, filter.EntityID)
+	}
+	if filter.TestName != "" {
+		query += ` AND test_name = ?`
+		args = append(args, filter.TestName)
+	}
+	if filter.TestFile != "" {
+		query += ` AND test_file = ?`
+		args = append(args, filter.TestFile)
+	}
+	query += ` ORDER BY test_file, test_name, entity_id`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query test_entity_map: %w", err)
+	}
+	defer rows.Close()
+
+	var mappings []*TestMapping
+	for rows.Next() {
+		var m TestMapping
+		if err := rows.Scan(&m.TestFile, &m.TestName, &m.EntityID); err != nil {
+			return nil, fmt.Errorf("scan test mapping: %w", err)
+		}
+		mappings = append(mappings, &m)
+	}
+	return mappings, rows.Err()
+}
+
+// ClearTestMappings removes all test to entity mappings.
+func (s *SQLStore) ClearTestMappings() error {
+	if _, err := s.db.Exec(`DELETE FROM test_entity_map`); err != nil {
+		return fmt.Errorf("clear test_entity_map: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// cycleHistoryTable records the dependency cycles found by each scan, one
+// row per cycle, so later scans can tell whether a cycle grew.
+const cycleHistoryTable = `CREATE TABLE IF NOT EXISTS cycle_history (
+    scan_id INT NOT NULL,
+    level VARCHAR(16) NOT NULL,
+    anchor VARCHAR(500) NOT NULL,
+    size INT NOT NULL,
+    members TEXT NOT NULL,
+    PRIMARY KEY (scan_id, level, anchor)
+)`
+
+// SaveCycles replaces the cycles recorded for a scan at one level (entity,
+// file or package). Each cycle is a sorted list of members; its first
+// member identifies it.
+func (s *SQLStore) SaveCycles(scanID int, level string, cycles [][]string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM cycle_history WHERE scan_id = ? AND level = ?`, scanID, level); err != nil {
+		return fmt.Errorf("clear cycles: %w", err)
+	}
+	for _, members := range cycles {
+		if len(members) == 0 {
+			continue
+		}
+		data, err := json.Marshal(members)
+		if err != nil {
+			return fmt.Errorf("marshal cycle: %w", err)
+		}
+		_, err = tx.Exec(`INSERT INTO cycle_history (scan_id, level, anchor, size, members) VALUES (?, ?, ?, ?, ?)`,
+			scanID, level, members[0], len(members), string(data))
+		if err != nil {
+			return fmt.Errorf("insert cycle: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetCycles returns the cycles recorded for a scan at one level.
+func (s *SQLStore) GetCycles(scanID int, level string) ([][]string, error) {
+	rows, err := s.db.Query(`SELECT members FROM cycle_history WHERE scan_id = ? AND level = ? ORDER BY anchor`, scanID, level)
+	if err != nil {
+		return nil, fmt.Errorf("query cycles: %w", err)
+	}
+	defer rows.Close()
+
+	var cycles [][]string
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("scan cycle: %w", err)
+		}
+		var members []string
+		if err := json.Unmarshal([]byte(data), &members); err != nil {
+			return nil, fmt.Errorf("decode cycle: %w", err)
+		}
+		cycles = append(cycles, members)
+	}
+	return cycles, rows.Err()
+}
+
+// PreviousScanID returns the ID of the last scan before scanID, or 0 if
+// there is none.
+func (s *SQLStore) PreviousScanID(scanID int) (int, error) {
+	var id sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(id) FROM scan_metadata WHERE id < ?`, scanID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("query previous scan: %w", err)
+	}
+	return int(id.Int64), nil
+}
@@ -1,29 +1,112 @@
 // Package store provides Dolt-backed persistence for cortex state and metadata.
 // The store is located at .cx/cortex/ (a Dolt repository) and provides efficient
 // storage with version control capabilities including history, diff, and time-travel.
+//
+// A lightweight SQLite backend (.cx/cortex.db) can be selected with
+// storage.backend: sqlite in .cx/config.yaml. It supports every query the Dolt
+// backend does; history features (log, diff, AS OF, blame) are served from
+// per-scan snapshots instead of Dolt commits.
 package store
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/anthropics/cx/internal/config"
 	_ "github.com/dolthub/driver"
 )
 
-// Store manages the .cx/cortex/ Dolt database for storing application state
+// Storage backends supported by the store.
+const (
+	BackendDolt   = "dolt"
+	BackendSQLite = "sqlite"
+)
+
+// SQLStore manages the .cx/cortex/ Dolt database for storing application state
 // and metadata with version control capabilities.
-type Store struct {
-	db     *sql.DB
-	dbPath string // Path to the Dolt repo directory (.cx/cortex/)
+type SQLStore struct {
+	db      *sql.DB
+	dbPath  string // Path to the Dolt repo directory (.cx/cortex/) or SQLite file (.cx/cortex.db)
+	cxDir   string // The .cx directory containing the database
+	backend string // BackendDolt or BackendSQLite
+	vectors vectorIndex
 }
 
 // Open opens or creates the store database at the specified .cx directory.
-// It auto-creates the directory if it doesn't exist and initializes the schema
-// if the database is new. The Dolt database is stored in .cx/cortex/.
-func Open(cxDir string) (*Store, error) {
+// It auto-creates the directory if it doesn't exist and applies any pending
+// schema migrations. The backend is read from storage.backend in
+// .cx/config.yaml; the Dolt database is stored in .cx/cortex/.
+func Open(cxDir string) (*SQLStore, error) {
+	return OpenWithOptions(cxDir, OpenOptions{})
+}
+
+// OpenBackend opens or creates the store at the specified .cx directory using
+// the given storage backend ("dolt" or "sqlite").
+func OpenBackend(cxDir, backend string) (*SQLStore, error) {
+	return OpenWithOptions(cxDir, OpenOptions{Backend: backend})
+}
+
+// OpenOptions controls how OpenWithOptions opens the store.
+type OpenOptions struct {
+	// Backend is the storage backend ("dolt" or "sqlite"). Empty reads
+	// storage.backend from .cx/config.yaml.
+	Backend string
+
+	// SkipMigrations opens the database without applying pending schema
+	// migrations, for inspecting them (cx admin db migrate --status/--dry-run).
+	// Databases from a newer cx are still rejected.
+	SkipMigrations bool
+}
+
+// OpenWithOptions opens or creates the store at the specified .cx directory.
+// It fails with ErrSchemaTooNew if the database was written by a newer cx.
+func OpenWithOptions(cxDir string, opts OpenOptions) (*SQLStore, error) {
+	if opts.Backend == "" {
+		opts.Backend = configuredBackend(cxDir)
+	}
+
+	var s *SQLStore
+	var err error
+	switch opts.Backend {
+	case BackendDolt, "":
+		s, err = openDolt(cxDir)
+	case BackendSQLite:
+		s, err = openSQLite(cxDir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (valid: %v)", opts.Backend, config.ValidStorageBackends)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.SkipMigrations {
+		_, err = s.PendingMigrations()
+	} else {
+		_, err = s.Migrate()
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// configuredBackend returns the storage backend configured in cxDir/config.yaml.
+// Falls back to Dolt when there is no config file or it cannot be parsed.
+func configuredBackend(cxDir string) string {
+	cfg, err := config.LoadFromPath(filepath.Join(cxDir, config.ConfigFileName))
+	if err != nil {
+		return BackendDolt
+	}
+	return cfg.Storage.Backend
+}
+
+// openDolt opens or creates the embedded Dolt database in cxDir/cortex/.
+func openDolt(cxDir string) (*SQLStore, error) {
 	// Create .cx directory if it doesn't exist
 	if err := os.MkdirAll(cxDir, 0755); err != nil {
 		return nil, fmt.Errorf("create .cx directory: %w", err)
@@ -59,19 +142,11 @@ func Open(cxDir string) (*Store, error) {
 		return nil, fmt.Errorf("open dolt db: %w", err)
 	}
 
-	store := &Store{db: db, dbPath: dbPath}
-
-	// Initialize schema
-	if err := store.initSchema(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("init schema: %w", err)
-	}
-
-	return store, nil
+	return &SQLStore{db: db, dbPath: dbPath, cxDir: cxDir, backend: BackendDolt}, nil
 }
 
 // OpenDefault opens the store in the default .cx directory in the current working directory.
-func OpenDefault() (*Store, error) {
+func OpenDefault() (*SQLStore, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("get working directory: %w", err)
@@ -81,27 +156,45 @@ func OpenDefault() (*Store, error) {
 	return Open(cxDir)
 }
 
-// Close closes the database connection.
-func (s *Store) Close() error {
+// Close saves any unsaved changes to the embedding index and closes the
+// database connection.
+func (s *SQLStore) Close() error {
 	if s.db == nil {
 		return nil
 	}
-	return s.db.Close()
+	return errors.Join(s.saveEmbeddingIndex(), s.db.Close())
 }
 
 // DB returns the underlying database connection for advanced operations.
-func (s *Store) DB() *sql.DB {
+func (s *SQLStore) DB() *sql.DB {
 	return s.db
 }
 
 // Path returns the database file path.
-func (s *Store) Path() string {
+func (s *SQLStore) Path() string {
 	return s.dbPath
 }
 
+// Backend returns the storage backend in use ("dolt" or "sqlite").
+func (s *SQLStore) Backend() string {
+	return s.backend
+}
+
+// SupportsVersionControl reports whether the backend provides Dolt version
+// control (branches, reset, working-set status). History queries work on
+// every backend; SQLite serves them from scan snapshots.
+func (s *SQLStore) SupportsVersionControl() bool {
+	return s.backend == BackendDolt
+}
+
 // DoltCommit creates a Dolt commit with the given message.
 // Returns the commit hash on success.
-func (s *Store) DoltCommit(message string) (string, error) {
+// With the SQLite backend this records a scan snapshot instead.
+func (s *SQLStore) DoltCommit(message string) (string, error) {
+	if s.backend == BackendSQLite {
+		return s.createSnapshot(message)
+	}
+
 	// Stage all changes and commit
 	_, err := s.db.Exec("CALL dolt_commit('-Am', ?)", message)
 	if err != nil {
@@ -121,7 +214,10 @@ func (s *Store) DoltCommit(message string) (string, error) {
 
 // DoltTag creates a Dolt tag at HEAD with the given name and optional message.
 // Tags can be used as refs for time-travel queries (--at, --since, --from).
-func (s *Store) DoltTag(name string, message string) error {
+func (s *SQLStore) DoltTag(name string, message string) error {
+	if s.backend == BackendSQLite {
+		return s.tagSnapshot(name, message)
+	}
 	if message != "" {
 		_, err := s.db.Exec("CALL dolt_tag('-m', ?, ?)", message, name)
 		if err != nil {
@@ -137,8 +233,12 @@ func (s *Store) DoltTag(name string, message string) error {
 }
 
 // DoltListTags returns all tags in the repository.
-func (s *Store) DoltListTags() ([]string, error) {
-	rows, err := s.db.Query("SELECT tag_name FROM dolt_tags ORDER BY tag_name")
+func (s *SQLStore) DoltListTags() ([]string, error) {
+	query := "SELECT tag_name FROM dolt_tags ORDER BY tag_name"
+	if s.backend == BackendSQLite {
+		query = "SELECT tag_name FROM snapshot_tags ORDER BY tag_name"
+	}
+	rows, err := s.db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("list tags: %w", err)
 	}
@@ -167,19 +267,26 @@ type ScanMetadata struct {
 	DurationMs        int
 }
 
-// SaveScanMetadata records scan metadata in the scan_metadata table.
-func (s *Store) SaveScanMetadata(meta *ScanMetadata) error {
-	_, err := s.db.Exec(`
+// SaveScanMetadata records scan metadata in the scan_metadata table and
+// sets meta.ID to the new row's ID.
+func (s *SQLStore) SaveScanMetadata(meta *ScanMetadata) error {
+	res, err := s.db.Exec(`
 		INSERT INTO scan_metadata
 			(git_commit, git_branch, files_scanned, entities_found, dependencies_found, scan_duration_ms)
 		VALUES (?, ?, ?, ?, ?, ?)`,
 		meta.GitCommit, meta.GitBranch, meta.FilesScanned, meta.EntitiesFound,
 		meta.DependenciesFound, meta.DurationMs)
-	return err
+	if err != nil {
+		return err
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		meta.ID = int(id)
+	}
+	return nil
 }
 
 // GetLatestScanMetadata returns the most recent scan metadata, or nil if no scans exist.
-func (s *Store) GetLatestScanMetadata() (*ScanMetadata, error) {
+func (s *SQLStore) GetLatestScanMetadata() (*ScanMetadata, error) {
 	row := s.db.QueryRow(`
 		SELECT id, scan_time, git_commit, git_branch, files_scanned, entities_found, dependencies_found, scan_duration_ms
 		FROM scan_metadata
@@ -5,20 +5,29 @@ import (
 	"time"
 )
 
-// CreateDependency inserts a single dependency.
-// Uses REPLACE INTO to handle duplicates gracefully.
-func (s *Store) CreateDependency(d *Dependency) error {
+// CreateDependency inserts a single dependency, or updates it if it exists.
+// An edge SCIP confirmed keeps its source when tree-sitter finds it again.
+func (s *SQLStore) CreateDependency(d *Dependency) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := s.db.Exec(`
-		REPLACE INTO dependencies (from_id, to_id, dep_type, created_at)
-		VALUES (?, ?, ?, ?)`,
-		d.FromID, d.ToID, d.DepType, now)
+	_, err := s.db.Exec(s.upsertDependency(),
+		d.FromID, d.ToID, d.DepType, d.Optional, depSource(d), now)
 	return err
 }
 
-// CreateDependenciesBulk inserts multiple dependencies in a single transaction.
-// Uses prepared statement for efficiency.
-func (s *Store) CreateDependenciesBulk(deps []*Dependency) error {
+// upsertDependency returns the statement CreateDependency and
+// CreateDependenciesBulk save a dependency with. The source is never
+// downgraded from scip: a plain scan re-finds the edges a --scip scan
+// confirmed, and their provenance must survive it.
+func (s *SQLStore) upsertDependency() string {
+	return `INSERT INTO dependencies (from_id, to_id, dep_type, optional, source, created_at)
+		VALUES (?, ?, ?, ?, ?, ?) ` +
+		s.onConflictUpdate([]string{"from_id", "to_id", "dep_type"}, []string{"optional", "created_at"}) +
+		`, source = CASE WHEN dependencies.source = '` + DepSourceSCIP + `' THEN dependencies.source ELSE ` + s.inserted("source") + ` END`
+}
+
+// CreateDependenciesBulk inserts or updates multiple dependencies in a single
+// transaction, like CreateDependency. Uses prepared statement for efficiency.
+func (s *SQLStore) CreateDependenciesBulk(deps []*Dependency) error {
 	if len(deps) == 0 {
 		return nil
 	}
@@ -29,9 +38,7 @@ func (s *Store) CreateDependenciesBulk(deps []*Dependency) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-		REPLACE INTO dependencies (from_id, to_id, dep_type, created_at)
-		VALUES (?, ?, ?, ?)`)
+	stmt, err := tx.Prepare(s.upsertDependency())
 	if err != nil {
 		return err
 	}
@@ -39,7 +46,7 @@ func (s *Store) CreateDependenciesBulk(deps []*Dependency) error {
 
 	now := time.Now().UTC().Format(time.RFC3339)
 	for _, d := range deps {
-		_, err := stmt.Exec(d.FromID, d.ToID, d.DepType, now)
+		_, err := stmt.Exec(d.FromID, d.ToID, d.DepType, d.Optional, depSource(d), now)
 		if err != nil {
 			return err
 		}
@@ -52,8 +59,9 @@ func (s *Store) CreateDependenciesBulk(deps []*Dependency) error {
 // If filter.FromID is set, returns dependencies FROM that entity.
 // If filter.ToID is set, returns dependencies TO that entity.
 // If filter.DepType is set, filters by dependency type.
-func (s *Store) GetDependencies(filter DependencyFilter) ([]*Dependency, error) {
-	query := `SELECT from_id, to_id, dep_type, created_at FROM dependencies WHERE 1=1`
+// If filter.Source is set, filters by provenance.
+func (s *SQLStore) GetDependencies(filter DependencyFilter) ([]*Dependency, error) {
+	query := `SELECT from_id, to_id, dep_type, optional, source, created_at FROM dependencies WHERE 1=1`
 	args := []interface{}{}
 
 	if filter.FromID != "" {
@@ -68,6 +76,10 @@ func (s *Store) GetDependencies(filter DependencyFilter) ([]*Dependency, error)
 		query += " AND dep_type = ?"
 		args = append(args, filter.DepType)
 	}
+	if filter.Source != "" {
+		query += " AND source = ?"
+		args = append(args, filter.Source)
+	}
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
@@ -79,7 +91,7 @@ func (s *Store) GetDependencies(filter DependencyFilter) ([]*Dependency, error)
 	for rows.Next() {
 		var d Dependency
 		var createdAt string
-		if err := rows.Scan(&d.FromID, &d.ToID, &d.DepType, &createdAt); err != nil {
+		if err := rows.Scan(&d.FromID, &d.ToID, &d.DepType, &d.Optional, &d.Source, &createdAt); err != nil {
 			return nil, err
 		}
 		d.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
@@ -90,17 +102,17 @@ func (s *Store) GetDependencies(filter DependencyFilter) ([]*Dependency, error)
 }
 
 // GetDependenciesFrom returns all dependencies from a specific entity.
-func (s *Store) GetDependenciesFrom(entityID string) ([]*Dependency, error) {
+func (s *SQLStore) GetDependenciesFrom(entityID string) ([]*Dependency, error) {
 	return s.GetDependencies(DependencyFilter{FromID: entityID})
 }
 
 // GetDependenciesTo returns all dependencies to a specific entity (callers/users).
-func (s *Store) GetDependenciesTo(entityID string) ([]*Dependency, error) {
+func (s *SQLStore) GetDependenciesTo(entityID string) ([]*Dependency, error) {
 	return s.GetDependencies(DependencyFilter{ToID: entityID})
 }
 
 // DeleteDependency removes a specific dependency.
-func (s *Store) DeleteDependency(fromID, toID, depType string) error {
+func (s *SQLStore) DeleteDependency(fromID, toID, depType string) error {
 	_, err := s.db.Exec(`
 		DELETE FROM dependencies
 		WHERE from_id = ? AND to_id = ? AND dep_type = ?`,
@@ -110,7 +122,7 @@ func (s *Store) DeleteDependency(fromID, toID, depType string) error {
 
 // DeleteDependenciesFrom removes all dependencies from an entity.
 // Used when rescanning - removes old call graph edges.
-func (s *Store) DeleteDependenciesFrom(entityID string) error {
+func (s *SQLStore) DeleteDependenciesFrom(entityID string) error {
 	_, err := s.db.Exec(`
 		DELETE FROM dependencies
 		WHERE from_id = ?`,
@@ -120,7 +132,7 @@ func (s *Store) DeleteDependenciesFrom(entityID string) error {
 
 // DeleteDependenciesByFile removes all dependencies where from_id matches an entity in the file.
 // Used when rescanning a file to clean up old edges.
-func (s *Store) DeleteDependenciesByFile(filePath string) error {
+func (s *SQLStore) DeleteDependenciesByFile(filePath string) error {
 	_, err := s.db.Exec(`
 		DELETE FROM dependencies
 		WHERE from_id IN (SELECT id FROM entities WHERE file_path = ?)`,
@@ -130,12 +142,12 @@ func (s *Store) DeleteDependenciesByFile(filePath string) error {
 
 // GetAllDependencies returns all dependencies in the database.
 // Used for building the full graph.
-func (s *Store) GetAllDependencies() ([]*Dependency, error) {
+func (s *SQLStore) GetAllDependencies() ([]*Dependency, error) {
 	return s.GetDependencies(DependencyFilter{})
 }
 
 // CountDependencies returns the total number of dependencies.
-func (s *Store) CountDependencies() (int, error) {
+func (s *SQLStore) CountDependencies() (int, error) {
 	var count int
 	err := s.db.QueryRow(`SELECT COUNT(*) FROM dependencies`).Scan(&count)
 	return count, err
@@ -144,18 +156,18 @@ func (s *Store) CountDependencies() (int, error) {
 // GetDependenciesAt returns dependencies matching the filter at a specific Dolt commit/ref.
 // Uses AS OF to query the table at a historical point.
 // Supports short commit hashes which are automatically resolved to full hashes.
-func (s *Store) GetDependenciesAt(filter DependencyFilter, ref string) ([]*Dependency, error) {
+func (s *SQLStore) GetDependenciesAt(filter DependencyFilter, ref string) ([]*Dependency, error) {
 	if !IsValidRef(ref) {
 		return nil, fmt.Errorf("invalid ref format: %s", ref)
 	}
 
-	// Resolve short commit hashes to full hashes
-	resolvedRef, err := s.ResolveRef(ref)
+	// Resolve the ref to a table reference at that point in history
+	from, err := s.tableAsOf("dependencies", ref)
 	if err != nil {
 		return nil, fmt.Errorf("resolve ref %s: %w", ref, err)
 	}
 
-	query := fmt.Sprintf(`SELECT from_id, to_id, dep_type, created_at FROM dependencies AS OF '%s' WHERE 1=1`, resolvedRef)
+	query := fmt.Sprintf(`SELECT from_id, to_id, dep_type, created_at FROM %s WHERE 1=1`, from)
 	args := []interface{}{}
 
 	if filter.FromID != "" {
@@ -192,11 +204,11 @@ func (s *Store) GetDependenciesAt(filter DependencyFilter, ref string) ([]*Depen
 }
 
 // GetDependenciesFromAt returns all dependencies from a specific entity at a commit/ref.
-func (s *Store) GetDependenciesFromAt(entityID, ref string) ([]*Dependency, error) {
+func (s *SQLStore) GetDependenciesFromAt(entityID, ref string) ([]*Dependency, error) {
 	return s.GetDependenciesAt(DependencyFilter{FromID: entityID}, ref)
 }
 
 // GetDependenciesToAt returns all dependencies to a specific entity at a commit/ref.
-func (s *Store) GetDependenciesToAt(entityID, ref string) ([]*Dependency, error) {
+func (s *SQLStore) GetDependenciesToAt(entityID, ref string) ([]*Dependency, error) {
 	return s.GetDependenciesAt(DependencyFilter{ToID: entityID}, ref)
 }
@@ -0,0 +1,40 @@
+package store
+
+import "strings"
+
+// insertIgnore returns the INSERT prefix that silently skips rows whose
+// primary key already exists.
+func (s *SQLStore) insertIgnore() string {
+	if s.backend == BackendSQLite {
+		return "INSERT OR IGNORE"
+	}
+	return "INSERT IGNORE"
+}
+
+// onConflictUpdate returns the upsert clause that updates updateCols when a
+// row with the same keyCols already exists.
+//
+// Dolt (MySQL):  ON DUPLICATE KEY UPDATE col = VALUES(col), ...
+// SQLite:        ON CONFLICT(key, ...) DO UPDATE SET col = excluded.col, ...
+func (s *SQLStore) onConflictUpdate(keyCols []string, updateCols []string) string {
+	sets := make([]string, len(updateCols))
+	if s.backend == BackendSQLite {
+		for i, col := range updateCols {
+			sets[i] = col + " = excluded." + col
+		}
+		return "ON CONFLICT(" + strings.Join(keyCols, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", ")
+	}
+	for i, col := range updateCols {
+		sets[i] = col + " = VALUES(" + col + ")"
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// inserted returns the expression for col's value in the row being inserted,
+// for use in an onConflictUpdate clause.
+func (s *SQLStore) inserted(col string) string {
+	if s.backend == BackendSQLite {
+		return "excluded." + col
+	}
+	return "VALUES(" + col + ")"
+}
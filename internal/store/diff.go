@@ -38,7 +38,7 @@ type DiffOptions struct {
 
 // DoltDiff queries the Dolt diff between two refs for a given table.
 // Returns categorized changes (added, modified, removed).
-func (s *Store) DoltDiff(opts DiffOptions) (*DiffResult, error) {
+func (s *SQLStore) DoltDiff(opts DiffOptions) (*DiffResult, error) {
 	// Set defaults
 	if opts.FromRef == "" {
 		opts.FromRef = "HEAD~1"
@@ -63,6 +63,10 @@ func (s *Store) DoltDiff(opts DiffOptions) (*DiffResult, error) {
 		return nil, fmt.Errorf("invalid ref format")
 	}
 
+	if s.backend == BackendSQLite {
+		return s.snapshotDiff(opts, result)
+	}
+
 	// Check if we have enough commit history for HEAD~N refs
 	if strings.HasPrefix(opts.FromRef, "HEAD~") {
 		count, err := s.commitCount()
@@ -186,7 +190,7 @@ func isValidRef(ref string) bool {
 }
 
 // DoltDiffSummary returns a quick summary of changes between two refs.
-func (s *Store) DoltDiffSummary(fromRef, toRef string) (added, modified, removed int, err error) {
+func (s *SQLStore) DoltDiffSummary(fromRef, toRef string) (added, modified, removed int, err error) {
 	if fromRef == "" {
 		fromRef = "HEAD~1"
 	}
@@ -199,6 +203,15 @@ func (s *Store) DoltDiffSummary(fromRef, toRef string) (added, modified, removed
 		return 0, 0, 0, fmt.Errorf("invalid ref format")
 	}
 
+	if s.backend == BackendSQLite {
+		diff, err := s.snapshotDiff(DiffOptions{FromRef: fromRef, ToRef: toRef, Table: "entities"},
+			&DiffResult{FromRef: fromRef, ToRef: toRef})
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return len(diff.Added), len(diff.Modified), len(diff.Removed), nil
+	}
+
 	// Check if we have enough commit history for HEAD~N refs
 	if strings.HasPrefix(fromRef, "HEAD~") {
 		count, err := s.commitCount()
@@ -250,9 +263,13 @@ func (s *Store) DoltDiffSummary(fromRef, toRef string) (added, modified, removed
 }
 
 // commitCount returns the number of commits in the Dolt log.
-func (s *Store) commitCount() (int, error) {
+func (s *SQLStore) commitCount() (int, error) {
+	query := "SELECT COUNT(*) FROM dolt_log"
+	if s.backend == BackendSQLite {
+		query = "SELECT COUNT(*) FROM scan_snapshots"
+	}
 	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM dolt_log").Scan(&count)
+	err := s.db.QueryRow(query).Scan(&count)
 	return count, err
 }
 
@@ -266,7 +283,7 @@ type DoltLogEntry struct {
 }
 
 // DoltLog returns recent Dolt commits.
-func (s *Store) DoltLog(limit int) ([]DoltLogEntry, error) {
+func (s *SQLStore) DoltLog(limit int) ([]DoltLogEntry, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -277,6 +294,14 @@ func (s *Store) DoltLog(limit int) ([]DoltLogEntry, error) {
 		ORDER BY date DESC
 		LIMIT ?
 	`
+	if s.backend == BackendSQLite {
+		query = `
+		SELECT commit_hash, committer, email, created_at, message
+		FROM scan_snapshots
+		ORDER BY id DESC
+		LIMIT ?
+	`
+	}
 
 	rows, err := s.db.Query(query, limit)
 	if err != nil {
@@ -306,13 +331,17 @@ type DoltLogStatsResult struct {
 // DoltLogStats returns entity and dependency counts at a specific commit.
 // Uses AS OF to query the tables at the given commit point.
 // Supports short commit hashes which are automatically resolved to full hashes.
-func (s *Store) DoltLogStats(commitHash string) (*DoltLogStatsResult, error) {
+func (s *SQLStore) DoltLogStats(commitHash string) (*DoltLogStatsResult, error) {
 	if !isValidRef(commitHash) {
 		return nil, fmt.Errorf("invalid commit hash")
 	}
 
-	// Resolve short commit hashes to full hashes
-	resolvedRef, err := s.ResolveRef(commitHash)
+	// Resolve short commit hashes to table references at that commit
+	entitiesAt, err := s.tableAsOf("entities", commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("resolve ref %s: %w", commitHash, err)
+	}
+	depsAt, err := s.tableAsOf("dependencies", commitHash)
 	if err != nil {
 		return nil, fmt.Errorf("resolve ref %s: %w", commitHash, err)
 	}
@@ -321,7 +350,7 @@ func (s *Store) DoltLogStats(commitHash string) (*DoltLogStatsResult, error) {
 
 	// Count entities at this commit using AS OF
 	// Note: AS OF requires the ref in the table reference, not as a function argument
-	entityQuery := fmt.Sprintf(`SELECT COUNT(*) FROM entities AS OF '%s'`, resolvedRef)
+	entityQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, entitiesAt)
 	err = s.db.QueryRow(entityQuery).Scan(&result.Entities)
 	if err != nil {
 		// Table might not exist at this commit
@@ -329,7 +358,7 @@ func (s *Store) DoltLogStats(commitHash string) (*DoltLogStatsResult, error) {
 	}
 
 	// Count dependencies at this commit
-	depQuery := fmt.Sprintf(`SELECT COUNT(*) FROM dependencies AS OF '%s'`, resolvedRef)
+	depQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, depsAt)
 	err = s.db.QueryRow(depQuery).Scan(&result.Dependencies)
 	if err != nil {
 		// Table might not exist at this commit
@@ -361,7 +390,7 @@ type EntityHistoryOptions struct {
 
 // EntityHistory returns the commit history for a specific entity.
 // Queries dolt_history_entities and computes change types between versions.
-func (s *Store) EntityHistory(opts EntityHistoryOptions) ([]EntityHistoryEntry, error) {
+func (s *SQLStore) EntityHistory(opts EntityHistoryOptions) ([]EntityHistoryEntry, error) {
 	if opts.EntityID == "" {
 		return nil, fmt.Errorf("entity ID required")
 	}
@@ -386,6 +415,25 @@ func (s *Store) EntityHistory(opts EntityHistoryOptions) ([]EntityHistoryEntry,
 		ORDER BY commit_date DESC
 		LIMIT ?
 	`
+	if s.backend == BackendSQLite {
+		query = `
+		SELECT
+			ss.commit_hash,
+			ss.created_at,
+			ss.committer,
+			se.file_path,
+			se.line_start,
+			se.line_end,
+			se.signature,
+			se.sig_hash,
+			se.body_hash
+		FROM snapshot_entities se
+		JOIN scan_snapshots ss ON ss.id = se.snapshot_id
+		WHERE se.id = ?
+		ORDER BY ss.id DESC
+		LIMIT ?
+	`
+	}
 
 	rows, err := s.db.Query(query, opts.EntityID, opts.Limit)
 	if err != nil {
@@ -489,7 +537,7 @@ type DependencyHistoryOptions struct {
 
 // DependencyHistory returns the commit history for dependencies involving an entity.
 // Queries dolt_history_dependencies for both outgoing and incoming edges.
-func (s *Store) DependencyHistory(opts DependencyHistoryOptions) ([]DependencyHistoryEntry, error) {
+func (s *SQLStore) DependencyHistory(opts DependencyHistoryOptions) ([]DependencyHistoryEntry, error) {
 	if opts.EntityID == "" {
 		return nil, fmt.Errorf("entity ID required")
 	}
@@ -511,6 +559,22 @@ func (s *Store) DependencyHistory(opts DependencyHistoryOptions) ([]DependencyHi
 		ORDER BY commit_date DESC
 		LIMIT ?
 	`
+	if s.backend == BackendSQLite {
+		query = `
+		SELECT
+			ss.commit_hash,
+			ss.created_at,
+			ss.committer,
+			sd.from_id,
+			sd.to_id,
+			sd.dep_type
+		FROM snapshot_dependencies sd
+		JOIN scan_snapshots ss ON ss.id = sd.snapshot_id
+		WHERE sd.from_id = ? OR sd.to_id = ?
+		ORDER BY ss.id DESC
+		LIMIT ?
+	`
+	}
 
 	rows, err := s.db.Query(query, opts.EntityID, opts.EntityID, opts.Limit)
 	if err != nil {
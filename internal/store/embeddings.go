@@ -1,10 +1,8 @@
 package store
 
 import (
-	"encoding/json"
 	"fmt"
 	"math"
-	"sort"
 	"time"
 )
 
@@ -50,43 +48,41 @@ type SimilarityResult struct {
 	Similarity float64 `json:"similarity"`
 }
 
-// SaveEmbedding stores an embedding for an entity using INSERT ... ON DUPLICATE KEY UPDATE.
-func (s *Store) SaveEmbedding(entityID string, embedding []float32, modelVersion, contentHash string) error {
-	embJSON, err := json.Marshal(embedding)
+// SaveEmbedding stores an embedding for an entity, replacing any existing one (upsert),
+// and updates the embedding index if it is loaded.
+func (s *SQLStore) SaveEmbedding(entityID string, embedding []float32, modelVersion, contentHash string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO entity_embeddings (entity_id, embedding, model_version, content_hash, created_at)
+		VALUES (?, ?, ?, ?, ?) `+
+		s.onConflictUpdate([]string{"entity_id"},
+			[]string{"embedding", "model_version", "content_hash", "created_at"}),
+		entityID, encodeVector(embedding), modelVersion, contentHash, time.Now().Format(time.RFC3339))
 	if err != nil {
-		return fmt.Errorf("marshal embedding: %w", err)
+		return err
 	}
-	_, err = s.db.Exec(`
-		INSERT INTO entity_embeddings (entity_id, embedding, model_version, content_hash, created_at)
-		VALUES (?, ?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE
-			embedding = VALUES(embedding),
-			model_version = VALUES(model_version),
-			content_hash = VALUES(content_hash),
-			created_at = VALUES(created_at)
-	`, entityID, string(embJSON), modelVersion, contentHash, time.Now().Format(time.RFC3339))
-	return err
+	s.indexEmbedding(entityID, embedding, embeddingTag(modelVersion, contentHash))
+	return nil
 }
 
-// GetEmbedding retrieves an embedding for an entity by querying the database and unmarshaling JSON.
-func (s *Store) GetEmbedding(entityID string) (*EntityEmbedding, error) {
+// GetEmbedding retrieves an embedding for an entity.
+func (s *SQLStore) GetEmbedding(entityID string) (*EntityEmbedding, error) {
 	var e EntityEmbedding
-	var embJSON string
+	var data []byte
 	err := s.db.QueryRow(`
 		SELECT entity_id, embedding, model_version, content_hash, created_at
 		FROM entity_embeddings WHERE entity_id = ?
-	`, entityID).Scan(&e.EntityID, &embJSON, &e.ModelVersion, &e.ContentHash, &e.CreatedAt)
+	`, entityID).Scan(&e.EntityID, &data, &e.ModelVersion, &e.ContentHash, &e.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
-	if err := json.Unmarshal([]byte(embJSON), &e.Embedding); err != nil {
-		return nil, fmt.Errorf("unmarshal embedding: %w", err)
+	if e.Embedding, err = decodeVector(data); err != nil {
+		return nil, fmt.Errorf("decode embedding: %w", err)
 	}
 	return &e, nil
 }
 
-// GetAllEmbeddings retrieves all embeddings for similarity search with batch unmarshaling.
-func (s *Store) GetAllEmbeddings() ([]*EntityEmbedding, error) {
+// GetAllEmbeddings retrieves all embeddings.
+func (s *SQLStore) GetAllEmbeddings() ([]*EntityEmbedding, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, embedding, model_version, content_hash, created_at
 		FROM entity_embeddings
@@ -99,46 +95,55 @@ func (s *Store) GetAllEmbeddings() ([]*EntityEmbedding, error) {
 	var results []*EntityEmbedding
 	for rows.Next() {
 		var e EntityEmbedding
-		var embJSON string
-		if err := rows.Scan(&e.EntityID, &embJSON, &e.ModelVersion, &e.ContentHash, &e.CreatedAt); err != nil {
+		var data []byte
+		if err := rows.Scan(&e.EntityID, &data, &e.ModelVersion, &e.ContentHash, &e.CreatedAt); err != nil {
 			return nil, err
 		}
-		if err := json.Unmarshal([]byte(embJSON), &e.Embedding); err != nil {
-			return nil, fmt.Errorf("unmarshal embedding for %s: %w", e.EntityID, err)
+		if e.Embedding, err = decodeVector(data); err != nil {
+			return nil, fmt.Errorf("decode embedding for %s: %w", e.EntityID, err)
 		}
 		results = append(results, &e)
 	}
 	return results, rows.Err()
 }
 
-// DeleteEmbedding removes an embedding from the database.
-func (s *Store) DeleteEmbedding(entityID string) error {
-	_, err := s.db.Exec(`DELETE FROM entity_embeddings WHERE entity_id = ?`, entityID)
-	return err
+// DeleteEmbedding removes an embedding from the database and the embedding index.
+func (s *SQLStore) DeleteEmbedding(entityID string) error {
+	if _, err := s.db.Exec(`DELETE FROM entity_embeddings WHERE entity_id = ?`, entityID); err != nil {
+		return err
+	}
+	s.indexEmbedding(entityID, nil, "")
+	return nil
 }
 
-// FindSimilar finds the top-K most similar entities to a query vector using cosine similarity.
-func (s *Store) FindSimilar(queryVec []float32, limit int) ([]SimilarityResult, error) {
-	embeddings, err := s.GetAllEmbeddings()
+// FindSimilar finds the top-K most similar entities to a query vector by
+// cosine similarity, using the HNSW index in .cx/embeddings.hnsw. The index
+// is built on first use and kept in sync with entity_embeddings after that,
+// so queries do not read stored vectors. Results are approximate: a close
+// match is occasionally missed.
+func (s *SQLStore) FindSimilar(queryVec []float32, limit int) ([]SimilarityResult, error) {
+	s.vectors.mu.Lock()
+	defer s.vectors.mu.Unlock()
+
+	idx, err := s.embeddingIndex()
 	if err != nil {
 		return nil, err
 	}
-
-	results := make([]SimilarityResult, 0, len(embeddings))
-	for _, e := range embeddings {
-		sim := cosineSimilarity(queryVec, e.Embedding)
-		results = append(results, SimilarityResult{
-			EntityID:   e.EntityID,
-			Similarity: sim,
-		})
+	if idx.Len() == 0 {
+		return nil, nil
+	}
+	if len(queryVec) != idx.Dim() {
+		return nil, fmt.Errorf("query embedding has %d dimensions but stored embeddings have %d; re-run 'cx scan --embed' after changing embedding models",
+			len(queryVec), idx.Dim())
+	}
+	if limit <= 0 {
+		limit = idx.Len()
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Similarity > results[j].Similarity
-	})
-
-	if limit > 0 && len(results) > limit {
-		results = results[:limit]
+	hits := idx.Search(queryVec, limit)
+	results := make([]SimilarityResult, len(hits))
+	for i, h := range hits {
+		results[i] = SimilarityResult{EntityID: h.Key, Similarity: h.Similarity}
 	}
 	return results, nil
 }
@@ -161,7 +166,7 @@ func cosineSimilarity(a, b []float32) float64 {
 }
 
 // NeedsEmbedding returns entity IDs that need (re)embedding for a given model version.
-func (s *Store) NeedsEmbedding(modelVersion string) ([]string, error) {
+func (s *SQLStore) NeedsEmbedding(modelVersion string) ([]string, error) {
 	// Find entities without embeddings OR with different model version
 	rows, err := s.db.Query(`
 		SELECT e.id FROM entities e
@@ -186,7 +191,7 @@ func (s *Store) NeedsEmbedding(modelVersion string) ([]string, error) {
 }
 
 // EmbeddingCount returns the number of stored embeddings.
-func (s *Store) EmbeddingCount() (int, error) {
+func (s *SQLStore) EmbeddingCount() (int, error) {
 	var count int
 	err := s.db.QueryRow(`SELECT COUNT(*) FROM entity_embeddings`).Scan(&count)
 	return count, err
@@ -194,7 +199,11 @@ func (s *Store) EmbeddingCount() (int, error) {
 
 // GetEmbeddingAt retrieves an embedding at a specific commit/ref using time travel queries.
 // Supports short commit hashes which are automatically resolved to full hashes.
-func (s *Store) GetEmbeddingAt(entityID, ref string) (*EntityEmbedding, error) {
+func (s *SQLStore) GetEmbeddingAt(entityID, ref string) (*EntityEmbedding, error) {
+	if s.backend == BackendSQLite {
+		return nil, fmt.Errorf("embedding history is not recorded by the sqlite backend")
+	}
+
 	// Resolve short commit hashes to full hashes
 	resolvedRef, err := s.ResolveRef(ref)
 	if err != nil {
@@ -202,18 +211,18 @@ func (s *Store) GetEmbeddingAt(entityID, ref string) (*EntityEmbedding, error) {
 	}
 
 	var e EntityEmbedding
-	var embJSON string
+	var data []byte
 	query := fmt.Sprintf(`
 		SELECT entity_id, embedding, model_version, content_hash, created_at
 		FROM entity_embeddings AS OF '%s'
 		WHERE entity_id = ?
 	`, resolvedRef)
-	err = s.db.QueryRow(query, entityID).Scan(&e.EntityID, &embJSON, &e.ModelVersion, &e.ContentHash, &e.CreatedAt)
+	err = s.db.QueryRow(query, entityID).Scan(&e.EntityID, &data, &e.ModelVersion, &e.ContentHash, &e.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
-	if err := json.Unmarshal([]byte(embJSON), &e.Embedding); err != nil {
-		return nil, fmt.Errorf("unmarshal embedding: %w", err)
+	if e.Embedding, err = decodeVector(data); err != nil {
+		return nil, fmt.Errorf("decode embedding: %w", err)
 	}
 	return &e, nil
 }
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindSimilarUsesPersistedIndex(t *testing.T) {
+	cxDir := t.TempDir()
+	s, err := OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SaveEmbedding("a", []float32{1, 0, 0}, "m1", "h1")
+	s.SaveEmbedding("b", []float32{0, 1, 0}, "m1", "h2")
+	s.SaveEmbedding("c", []float32{0, 0, 1}, "m1", "h3")
+
+	got, err := s.FindSimilar([]float32{0.9, 0.1, 0}, 2)
+	if err != nil || len(got) != 2 || got[0].EntityID != "a" || got[1].EntityID != "b" {
+		t.Fatalf("FindSimilar = %+v, %v; want [a b]", got, err)
+	}
+	if got[0].Similarity < 0.99 {
+		t.Errorf("similarity = %f, want cosine ~0.994", got[0].Similarity)
+	}
+
+	// Updates after the index is loaded are applied incrementally
+	s.SaveEmbedding("c", []float32{1, 0.1, 0}, "m1", "h4")
+	s.DeleteEmbedding("a")
+	got, _ = s.FindSimilar([]float32{1, 0, 0}, 1)
+	if len(got) != 1 || got[0].EntityID != "c" {
+		t.Errorf("FindSimilar after update = %+v, want c", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cxDir, EmbeddingIndexFile)); err != nil {
+		t.Fatalf("index not saved on Close: %v", err)
+	}
+
+	// Changes made while the index was not loaded are reconciled on next use
+	s, err = OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SaveEmbedding("b", []float32{1, 0, 0.2}, "m1", "h5")
+	if _, err := s.db.Exec(`DELETE FROM entity_embeddings WHERE entity_id = 'c'`); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.FindSimilar([]float32{1, 0, 0}, 5)
+	if len(got) != 1 || got[0].EntityID != "b" {
+		t.Errorf("FindSimilar after reopen = %+v, want only b", got)
+	}
+
+	if _, err := s.FindSimilar([]float32{1, 0}, 5); err == nil {
+		t.Error("FindSimilar with a query of the wrong dimension should fail")
+	}
+}
+
+func TestSyncEmbeddingIndexRebuildsOnModelChange(t *testing.T) {
+	cxDir := t.TempDir()
+	s, err := OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SaveEmbedding("a", []float32{1, 0}, "small", "h1")
+	if err := s.SyncEmbeddingIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new model with a different dimension replaces every vector
+	other, err := OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.SaveEmbedding("a", []float32{0, 1, 0}, "large", "h1")
+	other.SaveEmbedding("b", []float32{1, 0, 0}, "large", "h2")
+	if err := other.SyncEmbeddingIndex(); err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+
+	got, err := s.FindSimilar([]float32{0, 1, 0}, 1)
+	if err != nil || len(got) != 1 || got[0].EntityID != "a" {
+		t.Errorf("FindSimilar after model change = %+v, %v; want a", got, err)
+	}
+}
+
+func TestFindSimilarMixedDimensions(t *testing.T) {
+	for _, backend := range []string{BackendSQLite, BackendDolt} {
+		t.Run(backend, func(t *testing.T) {
+			s, err := OpenBackend(t.TempDir(), backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+
+			// An archived entity keeps the old model's vector after a switch
+			s.SaveEmbedding("old", []float32{1, 0, 0}, "small", "h1")
+			if _, err := s.FindSimilar([]float32{1, 0, 0}, 1); err != nil {
+				t.Fatal(err)
+			}
+			s.SaveEmbedding("a", []float32{1, 0, 0, 0}, "large", "h2")
+			s.SaveEmbedding("b", []float32{0, 1, 0, 0}, "large", "h3")
+
+			got, err := s.FindSimilar([]float32{0, 1, 0, 0}, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range got {
+				ids = append(ids, r.EntityID)
+			}
+			if len(ids) != 2 || ids[0] != "b" || ids[1] != "a" {
+				t.Errorf("FindSimilar over mixed dimensions = %v, want [b a]", ids)
+			}
+			if err := s.SyncEmbeddingIndex(); err != nil {
+				t.Errorf("SyncEmbeddingIndex over mixed dimensions: %v", err)
+			}
+		})
+	}
+}
+
+func TestEmbeddingsStoredAsBinary(t *testing.T) {
+	s := testSQLiteStore(t)
+	vec := []float32{0.25, -1.5, 3}
+	if err := s.SaveEmbedding("a", vec, "m1", "h1"); err != nil {
+		t.Fatal(err)
+	}
+	var raw []byte
+	if err := s.db.QueryRow(`SELECT embedding FROM entity_embeddings WHERE entity_id = 'a'`).Scan(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 4*len(vec) {
+		t.Errorf("stored embedding is %d bytes, want %d", len(raw), 4*len(vec))
+	}
+	e, err := s.GetEmbedding("a")
+	if err != nil || len(e.Embedding) != 3 || e.Embedding[1] != -1.5 {
+		t.Errorf("GetEmbedding = %+v, %v", e, err)
+	}
+}
+
+func TestMigrateLegacyEmbeddings(t *testing.T) {
+	for _, backend := range []string{BackendSQLite, BackendDolt} {
+		t.Run(backend, func(t *testing.T) {
+			cxDir := t.TempDir()
+			saved := migrations
+			migrations = saved[:1]
+			s, err := OpenBackend(cxDir, backend)
+			migrations = saved
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.db.Exec(`INSERT INTO entity_embeddings (entity_id, embedding, model_version, content_hash, created_at)
+				VALUES ('a', '[0.5, 1, -2]', 'all-MiniLM-L6-v2', 'h1', '')`); err != nil {
+				t.Fatal(err)
+			}
+			s.Close()
+
+			s, err = OpenBackend(cxDir, backend)
+			if err != nil {
+				t.Fatalf("migrate: %v", err)
+			}
+			defer s.Close()
+			e, err := s.GetEmbedding("a")
+			if err != nil || len(e.Embedding) != 3 || e.Embedding[2] != -2 {
+				t.Fatalf("GetEmbedding after migration = %+v, %v", e, err)
+			}
+			var raw []byte
+			s.db.QueryRow(`SELECT embedding FROM entity_embeddings WHERE entity_id = 'a'`).Scan(&raw)
+			if len(raw) != 12 {
+				t.Errorf("migrated embedding is %d bytes, want 12", len(raw))
+			}
+			if e.ModelVersion != "local:all-MiniLM-L6-v2" {
+				t.Errorf("model_version = %q, want the provider-qualified version", e.ModelVersion)
+			}
+
+			// Provider-qualified versions may exceed the original column width
+			long := "openai:" + strings.Repeat("x", 80)
+			if err := s.SaveEmbedding("b", []float32{1}, long, "h2"); err != nil {
+				t.Errorf("save long model_version: %v", err)
+			}
+		})
+	}
+}
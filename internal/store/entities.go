@@ -8,7 +8,7 @@ import (
 )
 
 // CreateEntity inserts a single entity into the database.
-func (s *Store) CreateEntity(e *Entity) error {
+func (s *SQLStore) CreateEntity(e *Entity) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	if e.Status == "" {
 		e.Status = "active"
@@ -29,7 +29,7 @@ func (s *Store) CreateEntity(e *Entity) error {
 
 // CreateEntitiesBulk inserts multiple entities in a single transaction.
 // Much faster than calling CreateEntity repeatedly.
-func (s *Store) CreateEntitiesBulk(entities []*Entity) error {
+func (s *SQLStore) CreateEntitiesBulk(entities []*Entity) error {
 	if len(entities) == 0 {
 		return nil
 	}
@@ -39,8 +39,7 @@ func (s *Store) CreateEntitiesBulk(entities []*Entity) error {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
 
-	stmt, err := tx.Prepare(`
-		INSERT IGNORE INTO entities (id, name, entity_type, kind, file_path, line_start, line_end,
+	stmt, err := tx.Prepare(s.insertIgnore() + ` INTO entities (id, name, entity_type, kind, file_path, line_start, line_end,
 			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
 			body_text, doc_comment, skeleton, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
@@ -78,7 +77,7 @@ func (s *Store) CreateEntitiesBulk(entities []*Entity) error {
 
 // GetEntity retrieves an entity by ID.
 // Returns sql.ErrNoRows if not found.
-func (s *Store) GetEntity(id string) (*Entity, error) {
+func (s *SQLStore) GetEntity(id string) (*Entity, error) {
 	var e Entity
 	var lineEnd sql.NullInt64
 	var language, bodyText, docComment, skeleton sql.NullString
@@ -138,7 +137,7 @@ func (s *Store) GetEntity(id string) (*Entity, error) {
 }
 
 // QueryEntities returns entities matching the filter.
-func (s *Store) QueryEntities(filter EntityFilter) ([]*Entity, error) {
+func (s *SQLStore) QueryEntities(filter EntityFilter) ([]*Entity, error) {
 	query := `
 		SELECT id, name, entity_type, kind, file_path, line_start, line_end,
 			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
@@ -250,7 +249,7 @@ func (s *Store) QueryEntities(filter EntityFilter) ([]*Entity, error) {
 
 // UpdateEntity updates an existing entity.
 // Only non-zero fields are updated.
-func (s *Store) UpdateEntity(e *Entity) error {
+func (s *SQLStore) UpdateEntity(e *Entity) error {
 	if e.ID == "" {
 		return fmt.Errorf("entity ID is required")
 	}
@@ -360,7 +359,7 @@ func (s *Store) UpdateEntity(e *Entity) error {
 
 // DeleteEntity removes an entity by ID.
 // Use ArchiveEntity for soft delete instead.
-func (s *Store) DeleteEntity(id string) error {
+func (s *SQLStore) DeleteEntity(id string) error {
 	result, err := s.db.Exec(`DELETE FROM entities WHERE id = ?`, id)
 	if err != nil {
 		return err
@@ -379,7 +378,7 @@ func (s *Store) DeleteEntity(id string) error {
 }
 
 // ArchiveEntity marks an entity as archived (soft delete).
-func (s *Store) ArchiveEntity(id string) error {
+func (s *SQLStore) ArchiveEntity(id string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	result, err := s.db.Exec(`
 		UPDATE entities SET status = ?, updated_at = ? WHERE id = ?`,
@@ -401,7 +400,7 @@ func (s *Store) ArchiveEntity(id string) error {
 }
 
 // CountEntities returns the total count of entities matching the filter.
-func (s *Store) CountEntities(filter EntityFilter) (int, error) {
+func (s *SQLStore) CountEntities(filter EntityFilter) (int, error) {
 	query := `SELECT COUNT(*) FROM entities WHERE 1=1`
 	args := []interface{}{}
 
@@ -441,7 +440,7 @@ func (s *Store) CountEntities(filter EntityFilter) (int, error) {
 
 // DeleteEntitiesByFile removes all entities from a given file path.
 // Used when rescanning a file.
-func (s *Store) DeleteEntitiesByFile(filePath string) error {
+func (s *SQLStore) DeleteEntitiesByFile(filePath string) error {
 	result, err := s.db.Exec(`DELETE FROM entities WHERE file_path = ?`, filePath)
 	if err != nil {
 		return err
@@ -487,11 +486,21 @@ const DoltHashLength = 32
 //   - Passes through special refs (HEAD, HEAD~N, etc.)
 //   - Passes through full 32-char hashes unchanged
 //   - Resolves short hashes by querying dolt_log
-func (s *Store) ResolveRef(ref string) (string, error) {
+//
+// With the SQLite backend every ref is resolved to a scan snapshot hash.
+func (s *SQLStore) ResolveRef(ref string) (string, error) {
 	if ref == "" {
 		return "", fmt.Errorf("empty ref")
 	}
 
+	if s.backend == BackendSQLite {
+		snap, err := s.resolveSnapshot(ref)
+		if err != nil {
+			return "", err
+		}
+		return snap.CommitHash, nil
+	}
+
 	// Special refs starting with HEAD pass through
 	if strings.HasPrefix(ref, "HEAD") {
 		return ref, nil
@@ -543,13 +552,13 @@ func (s *Store) ResolveRef(ref string) (string, error) {
 // GetEntityAt retrieves an entity by ID at a specific Dolt commit/ref.
 // Uses AS OF to query the table at a historical point.
 // Supported refs: commit hash (full or short), branch name, tag, HEAD~N.
-func (s *Store) GetEntityAt(id string, ref string) (*Entity, error) {
+func (s *SQLStore) GetEntityAt(id string, ref string) (*Entity, error) {
 	if !IsValidRef(ref) {
 		return nil, fmt.Errorf("invalid ref format: %s", ref)
 	}
 
-	// Resolve short commit hashes to full hashes
-	resolvedRef, err := s.ResolveRef(ref)
+	// Resolve the ref to a table reference at that point in history
+	from, err := s.tableAsOf("entities", ref)
 	if err != nil {
 		return nil, fmt.Errorf("resolve ref %s: %w", ref, err)
 	}
@@ -564,7 +573,7 @@ func (s *Store) GetEntityAt(id string, ref string) (*Entity, error) {
 		SELECT id, name, entity_type, kind, file_path, line_start, line_end,
 			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
 			body_text, doc_comment, skeleton, created_at, updated_at
-		FROM entities AS OF '%s' WHERE id = ?`, resolvedRef)
+		FROM %s WHERE id = ?`, from)
 
 	err = s.db.QueryRow(query, id).Scan(
 		&e.ID, &e.Name, &e.EntityType, &e.Kind, &e.FilePath, &e.LineStart, &lineEnd,
@@ -617,13 +626,13 @@ func (s *Store) GetEntityAt(id string, ref string) (*Entity, error) {
 // QueryEntitiesAt returns entities matching the filter at a specific Dolt commit/ref.
 // Uses AS OF to query the table at a historical point.
 // Supports short commit hashes which are automatically resolved to full hashes.
-func (s *Store) QueryEntitiesAt(filter EntityFilter, ref string) ([]*Entity, error) {
+func (s *SQLStore) QueryEntitiesAt(filter EntityFilter, ref string) ([]*Entity, error) {
 	if !IsValidRef(ref) {
 		return nil, fmt.Errorf("invalid ref format: %s", ref)
 	}
 
-	// Resolve short commit hashes to full hashes
-	resolvedRef, err := s.ResolveRef(ref)
+	// Resolve the ref to a table reference at that point in history
+	from, err := s.tableAsOf("entities", ref)
 	if err != nil {
 		return nil, fmt.Errorf("resolve ref %s: %w", ref, err)
 	}
@@ -633,7 +642,7 @@ func (s *Store) QueryEntitiesAt(filter EntityFilter, ref string) ([]*Entity, err
 		SELECT id, name, entity_type, kind, file_path, line_start, line_end,
 			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
 			body_text, doc_comment, skeleton, created_at, updated_at
-		FROM entities AS OF '%s' WHERE 1=1`, resolvedRef)
+		FROM %s WHERE 1=1`, from)
 	args := []interface{}{}
 
 	if filter.EntityType != "" {
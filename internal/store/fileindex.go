@@ -7,7 +7,7 @@ import (
 )
 
 // SetFileScanned records that a file has been scanned with the given hash.
-func (s *Store) SetFileScanned(path, hash string) error {
+func (s *SQLStore) SetFileScanned(path, hash string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	_, err := s.db.Exec(`
         REPLACE INTO file_index (file_path, scan_hash, scanned_at)
@@ -19,7 +19,7 @@ func (s *Store) SetFileScanned(path, hash string) error {
 }
 
 // SetFilesScannedBulk records scan state for multiple files efficiently.
-func (s *Store) SetFilesScannedBulk(entries []*FileIndex) error {
+func (s *SQLStore) SetFilesScannedBulk(entries []*FileIndex) error {
 	if len(entries) == 0 {
 		return nil
 	}
@@ -55,7 +55,7 @@ func (s *Store) SetFilesScannedBulk(entries []*FileIndex) error {
 
 // GetFileHash retrieves the last scan hash for a file.
 // Returns sql.ErrNoRows if the file has not been scanned.
-func (s *Store) GetFileHash(path string) (string, error) {
+func (s *SQLStore) GetFileHash(path string) (string, error) {
 	var hash string
 	err := s.db.QueryRow("SELECT scan_hash FROM file_index WHERE file_path = ?", path).Scan(&hash)
 	if err != nil {
@@ -65,7 +65,7 @@ func (s *Store) GetFileHash(path string) (string, error) {
 }
 
 // GetFileEntry retrieves the full file entry including scan time.
-func (s *Store) GetFileEntry(path string) (*FileIndex, error) {
+func (s *SQLStore) GetFileEntry(path string) (*FileIndex, error) {
 	var entry FileIndex
 	var scannedAt string
 	err := s.db.QueryRow(`
@@ -80,7 +80,7 @@ func (s *Store) GetFileEntry(path string) (*FileIndex, error) {
 
 // IsFileChanged checks if a file's content has changed since last scan.
 // Returns true if the file has changed or has never been scanned.
-func (s *Store) IsFileChanged(path, newHash string) (bool, error) {
+func (s *SQLStore) IsFileChanged(path, newHash string) (bool, error) {
 	oldHash, err := s.GetFileHash(path)
 	if err == sql.ErrNoRows {
 		return true, nil // Never scanned
@@ -92,7 +92,7 @@ func (s *Store) IsFileChanged(path, newHash string) (bool, error) {
 }
 
 // GetAllFileEntries retrieves all file entries from the index.
-func (s *Store) GetAllFileEntries() ([]*FileIndex, error) {
+func (s *SQLStore) GetAllFileEntries() ([]*FileIndex, error) {
 	rows, err := s.db.Query(`
         SELECT file_path, scan_hash, scanned_at FROM file_index ORDER BY file_path`)
 	if err != nil {
@@ -115,13 +115,13 @@ func (s *Store) GetAllFileEntries() ([]*FileIndex, error) {
 }
 
 // DeleteFileEntry removes a file from the index.
-func (s *Store) DeleteFileEntry(path string) error {
+func (s *SQLStore) DeleteFileEntry(path string) error {
 	_, err := s.db.Exec("DELETE FROM file_index WHERE file_path = ?", path)
 	return err
 }
 
 // GetChangedFiles returns files that have changed compared to the provided hashes.
-func (s *Store) GetChangedFiles(fileHashes map[string]string) ([]string, error) {
+func (s *SQLStore) GetChangedFiles(fileHashes map[string]string) ([]string, error) {
 	var changed []string
 
 	for path, newHash := range fileHashes {
@@ -138,7 +138,7 @@ func (s *Store) GetChangedFiles(fileHashes map[string]string) ([]string, error)
 }
 
 // PruneStaleEntries removes file entries for files no longer in the provided set.
-func (s *Store) PruneStaleEntries(validPaths map[string]bool) (int, error) {
+func (s *SQLStore) PruneStaleEntries(validPaths map[string]bool) (int, error) {
 	entries, err := s.GetAllFileEntries()
 	if err != nil {
 		return 0, err
@@ -158,13 +158,13 @@ func (s *Store) PruneStaleEntries(validPaths map[string]bool) (int, error) {
 }
 
 // ClearFileIndex removes all file index data.
-func (s *Store) ClearFileIndex() error {
+func (s *SQLStore) ClearFileIndex() error {
 	_, err := s.db.Exec("DELETE FROM file_index")
 	return err
 }
 
 // CountFileIndex returns the number of indexed files.
-func (s *Store) CountFileIndex() (int, error) {
+func (s *SQLStore) CountFileIndex() (int, error) {
 	var count int
 	err := s.db.QueryRow("SELECT COUNT(*) FROM file_index").Scan(&count)
 	return count, err
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fingerprintsTable holds the winnowed token fingerprint of each function
+// and method body, which cx find --clones and cx guard compare to find
+// near-copies. Hashes are stored as concatenated 8-digit hex values.
+const fingerprintsTable = `CREATE TABLE IF NOT EXISTS entity_fingerprints (
+    entity_id VARCHAR(255) PRIMARY KEY,
+    file_path VARCHAR(500) NOT NULL,
+    tokens INT NOT NULL,
+    hashes TEXT NOT NULL
+)`
+
+// EntityFingerprint is the fingerprint of one function or method body
+type EntityFingerprint struct {
+	EntityID string
+	FilePath string
+	Tokens   int
+	Hashes   []uint32 // sorted, distinct
+}
+
+// SaveFingerprints replaces the fingerprints of the given files.
+func (s *SQLStore) SaveFingerprints(files []string, fingerprints []EntityFingerprint) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, f := range files {
+		if _, err := tx.Exec(`DELETE FROM entity_fingerprints WHERE file_path = ?`, f); err != nil {
+			return fmt.Errorf("clear fingerprints: %w", err)
+		}
+	}
+	stmt, err := tx.Prepare(`REPLACE INTO entity_fingerprints (entity_id, file_path, tokens, hashes) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+	for _, fp := range fingerprints {
+		if _, err := stmt.Exec(fp.EntityID, fp.FilePath, fp.Tokens, encodeHashes(fp.Hashes)); err != nil {
+			return fmt.Errorf("insert fingerprint: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetFingerprints returns the fingerprints of all active entities.
+func (s *SQLStore) GetFingerprints() ([]EntityFingerprint, error) {
+	rows, err := s.db.Query(`SELECT f.entity_id, f.file_path, f.tokens, f.hashes
+		FROM entity_fingerprints f JOIN entities e ON e.id = f.entity_id
+		WHERE e.status = 'active'
+		ORDER BY f.entity_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	var result []EntityFingerprint
+	for rows.Next() {
+		var fp EntityFingerprint
+		var hashes string
+		if err := rows.Scan(&fp.EntityID, &fp.FilePath, &fp.Tokens, &hashes); err != nil {
+			return nil, fmt.Errorf("scan fingerprint: %w", err)
+		}
+		if fp.Hashes, err = decodeHashes(hashes); err != nil {
+			return nil, fmt.Errorf("fingerprint of %s: %w", fp.EntityID, err)
+		}
+		result = append(result, fp)
+	}
+	return result, rows.Err()
+}
+
+// encodeHashes writes hashes as concatenated 8-digit hex values
+func encodeHashes(hashes []uint32) string {
+	var sb strings.Builder
+	sb.Grow(len(hashes) * 8)
+	for _, h := range hashes {
+		fmt.Fprintf(&sb, "%08x", h)
+	}
+	return sb.String()
+}
+
+// decodeHashes reads hashes written by encodeHashes
+func decodeHashes(s string) ([]uint32, error) {
+	if len(s)%8 != 0 {
+		return nil, fmt.Errorf("invalid hash list length %d", len(s))
+	}
+	hashes := make([]uint32, 0, len(s)/8)
+	for i := 0; i < len(s); i += 8 {
+		h, err := strconv.ParseUint(s[i:i+8], 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, uint32(h))
+	}
+	return hashes, nil
+}
@@ -41,15 +41,8 @@ func DefaultSearchOptions() SearchOptions {
 	}
 }
 
-// SearchEntities performs full-text search on entities using MySQL FULLTEXT.
-// Returns entities sorted by combined relevance score (FTS + PageRank).
-// Note: Dolt only supports NATURAL LANGUAGE MODE (no boolean operators).
-func (s *Store) SearchEntities(opts SearchOptions) ([]*SearchResult, error) {
-	if opts.Query == "" {
-		return nil, fmt.Errorf("search query is required")
-	}
-
-	// Set defaults
+// withSearchDefaults fills in zero-valued limits and boosts.
+func withSearchDefaults(opts SearchOptions) SearchOptions {
 	if opts.Limit <= 0 {
 		opts.Limit = 10
 	}
@@ -62,10 +55,26 @@ func (s *Store) SearchEntities(opts SearchOptions) ([]*SearchResult, error) {
 	if opts.BoostExactName <= 0 {
 		opts.BoostExactName = 2.0
 	}
+	return opts
+}
+
+// SearchEntities performs full-text search on entities using MySQL FULLTEXT.
+// Returns entities sorted by combined relevance score (FTS + PageRank).
+// Note: Dolt only supports NATURAL LANGUAGE MODE (no boolean operators).
+func (s *SQLStore) SearchEntities(opts SearchOptions) ([]*SearchResult, error) {
+	if opts.Query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+
+	opts = withSearchDefaults(opts)
 
 	// Normalize query for FULLTEXT matching
 	ftsQuery := buildFTSQuery(opts.Query)
 
+	if s.backend == BackendSQLite {
+		return s.searchEntitiesFTS5(opts, ftsQuery)
+	}
+
 	// Build the SQL query using MySQL FULLTEXT syntax
 	// MATCH() AGAINST() returns relevance score when used in SELECT
 	// Note: Dolt has a bug where FULLTEXT queries fail when table has an alias,
@@ -143,8 +152,12 @@ func (s *Store) SearchEntities(opts SearchOptions) ([]*SearchResult, error) {
 
 // RebuildFTSIndex is a no-op for MySQL FULLTEXT indexes.
 // FULLTEXT indexes are automatically maintained by the database engine.
-// This function is kept for API compatibility.
-func (s *Store) RebuildFTSIndex() error {
+// With the SQLite backend it rebuilds the FTS5 index from the entities table.
+func (s *SQLStore) RebuildFTSIndex() error {
+	if s.backend == BackendSQLite {
+		_, err := s.db.Exec(`INSERT INTO entities_fts(entities_fts) VALUES('rebuild')`)
+		return err
+	}
 	// MySQL FULLTEXT indexes are automatically maintained
 	// No manual rebuild needed like with SQLite FTS5
 	return nil
@@ -152,7 +165,7 @@ func (s *Store) RebuildFTSIndex() error {
 
 // CountFTSEntries returns the number of entities with searchable content.
 // For MySQL FULLTEXT, this counts entities that have indexable text.
-func (s *Store) CountFTSEntries() (int, error) {
+func (s *SQLStore) CountFTSEntries() (int, error) {
 	var count int
 	err := s.db.QueryRow(`
 		SELECT COUNT(*) FROM entities
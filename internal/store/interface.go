@@ -0,0 +1,124 @@
+package store
+
+// Store is the persistence interface for the code graph and everything cx
+// records about it. SQLStore implements it on top of Dolt or SQLite, and
+// MemoryStore implements it in memory for tests and library use.
+//
+// Lookups of a single row (GetEntity, GetMetrics, GetCoverage, ...) return
+// sql.ErrNoRows when the row does not exist, regardless of implementation.
+type Store interface {
+	EntityStore
+	DependencyStore
+	MetricsStore
+	TagStore
+	LinkStore
+	CoverageStore
+	EmbeddingStore
+	FileIndexStore
+	HistoryStore
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// EntityStore manages code entities.
+type EntityStore interface {
+	CreateEntity(e *Entity) error
+	CreateEntitiesBulk(entities []*Entity) error
+	GetEntity(id string) (*Entity, error)
+	QueryEntities(filter EntityFilter) ([]*Entity, error)
+	UpdateEntity(e *Entity) error
+	DeleteEntity(id string) error
+	ArchiveEntity(id string) error
+	CountEntities(filter EntityFilter) (int, error)
+	DeleteEntitiesByFile(filePath string) error
+	SearchEntities(opts SearchOptions) ([]*SearchResult, error)
+}
+
+// DependencyStore manages edges between entities.
+type DependencyStore interface {
+	CreateDependency(d *Dependency) error
+	CreateDependenciesBulk(deps []*Dependency) error
+	GetDependencies(filter DependencyFilter) ([]*Dependency, error)
+	GetDependenciesFrom(entityID string) ([]*Dependency, error)
+	GetDependenciesTo(entityID string) ([]*Dependency, error)
+	GetAllDependencies() ([]*Dependency, error)
+	DeleteDependency(fromID, toID, depType string) error
+	DeleteDependenciesFrom(entityID string) error
+	DeleteDependenciesByFile(filePath string) error
+	CountDependencies() (int, error)
+}
+
+// MetricsStore manages cached graph metrics.
+type MetricsStore interface {
+	SaveMetrics(m *Metrics) error
+	SaveBulkMetrics(metrics []*Metrics) error
+	GetMetrics(entityID string) (*Metrics, error)
+	GetAllMetrics() ([]*Metrics, error)
+	GetTopByPageRank(n int) ([]*Metrics, error)
+	GetTopByBetweenness(n int) ([]*Metrics, error)
+	GetTopByInDegree(n int) ([]*Metrics, error)
+	GetTopByOutDegree(n int) ([]*Metrics, error)
+	GetKeystones(threshold float64) ([]*Metrics, error)
+	GetBottlenecks(threshold float64) ([]*Metrics, error)
+	GetHighlyConnected(threshold int) ([]*Metrics, error)
+	DeleteMetrics(entityID string) error
+	ClearMetrics() error
+	CountMetrics() (int, error)
+}
+
+// TagStore manages tags on entities.
+type TagStore interface {
+	AddTag(entityID, tag, createdBy string) error
+	AddTagWithNote(entityID, tag, createdBy, note string) error
+	RemoveTag(entityID, tag string) error
+	GetTags(entityID string) ([]*EntityTag, error)
+	FindByTag(tag string) ([]*Entity, error)
+	FindByTags(tags []string, matchAll bool) ([]*Entity, error)
+	ListAllTags() (map[string]int, error)
+	GetAllTagsWithEntity() ([]*EntityTagWithName, error)
+	DeleteTagsForEntity(entityID string) error
+	CountTags() (int, error)
+}
+
+// LinkStore manages links from entities to external systems.
+type LinkStore interface {
+	CreateLink(link *EntityLink) error
+	GetLinks(entityID string) ([]*EntityLink, error)
+	GetLinksByExternalID(externalSystem, externalID string) ([]*EntityLink, error)
+	GetLinksBySystem(externalSystem string) ([]*EntityLink, error)
+	DeleteLink(entityID, externalSystem, externalID string) error
+	DeleteLinksForEntity(entityID string) error
+	CountLinks() (int, error)
+}
+
+// CoverageStore manages test coverage and test to entity mappings.
+type CoverageStore interface {
+	SaveCoverage(cov *EntityCoverage) error
+	GetCoverage(entityID string) (*EntityCoverage, error)
+	GetAllCoverage() ([]*EntityCoverage, error)
+	AddTestMapping(testFile, testName, entityID string) error
+	GetTestMappings(filter TestMappingFilter) ([]*TestMapping, error)
+	ClearTestMappings() error
+}
+
+// FileIndexStore tracks which files have been scanned and with what content.
+type FileIndexStore interface {
+	SetFileScanned(path, hash string) error
+	GetFileEntry(path string) (*FileIndex, error)
+	GetAllFileEntries() ([]*FileIndex, error)
+	DeleteFileEntry(path string) error
+}
+
+// HistoryStore answers questions about earlier versions of the graph.
+// Implementations without history return an error from every method.
+type HistoryStore interface {
+	GetEntityAt(id string, ref string) (*Entity, error)
+	QueryEntitiesAt(filter EntityFilter, ref string) ([]*Entity, error)
+	GetDependenciesAt(filter DependencyFilter, ref string) ([]*Dependency, error)
+	EntityHistory(opts EntityHistoryOptions) ([]EntityHistoryEntry, error)
+	DependencyHistory(opts DependencyHistoryOptions) ([]DependencyHistoryEntry, error)
+}
+
+// Compile-time interface checks.
+var _ Store = (*SQLStore)(nil)
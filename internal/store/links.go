@@ -5,7 +5,7 @@ import (
 )
 
 // CreateLink creates a link between an entity and an external system.
-func (s *Store) CreateLink(link *EntityLink) error {
+func (s *SQLStore) CreateLink(link *EntityLink) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	if link.LinkType == "" {
 		link.LinkType = "related"
@@ -19,7 +19,7 @@ func (s *Store) CreateLink(link *EntityLink) error {
 }
 
 // GetLinks returns all links for an entity.
-func (s *Store) GetLinks(entityID string) ([]*EntityLink, error) {
+func (s *SQLStore) GetLinks(entityID string) ([]*EntityLink, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, external_system, external_id, link_type, created_at
 		FROM entity_links WHERE entity_id = ?
@@ -45,7 +45,7 @@ func (s *Store) GetLinks(entityID string) ([]*EntityLink, error) {
 
 // GetLinksByExternalID returns all links to a specific external ID.
 // Useful for finding all entities linked to a specific bead or issue.
-func (s *Store) GetLinksByExternalID(externalSystem, externalID string) ([]*EntityLink, error) {
+func (s *SQLStore) GetLinksByExternalID(externalSystem, externalID string) ([]*EntityLink, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, external_system, external_id, link_type, created_at
 		FROM entity_links
@@ -71,7 +71,7 @@ func (s *Store) GetLinksByExternalID(externalSystem, externalID string) ([]*Enti
 }
 
 // GetLinksBySystem returns all links to a specific external system.
-func (s *Store) GetLinksBySystem(externalSystem string) ([]*EntityLink, error) {
+func (s *SQLStore) GetLinksBySystem(externalSystem string) ([]*EntityLink, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, external_system, external_id, link_type, created_at
 		FROM entity_links WHERE external_system = ?
@@ -96,7 +96,7 @@ func (s *Store) GetLinksBySystem(externalSystem string) ([]*EntityLink, error) {
 }
 
 // DeleteLink removes a specific link.
-func (s *Store) DeleteLink(entityID, externalSystem, externalID string) error {
+func (s *SQLStore) DeleteLink(entityID, externalSystem, externalID string) error {
 	_, err := s.db.Exec(`
 		DELETE FROM entity_links
 		WHERE entity_id = ? AND external_system = ? AND external_id = ?`,
@@ -105,13 +105,13 @@ func (s *Store) DeleteLink(entityID, externalSystem, externalID string) error {
 }
 
 // DeleteLinksForEntity removes all links for an entity.
-func (s *Store) DeleteLinksForEntity(entityID string) error {
+func (s *SQLStore) DeleteLinksForEntity(entityID string) error {
 	_, err := s.db.Exec(`DELETE FROM entity_links WHERE entity_id = ?`, entityID)
 	return err
 }
 
 // CountLinks returns the total number of links.
-func (s *Store) CountLinks() (int, error) {
+func (s *SQLStore) CountLinks() (int, error) {
 	var count int
 	err := s.db.QueryRow(`SELECT COUNT(*) FROM entity_links`).Scan(&count)
 	return count, err
@@ -0,0 +1,1199 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNoHistory is returned by the HistoryStore methods of MemoryStore.
+var errNoHistory = errors.New("history is not recorded by the in-memory store")
+
+// MemoryStore is a Store kept entirely in memory. It is meant for tests and
+// for embedding cx as a library against graphs built in code; nothing is
+// persisted and no history is recorded. It is safe for concurrent use.
+//
+// Values passed in and returned are copies, so callers may modify them
+// freely without affecting the store.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	entities     map[string]*Entity
+	dependencies map[depID]*Dependency
+	metrics      map[string]*Metrics
+	tags         map[string]map[string]*EntityTag // entity ID -> tag -> tag
+	links        map[linkID]*EntityLink
+	coverage     map[string]*EntityCoverage
+	testMappings map[TestMapping]bool
+	embeddings   map[string]*EntityEmbedding
+	files        map[string]*FileIndex
+}
+
+// Compile-time interface check.
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entities:     make(map[string]*Entity),
+		dependencies: make(map[depID]*Dependency),
+		metrics:      make(map[string]*Metrics),
+		tags:         make(map[string]map[string]*EntityTag),
+		links:        make(map[linkID]*EntityLink),
+		coverage:     make(map[string]*EntityCoverage),
+		testMappings: make(map[TestMapping]bool),
+		embeddings:   make(map[string]*EntityEmbedding),
+		files:        make(map[string]*FileIndex),
+	}
+}
+
+// Close is a no-op; the store's contents stay readable until it is dropped.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// --- entities ---
+
+// CreateEntity inserts a single entity. It fails if the ID already exists.
+func (s *MemoryStore) CreateEntity(e *Entity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entities[e.ID]; ok {
+		return fmt.Errorf("entity %s already exists", e.ID)
+	}
+	s.insertEntity(e)
+	return nil
+}
+
+// CreateEntitiesBulk inserts multiple entities, skipping IDs that already exist.
+func (s *MemoryStore) CreateEntitiesBulk(entities []*Entity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range entities {
+		if _, ok := s.entities[e.ID]; ok {
+			continue
+		}
+		s.insertEntity(e)
+	}
+	return nil
+}
+
+// insertEntity applies the same defaults as SQLStore and stores a copy.
+// Callers must hold the write lock.
+func (s *MemoryStore) insertEntity(e *Entity) {
+	if e.Status == "" {
+		e.Status = "active"
+	}
+	if e.Language == "" {
+		e.Language = "go"
+	}
+	c := copyEntity(e)
+	now := time.Now().UTC().Truncate(time.Second)
+	c.CreatedAt, c.UpdatedAt = now, now
+	s.entities[e.ID] = c
+}
+
+// GetEntity retrieves an entity by ID.
+// Returns sql.ErrNoRows if not found.
+func (s *MemoryStore) GetEntity(id string) (*Entity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entities[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return copyEntity(e), nil
+}
+
+// QueryEntities returns entities matching the filter, ordered by file path
+// and line like SQLStore.
+func (s *MemoryStore) QueryEntities(filter EntityFilter) ([]*Entity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []*Entity
+	for _, e := range s.entities {
+		if matchEntityFilter(e, filter) {
+			matched = append(matched, copyEntity(e))
+		}
+	}
+	sortEntities(matched)
+
+	if filter.Limit > 0 {
+		if filter.Offset >= len(matched) {
+			return nil, nil
+		}
+		matched = matched[filter.Offset:]
+		if len(matched) > filter.Limit {
+			matched = matched[:filter.Limit]
+		}
+	}
+	return matched, nil
+}
+
+// UpdateEntity updates an existing entity.
+// Only non-zero fields are updated.
+func (s *MemoryStore) UpdateEntity(e *Entity) error {
+	if e.ID == "" {
+		return fmt.Errorf("entity ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cur, ok := s.entities[e.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	changed := false
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+			changed = true
+		}
+	}
+	set(&cur.Name, e.Name)
+	set(&cur.EntityType, e.EntityType)
+	set(&cur.Kind, e.Kind)
+	set(&cur.FilePath, e.FilePath)
+	set(&cur.Signature, e.Signature)
+	set(&cur.SigHash, e.SigHash)
+	set(&cur.BodyHash, e.BodyHash)
+	set(&cur.Receiver, e.Receiver)
+	set(&cur.Visibility, e.Visibility)
+	set(&cur.Fields, e.Fields)
+	set(&cur.Status, e.Status)
+	set(&cur.Language, e.Language)
+	set(&cur.BodyText, e.BodyText)
+	set(&cur.DocComment, e.DocComment)
+	set(&cur.Skeleton, e.Skeleton)
+	if e.LineStart != 0 {
+		cur.LineStart = e.LineStart
+		changed = true
+	}
+	if e.LineEnd != nil {
+		v := *e.LineEnd
+		cur.LineEnd = &v
+		changed = true
+	}
+
+	if changed {
+		cur.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	return nil
+}
+
+// DeleteEntity removes an entity by ID.
+// Returns sql.ErrNoRows if not found.
+func (s *MemoryStore) DeleteEntity(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entities[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.entities, id)
+	return nil
+}
+
+// ArchiveEntity marks an entity as archived (soft delete).
+func (s *MemoryStore) ArchiveEntity(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entities[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	e.Status = "archived"
+	e.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	return nil
+}
+
+// CountEntities returns the number of entities matching the filter.
+func (s *MemoryStore) CountEntities(filter EntityFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, e := range s.entities {
+		if matchEntityFilter(e, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteEntitiesByFile removes all entities from a given file path.
+func (s *MemoryStore) DeleteEntitiesByFile(filePath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, e := range s.entities {
+		if e.FilePath == filePath {
+			delete(s.entities, id)
+		}
+	}
+	return nil
+}
+
+// SearchEntities performs a keyword search over entity names, doc comments
+// and bodies. Scores count term occurrences, weighting names above doc
+// comments above bodies, and are combined with PageRank the same way as
+// SQLStore's full-text search.
+func (s *MemoryStore) SearchEntities(opts SearchOptions) ([]*SearchResult, error) {
+	if opts.Query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+	opts = withSearchDefaults(opts)
+	terms := strings.Fields(strings.ToLower(buildFTSQuery(opts.Query)))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []*SearchResult
+	for _, e := range s.entities {
+		if e.Status != "active" {
+			continue
+		}
+		if opts.Language != "" && e.Language != opts.Language {
+			continue
+		}
+		if opts.EntityType != "" && e.EntityType != opts.EntityType {
+			continue
+		}
+
+		name := strings.ToLower(e.Name)
+		doc := strings.ToLower(e.DocComment)
+		body := strings.ToLower(e.BodyText)
+
+		var score float64
+		matchColumn := ""
+		for _, term := range terms {
+			if n := strings.Count(name, term); n > 0 {
+				score += 3 * float64(n)
+				if matchColumn == "" {
+					matchColumn = "name"
+				}
+			}
+			if n := strings.Count(doc, term); n > 0 {
+				score += 2 * float64(n)
+				if matchColumn == "" {
+					matchColumn = "doc_comment"
+				}
+			}
+			if n := strings.Count(body, term); n > 0 {
+				score += float64(n)
+				if matchColumn == "" {
+					matchColumn = "body_text"
+				}
+			}
+		}
+		if score == 0 {
+			continue
+		}
+
+		var pagerank float64
+		if m, ok := s.metrics[e.ID]; ok {
+			pagerank = m.PageRank
+		}
+		result := &SearchResult{
+			Entity:        copyEntity(e),
+			FTSScore:      score,
+			PageRank:      pagerank,
+			CombinedScore: opts.BoostFTS*normalizeBM25Score(score) + opts.BoostPageRank*pagerank,
+			MatchColumn:   matchColumn,
+		}
+		if strings.EqualFold(e.Name, opts.Query) {
+			result.CombinedScore *= opts.BoostExactName
+		}
+		if result.CombinedScore >= opts.Threshold {
+			results = append(results, result)
+		}
+	}
+
+	// Break ties deterministically before the stable score sort
+	sort.Slice(results, func(i, j int) bool { return results[i].Entity.ID < results[j].Entity.ID })
+	sortSearchResults(results)
+
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// --- dependencies ---
+
+// CreateDependency inserts a single dependency, replacing an identical edge.
+func (s *MemoryStore) CreateDependency(d *Dependency) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.insertDependency(d)
+	return nil
+}
+
+// CreateDependenciesBulk inserts multiple dependencies.
+func (s *MemoryStore) CreateDependenciesBulk(deps []*Dependency) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range deps {
+		s.insertDependency(d)
+	}
+	return nil
+}
+
+// insertDependency stores a copy of d, keeping the scip source of an edge
+// SCIP confirmed. Callers must hold the write lock.
+func (s *MemoryStore) insertDependency(d *Dependency) {
+	c := *d
+	c.Source = depSource(d)
+	c.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	key := depKey(d.FromID, d.ToID, d.DepType)
+	if old, ok := s.dependencies[key]; ok && old.Source == DepSourceSCIP {
+		c.Source = DepSourceSCIP
+	}
+	s.dependencies[key] = &c
+}
+
+// GetDependencies returns dependencies matching the filter, ordered by
+// source, target and type.
+func (s *MemoryStore) GetDependencies(filter DependencyFilter) ([]*Dependency, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deps []*Dependency
+	for _, d := range s.dependencies {
+		if filter.FromID != "" && d.FromID != filter.FromID {
+			continue
+		}
+		if filter.ToID != "" && d.ToID != filter.ToID {
+			continue
+		}
+		if filter.DepType != "" && d.DepType != filter.DepType {
+			continue
+		}
+		if filter.Source != "" && d.Source != filter.Source {
+			continue
+		}
+		c := *d
+		deps = append(deps, &c)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].FromID != deps[j].FromID {
+			return deps[i].FromID < deps[j].FromID
+		}
+		if deps[i].ToID != deps[j].ToID {
+			return deps[i].ToID < deps[j].ToID
+		}
+		return deps[i].DepType < deps[j].DepType
+	})
+	return deps, nil
+}
+
+// GetDependenciesFrom returns all dependencies from a specific entity.
+func (s *MemoryStore) GetDependenciesFrom(entityID string) ([]*Dependency, error) {
+	return s.GetDependencies(DependencyFilter{FromID: entityID})
+}
+
+// GetDependenciesTo returns all dependencies to a specific entity.
+func (s *MemoryStore) GetDependenciesTo(entityID string) ([]*Dependency, error) {
+	return s.GetDependencies(DependencyFilter{ToID: entityID})
+}
+
+// GetAllDependencies returns every dependency in the store.
+func (s *MemoryStore) GetAllDependencies() ([]*Dependency, error) {
+	return s.GetDependencies(DependencyFilter{})
+}
+
+// DeleteDependency removes a specific dependency.
+func (s *MemoryStore) DeleteDependency(fromID, toID, depType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.dependencies, depKey(fromID, toID, depType))
+	return nil
+}
+
+// DeleteDependenciesFrom removes all dependencies from an entity.
+func (s *MemoryStore) DeleteDependenciesFrom(entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.dependencies {
+		if k.fromID == entityID {
+			delete(s.dependencies, k)
+		}
+	}
+	return nil
+}
+
+// DeleteDependenciesByFile removes all dependencies whose source entity is in the file.
+func (s *MemoryStore) DeleteDependenciesByFile(filePath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.dependencies {
+		if e, ok := s.entities[k.fromID]; ok && e.FilePath == filePath {
+			delete(s.dependencies, k)
+		}
+	}
+	return nil
+}
+
+// CountDependencies returns the total number of dependencies.
+func (s *MemoryStore) CountDependencies() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.dependencies), nil
+}
+
+// --- metrics ---
+
+// SaveMetrics stores metrics for an entity, replacing any existing values.
+func (s *MemoryStore) SaveMetrics(m *Metrics) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *m
+	s.metrics[m.EntityID] = &c
+	return nil
+}
+
+// SaveBulkMetrics stores metrics for multiple entities.
+func (s *MemoryStore) SaveBulkMetrics(metrics []*Metrics) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range metrics {
+		c := *m
+		s.metrics[m.EntityID] = &c
+	}
+	return nil
+}
+
+// GetMetrics retrieves metrics for a specific entity.
+// Returns sql.ErrNoRows if the entity is not found.
+func (s *MemoryStore) GetMetrics(entityID string) (*Metrics, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.metrics[entityID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	c := *m
+	return &c, nil
+}
+
+// GetAllMetrics retrieves all metrics ordered by PageRank descending.
+func (s *MemoryStore) GetAllMetrics() ([]*Metrics, error) {
+	return s.selectMetrics(nil, func(a, b *Metrics) bool { return a.PageRank > b.PageRank }, 0), nil
+}
+
+// GetTopByPageRank returns the top N entities by PageRank score.
+func (s *MemoryStore) GetTopByPageRank(n int) ([]*Metrics, error) {
+	return s.selectMetrics(nil, func(a, b *Metrics) bool { return a.PageRank > b.PageRank }, n), nil
+}
+
+// GetTopByBetweenness returns the top N entities by betweenness centrality.
+func (s *MemoryStore) GetTopByBetweenness(n int) ([]*Metrics, error) {
+	return s.selectMetrics(nil, func(a, b *Metrics) bool { return a.Betweenness > b.Betweenness }, n), nil
+}
+
+// GetTopByInDegree returns the top N entities by in-degree.
+func (s *MemoryStore) GetTopByInDegree(n int) ([]*Metrics, error) {
+	return s.selectMetrics(nil, func(a, b *Metrics) bool { return a.InDegree > b.InDegree }, n), nil
+}
+
+// GetTopByOutDegree returns the top N entities by out-degree.
+func (s *MemoryStore) GetTopByOutDegree(n int) ([]*Metrics, error) {
+	return s.selectMetrics(nil, func(a, b *Metrics) bool { return a.OutDegree > b.OutDegree }, n), nil
+}
+
+// GetKeystones returns entities with PageRank >= threshold.
+func (s *MemoryStore) GetKeystones(threshold float64) ([]*Metrics, error) {
+	return s.selectMetrics(
+		func(m *Metrics) bool { return m.PageRank >= threshold },
+		func(a, b *Metrics) bool { return a.PageRank > b.PageRank }, 0), nil
+}
+
+// GetBottlenecks returns entities with betweenness >= threshold.
+func (s *MemoryStore) GetBottlenecks(threshold float64) ([]*Metrics, error) {
+	return s.selectMetrics(
+		func(m *Metrics) bool { return m.Betweenness >= threshold },
+		func(a, b *Metrics) bool { return a.Betweenness > b.Betweenness }, 0), nil
+}
+
+// GetHighlyConnected returns entities with in_degree >= threshold.
+func (s *MemoryStore) GetHighlyConnected(threshold int) ([]*Metrics, error) {
+	return s.selectMetrics(
+		func(m *Metrics) bool { return m.InDegree >= threshold },
+		func(a, b *Metrics) bool { return a.InDegree > b.InDegree }, 0), nil
+}
+
+// selectMetrics returns copies of the metrics accepted by keep (all if nil),
+// ordered by less and truncated to limit (no limit if 0).
+func (s *MemoryStore) selectMetrics(keep func(*Metrics) bool, less func(a, b *Metrics) bool, limit int) []*Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*Metrics
+	for _, m := range s.metrics {
+		if keep != nil && !keep(m) {
+			continue
+		}
+		c := *m
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if less(result[i], result[j]) {
+			return true
+		}
+		if less(result[j], result[i]) {
+			return false
+		}
+		return result[i].EntityID < result[j].EntityID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// DeleteMetrics removes metrics for a specific entity.
+func (s *MemoryStore) DeleteMetrics(entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.metrics, entityID)
+	return nil
+}
+
+// ClearMetrics removes all cached metrics.
+func (s *MemoryStore) ClearMetrics() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metrics = make(map[string]*Metrics)
+	return nil
+}
+
+// CountMetrics returns the number of entities with cached metrics.
+func (s *MemoryStore) CountMetrics() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.metrics), nil
+}
+
+// --- tags ---
+
+// AddTag adds a tag to an entity.
+func (s *MemoryStore) AddTag(entityID, tag, createdBy string) error {
+	return s.AddTagWithNote(entityID, tag, createdBy, "")
+}
+
+// AddTagWithNote adds a tag with a note, updating the creator and note if
+// the tag already exists.
+func (s *MemoryStore) AddTagWithNote(entityID, tag, createdBy, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byTag, ok := s.tags[entityID]
+	if !ok {
+		byTag = make(map[string]*EntityTag)
+		s.tags[entityID] = byTag
+	}
+	if t, ok := byTag[tag]; ok {
+		t.CreatedBy = createdBy
+		t.Note = note
+		return nil
+	}
+	byTag[tag] = &EntityTag{
+		EntityID:  entityID,
+		Tag:       tag,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		CreatedBy: createdBy,
+		Note:      note,
+	}
+	return nil
+}
+
+// RemoveTag removes a tag from an entity.
+// Returns sql.ErrNoRows if the tag does not exist.
+func (s *MemoryStore) RemoveTag(entityID, tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[entityID][tag]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.tags[entityID], tag)
+	if len(s.tags[entityID]) == 0 {
+		delete(s.tags, entityID)
+	}
+	return nil
+}
+
+// GetTags returns the tags on an entity ordered by tag.
+func (s *MemoryStore) GetTags(entityID string) ([]*EntityTag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tags []*EntityTag
+	for _, t := range s.tags[entityID] {
+		c := *t
+		tags = append(tags, &c)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags, nil
+}
+
+// FindByTag returns entities that carry the tag.
+func (s *MemoryStore) FindByTag(tag string) ([]*Entity, error) {
+	return s.FindByTags([]string{tag}, false)
+}
+
+// FindByTags returns entities carrying any of the tags, or all of them when
+// matchAll is set.
+func (s *MemoryStore) FindByTags(tags []string, matchAll bool) ([]*Entity, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*Entity
+	for entityID, byTag := range s.tags {
+		e, ok := s.entities[entityID]
+		if !ok {
+			continue
+		}
+		hits := 0
+		for _, tag := range tags {
+			if _, ok := byTag[tag]; ok {
+				hits++
+			}
+		}
+		if hits == 0 || (matchAll && hits < len(tags)) {
+			continue
+		}
+		result = append(result, copyEntity(e))
+	}
+	sortEntities(result)
+	return result, nil
+}
+
+// ListAllTags returns every tag with the number of entities carrying it.
+func (s *MemoryStore) ListAllTags() (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]int)
+	for _, byTag := range s.tags {
+		for tag := range byTag {
+			result[tag]++
+		}
+	}
+	return result, nil
+}
+
+// DeleteTagsForEntity removes all tags from an entity.
+func (s *MemoryStore) DeleteTagsForEntity(entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tags, entityID)
+	return nil
+}
+
+// CountTags returns the total number of entity tags.
+func (s *MemoryStore) CountTags() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, byTag := range s.tags {
+		count += len(byTag)
+	}
+	return count, nil
+}
+
+// GetAllTagsWithEntity returns every tag with its entity's name, ordered by
+// entity ID and tag.
+func (s *MemoryStore) GetAllTagsWithEntity() ([]*EntityTagWithName, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tags []*EntityTagWithName
+	for entityID, byTag := range s.tags {
+		name := ""
+		if e, ok := s.entities[entityID]; ok {
+			name = e.Name
+		}
+		for _, t := range byTag {
+			tags = append(tags, &EntityTagWithName{
+				EntityID: entityID, EntityName: name, Tag: t.Tag, Note: t.Note, CreatedBy: t.CreatedBy,
+			})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].EntityID != tags[j].EntityID {
+			return tags[i].EntityID < tags[j].EntityID
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// --- links ---
+
+// CreateLink links an entity to an external system, replacing an identical link.
+func (s *MemoryStore) CreateLink(link *EntityLink) error {
+	if link.LinkType == "" {
+		link.LinkType = "related"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *link
+	c.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	s.links[linkKey(link.EntityID, link.ExternalSystem, link.ExternalID)] = &c
+	return nil
+}
+
+// GetLinks returns the links of an entity ordered by system and external ID.
+func (s *MemoryStore) GetLinks(entityID string) ([]*EntityLink, error) {
+	return s.selectLinks(func(l *EntityLink) bool { return l.EntityID == entityID }), nil
+}
+
+// GetLinksByExternalID returns the entities linked to an external item.
+func (s *MemoryStore) GetLinksByExternalID(externalSystem, externalID string) ([]*EntityLink, error) {
+	return s.selectLinks(func(l *EntityLink) bool {
+		return l.ExternalSystem == externalSystem && l.ExternalID == externalID
+	}), nil
+}
+
+// GetLinksBySystem returns all links to an external system.
+func (s *MemoryStore) GetLinksBySystem(externalSystem string) ([]*EntityLink, error) {
+	return s.selectLinks(func(l *EntityLink) bool { return l.ExternalSystem == externalSystem }), nil
+}
+
+// selectLinks returns copies of the links accepted by keep, ordered by
+// entity, system and external ID.
+func (s *MemoryStore) selectLinks(keep func(*EntityLink) bool) []*EntityLink {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var links []*EntityLink
+	for _, l := range s.links {
+		if keep(l) {
+			c := *l
+			links = append(links, &c)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].EntityID != links[j].EntityID {
+			return links[i].EntityID < links[j].EntityID
+		}
+		if links[i].ExternalSystem != links[j].ExternalSystem {
+			return links[i].ExternalSystem < links[j].ExternalSystem
+		}
+		return links[i].ExternalID < links[j].ExternalID
+	})
+	return links
+}
+
+// DeleteLink removes a specific link.
+func (s *MemoryStore) DeleteLink(entityID, externalSystem, externalID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.links, linkKey(entityID, externalSystem, externalID))
+	return nil
+}
+
+// DeleteLinksForEntity removes all links of an entity.
+func (s *MemoryStore) DeleteLinksForEntity(entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.links {
+		if k.entityID == entityID {
+			delete(s.links, k)
+		}
+	}
+	return nil
+}
+
+// CountLinks returns the total number of links.
+func (s *MemoryStore) CountLinks() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.links), nil
+}
+
+// --- coverage ---
+
+// SaveCoverage stores coverage data for an entity, replacing any existing data.
+func (s *MemoryStore) SaveCoverage(cov *EntityCoverage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.coverage[cov.EntityID] = copyCoverage(cov)
+	return nil
+}
+
+// GetCoverage retrieves coverage data for a specific entity.
+// Returns sql.ErrNoRows if the entity has no coverage data.
+func (s *MemoryStore) GetCoverage(entityID string) (*EntityCoverage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cov, ok := s.coverage[entityID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return copyCoverage(cov), nil
+}
+
+// GetAllCoverage retrieves coverage data for every entity that has it.
+func (s *MemoryStore) GetAllCoverage() ([]*EntityCoverage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]*EntityCoverage, 0, len(s.coverage))
+	for _, cov := range s.coverage {
+		results = append(results, copyCoverage(cov))
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].EntityID < results[j].EntityID })
+	return results, nil
+}
+
+// AddTestMapping records that a test covers an entity.
+func (s *MemoryStore) AddTestMapping(testFile, testName, entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.testMappings[TestMapping{TestFile: testFile, TestName: testName, EntityID: entityID}] = true
+	return nil
+}
+
+// GetTestMappings returns test to entity mappings matching the filter,
+// ordered by test file, test name and entity ID.
+func (s *MemoryStore) GetTestMappings(filter TestMappingFilter) ([]*TestMapping, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var mappings []*TestMapping
+	for m := range s.testMappings {
+		if filter.EntityID != "" && m.EntityID != filter.EntityID {
+			continue
+		}
+		if filter.TestName != "" && m.TestName != filter.TestName {
+			continue
+		}
+		if filter.TestFile != "" && m.TestFile != filter.TestFile {
+			continue
+		}
+		c := m
+		mappings = append(mappings, &c)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		a, b := mappings[i], mappings[j]
+		if a.TestFile != b.TestFile {
+			return a.TestFile < b.TestFile
+		}
+		if a.TestName != b.TestName {
+			return a.TestName < b.TestName
+		}
+		return a.EntityID < b.EntityID
+	})
+	return mappings, nil
+}
+
+// ClearTestMappings removes all test to entity mappings.
+func (s *MemoryStore) ClearTestMappings() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.testMappings = make(map[TestMapping]bool)
+	return nil
+}
+
+// --- embeddings ---
+
+// SaveEmbedding stores an embedding for an entity, replacing any existing one.
+func (s *MemoryStore) SaveEmbedding(entityID string, embedding []float32, modelVersion, contentHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.embeddings[entityID] = &EntityEmbedding{
+		EntityID:     entityID,
+		Embedding:    append([]float32(nil), embedding...),
+		ModelVersion: modelVersion,
+		ContentHash:  contentHash,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+	}
+	return nil
+}
+
+// GetEmbedding retrieves the embedding for an entity.
+// Returns sql.ErrNoRows if the entity has no embedding.
+func (s *MemoryStore) GetEmbedding(entityID string) (*EntityEmbedding, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.embeddings[entityID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return copyEmbedding(e), nil
+}
+
+// GetAllEmbeddings retrieves all embeddings.
+func (s *MemoryStore) GetAllEmbeddings() ([]*EntityEmbedding, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]*EntityEmbedding, 0, len(s.embeddings))
+	for _, e := range s.embeddings {
+		results = append(results, copyEmbedding(e))
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].EntityID < results[j].EntityID })
+	return results, nil
+}
+
+// DeleteEmbedding removes an embedding.
+func (s *MemoryStore) DeleteEmbedding(entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.embeddings, entityID)
+	return nil
+}
+
+// FindSimilar finds the top-K most similar entities to a query vector using cosine similarity.
+func (s *MemoryStore) FindSimilar(queryVec []float32, limit int) ([]SimilarityResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]SimilarityResult, 0, len(s.embeddings))
+	for _, e := range s.embeddings {
+		results = append(results, SimilarityResult{
+			EntityID:   e.EntityID,
+			Similarity: cosineSimilarity(queryVec, e.Embedding),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Similarity != results[j].Similarity {
+			return results[i].Similarity > results[j].Similarity
+		}
+		return results[i].EntityID < results[j].EntityID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// NeedsEmbedding returns IDs of active entities that have no embedding or
+// were embedded with a different model version.
+func (s *MemoryStore) NeedsEmbedding(modelVersion string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []string
+	for id, e := range s.entities {
+		if e.Status != "active" {
+			continue
+		}
+		if emb, ok := s.embeddings[id]; !ok || emb.ModelVersion != modelVersion {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// EmbeddingCount returns the number of stored embeddings.
+func (s *MemoryStore) EmbeddingCount() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.embeddings), nil
+}
+
+// GetEmbeddingAt is not supported: the in-memory store keeps no history.
+func (s *MemoryStore) GetEmbeddingAt(entityID, ref string) (*EntityEmbedding, error) {
+	return nil, errNoHistory
+}
+
+// --- file index ---
+
+// SetFileScanned records that a file has been scanned with the given hash.
+func (s *MemoryStore) SetFileScanned(path, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[path] = &FileIndex{
+		FilePath:  path,
+		ScanHash:  hash,
+		ScannedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	return nil
+}
+
+// GetFileEntry retrieves the scan state of a file.
+// Returns sql.ErrNoRows if the file has not been scanned.
+func (s *MemoryStore) GetFileEntry(path string) (*FileIndex, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.files[path]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	c := *f
+	return &c, nil
+}
+
+// GetAllFileEntries retrieves all file entries ordered by path.
+func (s *MemoryStore) GetAllFileEntries() ([]*FileIndex, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]*FileIndex, 0, len(s.files))
+	for _, f := range s.files {
+		c := *f
+		entries = append(entries, &c)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].FilePath < entries[j].FilePath })
+	return entries, nil
+}
+
+// DeleteFileEntry removes a file from the index.
+func (s *MemoryStore) DeleteFileEntry(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files, path)
+	return nil
+}
+
+// --- history (unsupported) ---
+
+// GetEntityAt is not supported: the in-memory store keeps no history.
+func (s *MemoryStore) GetEntityAt(id string, ref string) (*Entity, error) {
+	return nil, errNoHistory
+}
+
+// QueryEntitiesAt is not supported: the in-memory store keeps no history.
+func (s *MemoryStore) QueryEntitiesAt(filter EntityFilter, ref string) ([]*Entity, error) {
+	return nil, errNoHistory
+}
+
+// GetDependenciesAt is not supported: the in-memory store keeps no history.
+func (s *MemoryStore) GetDependenciesAt(filter DependencyFilter, ref string) ([]*Dependency, error) {
+	return nil, errNoHistory
+}
+
+// EntityHistory is not supported: the in-memory store keeps no history.
+func (s *MemoryStore) EntityHistory(opts EntityHistoryOptions) ([]EntityHistoryEntry, error) {
+	return nil, errNoHistory
+}
+
+// DependencyHistory is not supported: the in-memory store keeps no history.
+func (s *MemoryStore) DependencyHistory(opts DependencyHistoryOptions) ([]DependencyHistoryEntry, error) {
+	return nil, errNoHistory
+}
+
+// --- helpers ---
+
+// depID identifies a dependency, mirroring the dependencies primary key.
+type depID struct{ fromID, toID, depType string }
+
+func depKey(fromID, toID, depType string) depID {
+	return depID{fromID, toID, depType}
+}
+
+// linkID identifies a link, mirroring the entity_links primary key.
+type linkID struct{ entityID, externalSystem, externalID string }
+
+func linkKey(entityID, externalSystem, externalID string) linkID {
+	return linkID{entityID, externalSystem, externalID}
+}
+
+// matchEntityFilter reports whether e satisfies filter, using the same
+// prefix, suffix and substring semantics as SQLStore's LIKE clauses.
+func matchEntityFilter(e *Entity, filter EntityFilter) bool {
+	if filter.EntityType != "" && e.EntityType != filter.EntityType {
+		return false
+	}
+	if filter.Status != "" && e.Status != filter.Status {
+		return false
+	}
+	if filter.FilePath != "" && !strings.HasPrefix(e.FilePath, filter.FilePath) {
+		return false
+	}
+	if filter.FilePathSuffix != "" && !strings.HasSuffix(e.FilePath, filter.FilePathSuffix) {
+		return false
+	}
+	if filter.Name != "" && !strings.Contains(strings.ToLower(e.Name), strings.ToLower(filter.Name)) {
+		return false
+	}
+	if filter.Language != "" && e.Language != filter.Language {
+		return false
+	}
+	return true
+}
+
+// sortEntities orders entities by file path, then line, then ID.
+func sortEntities(entities []*Entity) {
+	sort.Slice(entities, func(i, j int) bool {
+		a, b := entities[i], entities[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		if a.LineStart != b.LineStart {
+			return a.LineStart < b.LineStart
+		}
+		return a.ID < b.ID
+	})
+}
+
+func copyEntity(e *Entity) *Entity {
+	c := *e
+	if e.LineEnd != nil {
+		v := *e.LineEnd
+		c.LineEnd = &v
+	}
+	return &c
+}
+
+func copyCoverage(cov *EntityCoverage) *EntityCoverage {
+	c := *cov
+	c.CoveredLines = append([]int(nil), cov.CoveredLines...)
+	c.UncoveredLines = append([]int(nil), cov.UncoveredLines...)
+	return &c
+}
+
+func copyEmbedding(e *EntityEmbedding) *EntityEmbedding {
+	c := *e
+	c.Embedding = append([]float32(nil), e.Embedding...)
+	return &c
+}
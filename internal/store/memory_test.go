@@ -0,0 +1,176 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestMemoryStoreEntities(t *testing.T) {
+	s := NewMemoryStore()
+
+	entities := []*Entity{
+		{ID: "fn-b", Name: "RateLimit", EntityType: "function", FilePath: "http/limit.go", LineStart: 5,
+			BodyText: "token bucket"},
+		{ID: "fn-a", Name: "LoginUser", EntityType: "function", FilePath: "auth/login.go", LineStart: 10,
+			BodyText: "validate password and create session"},
+		{ID: "fn-t", Name: "TestLoginUser", EntityType: "function", FilePath: "auth/login_test.go", LineStart: 3},
+	}
+	if err := s.CreateEntitiesBulk(entities); err != nil {
+		t.Fatalf("create entities: %v", err)
+	}
+	if err := s.CreateEntity(entities[0]); err == nil {
+		t.Error("CreateEntity with an existing ID should fail")
+	}
+
+	got, err := s.QueryEntities(EntityFilter{FilePath: "auth/"})
+	if err != nil || len(got) != 2 || got[0].ID != "fn-a" || got[0].Language != "go" || got[0].Status != "active" {
+		t.Fatalf("QueryEntities(auth/) = %+v, %v", got, err)
+	}
+	got, _ = s.QueryEntities(EntityFilter{Name: "login", FilePathSuffix: "_test.go"})
+	if len(got) != 1 || got[0].ID != "fn-t" {
+		t.Errorf("QueryEntities(name, suffix) = %+v, want fn-t", got)
+	}
+
+	// Returned entities are copies
+	got[0].Name = "Mutated"
+	if e, _ := s.GetEntity("fn-t"); e.Name != "TestLoginUser" {
+		t.Errorf("store was modified through a returned entity: %q", e.Name)
+	}
+
+	if err := s.UpdateEntity(&Entity{ID: "fn-b", BodyText: "sliding window password throttle"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	results, err := s.SearchEntities(SearchOptions{Query: "password"})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("search = %+v, %v; want 2 results", results, err)
+	}
+
+	if err := s.ArchiveEntity("fn-b"); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if n, _ := s.CountEntities(EntityFilter{Status: "active"}); n != 2 {
+		t.Errorf("active count = %d, want 2", n)
+	}
+	if _, err := s.GetEntity("missing"); err != sql.ErrNoRows {
+		t.Errorf("GetEntity(missing) error = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.DeleteEntity("missing"); err != sql.ErrNoRows {
+		t.Errorf("DeleteEntity(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryStoreDependenciesAndMetrics(t *testing.T) {
+	s := NewMemoryStore()
+	s.CreateEntity(&Entity{ID: "a", Name: "A", FilePath: "a.go"})
+	s.CreateEntity(&Entity{ID: "b", Name: "B", FilePath: "b.go"})
+
+	deps := []*Dependency{
+		{FromID: "a", ToID: "b", DepType: "calls"},
+		{FromID: "a", ToID: "b", DepType: "calls"},
+		{FromID: "b", ToID: "c", DepType: "uses_type"},
+	}
+	if err := s.CreateDependenciesBulk(deps); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.CountDependencies(); n != 2 {
+		t.Errorf("CountDependencies = %d, want 2 (duplicates replaced)", n)
+	}
+	to, _ := s.GetDependenciesTo("b")
+	if len(to) != 1 || to[0].FromID != "a" {
+		t.Errorf("GetDependenciesTo(b) = %+v", to)
+	}
+	if err := s.DeleteDependenciesByFile("b.go"); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := s.GetAllDependencies(); len(all) != 1 {
+		t.Errorf("after DeleteDependenciesByFile: %d deps, want 1", len(all))
+	}
+
+	s.SaveBulkMetrics([]*Metrics{
+		{EntityID: "a", PageRank: 0.2, InDegree: 0, Betweenness: 0.5},
+		{EntityID: "b", PageRank: 0.6, InDegree: 1},
+	})
+	top, _ := s.GetTopByPageRank(1)
+	if len(top) != 1 || top[0].EntityID != "b" {
+		t.Errorf("GetTopByPageRank(1) = %+v, want b", top)
+	}
+	if ks, _ := s.GetKeystones(0.3); len(ks) != 1 || ks[0].EntityID != "b" {
+		t.Errorf("GetKeystones(0.3) = %+v, want b", ks)
+	}
+	if _, err := s.GetMetrics("c"); err != sql.ErrNoRows {
+		t.Errorf("GetMetrics(c) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryStoreTagsLinksCoverage(t *testing.T) {
+	s := NewMemoryStore()
+	s.CreateEntity(&Entity{ID: "a", Name: "A", FilePath: "a.go"})
+	s.CreateEntity(&Entity{ID: "b", Name: "B", FilePath: "b.go"})
+
+	s.AddTagWithNote("a", "critical", "agent", "first")
+	s.AddTagWithNote("a", "critical", "user", "second")
+	s.AddTag("a", "auth", "user")
+	s.AddTag("b", "auth", "user")
+
+	tags, _ := s.GetTags("a")
+	if len(tags) != 2 || tags[0].Tag != "auth" || tags[1].Note != "second" {
+		t.Errorf("GetTags(a) = %+v", tags)
+	}
+	if both, _ := s.FindByTags([]string{"auth", "critical"}, true); len(both) != 1 || both[0].ID != "a" {
+		t.Errorf("FindByTags(matchAll) = %+v, want a", both)
+	}
+	if counts, _ := s.ListAllTags(); counts["auth"] != 2 || counts["critical"] != 1 {
+		t.Errorf("ListAllTags = %v", counts)
+	}
+	if err := s.RemoveTag("b", "missing"); err != sql.ErrNoRows {
+		t.Errorf("RemoveTag(missing) error = %v, want sql.ErrNoRows", err)
+	}
+
+	s.CreateLink(&EntityLink{EntityID: "a", ExternalSystem: "github", ExternalID: "42"})
+	links, _ := s.GetLinksByExternalID("github", "42")
+	if len(links) != 1 || links[0].LinkType != "related" {
+		t.Errorf("GetLinksByExternalID = %+v", links)
+	}
+
+	if err := s.SaveCoverage(&EntityCoverage{EntityID: "a", CoveragePercent: 75, CoveredLines: []int{1, 2, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	cov, err := s.GetCoverage("a")
+	if err != nil || cov.CoveragePercent != 75 || len(cov.CoveredLines) != 3 {
+		t.Errorf("GetCoverage(a) = %+v, %v", cov, err)
+	}
+	if _, err := s.GetCoverage("b"); err != sql.ErrNoRows {
+		t.Errorf("GetCoverage(b) error = %v, want sql.ErrNoRows", err)
+	}
+
+	s.AddTestMapping("a_test.go", "TestA", "a")
+	s.AddTestMapping("a_test.go", "TestA", "a")
+	s.AddTestMapping("a_test.go", "TestAB", "b")
+	if m, _ := s.GetTestMappings(TestMappingFilter{EntityID: "a"}); len(m) != 1 || m[0].TestName != "TestA" {
+		t.Errorf("GetTestMappings(a) = %+v", m)
+	}
+	s.ClearTestMappings()
+	if m, _ := s.GetTestMappings(TestMappingFilter{}); len(m) != 0 {
+		t.Errorf("after clear: %d mappings", len(m))
+	}
+}
+
+func TestMemoryStoreEmbeddings(t *testing.T) {
+	s := NewMemoryStore()
+	s.CreateEntity(&Entity{ID: "a", Name: "A"})
+	s.CreateEntity(&Entity{ID: "b", Name: "B"})
+
+	s.SaveEmbedding("a", []float32{1, 0}, "m1", "h1")
+	s.SaveEmbedding("b", []float32{0, 1}, "m0", "h2")
+
+	similar, _ := s.FindSimilar([]float32{0.9, 0.1}, 1)
+	if len(similar) != 1 || similar[0].EntityID != "a" {
+		t.Errorf("FindSimilar = %+v, want a", similar)
+	}
+	if ids, _ := s.NeedsEmbedding("m1"); len(ids) != 1 || ids[0] != "b" {
+		t.Errorf("NeedsEmbedding(m1) = %v, want [b]", ids)
+	}
+	if _, err := s.GetEmbeddingAt("a", "HEAD"); err == nil {
+		t.Error("GetEmbeddingAt should report that history is unavailable")
+	}
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// metricHistoryTable snapshots compact per-entity metrics for each scan, so
+// trends do not need a Dolt AS OF query per entity. Entities are identified
+// by file and name as well as ID, since IDs change when code moves.
+const metricHistoryTable = `CREATE TABLE IF NOT EXISTS metric_history (
+    scan_id INT NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+    package VARCHAR(500) NOT NULL,
+    pagerank DOUBLE NOT NULL,
+    fan_in INT NOT NULL,
+    fan_out INT NOT NULL,
+    loc INT NOT NULL,
+    coverage DOUBLE,
+    PRIMARY KEY (scan_id, entity_id)
+)`
+
+// MetricHistoryRetention is how many of the most recent scans keep their
+// metric snapshots. Older ones are pruned as new scans are recorded.
+const MetricHistoryRetention = 100
+
+// HistoryMetrics are the metrics a trend can follow
+var HistoryMetrics = []string{"pagerank", "coverage", "fan_in", "fan_out", "loc"}
+
+// historyAggregates sums each metric over the rows of a series. Coverage is
+// weighted by lines of code over the entities that have coverage.
+var historyAggregates = map[string]string{
+	"pagerank": "SUM(h.pagerank)",
+	"fan_in":   "SUM(h.fan_in)",
+	"fan_out":  "SUM(h.fan_out)",
+	"loc":      "SUM(h.loc)",
+	"coverage": "SUM(h.coverage * h.loc) / SUM(CASE WHEN h.coverage IS NULL THEN NULL ELSE h.loc END)",
+}
+
+// MetricSnapshot holds the metrics of one entity at a scan
+type MetricSnapshot struct {
+	EntityID string
+	Name     string
+	FilePath string
+	Package  string
+	PageRank float64
+	FanIn    int
+	FanOut   int
+	LOC      int
+	Coverage *float64 // percent; nil without coverage data
+}
+
+// HistoryScope selects the snapshots a series aggregates: one entity by file
+// and name, one package, or the whole repository when empty.
+type HistoryScope struct {
+	FilePath string
+	Name     string
+	Package  string
+}
+
+// MetricPoint is the value of a metric at one scan
+type MetricPoint struct {
+	ScanID    int
+	GitCommit string
+	ScanTime  time.Time
+	Value     float64
+}
+
+// SaveMetricHistory replaces the metric snapshots recorded for a scan.
+func (s *SQLStore) SaveMetricHistory(scanID int, snapshots []MetricSnapshot) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM metric_history WHERE scan_id = ?`, scanID); err != nil {
+		return fmt.Errorf("clear metric history: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO metric_history (scan_id, entity_id, name, file_path, package,
+		pagerank, fan_in, fan_out, loc, coverage) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+	for _, m := range snapshots {
+		var coverage sql.NullFloat64
+		if m.Coverage != nil {
+			coverage = sql.NullFloat64{Float64: *m.Coverage, Valid: true}
+		}
+		_, err := stmt.Exec(scanID, m.EntityID, m.Name, m.FilePath, m.Package,
+			m.PageRank, m.FanIn, m.FanOut, m.LOC, coverage)
+		if err != nil {
+			return fmt.Errorf("insert metric history: %w", err)
+		}
+	}
+	if err := pruneMetricHistory(tx, MetricHistoryRetention); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// pruneMetricHistory deletes the snapshots of all but the keep most recent scans
+func pruneMetricHistory(tx *sql.Tx, keep int) error {
+	var oldest sql.NullInt64
+	err := tx.QueryRow(`SELECT scan_id FROM (SELECT DISTINCT scan_id FROM metric_history) AS scans
+		ORDER BY scan_id DESC LIMIT 1 OFFSET ?`, keep-1).Scan(&oldest)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("find oldest metric history: %w", err)
+	}
+	if !oldest.Valid {
+		return nil
+	}
+	if _, err := tx.Exec(`DELETE FROM metric_history WHERE scan_id < ?`, oldest.Int64); err != nil {
+		return fmt.Errorf("prune metric history: %w", err)
+	}
+	return nil
+}
+
+// GetMetricSeries returns a metric over the scans with snapshots in scope,
+// oldest first. Scans where the metric has no value (coverage before any
+// was imported) are left out.
+func (s *SQLStore) GetMetricSeries(metric string, scope HistoryScope) ([]MetricPoint, error) {
+	agg, ok := historyAggregates[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q: must be one of %v", metric, HistoryMetrics)
+	}
+	where, args := "1 = 1", []any{}
+	switch {
+	case scope.FilePath != "":
+		where, args = "h.file_path = ? AND h.name = ?", []any{scope.FilePath, scope.Name}
+	case scope.Package != "":
+		where, args = "h.package = ?", []any{scope.Package}
+	}
+
+	rows, err := s.db.Query(`SELECT h.scan_id, m.git_commit, m.scan_time, `+agg+`
+		FROM metric_history h JOIN scan_metadata m ON m.id = h.scan_id
+		WHERE `+where+`
+		GROUP BY h.scan_id, m.git_commit, m.scan_time
+		ORDER BY h.scan_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query metric history: %w", err)
+	}
+	defer rows.Close()
+
+	var series []MetricPoint
+	for rows.Next() {
+		var p MetricPoint
+		var commit, scanTime sql.NullString
+		var value sql.NullFloat64
+		if err := rows.Scan(&p.ScanID, &commit, &scanTime, &value); err != nil {
+			return nil, fmt.Errorf("scan metric history: %w", err)
+		}
+		if !value.Valid {
+			continue
+		}
+		p.GitCommit, p.Value = commit.String, value.Float64
+		if p.ScanTime, err = time.Parse("2006-01-02 15:04:05", scanTime.String); err != nil {
+			p.ScanTime, _ = time.Parse(time.RFC3339, scanTime.String)
+		}
+		series = append(series, p)
+	}
+	return series, rows.Err()
+}
+
+// HasHistoryPackage reports whether any snapshot belongs to the package.
+func (s *SQLStore) HasHistoryPackage(pkg string) (bool, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM metric_history WHERE package = ?`, pkg).Scan(&n); err != nil {
+		return false, fmt.Errorf("query metric history: %w", err)
+	}
+	return n > 0, nil
+}
+
+// IsHistoryMetric reports whether metric can be followed by a trend.
+func IsHistoryMetric(metric string) bool {
+	return slices.Contains(HistoryMetrics, metric)
+}
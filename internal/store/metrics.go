@@ -8,7 +8,7 @@ import (
 
 // SaveMetrics stores metrics for a single entity.
 // If metrics for this entity already exist, they are replaced.
-func (s *Store) SaveMetrics(m *Metrics) error {
+func (s *SQLStore) SaveMetrics(m *Metrics) error {
 	_, err := s.db.Exec(`
 		REPLACE INTO metrics
 		(entity_id, pagerank, in_degree, out_degree, betweenness, computed_at)
@@ -24,7 +24,7 @@ func (s *Store) SaveMetrics(m *Metrics) error {
 
 // GetMetrics retrieves metrics for a specific entity.
 // Returns sql.ErrNoRows if the entity is not found.
-func (s *Store) GetMetrics(entityID string) (*Metrics, error) {
+func (s *SQLStore) GetMetrics(entityID string) (*Metrics, error) {
 	row := s.db.QueryRow(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics WHERE entity_id = ?`, entityID)
@@ -40,7 +40,7 @@ func (s *Store) GetMetrics(entityID string) (*Metrics, error) {
 }
 
 // GetAllMetrics retrieves all cached metrics.
-func (s *Store) GetAllMetrics() ([]*Metrics, error) {
+func (s *SQLStore) GetAllMetrics() ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics ORDER BY pagerank DESC`)
@@ -53,7 +53,7 @@ func (s *Store) GetAllMetrics() ([]*Metrics, error) {
 }
 
 // GetTopByPageRank returns the top N entities by PageRank score.
-func (s *Store) GetTopByPageRank(n int) ([]*Metrics, error) {
+func (s *SQLStore) GetTopByPageRank(n int) ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics ORDER BY pagerank DESC LIMIT ?`, n)
@@ -66,7 +66,7 @@ func (s *Store) GetTopByPageRank(n int) ([]*Metrics, error) {
 }
 
 // GetTopByBetweenness returns the top N entities by betweenness centrality.
-func (s *Store) GetTopByBetweenness(n int) ([]*Metrics, error) {
+func (s *SQLStore) GetTopByBetweenness(n int) ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics ORDER BY betweenness DESC LIMIT ?`, n)
@@ -79,7 +79,7 @@ func (s *Store) GetTopByBetweenness(n int) ([]*Metrics, error) {
 }
 
 // GetTopByInDegree returns the top N entities by in-degree (most depended upon).
-func (s *Store) GetTopByInDegree(n int) ([]*Metrics, error) {
+func (s *SQLStore) GetTopByInDegree(n int) ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics ORDER BY in_degree DESC LIMIT ?`, n)
@@ -92,7 +92,7 @@ func (s *Store) GetTopByInDegree(n int) ([]*Metrics, error) {
 }
 
 // GetTopByOutDegree returns the top N entities by out-degree (most dependencies).
-func (s *Store) GetTopByOutDegree(n int) ([]*Metrics, error) {
+func (s *SQLStore) GetTopByOutDegree(n int) ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics ORDER BY out_degree DESC LIMIT ?`, n)
@@ -106,7 +106,7 @@ func (s *Store) GetTopByOutDegree(n int) ([]*Metrics, error) {
 
 // GetKeystones returns entities with PageRank >= threshold.
 // These are the central, highly-connected entities in the codebase.
-func (s *Store) GetKeystones(threshold float64) ([]*Metrics, error) {
+func (s *SQLStore) GetKeystones(threshold float64) ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics WHERE pagerank >= ? ORDER BY pagerank DESC`, threshold)
@@ -120,7 +120,7 @@ func (s *Store) GetKeystones(threshold float64) ([]*Metrics, error) {
 
 // GetBottlenecks returns entities with betweenness >= threshold.
 // These are entities that many paths flow through, making them critical points.
-func (s *Store) GetBottlenecks(threshold float64) ([]*Metrics, error) {
+func (s *SQLStore) GetBottlenecks(threshold float64) ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics WHERE betweenness >= ? ORDER BY betweenness DESC`, threshold)
@@ -134,7 +134,7 @@ func (s *Store) GetBottlenecks(threshold float64) ([]*Metrics, error) {
 
 // GetHighlyConnected returns entities with in_degree >= threshold.
 // These are entities that many other entities depend on.
-func (s *Store) GetHighlyConnected(threshold int) ([]*Metrics, error) {
+func (s *SQLStore) GetHighlyConnected(threshold int) ([]*Metrics, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, pagerank, in_degree, out_degree, betweenness, computed_at
 		FROM metrics WHERE in_degree >= ? ORDER BY in_degree DESC`, threshold)
@@ -147,7 +147,7 @@ func (s *Store) GetHighlyConnected(threshold int) ([]*Metrics, error) {
 }
 
 // SaveBulkMetrics saves multiple metrics efficiently using a transaction.
-func (s *Store) SaveBulkMetrics(metrics []*Metrics) error {
+func (s *SQLStore) SaveBulkMetrics(metrics []*Metrics) error {
 	if len(metrics) == 0 {
 		return nil
 	}
@@ -183,7 +183,7 @@ func (s *Store) SaveBulkMetrics(metrics []*Metrics) error {
 }
 
 // DeleteMetrics removes metrics for a specific entity.
-func (s *Store) DeleteMetrics(entityID string) error {
+func (s *SQLStore) DeleteMetrics(entityID string) error {
 	_, err := s.db.Exec("DELETE FROM metrics WHERE entity_id = ?", entityID)
 	if err != nil {
 		return fmt.Errorf("delete metrics for %s: %w", entityID, err)
@@ -192,7 +192,7 @@ func (s *Store) DeleteMetrics(entityID string) error {
 }
 
 // ClearMetrics removes all cached metrics.
-func (s *Store) ClearMetrics() error {
+func (s *SQLStore) ClearMetrics() error {
 	_, err := s.db.Exec("DELETE FROM metrics")
 	if err != nil {
 		return fmt.Errorf("clear metrics: %w", err)
@@ -201,7 +201,7 @@ func (s *Store) ClearMetrics() error {
 }
 
 // CountMetrics returns the number of entities with cached metrics.
-func (s *Store) CountMetrics() (int, error) {
+func (s *SQLStore) CountMetrics() (int, error) {
 	var count int
 	err := s.db.QueryRow("SELECT COUNT(*) FROM metrics").Scan(&count)
 	if err != nil {
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// metricsRunsTable records each computation of the graph metrics, so outputs
+// can say how fresh PageRank and betweenness are.
+const metricsRunsTable = `CREATE TABLE IF NOT EXISTS metrics_runs (
+    computed_at VARCHAR(40) NOT NULL PRIMARY KEY,
+    scan_id INT NOT NULL,
+    mode VARCHAR(16) NOT NULL,
+    entities INT NOT NULL,
+    updated INT NOT NULL,
+    iterations INT NOT NULL,
+    betweenness VARCHAR(16) NOT NULL,
+    pivots INT NOT NULL,
+    duration_ms INT NOT NULL
+)`
+
+// MetricsRun describes one computation of the graph metrics
+type MetricsRun struct {
+	ComputedAt  time.Time
+	ScanID      int    // latest scan the metrics reflect
+	Mode        string // full or incremental (PageRank)
+	Entities    int
+	Updated     int    // nodes whose PageRank was recomputed
+	Iterations  int    // PageRank iterations
+	Betweenness string // exact or sampled
+	Pivots      int    // sampled sources when Betweenness is sampled
+	DurationMs  int
+}
+
+// SaveMetricsRun records a metrics computation.
+func (s *SQLStore) SaveMetricsRun(run *MetricsRun) error {
+	_, err := s.db.Exec(`INSERT INTO metrics_runs
+		(computed_at, scan_id, mode, entities, updated, iterations, betweenness, pivots, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) `+
+		s.onConflictUpdate([]string{"computed_at"},
+			[]string{"scan_id", "mode", "entities", "updated", "iterations", "betweenness", "pivots", "duration_ms"}),
+		run.ComputedAt.UTC().Format(time.RFC3339), run.ScanID, run.Mode, run.Entities, run.Updated,
+		run.Iterations, run.Betweenness, run.Pivots, run.DurationMs)
+	if err != nil {
+		return fmt.Errorf("save metrics run: %w", err)
+	}
+	return nil
+}
+
+// GetLatestMetricsRun returns the most recent metrics computation, or nil if
+// none was recorded.
+func (s *SQLStore) GetLatestMetricsRun() (*MetricsRun, error) {
+	run := &MetricsRun{}
+	var computedAt string
+	err := s.db.QueryRow(`SELECT computed_at, scan_id, mode, entities, updated, iterations, betweenness, pivots, duration_ms
+		FROM metrics_runs ORDER BY computed_at DESC LIMIT 1`).Scan(&computedAt, &run.ScanID, &run.Mode,
+		&run.Entities, &run.Updated, &run.Iterations, &run.Betweenness, &run.Pivots, &run.DurationMs)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query metrics run: %w", err)
+	}
+	run.ComputedAt, _ = time.Parse(time.RFC3339, computedAt)
+	return run, nil
+}
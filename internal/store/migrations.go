@@ -0,0 +1,257 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when opening a database whose schema was
+// written by a newer cx than the one running.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of cx supports")
+
+// Migration is one ordered, versioned change to the database schema.
+//
+// Migrations are applied in Version order when the store is opened, and each
+// applied version is recorded in the schema_version table. On Dolt every
+// migration is followed by its own commit so schema changes show up in
+// cx history. A migration must be safe to re-run: if cx stops between
+// applying it and recording it, it runs again on the next open.
+type Migration struct {
+	Version     int
+	Description string
+	up          func(s *SQLStore) error
+}
+
+// MigrationStatus reports whether a migration has been applied to a database.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt string // RFC3339, empty if pending
+}
+
+// migrations lists every schema migration in version order.
+// Append new migrations to the end; never edit or renumber released ones.
+var migrations = []Migration{
+	{Version: 1, Description: "initial schema", up: migrateInitialSchema},
+	{Version: 2, Description: "store embeddings as binary float32", up: migrateBinaryEmbeddings},
+	{Version: 3, Description: "record embedding provider in model_version", up: migrateEmbeddingProviders},
+	{Version: 4, Description: "add dependencies.optional", up: func(s *SQLStore) error {
+		return s.addColumn("dependencies", "optional", "BOOLEAN NOT NULL DEFAULT FALSE")
+	}},
+	{Version: 5, Description: "add dependencies.source", up: func(s *SQLStore) error {
+		return s.addColumn("dependencies", "source", "VARCHAR(16) NOT NULL DEFAULT 'treesitter'")
+	}},
+	{Version: 6, Description: "add cycle_history", up: func(s *SQLStore) error {
+		_, err := s.db.Exec(cycleHistoryTable)
+		return err
+	}},
+	{Version: 7, Description: "add package_metrics", up: func(s *SQLStore) error {
+		_, err := s.db.Exec(packageMetricsTable)
+		return err
+	}},
+	{Version: 8, Description: "add metrics_runs", up: func(s *SQLStore) error {
+		_, err := s.db.Exec(metricsRunsTable)
+		return err
+	}},
+	{Version: 9, Description: "add co_changes", up: func(s *SQLStore) error {
+		_, err := s.db.Exec(coChangesTable)
+		return err
+	}},
+	{Version: 10, Description: "add metric_history", up: func(s *SQLStore) error {
+		_, err := s.db.Exec(metricHistoryTable)
+		return err
+	}},
+	{Version: 11, Description: "add entity_fingerprints", up: func(s *SQLStore) error {
+		_, err := s.db.Exec(fingerprintsTable)
+		return err
+	}},
+	{Version: 12, Description: "add entity_change_sets", up: func(s *SQLStore) error {
+		_, err := s.db.Exec(entityChangeSetsTable)
+		return err
+	}},
+	{Version: 13, Description: "add snapshot_dependencies.optional and source", up: func(s *SQLStore) error {
+		// Snapshots exist only on SQLite; Dolt keeps history itself
+		if s.backend != BackendSQLite {
+			return nil
+		}
+		if err := s.addColumn("snapshot_dependencies", "optional", "BOOLEAN NOT NULL DEFAULT FALSE"); err != nil {
+			return err
+		}
+		return s.addColumn("snapshot_dependencies", "source", "VARCHAR(16) NOT NULL DEFAULT 'treesitter'")
+	}},
+}
+
+// LatestSchemaVersion returns the schema version this build of cx writes.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// schemaVersionTable records which migrations have been applied.
+const schemaVersionTable = `CREATE TABLE IF NOT EXISTS schema_version (
+    version INT PRIMARY KEY,
+    description VARCHAR(255) NOT NULL,
+    applied_at VARCHAR(30) NOT NULL
+)`
+
+// migrateInitialSchema creates the tables and indexes cx shipped with before
+// schema versioning. It is idempotent, so databases created by older cx
+// releases adopt version 1 without losing data.
+func migrateInitialSchema(s *SQLStore) error {
+	if s.backend == BackendSQLite {
+		return s.initSQLiteSchema()
+	}
+	return s.initSchema()
+}
+
+// SchemaVersion returns the highest migration version applied to the
+// database, or 0 if none have been recorded.
+func (s *SQLStore) SchemaVersion() (int, error) {
+	exists, err := s.hasTable("schema_version")
+	if err != nil || !exists {
+		return 0, err
+	}
+	var version int
+	err = s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// MigrationStatus returns every known migration and whether it has been applied.
+func (s *SQLStore) MigrationStatus() ([]MigrationStatus, error) {
+	applied := make(map[int]string)
+	exists, err := s.hasTable("schema_version")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		rows, err := s.db.Query(`SELECT version, applied_at FROM schema_version`)
+		if err != nil {
+			return nil, fmt.Errorf("query schema_version: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var appliedAt string
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return nil, fmt.Errorf("scan schema_version: %w", err)
+			}
+			applied[version] = appliedAt
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	status := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		status[i] = MigrationStatus{Migration: m, Applied: ok, AppliedAt: appliedAt}
+	}
+	return status, nil
+}
+
+// PendingMigrations returns the migrations newer than the database's
+// schema version, in the order they would be applied.
+func (s *SQLStore) PendingMigrations() ([]Migration, error) {
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaVersion(current); err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies all pending migrations and returns the ones it applied.
+// It fails with ErrSchemaTooNew if the database is ahead of this cx.
+func (s *SQLStore) Migrate() ([]Migration, error) {
+	pending, err := s.PendingMigrations()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec(schemaVersionTable); err != nil {
+		return nil, fmt.Errorf("create schema_version: %w", err)
+	}
+
+	var applied []Migration
+	for _, m := range pending {
+		if err := m.up(s); err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		_, err := s.db.Exec(`REPLACE INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)`,
+			m.Version, m.Description, time.Now().UTC().Format(time.RFC3339))
+		if err != nil {
+			return applied, fmt.Errorf("record migration %d: %w", m.Version, err)
+		}
+		if s.backend == BackendDolt {
+			msg := fmt.Sprintf("schema migration %d: %s", m.Version, m.Description)
+			if _, err := s.db.Exec("CALL dolt_commit('-Am', ?)", msg); err != nil {
+				return applied, fmt.Errorf("commit migration %d: %w", m.Version, err)
+			}
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// checkSchemaVersion rejects databases written by a newer cx.
+func checkSchemaVersion(current int) error {
+	if latest := LatestSchemaVersion(); current > latest {
+		return fmt.Errorf("%w: database is at schema version %d, this cx supports up to %d; upgrade cx, or run 'cx admin reset' to rebuild the database",
+			ErrSchemaTooNew, current, latest)
+	}
+	return nil
+}
+
+// hasTable reports whether a table exists in the current database.
+func (s *SQLStore) hasTable(table string) (bool, error) {
+	query := `SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = ?`
+	if s.backend == BackendSQLite {
+		query = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+	}
+	var count int
+	if err := s.db.QueryRow(query, table).Scan(&count); err != nil {
+		return false, fmt.Errorf("check table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// hasColumn reports whether a table has the named column.
+func (s *SQLStore) hasColumn(table, column string) (bool, error) {
+	query := `SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?`
+	if s.backend == BackendSQLite {
+		query = `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
+	}
+	var count int
+	if err := s.db.QueryRow(query, table, column).Scan(&count); err != nil {
+		return false, fmt.Errorf("check column %s.%s: %w", table, column, err)
+	}
+	return count > 0, nil
+}
+
+// addColumn adds a column to an existing table unless it is already there.
+// definition is the column type and constraints, e.g. "INT NOT NULL DEFAULT 0".
+func (s *SQLStore) addColumn(table, column, definition string) error {
+	exists, err := s.hasColumn(table, column)
+	if err != nil || exists {
+		return err
+	}
+	if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	version, err := s.SchemaVersion()
+	if err != nil || version != LatestSchemaVersion() {
+		t.Fatalf("SchemaVersion = %d, %v; want %d", version, err, LatestSchemaVersion())
+	}
+	status, err := s.MigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range status {
+		if !m.Applied || m.AppliedAt == "" {
+			t.Errorf("migration %d not recorded as applied: %+v", m.Version, m)
+		}
+	}
+	if pending, _ := s.PendingMigrations(); len(pending) != 0 {
+		t.Errorf("pending after open = %+v, want none", pending)
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	cxDir := t.TempDir()
+
+	// A database created before schema versioning: tables, no schema_version
+	legacy, err := openSQLite(cxDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.initSQLiteSchema(); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.CreateEntity(&Entity{ID: "fn-1", Name: "Alpha", EntityType: "function", FilePath: "a.go"}); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	// Inspecting does not migrate
+	s, err := OpenWithOptions(cxDir, OpenOptions{Backend: BackendSQLite, SkipMigrations: true})
+	if err != nil {
+		t.Fatalf("open without migrating: %v", err)
+	}
+	pending, err := s.PendingMigrations()
+	if err != nil || len(pending) != len(migrations) {
+		t.Fatalf("pending = %d, %v; want %d", len(pending), err, len(migrations))
+	}
+	if ok, _ := s.hasTable("schema_version"); ok {
+		t.Error("SkipMigrations should not create schema_version")
+	}
+	s.Close()
+
+	s, err = OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatalf("open legacy database: %v", err)
+	}
+	defer s.Close()
+	if v, _ := s.SchemaVersion(); v != LatestSchemaVersion() {
+		t.Errorf("SchemaVersion = %d, want %d", v, LatestSchemaVersion())
+	}
+	if _, err := s.GetEntity("fn-1"); err != nil {
+		t.Errorf("existing data lost during migration: %v", err)
+	}
+}
+
+func TestMigrateAppliesNewMigrations(t *testing.T) {
+	cxDir := t.TempDir()
+	s, err := OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	next := LatestSchemaVersion() + 1
+	migrations = append(append([]Migration(nil), saved...), Migration{
+		Version:     next,
+		Description: "add entities.test_column",
+		up: func(s *SQLStore) error {
+			return s.addColumn("entities", "test_column", "VARCHAR(20) DEFAULT ''")
+		},
+	})
+
+	s, err = OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatalf("open with new migration: %v", err)
+	}
+	defer s.Close()
+
+	if v, _ := s.SchemaVersion(); v != next {
+		t.Errorf("SchemaVersion = %d, want %d", v, next)
+	}
+	if ok, err := s.hasColumn("entities", "test_column"); err != nil || !ok {
+		t.Errorf("hasColumn(entities.test_column) = %v, %v; want true", ok, err)
+	}
+	// addColumn is idempotent so re-running a migration is safe
+	if err := s.addColumn("entities", "test_column", "VARCHAR(20) DEFAULT ''"); err != nil {
+		t.Errorf("re-adding column: %v", err)
+	}
+}
+
+func TestOpenRejectsNewerSchema(t *testing.T) {
+	cxDir := t.TempDir()
+	s, err := OpenBackend(cxDir, BackendSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	future := LatestSchemaVersion() + 1
+	if _, err := s.db.Exec(`INSERT INTO schema_version (version, description, applied_at) VALUES (?, 'future', '')`, future); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	for _, skip := range []bool{false, true} {
+		_, err := OpenWithOptions(cxDir, OpenOptions{Backend: BackendSQLite, SkipMigrations: skip})
+		if !errors.Is(err, ErrSchemaTooNew) {
+			t.Fatalf("open (skip=%v) error = %v, want ErrSchemaTooNew", skip, err)
+		}
+		if !strings.Contains(err.Error(), "upgrade cx") {
+			t.Errorf("error should tell the user how to recover: %v", err)
+		}
+	}
+}
+
+func TestMigrateDoltCommitsEachMigration(t *testing.T) {
+	s, err := OpenBackend(t.TempDir(), BackendDolt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	log, err := s.DoltLog(LatestSchemaVersion() + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, entry := range log {
+		if entry.Message == "schema migration 1: initial schema" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no Dolt commit for migration 1 in %+v", log)
+	}
+}
@@ -0,0 +1,83 @@
+package store
+
+import "fmt"
+
+// packageMetricsTable records package coupling metrics for each scan
+const packageMetricsTable = `CREATE TABLE IF NOT EXISTS package_metrics (
+    scan_id INT NOT NULL,
+    package VARCHAR(500) NOT NULL,
+    entities INT NOT NULL,
+    types INT NOT NULL,
+    abstract_types INT NOT NULL,
+    ca INT NOT NULL,
+    ce INT NOT NULL,
+    instability DOUBLE NOT NULL,
+    abstractness DOUBLE NOT NULL,
+    distance DOUBLE NOT NULL,
+    zone VARCHAR(16) NOT NULL,
+    PRIMARY KEY (scan_id, package)
+)`
+
+// PackageMetrics holds the coupling metrics of one package for a scan
+type PackageMetrics struct {
+	Package       string
+	Entities      int
+	Types         int
+	AbstractTypes int
+	Ca            int
+	Ce            int
+	Instability   float64
+	Abstractness  float64
+	Distance      float64
+	Zone          string // pain, uselessness or empty
+}
+
+// SavePackageMetrics replaces the package metrics recorded for a scan.
+func (s *SQLStore) SavePackageMetrics(scanID int, metrics []PackageMetrics) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM package_metrics WHERE scan_id = ?`, scanID); err != nil {
+		return fmt.Errorf("clear package metrics: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO package_metrics (scan_id, package, entities, types, abstract_types,
+		ca, ce, instability, abstractness, distance, zone) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+	for _, m := range metrics {
+		_, err := stmt.Exec(scanID, m.Package, m.Entities, m.Types, m.AbstractTypes,
+			m.Ca, m.Ce, m.Instability, m.Abstractness, m.Distance, m.Zone)
+		if err != nil {
+			return fmt.Errorf("insert package metrics: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPackageMetrics returns the package metrics recorded for a scan, sorted
+// by package.
+func (s *SQLStore) GetPackageMetrics(scanID int) ([]PackageMetrics, error) {
+	rows, err := s.db.Query(`SELECT package, entities, types, abstract_types, ca, ce,
+		instability, abstractness, distance, zone
+		FROM package_metrics WHERE scan_id = ? ORDER BY package`, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("query package metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var result []PackageMetrics
+	for rows.Next() {
+		var m PackageMetrics
+		if err := rows.Scan(&m.Package, &m.Entities, &m.Types, &m.AbstractTypes, &m.Ca, &m.Ce,
+			&m.Instability, &m.Abstractness, &m.Distance, &m.Zone); err != nil {
+			return nil, fmt.Errorf("scan package metrics: %w", err)
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
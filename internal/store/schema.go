@@ -1,6 +1,7 @@
 package store
 
-// schemaTables defines the MySQL/Dolt schema for the cortex database tables.
+// schemaTables defines the MySQL/Dolt schema for the cortex database tables
+// as of schema version 1. Later changes belong in migrations.go, not here.
 // Each statement is separate for compatibility with Dolt driver.
 var schemaTables = []string{
 	// entities table (replaces beads for code entities)
@@ -138,7 +139,8 @@ var schemaIndexes = []string{
 }
 
 // initSchema creates the database tables and indexes if they don't exist.
-func (s *Store) initSchema() error {
+// It is the body of migration 1 on Dolt.
+func (s *SQLStore) initSchema() error {
 	// Create tables one at a time (Dolt requires single-statement execution)
 	for _, stmt := range schemaTables {
 		if _, err := s.db.Exec(stmt); err != nil {
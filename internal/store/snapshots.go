@@ -0,0 +1,382 @@
+package store
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of the entities and dependencies tables.
+// The SQLite backend records one per scan in place of a Dolt commit, so
+// history, diff and AS OF queries keep working at scan granularity.
+type Snapshot struct {
+	ID         int64
+	CommitHash string
+	Message    string
+	CreatedAt  string
+}
+
+// SnapshotRetention is the number of most recent scan snapshots kept. Each
+// snapshot copies the whole graph, so older ones are pruned as new ones are
+// recorded; tagged snapshots are kept regardless.
+const SnapshotRetention = 100
+
+// snapshotTables lists the tables copied into each snapshot.
+var snapshotTables = map[string]bool{
+	"entities":     true,
+	"dependencies": true,
+}
+
+// createSnapshot copies the current entities and dependencies into a new
+// scan snapshot and returns its hash. Untagged snapshots older than the
+// last SnapshotRetention are deleted.
+func (s *SQLStore) createSnapshot(message string) (string, error) {
+	now := time.Now().UTC()
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", message, now.UnixNano())))
+	hash := hex.EncodeToString(sum[:])[:DoltHashLength]
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("begin snapshot: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO scan_snapshots (commit_hash, message, committer, email, created_at)
+		VALUES (?, ?, 'Cortex', 'cx@local', ?)`,
+		hash, message, now.Format("2006-01-02 15:04:05.000"))
+	if err != nil {
+		return "", fmt.Errorf("record snapshot: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", fmt.Errorf("snapshot id: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO snapshot_entities (snapshot_id, id, name, entity_type, kind, file_path, line_start, line_end,
+			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
+			body_text, doc_comment, skeleton, created_at, updated_at)
+		SELECT ?, id, name, entity_type, kind, file_path, line_start, line_end,
+			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
+			NULL, doc_comment, skeleton, created_at, updated_at
+		FROM entities`, id); err != nil {
+		return "", fmt.Errorf("snapshot entities: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO snapshot_dependencies (snapshot_id, from_id, to_id, dep_type, optional, source, created_at)
+		SELECT ?, from_id, to_id, dep_type, optional, source, created_at FROM dependencies`, id); err != nil {
+		return "", fmt.Errorf("snapshot dependencies: %w", err)
+	}
+
+	if err := pruneSnapshots(tx, id-SnapshotRetention); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("commit snapshot: %w", err)
+	}
+	return hash, nil
+}
+
+// pruneSnapshots deletes the untagged snapshots with IDs up to maxID
+func pruneSnapshots(tx *sql.Tx, maxID int64) error {
+	if maxID <= 0 {
+		return nil
+	}
+	const old = `SELECT id FROM scan_snapshots
+		WHERE id <= ? AND id NOT IN (SELECT snapshot_id FROM snapshot_tags)`
+	for _, stmt := range []string{
+		`DELETE FROM snapshot_entities WHERE snapshot_id IN (` + old + `)`,
+		`DELETE FROM snapshot_dependencies WHERE snapshot_id IN (` + old + `)`,
+		`DELETE FROM scan_snapshots WHERE id IN (` + old + `)`,
+	} {
+		if _, err := tx.Exec(stmt, maxID); err != nil {
+			return fmt.Errorf("prune snapshots: %w", err)
+		}
+	}
+	return nil
+}
+
+// tagSnapshot tags the most recent snapshot with the given name.
+func (s *SQLStore) tagSnapshot(name, message string) error {
+	snap, err := s.resolveSnapshot("HEAD")
+	if err != nil {
+		return fmt.Errorf("tag snapshot: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO snapshot_tags (tag_name, snapshot_id, message, created_at)
+		VALUES (?, ?, ?, ?)`,
+		name, snap.ID, message, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("tag snapshot: %w", err)
+	}
+	return nil
+}
+
+// resolveSnapshot resolves a ref to a scan snapshot.
+// Supported refs: HEAD, HEAD~N, tag names, and full or abbreviated snapshot hashes.
+func (s *SQLStore) resolveSnapshot(ref string) (*Snapshot, error) {
+	if !isValidRef(ref) {
+		return nil, fmt.Errorf("invalid ref format: %s", ref)
+	}
+
+	const cols = `SELECT id, commit_hash, COALESCE(message, ''), created_at FROM scan_snapshots`
+	var row *sql.Row
+
+	switch {
+	case ref == "HEAD":
+		row = s.db.QueryRow(cols + ` ORDER BY id DESC LIMIT 1`)
+	case strings.HasPrefix(ref, "HEAD~") || strings.HasPrefix(ref, "HEAD^"):
+		n := 1
+		if len(ref) > 5 {
+			if _, err := fmt.Sscanf(ref[5:], "%d", &n); err != nil {
+				return nil, fmt.Errorf("invalid ref format: %s", ref)
+			}
+		}
+		row = s.db.QueryRow(cols+` ORDER BY id DESC LIMIT 1 OFFSET ?`, n)
+	default:
+		var id int64
+		err := s.db.QueryRow(`SELECT snapshot_id FROM snapshot_tags WHERE tag_name = ?`, ref).Scan(&id)
+		if err == nil {
+			row = s.db.QueryRow(cols+` WHERE id = ?`, id)
+			break
+		}
+
+		rows, err := s.db.Query(cols+` WHERE commit_hash LIKE ? LIMIT 2`, ref+"%")
+		if err != nil {
+			return nil, fmt.Errorf("resolve ref %s: %w", ref, err)
+		}
+		var matches []*Snapshot
+		for rows.Next() {
+			var snap Snapshot
+			if err := rows.Scan(&snap.ID, &snap.CommitHash, &snap.Message, &snap.CreatedAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			matches = append(matches, &snap)
+		}
+		rows.Close()
+
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("cannot resolve ref '%s': no such snapshot", ref)
+		case 1:
+			return matches[0], nil
+		default:
+			return nil, fmt.Errorf("ambiguous ref '%s': matches %d snapshots", ref, len(matches))
+		}
+	}
+
+	var snap Snapshot
+	if err := row.Scan(&snap.ID, &snap.CommitHash, &snap.Message, &snap.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("cannot resolve ref '%s': no such snapshot", ref)
+		}
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// tableAsOf returns a FROM-clause table reference for table at ref.
+// Dolt uses "table AS OF 'ref'"; SQLite selects the matching snapshot rows
+// under the original table name so callers can append WHERE clauses unchanged.
+func (s *SQLStore) tableAsOf(table, ref string) (string, error) {
+	if s.backend != BackendSQLite {
+		resolvedRef, err := s.ResolveRef(ref)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s AS OF '%s'", table, resolvedRef), nil
+	}
+
+	if !snapshotTables[table] {
+		return "", fmt.Errorf("history for table %s is not recorded by the sqlite backend", table)
+	}
+	if ref == "WORKING" {
+		return table, nil
+	}
+	snap, err := s.resolveSnapshot(ref)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(SELECT * FROM snapshot_%s WHERE snapshot_id = %d) AS %s", table, snap.ID, table), nil
+}
+
+// snapshotEntityRow is the subset of entity columns compared by snapshotDiff.
+type snapshotEntityRow struct {
+	name, entityType, filePath string
+	lineStart                  int
+	lineEnd                    sql.NullInt64
+	sigHash, bodyHash, status  sql.NullString
+}
+
+// snapshotDiff is the SQLite implementation of DoltDiff.
+// It compares two snapshots (or a snapshot and the live WORKING tables) in Go.
+func (s *SQLStore) snapshotDiff(opts DiffOptions, result *DiffResult) (*DiffResult, error) {
+	if opts.Table != "entities" {
+		return nil, fmt.Errorf("diff of table %s is not supported by the sqlite backend", opts.Table)
+	}
+
+	// Mirror Dolt: not enough history yields an empty diff
+	from, err := s.snapshotEntityRows(opts.FromRef)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such snapshot") {
+			return result, nil
+		}
+		return nil, err
+	}
+	to, err := s.snapshotEntityRows(opts.ToRef)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such snapshot") {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	matches := func(id string, row *snapshotEntityRow) bool {
+		if opts.EntityID != "" && id != opts.EntityID {
+			return false
+		}
+		if opts.EntityName != "" && !strings.Contains(strings.ToLower(row.name), strings.ToLower(opts.EntityName)) {
+			return false
+		}
+		return true
+	}
+	change := func(diffType, id string, row *snapshotEntityRow, oldHash, newHash sql.NullString) DiffChange {
+		c := DiffChange{
+			DiffType:   diffType,
+			EntityID:   id,
+			EntityName: row.name,
+			EntityType: row.entityType,
+			FilePath:   row.filePath,
+			LineStart:  row.lineStart,
+		}
+		if oldHash.Valid {
+			c.OldSigHash = &oldHash.String
+		}
+		if newHash.Valid {
+			c.NewSigHash = &newHash.String
+		}
+		return c
+	}
+
+	for id, newRow := range to {
+		if !matches(id, newRow) {
+			continue
+		}
+		oldRow, ok := from[id]
+		if !ok {
+			result.Added = append(result.Added, change("added", id, newRow, sql.NullString{}, newRow.sigHash))
+			continue
+		}
+		if *oldRow != *newRow {
+			result.Modified = append(result.Modified, change("modified", id, newRow, oldRow.sigHash, newRow.sigHash))
+		}
+	}
+	for id, oldRow := range from {
+		if _, ok := to[id]; ok || !matches(id, oldRow) {
+			continue
+		}
+		result.Removed = append(result.Removed, change("removed", id, oldRow, oldRow.sigHash, sql.NullString{}))
+	}
+
+	for _, changes := range [][]DiffChange{result.Added, result.Modified, result.Removed} {
+		sortDiffChanges(changes)
+	}
+	return result, nil
+}
+
+// snapshotEntityRows loads the comparable entity columns at ref, keyed by entity ID.
+func (s *SQLStore) snapshotEntityRows(ref string) (map[string]*snapshotEntityRow, error) {
+	from, err := s.tableAsOf("entities", ref)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT id, name, entity_type, file_path, line_start, line_end, sig_hash, body_hash, status
+		FROM %s`, from))
+	if err != nil {
+		return nil, fmt.Errorf("query entities at %s: %w", ref, err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]*snapshotEntityRow)
+	for rows.Next() {
+		var id string
+		var r snapshotEntityRow
+		if err := rows.Scan(&id, &r.name, &r.entityType, &r.filePath, &r.lineStart,
+			&r.lineEnd, &r.sigHash, &r.bodyHash, &r.status); err != nil {
+			return nil, fmt.Errorf("scan entity row: %w", err)
+		}
+		result[id] = &r
+	}
+	return result, rows.Err()
+}
+
+// sortDiffChanges orders changes by entity name, matching the Dolt diff query.
+func sortDiffChanges(changes []DiffChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].EntityName < changes[j].EntityName
+	})
+}
+
+// RestoreSnapshot replaces the live entities and dependencies with the
+// contents of the snapshot at ref. It is the SQLite counterpart of a hard
+// Dolt reset; body_text is not recorded in snapshots and stays empty until
+// the next scan.
+func (s *SQLStore) RestoreSnapshot(ref string) (*Snapshot, error) {
+	if s.backend != BackendSQLite {
+		return nil, fmt.Errorf("snapshots are only used by the sqlite backend")
+	}
+	snap, err := s.resolveSnapshot(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin restore: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmts := []string{
+		`DELETE FROM dependencies`,
+		`DELETE FROM entities`,
+		`INSERT INTO entities (id, name, entity_type, kind, file_path, line_start, line_end,
+			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
+			body_text, doc_comment, skeleton, created_at, updated_at)
+		SELECT id, name, entity_type, kind, file_path, line_start, line_end,
+			signature, sig_hash, body_hash, receiver, visibility, fields, language, status,
+			body_text, doc_comment, skeleton, created_at, updated_at
+		FROM snapshot_entities WHERE snapshot_id = ?`,
+		`INSERT INTO dependencies (from_id, to_id, dep_type, optional, source, created_at)
+		SELECT from_id, to_id, dep_type, optional, source, created_at
+		FROM snapshot_dependencies WHERE snapshot_id = ?`,
+		`DELETE FROM snapshot_dependencies WHERE snapshot_id > ?`,
+		`DELETE FROM snapshot_entities WHERE snapshot_id > ?`,
+		`DELETE FROM snapshot_tags WHERE snapshot_id > ?`,
+		`DELETE FROM scan_snapshots WHERE id > ?`,
+	}
+	for i, stmt := range stmts {
+		var err error
+		if i < 2 {
+			_, err = tx.Exec(stmt)
+		} else {
+			_, err = tx.Exec(stmt, snap.ID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("restore snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit restore: %w", err)
+	}
+	return snap, nil
+}
@@ -0,0 +1,336 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchemaTables mirrors schemaTables for the SQLite backend.
+// Column names and types are kept identical so every query in this package
+// works unchanged; only MySQL-specific syntax differs.
+var sqliteSchemaTables = []string{
+	`CREATE TABLE IF NOT EXISTS entities (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    kind VARCHAR(50),
+    file_path VARCHAR(500) NOT NULL,
+    line_start INT NOT NULL,
+    line_end INT,
+    signature TEXT,
+    sig_hash VARCHAR(16),
+    body_hash VARCHAR(16),
+    receiver VARCHAR(255),
+    visibility VARCHAR(20),
+    fields TEXT,
+    language VARCHAR(20) DEFAULT 'go',
+    status VARCHAR(20) DEFAULT 'active',
+    body_text TEXT,
+    doc_comment TEXT,
+    skeleton TEXT,
+    created_at VARCHAR(30) NOT NULL,
+    updated_at VARCHAR(30) NOT NULL
+)`,
+
+	`CREATE TABLE IF NOT EXISTS dependencies (
+    from_id VARCHAR(255) NOT NULL,
+    to_id VARCHAR(255) NOT NULL,
+    dep_type VARCHAR(50) NOT NULL,
+    created_at VARCHAR(30) NOT NULL,
+    PRIMARY KEY (from_id, to_id, dep_type)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS metrics (
+    entity_id VARCHAR(255) PRIMARY KEY,
+    pagerank DOUBLE DEFAULT 0,
+    in_degree INT DEFAULT 0,
+    out_degree INT DEFAULT 0,
+    betweenness DOUBLE DEFAULT 0,
+    computed_at VARCHAR(30)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS file_index (
+    file_path VARCHAR(500) PRIMARY KEY,
+    scan_hash VARCHAR(64) NOT NULL,
+    scanned_at VARCHAR(30) NOT NULL
+)`,
+
+	`CREATE TABLE IF NOT EXISTS entity_links (
+    entity_id VARCHAR(255) NOT NULL,
+    external_system VARCHAR(50) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    link_type VARCHAR(50) DEFAULT 'related',
+    created_at VARCHAR(30) NOT NULL,
+    PRIMARY KEY (entity_id, external_system, external_id)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS entity_tags (
+    entity_id VARCHAR(255) NOT NULL,
+    tag VARCHAR(100) NOT NULL,
+    created_at VARCHAR(30) NOT NULL,
+    created_by VARCHAR(100),
+    note TEXT,
+    PRIMARY KEY (entity_id, tag)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS entity_coverage (
+    entity_id VARCHAR(255) PRIMARY KEY,
+    coverage_percent DOUBLE DEFAULT 0,
+    covered_lines TEXT,
+    uncovered_lines TEXT,
+    last_run VARCHAR(30)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS test_entity_map (
+    test_file VARCHAR(500) NOT NULL,
+    test_name VARCHAR(255) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (test_file, test_name, entity_id)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS scan_metadata (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scan_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    git_commit VARCHAR(40),
+    git_branch VARCHAR(255),
+    files_scanned INT,
+    entities_found INT,
+    dependencies_found INT,
+    scan_duration_ms INT
+)`,
+
+	`CREATE TABLE IF NOT EXISTS entity_embeddings (
+    entity_id VARCHAR(255) PRIMARY KEY,
+    embedding TEXT NOT NULL,
+    model_version VARCHAR(50) NOT NULL,
+    content_hash VARCHAR(16) NOT NULL,
+    created_at VARCHAR(30) NOT NULL
+)`,
+
+	// FTS5 index over entities (external content, kept in sync by triggers)
+	`CREATE VIRTUAL TABLE IF NOT EXISTS entities_fts USING fts5(
+    name, body_text, doc_comment,
+    content='entities', content_rowid='rowid'
+)`,
+
+	`CREATE TRIGGER IF NOT EXISTS entities_fts_insert AFTER INSERT ON entities BEGIN
+    INSERT INTO entities_fts(rowid, name, body_text, doc_comment)
+    VALUES (new.rowid, new.name, new.body_text, new.doc_comment);
+END`,
+
+	`CREATE TRIGGER IF NOT EXISTS entities_fts_delete AFTER DELETE ON entities BEGIN
+    INSERT INTO entities_fts(entities_fts, rowid, name, body_text, doc_comment)
+    VALUES ('delete', old.rowid, old.name, old.body_text, old.doc_comment);
+END`,
+
+	`CREATE TRIGGER IF NOT EXISTS entities_fts_update AFTER UPDATE ON entities BEGIN
+    INSERT INTO entities_fts(entities_fts, rowid, name, body_text, doc_comment)
+    VALUES ('delete', old.rowid, old.name, old.body_text, old.doc_comment);
+    INSERT INTO entities_fts(rowid, name, body_text, doc_comment)
+    VALUES (new.rowid, new.name, new.body_text, new.doc_comment);
+END`,
+
+	// Scan snapshots stand in for Dolt commits (history, diff, AS OF)
+	`CREATE TABLE IF NOT EXISTS scan_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    commit_hash VARCHAR(32) NOT NULL UNIQUE,
+    message TEXT,
+    committer VARCHAR(100),
+    email VARCHAR(100),
+    created_at VARCHAR(30) NOT NULL
+)`,
+
+	`CREATE TABLE IF NOT EXISTS snapshot_tags (
+    tag_name VARCHAR(255) PRIMARY KEY,
+    snapshot_id INTEGER NOT NULL,
+    message TEXT,
+    created_at VARCHAR(30) NOT NULL
+)`,
+
+	// snapshot_entities leaves body_text empty to keep snapshots small
+	`CREATE TABLE IF NOT EXISTS snapshot_entities (
+    snapshot_id INTEGER NOT NULL,
+    id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    kind VARCHAR(50),
+    file_path VARCHAR(500) NOT NULL,
+    line_start INT NOT NULL,
+    line_end INT,
+    signature TEXT,
+    sig_hash VARCHAR(16),
+    body_hash VARCHAR(16),
+    receiver VARCHAR(255),
+    visibility VARCHAR(20),
+    fields TEXT,
+    language VARCHAR(20),
+    status VARCHAR(20),
+    body_text TEXT,
+    doc_comment TEXT,
+    skeleton TEXT,
+    created_at VARCHAR(30) NOT NULL,
+    updated_at VARCHAR(30) NOT NULL,
+    PRIMARY KEY (snapshot_id, id)
+)`,
+
+	`CREATE TABLE IF NOT EXISTS snapshot_dependencies (
+    snapshot_id INTEGER NOT NULL,
+    from_id VARCHAR(255) NOT NULL,
+    to_id VARCHAR(255) NOT NULL,
+    dep_type VARCHAR(50) NOT NULL,
+    created_at VARCHAR(30) NOT NULL,
+    PRIMARY KEY (snapshot_id, from_id, to_id, dep_type)
+)`,
+}
+
+// sqliteSchemaIndexes mirrors schemaIndexes using IF NOT EXISTS, which SQLite supports.
+var sqliteSchemaIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_entities_type ON entities(entity_type)",
+	"CREATE INDEX IF NOT EXISTS idx_entities_file ON entities(file_path)",
+	"CREATE INDEX IF NOT EXISTS idx_entities_name ON entities(name)",
+	"CREATE INDEX IF NOT EXISTS idx_entities_status ON entities(status)",
+	"CREATE INDEX IF NOT EXISTS idx_entities_language ON entities(language)",
+	"CREATE INDEX IF NOT EXISTS idx_deps_from ON dependencies(from_id)",
+	"CREATE INDEX IF NOT EXISTS idx_deps_to ON dependencies(to_id)",
+	"CREATE INDEX IF NOT EXISTS idx_deps_type ON dependencies(dep_type)",
+	"CREATE INDEX IF NOT EXISTS idx_metrics_pagerank ON metrics(pagerank)",
+	"CREATE INDEX IF NOT EXISTS idx_metrics_betweenness ON metrics(betweenness)",
+	"CREATE INDEX IF NOT EXISTS idx_links_external ON entity_links(external_system, external_id)",
+	"CREATE INDEX IF NOT EXISTS idx_tags_tag ON entity_tags(tag)",
+	"CREATE INDEX IF NOT EXISTS idx_tags_entity ON entity_tags(entity_id)",
+	"CREATE INDEX IF NOT EXISTS idx_coverage_percent ON entity_coverage(coverage_percent)",
+	"CREATE INDEX IF NOT EXISTS idx_test_entity ON test_entity_map(entity_id)",
+	"CREATE INDEX IF NOT EXISTS idx_embeddings_model ON entity_embeddings(model_version)",
+	"CREATE INDEX IF NOT EXISTS idx_embeddings_hash ON entity_embeddings(content_hash)",
+	"CREATE INDEX IF NOT EXISTS idx_snapshot_entities_id ON snapshot_entities(id)",
+	"CREATE INDEX IF NOT EXISTS idx_snapshot_deps_from ON snapshot_dependencies(from_id)",
+	"CREATE INDEX IF NOT EXISTS idx_snapshot_deps_to ON snapshot_dependencies(to_id)",
+}
+
+// openSQLite opens or creates the SQLite database at cxDir/cortex.db.
+func openSQLite(cxDir string) (*SQLStore, error) {
+	if err := os.MkdirAll(cxDir, 0755); err != nil {
+		return nil, fmt.Errorf("create .cx directory: %w", err)
+	}
+
+	dbPath := filepath.Join(cxDir, "cortex.db")
+
+	// Pragmas are applied per connection via the DSN:
+	//   - WAL + busy_timeout for concurrent readers (daemon, MCP server, CLI)
+	//   - recursive_triggers so REPLACE INTO keeps the FTS5 index in sync
+	dsn := "file:" + dbPath +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=recursive_triggers(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite db: %w", err)
+	}
+
+	return &SQLStore{db: db, dbPath: dbPath, cxDir: cxDir, backend: BackendSQLite}, nil
+}
+
+// initSQLiteSchema creates the SQLite tables, FTS5 index and indexes if they don't exist.
+// It is the body of migration 1 on SQLite.
+func (s *SQLStore) initSQLiteSchema() error {
+	for _, stmt := range sqliteSchemaTables {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	for _, idx := range sqliteSchemaIndexes {
+		if _, err := s.db.Exec(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchEntitiesFTS5 is the SQLite implementation of SearchEntities.
+// It ranks with FTS5's bm25(), negated so higher scores are better matches
+// (matching the MySQL MATCH() AGAINST() convention used by scanSearchResult).
+func (s *SQLStore) searchEntitiesFTS5(opts SearchOptions, ftsQuery string) ([]*SearchResult, error) {
+	match := buildFTS5Match(ftsQuery)
+	if match == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT
+			entities.id, entities.name, entities.entity_type, entities.kind, entities.file_path,
+			entities.line_start, entities.line_end, entities.signature, entities.sig_hash,
+			entities.body_hash, entities.receiver, entities.visibility, entities.fields,
+			entities.language, entities.status, entities.body_text, entities.doc_comment, entities.skeleton,
+			entities.created_at, entities.updated_at,
+			-bm25(entities_fts) as fts_score,
+			COALESCE(metrics.pagerank, 0.0) as pagerank
+		FROM entities_fts
+		JOIN entities ON entities.rowid = entities_fts.rowid
+		LEFT JOIN metrics ON metrics.entity_id = entities.id
+		WHERE entities_fts MATCH ?
+		AND entities.status = 'active'`
+
+	args := []interface{}{match}
+
+	if opts.Language != "" {
+		query += " AND entities.language = ?"
+		args = append(args, opts.Language)
+	}
+	if opts.EntityType != "" {
+		query += " AND entities.entity_type = ?"
+		args = append(args, opts.EntityType)
+	}
+
+	query += " ORDER BY fts_score DESC"
+	query += fmt.Sprintf(" LIMIT %d", opts.Limit*3)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		result, err := scanSearchResult(rows, opts)
+		if err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+
+		if strings.EqualFold(result.Entity.Name, opts.Query) {
+			result.CombinedScore *= opts.BoostExactName
+		}
+
+		if result.CombinedScore >= opts.Threshold {
+			results = append(results, result)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating search results: %w", err)
+	}
+
+	sortSearchResults(results)
+
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	return results, nil
+}
+
+// buildFTS5Match converts the cleaned keyword query into an FTS5 MATCH expression.
+// Each keyword is quoted (so identifiers are never parsed as FTS5 operators) and
+// the terms are OR-ed together to mirror MySQL natural language mode, where any
+// matching word contributes to relevance.
+func buildFTS5Match(ftsQuery string) string {
+	words := strings.Fields(ftsQuery)
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+strings.ReplaceAll(w, `"`, `""`)+`"`)
+	}
+	return strings.Join(terms, " OR ")
+}
@@ -0,0 +1,475 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSQLiteStore creates a temporary SQLite-backed store for testing.
+func testSQLiteStore(t *testing.T) *SQLStore {
+	t.Helper()
+	s, err := OpenBackend(t.TempDir(), BackendSQLite)
+	if err != nil {
+		t.Fatalf("open sqlite store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestOpenSQLiteFromConfig(t *testing.T) {
+	cxDir := filepath.Join(t.TempDir(), ".cx")
+	if err := os.MkdirAll(cxDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cxDir, "config.yaml"), []byte("storage:\n  backend: sqlite\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(cxDir)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	if s.Backend() != BackendSQLite {
+		t.Errorf("Backend() = %q, want %q", s.Backend(), BackendSQLite)
+	}
+	if s.SupportsVersionControl() {
+		t.Error("sqlite backend should not report Dolt version control")
+	}
+	if _, err := os.Stat(filepath.Join(cxDir, "cortex.db")); err != nil {
+		t.Errorf("expected cortex.db to be created: %v", err)
+	}
+}
+
+func TestSQLiteEntitiesAndSearch(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	entities := []*Entity{
+		{ID: "fn-1", Name: "LoginUser", EntityType: "function", FilePath: "auth/login.go", LineStart: 10,
+			BodyText: "validate password and create session", DocComment: "LoginUser authenticates a user"},
+		{ID: "fn-2", Name: "RateLimit", EntityType: "function", FilePath: "http/limit.go", LineStart: 5,
+			BodyText: "token bucket"},
+	}
+	if err := s.CreateEntitiesBulk(entities); err != nil {
+		t.Fatalf("create entities: %v", err)
+	}
+	// Bulk insert ignores duplicates
+	if err := s.CreateEntitiesBulk(entities[:1]); err != nil {
+		t.Fatalf("re-insert entities: %v", err)
+	}
+
+	count, err := s.CountEntities(EntityFilter{Status: "active"})
+	if err != nil || count != 2 {
+		t.Fatalf("CountEntities = %d, %v; want 2", count, err)
+	}
+
+	results, err := s.SearchEntities(SearchOptions{Query: "password session"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 1 || results[0].Entity.ID != "fn-1" {
+		t.Fatalf("search results = %+v, want fn-1", results)
+	}
+	if results[0].FTSScore <= 0 {
+		t.Errorf("FTSScore = %f, want > 0", results[0].FTSScore)
+	}
+
+	// Updates keep the FTS index in sync
+	if err := s.UpdateEntity(&Entity{ID: "fn-2", BodyText: "sliding window password throttle"}); err != nil {
+		t.Fatalf("update entity: %v", err)
+	}
+	results, err = s.SearchEntities(SearchOptions{Query: "throttle"})
+	if err != nil || len(results) != 1 || results[0].Entity.ID != "fn-2" {
+		t.Fatalf("search after update = %+v, %v", results, err)
+	}
+}
+
+func TestSQLiteUpserts(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	if err := s.AddTagWithNote("fn-1", "critical", "agent", "first"); err != nil {
+		t.Fatalf("add tag: %v", err)
+	}
+	if err := s.AddTagWithNote("fn-1", "critical", "user", "second"); err != nil {
+		t.Fatalf("update tag: %v", err)
+	}
+	tags, err := s.GetTags("fn-1")
+	if err != nil || len(tags) != 1 || tags[0].Note != "second" || tags[0].CreatedBy != "user" {
+		t.Fatalf("tags = %+v, %v", tags, err)
+	}
+
+	if err := s.SaveEmbedding("fn-1", []float32{1, 0}, "m1", "h1"); err != nil {
+		t.Fatalf("save embedding: %v", err)
+	}
+	if err := s.SaveEmbedding("fn-1", []float32{0, 1}, "m2", "h2"); err != nil {
+		t.Fatalf("update embedding: %v", err)
+	}
+	emb, err := s.GetEmbedding("fn-1")
+	if err != nil || emb.ModelVersion != "m2" || emb.Embedding[1] != 1 {
+		t.Fatalf("embedding = %+v, %v", emb, err)
+	}
+
+	if err := s.AddTestMapping("a_test.go", "TestA", "fn-1"); err != nil {
+		t.Fatalf("add test mapping: %v", err)
+	}
+	if err := s.AddTestMapping("a_test.go", "TestA", "fn-1"); err != nil {
+		t.Fatalf("duplicate test mapping should be ignored: %v", err)
+	}
+
+	if err := s.CreateDependenciesBulk([]*Dependency{{FromID: "fn-1", ToID: "fn-2", DepType: "calls", Source: DepSourceSCIP}}); err != nil {
+		t.Fatalf("save scip dependency: %v", err)
+	}
+	if err := s.CreateDependenciesBulk([]*Dependency{{FromID: "fn-1", ToID: "fn-2", DepType: "calls", Optional: true}}); err != nil {
+		t.Fatalf("update dependency: %v", err)
+	}
+	deps, err := s.GetDependenciesFrom("fn-1")
+	if err != nil || len(deps) != 1 || deps[0].Source != DepSourceSCIP || !deps[0].Optional {
+		t.Fatalf("dependencies = %+v, %v; want one optional scip edge", deps, err)
+	}
+}
+
+func TestSQLiteSnapshotHistory(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	if err := s.CreateEntity(&Entity{ID: "fn-1", Name: "Alpha", EntityType: "function",
+		FilePath: "a.go", LineStart: 1, SigHash: "s1", BodyHash: "b1"}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := s.DoltCommit("scan 1")
+	if err != nil {
+		t.Fatalf("first snapshot: %v", err)
+	}
+	if len(first) != DoltHashLength {
+		t.Errorf("snapshot hash length = %d, want %d", len(first), DoltHashLength)
+	}
+	if err := s.DoltTag("v1", "first release"); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+
+	if err := s.UpdateEntity(&Entity{ID: "fn-1", BodyHash: "b2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateEntity(&Entity{ID: "fn-2", Name: "Beta", EntityType: "function",
+		FilePath: "b.go", LineStart: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateDependency(&Dependency{FromID: "fn-2", ToID: "fn-1", DepType: "calls"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DoltCommit("scan 2"); err != nil {
+		t.Fatalf("second snapshot: %v", err)
+	}
+
+	log, err := s.DoltLog(10)
+	if err != nil || len(log) != 2 || log[1].CommitHash != first {
+		t.Fatalf("DoltLog = %+v, %v", log, err)
+	}
+
+	added, modified, removed, err := s.DoltDiffSummary("HEAD~1", "HEAD")
+	if err != nil || added != 1 || modified != 1 || removed != 0 {
+		t.Fatalf("diff summary = %d/%d/%d, %v; want 1/1/0", added, modified, removed, err)
+	}
+
+	old, err := s.GetEntityAt("fn-1", first[:7])
+	if err != nil || old.BodyHash != "b1" {
+		t.Fatalf("GetEntityAt = %+v, %v", old, err)
+	}
+	atTag, err := s.QueryEntitiesAt(EntityFilter{}, "v1")
+	if err != nil || len(atTag) != 1 {
+		t.Fatalf("QueryEntitiesAt(v1) = %d entities, %v; want 1", len(atTag), err)
+	}
+
+	stats, err := s.DoltLogStats("HEAD")
+	if err != nil || stats.Entities != 2 || stats.Dependencies != 1 {
+		t.Fatalf("DoltLogStats = %+v, %v", stats, err)
+	}
+
+	history, err := s.EntityHistory(EntityHistoryOptions{EntityID: "fn-1"})
+	if err != nil || len(history) != 2 || history[0].ChangeType != "current" || history[1].ChangeType != "added" {
+		t.Fatalf("EntityHistory = %+v, %v", history, err)
+	}
+
+	if _, err := s.RestoreSnapshot("v1"); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	count, _ := s.CountEntities(EntityFilter{})
+	deps, _ := s.CountDependencies()
+	if count != 1 || deps != 0 {
+		t.Errorf("after restore: %d entities, %d deps; want 1, 0", count, deps)
+	}
+}
+
+func TestSQLiteSnapshotRetention(t *testing.T) {
+	s := testSQLiteStore(t)
+	if err := s.CreateEntity(&Entity{ID: "fn-1", Name: "Alpha", EntityType: "function", FilePath: "a.go", LineStart: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DoltCommit("scan 0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DoltTag("v0", "kept"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= SnapshotRetention+5; i++ {
+		if _, err := s.DoltCommit(fmt.Sprintf("scan %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var snapshots, copies int
+	s.db.QueryRow(`SELECT COUNT(*) FROM scan_snapshots`).Scan(&snapshots)
+	s.db.QueryRow(`SELECT COUNT(*) FROM snapshot_entities`).Scan(&copies)
+	if snapshots != SnapshotRetention+1 || copies != SnapshotRetention+1 {
+		t.Errorf("kept %d snapshots with %d entity copies, want %d each", snapshots, copies, SnapshotRetention+1)
+	}
+	if got, err := s.QueryEntitiesAt(EntityFilter{}, "v0"); err != nil || len(got) != 1 {
+		t.Errorf("tagged snapshot pruned: %d entities, %v", len(got), err)
+	}
+}
+
+func TestSQLiteMetricHistoryRetention(t *testing.T) {
+	s := testSQLiteStore(t)
+	var scanIDs []int
+	for i := 0; i < MetricHistoryRetention+3; i++ {
+		meta := &ScanMetadata{FilesScanned: i}
+		if err := s.SaveScanMetadata(meta); err != nil {
+			t.Fatal(err)
+		}
+		snapshot := []MetricSnapshot{{EntityID: "fn-1", Name: "Alpha", FilePath: "a.go", Package: ".", LOC: i}}
+		if err := s.SaveMetricHistory(meta.ID, snapshot); err != nil {
+			t.Fatal(err)
+		}
+		scanIDs = append(scanIDs, meta.ID)
+	}
+
+	series, err := s.GetMetricSeries("loc", HistoryScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != MetricHistoryRetention {
+		t.Fatalf("kept %d scans, want %d", len(series), MetricHistoryRetention)
+	}
+	if series[0].ScanID != scanIDs[3] || series[len(series)-1].ScanID != scanIDs[len(scanIDs)-1] {
+		t.Errorf("kept scans %d..%d, want %d..%d", series[0].ScanID, series[len(series)-1].ScanID,
+			scanIDs[3], scanIDs[len(scanIDs)-1])
+	}
+}
+
+func TestSQLiteSnapshotKeepsDependencySource(t *testing.T) {
+	s := testSQLiteStore(t)
+	for _, id := range []string{"fn-1", "fn-2"} {
+		if err := s.CreateEntity(&Entity{ID: id, Name: id, EntityType: "function", FilePath: "a.go", LineStart: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.CreateDependency(&Dependency{FromID: "fn-1", ToID: "fn-2", DepType: "calls", Optional: true, Source: DepSourceSCIP}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := s.DoltCommit("scan 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDependenciesFrom("fn-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DoltCommit("scan 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RestoreSnapshot(first); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	deps, err := s.GetDependenciesFrom("fn-1")
+	if err != nil || len(deps) != 1 || deps[0].Source != DepSourceSCIP || !deps[0].Optional {
+		t.Fatalf("restored dependencies = %+v, %v; want one optional scip edge", deps, err)
+	}
+}
+
+func TestSQLiteCycleHistory(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	first := &ScanMetadata{FilesScanned: 1}
+	second := &ScanMetadata{FilesScanned: 2}
+	if err := s.SaveScanMetadata(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveScanMetadata(second); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("scan IDs = %d, %d; want increasing IDs", first.ID, second.ID)
+	}
+	if prev, err := s.PreviousScanID(second.ID); err != nil || prev != first.ID {
+		t.Errorf("PreviousScanID(%d) = %d, %v; want %d", second.ID, prev, err, first.ID)
+	}
+	if prev, _ := s.PreviousScanID(first.ID); prev != 0 {
+		t.Errorf("PreviousScanID(first) = %d, want 0", prev)
+	}
+
+	cycles := [][]string{{"internal/a", "internal/b"}, {"internal/c", "internal/d", "internal/e"}}
+	if err := s.SaveCycles(first.ID, "package", cycles); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again replaces the scan's cycles at that level
+	if err := s.SaveCycles(first.ID, "package", cycles[:1]); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetCycles(first.ID, "package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0]) != 2 || got[0][1] != "internal/b" {
+		t.Errorf("GetCycles() = %v, want %v", got, cycles[:1])
+	}
+	if got, _ := s.GetCycles(first.ID, "file"); len(got) != 0 {
+		t.Errorf("GetCycles(file) = %v, want none", got)
+	}
+}
+
+func TestSQLitePackageMetrics(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	scan := &ScanMetadata{FilesScanned: 1}
+	if err := s.SaveScanMetadata(scan); err != nil {
+		t.Fatal(err)
+	}
+	metrics := []PackageMetrics{
+		{Package: "internal/store", Entities: 10, Types: 4, AbstractTypes: 1, Ca: 6, Ce: 2, Instability: 0.25, Abstractness: 0.25, Distance: 0.5},
+		{Package: "internal/api", Entities: 3, Types: 3, AbstractTypes: 3, Ca: 0, Ce: 1, Instability: 1, Abstractness: 1, Distance: 1, Zone: "uselessness"},
+	}
+	if err := s.SavePackageMetrics(scan.ID, metrics); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again replaces the scan's metrics
+	if err := s.SavePackageMetrics(scan.ID, metrics); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetPackageMetrics(scan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Package != "internal/api" || got[0].Zone != "uselessness" || got[1].Ca != 6 || got[1].Distance != 0.5 {
+		t.Errorf("GetPackageMetrics() = %+v", got)
+	}
+	if got, _ := s.GetPackageMetrics(scan.ID + 1); len(got) != 0 {
+		t.Errorf("GetPackageMetrics(other scan) = %+v, want none", got)
+	}
+}
+
+func TestSQLiteMetricsRuns(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	if run, err := s.GetLatestMetricsRun(); err != nil || run != nil {
+		t.Fatalf("GetLatestMetricsRun() on empty store = %v, %v; want nil", run, err)
+	}
+	first := &MetricsRun{ComputedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), ScanID: 1, Mode: "full", Entities: 10, Updated: 10, Betweenness: "exact"}
+	second := &MetricsRun{ComputedAt: first.ComputedAt.Add(time.Minute), ScanID: 2, Mode: "incremental", Entities: 11, Updated: 3, Betweenness: "sampled", Pivots: 256}
+	for _, run := range []*MetricsRun{second, first} {
+		if err := s.SaveMetricsRun(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.GetLatestMetricsRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ScanID != 2 || got.Mode != "incremental" || got.Pivots != 256 || !got.ComputedAt.Equal(second.ComputedAt) {
+		t.Errorf("GetLatestMetricsRun() = %+v, want %+v", got, second)
+	}
+}
+
+func TestSQLiteCoChanges(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	edges := []CoChange{
+		{From: "handler.go", To: "001.sql", Support: 4, Confidence: 0.8},
+		{From: "handler.go", To: "README.md", Support: 3, Confidence: 0.3},
+		{From: "001.sql", To: "handler.go", Support: 4, Confidence: 1},
+	}
+	if err := s.SaveCoChanges(CoChangeFile, edges); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveCoChanges(CoChangeFile, edges[:2]); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetCoChanges(CoChangeFile, []string{"handler.go", "001.sql"}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].To != "001.sql" || got[0].Support != 4 || got[0].Level != CoChangeFile {
+		t.Errorf("GetCoChanges() = %+v, want only handler.go => 001.sql", got)
+	}
+	if got, _ := s.GetCoChanges(CoChangeEntity, []string{"handler.go"}, 0); len(got) != 0 {
+		t.Errorf("entity level = %+v, want none", got)
+	}
+}
+
+func TestSQLiteEntityChangeSets(t *testing.T) {
+	s := testSQLiteStore(t)
+
+	for _, id := range []string{"fn-1", "fn-2", "fn-3"} {
+		if err := s.CreateEntity(&Entity{ID: id, Name: id, EntityType: "function",
+			FilePath: "a.go", LineStart: 1, SigHash: "s1", BodyHash: "b1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.DoltCommit("scan 1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"fn-1", "fn-2"} {
+		if err := s.UpdateEntity(&Entity{ID: id, BodyHash: "b2"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.DoltCommit("scan 2"); err != nil {
+		t.Fatal(err)
+	}
+	// Uncommitted changes of the running scan
+	if err := s.UpdateEntity(&Entity{ID: "fn-3", BodyHash: "b2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first record mines the earlier scans from history
+	if err := s.RecordEntityChangeSet(3, 10); err != nil {
+		t.Fatal(err)
+	}
+	sets, err := s.EntityChangeSets(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("EntityChangeSets() = %v, want 2 sets", sets)
+	}
+	if len(sets[0]) != 1 || sets[0][0] != "fn-3" || strings.Join(sets[1], ",") != "fn-1,fn-2" {
+		t.Errorf("EntityChangeSets() = %v, want [[fn-3] [fn-1 fn-2]]", sets)
+	}
+
+	// Later records add only their own scan and keep the newest
+	if _, err := s.DoltCommit("scan 3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordEntityChangeSet(4, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DoltCommit("scan 4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateEntity(&Entity{ID: "fn-1", BodyHash: "b3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordEntityChangeSet(5, 2); err != nil {
+		t.Fatal(err)
+	}
+	sets, err = s.EntityChangeSets(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 1 || strings.Join(sets[0], ",") != "fn-1" {
+		t.Errorf("EntityChangeSets() = %v, want [[fn-1]] after scan 4 changed nothing", sets)
+	}
+}
@@ -2,14 +2,16 @@ package store
 
 import (
 	"database/sql"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
 
 // testStore creates a temporary store for testing.
-func testStore(t *testing.T) (*Store, func()) {
+func testStore(t *testing.T) (*SQLStore, func()) {
 	t.Helper()
 	tmpDir, err := os.MkdirTemp("", "cx-store-test-*")
 	if err != nil {
@@ -647,6 +649,28 @@ func TestCreateDependenciesBulk(t *testing.T) {
 		t.Errorf("expected 3 deps, got %d", count)
 	}
 
+	// The optional flag and source round-trip; source defaults to tree-sitter
+	if err := store.CreateDependenciesBulk([]*Dependency{{FromID: "fn-3", ToID: "fn-4", DepType: "calls", Optional: true, Source: DepSourceSCIP}}); err != nil {
+		t.Fatalf("create optional: %v", err)
+	}
+	got, _ := store.GetDependenciesFrom("fn-3")
+	if len(got) != 1 || !got[0].Optional || got[0].Source != DepSourceSCIP {
+		t.Errorf("optional scip dependency not stored: %+v", got)
+	}
+	got, _ = store.GetDependencies(DependencyFilter{Source: DepSourceTreeSitter})
+	if len(got) != 3 {
+		t.Errorf("got %d tree-sitter dependencies, want 3", len(got))
+	}
+
+	// A plain scan finding a SCIP-confirmed edge again keeps its source
+	if err := store.CreateDependency(&Dependency{FromID: "fn-3", ToID: "fn-4", DepType: "calls"}); err != nil {
+		t.Fatalf("re-create: %v", err)
+	}
+	got, _ = store.GetDependenciesFrom("fn-3")
+	if len(got) != 1 || got[0].Optional || got[0].Source != DepSourceSCIP {
+		t.Errorf("scip dependency after tree-sitter upsert: %+v", got)
+	}
+
 	// Empty slice should be no-op
 	if err := store.CreateDependenciesBulk([]*Dependency{}); err != nil {
 		t.Errorf("empty bulk: %v", err)
@@ -1545,6 +1569,115 @@ func TestSaveScanMetadata(t *testing.T) {
 	}
 }
 
+func TestMetricHistory(t *testing.T) {
+	store, cleanup := testStore(t)
+	defer cleanup()
+
+	cov := func(v float64) *float64 { return &v }
+	scans := []struct {
+		commit    string
+		snapshots []MetricSnapshot
+	}{
+		{"aaa1111", []MetricSnapshot{
+			{EntityID: "sa-fn-1-Parse", Name: "Parse", FilePath: "pkg/parse.go", Package: "pkg", PageRank: 0.1, FanIn: 2, LOC: 10},
+			{EntityID: "sa-fn-1-lex", Name: "lex", FilePath: "pkg/lex.go", Package: "pkg", PageRank: 0.2, FanIn: 1, LOC: 30},
+		}},
+		// Parse moved down the file and got a new ID; coverage was imported
+		{"bbb2222", []MetricSnapshot{
+			{EntityID: "sa-fn-9-Parse", Name: "Parse", FilePath: "pkg/parse.go", Package: "pkg", PageRank: 0.3, FanIn: 5, LOC: 10, Coverage: cov(100)},
+			{EntityID: "sa-fn-1-lex", Name: "lex", FilePath: "pkg/lex.go", Package: "pkg", PageRank: 0.2, FanIn: 1, LOC: 30, Coverage: cov(50)},
+			{EntityID: "sa-fn-1-main", Name: "main", FilePath: "main.go", Package: ".", PageRank: 0.5, LOC: 5},
+		}},
+	}
+	for _, sc := range scans {
+		meta := &ScanMetadata{GitCommit: sc.commit}
+		if err := store.SaveScanMetadata(meta); err != nil {
+			t.Fatalf("save scan metadata: %v", err)
+		}
+		if err := store.SaveMetricHistory(meta.ID, sc.snapshots); err != nil {
+			t.Fatalf("save metric history: %v", err)
+		}
+	}
+
+	values := func(series []MetricPoint) []float64 {
+		var v []float64
+		for _, p := range series {
+			v = append(v, math.Round(p.Value*1000)/1000)
+		}
+		return v
+	}
+	tests := []struct {
+		metric string
+		scope  HistoryScope
+		want   []float64
+	}{
+		{"fan_in", HistoryScope{FilePath: "pkg/parse.go", Name: "Parse"}, []float64{2, 5}},
+		{"pagerank", HistoryScope{Package: "pkg"}, []float64{0.3, 0.5}},
+		{"loc", HistoryScope{}, []float64{40, 45}},
+		// LOC-weighted over the entities with coverage, only once it exists
+		{"coverage", HistoryScope{}, []float64{62.5}},
+	}
+	for _, tt := range tests {
+		series, err := store.GetMetricSeries(tt.metric, tt.scope)
+		if err != nil {
+			t.Fatalf("GetMetricSeries(%s): %v", tt.metric, err)
+		}
+		if got := values(series); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetMetricSeries(%s, %+v) = %v, want %v", tt.metric, tt.scope, got, tt.want)
+		}
+	}
+
+	series, _ := store.GetMetricSeries("fan_in", HistoryScope{FilePath: "pkg/parse.go", Name: "Parse"})
+	if len(series) != 2 || series[1].GitCommit != "bbb2222" || series[0].ScanID >= series[1].ScanID {
+		t.Errorf("series should follow the scans in order with their commits, got %+v", series)
+	}
+	if _, err := store.GetMetricSeries("betweenness", HistoryScope{}); err == nil {
+		t.Error("expected an error for a metric without history")
+	}
+	if ok, _ := store.HasHistoryPackage("pkg"); !ok {
+		t.Error("expected package pkg in the history")
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	store, cleanup := testStore(t)
+	defer cleanup()
+
+	for _, id := range []string{"sa-fn-1-Parse", "sa-fn-9-lex", "sa-fn-3-old"} {
+		e := &Entity{ID: id, Name: id, EntityType: "function", FilePath: "pkg/parse.go", LineStart: 1}
+		if err := store.CreateEntity(e); err != nil {
+			t.Fatalf("create entity: %v", err)
+		}
+	}
+	first := []EntityFingerprint{
+		{EntityID: "sa-fn-1-Parse", FilePath: "pkg/parse.go", Tokens: 40, Hashes: []uint32{1, 0xdeadbeef}},
+		{EntityID: "sa-fn-3-old", FilePath: "pkg/parse.go", Tokens: 12, Hashes: []uint32{7}},
+	}
+	if err := store.SaveFingerprints([]string{"pkg/parse.go"}, first); err != nil {
+		t.Fatalf("save fingerprints: %v", err)
+	}
+	// A rescan of the file replaces all of its fingerprints
+	second := []EntityFingerprint{
+		{EntityID: "sa-fn-1-Parse", FilePath: "pkg/parse.go", Tokens: 41, Hashes: []uint32{1, 2}},
+		{EntityID: "sa-fn-9-lex", FilePath: "pkg/parse.go", Tokens: 30, Hashes: []uint32{3}},
+	}
+	if err := store.SaveFingerprints([]string{"pkg/parse.go"}, second); err != nil {
+		t.Fatalf("save fingerprints: %v", err)
+	}
+	if err := store.ArchiveEntity("sa-fn-9-lex"); err != nil {
+		t.Fatalf("archive entity: %v", err)
+	}
+
+	got, err := store.GetFingerprints()
+	if err != nil {
+		t.Fatalf("get fingerprints: %v", err)
+	}
+	want := []EntityFingerprint{second[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFingerprints() = %+v, want %+v", got, want)
+	}
+}
+
 func TestDoltCommit(t *testing.T) {
 	store, cleanup := testStore(t)
 	defer cleanup()
@@ -8,26 +8,24 @@ import (
 
 // AddTag adds a tag to an entity.
 // If the tag already exists for the entity, it updates the note and created_by fields.
-func (s *Store) AddTag(entityID, tag, createdBy string) error {
+func (s *SQLStore) AddTag(entityID, tag, createdBy string) error {
 	return s.AddTagWithNote(entityID, tag, createdBy, "")
 }
 
 // AddTagWithNote adds a tag to an entity with an optional note.
 // If the tag already exists for the entity, it updates the note and created_by fields.
-func (s *Store) AddTagWithNote(entityID, tag, createdBy, note string) error {
+func (s *SQLStore) AddTagWithNote(entityID, tag, createdBy, note string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	_, err := s.db.Exec(`
 		INSERT INTO entity_tags (entity_id, tag, created_at, created_by, note)
-		VALUES (?, ?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE
-			created_by = VALUES(created_by),
-			note = VALUES(note)`,
+		VALUES (?, ?, ?, ?, ?) `+
+		s.onConflictUpdate([]string{"entity_id", "tag"}, []string{"created_by", "note"}),
 		entityID, tag, now, createdBy, note)
 	return err
 }
 
 // RemoveTag removes a tag from an entity.
-func (s *Store) RemoveTag(entityID, tag string) error {
+func (s *SQLStore) RemoveTag(entityID, tag string) error {
 	result, err := s.db.Exec(`DELETE FROM entity_tags WHERE entity_id = ? AND tag = ?`, entityID, tag)
 	if err != nil {
 		return err
@@ -46,7 +44,7 @@ func (s *Store) RemoveTag(entityID, tag string) error {
 }
 
 // GetTags returns all tags for an entity.
-func (s *Store) GetTags(entityID string) ([]*EntityTag, error) {
+func (s *SQLStore) GetTags(entityID string) ([]*EntityTag, error) {
 	rows, err := s.db.Query(`
 		SELECT entity_id, tag, created_at, created_by, note
 		FROM entity_tags WHERE entity_id = ?
@@ -80,7 +78,7 @@ func (s *Store) GetTags(entityID string) ([]*EntityTag, error) {
 }
 
 // FindByTag returns all entities with a specific tag.
-func (s *Store) FindByTag(tag string) ([]*Entity, error) {
+func (s *SQLStore) FindByTag(tag string) ([]*Entity, error) {
 	rows, err := s.db.Query(`
 		SELECT e.id, e.name, e.entity_type, e.kind, e.file_path, e.line_start, e.line_end,
 			e.signature, e.sig_hash, e.body_hash, e.receiver, e.visibility, e.fields, e.language, e.status,
@@ -98,7 +96,7 @@ func (s *Store) FindByTag(tag string) ([]*Entity, error) {
 }
 
 // FindByTags returns entities that have all (matchAll=true) or any (matchAll=false) of the given tags.
-func (s *Store) FindByTags(tags []string, matchAll bool) ([]*Entity, error) {
+func (s *SQLStore) FindByTags(tags []string, matchAll bool) ([]*Entity, error) {
 	if len(tags) == 0 {
 		return nil, nil
 	}
@@ -149,7 +147,7 @@ func (s *Store) FindByTags(tags []string, matchAll bool) ([]*Entity, error) {
 }
 
 // ListAllTags returns all unique tags in the database with their usage counts.
-func (s *Store) ListAllTags() (map[string]int, error) {
+func (s *SQLStore) ListAllTags() (map[string]int, error) {
 	rows, err := s.db.Query(`
 		SELECT tag, COUNT(*) as count
 		FROM entity_tags
@@ -173,20 +171,20 @@ func (s *Store) ListAllTags() (map[string]int, error) {
 }
 
 // DeleteTagsForEntity removes all tags for an entity.
-func (s *Store) DeleteTagsForEntity(entityID string) error {
+func (s *SQLStore) DeleteTagsForEntity(entityID string) error {
 	_, err := s.db.Exec(`DELETE FROM entity_tags WHERE entity_id = ?`, entityID)
 	return err
 }
 
 // CountTags returns the total number of tag assignments.
-func (s *Store) CountTags() (int, error) {
+func (s *SQLStore) CountTags() (int, error) {
 	var count int
 	err := s.db.QueryRow(`SELECT COUNT(*) FROM entity_tags`).Scan(&count)
 	return count, err
 }
 
 // CountUniqueTags returns the number of unique tags.
-func (s *Store) CountUniqueTags() (int, error) {
+func (s *SQLStore) CountUniqueTags() (int, error) {
 	var count int
 	err := s.db.QueryRow(`SELECT COUNT(DISTINCT tag) FROM entity_tags`).Scan(&count)
 	return count, err
@@ -202,7 +200,7 @@ type EntityTagWithName struct {
 }
 
 // GetAllTagsWithEntity returns all tags with entity names for export.
-func (s *Store) GetAllTagsWithEntity() ([]*EntityTagWithName, error) {
+func (s *SQLStore) GetAllTagsWithEntity() ([]*EntityTagWithName, error) {
 	rows, err := s.db.Query(`
 		SELECT t.entity_id, COALESCE(e.name, '') as entity_name, t.tag, t.note, t.created_by
 		FROM entity_tags t
@@ -30,10 +30,28 @@ type Entity struct {
 type Dependency struct {
 	FromID    string    `json:"from_id"`
 	ToID      string    `json:"to_id"`
-	DepType   string    `json:"dep_type"` // calls, uses_type, implements, extends, imports
+	DepType   string    `json:"dep_type"`           // calls, uses_type, implements, extends, imports
+	Optional  bool      `json:"optional,omitempty"` // conditional use (optional chaining, nil-guarded, try/rescue)
+	Source    string    `json:"source,omitempty"`   // provenance: treesitter (heuristic) or scip (compiler-precise)
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Dependency sources record where an edge came from. Tree-sitter edges are
+// resolved heuristically by name; SCIP edges come from a compiler's index.
+const (
+	DepSourceTreeSitter = "treesitter"
+	DepSourceSCIP       = "scip"
+)
+
+// depSource returns d's source, defaulting to tree-sitter for edges created
+// without one.
+func depSource(d *Dependency) string {
+	if d.Source == "" {
+		return DepSourceTreeSitter
+	}
+	return d.Source
+}
+
 // Metrics represents computed graph metrics for an entity
 type Metrics struct {
 	EntityID    string    `json:"entity_id"`
@@ -86,4 +104,28 @@ type DependencyFilter struct {
 	FromID  string // filter by source entity
 	ToID    string // filter by target entity
 	DepType string // filter by dependency type
+	Source  string // filter by provenance (treesitter, scip)
+}
+
+// EntityCoverage represents test coverage for a single entity
+type EntityCoverage struct {
+	EntityID        string    `json:"entity_id"`
+	CoveragePercent float64   `json:"coverage_percent"`
+	CoveredLines    []int     `json:"covered_lines"`
+	UncoveredLines  []int     `json:"uncovered_lines"`
+	LastRun         time.Time `json:"last_run"`
+}
+
+// TestMapping records that a test covers an entity
+type TestMapping struct {
+	TestFile string `json:"test_file"`
+	TestName string `json:"test_name"`
+	EntityID string `json:"entity_id"`
+}
+
+// TestMappingFilter contains filters for querying test mappings
+type TestMappingFilter struct {
+	EntityID string // filter by covered entity
+	TestName string // filter by test function name
+	TestFile string // filter by test file path
 }
@@ -0,0 +1,359 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/cx/internal/ann"
+)
+
+// EmbeddingIndexFile is the name of the approximate nearest-neighbour index
+// over entity_embeddings, stored in the .cx directory next to the database.
+const EmbeddingIndexFile = "embeddings.hnsw"
+
+// encodeVector packs an embedding as little-endian float32s, 4 bytes per
+// dimension, for the entity_embeddings.embedding column.
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+// decodeVector unpacks an embedding column. It also accepts the JSON arrays
+// written before schema migration 2, which Dolt still returns for AS OF
+// queries against older commits.
+func decodeVector(data []byte) ([]float32, error) {
+	if n := len(data); n >= 2 && data[0] == '[' && data[n-1] == ']' {
+		var v []float32
+		if err := json.Unmarshal(data, &v); err == nil {
+			return v, nil
+		}
+	}
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("embedding is %d bytes, not a whole number of float32s", len(data))
+	}
+	v := make([]float32, len(data)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return v, nil
+}
+
+// migrateBinaryEmbeddings converts entity_embeddings.embedding from JSON text
+// to packed float32s, roughly a quarter of the size and with no parsing cost.
+func migrateBinaryEmbeddings(s *SQLStore) error {
+	if s.backend == BackendDolt {
+		// SQLite columns accept blobs as-is; Dolt's JSON column must change type
+		if _, err := s.db.Exec(`ALTER TABLE entity_embeddings MODIFY embedding LONGBLOB NOT NULL`); err != nil {
+			return fmt.Errorf("alter embedding column: %w", err)
+		}
+	}
+
+	rows, err := s.db.Query(`SELECT entity_id, embedding FROM entity_embeddings`)
+	if err != nil {
+		return err
+	}
+	converted := make(map[string][]byte)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return err
+		}
+		if len(data) == 0 || data[0] != '[' {
+			continue // already binary
+		}
+		vec, err := decodeVector(data)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("decode embedding for %s: %w", id, err)
+		}
+		converted[id] = encodeVector(vec)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for id, data := range converted {
+		if _, err := tx.Exec(`UPDATE entity_embeddings SET embedding = ? WHERE entity_id = ?`, data, id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("rewrite embedding for %s: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// migrateEmbeddingProviders makes room for "provider:model" model versions,
+// which can be longer than the original 50 characters, and renames versions
+// written by the built-in model before providers existed so those embeddings
+// are not regenerated.
+func migrateEmbeddingProviders(s *SQLStore) error {
+	if s.backend == BackendDolt {
+		// SQLite does not enforce VARCHAR lengths
+		if _, err := s.db.Exec(`ALTER TABLE entity_embeddings MODIFY model_version VARCHAR(255) NOT NULL`); err != nil {
+			return fmt.Errorf("widen model_version: %w", err)
+		}
+	}
+	_, err := s.db.Exec(`UPDATE entity_embeddings SET model_version = 'local:all-MiniLM-L6-v2'
+		WHERE model_version = 'all-MiniLM-L6-v2'`)
+	return err
+}
+
+// vectorIndex holds the ANN index over entity_embeddings. It is loaded from
+// .cx/embeddings.hnsw on first use, reconciled with the table, then kept up
+// to date as embeddings are saved and deleted. Unsaved changes are written
+// back on Close.
+type vectorIndex struct {
+	mu      sync.Mutex
+	idx     *ann.Index // nil until loaded
+	dirty   bool       // idx has changes not yet written to disk
+	modTime time.Time  // modification time of the file idx was loaded from or saved to
+}
+
+// embeddingTag identifies the version of an embedding, so a persisted index
+// can tell which of its entries were re-embedded since it was written.
+func embeddingTag(modelVersion, contentHash string) string {
+	return modelVersion + "@" + contentHash
+}
+
+func (s *SQLStore) embeddingIndexPath() string {
+	return filepath.Join(s.cxDir, EmbeddingIndexFile)
+}
+
+// embeddingIndex returns the loaded index, loading and reconciling it first
+// if needed. The index is also reloaded when another process (cx scan while
+// the MCP server or daemon is running) has rewritten the file.
+// The caller must hold s.vectors.mu.
+func (s *SQLStore) embeddingIndex() (*ann.Index, error) {
+	path := s.embeddingIndexPath()
+	info, statErr := os.Stat(path)
+	if s.vectors.idx != nil && (statErr != nil || info.ModTime().Equal(s.vectors.modTime)) {
+		return s.vectors.idx, nil
+	}
+
+	idx, err := ann.Load(path)
+	switch {
+	case err == nil:
+		s.vectors.modTime = info.ModTime()
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, ann.ErrBadFormat):
+		idx = ann.New()
+	default:
+		return nil, fmt.Errorf("load embedding index: %w", err)
+	}
+
+	changed, err := s.syncEmbeddingIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+	s.vectors.idx = idx
+	s.vectors.dirty = changed > 0
+	return idx, nil
+}
+
+// syncEmbeddingIndex brings idx in line with entity_embeddings: entries whose
+// model version or content hash changed are re-read and replaced, and entries
+// with no row are removed. Only changed vectors are read from the database.
+// The index holds the embeddings of one model, the one the newest row was
+// made with; rows left by an earlier model (archived entities that were not
+// re-embedded, or an interrupted --embed run) are left out of it.
+// It returns the number of entries added, replaced or removed.
+func (s *SQLStore) syncEmbeddingIndex(idx *ann.Index) (int, error) {
+	model, dim, err := s.embeddingTarget()
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	if idx.Dim() != 0 && idx.Dim() != dim {
+		changed = idx.Len()
+		*idx = *ann.New()
+	}
+
+	rows, err := s.db.Query(`SELECT entity_id, content_hash FROM entity_embeddings WHERE model_version = ?`, model)
+	if err != nil {
+		return changed, err
+	}
+	current := idx.Tags()
+	var stale []string
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var id, hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			rows.Close()
+			return changed, err
+		}
+		seen[id] = true
+		if tag, ok := current[id]; !ok || tag != embeddingTag(model, hash) {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return changed, err
+	}
+
+	for id := range current {
+		if !seen[id] {
+			idx.Remove(id)
+			changed++
+		}
+	}
+
+	const batchSize = 500
+	for start := 0; start < len(stale); start += batchSize {
+		batch := stale[start:min(start+batchSize, len(stale))]
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		query := `SELECT entity_id, embedding, model_version, content_hash FROM entity_embeddings
+			WHERE entity_id IN (?` + strings.Repeat(", ?", len(batch)-1) + `)`
+		n, err := s.addEmbeddingRows(idx, dim, query, args...)
+		if err != nil {
+			return changed, err
+		}
+		changed += n
+	}
+	return changed, nil
+}
+
+// embeddingTarget returns the model version and dimension the index is
+// built for: those of the newest embeddings, the most common model among
+// rows saved in the same second. Both are zero when there are no
+// embeddings.
+func (s *SQLStore) embeddingTarget() (string, int, error) {
+	var model string
+	var count int
+	err := s.db.QueryRow(`SELECT model_version, COUNT(*) AS n FROM entity_embeddings
+		WHERE created_at = (SELECT MAX(created_at) FROM entity_embeddings)
+		GROUP BY model_version ORDER BY n DESC, model_version LIMIT 1`).Scan(&model, &count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	var data []byte
+	err = s.db.QueryRow(`SELECT embedding FROM entity_embeddings
+		WHERE model_version = ? ORDER BY created_at DESC LIMIT 1`, model).Scan(&data)
+	if err != nil {
+		return "", 0, err
+	}
+	vec, err := decodeVector(data)
+	if err != nil {
+		return "", 0, fmt.Errorf("decode newest embedding: %w", err)
+	}
+	return model, len(vec), nil
+}
+
+// addEmbeddingRows adds the (entity_id, embedding, model_version,
+// content_hash) rows returned by query to idx, skipping vectors that are
+// not dim long.
+func (s *SQLStore) addEmbeddingRows(idx *ann.Index, dim int, query string, args ...any) (int, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var id, model, hash string
+		var data []byte
+		if err := rows.Scan(&id, &data, &model, &hash); err != nil {
+			return n, err
+		}
+		vec, err := decodeVector(data)
+		if err != nil {
+			return n, fmt.Errorf("decode embedding for %s: %w", id, err)
+		}
+		if len(vec) != dim {
+			continue
+		}
+		if err := idx.Add(id, embeddingTag(model, hash), vec); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// SyncEmbeddingIndex reconciles the embedding index with entity_embeddings
+// and writes it to .cx/embeddings.hnsw, so the next semantic query does not
+// pay for loading changes. cx scan calls it after generating embeddings.
+func (s *SQLStore) SyncEmbeddingIndex() error {
+	s.vectors.mu.Lock()
+	defer s.vectors.mu.Unlock()
+	idx, err := s.embeddingIndex()
+	if err != nil {
+		return err
+	}
+	changed, err := s.syncEmbeddingIndex(idx)
+	if err != nil {
+		return err
+	}
+	if changed > 0 {
+		s.vectors.dirty = true
+	}
+	return s.saveEmbeddingIndexLocked()
+}
+
+// saveEmbeddingIndex writes the index to disk if it has unsaved changes.
+func (s *SQLStore) saveEmbeddingIndex() error {
+	s.vectors.mu.Lock()
+	defer s.vectors.mu.Unlock()
+	return s.saveEmbeddingIndexLocked()
+}
+
+func (s *SQLStore) saveEmbeddingIndexLocked() error {
+	if s.vectors.idx == nil || !s.vectors.dirty {
+		return nil
+	}
+	path := s.embeddingIndexPath()
+	if err := s.vectors.idx.Save(path); err != nil {
+		return fmt.Errorf("save embedding index: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		s.vectors.modTime = info.ModTime()
+	}
+	s.vectors.dirty = false
+	return nil
+}
+
+// indexEmbedding applies a saved or deleted embedding to the loaded index.
+// If the index has not been loaded yet there is nothing to do: it is
+// reconciled with the table when it is.
+func (s *SQLStore) indexEmbedding(entityID string, vec []float32, tag string) {
+	s.vectors.mu.Lock()
+	defer s.vectors.mu.Unlock()
+	idx := s.vectors.idx
+	if idx == nil {
+		return
+	}
+	if vec == nil {
+		idx.Remove(entityID)
+	} else if err := idx.Add(entityID, tag, vec); err != nil {
+		// Dimension changed: drop the index and rebuild it for the new
+		// model on next use
+		s.vectors.idx = nil
+		return
+	}
+	s.vectors.dirty = true
+}
@@ -0,0 +1,217 @@
+package tokens
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// cl100kBase is the embedded vocabulary: tiktoken's cl100k_base, gzipped,
+// so counts match models that use it exactly and others closely. Its
+// license is in cl100k_base.LICENSE. To refresh it:
+//
+//	curl -s https://openaipublic.blob.core.windows.net/encodings/cl100k_base.tiktoken | gzip -9n > cl100k_base.tiktoken.gz
+//
+//go:embed cl100k_base.tiktoken.gz
+var cl100kBase []byte
+
+// cl100kBaseSHA256 is the hash of the uncompressed vocabulary, the one
+// tiktoken checks cl100k_base.tiktoken against
+const cl100kBaseSHA256 = "223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7"
+
+// EmbeddedVocab names the embedded vocabulary in BPE.Name
+const EmbeddedVocab = "cl100k_base"
+
+// maxCachedPieces bounds the piece count cache; it is cleared when full
+const maxCachedPieces = 1 << 16
+
+// BPE counts tokens by byte-pair encoding with a tiktoken-format vocabulary:
+// text is split into pieces by the encoding's pre-tokenization rules, and
+// each piece is merged from single bytes by lowest rank first, as tiktoken
+// does. Only counts are kept, never token ids. Safe for concurrent use.
+type BPE struct {
+	name     string
+	encoding string
+	ranks    map[string]int
+
+	mu    sync.Mutex
+	cache map[string]int
+}
+
+var (
+	embeddedOnce sync.Once
+	embedded     *BPE
+	embeddedErr  error
+)
+
+// Embedded returns the counter for the embedded vocabulary, loaded once
+func Embedded() (*BPE, error) {
+	embeddedOnce.Do(func() {
+		embedded, embeddedErr = readEmbedded(cl100kBase)
+	})
+	return embedded, embeddedErr
+}
+
+// readEmbedded reads a gzipped vocabulary and checks it is cl100k_base
+func readEmbedded(data []byte) (*BPE, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("embedded vocabulary: %w", err)
+	}
+	defer zr.Close()
+	h := sha256.New()
+	b, err := ReadBPE(EmbeddedVocab, io.TeeReader(zr, h), EncodingCL100K)
+	if err != nil {
+		return nil, err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != cl100kBaseSHA256 {
+		return nil, fmt.Errorf("embedded vocabulary: sha256 %s, want %s", sum, cl100kBaseSHA256)
+	}
+	return b, nil
+}
+
+// LoadBPE reads a tiktoken-format vocabulary file, such as
+// cl100k_base.tiktoken or o200k_base.tiktoken, split by the given encoding
+func LoadBPE(path, encoding string) (*BPE, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open vocabulary: %w", err)
+	}
+	defer f.Close()
+	name := strings.TrimSuffix(filepath.Base(path), ".tiktoken")
+	return ReadBPE(name, f, encoding)
+}
+
+// ReadBPE reads a tiktoken-format vocabulary: one token per line, the token
+// bytes in base64 followed by its rank
+func ReadBPE(name string, r io.Reader, encoding string) (*BPE, error) {
+	if encoding == "" {
+		encoding = EncodingCL100K
+	}
+	if encoding != EncodingCL100K && encoding != EncodingO200K {
+		return nil, fmt.Errorf("unknown encoding %q (valid: %s, %s)", encoding, EncodingCL100K, EncodingO200K)
+	}
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("vocabulary line %d: want \"<base64 token> <rank>\"", line)
+		}
+		b, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary line %d: %w", line, err)
+		}
+		n, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary line %d: %w", line, err)
+		}
+		ranks[string(b)] = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read vocabulary: %w", err)
+	}
+	for b := 0; b < 256; b++ {
+		if _, ok := ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("vocabulary is not byte-level: byte %#x has no token", b)
+		}
+	}
+	return &BPE{name: name, encoding: encoding, ranks: ranks, cache: make(map[string]int)}, nil
+}
+
+// Name identifies the vocabulary, e.g. bpe:cl100k_base or bpe:o200k_base
+func (b *BPE) Name() string { return KindBPE + ":" + b.name }
+
+// Size is the number of tokens in the vocabulary
+func (b *BPE) Size() int { return len(b.ranks) }
+
+// Count returns the number of tokens text encodes to
+func (b *BPE) Count(text string) int {
+	n := 0
+	forEachPiece(text, b.encoding, func(piece string) {
+		n += b.countPiece(piece)
+	})
+	return n
+}
+
+// countPiece counts the tokens of one piece, caching multi-token pieces
+func (b *BPE) countPiece(piece string) int {
+	if _, ok := b.ranks[piece]; ok {
+		return 1
+	}
+	b.mu.Lock()
+	n, ok := b.cache[piece]
+	b.mu.Unlock()
+	if ok {
+		return n
+	}
+	n = b.merge(piece)
+	b.mu.Lock()
+	if len(b.cache) >= maxCachedPieces {
+		clear(b.cache)
+	}
+	b.cache[piece] = n
+	b.mu.Unlock()
+	return n
+}
+
+// merge runs byte-pair merges over a piece and returns the number of parts
+// left. Like tiktoken it keeps the rank of each adjacent pair and merges the
+// lowest until no pair is in the vocabulary.
+func (b *BPE) merge(piece string) int {
+	// parts[i] is the start of part i; the last entry is len(piece)
+	parts := make([]int, len(piece)+1)
+	for i := range parts {
+		parts[i] = i
+	}
+	rank := func(i int) int {
+		if i+2 >= len(parts) {
+			return math.MaxInt
+		}
+		if r, ok := b.ranks[piece[parts[i]:parts[i+2]]]; ok {
+			return r
+		}
+		return math.MaxInt
+	}
+	ranks := make([]int, len(parts)-1)
+	for i := range ranks {
+		ranks[i] = rank(i)
+	}
+	for len(ranks) > 1 {
+		best, at := math.MaxInt, -1
+		for i, r := range ranks[:len(ranks)-1] {
+			if r < best {
+				best, at = r, i
+			}
+		}
+		if at < 0 {
+			break
+		}
+		// Merge parts at and at+1, then re-rank the pairs around them
+		parts = append(parts[:at+1], parts[at+2:]...)
+		ranks = append(ranks[:at+1], ranks[at+2:]...)
+		ranks[at] = rank(at)
+		if at > 0 {
+			ranks[at-1] = rank(at - 1)
+		}
+	}
+	return len(parts) - 1
+}
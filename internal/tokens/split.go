@@ -0,0 +1,237 @@
+package tokens
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encodings name the pre-tokenization rules a BPE vocabulary was trained
+// with. Text is split into pieces first and merges never cross a piece.
+const (
+	// EncodingCL100K splits as cl100k_base does: letter runs with one
+	// leading non-letter, digits in threes, punctuation runs, and whitespace
+	// that leaves its last space to the next word.
+	EncodingCL100K = "cl100k"
+	// EncodingO200K splits as o200k_base does: like cl100k, but letter runs
+	// break before an upper-case letter that follows a lower-case one.
+	EncodingO200K = "o200k"
+)
+
+// Split splits text into the pieces BPE merges within, following the
+// pre-tokenization regex of the encoding. Unknown encodings split as cl100k.
+func Split(text, encoding string) []string {
+	var pieces []string
+	forEachPiece(text, encoding, func(piece string) {
+		pieces = append(pieces, piece)
+	})
+	return pieces
+}
+
+// forEachPiece calls fn with each piece of text in order
+func forEachPiece(text, encoding string, fn func(string)) {
+	next := nextCL100K
+	if encoding == EncodingO200K {
+		next = nextO200K
+	}
+	for i := 0; i < len(text); {
+		end := next(text, i)
+		if end <= i {
+			// Every alternative failed: a lone character stands alone
+			_, size := utf8.DecodeRuneInString(text[i:])
+			end = i + size
+		}
+		fn(text[i:end])
+		i = end
+	}
+}
+
+// nextCL100K returns the end of the piece starting at i under
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func nextCL100K(text string, i int) int {
+	if end := contraction(text, i); end > i {
+		return end
+	}
+	r, size := utf8.DecodeRuneInString(text[i:])
+	if isLetter(r) {
+		return skip(text, i, isLetter)
+	}
+	if !isNewline(r) && !isNumber(r) {
+		if next, _ := runeAt(text, i+size); isLetter(next) {
+			return skip(text, i+size, isLetter)
+		}
+	}
+	if isNumber(r) {
+		return skipN(text, i, isNumber, 3)
+	}
+	if end := punctuation(text, i, false); end > i {
+		return end
+	}
+	return whitespace(text, i)
+}
+
+// nextO200K returns the end of the piece starting at i under
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|...)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|...)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func nextO200K(text string, i int) int {
+	r, size := utf8.DecodeRuneInString(text[i:])
+	prefixed := !isNewline(r) && !isLetter(r) && !isNumber(r)
+	for _, word := range []func(string, int) int{upperLower, upperRun} {
+		if prefixed {
+			if end := word(text, i+size); end > i+size {
+				return withContraction(text, end)
+			}
+		}
+		if end := word(text, i); end > i {
+			return withContraction(text, end)
+		}
+	}
+	if isNumber(r) {
+		return skipN(text, i, isNumber, 3)
+	}
+	if end := punctuation(text, i, true); end > i {
+		return end
+	}
+	return whitespace(text, i)
+}
+
+// upperLower matches [upper]*[lower]+ at i, backtracking the upper run
+// when the lower run cannot start after it
+func upperLower(text string, i int) int {
+	u := skip(text, i, isUpperish)
+	if r, _ := runeAt(text, u); isLowerish(r) {
+		return skip(text, u, isLowerish)
+	}
+	for v := u; v > i; {
+		r, size := utf8.DecodeLastRuneInString(text[i:v])
+		v -= size
+		if isLowerish(r) {
+			return skip(text, v, isLowerish)
+		}
+	}
+	return i
+}
+
+// upperRun matches [upper]+[lower]* at i
+func upperRun(text string, i int) int {
+	u := skip(text, i, isUpperish)
+	if u == i {
+		return i
+	}
+	return skip(text, u, isLowerish)
+}
+
+// withContraction extends a word ending at end by a following contraction
+func withContraction(text string, end int) int {
+	if c := contraction(text, end); c > end {
+		return c
+	}
+	return end
+}
+
+// contraction matches (?i:'s|'t|'re|'ve|'m|'ll|'d) at i
+func contraction(text string, i int) int {
+	if i >= len(text) || text[i] != '\'' {
+		return i
+	}
+	lower := func(j int) byte {
+		if j < len(text) && text[j] >= 'A' && text[j] <= 'Z' {
+			return text[j] + 'a' - 'A'
+		}
+		if j < len(text) {
+			return text[j]
+		}
+		return 0
+	}
+	switch a, b := lower(i+1), lower(i+2); {
+	case a == 's' || a == 't' || a == 'm' || a == 'd':
+		return i + 2
+	case a == 'r' && b == 'e', a == 'v' && b == 'e', a == 'l' && b == 'l':
+		return i + 3
+	}
+	return i
+}
+
+// punctuation matches " ?[^\s\p{L}\p{N}]+[\r\n]*" at i; o200k also lets
+// slashes trail
+func punctuation(text string, i int, slashes bool) int {
+	j := i
+	if text[j] == ' ' {
+		j++
+	}
+	k := skip(text, j, isPunct)
+	if k == j {
+		return i
+	}
+	return skip(text, k, func(r rune) bool { return isNewline(r) || (slashes && r == '/') })
+}
+
+// whitespace matches \s*[\r\n]+|\s+(?!\S)|\s+ at i
+func whitespace(text string, i int) int {
+	end := skip(text, i, unicode.IsSpace)
+	if end == i {
+		return i
+	}
+	// \s*[\r\n]+ ends after the last line break of the run
+	for j := end; j > i; j-- {
+		if isNewline(rune(text[j-1])) {
+			return j
+		}
+	}
+	// \s+(?!\S) leaves the last whitespace character to the next piece
+	if end < len(text) {
+		_, size := utf8.DecodeLastRuneInString(text[i:end])
+		if end-size > i {
+			return end - size
+		}
+	}
+	return end
+}
+
+// skip returns the end of the run of runes matching class from i
+func skip(text string, i int, class func(rune) bool) int {
+	return skipN(text, i, class, -1)
+}
+
+// skipN is skip limited to n runes; n < 0 is unlimited
+func skipN(text string, i int, class func(rune) bool, n int) int {
+	for i < len(text) && n != 0 {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !class(r) {
+			break
+		}
+		i += size
+		n--
+	}
+	return i
+}
+
+// runeAt decodes the rune at i, or returns -1 past the end
+func runeAt(text string, i int) (rune, int) {
+	if i >= len(text) {
+		return -1, 0
+	}
+	return utf8.DecodeRuneInString(text[i:])
+}
+
+func isLetter(r rune) bool  { return unicode.IsLetter(r) }
+func isNumber(r rune) bool  { return unicode.IsNumber(r) }
+func isNewline(r rune) bool { return r == '\r' || r == '\n' }
+
+// isPunct is [^\s\p{L}\p{N}]
+func isPunct(r rune) bool {
+	return r >= 0 && !unicode.IsSpace(r) && !isLetter(r) && !isNumber(r)
+}
+
+// isUpperish is [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]
+func isUpperish(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+// isLowerish is [\p{Ll}\p{Lm}\p{Lo}\p{M}]
+func isLowerish(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
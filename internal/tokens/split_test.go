@@ -0,0 +1,58 @@
+package tokens
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCL100K(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello world", []string{"Hello", " world"}},
+		{"hello   world", []string{"hello", "  ", " world"}},
+		{"don't STOP'LL", []string{"don", "'t", " STOP", "'LL"}},
+		{"12345", []string{"123", "45"}},
+		{"x := y\n\n\tz", []string{"x", " :=", " y", "\n\n", "\tz"}},
+		{"a.b()", []string{"a", ".b", "()"}},
+		{"  \n  x", []string{"  \n", " ", " x"}},
+		{"end  ", []string{"end", "  "}},
+		{"__init__", []string{"__", "init", "__"}},
+		{"if err != nil {\n\treturn err\n}", []string{"if", " err", " !=", " nil", " {\n", "\treturn", " err", "\n", "}"}},
+		{"naïve café", []string{"naïve", " café"}},
+	}
+	for _, tt := range tests {
+		if got := Split(tt.text, EncodingCL100K); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitO200K(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"camelCaseWord", []string{"camel", "Case", "Word"}},
+		{"getHTTP", []string{"get", "HTTP"}},
+		{"HTTPServer", []string{"HTTPServer"}},
+		{"path/to x//\n", []string{"path", "/to", " x", "//\n"}},
+		{"it's", []string{"it's"}},
+	}
+	for _, tt := range tests {
+		if got := Split(tt.text, EncodingO200K); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitCoversText(t *testing.T) {
+	text := "func (s *Server) Run(ctx context.Context) error {\n\t// 日本語 ok\r\n\treturn nil  \n}\n\xff"
+	for _, enc := range []string{EncodingCL100K, EncodingO200K} {
+		if got := strings.Join(Split(text, enc), ""); got != text {
+			t.Errorf("%s pieces join to %q, want the text", enc, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package tokens counts the tokens text costs in a language model's context
+// window, for the token budgets of cx context and cx map.
+//
+// The default counter is byte-pair encoding with a vocabulary embedded in
+// the binary, so counting works offline. A model's own tiktoken vocabulary
+// can replace it through tokenizer.vocab_path in .cx/config.yaml, and the
+// heuristic counter trades accuracy for not loading a vocabulary at all.
+package tokens
+
+import (
+	"fmt"
+
+	"github.com/anthropics/cx/internal/config"
+)
+
+// Counter counts the tokens of text.
+type Counter interface {
+	// Count returns the number of tokens text encodes to.
+	Count(text string) int
+
+	// Name identifies the counter, e.g. bpe:cl100k_base or heuristic.
+	Name() string
+}
+
+// Tokenizers selectable with tokenizer.kind in .cx/config.yaml.
+const (
+	KindBPE       = "bpe"       // byte-pair encoding, embedded or tokenizer.vocab_path vocabulary
+	KindHeuristic = "heuristic" // character classes, no vocabulary
+)
+
+// New creates the counter selected by cfg.
+func New(cfg config.TokenizerConfig) (Counter, error) {
+	switch cfg.Kind {
+	case KindBPE, "":
+		if cfg.VocabPath != "" {
+			return LoadBPE(cfg.VocabPath, cfg.Encoding)
+		}
+		return Embedded()
+	case KindHeuristic:
+		return Heuristic{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tokenizer %q (valid: %v)", cfg.Kind, config.ValidTokenizers)
+	}
+}
+
+// NewFromConfig creates the counter configured in the .cx/config.yaml found
+// from the current directory, or the embedded BPE counter if there is none.
+func NewFromConfig() (Counter, error) {
+	cfg, err := config.Load(".")
+	if err != nil || cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	return New(cfg.Tokenizer)
+}
+
+// Default returns the configured counter, or the heuristic when the
+// configured one cannot be loaded, so that budgeting never fails a command.
+func Default() Counter {
+	if c, err := NewFromConfig(); err == nil {
+		return c
+	}
+	return Heuristic{}
+}
@@ -0,0 +1,155 @@
+package tokens
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/cx/internal/config"
+)
+
+// tinyVocab is the 256 bytes plus the given merged tokens, ranked in order
+func tinyVocab(merged ...string) string {
+	var sb strings.Builder
+	for b := 0; b < 256; b++ {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(b)}), b)
+	}
+	for i, m := range merged {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(m)), 256+i)
+	}
+	return sb.String()
+}
+
+func TestBPEMerges(t *testing.T) {
+	b, err := ReadBPE("tiny", strings.NewReader(tinyVocab("ab", "cd", "abcd", " x")), EncodingCL100K)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},     // in the vocabulary
+		{"abcde", 2},    // ab, cd, then abcd; e
+		{"abab", 2},     // abab is not a token
+		{"acbd", 4},     // no pair is a token
+		{"ab x", 2},     // pieces ab and " x"
+		{"ab abcd", 3},  // " abcd" merges to " " and abcd
+		{"  ab", 3},     // " " and " ab", which merges to " " and ab
+		{"xx\n\nyy", 6}, // merges never cross pieces
+		{"日本", 6},       // three bytes each
+		{"aéb", 4},      // é is two bytes
+	}
+	for _, tt := range tests {
+		if got := b.Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReadBPEErrors(t *testing.T) {
+	if _, err := ReadBPE("x", strings.NewReader("YQ== 0\n"), EncodingCL100K); err == nil {
+		t.Error("want an error for a vocabulary without every byte")
+	}
+	if _, err := ReadBPE("x", strings.NewReader("YQ==\n"), EncodingCL100K); err == nil {
+		t.Error("want an error for a line without a rank")
+	}
+	if _, err := ReadBPE("x", strings.NewReader(tinyVocab()), "p50k"); err == nil {
+		t.Error("want an error for an unknown encoding")
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	b, err := Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Size() != 100256 {
+		t.Errorf("embedded vocabulary has %d tokens, want 100256", b.Size())
+	}
+	if b.Name() != "bpe:cl100k_base" {
+		t.Errorf("Name() = %q", b.Name())
+	}
+	// Counts of tiktoken's cl100k_base encoding
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"The quick brown fox jumps over the lazy dog.", 10},
+		{"hello world", 2},
+		{"func (s *Server) Run(ctx context.Context) error {", 13},
+		{"\treturn fmt.Errorf(\"failed to open store: %w\", err)", 14},
+		{"    def __init__(self, name: str) -> None:\n        self.name = name\n", 20},
+		{"日本語のテキスト", 8},
+		{"naïve café 12345678", 8},
+		{"  \n\n\tx := 1\r\n", 6},
+	}
+	for _, tt := range tests {
+		if got := b.Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReadEmbeddedChecksum(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(tinyVocab("ab")))
+	zw.Close()
+	if _, err := readEmbedded(buf.Bytes()); err == nil || !strings.Contains(err.Error(), "sha256") {
+		t.Errorf("readEmbedded of another vocabulary = %v, want a checksum error", err)
+	}
+}
+
+func TestHeuristicTracksBPE(t *testing.T) {
+	b, err := Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile("bpe.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := b.Count(string(src)), Heuristic{}.Count(string(src))
+	if diff := float64(got-want) / float64(want); diff < -0.15 || diff > 0.15 {
+		t.Errorf("heuristic counts %d tokens, BPE %d: off by %.0f%%", got, want, diff*100)
+	}
+	if (Heuristic{}).Count("") != 0 {
+		t.Error("empty text has tokens")
+	}
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(config.TokenizerConfig{Kind: KindHeuristic})
+	if err != nil || c.Name() != KindHeuristic {
+		t.Errorf("heuristic: %v, %v", c, err)
+	}
+	c, err = New(config.TokenizerConfig{})
+	if err != nil || c.Name() != "bpe:cl100k_base" {
+		t.Errorf("default: %v, %v", c, err)
+	}
+	if _, err := New(config.TokenizerConfig{Kind: "sentencepiece"}); err == nil {
+		t.Error("want an error for an unknown tokenizer")
+	}
+
+	path := filepath.Join(t.TempDir(), "tiny_base.tiktoken")
+	if err := os.WriteFile(path, []byte(tinyVocab("ab")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = New(config.TokenizerConfig{Kind: KindBPE, VocabPath: path, Encoding: EncodingO200K})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name() != "bpe:tiny_base" || c.Count("ab") != 1 {
+		t.Errorf("vocab_path counter %s counts ab as %d tokens", c.Name(), c.Count("ab"))
+	}
+	if _, err := New(config.TokenizerConfig{VocabPath: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("want an error for a missing vocabulary")
+	}
+}